@@ -0,0 +1,122 @@
+package main
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Addr2Line prints the function name and source location for each of the
+// given addresses, using the DWARF debug information in the given ELF file.
+// This can be a regular executable or a .debug file created with
+// -debug=compressed. Compressed debug sections are decompressed transparently.
+func Addr2Line(w io.Writer, path string, addresses []string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := f.DWARF()
+	if err != nil {
+		return fmt.Errorf("could not read debug information from %s: %w", path, err)
+	}
+
+	for _, s := range addresses {
+		addr, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", s)
+		}
+		function, file, line, err := lookupAddress(data, addr)
+		if err != nil {
+			return err
+		}
+		if function == "" {
+			function = "??"
+		}
+		if file == "" {
+			file = "??"
+		}
+		fmt.Fprintf(w, "%s\n%s:%d\n", function, file, line)
+	}
+	return nil
+}
+
+// lookupAddress returns the function name, file and line number for the given
+// address. The returned strings are empty if they could not be determined.
+func lookupAddress(data *dwarf.Data, addr uint64) (function, file string, line int, err error) {
+	r := data.Reader()
+	for {
+		cu, err := r.Next()
+		if err != nil {
+			return "", "", 0, err
+		}
+		if cu == nil {
+			// Not found in any of the compile units.
+			return "", "", 0, nil
+		}
+		if cu.Tag != dwarf.TagCompileUnit {
+			r.SkipChildren()
+			continue
+		}
+		if !containsAddress(data, cu, addr) {
+			r.SkipChildren()
+			continue
+		}
+
+		// Find the source location using the line table.
+		lr, err := data.LineReader(cu)
+		if err != nil {
+			return "", "", 0, err
+		}
+		if lr != nil {
+			var entry dwarf.LineEntry
+			err := lr.SeekPC(addr, &entry)
+			if err == nil {
+				file = entry.File.Name
+				line = entry.Line
+			} else if !errors.Is(err, dwarf.ErrUnknownPC) {
+				return "", "", 0, err
+			}
+		}
+
+		// Find the function that contains this address.
+		for {
+			e, err := r.Next()
+			if err != nil {
+				return "", "", 0, err
+			}
+			if e == nil || e.Tag == 0 {
+				// End of this compile unit.
+				break
+			}
+			if e.Tag == dwarf.TagSubprogram {
+				if containsAddress(data, e, addr) {
+					function, _ = e.Val(dwarf.AttrName).(string)
+				}
+				r.SkipChildren()
+			} else if e.Children {
+				r.SkipChildren()
+			}
+		}
+		return function, file, line, nil
+	}
+}
+
+// containsAddress returns whether the given DWARF entry (a compile unit or a
+// function) covers the given address.
+func containsAddress(data *dwarf.Data, e *dwarf.Entry, addr uint64) bool {
+	ranges, err := data.Ranges(e)
+	if err != nil {
+		return false
+	}
+	for _, r := range ranges {
+		if addr >= r[0] && addr < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"go/types"
 	"hash/crc32"
+	"io"
 	"io/fs"
 	"math/bits"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"github.com/tinygo-org/tinygo/goenv"
 	"github.com/tinygo-org/tinygo/interp"
 	"github.com/tinygo-org/tinygo/loader"
+	"github.com/tinygo-org/tinygo/pgo"
 	"github.com/tinygo-org/tinygo/stacksize"
 	"github.com/tinygo-org/tinygo/transform"
 	"tinygo.org/x/go-llvm"
@@ -61,6 +63,10 @@ type BuildResult struct {
 	// correctly printing test results: the import path isn't always the same as
 	// the path listed on the command line.
 	ImportPath string
+
+	// A path to the generated C header file with declarations for all
+	// exported functions. Only set with -buildmode=c-archive and plugin.
+	CHeader string
 }
 
 // packageAction is the struct that is serialized to JSON and hashed, to work as
@@ -180,6 +186,8 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		AutomaticStackSize: config.AutomaticStackSize(),
 		DefaultStackSize:   config.StackSize(),
 		NeedsStackObjects:  config.NeedsStackObjects(),
+		IntOverflowTrap:    config.IntOverflow() == "trap",
+		StackOverflowCheck: config.StackOverflowCheck(),
 		Debug:              true,
 	}
 
@@ -204,6 +212,14 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		return err
 	}
 
+	// The plugin package loads plugins into the program. A plugin has its own
+	// runtime, which scans the stack from where it is called up to the top of
+	// the system stack, so it can't be called from a goroutine stack.
+	_, loadsPlugins := lprogram.Packages["plugin"]
+	if loadsPlugins && config.Scheduler() != "none" {
+		return fmt.Errorf("programs that import the plugin package must be built with -scheduler=none, got -scheduler=%s", config.Scheduler())
+	}
+
 	// Create the *ssa.Program. This does not yet build the entire SSA of the
 	// program so it's pretty fast and doesn't need to be parallelized.
 	program := lprogram.LoadSSA()
@@ -227,12 +243,30 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		config.Options.GlobalValues["runtime"]["buildVersion"] = version
 	}
 
+	// Embed the module information for runtime/debug.ReadBuildInfo. This is
+	// different for every program, so it isn't stored in the config.
+	globalValues := config.Options.GlobalValues
+	if globalValues["runtime/debug"]["modinfo"] == "" {
+		if info := lprogram.BuildInfo(); info != "" {
+			globalValues = make(map[string]map[string]string)
+			for pkgPath, values := range config.Options.GlobalValues {
+				globalValues[pkgPath] = values
+			}
+			debugValues := make(map[string]string)
+			for name, value := range config.Options.GlobalValues["runtime/debug"] {
+				debugValues[name] = value
+			}
+			debugValues["modinfo"] = info
+			globalValues["runtime/debug"] = debugValues
+		}
+	}
+
 	var embedFileObjects []*compileJob
 	for _, pkg := range lprogram.Sorted() {
 		pkg := pkg // necessary to avoid a race condition
 
 		var undefinedGlobals []string
-		for name := range config.Options.GlobalValues[pkg.Pkg.Path()] {
+		for name := range globalValues[pkg.Pkg.Path()] {
 			undefinedGlobals = append(undefinedGlobals, name)
 		}
 		sort.Strings(undefinedGlobals)
@@ -527,6 +561,12 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 			}
 			irbuilder.CreateRetVoid()
 
+			// A C archive or plugin doesn't have a main function of its own:
+			// initialize the runtime from a constructor instead.
+			if config.BuildsLibrary() {
+				addCArchiveConstructor(mod)
+			}
+
 			// After linking, functions should (as far as possible) be set to
 			// private linkage or internal linkage. The compiler package marks
 			// non-exported functions by setting the visibility to hidden or
@@ -558,7 +598,7 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 
 			// Run all optimization passes, which are much more effective now
 			// that the optimizer can see the whole program at once.
-			err := optimizeProgram(mod, config)
+			err := optimizeProgram(mod, config, globalValues)
 			if err != nil {
 				return err
 			}
@@ -644,7 +684,8 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 
 	// Add compiler-rt dependency if needed. Usually this is a simple load from
 	// a cache.
-	if config.Target.RTLib == "compiler-rt" {
+	// The C toolchain that links a C archive provides its own runtime library.
+	if config.Target.RTLib == "compiler-rt" && config.BuildMode() != "c-archive" {
 		job, unlock, err := CompilerRT.load(config, dir)
 		if err != nil {
 			return err
@@ -694,12 +735,29 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		ldflags = append(ldflags, lprogram.LDFlags...)
 	}
 
-	// Add libc dependencies, if they exist.
-	linkerDependencies = append(linkerDependencies, libcDependencies...)
+	// Plugins use the libc of the program that loads them. Export the symbols
+	// of the program in a dynamic symbol table, so that the plugin package can
+	// resolve the references of the plugin to them.
+	if loadsPlugins {
+		ldflags = append(ldflags, "--export-dynamic", "--hash-style=sysv")
+	}
 
 	// Add embedded files.
 	linkerDependencies = append(linkerDependencies, embedFileObjects...)
 
+	// A C archive is not linked: it is an archive of all object files (except
+	// for the libc, which is provided by the C program) with a header file
+	// declaring the exported functions.
+	if config.BuildMode() == "c-archive" {
+		return buildCArchive(lprogram, linkerDependencies, dir, config, action)
+	}
+
+	// Add libc dependencies, if they exist. A plugin uses the libc of the host
+	// program instead, see the plugin package.
+	if config.BuildMode() != "plugin" {
+		linkerDependencies = append(linkerDependencies, libcDependencies...)
+	}
+
 	// Determine whether the compilation configuration would result in debug
 	// (DWARF) information in the object files.
 	var hasDebug = true
@@ -726,6 +784,16 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		}
 	}
 
+	// Compress debug information with -debug=compressed.
+	if hasDebug && config.DebugCompressed() {
+		if config.Target.Linker == "ld.lld" {
+			ldflags = append(ldflags, "--compress-debug-sections=zlib")
+		} else {
+			// wasm-ld doesn't support compressing debug sections.
+			return errors.New("cannot compress debug information: unsupported linker: " + config.Target.Linker)
+		}
+	}
+
 	// Create a linker job, which links all object files together and does some
 	// extra stuff that can only be done after linking.
 	linkJob := &compileJob{
@@ -779,11 +847,12 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 
 			var calculatedStacks []string
 			var stackSizes map[string]functionStackSize
-			if config.Options.PrintStacks || config.AutomaticStackSize() {
+			var callGraph map[string][]*stacksize.CallNode
+			if config.Options.PrintStacks || config.Options.StackReport || config.AutomaticStackSize() {
 				// Try to determine stack sizes at compile time.
 				// Don't do this by default as it usually doesn't work on
 				// unsupported architectures.
-				calculatedStacks, stackSizes, err = determineStackSizes(mod, executable)
+				calculatedStacks, stackSizes, callGraph, err = determineStackSizes(mod, executable)
 				if err != nil {
 					return err
 				}
@@ -869,6 +938,9 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 			if config.Options.PrintStacks {
 				printStacks(calculatedStacks, stackSizes)
 			}
+			if config.Options.StackReport {
+				printStackUsageReport(os.Stdout, callGraph, calculatedStacks, stackSizes)
+			}
 
 			return nil
 		},
@@ -898,7 +970,7 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	case "uf2":
 		// Get UF2 from the .elf file.
 		tmppath = filepath.Join(dir, "main"+outext)
-		err := convertELFFileToUF2File(executable, tmppath, config.Target.UF2FamilyID)
+		err := convertELFFileToUF2File(executable, tmppath, config.UF2FamilyID())
 		if err != nil {
 			return err
 		}
@@ -933,12 +1005,23 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		moduleroot = lprogram.MainPkg().Root
 	}
 
+	// A plugin exports functions to be called from C, just like a C archive.
+	var headerPath string
+	if config.BuildMode() == "plugin" {
+		headerPath = filepath.Join(dir, "main.h")
+		err := writeCHeader(lprogram, headerPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	return action(BuildResult{
 		Executable: executable,
 		Binary:     tmppath,
 		MainDir:    lprogram.MainPkg().Dir,
 		ModuleRoot: moduleroot,
 		ImportPath: lprogram.MainPkg().ImportPath,
+		CHeader:    headerPath,
 	})
 }
 
@@ -1050,8 +1133,9 @@ func createEmbedObjectFile(data, hexSum, sourceFile, sourceDir, tmpdir string, c
 
 // optimizeProgram runs a series of optimizations and transformations that are
 // needed to convert a program to its final form. Some transformations are not
-// optional and must be run as the compiler expects them to run.
-func optimizeProgram(mod llvm.Module, config *compileopts.Config) error {
+// optional and must be run as the compiler expects them to run. The global
+// values are inserted as if they were passed with -ldflags="-X ...".
+func optimizeProgram(mod llvm.Module, config *compileopts.Config, globalValues map[string]map[string]string) error {
 	err := interp.Run(mod, config.Options.InterpTimeout, config.DumpSSA())
 	if err != nil {
 		return err
@@ -1073,8 +1157,23 @@ func optimizeProgram(mod llvm.Module, config *compileopts.Config) error {
 		transform.ApplyFunctionSections(mod) // -ffunction-sections
 	}
 
+	if config.Options.PGO != "" {
+		// Optimize the functions where most of the time is spent for speed,
+		// and leave the rest optimized for size.
+		prof, err := pgo.ReadFile(config.Options.PGO)
+		if err != nil {
+			return err
+		}
+		transform.MarkHotFunctions(mod, prof.HotFunctions(0.9))
+	}
+
+	if config.Options.HeapProfile {
+		// The heap profiler records stacks by walking frame pointers.
+		transform.KeepFramePointers(mod)
+	}
+
 	// Insert values from -ldflags="-X ..." into the IR.
-	err = setGlobalValues(mod, config.Options.GlobalValues)
+	err = setGlobalValues(mod, globalValues)
 	if err != nil {
 		return err
 	}
@@ -1177,12 +1276,14 @@ type functionStackSize struct {
 	stackSize        uint64
 	stackSizeType    stacksize.SizeType
 	missingStackSize *stacksize.CallNode
+	node             *stacksize.CallNode
 }
 
 // determineStackSizes tries to determine the stack sizes of all started
 // goroutines and of the reset vector. The LLVM module is necessary to find
-// functions that call a function pointer.
-func determineStackSizes(mod llvm.Module, executable string) ([]string, map[string]functionStackSize, error) {
+// functions that call a function pointer. The call graph of the executable is
+// returned as well.
+func determineStackSizes(mod llvm.Module, executable string) ([]string, map[string]functionStackSize, map[string][]*stacksize.CallNode, error) {
 	var callsIndirectFunction []string
 	gowrappers := []string{}
 	gowrapperNames := make(map[string]string)
@@ -1212,21 +1313,21 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 	// Load the ELF binary.
 	f, err := elf.Open(executable)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not load executable for stack size analysis: %w", err)
+		return nil, nil, nil, fmt.Errorf("could not load executable for stack size analysis: %w", err)
 	}
 	defer f.Close()
 
 	// Determine the frame size of each function (if available) and the callgraph.
 	functions, err := stacksize.CallGraph(f, callsIndirectFunction)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not parse executable for stack size analysis: %w", err)
+		return nil, nil, nil, fmt.Errorf("could not parse executable for stack size analysis: %w", err)
 	}
 
 	// Goroutines need to be started and finished and take up some stack space
 	// that way. This can be measured by measuing the stack size of
 	// tinygo_startTask.
 	if numFuncs := len(functions["tinygo_startTask"]); numFuncs != 1 {
-		return nil, nil, fmt.Errorf("expected exactly one definition of tinygo_startTask, got %d", numFuncs)
+		return nil, nil, nil, fmt.Errorf("expected exactly one definition of tinygo_startTask, got %d", numFuncs)
 	}
 	baseStackSize, baseStackSizeType, baseStackSizeFailedAt := functions["tinygo_startTask"][0].StackSize()
 
@@ -1244,7 +1345,7 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 	if resetFunction != "" {
 		funcs := functions[resetFunction]
 		if len(funcs) != 1 {
-			return nil, nil, fmt.Errorf("expected exactly one definition of %s in the callgraph, found %d", resetFunction, len(funcs))
+			return nil, nil, nil, fmt.Errorf("expected exactly one definition of %s in the callgraph, found %d", resetFunction, len(funcs))
 		}
 		stackSize, stackSizeType, missingStackSize := funcs[0].StackSize()
 		sizes[resetFunction] = functionStackSize{
@@ -1252,6 +1353,7 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 			stackSizeType:    stackSizeType,
 			missingStackSize: missingStackSize,
 			humanName:        resetFunction,
+			node:             funcs[0],
 		}
 	}
 
@@ -1259,7 +1361,7 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 	for _, name := range gowrappers {
 		funcs := functions[name]
 		if len(funcs) != 1 {
-			return nil, nil, fmt.Errorf("expected exactly one definition of %s in the callgraph, found %d", name, len(funcs))
+			return nil, nil, nil, fmt.Errorf("expected exactly one definition of %s in the callgraph, found %d", name, len(funcs))
 		}
 		humanName := gowrapperNames[name]
 		if humanName == "" {
@@ -1283,13 +1385,14 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 			stackSizeType:    stackSizeType,
 			missingStackSize: missingStackSize,
 			humanName:        humanName,
+			node:             funcs[0],
 		}
 	}
 
 	if resetFunction != "" {
-		return append([]string{resetFunction}, gowrappers...), sizes, nil
+		return append([]string{resetFunction}, gowrappers...), sizes, functions, nil
 	}
-	return gowrappers, sizes, nil
+	return gowrappers, sizes, functions, nil
 }
 
 // modifyStackSizes modifies the .tinygo_stacksizes section with the updated
@@ -1386,6 +1489,77 @@ func printStacks(calculatedStacks []string, stackSizes map[string]functionStackS
 	}
 }
 
+// printStackUsageReport prints the frame size of each function in the call
+// graph, largest first, followed by the worst-case stack usage of each stack
+// (the reset handler and all goroutines) with the call chain responsible for
+// it. Frame sizes are read from the DWARF call frame information, so functions
+// without it (usually hand-written assembly) are unknown.
+//
+// It might print something like the following:
+//
+//	function                         frame size (in bytes)
+//	runtime.run$1                    48
+//	Reset_Handler                    8
+//	...
+//
+//	stack                            worst-case usage (in bytes)
+//	Reset_Handler                    316
+//	    Reset_Handler                8
+//	    runtime.run$1                48
+//	    ...
+func printStackUsageReport(w io.Writer, callGraph map[string][]*stacksize.CallNode, calculatedStacks []string, stackSizes map[string]functionStackSize) {
+	// Collect all functions. A function may be listed under multiple names
+	// (aliases), so deduplicate them.
+	var nodes []*stacksize.CallNode
+	seen := make(map[*stacksize.CallNode]struct{})
+	unknown := 0
+	for _, funcs := range callGraph {
+		for _, node := range funcs {
+			if _, ok := seen[node]; ok {
+				continue
+			}
+			seen[node] = struct{}{}
+			if node.FrameSizeType != stacksize.Bounded {
+				unknown++
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].FrameSize != nodes[j].FrameSize {
+			return nodes[i].FrameSize > nodes[j].FrameSize
+		}
+		return nodes[i].Names[0] < nodes[j].Names[0]
+	})
+
+	fmt.Fprintf(w, "%-32s %s\n", "function", "frame size (in bytes)")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%-32s %d\n", node.Names[0], node.FrameSize)
+	}
+	if unknown != 0 {
+		fmt.Fprintf(w, "(%d functions without frame size information)\n", unknown)
+	}
+
+	fmt.Fprintf(w, "\n%-32s %s\n", "stack", "worst-case usage (in bytes)")
+	for _, name := range calculatedStacks {
+		fn := stackSizes[name]
+		switch fn.stackSizeType {
+		case stacksize.Bounded:
+			fmt.Fprintf(w, "%-32s %d\n", fn.humanName, fn.stackSize)
+			for _, node := range fn.node.WorstCasePath() {
+				fmt.Fprintf(w, "    %-28s %d\n", node.Names[0], node.FrameSize)
+			}
+		case stacksize.Unknown:
+			fmt.Fprintf(w, "%-32s unknown, %s does not have stack frame information\n", fn.humanName, fn.missingStackSize)
+		case stacksize.Recursive:
+			fmt.Fprintf(w, "%-32s recursive, %s may call itself\n", fn.humanName, fn.missingStackSize)
+		case stacksize.IndirectCall:
+			fmt.Fprintf(w, "%-32s unknown, %s calls a function pointer\n", fn.humanName, fn.missingStackSize)
+		}
+	}
+}
+
 // RP2040 second stage bootloader CRC32 calculation
 //
 // Spec: https://datasheets.raspberrypi.org/rp2040/rp2040-datasheet.pdf
@@ -0,0 +1,76 @@
+package builder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/stacksize"
+)
+
+func TestStackUsageReport(t *testing.T) {
+	// Build a small call graph by hand:
+	//   Reset_Handler -> main.main -> {main.small, main.big -> memcpy}
+	//   main.worker$gowrapper -> main.worker -> main.worker (recursive)
+	node := func(name string, frameSize uint64, children ...*stacksize.CallNode) *stacksize.CallNode {
+		return &stacksize.CallNode{
+			Names:         []string{name},
+			FrameSize:     frameSize,
+			FrameSizeType: stacksize.Bounded,
+			Children:      children,
+		}
+	}
+	memcpy := &stacksize.CallNode{Names: []string{"memcpy", "__aeabi_memcpy"}, FrameSize: 8, FrameSizeType: stacksize.Bounded}
+	small := node("main.small", 16)
+	big := node("main.big", 64, memcpy)
+	mainMain := node("main.main", 24, small, big)
+	reset := node("Reset_Handler", 8, mainMain)
+	worker := node("main.worker", 32)
+	worker.Children = []*stacksize.CallNode{worker}
+	wrapper := node("main.worker$gowrapper", 8, worker)
+	asm := &stacksize.CallNode{Names: []string{"tinygo_scanstack"}}
+
+	callGraph := make(map[string][]*stacksize.CallNode)
+	for _, n := range []*stacksize.CallNode{memcpy, small, big, mainMain, reset, worker, wrapper, asm} {
+		for _, name := range n.Names {
+			callGraph[name] = append(callGraph[name], n)
+		}
+	}
+	stackSizes := make(map[string]functionStackSize)
+	for _, n := range []*stacksize.CallNode{reset, wrapper} {
+		size, sizeType, missing := n.StackSize()
+		stackSizes[n.Names[0]] = functionStackSize{
+			humanName:        n.Names[0],
+			stackSize:        size,
+			stackSizeType:    sizeType,
+			missingStackSize: missing,
+			node:             n,
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	printStackUsageReport(buf, callGraph, []string{"Reset_Handler", "main.worker$gowrapper"}, stackSizes)
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"function                         frame size (in bytes)",
+		"main.big                         64",
+		"main.worker                      32",
+		"main.main                        24",
+		"main.small                       16",
+		"Reset_Handler                    8",
+		"main.worker$gowrapper            8",
+		"memcpy                           8",
+		"(1 functions without frame size information)",
+		"",
+		"stack                            worst-case usage (in bytes)",
+		"Reset_Handler                    104",
+		"    Reset_Handler                8",
+		"    main.main                    24",
+		"    main.big                     64",
+		"    memcpy                       8",
+		"main.worker$gowrapper            recursive, main.worker may call itself",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected report:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,252 @@
+package builder
+
+// This file implements -buildmode=c-archive: a static library with all
+// exported (//export) functions, and a C header file declaring them. The
+// header is also used for -buildmode=plugin, which is linked as a shared
+// object in build.go.
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/compileopts"
+	"github.com/tinygo-org/tinygo/loader"
+	"tinygo.org/x/go-llvm"
+)
+
+// addCArchiveConstructor adds runtime.initCArchive to the list of constructors
+// of the module, so that the runtime is initialized before the main function
+// of the C program runs.
+func addCArchiveConstructor(mod llvm.Module) {
+	ctx := mod.Context()
+	initFn := mod.NamedFunction("runtime.initCArchive")
+	ctorFnType := llvm.PointerType(llvm.FunctionType(ctx.VoidType(), nil, false), 0)
+	i8ptrType := llvm.PointerType(ctx.Int8Type(), 0)
+	ctorType := ctx.StructType([]llvm.Type{ctx.Int32Type(), ctorFnType, i8ptrType}, false)
+	ctor := ctx.ConstStruct([]llvm.Value{
+		llvm.ConstInt(ctx.Int32Type(), 65535, false), // default priority
+		llvm.ConstBitCast(initFn, ctorFnType),
+		llvm.ConstNull(i8ptrType),
+	}, false)
+	ctors := llvm.AddGlobal(mod, llvm.ArrayType(ctorType, 1), "llvm.global_ctors")
+	ctors.SetLinkage(llvm.AppendingLinkage)
+	ctors.SetInitializer(llvm.ConstArray(ctorType, []llvm.Value{ctor}))
+}
+
+// buildCArchive runs the given jobs to compile all object files, and puts them
+// in an archive together with a header file for the exported functions.
+func buildCArchive(lprogram *loader.Program, objectJobs []*compileJob, dir string, config *compileopts.Config, action func(BuildResult) error) error {
+	archivePath := filepath.Join(dir, "main.a")
+	headerPath := filepath.Join(dir, "main.h")
+	archiveJob := &compileJob{
+		description:  "create C archive",
+		dependencies: objectJobs,
+		run: func(job *compileJob) error {
+			var objs []string
+			for _, dependency := range job.dependencies {
+				if dependency.result == "" {
+					return errors.New("dependency without result: " + dependency.description)
+				}
+				objs = append(objs, dependency.result)
+			}
+			f, err := os.Create(archivePath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return makeArchive(f, objs)
+		},
+	}
+	err := runJobs(archiveJob, config.Options.Semaphore)
+	if err != nil {
+		return err
+	}
+
+	err = writeCHeader(lprogram, headerPath)
+	if err != nil {
+		return err
+	}
+
+	// If there's a module root, use that.
+	moduleroot := lprogram.MainPkg().Module.Dir
+	if moduleroot == "" {
+		// if not, just the regular root
+		moduleroot = lprogram.MainPkg().Root
+	}
+
+	return action(BuildResult{
+		Binary:     archivePath,
+		MainDir:    lprogram.MainPkg().Dir,
+		ModuleRoot: moduleroot,
+		ImportPath: lprogram.MainPkg().ImportPath,
+		CHeader:    headerPath,
+	})
+}
+
+// writeCHeader writes a C header file declaring all exported functions of the
+// main package to headerPath. It is used for C archives and plugins.
+func writeCHeader(lprogram *loader.Program, headerPath string) error {
+	header, err := makeCHeader(lprogram)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(headerPath, []byte(header), 0666)
+}
+
+// makeCHeader returns the contents of a C header file with prototypes for all
+// functions that are exported with //export (or //go:export) in the main
+// package. Other packages (like the runtime) also export functions, but those
+// are meant to be called from assembly and are not part of the API of the
+// archive. Only parameter and result types that have a direct C equivalent (such as
+// integers, floats, and pointers) are supported.
+func makeCHeader(lprogram *loader.Program) (string, error) {
+	var prototypes []string
+	var errs []error
+	pkg := lprogram.MainPkg()
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Body == nil || decl.Recv != nil {
+				// Only functions with a body can be called from C.
+				continue
+			}
+			name := exportName(decl)
+			if name == "" {
+				continue
+			}
+			fn := pkg.Pkg.Scope().Lookup(decl.Name.Name).(*types.Func)
+			prototype, err := makeCPrototype(name, fn.Type().(*types.Signature))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: cannot export %s: %w", lprogram.Fset().Position(decl.Pos()), name, err))
+				continue
+			}
+			prototypes = append(prototypes, prototype)
+		}
+	}
+	if len(errs) != 0 {
+		return "", &MultiError{Errs: errs}
+	}
+
+	header := "/* Code generated by TinyGo for package " + pkg.ImportPath + ". DO NOT EDIT. */\n\n"
+	header += "#pragma once\n\n"
+	header += "#include <stdbool.h>\n"
+	header += "#include <stdint.h>\n\n"
+	header += "#ifdef __cplusplus\nextern \"C\" {\n#endif\n\n"
+	for _, prototype := range prototypes {
+		header += prototype + ";\n"
+	}
+	header += "\n#ifdef __cplusplus\n}\n#endif\n"
+	return header, nil
+}
+
+// exportName returns the name with which the function is exported to C, or
+// the empty string if it isn't exported. Functions that are imported from
+// WebAssembly modules are not exported.
+func exportName(decl *ast.FuncDecl) string {
+	if decl.Doc == nil {
+		return ""
+	}
+	name := ""
+	for _, comment := range decl.Doc.List {
+		parts := strings.Fields(comment.Text)
+		if len(parts) == 0 {
+			continue
+		}
+		switch parts[0] {
+		case "//export", "//go:export":
+			if len(parts) == 2 {
+				name = parts[1]
+			}
+		case "//go:wasm-module":
+			return ""
+		}
+	}
+	return name
+}
+
+// makeCPrototype returns the C prototype for a Go function with the given
+// signature, exported with the given name.
+func makeCPrototype(name string, sig *types.Signature) (string, error) {
+	result := "void"
+	switch sig.Results().Len() {
+	case 0:
+	case 1:
+		var err error
+		result, err = cTypeName(sig.Results().At(0).Type())
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.New("multiple return values are not supported")
+	}
+
+	var params []string
+	for i := 0; i < sig.Params().Len(); i++ {
+		param := sig.Params().At(i)
+		typeName, err := cTypeName(param.Type())
+		if err != nil {
+			return "", err
+		}
+		paramName := param.Name()
+		if paramName == "" || paramName == "_" {
+			paramName = fmt.Sprintf("p%d", i)
+		}
+		params = append(params, typeName+" "+paramName)
+	}
+	if len(params) == 0 {
+		params = []string{"void"}
+	}
+	return result + " " + name + "(" + strings.Join(params, ", ") + ")", nil
+}
+
+// cTypeName returns the C type that corresponds to the given Go type when it
+// is passed to or returned from an exported function.
+func cTypeName(typ types.Type) (string, error) {
+	switch typ := typ.Underlying().(type) {
+	case *types.Basic:
+		switch typ.Kind() {
+		case types.Bool:
+			return "bool", nil
+		case types.Int8:
+			return "int8_t", nil
+		case types.Int16:
+			return "int16_t", nil
+		case types.Int32:
+			return "int32_t", nil
+		case types.Int64:
+			return "int64_t", nil
+		case types.Uint8:
+			return "uint8_t", nil
+		case types.Uint16:
+			return "uint16_t", nil
+		case types.Uint32:
+			return "uint32_t", nil
+		case types.Uint64:
+			return "uint64_t", nil
+		case types.Int:
+			// int and uint are pointer-sized in TinyGo.
+			return "intptr_t", nil
+		case types.Uint, types.Uintptr:
+			return "uintptr_t", nil
+		case types.Float32:
+			return "float", nil
+		case types.Float64:
+			return "double", nil
+		case types.UnsafePointer:
+			return "void *", nil
+		}
+	case *types.Pointer:
+		elem, err := cTypeName(typ.Elem())
+		if err != nil || strings.HasSuffix(elem, "*") {
+			// Use an opaque pointer for types that have no C equivalent.
+			return "void *", nil
+		}
+		return elem + " *", nil
+	}
+	return "", fmt.Errorf("unsupported type %s", typ.String())
+}
@@ -13,6 +13,12 @@ import (
 // uses the currently active GOPATH (from the goenv package) to determine the Go
 // version to use.
 func NewConfig(options *compileopts.Options) (*compileopts.Config, error) {
+	if options.Target == "darwin-universal" {
+		// This is not a real target: tinygo build builds the program for each
+		// architecture separately and then combines the executables.
+		return nil, errors.New("-target=darwin-universal can only be used with tinygo build, leave out -target to run, test or flash on macOS")
+	}
+
 	spec, err := compileopts.LoadTarget(options)
 	if err != nil {
 		return nil, err
@@ -39,11 +45,86 @@ func NewConfig(options *compileopts.Options) (*compileopts.Config, error) {
 
 	clangHeaderPath := getClangHeaderPath(goenv.Get("TINYGOROOT"))
 
-	return &compileopts.Config{
+	config := &compileopts.Config{
 		Options:        options,
 		Target:         spec,
 		GoMinorVersion: minor,
 		ClangHeaders:   clangHeaderPath,
 		TestConfig:     options.TestConfig,
-	}, nil
+	}
+
+	if options.HeapGuard {
+		// Guard pages are implemented with mmap in the conservative GC.
+		if config.GC() != "conservative" {
+			return nil, fmt.Errorf("-heap-guard requires -gc=conservative, got -gc=%s", config.GC())
+		}
+		hosted := spec.GOOS == "linux" || spec.GOOS == "darwin"
+		for _, tag := range spec.BuildTags {
+			switch tag {
+			case "baremetal", "tinygo.wasm", "nintendoswitch":
+				hosted = false
+			}
+		}
+		if !hosted {
+			return nil, errors.New("-heap-guard is only supported on Linux and macOS")
+		}
+	}
+
+	if options.StackCheck {
+		// The check compares the stack pointer against the guard region of
+		// the running goroutine, which only exists with separate stacks.
+		switch config.Scheduler() {
+		case "tasks", "external":
+		default:
+			return nil, fmt.Errorf("-stack-check requires -scheduler=tasks or -scheduler=external, got -scheduler=%s", config.Scheduler())
+		}
+	}
+
+	if options.HeapProfile {
+		// The profiler hooks into the conservative GC to find out which
+		// sampled objects have been freed, and records the stack of each
+		// sampled allocation by following the chain of frame pointers.
+		if config.GC() != "conservative" {
+			return nil, fmt.Errorf("-heap-profile requires -gc=conservative, got -gc=%s", config.GC())
+		}
+		hosted := spec.GOOS == "linux" || spec.GOOS == "darwin"
+		for _, tag := range spec.BuildTags {
+			switch tag {
+			case "baremetal", "tinygo.wasm", "nintendoswitch":
+				hosted = false
+			}
+		}
+		if !hosted {
+			return nil, errors.New("-heap-profile is only supported on Linux and macOS")
+		}
+		switch spec.GOARCH {
+		case "386", "amd64", "arm64":
+		default:
+			return nil, fmt.Errorf("-heap-profile is not supported on GOARCH=%s", spec.GOARCH)
+		}
+	}
+
+	if config.BuildsLibrary() {
+		// The runtime is initialized from a constructor, and the archive is
+		// created with an ELF symbol table. Plugins use the libc of the host
+		// program, which must be a TinyGo program (see the plugin package).
+		if spec.GOOS != "linux" || spec.Libc != "musl" {
+			return nil, fmt.Errorf("-buildmode=%s is only supported on Linux", config.BuildMode())
+		}
+		if config.Scheduler() != "none" {
+			return nil, fmt.Errorf("-buildmode=%s requires -scheduler=none, got -scheduler=%s", config.BuildMode(), config.Scheduler())
+		}
+		if options.PIE {
+			return nil, fmt.Errorf("-pie cannot be used with -buildmode=%s", config.BuildMode())
+		}
+	}
+
+	if options.PIE {
+		// Only static PIEs with musl are supported, see Config.LDFlags.
+		if spec.GOOS != "linux" || spec.Libc != "musl" {
+			return nil, errors.New("-pie is only supported on Linux")
+		}
+	}
+
+	return config, nil
 }
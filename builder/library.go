@@ -33,6 +33,10 @@ type Library struct {
 
 	// The source code for the crt1.o file, relative to sourceDir.
 	crt1Source string
+
+	// The source code for the crt1.o file when building a position-independent
+	// executable, relative to sourceDir. It is used instead of crt1Source.
+	pieCrt1Source string
 }
 
 // Load the library archive, possibly generating and caching it if needed.
@@ -162,6 +166,11 @@ func (l *Library) load(config *compileopts.Config, tmpdir string) (job *compileJ
 			args = append(args, "-fshort-enums", "-fomit-frame-pointer", "-mfloat-abi=soft", "-fno-unwind-tables", "-fno-asynchronous-unwind-tables")
 		}
 	}
+	if config.Options.PIE {
+		args = append(args, "-fPIE")
+	} else if config.BuildMode() == "plugin" {
+		args = append(args, "-fPIC")
+	}
 	if strings.HasPrefix(target, "avr") {
 		// AVR defaults to C float and double both being 32-bit. This deviates
 		// from what most code (and certainly compiler-rt) expects. So we need
@@ -248,8 +257,12 @@ func (l *Library) load(config *compileopts.Config, tmpdir string) (job *compileJ
 	// Add this as a (fake) dependency to the ar file so it gets compiled.
 	// (It could be done in parallel with creating the ar file, but it probably
 	// won't make much of a difference in speed).
-	if l.crt1Source != "" {
-		srcpath := filepath.Join(sourceDir, l.crt1Source)
+	crt1Source := l.crt1Source
+	if config.Options.PIE && l.pieCrt1Source != "" {
+		crt1Source = l.pieCrt1Source
+	}
+	if crt1Source != "" {
+		srcpath := filepath.Join(sourceDir, crt1Source)
 		job.dependencies = append(job.dependencies, &compileJob{
 			description: "compile " + srcpath,
 			run: func(*compileJob) error {
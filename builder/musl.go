@@ -161,5 +161,6 @@ var Musl = Library{
 		}
 		return sources
 	},
-	crt1Source: "../crt/crt1.c", // lib/musl/crt/crt1.c
+	crt1Source:    "../crt/crt1.c",  // lib/musl/crt/crt1.c
+	pieCrt1Source: "../crt/rcrt1.c", // lib/musl/crt/rcrt1.c
 }
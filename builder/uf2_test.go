@@ -0,0 +1,48 @@
+package builder
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// Test that every UF2 block carries the requested family ID.
+func TestUF2FamilyID(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	for _, tc := range []struct {
+		familyID string
+		flags    uint32
+		expected uint32
+	}{
+		{"", 0, 0},
+		{"0xe48bff56", flagFamilyIDPresent, 0xe48bff56},
+		{"0x12345678", flagFamilyIDPresent, 0x12345678},
+	} {
+		output, numBlocks, err := convertBinToUF2(data, 0x10000000, tc.familyID)
+		if err != nil {
+			t.Fatalf("failed to convert to UF2 with family ID %q: %v", tc.familyID, err)
+		}
+		if numBlocks != 4 || len(output) != numBlocks*512 {
+			t.Fatalf("unexpected number of blocks: %d (%d bytes)", numBlocks, len(output))
+		}
+		for i := 0; i < numBlocks; i++ {
+			block := output[i*512 : (i+1)*512]
+			if magic := binary.LittleEndian.Uint32(block[0:]); magic != uf2MagicStart0 {
+				t.Errorf("block %d: unexpected magic: %#x", i, magic)
+			}
+			if flags := binary.LittleEndian.Uint32(block[8:]); flags != tc.flags {
+				t.Errorf("block %d: expected flags %#x, got %#x", i, tc.flags, flags)
+			}
+			if familyID := binary.LittleEndian.Uint32(block[28:]); familyID != tc.expected {
+				t.Errorf("block %d: expected family ID %#x, got %#x", i, tc.expected, familyID)
+			}
+		}
+	}
+
+	if _, _, err := convertBinToUF2(data, 0x10000000, "foo"); err == nil {
+		t.Error("expected an error for an invalid family ID")
+	}
+}
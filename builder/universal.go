@@ -0,0 +1,72 @@
+package builder
+
+// This file combines Mach-O executables for different architectures into a
+// single universal (fat) binary, like the lipo tool does on macOS.
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+// MakeUniversalBinary combines the Mach-O executables in inputs, which must
+// all be for a different architecture, into a universal binary at outpath.
+func MakeUniversalBinary(outpath string, inputs []string) error {
+	type slice struct {
+		cpu    macho.Cpu
+		subCpu uint32
+		align  uint32 // as a power of two
+		data   []byte
+	}
+	var slices []slice
+	for _, input := range inputs {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return err
+		}
+		f, err := macho.NewFile(bytes.NewReader(data))
+		if err != nil {
+			return errors.New("cannot create universal binary: " + input + ": " + err.Error())
+		}
+		for _, s := range slices {
+			if s.cpu == f.Cpu {
+				return errors.New("cannot create universal binary: more than one executable for " + f.Cpu.String())
+			}
+		}
+		// Slices are aligned to the page size of the architecture, like lipo
+		// does: 16kB for arm64 and 4kB for others.
+		align := uint32(12)
+		if f.Cpu == macho.CpuArm64 {
+			align = 14
+		}
+		slices = append(slices, slice{cpu: f.Cpu, subCpu: f.SubCpu, align: align, data: data})
+	}
+
+	// The fat header (magic and number of slices), followed by a fat_arch
+	// structure for each slice. All values are big endian.
+	header := []uint32{macho.MagicFat, uint32(len(slices))}
+	offset := uint64(8 + 20*len(slices))
+	for _, s := range slices {
+		alignment := uint64(1) << s.align
+		offset = (offset + alignment - 1) &^ (alignment - 1)
+		size := uint64(len(s.data))
+		if offset+size > 1<<32-1 {
+			return errors.New("cannot create universal binary: too large")
+		}
+		header = append(header, uint32(s.cpu), s.subCpu, uint32(offset), uint32(size), s.align)
+		offset += size
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, header)
+	for _, s := range slices {
+		alignment := 1 << s.align
+		for buf.Len()%alignment != 0 {
+			buf.WriteByte(0)
+		}
+		buf.Write(s.data)
+	}
+	return os.WriteFile(outpath, buf.Bytes(), 0777)
+}
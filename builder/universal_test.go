@@ -0,0 +1,62 @@
+package builder
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test that a universal binary contains all the input executables, at an
+// offset aligned to the page size of each architecture.
+func TestMakeUniversalBinary(t *testing.T) {
+	tmpdir := t.TempDir()
+	var inputs []string
+	contents := map[macho.Cpu][]byte{}
+	for i, cpu := range []macho.Cpu{macho.CpuAmd64, macho.CpuArm64} {
+		// A minimal 64-bit Mach-O executable: only a header, followed by some
+		// data.
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, []uint32{macho.Magic64, uint32(cpu), 3, uint32(macho.TypeExec), 0, 0, 0, 0})
+		for j := 0; j < 5000+i*100; j++ {
+			buf.WriteByte(byte(j + i))
+		}
+		input := filepath.Join(tmpdir, cpu.String())
+		if err := os.WriteFile(input, buf.Bytes(), 0666); err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, input)
+		contents[cpu] = buf.Bytes()
+	}
+
+	outpath := filepath.Join(tmpdir, "universal")
+	if err := MakeUniversalBinary(outpath, inputs); err != nil {
+		t.Fatal("could not create universal binary:", err)
+	}
+
+	output, err := os.ReadFile(outpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := macho.NewFatFile(bytes.NewReader(output))
+	if err != nil {
+		t.Fatal("could not read universal binary:", err)
+	}
+	if len(f.Arches) != 2 {
+		t.Fatalf("expected 2 architectures, got %d", len(f.Arches))
+	}
+	for _, arch := range f.Arches {
+		alignment := uint32(1) << arch.Align
+		if arch.Offset%alignment != 0 || (arch.Cpu == macho.CpuArm64 && alignment != 1<<14) {
+			t.Errorf("%s: unexpected offset %#x with alignment %#x", arch.Cpu, arch.Offset, alignment)
+		}
+		if arch.SubCpu != 3 {
+			t.Errorf("%s: unexpected CPU subtype %d", arch.Cpu, arch.SubCpu)
+		}
+		if !bytes.Equal(output[arch.Offset:arch.Offset+arch.Size], contents[arch.Cpu]) {
+			t.Errorf("%s: contents don't match the input executable", arch.Cpu)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package builder
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"io"
+)
+
+// FlashSegment is a contiguous part of a firmware image, at the address where
+// it is stored in flash.
+type FlashSegment struct {
+	Addr uint64
+	Data []byte
+}
+
+// FlashSegments returns the segments of the given ELF file that are written to
+// flash, at their load address. These are the same segments that are included
+// in .bin and .hex files (see extractROM).
+func FlashSegments(path string) ([]FlashSegment, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, objcopyError{"failed to open ELF file to read flash segments", err}
+	}
+	defer f.Close()
+
+	var segments []FlashSegment
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_LOAD || prog.Filesz == 0 || prog.Off == 0 {
+			continue
+		}
+		data, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return nil, objcopyError{"failed to extract segment from ELF file: " + path, err}
+		}
+		segments = append(segments, FlashSegment{Addr: prog.Paddr, Data: data})
+	}
+	if len(segments) == 0 {
+		return nil, objcopyError{"file does not contain ROM segments: " + path, nil}
+	}
+	return segments, nil
+}
+
+// VerifyFlash reads back every segment with the given read function, which
+// returns the current contents of size bytes of flash at addr, and compares it
+// against the expected contents. It returns an error describing the first
+// difference, if there is one.
+func VerifyFlash(segments []FlashSegment, read func(addr, size uint64) ([]byte, error)) error {
+	for _, segment := range segments {
+		size := uint64(len(segment.Data))
+		actual, err := read(segment.Addr, size)
+		if err != nil {
+			return fmt.Errorf("could not read back %d bytes at address %#x: %w", size, segment.Addr, err)
+		}
+		if uint64(len(actual)) != size {
+			return fmt.Errorf("verify failed: read back %d bytes at address %#x, expected %d", len(actual), segment.Addr, size)
+		}
+		if bytes.Equal(actual, segment.Data) {
+			continue
+		}
+		for i := range segment.Data {
+			if actual[i] != segment.Data[i] {
+				return fmt.Errorf("verify failed: flash contents differ at address %#x: expected %#02x, read %#02x", segment.Addr+uint64(i), segment.Data[i], actual[i])
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test that VerifyFlash reads back every segment and reports the first
+// difference with the expected image.
+func TestVerifyFlash(t *testing.T) {
+	segments := []FlashSegment{
+		{Addr: 0x1000, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{Addr: 0x2000, Data: []byte{9, 10, 11}},
+	}
+
+	// Simulated flash memory.
+	flash := map[uint64]byte{}
+	for _, segment := range segments {
+		for i, b := range segment.Data {
+			flash[segment.Addr+uint64(i)] = b
+		}
+	}
+	var reads []uint64
+	read := func(addr, size uint64) ([]byte, error) {
+		reads = append(reads, addr)
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = flash[addr+uint64(i)]
+		}
+		return data, nil
+	}
+
+	if err := VerifyFlash(segments, read); err != nil {
+		t.Errorf("verify of unchanged flash failed: %v", err)
+	}
+	if len(reads) != 2 || reads[0] != 0x1000 || reads[1] != 0x2000 {
+		t.Errorf("unexpected reads: %#x", reads)
+	}
+
+	// Corrupt a byte in the second segment.
+	flash[0x2001] = 0xff
+	err := VerifyFlash(segments, read)
+	if err == nil || !strings.Contains(err.Error(), "differ at address 0x2001: expected 0x0a, read 0xff") {
+		t.Errorf("unexpected error for corrupted flash: %v", err)
+	}
+
+	// Short reads and read errors must fail as well.
+	err = VerifyFlash(segments, func(addr, size uint64) ([]byte, error) {
+		return make([]byte, size-1), nil
+	})
+	if err == nil {
+		t.Error("expected an error for a short read")
+	}
+	readErr := errors.New("no connection")
+	err = VerifyFlash(segments, func(addr, size uint64) ([]byte, error) {
+		return nil, readErr
+	})
+	if !errors.Is(err, readErr) {
+		t.Errorf("unexpected error for a failed read: %v", err)
+	}
+}
@@ -68,11 +68,33 @@ func (c *Config) GOARM() string {
 }
 
 // BuildTags returns the complete list of build tags used during this build.
+//
+// The tinygo tag is always defined, so that code can be guarded with
+// "//go:build tinygo". The version of TinyGo is available as cumulative release
+// tags like tinygo0.26 (see goenv.ReleaseTags), next to the usual go1.N tags.
+// Tags that start with "tinygo." are set by build flags, like tinygo.heapguard
+// for -heap-guard.
 func (c *Config) BuildTags() []string {
-	tags := append(c.Target.BuildTags, []string{"tinygo", "math_big_pure_go", "gc." + c.GC(), "scheduler." + c.Scheduler(), "serial." + c.Serial()}...)
+	var tags []string
+	tags = append(tags, c.Target.BuildTags...)
+	tags = append(tags, "tinygo", "math_big_pure_go", "gc."+c.GC(), "scheduler."+c.Scheduler(), "serial."+c.Serial())
 	for i := 1; i <= c.GoMinorVersion; i++ {
 		tags = append(tags, fmt.Sprintf("go1.%d", i))
 	}
+	tags = append(tags, goenv.ReleaseTags()...)
+	if c.Options.HeapGuard {
+		tags = append(tags, "tinygo.heapguard")
+	}
+	if c.Options.HeapProfile {
+		tags = append(tags, "tinygo.heapprofile")
+	}
+	if c.StackOverflowCheck() {
+		tags = append(tags, "tinygo.stackcheck")
+	}
+	if c.BuildsLibrary() {
+		// The runtime is initialized from a constructor instead of main.
+		tags = append(tags, "tinygo.carchive")
+	}
 	tags = append(tags, c.Options.Tags...)
 	return tags
 }
@@ -113,11 +135,16 @@ func (c *Config) NeedsStackObjects() bool {
 }
 
 // Scheduler returns the scheduler implementation. Valid values are "none",
-// "asyncify" and "tasks".
+// "asyncify", "tasks" and "external".
 func (c *Config) Scheduler() string {
 	if c.Options.Scheduler != "" {
 		return c.Options.Scheduler
 	}
+	if c.BuildsLibrary() {
+		// Exported functions are called from C on the system stack, so there
+		// is no way to run goroutines.
+		return "none"
+	}
 	if c.Target.Scheduler != "" {
 		return c.Target.Scheduler
 	}
@@ -125,6 +152,23 @@ func (c *Config) Scheduler() string {
 	return "none"
 }
 
+// BuildMode returns the kind of output file: "default" for an executable or
+// firmware image, "c-archive" for a static library to be linked into a C
+// program, or "plugin" for a shared object to be loaded at runtime.
+func (c *Config) BuildMode() string {
+	if c.Options.BuildMode != "" {
+		return c.Options.BuildMode
+	}
+	return "default"
+}
+
+// BuildsLibrary returns whether the output is a library that becomes part of
+// another program (-buildmode=c-archive or -buildmode=plugin), instead of a
+// program with its own main function.
+func (c *Config) BuildsLibrary() bool {
+	return c.BuildMode() == "c-archive" || c.BuildMode() == "plugin"
+}
+
 // Serial returns the serial implementation for this build configuration: uart,
 // usb (meaning USB-CDC), or none.
 func (c *Config) Serial() string {
@@ -165,11 +209,28 @@ func (c *Config) PanicStrategy() string {
 	return c.Options.PanicStrategy
 }
 
+// IntOverflow returns what happens on signed integer overflow: "wrap" (wrap
+// around, as the Go specification requires) or "trap" (panic at the
+// overflowing operation). Standard library packages always wrap.
+func (c *Config) IntOverflow() string {
+	if c.Options.IntOverflow == "" {
+		return "wrap"
+	}
+	return c.Options.IntOverflow
+}
+
+// StackOverflowCheck returns whether the compiler checks for a goroutine stack
+// overflow at the start of every function (-stack-check). Without it, a stack
+// overflow is only detected when the goroutine is paused.
+func (c *Config) StackOverflowCheck() bool {
+	return c.Options.StackCheck
+}
+
 // AutomaticStackSize returns whether goroutine stack sizes should be determined
 // automatically at compile time, if possible. If it is false, no attempt is
 // made.
 func (c *Config) AutomaticStackSize() bool {
-	if c.Target.AutoStackSize != nil && c.Scheduler() == "tasks" {
+	if c.Target.AutoStackSize != nil && (c.Scheduler() == "tasks" || c.Scheduler() == "external") {
 		return *c.Target.AutoStackSize
 	}
 	return false
@@ -199,6 +260,11 @@ func (c *Config) UseThinLTO() bool {
 		// through a plugin, but it's too much hassle to set up.
 		return false
 	}
+	if c.BuildMode() == "c-archive" {
+		// The archive is linked by the C toolchain of the user, which may not
+		// understand LLVM bitcode.
+		return false
+	}
 	// Other architectures support ThinLTO.
 	return true
 }
@@ -231,6 +297,15 @@ func (c *Config) LibcPath(name string) (path string, precompiled bool) {
 		archname += "-" + c.CPU()
 	}
 
+	if c.Options.PIE {
+		// Libraries need to be compiled as position-independent code.
+		archname += "-pie"
+	} else if c.BuildMode() == "plugin" {
+		// Libraries linked into a shared object need to be compiled with
+		// -fPIC.
+		archname += "-pic"
+	}
+
 	// Try to load a precompiled library.
 	precompiledDir := filepath.Join(goenv.Get("TINYGOROOT"), "pkg", archname, name)
 	if _, err := os.Stat(precompiledDir); err == nil {
@@ -247,6 +322,12 @@ func (c *Config) LibcPath(name string) (path string, precompiled bool) {
 // DefaultBinaryExtension returns the default extension for binaries, such as
 // .exe, .wasm, or no extension (depending on the target).
 func (c *Config) DefaultBinaryExtension() string {
+	switch c.BuildMode() {
+	case "c-archive":
+		return ".a"
+	case "plugin":
+		return ".so"
+	}
 	parts := strings.Split(c.Triple(), "-")
 	if parts[0] == "wasm32" {
 		// WebAssembly files always have the .wasm file extension.
@@ -325,6 +406,11 @@ func (c *Config) CFlags() []string {
 	cflags = append(cflags, "-O"+c.Options.Opt)
 	// Set the LLVM target triple.
 	cflags = append(cflags, "--target="+c.Triple())
+	if c.Options.PIE {
+		cflags = append(cflags, "-fPIE")
+	} else if c.BuildsLibrary() {
+		cflags = append(cflags, "-fPIC")
+	}
 	// Set the -mcpu (or similar) flag.
 	if c.Target.CPU != "" {
 		if c.GOARCH() == "amd64" || c.GOARCH() == "386" {
@@ -352,6 +438,18 @@ func (c *Config) LDFlags() []string {
 		ldflags = append(ldflags, strings.ReplaceAll(flag, "{root}", root))
 	}
 	ldflags = append(ldflags, "-L", root)
+	if c.Options.PIE {
+		// Link a static PIE: an ET_DYN executable without dynamic linker that
+		// relocates itself at startup (in rcrt1.o).
+		ldflags = append(ldflags, "-pie", "--no-dynamic-linker", "-z", "text")
+	}
+	if c.BuildMode() == "plugin" {
+		// Bind references to symbols defined in the plugin (like the runtime)
+		// to those definitions, so that they're not interposed by symbols
+		// with the same name in the host program or in other plugins. The
+		// plugin package looks up symbols using the DT_HASH table.
+		ldflags = append(ldflags, "-shared", "-Bsymbolic", "--hash-style=sysv")
+	}
 	if c.Target.LinkerScript != "" {
 		ldflags = append(ldflags, "-T", c.Target.LinkerScript)
 	}
@@ -361,6 +459,18 @@ func (c *Config) LDFlags() []string {
 // ExtraFiles returns the list of extra files to be built and linked with the
 // executable. This can include extra C and assembly files.
 func (c *Config) ExtraFiles() []string {
+	if c.BuildMode() == "plugin" {
+		// All global symbols of a shared object are kept by the linker, so
+		// leave out the goroutine switching code. It refers to the tasks
+		// scheduler, which isn't used in plugins.
+		var files []string
+		for _, file := range c.Target.ExtraFiles {
+			if !strings.HasPrefix(file, "src/internal/task/") {
+				files = append(files, file)
+			}
+		}
+		return files
+	}
 	return c.Target.ExtraFiles
 }
 
@@ -383,6 +493,23 @@ func (c *Config) Debug() bool {
 	return c.Options.Debug
 }
 
+// DebugCompressed returns whether the debug (DWARF) sections should be
+// compressed by the linker, as set with -debug=compressed. The resulting debug
+// information is still read by debuggers and by tinygo addr2line.
+func (c *Config) DebugCompressed() bool {
+	return c.Options.Debug && c.Options.DebugFormat == "compressed"
+}
+
+// UF2FamilyID returns the family ID to store in UF2 files. It can be set with
+// the -uf2-family-id flag, and otherwise comes from the target JSON file. An
+// empty string means no family ID is stored.
+func (c *Config) UF2FamilyID() string {
+	if c.Options.UF2FamilyID != "" {
+		return c.Options.UF2FamilyID
+	}
+	return c.Target.UF2FamilyID
+}
+
 // BinaryFormat returns an appropriate binary format, based on the file
 // extension and the configured binary format in the target JSON file.
 func (c *Config) BinaryFormat(ext string) string {
@@ -491,6 +618,12 @@ func (c *Config) CodeModel() string {
 // RelocationModel returns the relocation model in use on this platform. Valid
 // values are "static", "pic", "dynamicnopic".
 func (c *Config) RelocationModel() string {
+	if c.Options.PIE || c.BuildsLibrary() {
+		// A C archive may be linked into a position-independent executable,
+		// which is the default for most C toolchains. A plugin is a shared
+		// object, which is always position-independent.
+		return "pic"
+	}
 	if c.Target.RelocationModel != "" {
 		return c.Target.RelocationModel
 	}
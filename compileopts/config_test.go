@@ -0,0 +1,45 @@
+package compileopts_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/compileopts"
+	"github.com/tinygo-org/tinygo/goenv"
+)
+
+func TestBuildTags(t *testing.T) {
+	targetTags := make([]string, 2, 10) // room to append without reallocating
+	targetTags[0], targetTags[1] = "cortexm", "baremetal"
+	config := &compileopts.Config{
+		Options:        &compileopts.Options{Tags: []string{"custom"}},
+		Target:         &compileopts.TargetSpec{BuildTags: targetTags, GC: "conservative", Scheduler: "tasks"},
+		GoMinorVersion: 19,
+	}
+	tags := config.BuildTags()
+	has := map[string]bool{}
+	for _, tag := range tags {
+		has[tag] = true
+	}
+	for _, tag := range []string{"cortexm", "baremetal", "tinygo", "go1.1", "go1.19", "gc.conservative", "scheduler.tasks", "custom", "tinygo0.1"} {
+		if !has[tag] {
+			t.Errorf("expected build tag %q, got %v", tag, tags)
+		}
+	}
+	if has["go1.20"] || has["tinygo0.999"] {
+		t.Errorf("unexpected version tags: %v", tags)
+	}
+
+	// The list of tags is cumulative, up to the current TinyGo version.
+	releaseTags := goenv.ReleaseTags()
+	version := goenv.Version[:strings.LastIndexByte(goenv.Version, '.')]
+	if last := releaseTags[len(releaseTags)-1]; last != "tinygo"+version || !has[last] {
+		t.Errorf("unexpected release tags for TinyGo %s: %v", goenv.Version, releaseTags)
+	}
+
+	// The tags of the target must not be modified by BuildTags.
+	config.BuildTags()
+	if len(config.Target.BuildTags) != 2 || targetTags[:3][2] != "" {
+		t.Errorf("BuildTags modified the target build tags: %v", targetTags[:cap(targetTags)])
+	}
+}
@@ -3,17 +3,21 @@ package compileopts
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 var (
 	validGCOptions            = []string{"none", "leaking", "conservative"}
-	validSchedulerOptions     = []string{"none", "tasks", "asyncify"}
+	validSchedulerOptions     = []string{"none", "tasks", "asyncify", "external"}
 	validSerialOptions        = []string{"none", "uart", "usb"}
 	validPrintSizeOptions     = []string{"none", "short", "full"}
 	validPanicStrategyOptions = []string{"print", "trap"}
 	validOptOptions           = []string{"none", "0", "1", "2", "s", "z"}
+	validDebugFormatOptions   = []string{"full", "compressed"}
+	validBuildModeOptions     = []string{"default", "c-archive", "plugin"}
+	validIntOverflowOptions   = []string{"wrap", "trap"}
 )
 
 // Options contains extra options to give to the compiler. These options are
@@ -27,6 +31,7 @@ type Options struct {
 	Opt             string
 	GC              string
 	PanicStrategy   string
+	IntOverflow     string // -int-overflow flag: wrap or trap
 	Scheduler       string
 	StackSize       uint64 // goroutine stack size (if none could be automatically determined)
 	Serial          string
@@ -38,9 +43,17 @@ type Options struct {
 	PrintCommands   func(cmd string, args ...string) `json:"-"`
 	Semaphore       chan struct{}                    `json:"-"` // -p flag controls cap
 	Debug           bool
+	DebugFormat     string // -debug flag: full or compressed
 	PrintSizes      string
 	PrintAllocs     *regexp.Regexp // regexp string
 	PrintStacks     bool
+	StackReport     bool   // -stack-usage-report flag: frame sizes and worst-case stack paths
+	StackCheck      bool   // -stack-check flag: check for goroutine stack overflows in every function
+	HeapGuard       bool   // -heap-guard flag: guard pages around large allocations
+	HeapProfile     bool   // -heap-profile flag: sample heap allocations for runtime.MemProfile
+	PGO             string // -pgo flag: CPU profile for profile-guided optimization
+	PIE             bool   // -pie flag: position-independent executable
+	BuildMode       string // -buildmode flag: default, c-archive or plugin
 	Tags            []string
 	WasmAbi         string
 	GlobalValues    map[string]map[string]string // map[pkgpath]map[varname]value
@@ -52,6 +65,8 @@ type Options struct {
 	PrintJSON       bool
 	Monitor         bool
 	BaudRate        int
+	UF2FamilyID     string // -uf2-family-id flag, overrides the target
+	VerifyFlash     bool   // -verify flag: read back the firmware after flashing
 }
 
 // Verify performs a validation on the given options, raising an error if options are not valid.
@@ -83,6 +98,15 @@ func (o *Options) Verify() error {
 		}
 	}
 
+	if o.BuildMode != "" {
+		valid := isInArray(validBuildModeOptions, o.BuildMode)
+		if !valid {
+			return fmt.Errorf(`invalid buildmode option '%s': valid values are %s`,
+				o.BuildMode,
+				strings.Join(validBuildModeOptions, ", "))
+		}
+	}
+
 	if o.PrintSizes != "" {
 		valid := isInArray(validPrintSizeOptions, o.PrintSizes)
 		if !valid {
@@ -101,12 +125,33 @@ func (o *Options) Verify() error {
 		}
 	}
 
+	if o.IntOverflow != "" {
+		if !isInArray(validIntOverflowOptions, o.IntOverflow) {
+			return fmt.Errorf("invalid -int-overflow=%s: valid values are %s", o.IntOverflow, strings.Join(validIntOverflowOptions, ", "))
+		}
+	}
+
 	if o.Opt != "" {
 		if !isInArray(validOptOptions, o.Opt) {
 			return fmt.Errorf("invalid -opt=%s: valid values are %s", o.Opt, strings.Join(validOptOptions, ", "))
 		}
 	}
 
+	if o.DebugFormat != "" {
+		if !isInArray(validDebugFormatOptions, o.DebugFormat) {
+			return fmt.Errorf("invalid -debug=%s: valid values are %s", o.DebugFormat, strings.Join(validDebugFormatOptions, ", "))
+		}
+		if !o.Debug {
+			return fmt.Errorf("-debug=%s cannot be combined with -no-debug", o.DebugFormat)
+		}
+	}
+
+	if o.UF2FamilyID != "" {
+		if _, err := strconv.ParseUint(o.UF2FamilyID, 0, 32); err != nil {
+			return fmt.Errorf("invalid -uf2-family-id=%s: must be a 32-bit number", o.UF2FamilyID)
+		}
+	}
+
 	return nil
 }
 
@@ -10,9 +10,13 @@ import (
 func TestVerifyOptions(t *testing.T) {
 
 	expectedGCError := errors.New(`invalid gc option 'incorrect': valid values are none, leaking, conservative`)
-	expectedSchedulerError := errors.New(`invalid scheduler option 'incorrect': valid values are none, tasks, asyncify`)
+	expectedSchedulerError := errors.New(`invalid scheduler option 'incorrect': valid values are none, tasks, asyncify, external`)
 	expectedPrintSizeError := errors.New(`invalid size option 'incorrect': valid values are none, short, full`)
 	expectedPanicStrategyError := errors.New(`invalid panic option 'incorrect': valid values are print, trap`)
+	expectedDebugFormatError := errors.New(`invalid -debug=incorrect: valid values are full, compressed`)
+	expectedNoDebugError := errors.New(`-debug=compressed cannot be combined with -no-debug`)
+	expectedIntOverflowError := errors.New(`invalid -int-overflow=incorrect: valid values are wrap, trap`)
+	expectedUF2FamilyIDError := errors.New(`invalid -uf2-family-id=0x123456789: must be a 32-bit number`)
 
 	testCases := []struct {
 		name          string
@@ -111,6 +115,54 @@ func TestVerifyOptions(t *testing.T) {
 				PanicStrategy: "trap",
 			},
 		},
+		{
+			name: "InvalidIntOverflowOption",
+			opts: compileopts.Options{
+				IntOverflow: "incorrect",
+			},
+			expectedError: expectedIntOverflowError,
+		},
+		{
+			name: "IntOverflowOptionTrap",
+			opts: compileopts.Options{
+				IntOverflow: "trap",
+			},
+		},
+		{
+			name: "InvalidDebugFormatOption",
+			opts: compileopts.Options{
+				DebugFormat: "incorrect",
+			},
+			expectedError: expectedDebugFormatError,
+		},
+		{
+			name: "DebugFormatOptionCompressed",
+			opts: compileopts.Options{
+				Debug:       true,
+				DebugFormat: "compressed",
+			},
+		},
+		{
+			name: "DebugFormatOptionWithNoDebug",
+			opts: compileopts.Options{
+				Debug:       false,
+				DebugFormat: "compressed",
+			},
+			expectedError: expectedNoDebugError,
+		},
+		{
+			name: "InvalidUF2FamilyIDOption",
+			opts: compileopts.Options{
+				UF2FamilyID: "0x123456789",
+			},
+			expectedError: expectedUF2FamilyIDError,
+		},
+		{
+			name: "UF2FamilyIDOption",
+			opts: compileopts.Options{
+				UF2FamilyID: "0xe48bff56",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
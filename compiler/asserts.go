@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"go/token"
 	"go/types"
+	"strconv"
+	"strings"
 
+	"github.com/tinygo-org/tinygo/compiler/llvmutil"
 	"golang.org/x/tools/go/ssa"
 	"tinygo.org/x/go-llvm"
 )
@@ -227,6 +230,43 @@ func (b *builder) createDivideByZeroCheck(y llvm.Value) {
 	b.createRuntimeAssert(isZero, "divbyzero", "divideByZeroPanic")
 }
 
+// hasOverflowCheck returns whether signed integer overflow should cause a
+// runtime panic in the current function. This can be enabled for all non-stdlib
+// packages with -int-overflow=trap, and can be overridden per function using
+// //go:overflow trap or //go:overflow wrap. Closures use the setting of the
+// function they are defined in.
+func (b *builder) hasOverflowCheck() bool {
+	fn := b.fn
+	for fn.Parent() != nil {
+		fn = fn.Parent()
+	}
+	switch b.getFunctionInfo(fn).overflow {
+	case overflowTrap:
+		return true
+	case overflowWrap:
+		return false
+	default:
+		return b.overflowTrap
+	}
+}
+
+// createOverflowCheckedOp creates a signed add, sub or mul (as indicated by op,
+// which is one of "sadd", "ssub" or "smul") using the LLVM overflow intrinsics,
+// and panics if the result overflows.
+func (b *builder) createOverflowCheckedOp(op string, x, y llvm.Value) llvm.Value {
+	fnName := "llvm." + op + ".with.overflow.i" + strconv.Itoa(x.Type().IntTypeWidth())
+	llvmFn := b.mod.NamedFunction(fnName)
+	if llvmFn.IsNil() {
+		resultType := b.ctx.StructType([]llvm.Type{x.Type(), b.ctx.Int1Type()}, false)
+		fnType := llvm.FunctionType(resultType, []llvm.Type{x.Type(), x.Type()}, false)
+		llvmFn = llvm.AddFunction(b.mod, fnName, fnType)
+	}
+	result := b.CreateCall(llvmFn, []llvm.Value{x, y}, "")
+	overflow := b.CreateExtractValue(result, 1, "")
+	b.createRuntimeAssert(overflow, "overflow", "overflowPanic")
+	return b.CreateExtractValue(result, 0, "")
+}
+
 // createRuntimeAssert is a common function to create a new branch on an assert
 // bool, calling an assert func if the assert value is true (1).
 func (b *builder) createRuntimeAssert(assert llvm.Value, blockPrefix, assertFunc string) {
@@ -249,15 +289,56 @@ func (b *builder) createRuntimeAssert(assert llvm.Value, blockPrefix, assertFunc
 	// Now branch to the out-of-bounds or the regular block.
 	b.CreateCondBr(assert, faultBlock, nextBlock)
 
-	// Fail: the assert triggered so panic.
+	// Fail: the assert triggered so panic. This panic can be recovered, so it
+	// needs to continue at the landing pad if there is one.
 	b.SetInsertPointAtEnd(faultBlock)
-	b.createRuntimeCall(assertFunc, nil, "")
+	b.createRuntimeInvoke(assertFunc, nil, "")
 	b.CreateUnreachable()
+	b.blockExits[b.currentBlock] = nextBlock // the invoke may have changed it
 
 	// Ok: assert didn't trigger so continue normally.
 	b.SetInsertPointAtEnd(nextBlock)
 }
 
+// createStackOverflowCheck emits a check at the start of the function that the
+// stack pointer is not below internal/task.stackLimit, which is the top of the
+// guard region of the running goroutine (and zero on the system stack). The
+// remaining guard region is used to switch back to the scheduler, which
+// reports the stack overflow.
+func (b *builder) createStackOverflowCheck() {
+	taskPkg := b.program.ImportedPackage("internal/task")
+	stackLimit := b.getGlobal(taskPkg.Members["stackLimit"].(*ssa.Global))
+	limit := b.CreateLoad(stackLimit, "stack.limit")
+	// Mark the stack pointer read, so that the interp package can tell it
+	// apart from other uses of llvm.stacksave.
+	sp := b.readStackPointer()
+	sp.SetMetadata(b.ctx.MDKindID(llvmutil.StackCheckMetadata), b.ctx.MDNode(nil))
+	sp = b.CreatePtrToInt(sp, b.uintptrType, "stack.pointer")
+	overflow := b.CreateICmp(llvm.IntULT, sp, limit, "stack.overflow")
+
+	faultBlock := b.ctx.AddBasicBlock(b.llvmFn, "stackoverflow.throw")
+	nextBlock := b.insertBasicBlock("stackoverflow.next")
+	b.blockExits[b.currentBlock] = nextBlock // adjust outgoing block for phi nodes
+	b.CreateCondBr(overflow, faultBlock, nextBlock)
+
+	// The stack overflow can't be recovered, so no landing pad is needed.
+	b.SetInsertPointAtEnd(faultBlock)
+	stackOverflow := b.getFunction(taskPkg.Members["stackOverflow"].(*ssa.Function))
+	b.createCall(stackOverflow, []llvm.Value{llvm.Undef(b.i8ptrType)}, "")
+	b.CreateUnreachable()
+
+	b.SetInsertPointAtEnd(nextBlock)
+}
+
+// isStackCheckExempt returns whether functions in the given package are never
+// checked for a stack overflow: the runtime, which reports it and mostly runs
+// on the system stack, and the device packages, which contain interrupt
+// vectors that may run on a different stack than the current goroutine.
+func isStackCheckExempt(pkgPath string) bool {
+	return pkgPath == "runtime" || pkgPath == "internal/task" ||
+		strings.HasPrefix(pkgPath, "runtime/") || strings.HasPrefix(pkgPath, "device/")
+}
+
 // extendInteger extends the value to at least targetType using a zero or sign
 // extend. The resulting value is not truncated: it may still be bigger than
 // targetType.
@@ -47,7 +47,7 @@ func (b *builder) createChanSend(instr *ssa.Send) {
 	channelBlockedListAlloca, channelBlockedListAllocaCast, channelBlockedListAllocaSize := b.createTemporaryAlloca(channelBlockedList, "chan.blockedList")
 
 	// Do the send.
-	b.createRuntimeCall("chanSend", []llvm.Value{ch, valueAllocaCast, channelBlockedListAlloca}, "")
+	b.createRuntimeInvoke("chanSend", []llvm.Value{ch, valueAllocaCast, channelBlockedListAlloca}, "")
 
 	// End the lifetime of the allocas.
 	// This also works around a bug in CoroSplit, at least in LLVM 8:
@@ -101,7 +101,7 @@ func (b *builder) createChanRecv(unop *ssa.UnOp) llvm.Value {
 
 // createChanClose closes the given channel.
 func (b *builder) createChanClose(ch llvm.Value) {
-	b.createRuntimeCall("chanClose", []llvm.Value{ch}, "")
+	b.createRuntimeInvoke("chanClose", []llvm.Value{ch}, "")
 }
 
 // createSelect emits all IR necessary for a select statements. That's a
@@ -126,6 +126,17 @@ func (b *builder) createSelect(expr *ssa.Select) llvm.Value {
 		}
 	}
 
+	if !expr.Blocking && len(expr.States) == 1 {
+		// A select with a single case and a default case, which is a common
+		// way to do a non-blocking send or receive:
+		//     select {
+		//     case ch <- v:
+		//     default:
+		//     }
+		// This can be done without constructing a list of select states.
+		return b.createSelectNonBlocking(expr)
+	}
+
 	// This code create a (stack-allocated) slice containing all the select
 	// cases and then calls runtime.chanSelect to perform the actual select
 	// statement.
@@ -240,6 +251,52 @@ func (b *builder) createSelect(expr *ssa.Select) llvm.Value {
 	return results
 }
 
+// createSelectNonBlocking lowers a select statement with a single send or
+// receive case and a default case to a call to runtime.chanTrySend or
+// runtime.chanTryRecv. These never block and never yield to the scheduler. The
+// returned value has the same form as the result of runtime.tryChanSelect.
+func (b *builder) createSelectNonBlocking(expr *ssa.Select) llvm.Value {
+	state := expr.States[0]
+	ch := b.getValue(state.Chan)
+	recvbuf := llvm.Undef(b.i8ptrType)
+	var selected, commaOk llvm.Value
+	switch state.Dir {
+	case types.RecvOnly:
+		// Create a receive buffer, where the received value will be stored.
+		llvmType := b.getLLVMType(state.Chan.Type().Underlying().(*types.Chan).Elem())
+		if b.targetData.TypeAllocSize(llvmType) != 0 {
+			_, recvbuf, _ = b.createTemporaryAlloca(llvmType, "select.recvbuf")
+		}
+		result := b.createRuntimeCall("chanTryRecv", []llvm.Value{ch, recvbuf}, "select.result")
+		selected = b.CreateExtractValue(result, 0, "select.received")
+		commaOk = b.CreateExtractValue(result, 1, "select.ok")
+	case types.SendOnly:
+		sendValue := b.getValue(state.Send)
+		alloca := llvmutil.CreateEntryBlockAlloca(b.Builder, sendValue.Type(), "select.send.value")
+		b.CreateStore(sendValue, alloca)
+		ptr := b.CreateBitCast(alloca, b.i8ptrType, "")
+		selected = b.createRuntimeCall("chanTrySend", []llvm.Value{ch, ptr}, "select.sent")
+		commaOk = selected
+	default:
+		panic("unreachable")
+	}
+
+	// Construct the {index, ok} result: the index is 0 when the case was
+	// selected and -1 when the default case should be taken.
+	index := b.CreateSelect(selected, llvm.ConstInt(b.uintptrType, 0, false), llvm.ConstInt(b.uintptrType, 0xffffffffffffffff, true), "select.index")
+	results := llvm.Undef(b.ctx.StructType([]llvm.Type{b.uintptrType, b.ctx.Int1Type()}, false))
+	results = b.CreateInsertValue(results, index, 0, "")
+	results = b.CreateInsertValue(results, commaOk, 1, "")
+
+	// See createSelect for why the receive buffer is stored in a sidetable.
+	if b.selectRecvBuf == nil {
+		b.selectRecvBuf = make(map[*ssa.Select]llvm.Value)
+	}
+	b.selectRecvBuf[expr] = recvbuf
+
+	return results
+}
+
 // getChanSelectResult returns the special values from a *ssa.Extract expression
 // when extracting a value from a select statement (*ssa.Select). Because
 // *ssa.Select cannot load all values in advance, it does this later in the
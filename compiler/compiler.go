@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/tinygo-org/tinygo/compiler/llvmutil"
 	"github.com/tinygo-org/tinygo/loader"
@@ -52,6 +53,8 @@ type Config struct {
 	AutomaticStackSize bool
 	DefaultStackSize   uint64
 	NeedsStackObjects  bool
+	IntOverflowTrap    bool // Panic on signed integer overflow (outside the standard library).
+	StackOverflowCheck bool // Check the goroutine stack limit at the start of every function (outside the runtime).
 	Debug              bool // Whether to emit debug information in the LLVM module.
 }
 
@@ -82,6 +85,8 @@ type compilerContext struct {
 	pkg              *types.Package
 	packageDir       string // directory for this package
 	runtimePkg       *types.Package
+	overflowTrap     bool // panic on signed integer overflow in this package
+	stackCheck       bool // check for goroutine stack overflows in this package
 }
 
 // newCompilerContext returns a new compiler context ready for use, most
@@ -255,6 +260,8 @@ func CompilePackage(moduleName string, pkg *loader.Package, ssaPkg *ssa.Package,
 	c := newCompilerContext(moduleName, machine, config, dumpSSA)
 	c.packageDir = pkg.OriginalDir()
 	c.embedGlobals = pkg.EmbedGlobals
+	c.overflowTrap = config.IntOverflowTrap && !pkg.Standard
+	c.stackCheck = config.StackOverflowCheck && !isStackCheckExempt(pkg.Pkg.Path())
 	c.pkg = pkg.Pkg
 	c.runtimePkg = ssaPkg.Prog.ImportedPackage("runtime").Pkg
 	c.program = ssaPkg.Prog
@@ -850,7 +857,13 @@ func (c *compilerContext) createPackage(irbuilder llvm.Builder, pkg *ssa.Package
 				c.createEmbedGlobal(member, global, files)
 			} else if !info.extern {
 				global.SetInitializer(llvm.ConstNull(global.Type().ElementType()))
-				global.SetVisibility(llvm.HiddenVisibility)
+				// Globals in a .noinit section are not zeroed at startup, so
+				// that they keep their value across a reset. Don't mark them
+				// hidden: they would be internalized, after which the
+				// optimizer assumes they start out as zero.
+				if !llvmutil.IsNoInitSection(info.section) {
+					global.SetVisibility(llvm.HiddenVisibility)
+				}
 				if info.section != "" {
 					global.SetSection(info.section)
 				}
@@ -1059,6 +1072,9 @@ func (b *builder) createFunctionStart(intrinsic bool) {
 	if b.info.section != "" {
 		b.llvmFn.SetSection(b.info.section)
 	}
+	if b.info.weak {
+		b.llvmFn.SetLinkage(llvm.WeakAnyLinkage)
+	}
 	if b.info.exported && strings.HasPrefix(b.Triple, "wasm") {
 		// Set the exported name. This is necessary for WebAssembly because
 		// otherwise the function is not exported.
@@ -1207,6 +1223,9 @@ func (b *builder) createFunction() {
 		}
 		b.SetInsertPointAtEnd(b.blockEntries[block])
 		b.currentBlock = block
+		if b.stackCheck && block == b.fn.Blocks[0] {
+			b.createStackOverflowCheck()
+		}
 		for _, instr := range block.Instrs {
 			if instr, ok := instr.(*ssa.DebugRef); ok {
 				if !b.Debug {
@@ -1341,6 +1360,11 @@ func (b *builder) createInstruction(instr ssa.Instruction) {
 
 	switch instr := instr.(type) {
 	case ssa.Value:
+		if isMergedStringConcat(instr) {
+			// This value is created as part of the string concatenation that
+			// uses it, see createStringConcat.
+			return
+		}
 		if value, err := b.createExpr(instr); err != nil {
 			// This expression could not be parsed. Add the error to the list
 			// of diagnostics and continue with an undef value.
@@ -1834,6 +1858,11 @@ func (b *builder) createExpr(expr ssa.Value) (llvm.Value, error) {
 			return buf, nil
 		}
 	case *ssa.BinOp:
+		if expr.Op == token.ADD && isStringType(expr.Type()) {
+			if operands := b.stringConcatOperands(expr, nil); len(operands) > 2 {
+				return b.createStringConcat(operands), nil
+			}
+		}
 		x := b.getValue(expr.X)
 		y := b.getValue(expr.Y)
 		return b.createBinOp(expr.Op, expr.X.Type(), expr.Y.Type(), x, y, expr.Pos())
@@ -2276,6 +2305,79 @@ func (b *builder) createExpr(expr ssa.Value) (llvm.Value, error) {
 	}
 }
 
+// isStringType returns whether the underlying type of t is a string.
+func isStringType(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// isMergedStringConcat returns whether v is a string concatenation that is
+// only used by another string concatenation in the same block, like a + b in
+// a + b + c. Such chains are lowered to a single runtime call, so that the
+// result is allocated only once instead of once for every + operator.
+func isMergedStringConcat(v ssa.Value) bool {
+	binop, ok := v.(*ssa.BinOp)
+	if !ok || binop.Op != token.ADD || !isStringType(binop.Type()) {
+		return false
+	}
+	var user *ssa.BinOp
+	for _, ref := range *binop.Referrers() {
+		if ref, ok := ref.(*ssa.DebugRef); ok {
+			if !ref.IsAddr {
+				// A named intermediate value, like x in x := a + b; x + c.
+				// Its value is needed for the debug info, so it must be
+				// created separately.
+				return false
+			}
+			continue
+		}
+		refBinop, ok := ref.(*ssa.BinOp)
+		if user != nil || !ok || refBinop.Op != token.ADD || !isStringType(refBinop.Type()) || refBinop.Block() != binop.Block() {
+			return false
+		}
+		user = refBinop
+	}
+	return user != nil
+}
+
+// stringConcatOperands appends the operands of the given string concatenation
+// to operands. The operands of merged concatenations (see
+// isMergedStringConcat) are included as well, in order.
+func (b *builder) stringConcatOperands(expr *ssa.BinOp, operands []llvm.Value) []llvm.Value {
+	for _, operand := range []ssa.Value{expr.X, expr.Y} {
+		if isMergedStringConcat(operand) {
+			operands = b.stringConcatOperands(operand.(*ssa.BinOp), operands)
+		} else {
+			operands = append(operands, b.getValue(operand))
+		}
+	}
+	return operands
+}
+
+// createStringConcat concatenates all the given strings with a single call to
+// runtime.stringConcatN, which only allocates the result once. The strings are
+// passed in a stack allocated slice.
+func (b *builder) createStringConcat(operands []llvm.Value) llvm.Value {
+	stringType := b.getLLVMRuntimeType("_string")
+	allocaType := llvm.ArrayType(stringType, len(operands))
+	alloca, allocaI8, allocaSize := b.createTemporaryAlloca(allocaType, "concat.strings.alloca")
+	for i, operand := range operands {
+		gep := b.CreateGEP(alloca, []llvm.Value{
+			llvm.ConstInt(b.ctx.Int32Type(), 0, false),
+			llvm.ConstInt(b.ctx.Int32Type(), uint64(i), false),
+		}, "")
+		b.CreateStore(operand, gep)
+	}
+	ptr := b.CreateGEP(alloca, []llvm.Value{
+		llvm.ConstInt(b.ctx.Int32Type(), 0, false),
+		llvm.ConstInt(b.ctx.Int32Type(), 0, false),
+	}, "concat.strings")
+	length := llvm.ConstInt(b.uintptrType, uint64(len(operands)), false)
+	result := b.createRuntimeCall("stringConcatN", []llvm.Value{ptr, length, length}, "")
+	b.emitLifetimeEnd(allocaI8, allocaSize)
+	return result
+}
+
 // createBinOp creates a LLVM binary operation (add, sub, mul, etc) for a Go
 // binary operation. This is almost a direct mapping, but there are some subtle
 // differences such as the requirement in LLVM IR that both sides must have the
@@ -2290,10 +2392,19 @@ func (b *builder) createBinOp(op token.Token, typ, ytyp types.Type, x, y llvm.Va
 			signed := typ.Info()&types.IsUnsigned == 0
 			switch op {
 			case token.ADD: // +
+				if signed && b.hasOverflowCheck() {
+					return b.createOverflowCheckedOp("sadd", x, y), nil
+				}
 				return b.CreateAdd(x, y, ""), nil
 			case token.SUB: // -
+				if signed && b.hasOverflowCheck() {
+					return b.createOverflowCheckedOp("ssub", x, y), nil
+				}
 				return b.CreateSub(x, y, ""), nil
 			case token.MUL: // *
+				if signed && b.hasOverflowCheck() {
+					return b.createOverflowCheckedOp("smul", x, y), nil
+				}
 				return b.CreateMul(x, y, ""), nil
 			case token.QUO, token.REM: // /, %
 				// Check for a divide by zero. If y is zero, the Go
@@ -2512,7 +2623,11 @@ func (b *builder) createBinOp(op token.Token, typ, ytyp types.Type, x, y llvm.Va
 			case token.QUO:
 				// Complex division.
 				// Do this in a library call because it's too difficult to do
-				// inline.
+				// inline. Constant operands are folded here, as LLVM can't
+				// look through the library call.
+				if !r1.IsAConstantFP().IsNil() && !i1.IsAConstantFP().IsNil() && !r2.IsAConstantFP().IsNil() && !i2.IsAConstantFP().IsNil() {
+					return b.createConstComplexDiv(r1, i1, r2, i2), nil
+				}
 				switch r1.Type().TypeKind() {
 				case llvm.FloatTypeKind:
 					return b.createRuntimeCall("complex64div", []llvm.Value{x, y}, ""), nil
@@ -2600,7 +2715,7 @@ func (b *builder) createBinOp(op token.Token, typ, ytyp types.Type, x, y llvm.Va
 				result = b.CreateICmp(llvm.IntEQ, typecodeX, typecodeY, "")
 			} else {
 				// Fall back to a full interface comparison.
-				result = b.createRuntimeCall("interfaceEqual", []llvm.Value{x, y}, "")
+				result = b.createRuntimeInvoke("interfaceEqual", []llvm.Value{x, y}, "")
 			}
 			if op == token.NEQ {
 				result = b.CreateNot(result, "")
@@ -2692,6 +2807,27 @@ func (b *builder) createBinOp(op token.Token, typ, ytyp types.Type, x, y llvm.Va
 	}
 }
 
+// createConstComplexDiv folds the division of two constant complex numbers,
+// given as their real and imaginary components. The result is the same as
+// what the runtime complex64div and complex128div functions would return,
+// because they use the same algorithm as the Go compiler running this code.
+func (b *builder) createConstComplexDiv(r1, i1, r2, i2 llvm.Value) llvm.Value {
+	fr1, _ := r1.DoubleValue()
+	fi1, _ := i1.DoubleValue()
+	fr2, _ := r2.DoubleValue()
+	fi2, _ := i2.DoubleValue()
+	result := complex(fr1, fi1) / complex(fr2, fi2)
+	if r1.Type().TypeKind() == llvm.FloatTypeKind {
+		// complex64div calculates in complex128 precision and then truncates
+		// the result.
+		result = complex128(complex64(result))
+	}
+	return b.ctx.ConstStruct([]llvm.Value{
+		llvm.ConstFloat(r1.Type(), real(result)),
+		llvm.ConstFloat(r1.Type(), imag(result)),
+	}, false)
+}
+
 // createConst creates a LLVM constant value from a Go constant.
 func (c *compilerContext) createConst(expr *ssa.Const) llvm.Value {
 	switch typ := expr.Type().Underlying().(type) {
@@ -2860,8 +2996,15 @@ func (b *builder) createConvert(typeFrom, typeTo types.Type, value llvm.Value, p
 				// Cast to an i32 value as expected by
 				// runtime.stringFromUnicode.
 				if sizeFrom > 4 {
+					// Values that don't fit in a rune must not be truncated
+					// to a valid code point. Replace all values outside the
+					// Unicode range (including negative values) with the
+					// Unicode replacement character.
+					maxRune := llvm.ConstInt(value.Type(), unicode.MaxRune, false)
+					isValid := b.CreateICmp(llvm.IntULE, value, maxRune, "")
 					value = b.CreateTrunc(value, b.ctx.Int32Type(), "")
-				} else if sizeFrom < 4 && typeTo.Info()&types.IsUnsigned != 0 {
+					value = b.CreateSelect(isValid, value, llvm.ConstInt(b.ctx.Int32Type(), unicode.ReplacementChar, false), "")
+				} else if sizeFrom < 4 && typeFrom.Info()&types.IsUnsigned != 0 {
 					value = b.CreateZExt(value, b.ctx.Int32Type(), "")
 				} else if sizeFrom < 4 {
 					value = b.CreateSExt(value, b.ctx.Int32Type(), "")
@@ -3049,7 +3192,12 @@ func (b *builder) createUnOp(unop *ssa.UnOp) (llvm.Value, error) {
 	case token.SUB: // -x
 		if typ, ok := unop.X.Type().Underlying().(*types.Basic); ok {
 			if typ.Info()&types.IsInteger != 0 {
-				return b.CreateSub(llvm.ConstInt(x.Type(), 0, false), x, ""), nil
+				zero := llvm.ConstInt(x.Type(), 0, false)
+				if typ.Info()&types.IsUnsigned == 0 && b.hasOverflowCheck() {
+					// Negating the most negative value overflows.
+					return b.createOverflowCheckedOp("ssub", zero, x), nil
+				}
+				return b.CreateSub(zero, x, ""), nil
 			} else if typ.Info()&types.IsFloat != 0 {
 				return b.CreateFNeg(x, ""), nil
 			} else if typ.Info()&types.IsComplex != 0 {
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/tinygo-org/tinygo/compileopts"
+	"github.com/tinygo-org/tinygo/compiler/llvmutil"
 	"github.com/tinygo-org/tinygo/loader"
 	"tinygo.org/x/go-llvm"
 )
@@ -34,6 +35,7 @@ func TestCompiler(t *testing.T) {
 		{"slice.go", "", ""},
 		{"string.go", "", ""},
 		{"float.go", "", ""},
+		{"complex.go", "", ""},
 		{"interface.go", "", ""},
 		{"func.go", "", ""},
 		{"defer.go", "cortex-m-qemu", ""},
@@ -46,9 +48,7 @@ func TestCompiler(t *testing.T) {
 
 	for _, tc := range tests {
 		name := tc.file
-		targetString := "wasm"
 		if tc.target != "" {
-			targetString = tc.target
 			name += "-" + tc.target
 		}
 		if tc.scheduler != "" {
@@ -56,66 +56,11 @@ func TestCompiler(t *testing.T) {
 		}
 
 		t.Run(name, func(t *testing.T) {
-			options := &compileopts.Options{
-				Target: targetString,
-			}
-			target, err := compileopts.LoadTarget(options)
-			if err != nil {
-				t.Fatal("failed to load target:", err)
-			}
-			if tc.scheduler != "" {
-				options.Scheduler = tc.scheduler
-			}
-			config := &compileopts.Config{
-				Options: options,
-				Target:  target,
-			}
-			compilerConfig := &Config{
-				Triple:             config.Triple(),
-				Features:           config.Features(),
-				GOOS:               config.GOOS(),
-				GOARCH:             config.GOARCH(),
-				CodeModel:          config.CodeModel(),
-				RelocationModel:    config.RelocationModel(),
-				Scheduler:          config.Scheduler(),
-				AutomaticStackSize: config.AutomaticStackSize(),
-				DefaultStackSize:   config.StackSize(),
-				NeedsStackObjects:  config.NeedsStackObjects(),
-			}
-			machine, err := NewTargetMachine(compilerConfig)
-			if err != nil {
-				t.Fatal("failed to create target machine:", err)
-			}
-			defer machine.Dispose()
-
-			// Load entire program AST into memory.
-			lprogram, err := loader.Load(config, "./testdata/"+tc.file, config.ClangHeaders, types.Config{
-				Sizes: Sizes(machine),
-			})
-			if err != nil {
-				t.Fatal("failed to create target machine:", err)
-			}
-			err = lprogram.Parse()
-			if err != nil {
-				t.Fatalf("could not parse test case %s: %s", tc.file, err)
-			}
-
-			// Compile AST to IR.
-			program := lprogram.LoadSSA()
-			pkg := lprogram.MainPkg()
-			mod, errs := CompilePackage(tc.file, pkg, program.Package(pkg.Pkg), machine, compilerConfig, false)
-			if errs != nil {
-				for _, err := range errs {
-					t.Error(err)
-				}
+			mod := testCompilePackage(t, tc)
+			if mod.IsNil() {
 				return
 			}
 
-			err = llvm.VerifyModule(mod, llvm.PrintMessageAction)
-			if err != nil {
-				t.Error(err)
-			}
-
 			// Optimize IR a little.
 			funcPasses := llvm.NewFunctionPassManagerForModule(mod)
 			defer funcPasses.Dispose()
@@ -156,6 +101,235 @@ func TestCompiler(t *testing.T) {
 	}
 }
 
+// Test that //go:inline and //go:noinline are honored by the inliner. The
+// inline hint raises the inline threshold, so inlineFunc is inlined while
+// plainFunc of the same size is not.
+func TestInlinePragmas(t *testing.T) {
+	t.Parallel()
+
+	mod := testCompilePackage(t, testCase{"pragma.go", "", ""})
+	if mod.IsNil() {
+		return
+	}
+
+	// Optimize like -opt=2 does.
+	builder := llvm.NewPassManagerBuilder()
+	defer builder.Dispose()
+	builder.SetOptLevel(2)
+	builder.SetSizeLevel(0)
+	builder.UseInlinerWithThreshold(225)
+	modPasses := llvm.NewPassManager()
+	defer modPasses.Dispose()
+	builder.Populate(modPasses)
+	modPasses.Run(mod)
+
+	// Check which calls remain.
+	calls := map[string]bool{}
+	fn := mod.NamedFunction("main.callInlinePragmas")
+	for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+		for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+			if !inst.IsACallInst().IsNil() {
+				calls[inst.CalledValue().Name()] = true
+			}
+		}
+	}
+	if calls["main.inlineFunc"] || !calls["main.inlineCallee"] {
+		t.Errorf("//go:inline function was not inlined, calls: %v", calls)
+	}
+	if !calls["main.noinlineFunc"] {
+		t.Errorf("//go:noinline function was inlined, calls: %v", calls)
+	}
+	if !calls["main.plainFunc"] {
+		t.Errorf("function without pragma was inlined, calls: %v", calls)
+	}
+}
+
+// Test that signed integer overflow panics with -int-overflow=trap and wraps
+// around otherwise, and that //go:overflow overrides the default.
+func TestIntOverflow(t *testing.T) {
+	t.Parallel()
+
+	for _, trap := range []bool{false, true} {
+		mod := testCompilePackageConfig(t, testCase{"intoverflow.go", "", ""}, func(config *Config) {
+			config.IntOverflowTrap = trap
+		})
+		if mod.IsNil() {
+			return
+		}
+
+		for _, tc := range []struct {
+			fn        string
+			intrinsic string // empty if the operation always wraps
+			pragma    bool   // checked because of //go:overflow trap
+		}{
+			{"main.addInt", "llvm.sadd.with.overflow.i32", false},
+			{"main.subInt8", "llvm.ssub.with.overflow.i8", false},
+			{"main.mulInt32", "llvm.smul.with.overflow.i32", false},
+			{"main.negInt64", "llvm.ssub.with.overflow.i64", false},
+			{"main.addUint32", "", false},
+			{"main.addWrap", "", false},
+			{"main.addTrap", "llvm.sadd.with.overflow.i32", true},
+			{"main.addTrapClosure$1", "llvm.sadd.with.overflow.i32", true},
+		} {
+			expected := tc.intrinsic
+			if !trap && !tc.pragma {
+				expected = ""
+			}
+			fn := mod.NamedFunction(tc.fn)
+			if fn.IsNil() {
+				t.Errorf("function %s not found", tc.fn)
+				continue
+			}
+			calls := map[string]bool{}
+			for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+				for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+					if !inst.IsACallInst().IsNil() {
+						calls[inst.CalledValue().Name()] = true
+					}
+				}
+			}
+			if expected == "" {
+				if calls["runtime.overflowPanic"] {
+					t.Errorf("trap=%v: %s checks for overflow, calls: %v", trap, tc.fn, calls)
+				}
+			} else if !calls[expected] || !calls["runtime.overflowPanic"] {
+				t.Errorf("trap=%v: %s does not check for overflow using %s, calls: %v", trap, tc.fn, expected, calls)
+			}
+		}
+	}
+}
+
+// Test that the stack overflow check is only emitted at the start of every
+// function when it is enabled.
+func TestStackOverflowCheck(t *testing.T) {
+	t.Parallel()
+
+	for _, check := range []bool{false, true} {
+		mod := testCompilePackageConfig(t, testCase{"goroutine.go", "cortex-m-qemu", "tasks"}, func(config *Config) {
+			config.StackOverflowCheck = check
+		})
+		if mod.IsNil() {
+			return
+		}
+
+		for _, name := range []string{"main.regularFunctionGoroutine", "main.closureFunctionGoroutine$1", "main.recoverBuiltinGoroutine"} {
+			fn := mod.NamedFunction(name)
+			if fn.IsNil() {
+				t.Errorf("function %s not found", name)
+				continue
+			}
+			entry := fn.EntryBasicBlock()
+			checked := false
+			marked := false
+			for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+				for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+					if inst.IsACallInst().IsNil() {
+						continue
+					}
+					switch inst.CalledValue().Name() {
+					case "internal/task.stackOverflow":
+						checked = true
+					case "llvm.stacksave":
+						// The interp package recognizes the stack pointer of the
+						// check by its metadata.
+						if !inst.Metadata(mod.Context().MDKindID(llvmutil.StackCheckMetadata)).IsNil() {
+							marked = true
+						}
+					}
+				}
+			}
+			if checked != check {
+				t.Errorf("check=%v: %s calls internal/task.stackOverflow: %v", check, name, checked)
+			}
+			if marked != check {
+				t.Errorf("check=%v: %s reads the stack pointer with %s metadata: %v", check, name, llvmutil.StackCheckMetadata, marked)
+			}
+			if check && entry.LastInstruction().IsABranchInst().IsNil() {
+				t.Errorf("check=%v: %s does not branch at the end of the entry block", check, name)
+			}
+		}
+	}
+}
+
+// testCompilePackage compiles the given test case to LLVM IR, without
+// optimizing it. It returns a nil module when compilation fails.
+func testCompilePackage(t *testing.T, tc testCase) llvm.Module {
+	return testCompilePackageConfig(t, tc, nil)
+}
+
+// testCompilePackageConfig is like testCompilePackage, but calls configure (if
+// not nil) to modify the compiler configuration before compiling.
+func testCompilePackageConfig(t *testing.T, tc testCase, configure func(*Config)) llvm.Module {
+	targetString := "wasm"
+	if tc.target != "" {
+		targetString = tc.target
+	}
+	options := &compileopts.Options{
+		Target: targetString,
+	}
+	target, err := compileopts.LoadTarget(options)
+	if err != nil {
+		t.Fatal("failed to load target:", err)
+	}
+	if tc.scheduler != "" {
+		options.Scheduler = tc.scheduler
+	}
+	config := &compileopts.Config{
+		Options: options,
+		Target:  target,
+	}
+	compilerConfig := &Config{
+		Triple:             config.Triple(),
+		Features:           config.Features(),
+		GOOS:               config.GOOS(),
+		GOARCH:             config.GOARCH(),
+		CodeModel:          config.CodeModel(),
+		RelocationModel:    config.RelocationModel(),
+		Scheduler:          config.Scheduler(),
+		AutomaticStackSize: config.AutomaticStackSize(),
+		DefaultStackSize:   config.StackSize(),
+		NeedsStackObjects:  config.NeedsStackObjects(),
+	}
+	if configure != nil {
+		configure(compilerConfig)
+	}
+	machine, err := NewTargetMachine(compilerConfig)
+	if err != nil {
+		t.Fatal("failed to create target machine:", err)
+	}
+	defer machine.Dispose()
+
+	// Load entire program AST into memory.
+	lprogram, err := loader.Load(config, "./testdata/"+tc.file, config.ClangHeaders, types.Config{
+		Sizes: Sizes(machine),
+	})
+	if err != nil {
+		t.Fatal("failed to create target machine:", err)
+	}
+	err = lprogram.Parse()
+	if err != nil {
+		t.Fatalf("could not parse test case %s: %s", tc.file, err)
+	}
+
+	// Compile AST to IR.
+	program := lprogram.LoadSSA()
+	pkg := lprogram.MainPkg()
+	mod, errs := CompilePackage(tc.file, pkg, program.Package(pkg.Pkg), machine, compilerConfig, false)
+	if errs != nil {
+		for _, err := range errs {
+			t.Error(err)
+		}
+		return llvm.Module{}
+	}
+
+	err = llvm.VerifyModule(mod, llvm.PrintMessageAction)
+	if err != nil {
+		t.Error(err)
+	}
+
+	return mod
+}
+
 // fuzzyEqualIR returns true if the two LLVM IR strings passed in are roughly
 // equal. That means, only relevant lines are compared (excluding comments
 // etc.).
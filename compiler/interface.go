@@ -76,6 +76,10 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		case *types.Interface:
 			methodSetGlobal := c.getInterfaceMethodSet(typ)
 			references = llvm.ConstBitCast(methodSetGlobal, global.Type())
+		case *types.Map:
+			// Take a pointer to a global with the key and element typecodes.
+			mapGlobal := c.makeMapTypeFields(typ, global.Type())
+			references = llvm.ConstBitCast(mapGlobal, global.Type())
 		}
 		if _, ok := typ.Underlying().(*types.Interface); !ok {
 			methodSet = c.getTypeMethodSet(typ)
@@ -152,6 +156,20 @@ func (c *compilerContext) makeStructTypeFields(typ *types.Struct) llvm.Value {
 	return structGlobal
 }
 
+// makeMapTypeFields creates a new global that stores the key and element
+// typecode of this map type, and returns the resulting global.
+func (c *compilerContext) makeMapTypeFields(typ *types.Map, typecodePtrType llvm.Type) llvm.Value {
+	mapGlobalValue := llvm.ConstArray(typecodePtrType, []llvm.Value{
+		c.getTypeCode(typ.Key()),
+		c.getTypeCode(typ.Elem()),
+	})
+	mapGlobal := llvm.AddGlobal(c.mod, mapGlobalValue.Type(), "reflect/types.mapFields")
+	mapGlobal.SetInitializer(mapGlobalValue)
+	mapGlobal.SetUnnamedAddr(true)
+	mapGlobal.SetLinkage(llvm.PrivateLinkage)
+	return mapGlobal
+}
+
 var basicTypes = [...]string{
 	types.Bool:          "bool",
 	types.Int:           "int",
@@ -422,7 +440,7 @@ func (b *builder) createTypeAssert(expr *ssa.TypeAssert) llvm.Value {
 	} else {
 		// This is kind of dirty as the branch above becomes mostly useless,
 		// but hopefully this gets optimized away.
-		b.createRuntimeCall("interfaceTypeAssert", []llvm.Value{commaOk}, "")
+		b.createRuntimeInvoke("interfaceTypeAssert", []llvm.Value{commaOk}, "")
 		return phi
 	}
 }
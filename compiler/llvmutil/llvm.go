@@ -7,7 +7,24 @@
 // places would be a big risk if only one of them is updated.
 package llvmutil
 
-import "tinygo.org/x/go-llvm"
+import (
+	"strings"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// StackCheckMetadata is the kind of the metadata that marks the stack pointer
+// read (llvm.stacksave) of the stack overflow check at the start of a function.
+// The interp package returns the highest address for it, as there is no stack
+// at compile time.
+const StackCheckMetadata = "tinygo.stackcheck"
+
+// IsNoInitSection returns whether globals in the given section are left as-is
+// at startup instead of being initialized: the linker scripts put .noinit and
+// .noinit.* sections in RAM that isn't zeroed.
+func IsNoInitSection(section string) bool {
+	return section == ".noinit" || strings.HasPrefix(section, ".noinit.")
+}
 
 // CreateEntryBlockAlloca creates a new alloca in the entry block, even though
 // the IR builder is located elsewhere. It assumes that the insert point is
@@ -128,13 +128,13 @@ func (b *builder) createMapUpdate(keyType types.Type, m, key, value llvm.Value,
 	if t, ok := keyType.(*types.Basic); ok && t.Info()&types.IsString != 0 {
 		// key is a string
 		params := []llvm.Value{m, key, valuePtr}
-		b.createRuntimeCall("hashmapStringSet", params, "")
+		b.createRuntimeInvoke("hashmapStringSet", params, "")
 	} else if hashmapIsBinaryKey(keyType) {
 		// key can be compared with runtime.memequal
 		keyAlloca, keyPtr, keySize := b.createTemporaryAlloca(key.Type(), "hashmap.key")
 		b.CreateStore(key, keyAlloca)
 		params := []llvm.Value{m, keyPtr, valuePtr}
-		b.createRuntimeCall("hashmapBinarySet", params, "")
+		b.createRuntimeInvoke("hashmapBinarySet", params, "")
 		b.emitLifetimeEnd(keyPtr, keySize)
 	} else {
 		// Key is not trivially comparable, so compare it as an interface instead.
@@ -144,7 +144,7 @@ func (b *builder) createMapUpdate(keyType types.Type, m, key, value llvm.Value,
 			itfKey = b.createMakeInterface(key, keyType, pos)
 		}
 		params := []llvm.Value{m, itfKey, valuePtr}
-		b.createRuntimeCall("hashmapInterfaceSet", params, "")
+		b.createRuntimeInvoke("hashmapInterfaceSet", params, "")
 	}
 	b.emitLifetimeEnd(valuePtr, valueSize)
 }
@@ -21,15 +21,17 @@ import (
 // The linkName value contains a valid link name, even if //go:linkname is not
 // present.
 type functionInfo struct {
-	module     string     // go:wasm-module
-	importName string     // go:linkname, go:export - The name the developer assigns
-	linkName   string     // go:linkname, go:export - The name that we map for the particular module -> importName
-	section    string     // go:section - object file section name
-	exported   bool       // go:export, CGo
-	interrupt  bool       // go:interrupt
-	nobounds   bool       // go:nobounds
-	variadic   bool       // go:variadic (CGo only)
-	inline     inlineType // go:inline
+	module     string       // go:wasm-module
+	importName string       // go:linkname, go:export - The name the developer assigns
+	linkName   string       // go:linkname, go:export - The name that we map for the particular module -> importName
+	section    string       // go:section - object file section name
+	exported   bool         // go:export, CGo
+	interrupt  bool         // go:interrupt
+	nobounds   bool         // go:nobounds
+	variadic   bool         // go:variadic (CGo only)
+	weak       bool         // go:weak
+	inline     inlineType   // go:inline
+	overflow   overflowType // go:overflow
 }
 
 type inlineType int
@@ -51,6 +53,21 @@ const (
 	inlineNone
 )
 
+type overflowType int
+
+// What to do on signed integer overflow.
+const (
+	// Default behavior, as set with the -int-overflow flag.
+	overflowDefault overflowType = iota
+
+	// Wrap around, as required by the Go specification. Signalled using
+	// //go:overflow wrap.
+	overflowWrap
+
+	// Panic with a runtime error. Signalled using //go:overflow trap.
+	overflowTrap
+)
+
 // getFunction returns the LLVM function for the given *ssa.Function, creating
 // it if needed. It can later be filled with compilerContext.createFunction().
 func (c *compilerContext) getFunction(fn *ssa.Function) llvm.Value {
@@ -159,6 +176,13 @@ func (c *compilerContext) getFunction(fn *ssa.Function) llvm.Value {
 		llvmFn.AddAttributeAtIndex(1, c.ctx.CreateEnumAttribute(llvm.AttributeKindID("nocapture"), 0))
 		llvmFn.AddAttributeAtIndex(1, c.ctx.CreateEnumAttribute(llvm.AttributeKindID("readonly"), 0))
 	}
+	if strings.HasPrefix(info.linkName, "runtime/volatile.Load") || strings.HasPrefix(info.linkName, "runtime/volatile.Store") {
+		// Volatile loads and stores don't retain the pointer. LLVM treats
+		// volatile accesses as capturing, so without this a local variable
+		// that is accessed with a volatile load or store (for example in
+		// crypto/subtle) would always be allocated on the heap.
+		llvmFn.AddAttributeAtIndex(1, c.ctx.CreateEnumAttribute(llvm.AttributeKindID("nocapture"), 0))
+	}
 
 	// External/exported functions may not retain pointer values.
 	// https://golang.org/cmd/cgo/#hdr-Passing_pointers
@@ -262,6 +286,16 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 				info.inline = inlineHint
 			case "//go:noinline":
 				info.inline = inlineNone
+			case "//go:overflow":
+				if len(parts) != 2 {
+					continue
+				}
+				switch parts[1] {
+				case "wrap":
+					info.overflow = overflowWrap
+				case "trap":
+					info.overflow = overflowTrap
+				}
 			case "//go:linkname":
 				if len(parts) != 3 || parts[1] != f.Name() {
 					continue
@@ -277,6 +311,14 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 				if len(parts) == 2 && hasUnsafeImport(f.Pkg.Pkg) {
 					info.section = parts[1]
 				}
+			case "//go:weak":
+				// Emit this function as a weak symbol, so that it can be
+				// replaced by a (non-weak) function with the same link name
+				// elsewhere in the program. Only allowed in packages that
+				// import unsafe.
+				if hasUnsafeImport(f.Pkg.Pkg) {
+					info.weak = true
+				}
 			case "//go:nobounds":
 				// Skip bounds checking in this function. Useful for some
 				// runtime functions.
@@ -376,8 +418,8 @@ func (c *compilerContext) addStandardAttributes(llvmFn llvm.Value) {
 // linkName is equal to .RelString(nil) on a global and extern is false, but for
 // some symbols this is different (due to //go:extern for example).
 type globalInfo struct {
-	linkName string // go:extern
-	extern   bool   // go:extern
+	linkName string // go:extern, go:linkname
+	extern   bool   // go:extern, go:linkname
 	align    int    // go:align
 	section  string // go:section
 }
@@ -463,14 +505,14 @@ func (c *compilerContext) getGlobalInfo(g *ssa.Global) globalInfo {
 	// Check for //go: pragmas, which may change the link name (among others).
 	doc := c.astComments[info.linkName]
 	if doc != nil {
-		info.parsePragmas(doc)
+		info.parsePragmas(doc, g)
 	}
 	return info
 }
 
 // Parse //go: pragma comments from the source. In particular, it parses the
-// //go:extern pragma on globals.
-func (info *globalInfo) parsePragmas(doc *ast.CommentGroup) {
+// //go:extern and //go:linkname pragmas on globals.
+func (info *globalInfo) parsePragmas(doc *ast.CommentGroup, g *ssa.Global) {
 	for _, comment := range doc.List {
 		if !strings.HasPrefix(comment.Text, "//go:") {
 			continue
@@ -482,6 +524,17 @@ func (info *globalInfo) parsePragmas(doc *ast.CommentGroup) {
 			if len(parts) == 2 {
 				info.linkName = parts[1]
 			}
+		case "//go:linkname":
+			if len(parts) != 3 || parts[1] != g.Name() {
+				continue
+			}
+			// Refer to a global defined in a different package, usually the
+			// runtime. Like with functions, this is only allowed when the
+			// package imports "unsafe".
+			if hasUnsafeImport(g.Pkg.Pkg) {
+				info.linkName = parts[2]
+				info.extern = true
+			}
 		case "//go:align":
 			align, err := strconv.Atoi(parts[1])
 			if err == nil {
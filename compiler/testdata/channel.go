@@ -23,3 +23,21 @@ func selectZeroRecv(ch1 chan int, ch2 chan struct{}) {
 	default:
 	}
 }
+
+func selectNonBlockingRecv(ch chan int) (int, bool) {
+	select {
+	case n := <-ch:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func selectNonBlockingSend(ch chan int) bool {
+	select {
+	case ch <- 3:
+		return true
+	default:
+		return false
+	}
+}
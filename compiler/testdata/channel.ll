@@ -7,7 +7,7 @@ target triple = "wasm32-unknown-wasi"
 %runtime.channelBlockedList = type { %runtime.channelBlockedList*, %"internal/task.Task"*, %runtime.chanSelectState*, { %runtime.channelBlockedList*, i32, i32 } }
 %"internal/task.Task" = type { %"internal/task.Task"*, i8*, i64, %"internal/task.gcData", %"internal/task.state", i8* }
 %"internal/task.gcData" = type { i8* }
-%"internal/task.state" = type { i32, i8*, %"internal/task.stackState", i1 }
+%"internal/task.state" = type { i32, i8*, %"internal/task.stackState", i1, i1 }
 %"internal/task.stackState" = type { i32, i32 }
 %runtime.chanSelectState = type { %runtime.channel*, i8* }
 
@@ -124,6 +124,47 @@ select.body:                                      ; preds = %select.next
 
 declare { i32, i1 } @runtime.tryChanSelect(i8*, %runtime.chanSelectState*, i32, i32, i8*) #0
 
+; Function Attrs: nounwind
+define hidden { i32, i1 } @main.selectNonBlockingRecv(%runtime.channel* dereferenceable_or_null(32) %ch, i8* %context) unnamed_addr #1 {
+entry:
+  %select.recvbuf = alloca i32, align 4
+  %select.recvbuf.bitcast = bitcast i32* %select.recvbuf to i8*
+  call void @llvm.lifetime.start.p0i8(i64 4, i8* nonnull %select.recvbuf.bitcast)
+  %select.result = call { i1, i1 } @runtime.chanTryRecv(%runtime.channel* %ch, i8* nonnull %select.recvbuf.bitcast, i8* undef) #3
+  %select.received = extractvalue { i1, i1 } %select.result, 0
+  call void @runtime.trackPointer(i8* nonnull %select.recvbuf.bitcast, i8* undef) #3
+  br i1 %select.received, label %select.body, label %select.next
+
+select.body:                                      ; preds = %entry
+  %0 = load i32, i32* %select.recvbuf, align 4
+  %1 = insertvalue { i32, i1 } zeroinitializer, i32 %0, 0
+  %2 = insertvalue { i32, i1 } %1, i1 true, 1
+  ret { i32, i1 } %2
+
+select.next:                                      ; preds = %entry
+  ret { i32, i1 } zeroinitializer
+}
+
+declare { i1, i1 } @runtime.chanTryRecv(%runtime.channel* dereferenceable_or_null(32), i8*, i8*) #0
+
+; Function Attrs: nounwind
+define hidden i1 @main.selectNonBlockingSend(%runtime.channel* dereferenceable_or_null(32) %ch, i8* %context) unnamed_addr #1 {
+entry:
+  %select.send.value = alloca i32, align 4
+  store i32 3, i32* %select.send.value, align 4
+  %0 = bitcast i32* %select.send.value to i8*
+  %select.sent = call i1 @runtime.chanTrySend(%runtime.channel* %ch, i8* nonnull %0, i8* undef) #3
+  br i1 %select.sent, label %select.body, label %select.next
+
+select.body:                                      ; preds = %entry
+  ret i1 true
+
+select.next:                                      ; preds = %entry
+  ret i1 false
+}
+
+declare i1 @runtime.chanTrySend(%runtime.channel* dereferenceable_or_null(32), i8*, i8*) #0
+
 attributes #0 = { "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #1 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #2 = { argmemonly nofree nosync nounwind willreturn }
@@ -0,0 +1,40 @@
+package main
+
+// Test that arithmetic on constant complex numbers is folded at compile time.
+
+func complex128Mul() complex128 {
+	a := complex(1, 2)
+	b := complex(3, 4)
+	return a * b
+}
+
+func complex128Quo() complex128 {
+	a := complex(1, 2)
+	b := complex(3, 4)
+	return a / b
+}
+
+func complex64Quo() complex64 {
+	a := complex64(complex(1, 2))
+	b := complex64(complex(3, 4))
+	return a / b
+}
+
+func complexQuoZero() complex128 {
+	a := complex(1, 2)
+	b := complex(0, 0)
+	return a / b
+}
+
+func complexSum() float64 {
+	a := complex(1, 2)
+	b := complex(3, 4)
+	c := (a*b + a) / (b - a)
+	return real(c) + imag(c)
+}
+
+// Division with a non-constant operand still needs a runtime call.
+func complex128QuoVar(x complex128) complex128 {
+	b := complex(3, 4)
+	return x / b
+}
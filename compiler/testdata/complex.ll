@@ -0,0 +1,57 @@
+; ModuleID = 'complex.go'
+source_filename = "complex.go"
+target datalayout = "e-m:e-p:32:32-p10:8:8-p20:8:8-i64:64-n32:64-S128-ni:1:10:20"
+target triple = "wasm32-unknown-wasi"
+
+declare noalias nonnull i8* @runtime.alloc(i32, i8*, i8*) #0
+
+declare void @runtime.trackPointer(i8* nocapture readonly, i8*) #0
+
+; Function Attrs: nounwind
+define hidden void @main.init(i8* %context) unnamed_addr #1 {
+entry:
+  ret void
+}
+
+; Function Attrs: nounwind
+define hidden { double, double } @main.complex128Mul(i8* %context) unnamed_addr #1 {
+entry:
+  ret { double, double } { double -5.000000e+00, double 1.000000e+01 }
+}
+
+; Function Attrs: nounwind
+define hidden { double, double } @main.complex128Quo(i8* %context) unnamed_addr #1 {
+entry:
+  ret { double, double } { double 4.400000e-01, double 8.000000e-02 }
+}
+
+; Function Attrs: nounwind
+define hidden { float, float } @main.complex64Quo(i8* %context) unnamed_addr #1 {
+entry:
+  ret { float, float } { float 0x3FDC28F5C0000000, float 0x3FB47AE140000000 }
+}
+
+; Function Attrs: nounwind
+define hidden { double, double } @main.complexQuoZero(i8* %context) unnamed_addr #1 {
+entry:
+  ret { double, double } { double 0x7FF0000000000000, double 0x7FF0000000000000 }
+}
+
+; Function Attrs: nounwind
+define hidden double @main.complexSum(i8* %context) unnamed_addr #1 {
+entry:
+  ret double 6.000000e+00
+}
+
+; Function Attrs: nounwind
+define hidden { double, double } @main.complex128QuoVar(double %x.r, double %x.i, i8* %context) unnamed_addr #1 {
+entry:
+  %0 = call { double, double } @runtime.complex128div(double %x.r, double %x.i, double 3.000000e+00, double 4.000000e+00, i8* undef) #2
+  ret { double, double } %0
+}
+
+declare { double, double } @runtime.complex128div(double, double, double, double, i8*) #0
+
+attributes #0 = { "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
+attributes #1 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
+attributes #2 = { nounwind }
@@ -259,6 +259,118 @@ entry:
   ret void
 }
 
+; Function Attrs: nounwind
+define hidden i32 @main.deferIndex(i32* %s.data, i32 %s.len, i32 %s.cap, i32 %i, i8* %context) unnamed_addr #1 {
+entry:
+  %defer.alloca = alloca { i32, %runtime._defer* }, align 4
+  %deferPtr = alloca %runtime._defer*, align 4
+  store %runtime._defer* null, %runtime._defer** %deferPtr, align 4
+  %deferframe.buf = alloca %runtime.deferFrame, align 4
+  %0 = call i8* @llvm.stacksave()
+  call void @runtime.setupDeferFrame(%runtime.deferFrame* nonnull %deferframe.buf, i8* %0, i8* undef) #3
+  %defer.alloca.repack = getelementptr inbounds { i32, %runtime._defer* }, { i32, %runtime._defer* }* %defer.alloca, i32 0, i32 0
+  store i32 0, i32* %defer.alloca.repack, align 4
+  %defer.alloca.repack16 = getelementptr inbounds { i32, %runtime._defer* }, { i32, %runtime._defer* }* %defer.alloca, i32 0, i32 1
+  store %runtime._defer* null, %runtime._defer** %defer.alloca.repack16, align 4
+  %1 = bitcast %runtime._defer** %deferPtr to { i32, %runtime._defer* }**
+  store { i32, %runtime._defer* }* %defer.alloca, { i32, %runtime._defer* }** %1, align 4
+  %.not = icmp ult i32 %i, %s.len
+  br i1 %.not, label %lookup.next, label %lookup.throw
+
+lookup.next:                                      ; preds = %entry
+  %2 = getelementptr inbounds i32, i32* %s.data, i32 %i
+  %3 = load i32, i32* %2, align 4
+  br label %rundefers.loophead
+
+rundefers.loophead:                               ; preds = %5, %lookup.next
+  %4 = load %runtime._defer*, %runtime._defer** %deferPtr, align 4
+  %stackIsNil = icmp eq %runtime._defer* %4, null
+  br i1 %stackIsNil, label %rundefers.end, label %rundefers.loop
+
+rundefers.loop:                                   ; preds = %rundefers.loophead
+  %stack.next.gep = getelementptr inbounds %runtime._defer, %runtime._defer* %4, i32 0, i32 1
+  %stack.next = load %runtime._defer*, %runtime._defer** %stack.next.gep, align 4
+  store %runtime._defer* %stack.next, %runtime._defer** %deferPtr, align 4
+  %callback.gep = getelementptr inbounds %runtime._defer, %runtime._defer* %4, i32 0, i32 0
+  %callback = load i32, i32* %callback.gep, align 4
+  switch i32 %callback, label %rundefers.default [
+    i32 0, label %rundefers.callback0
+  ]
+
+rundefers.callback0:                              ; preds = %rundefers.loop
+  %setjmp1 = call i32 asm "\0Amovs r0, #0\0Amov r2, pc\0Astr r2, [r1, #4]", "={r0},{r1},~{r1},~{r2},~{r3},~{r4},~{r5},~{r6},~{r7},~{r8},~{r9},~{r10},~{r11},~{r12},~{lr},~{q0},~{q1},~{q2},~{q3},~{q4},~{q5},~{q6},~{q7},~{q8},~{q9},~{q10},~{q11},~{q12},~{q13},~{q14},~{q15},~{cpsr},~{memory}"(%runtime.deferFrame* nonnull %deferframe.buf) #4
+  %setjmp.result2 = icmp eq i32 %setjmp1, 0
+  br i1 %setjmp.result2, label %5, label %lpad
+
+5:                                                ; preds = %rundefers.callback0
+  call void @"main.deferIndex$1"(i8* undef)
+  br label %rundefers.loophead
+
+rundefers.default:                                ; preds = %rundefers.loop
+  unreachable
+
+rundefers.end:                                    ; preds = %rundefers.loophead
+  call void @runtime.destroyDeferFrame(%runtime.deferFrame* nonnull %deferframe.buf, i8* undef) #3
+  ret i32 %3
+
+recover:                                          ; preds = %rundefers.end3
+  call void @runtime.destroyDeferFrame(%runtime.deferFrame* nonnull %deferframe.buf, i8* undef) #3
+  ret i32 0
+
+lpad:                                             ; preds = %rundefers.callback012, %rundefers.callback0, %lookup.throw
+  br label %rundefers.loophead6
+
+rundefers.loophead6:                              ; preds = %7, %lpad
+  %6 = load %runtime._defer*, %runtime._defer** %deferPtr, align 4
+  %stackIsNil7 = icmp eq %runtime._defer* %6, null
+  br i1 %stackIsNil7, label %rundefers.end3, label %rundefers.loop5
+
+rundefers.loop5:                                  ; preds = %rundefers.loophead6
+  %stack.next.gep8 = getelementptr inbounds %runtime._defer, %runtime._defer* %6, i32 0, i32 1
+  %stack.next9 = load %runtime._defer*, %runtime._defer** %stack.next.gep8, align 4
+  store %runtime._defer* %stack.next9, %runtime._defer** %deferPtr, align 4
+  %callback.gep10 = getelementptr inbounds %runtime._defer, %runtime._defer* %6, i32 0, i32 0
+  %callback11 = load i32, i32* %callback.gep10, align 4
+  switch i32 %callback11, label %rundefers.default4 [
+    i32 0, label %rundefers.callback012
+  ]
+
+rundefers.callback012:                            ; preds = %rundefers.loop5
+  %setjmp14 = call i32 asm "\0Amovs r0, #0\0Amov r2, pc\0Astr r2, [r1, #4]", "={r0},{r1},~{r1},~{r2},~{r3},~{r4},~{r5},~{r6},~{r7},~{r8},~{r9},~{r10},~{r11},~{r12},~{lr},~{q0},~{q1},~{q2},~{q3},~{q4},~{q5},~{q6},~{q7},~{q8},~{q9},~{q10},~{q11},~{q12},~{q13},~{q14},~{q15},~{cpsr},~{memory}"(%runtime.deferFrame* nonnull %deferframe.buf) #4
+  %setjmp.result15 = icmp eq i32 %setjmp14, 0
+  br i1 %setjmp.result15, label %7, label %lpad
+
+7:                                                ; preds = %rundefers.callback012
+  call void @"main.deferIndex$1"(i8* undef)
+  br label %rundefers.loophead6
+
+rundefers.default4:                               ; preds = %rundefers.loop5
+  unreachable
+
+rundefers.end3:                                   ; preds = %rundefers.loophead6
+  br label %recover
+
+lookup.throw:                                     ; preds = %entry
+  %setjmp = call i32 asm "\0Amovs r0, #0\0Amov r2, pc\0Astr r2, [r1, #4]", "={r0},{r1},~{r1},~{r2},~{r3},~{r4},~{r5},~{r6},~{r7},~{r8},~{r9},~{r10},~{r11},~{r12},~{lr},~{q0},~{q1},~{q2},~{q3},~{q4},~{q5},~{q6},~{q7},~{q8},~{q9},~{q10},~{q11},~{q12},~{q13},~{q14},~{q15},~{cpsr},~{memory}"(%runtime.deferFrame* nonnull %deferframe.buf) #4
+  %setjmp.result = icmp eq i32 %setjmp, 0
+  br i1 %setjmp.result, label %8, label %lpad
+
+8:                                                ; preds = %lookup.throw
+  call void @runtime.lookupPanic(i8* undef) #3
+  unreachable
+}
+
+declare void @runtime.lookupPanic(i8*) #0
+
+; Function Attrs: nounwind
+define internal void @"main.deferIndex$1"(i8* %context) unnamed_addr #1 {
+entry:
+  %0 = call %runtime._interface @runtime._recover(i1 false, i8* undef) #3
+  ret void
+}
+
+declare %runtime._interface @runtime._recover(i1, i8*) #0
+
 attributes #0 = { "target-features"="+armv7-m,+hwdiv,+soft-float,+strict-align,+thumb-mode,-aes,-bf16,-cdecp0,-cdecp1,-cdecp2,-cdecp3,-cdecp4,-cdecp5,-cdecp6,-cdecp7,-crc,-crypto,-d32,-dotprod,-dsp,-fp-armv8,-fp-armv8d16,-fp-armv8d16sp,-fp-armv8sp,-fp16,-fp16fml,-fp64,-fpregs,-fullfp16,-hwdiv-arm,-i8mm,-lob,-mve,-mve.fp,-neon,-pacbti,-ras,-sb,-sha2,-vfp2,-vfp2sp,-vfp3,-vfp3d16,-vfp3d16sp,-vfp3sp,-vfp4,-vfp4d16,-vfp4d16sp,-vfp4sp" }
 attributes #1 = { nounwind "target-features"="+armv7-m,+hwdiv,+soft-float,+strict-align,+thumb-mode,-aes,-bf16,-cdecp0,-cdecp1,-cdecp2,-cdecp3,-cdecp4,-cdecp5,-cdecp6,-cdecp7,-crc,-crypto,-d32,-dotprod,-dsp,-fp-armv8,-fp-armv8d16,-fp-armv8d16sp,-fp-armv8sp,-fp16,-fp16fml,-fp64,-fpregs,-fullfp16,-hwdiv-arm,-i8mm,-lob,-mve,-mve.fp,-neon,-pacbti,-ras,-sb,-sha2,-vfp2,-vfp2sp,-vfp3,-vfp3d16,-vfp3d16sp,-vfp3sp,-vfp4,-vfp4d16,-vfp4d16sp,-vfp4sp" }
 attributes #2 = { nofree nosync nounwind willreturn }
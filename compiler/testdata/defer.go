@@ -18,3 +18,11 @@ func deferMultiple() {
 	}()
 	external()
 }
+
+// The bounds check can panic, so it must continue at the landing pad.
+func deferIndex(s []int, i int) int {
+	defer func() {
+		recover()
+	}()
+	return s[i]
+}
@@ -0,0 +1,39 @@
+package main
+
+func addInt(x, y int) int {
+	return x + y
+}
+
+func subInt8(x, y int8) int8 {
+	return x - y
+}
+
+func mulInt32(x, y int32) int32 {
+	return x * y
+}
+
+func negInt64(x int64) int64 {
+	return -x
+}
+
+// Unsigned integers always wrap around.
+func addUint32(x, y uint32) uint32 {
+	return x + y
+}
+
+//go:overflow wrap
+func addWrap(x, y int32) int32 {
+	return x + y
+}
+
+//go:overflow trap
+func addTrap(x, y int32) int32 {
+	return x + y
+}
+
+//go:overflow trap
+func addTrapClosure(x, y int32) func() int32 {
+	return func() int32 {
+		return x + y
+	}
+}
@@ -18,6 +18,11 @@ var alignedGlobal [4]uint32
 //go:align 16
 var alignedGlobal16 [4]uint32
 
+// Import a global from a different package using go:linkname.
+//
+//go:linkname linknamedGlobal somepkg.someGlobal
+var linknamedGlobal uint32
+
 // Test exported functions.
 //
 //export extern_func
@@ -39,6 +44,18 @@ func withLinkageName2()
 //
 //go:inline
 func inlineFunc() {
+	inlineCallee(1)
+	inlineCallee(2)
+	inlineCallee(3)
+	inlineCallee(4)
+	inlineCallee(5)
+	inlineCallee(6)
+	inlineCallee(7)
+	inlineCallee(8)
+	inlineCallee(9)
+	inlineCallee(10)
+	inlineCallee(11)
+	inlineCallee(12)
 }
 
 // Function should never be inlined, equivalent to GCC
@@ -46,6 +63,33 @@ func inlineFunc() {
 //
 //go:noinline
 func noinlineFunc() {
+	inlineCallee(1)
+}
+
+// Function without a pragma, of the same size as inlineFunc.
+func plainFunc() {
+	inlineCallee(1)
+	inlineCallee(2)
+	inlineCallee(3)
+	inlineCallee(4)
+	inlineCallee(5)
+	inlineCallee(6)
+	inlineCallee(7)
+	inlineCallee(8)
+	inlineCallee(9)
+	inlineCallee(10)
+	inlineCallee(11)
+	inlineCallee(12)
+}
+
+func inlineCallee(int)
+
+// Calls the functions above, to check inlining in TestInlinePragmas.
+func callInlinePragmas() {
+	inlineFunc()
+	noinlineFunc()
+	plainFunc()
+	plainFunc()
 }
 
 // This function should have the specified section.
@@ -64,6 +108,14 @@ func exportedFunctionInSection() {
 //go:section .special_function_section
 func undefinedFunctionNotInSection()
 
+// This function can be replaced by a function with the same name elsewhere in
+// the program.
+//
+//export weakFunction
+//go:weak
+func weakFunction() {
+}
+
 //go:section .special_global_section
 var globalInSection uint32
 
@@ -74,3 +126,8 @@ var undefinedGlobalNotInSection uint32
 //go:align 1024
 //go:section .global_section
 var multipleGlobalPragmas uint32
+
+// A global that is not zeroed at startup. It must not be hidden.
+//
+//go:section .noinit
+var globalNoInit uint32
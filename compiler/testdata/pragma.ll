@@ -6,9 +6,11 @@ target triple = "wasm32-unknown-wasi"
 @extern_global = external global [0 x i8], align 1
 @main.alignedGlobal = hidden global [4 x i32] zeroinitializer, align 32
 @main.alignedGlobal16 = hidden global [4 x i32] zeroinitializer, align 16
+@somepkg.someGlobal = external global i32, align 4
 @main.globalInSection = hidden global i32 0, section ".special_global_section", align 4
 @undefinedGlobalNotInSection = external global i32, align 4
 @main.multipleGlobalPragmas = hidden global i32 0, section ".global_section", align 1024
+@main.globalNoInit = global i32 0, section ".noinit", align 4
 
 declare noalias nonnull i8* @runtime.alloc(i32, i8*, i8*) #0
 
@@ -37,12 +39,55 @@ declare void @somepkg.someFunction2(i8*) #0
 ; Function Attrs: inlinehint nounwind
 define hidden void @main.inlineFunc(i8* %context) unnamed_addr #3 {
 entry:
+  call void @main.inlineCallee(i32 1, i8* undef) #7
+  call void @main.inlineCallee(i32 2, i8* undef) #7
+  call void @main.inlineCallee(i32 3, i8* undef) #7
+  call void @main.inlineCallee(i32 4, i8* undef) #7
+  call void @main.inlineCallee(i32 5, i8* undef) #7
+  call void @main.inlineCallee(i32 6, i8* undef) #7
+  call void @main.inlineCallee(i32 7, i8* undef) #7
+  call void @main.inlineCallee(i32 8, i8* undef) #7
+  call void @main.inlineCallee(i32 9, i8* undef) #7
+  call void @main.inlineCallee(i32 10, i8* undef) #7
+  call void @main.inlineCallee(i32 11, i8* undef) #7
+  call void @main.inlineCallee(i32 12, i8* undef) #7
   ret void
 }
 
+declare void @main.inlineCallee(i32, i8*) #0
+
 ; Function Attrs: noinline nounwind
 define hidden void @main.noinlineFunc(i8* %context) unnamed_addr #4 {
 entry:
+  call void @main.inlineCallee(i32 1, i8* undef) #7
+  ret void
+}
+
+; Function Attrs: nounwind
+define hidden void @main.plainFunc(i8* %context) unnamed_addr #1 {
+entry:
+  call void @main.inlineCallee(i32 1, i8* undef) #7
+  call void @main.inlineCallee(i32 2, i8* undef) #7
+  call void @main.inlineCallee(i32 3, i8* undef) #7
+  call void @main.inlineCallee(i32 4, i8* undef) #7
+  call void @main.inlineCallee(i32 5, i8* undef) #7
+  call void @main.inlineCallee(i32 6, i8* undef) #7
+  call void @main.inlineCallee(i32 7, i8* undef) #7
+  call void @main.inlineCallee(i32 8, i8* undef) #7
+  call void @main.inlineCallee(i32 9, i8* undef) #7
+  call void @main.inlineCallee(i32 10, i8* undef) #7
+  call void @main.inlineCallee(i32 11, i8* undef) #7
+  call void @main.inlineCallee(i32 12, i8* undef) #7
+  ret void
+}
+
+; Function Attrs: nounwind
+define hidden void @main.callInlinePragmas(i8* %context) unnamed_addr #1 {
+entry:
+  call void @main.inlineFunc(i8* undef)
+  call void @main.noinlineFunc(i8* undef)
+  call void @main.plainFunc(i8* undef)
+  call void @main.plainFunc(i8* undef)
   ret void
 }
 
@@ -60,9 +105,17 @@ entry:
 
 declare void @main.undefinedFunctionNotInSection(i8*) #0
 
+; Function Attrs: nounwind
+define weak void @weakFunction() #6 {
+entry:
+  ret void
+}
+
 attributes #0 = { "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #1 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #2 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" "wasm-export-name"="extern_func" "wasm-import-module"="env" "wasm-import-name"="extern_func" }
 attributes #3 = { inlinehint nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #4 = { noinline nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #5 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" "wasm-export-name"="exportedFunctionInSection" "wasm-import-module"="env" "wasm-import-name"="exportedFunctionInSection" }
+attributes #6 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" "wasm-export-name"="weakFunction" "wasm-import-module"="env" "wasm-import-name"="weakFunction" }
+attributes #7 = { nounwind }
@@ -32,3 +32,12 @@ func stringLookup(s string, x uint8) byte {
 	// Test that x is correctly extended to an uint before comparison.
 	return s[x]
 }
+
+func stringConcat(s1, s2 string) string {
+	return s1 + s2
+}
+
+func stringConcatMany(s1, s2, s3 string) string {
+	// Concatenated with a single runtime call.
+	return s1 + "-" + s2 + s3
+}
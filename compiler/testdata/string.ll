@@ -6,6 +6,7 @@ target triple = "wasm32-unknown-wasi"
 %runtime._string = type { i8*, i32 }
 
 @"main$string" = internal unnamed_addr constant [3 x i8] c"foo", align 1
+@"main$string.1" = internal unnamed_addr constant [1 x i8] c"-", align 1
 
 declare noalias nonnull i8* @runtime.alloc(i32, i8*, i8*) #0
 
@@ -47,7 +48,7 @@ lookup.next:                                      ; preds = %entry
   ret i8 %1
 
 lookup.throw:                                     ; preds = %entry
-  call void @runtime.lookupPanic(i8* undef) #2
+  call void @runtime.lookupPanic(i8* undef) #3
   unreachable
 }
 
@@ -56,7 +57,7 @@ declare void @runtime.lookupPanic(i8*) #0
 ; Function Attrs: nounwind
 define hidden i1 @main.stringCompareEqual(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* %context) unnamed_addr #1 {
 entry:
-  %0 = call i1 @runtime.stringEqual(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* undef) #2
+  %0 = call i1 @runtime.stringEqual(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* undef) #3
   ret i1 %0
 }
 
@@ -65,7 +66,7 @@ declare i1 @runtime.stringEqual(i8*, i32, i8*, i32, i8*) #0
 ; Function Attrs: nounwind
 define hidden i1 @main.stringCompareUnequal(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* %context) unnamed_addr #1 {
 entry:
-  %0 = call i1 @runtime.stringEqual(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* undef) #2
+  %0 = call i1 @runtime.stringEqual(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* undef) #3
   %1 = xor i1 %0, true
   ret i1 %1
 }
@@ -73,7 +74,7 @@ entry:
 ; Function Attrs: nounwind
 define hidden i1 @main.stringCompareLarger(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* %context) unnamed_addr #1 {
 entry:
-  %0 = call i1 @runtime.stringLess(i8* %s2.data, i32 %s2.len, i8* %s1.data, i32 %s1.len, i8* undef) #2
+  %0 = call i1 @runtime.stringLess(i8* %s2.data, i32 %s2.len, i8* %s1.data, i32 %s1.len, i8* undef) #3
   ret i1 %0
 }
 
@@ -92,10 +93,60 @@ lookup.next:                                      ; preds = %entry
   ret i8 %2
 
 lookup.throw:                                     ; preds = %entry
-  call void @runtime.lookupPanic(i8* undef) #2
+  call void @runtime.lookupPanic(i8* undef) #3
   unreachable
 }
 
+; Function Attrs: nounwind
+define hidden %runtime._string @main.stringConcat(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* %context) unnamed_addr #1 {
+entry:
+  %0 = call %runtime._string @runtime.stringConcat(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* undef) #3
+  %1 = extractvalue %runtime._string %0, 0
+  call void @runtime.trackPointer(i8* %1, i8* undef) #3
+  ret %runtime._string %0
+}
+
+declare %runtime._string @runtime.stringConcat(i8* dereferenceable_or_null(1), i32, i8* dereferenceable_or_null(1), i32, i8*) #0
+
+; Function Attrs: nounwind
+define hidden %runtime._string @main.stringConcatMany(i8* %s1.data, i32 %s1.len, i8* %s2.data, i32 %s2.len, i8* %s3.data, i32 %s3.len, i8* %context) unnamed_addr #1 {
+entry:
+  %concat.strings.alloca = alloca [4 x %runtime._string], align 8
+  %concat.strings.alloca.bitcast = bitcast [4 x %runtime._string]* %concat.strings.alloca to i8*
+  call void @llvm.lifetime.start.p0i8(i64 32, i8* nonnull %concat.strings.alloca.bitcast)
+  %.repack = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 0, i32 0
+  store i8* %s1.data, i8** %.repack, align 8
+  %.repack1 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 0, i32 1
+  store i32 %s1.len, i32* %.repack1, align 4
+  %.repack3 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 1, i32 0
+  store i8* getelementptr inbounds ([1 x i8], [1 x i8]* @"main$string.1", i32 0, i32 0), i8** %.repack3, align 8
+  %.repack4 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 1, i32 1
+  store i32 1, i32* %.repack4, align 4
+  %.repack5 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 2, i32 0
+  store i8* %s2.data, i8** %.repack5, align 8
+  %.repack6 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 2, i32 1
+  store i32 %s2.len, i32* %.repack6, align 4
+  %.repack8 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 3, i32 0
+  store i8* %s3.data, i8** %.repack8, align 8
+  %.repack9 = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 3, i32 1
+  store i32 %s3.len, i32* %.repack9, align 4
+  %concat.strings = getelementptr inbounds [4 x %runtime._string], [4 x %runtime._string]* %concat.strings.alloca, i32 0, i32 0
+  %0 = call %runtime._string @runtime.stringConcatN(%runtime._string* nonnull %concat.strings, i32 4, i32 4, i8* undef) #3
+  call void @llvm.lifetime.end.p0i8(i64 32, i8* nonnull %concat.strings.alloca.bitcast)
+  %1 = extractvalue %runtime._string %0, 0
+  call void @runtime.trackPointer(i8* %1, i8* undef) #3
+  ret %runtime._string %0
+}
+
+; Function Attrs: argmemonly nofree nosync nounwind willreturn
+declare void @llvm.lifetime.start.p0i8(i64 immarg, i8* nocapture) #2
+
+declare %runtime._string @runtime.stringConcatN(%runtime._string*, i32, i32, i8*) #0
+
+; Function Attrs: argmemonly nofree nosync nounwind willreturn
+declare void @llvm.lifetime.end.p0i8(i64 immarg, i8* nocapture) #2
+
 attributes #0 = { "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
 attributes #1 = { nounwind "target-features"="+bulk-memory,+nontrapping-fptoint,+sign-ext" }
-attributes #2 = { nounwind }
+attributes #2 = { argmemonly nofree nosync nounwind willreturn }
+attributes #3 = { nounwind }
@@ -20,6 +20,24 @@ var (
 	GitSha1 string
 )
 
+// ReleaseTags returns the build tags for this version of TinyGo. Like the
+// go1.N release tags of Go, they are cumulative: TinyGo 0.26 defines tinygo0.1
+// through tinygo0.26, so that a file with a "//go:build tinygo0.26" constraint
+// is included in TinyGo 0.26 and all later versions.
+func ReleaseTags() []string {
+	var major, minor int
+	fmt.Sscanf(Version, "%d.%d", &major, &minor)
+	first := 0
+	if major == 0 {
+		first = 1
+	}
+	var tags []string
+	for i := first; i <= minor; i++ {
+		tags = append(tags, fmt.Sprintf("tinygo%d.%d", major, i))
+	}
+	return tags
+}
+
 // GetGorootVersion returns the major and minor version for a given GOROOT path.
 // If the goroot cannot be determined, (0, 0) is returned.
 func GetGorootVersion(goroot string) (major, minor int, err error) {
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/tinygo-org/tinygo/compiler/llvmutil"
 	"tinygo.org/x/go-llvm"
 )
 
@@ -274,6 +275,17 @@ func (r *runner) run(fn *function, params []value, parentMem *memoryView, indent
 				// means that monotonic time in the time package is counted from
 				// time.Time{}.Sub(1), which should be fine.
 				locals[inst.localIndex] = literalValue{uint64(0)}
+			case callFn.name == "llvm.stacksave" && !inst.llvmInst.Metadata(r.mod.Context().MDKindID(llvmutil.StackCheckMetadata)).IsNil():
+				// This is the stack pointer read by the stack overflow check at
+				// the start of a function (see createStackOverflowCheck in the
+				// compiler). There is no stack at compile time, so return the
+				// highest address, which never overflows.
+				switch r.pointerSize {
+				case 4:
+					locals[inst.localIndex] = literalValue{^uint32(0)}
+				default:
+					locals[inst.localIndex] = literalValue{^uint64(0)}
+				}
 			case callFn.name == "runtime.alloc":
 				// Allocate heap memory. At compile time, this is instead done
 				// by creating a global variable.
@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/tinygo-org/tinygo/compiler/llvmutil"
 	"tinygo.org/x/go-llvm"
 )
 
@@ -1174,7 +1175,9 @@ func (r *runner) getValue(llvmValue llvm.Value) value {
 			r.objects = append(r.objects, obj)
 			if !llvmValue.IsAGlobalVariable().IsNil() {
 				obj.size = uint32(r.targetData.TypeAllocSize(llvmValue.Type().ElementType()))
-				if initializer := llvmValue.Initializer(); !initializer.IsNil() {
+				// The initializer of a global in a .noinit section is
+				// meaningless, so it is treated like an external global.
+				if initializer := llvmValue.Initializer(); !initializer.IsNil() && !llvmutil.IsNoInitSection(llvmValue.Section()) {
 					obj.buffer = r.getValue(initializer)
 					obj.constant = llvmValue.IsGlobalConstant()
 				}
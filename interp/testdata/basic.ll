@@ -10,6 +10,7 @@ target triple = "x86_64--linux"
 @main.exposedValue1 = global i16 0
 @main.exposedValue2 = global i16 0
 @main.insertedValue = global {i8, i32, {float, {i64, i16}}} zeroinitializer
+@main.bootCount = global i32 0, section ".noinit"
 
 declare void @runtime.printint64(i64) unnamed_addr
 
@@ -88,6 +89,12 @@ entry:
   %agg2 = insertvalue {i8, i32, {float, {i64, i16}}} %agg, i64 5, 2, 1, 0
   store {i8, i32, {float, {i64, i16}}} %agg2, {i8, i32, {float, {i64, i16}}}* @main.insertedValue
 
+  ; Test that globals in a .noinit section are not assumed to be zero: they
+  ; keep their value across a reset.
+  %bootCount = load i32, i32* @main.bootCount
+  %bootCount.next = add i32 %bootCount, 1
+  store i32 %bootCount.next, i32* @main.bootCount
+
   ret void
 }
 
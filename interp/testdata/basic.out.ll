@@ -9,6 +9,7 @@ target triple = "x86_64--linux"
 @main.exposedValue1 = global i16 0
 @main.exposedValue2 = local_unnamed_addr global i16 0
 @main.insertedValue = local_unnamed_addr global { i8, i32, { float, { i64, i16 } } } zeroinitializer
+@main.bootCount = local_unnamed_addr global i32 0, section ".noinit"
 
 declare void @runtime.printint64(i64) unnamed_addr
 
@@ -43,6 +44,9 @@ entry:
   %agg2.insertvalue1 = insertvalue { float, { i64, i16 } } %agg2.agg0, { i64, i16 } %agg2.insertvalue2, 1
   %agg2.insertvalue0 = insertvalue { i8, i32, { float, { i64, i16 } } } %agg, { float, { i64, i16 } } %agg2.insertvalue1, 2
   store { i8, i32, { float, { i64, i16 } } } %agg2.insertvalue0, { i8, i32, { float, { i64, i16 } } }* @main.insertedValue, align 8
+  %bootCount = load i32, i32* @main.bootCount, align 4
+  %bootCount.next = add i32 %bootCount, 1
+  store i32 %bootCount.next, i32* @main.bootCount, align 4
   ret void
 }
 
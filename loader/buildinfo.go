@@ -0,0 +1,104 @@
+package loader
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/goenv"
+)
+
+// BuildInfo returns information about the main package, the modules it is
+// built from and the build settings, in the text format used by
+// runtime/debug.BuildInfo.String. It is embedded in the binary, so that it can
+// be read back using runtime/debug.ReadBuildInfo. Keys and values are never
+// quoted, as ReadBuildInfo doesn't support that.
+//
+// It returns the empty string if the program isn't built in module mode.
+func (p *Program) BuildInfo() string {
+	mainPkg := p.MainPkg()
+	if mainPkg.Module.Path == "" {
+		return ""
+	}
+
+	// Collect all modules that contribute a package to the program.
+	var main *Package
+	deps := make(map[string]*Package) // one package of each dependency
+	var paths []string
+	for _, pkg := range p.sorted {
+		switch {
+		case pkg.Module.Path == "":
+			// Part of the standard library.
+		case pkg.Module.Main:
+			main = pkg
+		case deps[pkg.Module.Path] == nil:
+			deps[pkg.Module.Path] = pkg
+			paths = append(paths, pkg.Module.Path)
+		}
+	}
+	sort.Strings(paths)
+
+	// Read the checksums of the dependencies from go.sum.
+	var sums map[string]string
+	if main != nil {
+		sums = readGoSum(filepath.Join(main.Module.Dir, "go.sum"))
+	}
+
+	buf := &strings.Builder{}
+	if version, err := goenv.GorootVersionString(goenv.Get("GOROOT")); err == nil {
+		buf.WriteString("go\t" + version + "\n")
+	}
+	buf.WriteString("path\t" + mainPkg.ImportPath + "\n")
+	if main != nil {
+		version := main.Module.Version
+		if version == "" {
+			version = "(devel)"
+		}
+		buf.WriteString("mod\t" + main.Module.Path + "\t" + version + "\t\n")
+	}
+	for _, path := range paths {
+		mod := &deps[path].Module
+		buf.WriteString("dep\t" + mod.Path + "\t" + mod.Version)
+		if mod.Replace == nil {
+			buf.WriteString("\t" + sums[mod.Path+" "+mod.Version] + "\n")
+		} else if mod.Replace.Version == "" {
+			// Replaced by a local directory, which has no checksum.
+			buf.WriteString("\n=>\t" + mod.Replace.Path + "\t(devel)\t\n")
+		} else {
+			sum := sums[mod.Replace.Path+" "+mod.Replace.Version]
+			buf.WriteString("\n=>\t" + mod.Replace.Path + "\t" + mod.Replace.Version + "\t" + sum + "\n")
+		}
+	}
+
+	// Add the build settings that are likely to be useful. Unlike the Go
+	// toolchain, TinyGo doesn't store the VCS information.
+	if tags := p.config.Options.Tags; len(tags) != 0 {
+		buf.WriteString("build\t-tags=" + strings.Join(tags, ",") + "\n")
+	}
+	buf.WriteString("build\tGOARCH=" + p.config.GOARCH() + "\n")
+	buf.WriteString("build\tGOOS=" + p.config.GOOS() + "\n")
+	return buf.String()
+}
+
+// readGoSum reads the module checksums from the given go.sum file. The returned
+// map has keys of the form "path version". The checksums of go.mod files are
+// skipped. A missing or unreadable file results in an empty map.
+func readGoSum(path string) map[string]string {
+	sums := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return sums
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	return sums
+}
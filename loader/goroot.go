@@ -183,14 +183,17 @@ func listGorootMergeLinks(goroot, tinygoroot string, overrides map[string]bool)
 		}
 		for _, e := range goEntries {
 			isDir := e.IsDir()
-			if hasTinyGoFiles && !isDir {
+			name := e.Name()
+			if hasTinyGoFiles && !isDir && !gorootFileOverrides[dir] {
 				// Only merge files from Go if TinyGo does not have any files.
 				// Otherwise we'd end up with a weird mix from both Go
 				// implementations.
 				continue
 			}
-
-			name := e.Name()
+			if _, ok := merges[filepath.Join("src", dir, name)]; ok {
+				// This file is replaced by the TinyGo version.
+				continue
+			}
 			if _, ok := overrides[path.Join(dir, name)+"/"]; ok {
 				// This entry is overridden by TinyGo.
 				// It has/will be merged elsewhere.
@@ -221,13 +224,23 @@ func needsSyscallPackage(buildTags []string) bool {
 	return false
 }
 
+// gorootFileOverrides lists the merged directories of pathsToOverride in which
+// TinyGo only replaces some of the files. The files of TinyGo are used instead
+// of the Go files with the same name, the other Go files are still included.
+var gorootFileOverrides = map[string]bool{
+	"bytes/": true, // buffer.go
+	"time/":  true, // format_rfc3339.go
+}
+
 // The boolean indicates whether to merge the subdirs. True means merge, false
 // means use the TinyGo version.
 func pathsToOverride(goMinor int, needsSyscallPackage bool) map[string]bool {
 	paths := map[string]bool{
 		"":                      true,
+		"bytes/":                true,
 		"crypto/":               true,
 		"crypto/rand/":          false,
+		"crypto/subtle/":        false,
 		"device/":               false,
 		"examples/":             false,
 		"internal/":             true,
@@ -238,10 +251,33 @@ func pathsToOverride(goMinor int, needsSyscallPackage bool) map[string]bool {
 		"machine/":              false,
 		"net/":                  true,
 		"os/":                   true,
+		"plugin/":               false,
 		"reflect/":              false,
 		"runtime/":              false,
 		"sync/":                 true,
 		"testing/":              true,
+		"tinygo/":               false,
+	}
+
+	if goMinor < 19 {
+		// The atomic types (like atomic.Pointer[T]) were added in Go 1.19, so
+		// use a copy of the Go 1.19+ package instead.
+		paths["sync/atomic/"] = false
+	}
+
+	if goMinor < 21 {
+		// The cmp, maps and slices packages were added in Go 1.21. Provide
+		// them for older Go versions as well.
+		paths["cmp/"] = false
+		paths["maps/"] = false
+		paths["slices/"] = false
+	}
+
+	if goMinor >= 20 {
+		// Go 1.20 added a fast path for RFC 3339 to time.Format and
+		// time.Parse. Replace it with a version that doesn't allocate while
+		// parsing.
+		paths["time/"] = true
 	}
 
 	if goMinor >= 19 {
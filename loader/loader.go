@@ -50,12 +50,18 @@ type PackageJSON struct {
 	Name       string
 	ForTest    string
 	Root       string
+	Standard   bool
 	Module     struct {
 		Path      string
+		Version   string
 		Main      bool
 		Dir       string
 		GoMod     string
 		GoVersion string
+		Replace   *struct {
+			Path    string
+			Version string
+		}
 	}
 
 	// Source files
@@ -295,6 +301,11 @@ func (p *Program) MainPkg() *Package {
 	return p.sorted[len(p.sorted)-1]
 }
 
+// Fset returns the file set of all files that are part of this program.
+func (p *Program) Fset() *token.FileSet {
+	return p.fset
+}
+
 // Parse parses all packages and typechecks them.
 //
 // The returned error may be an Errors error, which contains a list of errors.
@@ -391,6 +402,9 @@ func (p *Package) Check() error {
 		packageName = "main"
 	}
 	typesPkg, err := checker.Check(packageName, p.program.fset, p.Files, &p.info)
+	for i, err := range typeErrors {
+		typeErrors[i] = p.explainTypeError(typesPkg, err)
+	}
 	if err != nil {
 		if err, ok := err.(Errors); ok {
 			return err
@@ -0,0 +1,241 @@
+package loader
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Identifiers in the standard library that TinyGo does not provide, with an
+// explanation of why. Using them results in a type error like "undefined:
+// exec.Command", which on its own suggests a typo rather than a missing
+// feature.
+var unsupportedIdentifiers = map[string]string{
+	"os/exec.Cmd":            "starting other processes is not supported",
+	"os/exec.Command":        "starting other processes is not supported",
+	"os/exec.CommandContext": "starting other processes is not supported",
+	"os/exec.LookPath":       "starting other processes is not supported",
+}
+
+// explainTypeError adds more information to some type errors:
+//
+//   - "undefined: pkg.Name" errors get a hint when pkg.Name is a known
+//     unsupported standard library identifier. Only errors on a selector of an
+//     imported package are considered, where the package really doesn't have
+//     the name.
+//   - When a value doesn't implement the interface it is assigned to, passed
+//     as or returned as, or a type argument doesn't satisfy its constraint,
+//     all the missing methods are listed with the signature they should have.
+//     The type checker only names the first one.
+//
+// It must be called after type checking of pkg, when all types are known.
+func (p *Package) explainTypeError(pkg *types.Package, err error) error {
+	typeErr, ok := err.(types.Error)
+	if !ok {
+		return err
+	}
+
+	// Find the path from the file to the innermost node at the position of
+	// the error.
+	var path []ast.Node
+	for _, file := range p.Files {
+		if typeErr.Pos < file.Pos() || typeErr.Pos > file.End() {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if n == nil || typeErr.Pos < n.Pos() || typeErr.Pos >= n.End() {
+				return false
+			}
+			path = append(path, n)
+			return true
+		})
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		// The type checker reports an undefined selected name at the name.
+		if sel, ok := path[i].(*ast.SelectorExpr); ok && sel.Sel.Pos() == typeErr.Pos && strings.HasPrefix(typeErr.Msg, "undefined: ") {
+			if hint := p.unsupportedHint(sel); hint != "" {
+				typeErr.Msg += " (not implemented by TinyGo: " + hint + ")"
+				return typeErr
+			}
+			return err
+		}
+
+		// Other errors are reported at the start of an expression, which may
+		// be nested in larger expressions that start at the same position.
+		if expr, ok := path[i].(ast.Expr); ok && expr.Pos() == typeErr.Pos {
+			if wants := p.missingMethods(pkg, path[:i], expr); wants != "" {
+				typeErr.Msg += wants
+				return typeErr
+			}
+		}
+	}
+	return err
+}
+
+// unsupportedHint returns why the selected name is not available, if it is a
+// known unsupported name of an imported standard library package.
+func (p *Package) unsupportedHint(sel *ast.SelectorExpr) string {
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	pkgName, ok := p.info.Uses[ident].(*types.PkgName)
+	if !ok {
+		return ""
+	}
+	imported := pkgName.Imported()
+	if imported.Scope().Lookup(sel.Sel.Name) != nil {
+		return ""
+	}
+	return unsupportedIdentifiers[imported.Path()+"."+sel.Sel.Name]
+}
+
+// missingMethods returns the methods of the interface that expr is used as but
+// that its type doesn't have, one per line in the form "want Name(params)
+// results". The parents are the nodes that enclose expr. It returns the empty
+// string if expr isn't used as an interface, or its type has all methods.
+func (p *Package) missingMethods(pkg *types.Package, parents []ast.Node, expr ast.Expr) string {
+	typ := p.info.TypeOf(expr)
+	want := p.expectedType(parents, expr)
+	if typ == nil || want == nil {
+		return ""
+	}
+	iface, ok := want.Underlying().(*types.Interface)
+	if !ok {
+		return ""
+	}
+
+	qualifier := types.RelativeTo(pkg)
+	var wants string
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		obj, _, _ := types.LookupFieldOrMethod(typ, true, method.Pkg(), method.Name())
+		if obj != nil {
+			// The method exists, but it may have the wrong signature or a
+			// pointer receiver. That's a different error, so only list methods
+			// that are really missing.
+			continue
+		}
+		sig := types.TypeString(method.Type(), qualifier)
+		wants += "\n\t\twant " + method.Name() + strings.TrimPrefix(sig, "func")
+	}
+	return wants
+}
+
+// expectedType returns the type that expr must be assignable to (or, for a
+// type argument, the constraint it must satisfy) because of where it is used,
+// or nil if it isn't used in one of the supported ways.
+func (p *Package) expectedType(parents []ast.Node, expr ast.Expr) types.Type {
+	switch parent := parents[len(parents)-1].(type) {
+	case *ast.ValueSpec:
+		// var x T = expr
+		if parent.Type != nil {
+			return p.info.TypeOf(parent.Type)
+		}
+	case *ast.AssignStmt:
+		// x = expr
+		if parent.Tok == token.ASSIGN && len(parent.Lhs) == len(parent.Rhs) {
+			for i, rhs := range parent.Rhs {
+				if rhs == expr {
+					return p.info.TypeOf(parent.Lhs[i])
+				}
+			}
+		}
+	case *ast.CallExpr:
+		tv, ok := p.info.Types[parent.Fun]
+		if !ok {
+			break
+		}
+		if tv.IsType() {
+			// T(expr)
+			return tv.Type
+		}
+		// f(..., expr, ...)
+		sig, ok := tv.Type.Underlying().(*types.Signature)
+		if !ok {
+			break
+		}
+		for i, arg := range parent.Args {
+			if arg != expr {
+				continue
+			}
+			params := sig.Params()
+			if sig.Variadic() && i >= params.Len()-1 {
+				last := params.At(params.Len() - 1).Type()
+				if parent.Ellipsis.IsValid() {
+					return last
+				}
+				if slice, ok := last.(*types.Slice); ok {
+					return slice.Elem()
+				}
+				break
+			}
+			if i < params.Len() {
+				return params.At(i).Type()
+			}
+		}
+	case *ast.ReturnStmt:
+		// return ..., expr, ...
+		var sig *types.Signature
+		for i := len(parents) - 1; i >= 0 && sig == nil; i-- {
+			switch fn := parents[i].(type) {
+			case *ast.FuncLit:
+				sig, _ = p.info.TypeOf(fn).(*types.Signature)
+			case *ast.FuncDecl:
+				if obj := p.info.Defs[fn.Name]; obj != nil {
+					sig, _ = obj.Type().(*types.Signature)
+				}
+			}
+		}
+		if sig == nil || sig.Results().Len() != len(parent.Results) {
+			break
+		}
+		for i, result := range parent.Results {
+			if result == expr {
+				return sig.Results().At(i).Type()
+			}
+		}
+	case *ast.IndexExpr:
+		// F[expr]
+		if parent.Index == expr {
+			return p.typeParamConstraint(parent.X, 0)
+		}
+	case *ast.IndexListExpr:
+		// F[..., expr, ...]
+		for i, index := range parent.Indices {
+			if index == expr {
+				return p.typeParamConstraint(parent.X, i)
+			}
+		}
+	}
+	return nil
+}
+
+// typeParamConstraint returns the constraint of the type parameter with the
+// given index of the generic function or type x, or nil if x is not generic.
+func (p *Package) typeParamConstraint(x ast.Expr, index int) types.Type {
+	var ident *ast.Ident
+	switch x := x.(type) {
+	case *ast.Ident:
+		ident = x
+	case *ast.SelectorExpr:
+		ident = x.Sel
+	default:
+		return nil
+	}
+	var params *types.TypeParamList
+	switch obj := p.info.Uses[ident].(type) {
+	case *types.Func:
+		params = obj.Type().(*types.Signature).TypeParams()
+	case *types.TypeName:
+		if named, ok := obj.Type().(*types.Named); ok {
+			params = named.TypeParams()
+		}
+	}
+	if index >= params.Len() {
+		return nil
+	}
+	return params.At(index).Constraint()
+}
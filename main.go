@@ -141,6 +141,10 @@ func printCommand(cmd string, args ...string) {
 
 // Build compiles and links the given package and writes it to outpath.
 func Build(pkgName, outpath string, options *compileopts.Options) error {
+	if options.Target == "darwin-universal" {
+		return buildDarwinUniversal(pkgName, outpath, options)
+	}
+
 	config, err := builder.NewConfig(options)
 	if err != nil {
 		return err
@@ -167,6 +171,24 @@ func Build(pkgName, outpath string, options *compileopts.Options) error {
 			}
 		}
 
+		if config.DebugCompressed() && result.Binary != result.Executable {
+			// The firmware image doesn't contain any debug information, so
+			// keep the ELF file next to it for tinygo addr2line and
+			// debuggers.
+			if err := copyFile(result.Executable, outpath+".debug"); err != nil {
+				return err
+			}
+		}
+
+		if result.CHeader != "" {
+			// Put the C header next to the archive or plugin, like Go does:
+			// libfoo.a gets the header libfoo.h.
+			headerPath := strings.TrimSuffix(outpath, filepath.Ext(outpath)) + ".h"
+			if err := copyFile(result.CHeader, headerPath); err != nil {
+				return err
+			}
+		}
+
 		if err := os.Rename(result.Binary, outpath); err != nil {
 			// Moving failed. Do a file copy.
 			inf, err := os.Open(result.Binary)
@@ -194,6 +216,44 @@ func Build(pkgName, outpath string, options *compileopts.Options) error {
 	})
 }
 
+// buildDarwinUniversal builds the package for both darwin/amd64 and
+// darwin/arm64, and combines the two executables into a single universal
+// binary that runs natively on both Intel and Apple Silicon Macs.
+func buildDarwinUniversal(pkgName, outpath string, options *compileopts.Options) error {
+	if outpath == "" {
+		// Pick a default output path like Build does.
+		if strings.HasSuffix(pkgName, ".go") {
+			outpath = filepath.Base(pkgName[:len(pkgName)-3])
+		} else {
+			path, err := filepath.Abs(pkgName)
+			if err != nil {
+				return err
+			}
+			outpath = filepath.Base(path)
+		}
+	}
+
+	tmpdir, err := os.MkdirTemp("", "tinygo-universal")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	var binaries []string
+	for _, goarch := range []string{"amd64", "arm64"} {
+		archOptions := *options
+		archOptions.Target = ""
+		archOptions.GOOS = "darwin"
+		archOptions.GOARCH = goarch
+		binary := filepath.Join(tmpdir, goarch)
+		if err := Build(pkgName, binary, &archOptions); err != nil {
+			return err
+		}
+		binaries = append(binaries, binary)
+	}
+	return builder.MakeUniversalBinary(outpath, binaries)
+}
+
 // Test runs the tests in the given package. Returns whether the test passed and
 // possibly an error if the test failed to run.
 func Test(pkgName string, stdout, stderr io.Writer, options *compileopts.Options, testCompileOnly, testVerbose, testShort bool, testRunRegexp string, testBenchRegexp string, testBenchTime string, testBenchMem bool, outpath string) (bool, error) {
@@ -225,7 +285,7 @@ func Test(pkgName string, stdout, stderr io.Writer, options *compileopts.Options
 	}
 
 	passed := false
-	err = buildAndRun(pkgName, config, os.Stdout, flags, nil, 0, func(cmd *exec.Cmd, result builder.BuildResult) error {
+	err = buildAndRun(pkgName, config, stdout, flags, nil, 0, func(cmd *exec.Cmd, result builder.BuildResult) error {
 		if testCompileOnly || outpath != "" {
 			// Write test binary to the specified file name.
 			if outpath == "" {
@@ -370,6 +430,9 @@ func Flash(pkgName, port string, options *compileopts.Options) error {
 	default:
 		return errors.New("unknown flash method: " + flashMethod)
 	}
+	if options.VerifyFlash && flashMethod != "openocd" && flashMethod != "bmp" {
+		return fmt.Errorf("-verify is not supported with flash method %#v", flashMethod)
+	}
 
 	return builder.Build(pkgName, fileExt, config, func(result builder.BuildResult) error {
 		// do we need port reset to put MCU into bootloader mode?
@@ -476,6 +539,12 @@ func Flash(pkgName, port string, options *compileopts.Options) error {
 		default:
 			return fmt.Errorf("unknown flash method: %s", flashMethod)
 		}
+		if options.VerifyFlash {
+			err := verifyFlash(config, flashMethod, result.Executable)
+			if err != nil {
+				return &commandError{"failed to verify flash", result.Binary, err}
+			}
+		}
 		if options.Monitor {
 			return Monitor("", options)
 		}
@@ -485,12 +554,16 @@ func Flash(pkgName, port string, options *compileopts.Options) error {
 
 // Debug compiles and flashes a program to a microcontroller (just like Flash)
 // but instead of resetting the target, it will drop into a debug shell like GDB
-// or LLDB. You can then set breakpoints, run the `continue` command to start,
-// hit Ctrl+C to break the running program, etc.
+// or LLDB, stopped at the start of main.main. You can then set breakpoints, run
+// the `continue` command to continue, hit Ctrl+C to break the running program,
+// etc.
 //
 // Note: this command is expected to execute just before exiting, as it
 // modifies global state.
 func Debug(debugger, pkgName string, ocdOutput bool, options *compileopts.Options) error {
+	// Symbols can't be resolved without debug information, so ignore
+	// -no-debug.
+	options.Debug = true
 	config, err := builder.NewConfig(options)
 	if err != nil {
 		return err
@@ -508,30 +581,7 @@ func Debug(debugger, pkgName string, ocdOutput bool, options *compileopts.Option
 
 	format, fileExt := config.EmulatorFormat()
 	return builder.Build(pkgName, fileExt, config, func(result builder.BuildResult) error {
-		// Find a good way to run GDB.
-		gdbInterface, openocdInterface := config.Programmer()
-		switch gdbInterface {
-		case "msd", "command", "":
-			emulator := config.EmulatorName()
-			if emulator != "" {
-				if emulator == "mgba" {
-					gdbInterface = "mgba"
-				} else if emulator == "simavr" {
-					gdbInterface = "simavr"
-				} else if strings.HasPrefix(emulator, "qemu-system-") {
-					gdbInterface = "qemu"
-				} else {
-					// Assume QEMU as an emulator.
-					gdbInterface = "qemu-user"
-				}
-			} else if openocdInterface != "" && config.Target.OpenOCDTarget != "" {
-				gdbInterface = "openocd"
-			} else if config.Target.JLinkDevice != "" {
-				gdbInterface = "jlink"
-			} else {
-				gdbInterface = "native"
-			}
-		}
+		gdbInterface := debugInterface(config)
 
 		// Run the GDB server, if necessary.
 		port := ""
@@ -655,35 +705,10 @@ func Debug(debugger, pkgName string, ocdOutput bool, options *compileopts.Option
 		}()
 
 		// Construct and execute a gdb or lldb command.
-		// By default: gdb -ex run <binary>
 		// Exit the debugger with Ctrl-D.
-		params := []string{result.Executable}
-		switch debugger {
-		case "gdb":
-			if port != "" {
-				params = append(params, "-ex", "target extended-remote "+port)
-			}
-			for _, cmd := range gdbCommands {
-				params = append(params, "-ex", cmd)
-			}
-		case "lldb":
-			params = append(params, "--arch", config.Triple())
-			if port != "" {
-				if strings.HasPrefix(port, ":") {
-					params = append(params, "-o", "gdb-remote "+port[1:])
-				} else {
-					return fmt.Errorf("cannot use LLDB over a gdb-remote that isn't a TCP port: %s", port)
-				}
-			}
-			for _, cmd := range gdbCommands {
-				if strings.HasPrefix(cmd, "monitor ") {
-					params = append(params, "-o", "process plugin packet "+cmd)
-				} else if cmd == "load" {
-					params = append(params, "-o", "target modules load --load --slide 0")
-				} else {
-					return fmt.Errorf("don't know how to convert GDB command %#v to LLDB", cmd)
-				}
-			}
+		params, err := debuggerArgs(debugger, result.Executable, config.Triple(), port, gdbCommands)
+		if err != nil {
+			return err
 		}
 		cmd := executeCommand(config.Options, cmdName, params...)
 		cmd.Stdin = os.Stdin
@@ -697,6 +722,85 @@ func Debug(debugger, pkgName string, ocdOutput bool, options *compileopts.Option
 	})
 }
 
+// debugInterface returns the way a debugger connects to the target: through
+// the GDB server of the programmer (such as "openocd") or emulator (such as
+// "qemu"), or "native" to run the program directly in the debugger.
+func debugInterface(config *compileopts.Config) string {
+	gdbInterface, openocdInterface := config.Programmer()
+	switch gdbInterface {
+	case "msd", "command", "":
+		emulator := config.EmulatorName()
+		if emulator != "" {
+			if emulator == "mgba" {
+				gdbInterface = "mgba"
+			} else if emulator == "simavr" {
+				gdbInterface = "simavr"
+			} else if strings.HasPrefix(emulator, "qemu-system-") {
+				gdbInterface = "qemu"
+			} else {
+				// Assume QEMU as an emulator.
+				gdbInterface = "qemu-user"
+			}
+		} else if openocdInterface != "" && config.Target.OpenOCDTarget != "" {
+			gdbInterface = "openocd"
+		} else if config.Target.JLinkDevice != "" {
+			gdbInterface = "jlink"
+		} else {
+			gdbInterface = "native"
+		}
+	}
+	return gdbInterface
+}
+
+// debuggerArgs returns the command line parameters for gdb or lldb. The
+// debugger loads the symbols from the executable, connects to the GDB server
+// at the given port (if any) and runs the given GDB commands to load the
+// program. Then it sets a breakpoint on main.main and runs the program until
+// it gets there.
+func debuggerArgs(debugger, executable, triple, port string, gdbCommands []string) ([]string, error) {
+	params := []string{executable}
+	switch debugger {
+	case "gdb":
+		if port != "" {
+			params = append(params, "-ex", "target extended-remote "+port)
+		}
+		for _, cmd := range gdbCommands {
+			params = append(params, "-ex", cmd)
+		}
+		params = append(params, "-ex", "break main.main")
+		if port != "" {
+			params = append(params, "-ex", "continue")
+		} else {
+			params = append(params, "-ex", "run")
+		}
+	case "lldb":
+		params = append(params, "--arch", triple)
+		if port != "" {
+			if strings.HasPrefix(port, ":") {
+				params = append(params, "-o", "gdb-remote "+port[1:])
+			} else {
+				return nil, fmt.Errorf("cannot use LLDB over a gdb-remote that isn't a TCP port: %s", port)
+			}
+		}
+		for _, cmd := range gdbCommands {
+			if strings.HasPrefix(cmd, "monitor ") {
+				params = append(params, "-o", "process plugin packet "+cmd)
+			} else if cmd == "load" {
+				params = append(params, "-o", "target modules load --load --slide 0")
+			} else {
+				return nil, fmt.Errorf("don't know how to convert GDB command %#v to LLDB", cmd)
+			}
+		}
+		params = append(params, "-o", "breakpoint set --name main.main")
+		if port != "" {
+			params = append(params, "-o", "process continue")
+		} else {
+			params = append(params, "-o", "process launch")
+		}
+	}
+	return params, nil
+}
+
 // Run compiles and runs the given program. Depending on the target provided in
 // the options, it will run the program directly on the host or will run it in
 // an emulator. For example, -target=wasm will cause the binary to be run inside
@@ -832,6 +936,66 @@ func buildAndRun(pkgName string, config *compileopts.Config, stdout io.Writer, c
 	})
 }
 
+// verifyFlash reads back the firmware image that was just flashed with the
+// given flash method, and compares it with the flash segments of the
+// executable.
+func verifyFlash(config *compileopts.Config, flashMethod, executable string) error {
+	segments, err := builder.FlashSegments(executable)
+	if err != nil {
+		return err
+	}
+	tmpdir, err := os.MkdirTemp("", "tinygo-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+	readbackPath := filepath.ToSlash(filepath.Join(tmpdir, "readback.bin"))
+
+	// Each read runs the programmer once, to dump the given memory region to
+	// readbackPath.
+	var runRead func(addr, size uint64) error
+	switch flashMethod {
+	case "openocd":
+		args, err := config.OpenOCDConfiguration()
+		if err != nil {
+			return err
+		}
+		runRead = func(addr, size uint64) error {
+			args := append(args[:len(args):len(args)], "-c", "init", "-c", fmt.Sprintf("dump_image %s %#x %d", readbackPath, addr, size), "-c", "exit")
+			cmd := executeCommand(config.Options, "openocd", args...)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		}
+	case "bmp":
+		gdb, err := config.Target.LookupGDB()
+		if err != nil {
+			return err
+		}
+		bmpGDBPort, _, err := getBMPPorts()
+		if err != nil {
+			return err
+		}
+		runRead = func(addr, size uint64) error {
+			args := []string{"-batch", "-ex", "target extended-remote " + bmpGDBPort, "-ex", "monitor swdp_scan", "-ex", "attach 1", "-ex", fmt.Sprintf("dump binary memory %s %#x %#x", readbackPath, addr, addr+size)}
+			cmd := executeCommand(config.Options, gdb, args...)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		}
+	default:
+		return fmt.Errorf("-verify is not supported with flash method %#v", flashMethod)
+	}
+
+	return builder.VerifyFlash(segments, func(addr, size uint64) ([]byte, error) {
+		os.Remove(readbackPath)
+		if err := runRead(addr, size); err != nil {
+			return nil, err
+		}
+		return os.ReadFile(readbackPath)
+	})
+}
+
 func touchSerialPortAt1200bps(port string) (err error) {
 	retryCount := 3
 	for i := 0; i < retryCount; i++ {
@@ -1113,20 +1277,21 @@ func usage(command string) {
 		fmt.Fprintln(os.Stderr, "version:", version)
 		fmt.Fprintf(os.Stderr, "usage: %s <command> [arguments]\n", os.Args[0])
 		fmt.Fprintln(os.Stderr, "\ncommands:")
-		fmt.Fprintln(os.Stderr, "  build:   compile packages and dependencies")
-		fmt.Fprintln(os.Stderr, "  run:     compile and run immediately")
-		fmt.Fprintln(os.Stderr, "  test:    test packages")
-		fmt.Fprintln(os.Stderr, "  flash:   compile and flash to the device")
-		fmt.Fprintln(os.Stderr, "  gdb:     run/flash and immediately enter GDB")
-		fmt.Fprintln(os.Stderr, "  lldb:    run/flash and immediately enter LLDB")
-		fmt.Fprintln(os.Stderr, "  monitor: open communication port")
-		fmt.Fprintln(os.Stderr, "  env:     list environment variables used during build")
-		fmt.Fprintln(os.Stderr, "  list:    run go list using the TinyGo root")
-		fmt.Fprintln(os.Stderr, "  clean:   empty cache directory ("+goenv.Get("GOCACHE")+")")
-		fmt.Fprintln(os.Stderr, "  targets: list targets")
-		fmt.Fprintln(os.Stderr, "  info:    show info for specified target")
-		fmt.Fprintln(os.Stderr, "  version: show version")
-		fmt.Fprintln(os.Stderr, "  help:    print this help text")
+		fmt.Fprintln(os.Stderr, "  build:     compile packages and dependencies")
+		fmt.Fprintln(os.Stderr, "  run:       compile and run immediately")
+		fmt.Fprintln(os.Stderr, "  test:      test packages")
+		fmt.Fprintln(os.Stderr, "  flash:     compile and flash to the device")
+		fmt.Fprintln(os.Stderr, "  gdb:       run/flash and enter GDB, stopped at main.main")
+		fmt.Fprintln(os.Stderr, "  lldb:      run/flash and enter LLDB, stopped at main.main")
+		fmt.Fprintln(os.Stderr, "  addr2line: convert addresses to function names and source locations")
+		fmt.Fprintln(os.Stderr, "  monitor:   open communication port")
+		fmt.Fprintln(os.Stderr, "  env:       list environment variables used during build")
+		fmt.Fprintln(os.Stderr, "  list:      run go list using the TinyGo root")
+		fmt.Fprintln(os.Stderr, "  clean:     empty cache directory ("+goenv.Get("GOCACHE")+")")
+		fmt.Fprintln(os.Stderr, "  targets:   list targets")
+		fmt.Fprintln(os.Stderr, "  info:      show info for specified target")
+		fmt.Fprintln(os.Stderr, "  version:   show version")
+		fmt.Fprintln(os.Stderr, "  help:      print this help text")
 
 		if flag.Parsed() {
 			fmt.Fprintln(os.Stderr, "\nflags:")
@@ -1304,9 +1469,16 @@ func main() {
 	command := os.Args[1]
 
 	opt := flag.String("opt", "z", "optimization level: 0, 1, 2, s, z")
+	pgoProfile := flag.String("pgo", "", "CPU profile (in pprof format) for profile-guided optimization")
 	gc := flag.String("gc", "", "garbage collector to use (none, leaking, conservative)")
+	heapGuard := flag.Bool("heap-guard", false, "surround large heap allocations with guard pages to catch overruns (hosted targets only)")
+	heapProfile := flag.Bool("heap-profile", false, "sample heap allocations for runtime.MemProfile and runtime/pprof (hosted targets only)")
+	pie := flag.Bool("pie", false, "build a position-independent executable (Linux only)")
+	buildMode := flag.String("buildmode", "", "build mode to use (default, c-archive, plugin)")
 	panicStrategy := flag.String("panic", "print", "panic strategy (print, trap)")
-	scheduler := flag.String("scheduler", "", "which scheduler to use (none, tasks, asyncify)")
+	intOverflow := flag.String("int-overflow", "wrap", "signed integer overflow outside the standard library: wrap or trap (panic)")
+	stackCheck := flag.Bool("stack-check", false, "check for goroutine stack overflows at the start of every function")
+	scheduler := flag.String("scheduler", "", "which scheduler to use (none, tasks, asyncify, external)")
 	serial := flag.String("serial", "", "which serial output to use (none, uart, usb)")
 	work := flag.Bool("work", false, "print the name of the temporary build directory and do not delete this directory on exit")
 	interpTimeout := flag.Duration("interp-timeout", 180*time.Second, "interp optimization pass timeout")
@@ -1324,10 +1496,12 @@ func main() {
 	})
 	printSize := flag.String("size", "", "print sizes (none, short, full)")
 	printStacks := flag.Bool("print-stacks", false, "print stack sizes of goroutines")
+	stackReport := flag.Bool("stack-usage-report", false, "print frame sizes of all functions and the worst-case call path of each stack")
 	printAllocsString := flag.String("print-allocs", "", "regular expression of functions for which heap allocations should be printed")
 	printCommands := flag.Bool("x", false, "Print commands")
 	parallelism := flag.Int("p", runtime.GOMAXPROCS(0), "the number of build jobs that can run in parallel")
 	nodebug := flag.Bool("no-debug", false, "strip debug information")
+	debugFormat := flag.String("debug", "", "debug information format: full or compressed (compressed DWARF sections, firmware images get a .debug symbol file)")
 	ocdCommandsString := flag.String("ocd-commands", "", "OpenOCD commands, overriding target spec (can specify multiple separated by commas)")
 	ocdOutput := flag.Bool("ocd-output", false, "print OCD daemon output during debug")
 	port := flag.String("port", "", "flash port (can specify multiple candidates separated by commas)")
@@ -1338,6 +1512,8 @@ func main() {
 	cpuprofile := flag.String("cpuprofile", "", "cpuprofile output")
 	monitor := flag.Bool("monitor", false, "enable serial monitor")
 	baudrate := flag.Int("baudrate", 115200, "baudrate of serial monitor")
+	uf2FamilyID := flag.String("uf2-family-id", "", "family ID to store in UF2 files, overriding the target (for example 0xe48bff56)")
+	verifyFlash := flag.Bool("verify", false, "read back the flashed firmware and compare it with the image (flash-method openocd or bmp)")
 
 	var flagJSON, flagDeps, flagTest bool
 	if command == "help" || command == "list" || command == "info" || command == "build" {
@@ -1408,6 +1584,7 @@ func main() {
 		Opt:             *opt,
 		GC:              *gc,
 		PanicStrategy:   *panicStrategy,
+		IntOverflow:     *intOverflow,
 		Scheduler:       *scheduler,
 		Serial:          *serial,
 		Work:            *work,
@@ -1417,8 +1594,16 @@ func main() {
 		VerifyIR:        *verifyIR,
 		Semaphore:       make(chan struct{}, *parallelism),
 		Debug:           !*nodebug,
+		DebugFormat:     *debugFormat,
 		PrintSizes:      *printSize,
 		PrintStacks:     *printStacks,
+		StackReport:     *stackReport,
+		StackCheck:      *stackCheck,
+		HeapGuard:       *heapGuard,
+		HeapProfile:     *heapProfile,
+		PGO:             *pgoProfile,
+		PIE:             *pie,
+		BuildMode:       *buildMode,
 		PrintAllocs:     printAllocs,
 		Tags:            []string(tags),
 		GlobalValues:    globalVarValues,
@@ -1429,6 +1614,8 @@ func main() {
 		PrintJSON:       flagJSON,
 		Monitor:         *monitor,
 		BaudRate:        *baudrate,
+		UF2FamilyID:     *uf2FamilyID,
+		VerifyFlash:     *verifyFlash,
 	}
 	if *printCommands {
 		options.PrintCommands = printCommand
@@ -1622,6 +1809,14 @@ func main() {
 	case "monitor":
 		err := Monitor(*port, options)
 		handleCompilerError(err)
+	case "addr2line":
+		if flag.NArg() < 2 {
+			fmt.Fprintln(os.Stderr, "addr2line needs an executable or .debug file and at least one address")
+			usage(command)
+			os.Exit(1)
+		}
+		err := Addr2Line(os.Stdout, flag.Arg(0), flag.Args()[1:])
+		handleCompilerError(err)
 	case "targets":
 		dir := filepath.Join(goenv.Get("TINYGOROOT"), "targets")
 		entries, err := ioutil.ReadDir(dir)
@@ -6,23 +6,28 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"debug/elf"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/tinygo-org/tinygo/builder"
 	"github.com/tinygo-org/tinygo/compileopts"
 	"github.com/tinygo-org/tinygo/goenv"
+	"github.com/tinygo-org/tinygo/loader"
 )
 
 const TESTDATA = "testdata"
@@ -45,11 +50,17 @@ func TestBuild(t *testing.T) {
 		"alias.go",
 		"atomic.go",
 		"binop.go",
+		"bufio.go",
+		"buildinfo/",
+		"buildtags/",
 		"calls.go",
 		"cgo/",
 		"channel.go",
+		"comparator.go",
+		"eeprom.go",
 		"embed/",
 		"float.go",
+		"fmt.go",
 		"gc.go",
 		"generics.go",
 		"goroutines.go",
@@ -57,17 +68,25 @@ func TestBuild(t *testing.T) {
 		"init_multi.go",
 		"interface.go",
 		"json.go",
+		"linkname.go",
 		"map.go",
 		"math.go",
+		"multiserial.go",
+		"panichandler.go",
+		"pinnotify.go",
 		"print.go",
 		"reflect.go",
 		"slice.go",
 		"sort.go",
 		"stdlib.go",
+		"stdout.go",
+		"strconv.go",
 		"string.go",
 		"structs.go",
+		"subtle.go",
 		"testing.go",
 		"timers.go",
+		"uartwritetimeout.go",
 		"zeroalloc.go",
 	}
 
@@ -178,6 +197,13 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 				continue
 			}
 		}
+		if options.Target == "cortex-m-qemu" || options.Target == "riscv-qemu" {
+			switch name {
+			case "comparator.go", "eeprom.go", "multiserial.go", "pinnotify.go", "uartwritetimeout.go":
+				// There is no machine package for the emulated boards.
+				continue
+			}
+		}
 		if options.Target == "simavr" {
 			// Not all tests are currently supported on AVR.
 			// Skip the ones that aren't.
@@ -190,7 +216,7 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 				// Does not pass due to high mark false positive rate.
 				continue
 
-			case "json.go", "stdlib.go", "testing.go":
+			case "json.go", "stdlib.go", "stdout.go", "testing.go":
 				// Breaks interp.
 				continue
 
@@ -206,6 +232,10 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 				// CGo does not work on AVR.
 				continue
 
+			case "comparator.go", "pinnotify.go", "uartwritetimeout.go":
+				// Needs the fake peripherals of the generic machine package.
+				continue
+
 			case "timers.go":
 				// Doesn't compile:
 				//   panic: compiler: could not store type code number inside interface type code
@@ -234,6 +264,21 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 			runTest("alias.go", options, t, nil, nil)
 		})
 	}
+	if options.Target == "" || options.Target == "cortex-m-qemu" {
+		t.Run("scheduler_external.go", func(t *testing.T) {
+			t.Parallel()
+			options := compileopts.Options(options)
+			options.Scheduler = "external"
+			runTest("scheduler_external.go", options, t, nil, nil)
+		})
+	}
+	if options.Target != "wasi" && options.Target != "wasm" {
+		// The preinit hook is not called on WebAssembly.
+		t.Run("preinit.go", func(t *testing.T) {
+			t.Parallel()
+			runTest("preinit.go", options, t, nil, nil)
+		})
+	}
 	if options.Target == "" || options.Target == "wasi" {
 		t.Run("filesystem.go", func(t *testing.T) {
 			t.Parallel()
@@ -246,6 +291,19 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 			runTest("rand.go", options, t, nil, nil)
 		})
 	}
+	if options.Target == "cortex-m-qemu" {
+		// Send values from an interrupt handler over a channel.
+		t.Run("interrupt.go", func(t *testing.T) {
+			t.Parallel()
+			runTest("interrupt.go", options, t, nil, nil)
+		})
+
+		// Reset the chip, which keeps the contents of the RAM.
+		t.Run("noinit.go", func(t *testing.T) {
+			t.Parallel()
+			runTest("noinit.go", options, t, nil, nil)
+		})
+	}
 	if options.Target != "wasi" && options.Target != "wasm" {
 		// The recover() builtin isn't supported yet on WebAssembly and Windows.
 		t.Run("recover.go", func(t *testing.T) {
@@ -465,6 +523,49 @@ func TestTest(t *testing.T) {
 				}
 			})
 
+			t.Run("Bench", func(t *testing.T) {
+				t.Parallel()
+
+				// Run benchmarks, and check from the output that b.N was
+				// scaled to the benchmark time using the clock of the
+				// target.
+
+				var wg sync.WaitGroup
+				defer wg.Wait()
+
+				out := ioLogger(t, &wg)
+				defer out.Close()
+
+				var output bytes.Buffer
+				opts := targ.opts
+				passed, err := Test("github.com/tinygo-org/tinygo/tests/testing/benchmark", io.MultiWriter(&output, out), out, &opts, false, false, false, "", ".", "100ms", false, "")
+				if err != nil {
+					t.Errorf("test error: %v", err)
+				}
+				if !passed {
+					t.Error("test failed")
+				}
+
+				results := make(map[string][2]float64) // name -> b.N, ns/op
+				for _, match := range regexp.MustCompile(`(?m)^(Benchmark\w+)\s+(\d+)\s+([0-9.]+) ns/op`).FindAllStringSubmatch(output.String(), -1) {
+					n, _ := strconv.ParseFloat(match[2], 64)
+					ns, _ := strconv.ParseFloat(match[3], 64)
+					results[match[1]] = [2]float64{n, ns}
+				}
+				if len(results) != 2 {
+					t.Fatalf("expected results for 2 benchmarks, got: %v", results)
+				}
+
+				// BenchmarkSleep takes 1ms per iteration, so it should run
+				// about 100 times in 100ms.
+				if r := results["BenchmarkSleep"]; r[0] < 20 || r[0] > 200 || r[1] < 1e6 || r[1] > 5e6 {
+					t.Errorf("unexpected result for BenchmarkSleep: N=%.0f, %.1f ns/op", r[0], r[1])
+				}
+				if r := results["BenchmarkLoop"]; r[0] < 1000 || r[1] <= 0 {
+					t.Errorf("unexpected result for BenchmarkLoop: N=%.0f, %.1f ns/op", r[0], r[1])
+				}
+			})
+
 			if targ.name != "Host" {
 				// Emulated tests are somewhat slow, and these do not need to be run across every platform.
 				return
@@ -535,6 +636,1040 @@ func ioLogger(t *testing.T, wg *sync.WaitGroup) io.WriteCloser {
 	return w
 }
 
+// TestNilMapWrite checks that writing to a nil map results in a runtime panic
+// with the same message as the gc toolchain.
+func TestNilMapWrite(t *testing.T) {
+	t.Parallel()
+
+	options := optionsFromTarget("", sema)
+	config, err := builder.NewConfig(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	err = buildAndRun("./testdata/nilmap.go", config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+		cmd.Stderr = stdout
+		if err := cmd.Run(); err == nil {
+			return errors.New("expected the program to panic")
+		}
+		return nil
+	})
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fail()
+		return
+	}
+
+	expected := "writing to nil map\npanic: runtime error: assignment to entry in nil map\n"
+	if !strings.HasPrefix(stdout.String(), expected) {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+// TestStackOverflow checks that a goroutine that overflows its stack results in
+// a runtime panic with -stack-check, instead of overwriting the memory below the
+// stack. The recursion never yields, so the overflow must be detected by the
+// check at the start of every function, on the host and on baremetal.
+func TestStackOverflow(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{"", "cortex-m-qemu"} {
+		target := target
+		name := target
+		if name == "" {
+			name = "host"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := optionsFromTarget(target, sema)
+			options.Scheduler = "tasks"
+			options.StackCheck = true
+			config, err := builder.NewConfig(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stdout := &bytes.Buffer{}
+			err = buildAndRun("./testdata/stackoverflow.go", config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+				cmd.Stdout = stdout
+				cmd.Stderr = stdout
+				if err := cmd.Run(); err == nil {
+					return errors.New("expected the program to panic")
+				}
+				return nil
+			})
+			if err != nil {
+				printCompilerError(t.Log, err)
+				t.Fail()
+				return
+			}
+
+			expected := "starting goroutine\npanic: runtime error: goroutine stack overflow\n"
+			if !strings.HasPrefix(strings.ReplaceAll(stdout.String(), "\r\n", "\n"), expected) {
+				t.Errorf("unexpected output:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+// TestBlockingPanic checks that blocking outside of a goroutine results in a
+// runtime panic. With -scheduler=external the main function runs on the stack
+// of the host.
+func TestBlockingPanic(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		scheduler string
+		expected  string
+	}{
+		{"scheduler_external_block.go", "external", "blocking in main\npanic: runtime error: cannot block outside of a goroutine with -scheduler=external, start a goroutine instead\n"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			options := optionsFromTarget("", sema)
+			options.Scheduler = tc.scheduler
+			config, err := builder.NewConfig(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stdout := &bytes.Buffer{}
+			err = buildAndRun("./testdata/"+tc.name, config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+				cmd.Stderr = stdout
+				if err := cmd.Run(); err == nil {
+					return errors.New("expected the program to panic")
+				}
+				return nil
+			})
+			if err != nil {
+				printCompilerError(t.Log, err)
+				t.Fail()
+				return
+			}
+
+			if !strings.HasPrefix(stdout.String(), tc.expected) {
+				t.Errorf("unexpected output:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+// TestIntOverflow checks that signed integer overflow panics at the overflowing
+// operation with -int-overflow=trap, and wraps around as before without it.
+func TestIntOverflow(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		intOverflow string
+		expected    string
+	}{
+		{"", "" +
+			"add overflow: -2147483648\n" +
+			"sub overflow: 2147483647\n" +
+			"mul overflow: 0\n" +
+			"neg overflow: -2147483648\n"},
+		{"trap", "" +
+			"add overflow: panic: runtime error: integer overflow\n" +
+			"sub overflow: panic: runtime error: integer overflow\n" +
+			"mul overflow: panic: runtime error: integer overflow\n" +
+			"neg overflow: panic: runtime error: integer overflow\n"},
+	} {
+		tc := tc
+		name := tc.intOverflow
+		if name == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			options := optionsFromTarget("", sema)
+			options.IntOverflow = tc.intOverflow
+			config, err := builder.NewConfig(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stdout := &bytes.Buffer{}
+			err = buildAndRun("./testdata/intoverflow.go", config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+				return cmd.Run()
+			})
+			if err != nil {
+				printCompilerError(t.Log, err)
+				t.Fail()
+				return
+			}
+
+			// The output is the same with and without -int-overflow=trap,
+			// except for the overflowing operations.
+			expected := "" +
+				"add: 2147483647\n" +
+				"mul: -2147483648\n" +
+				tc.expected +
+				"unsigned: 1\n" +
+				"wrap pragma: -2147483648\n" +
+				"trap pragma: panic: runtime error: integer overflow\n" +
+				"trap pragma closure: panic: runtime error: integer overflow\n"
+			if stdout.String() != expected {
+				t.Errorf("unexpected output:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+// TestMissingMethods checks that assigning a type to an interface it doesn't
+// implement results in a compile error that lists the missing methods.
+func TestMissingMethods(t *testing.T) {
+	t.Parallel()
+
+	options := optionsFromTarget("", sema)
+	err := Build("./testdata/missingmethods.go", filepath.Join(t.TempDir(), "missingmethods"), &options)
+	if err == nil {
+		t.Fatal("expected a compile error")
+	}
+	buf := &bytes.Buffer{}
+	printCompilerError(func(v ...interface{}) {
+		fmt.Fprintln(buf, v...)
+	}, err)
+
+	// The first line of the error names the first missing method, in a way
+	// that depends on the Go version. It is followed by all missing methods.
+	output := buf.String()
+	for _, expected := range []struct {
+		message string
+		wants   string
+	}{
+		{"Square does not implement Shape (", "\t\twant Scale(factor float64) Shape\n"},
+		{"Circle does not implement Shape (", "\t\twant Perimeter() float64\n\t\twant Scale(factor float64) Shape\n"},
+	} {
+		i := strings.Index(output, expected.message)
+		if i < 0 {
+			t.Errorf("expected error %q, actual output:\n%s", expected.message, output)
+			continue
+		}
+		rest := output[i:]
+		rest = rest[strings.IndexByte(rest, '\n')+1:]
+		if !strings.HasPrefix(rest, expected.wants) {
+			t.Errorf("expected %q to be followed by:\n%s\nactual output:\n%s", expected.message, expected.wants, output)
+		}
+	}
+}
+
+// TestHeapGuard checks that -heap-guard makes a buffer overrun fault at the
+// overrun, and that the garbage collector still works with guarded objects.
+func TestHeapGuard(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("-heap-guard is only supported on Linux and macOS")
+	}
+
+	options := optionsFromTarget("", sema)
+	options.HeapGuard = true
+
+	t.Run("overrun", func(t *testing.T) {
+		config, err := builder.NewConfig(&options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stdout := &bytes.Buffer{}
+		err = buildAndRun("./testdata/heapguard.go", config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+			cmd.Stderr = stdout
+			err := cmd.Run()
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return fmt.Errorf("expected the program to crash, got %v", err)
+			}
+			// The write to the guard page must be killed by the kernel (macOS
+			// may send SIGBUS instead of SIGSEGV), not end in a runtime panic.
+			status, ok := exitErr.Sys().(syscall.WaitStatus)
+			if !ok || !status.Signaled() || (status.Signal() != syscall.SIGSEGV && status.Signal() != syscall.SIGBUS) {
+				return fmt.Errorf("expected a segmentation fault, got %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			printCompilerError(t.Log, err)
+			t.Fail()
+			return
+		}
+
+		// The write that faulted is the first byte past the end of the buffer,
+		// at the start of the guard page.
+		expected := "in bounds: 0 231\noverrun at start of page: true\n"
+		if stdout.String() != expected {
+			t.Errorf("unexpected output:\n%s", stdout.String())
+		}
+	})
+
+	t.Run("gc.go", func(t *testing.T) {
+		runTest("gc.go", options, t, nil, nil)
+	})
+}
+
+// TestHeapProfile checks that -heap-profile records sampled allocations for
+// runtime.MemProfile and runtime/pprof.
+func TestHeapProfile(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("-heap-profile is only supported on Linux and macOS")
+	}
+	switch runtime.GOARCH {
+	case "386", "amd64", "arm64":
+	default:
+		t.Skip("-heap-profile is not supported on GOARCH=" + runtime.GOARCH)
+	}
+
+	options := optionsFromTarget("", sema)
+	options.HeapProfile = true
+	profile := filepath.Join(t.TempDir(), "heap.prof")
+	runTest("heapprofile.go", options, t, []string{profile}, nil)
+	if t.Failed() {
+		return
+	}
+
+	// Check that pprof can read the profile, and that the samples match the
+	// allocations of the program. The binary is gone by now, so don't try to
+	// symbolize the addresses.
+	cmd := exec.Command(filepath.Join(goenv.Get("GOROOT"), "bin", "go"), "tool", "pprof", "-raw", "-symbolize=none", profile)
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("go tool pprof failed: %v\n%s", err, output)
+	}
+	samples, err := parsePprofRawSamples(string(output))
+	if err != nil {
+		t.Fatalf("could not parse go tool pprof output: %v\n%s", err, output)
+	}
+	var foundKept, foundGarbage bool
+	for _, s := range samples {
+		switch {
+		case s.allocObjects == 100 && s.allocBytes == 100*1000:
+			foundKept = true
+			if s.inuseObjects != 100 || s.inuseBytes != 100*1000 {
+				t.Errorf("kept allocations: %d objects and %d bytes in use, want 100 and 100000", s.inuseObjects, s.inuseBytes)
+			}
+		case s.allocObjects == 1000 && s.allocBytes == 1000*100:
+			foundGarbage = true
+			if s.inuseObjects > 10 {
+				t.Errorf("garbage allocations: %d objects still in use", s.inuseObjects)
+			}
+		default:
+			continue
+		}
+		if s.locations == 0 {
+			t.Errorf("sample %+v has no stack", s)
+		}
+	}
+	if !foundKept || !foundGarbage {
+		t.Errorf("missing samples in the profile (kept: %v, garbage: %v):\n%s", foundKept, foundGarbage, output)
+	}
+}
+
+// pprofSample is a heap profile sample as printed by go tool pprof -raw.
+type pprofSample struct {
+	allocObjects, allocBytes int64
+	inuseObjects, inuseBytes int64
+	locations                int
+}
+
+// parsePprofRawSamples parses the samples of a heap profile from the output of
+// go tool pprof -raw, which looks like this:
+//
+//	Samples:
+//	alloc_objects/count alloc_space/bytes inuse_objects/count inuse_space/bytes
+//	        100     100000        100     100000: 1 2 3
+//	                bytes:[1000]
+//	Locations
+//	...
+func parsePprofRawSamples(output string) ([]pprofSample, error) {
+	_, section, ok := strings.Cut(output, "\nSamples:\n")
+	if !ok {
+		return nil, errors.New("no samples")
+	}
+	header, section, _ := strings.Cut(section, "\n")
+	if header != "alloc_objects/count alloc_space/bytes inuse_objects/count inuse_space/bytes" {
+		return nil, fmt.Errorf("unexpected sample types: %s", header)
+	}
+	var samples []pprofSample
+	for _, line := range strings.Split(section, "\n") {
+		values, stack, ok := strings.Cut(line, ":")
+		fields := strings.Fields(values)
+		if !ok || len(fields) != 4 {
+			if len(line) != 0 && line[0] != ' ' {
+				break // end of the samples section
+			}
+			continue // labels of the previous sample
+		}
+		var counts [4]int64
+		for i, field := range fields {
+			n, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			counts[i] = n
+		}
+		samples = append(samples, pprofSample{
+			allocObjects: counts[0],
+			allocBytes:   counts[1],
+			inuseObjects: counts[2],
+			inuseBytes:   counts[3],
+			locations:    len(strings.Fields(stack)),
+		})
+	}
+	return samples, nil
+}
+
+// TestSchedulerTrace checks that the scheduler_trace build tag reports
+// goroutine state transitions to the handler set with runtime.SetTraceHandler.
+func TestSchedulerTrace(t *testing.T) {
+	t.Parallel()
+	options := optionsFromTarget("", sema)
+	options.Tags = []string{"scheduler_trace"}
+	runTest("schedtrace.go", options, t, nil, nil)
+}
+
+// TestPIE checks that -pie results in a position-independent executable that
+// runs correctly.
+func TestPIE(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("-pie is only supported on Linux")
+	}
+
+	options := optionsFromTarget("", sema)
+	options.PIE = true
+	config, err := builder.NewConfig(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	err = buildAndRun("./testdata/gc.go", config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+		f, err := elf.Open(result.Executable)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if f.Type != elf.ET_DYN {
+			return fmt.Errorf("expected an ET_DYN executable, got %s", f.Type)
+		}
+		return cmd.Run()
+	})
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fail()
+		return
+	}
+
+	expected, err := os.ReadFile("./testdata/gc.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != string(expected) {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+// TestDarwinUniversal builds a universal binary with -target=darwin-universal
+// and runs both of the executables in it.
+func TestDarwinUniversal(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "darwin" {
+		t.Skip("universal binaries can only be run on macOS")
+	}
+
+	options := optionsFromTarget("darwin-universal", sema)
+	program := filepath.Join(t.TempDir(), "universal")
+	err := Build("./testdata/stdlib.go", program, &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.FailNow()
+	}
+
+	output, err := exec.Command("lipo", "-info", program).CombinedOutput()
+	if err != nil {
+		t.Fatalf("lipo -info failed: %v\n%s", err, output)
+	}
+	archs := strings.Fields(string(output[bytes.LastIndexByte(output, ':')+1:]))
+	if len(archs) != 2 || archs[0] != "x86_64" || archs[1] != "arm64" {
+		t.Fatalf("unexpected architectures in universal binary: %s", output)
+	}
+
+	expected, err := os.ReadFile("./testdata/stdlib.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arch := range archs {
+		arch := arch
+		t.Run(arch, func(t *testing.T) {
+			if arch == "arm64" && runtime.GOARCH != "arm64" {
+				t.Skip("arm64 executables can't be run on an Intel Mac")
+			}
+			// On Apple Silicon, the x86_64 executable runs under Rosetta.
+			output, err := exec.Command("arch", "-"+arch, program).Output()
+			if err != nil {
+				t.Fatalf("could not run the %s executable: %v", arch, err)
+			}
+			if string(output) != string(expected) {
+				t.Errorf("unexpected output of the %s executable:\n%s", arch, output)
+			}
+		})
+	}
+
+	// A universal binary can only be built, not run.
+	if err := Run("./testdata/stdlib.go", &options, nil); err == nil || !strings.Contains(err.Error(), "darwin-universal") {
+		t.Errorf("tinygo run with -target=darwin-universal: got error %v", err)
+	}
+}
+
+// TestCArchive builds testdata/carchive.go with -buildmode=c-archive, links it
+// into the C program in testdata/carchive.c, and checks the output.
+func TestCArchive(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("-buildmode=c-archive is only supported on Linux")
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler found:", err)
+	}
+
+	tmpdir := t.TempDir()
+	options := optionsFromTarget("", sema)
+	options.BuildMode = "c-archive"
+	err = Build("./testdata/carchive.go", filepath.Join(tmpdir, "carchive.a"), &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fail()
+		return
+	}
+
+	// Check that the header declares all exported functions.
+	header, err := os.ReadFile(filepath.Join(tmpdir, "carchive.h"))
+	if err != nil {
+		t.Fatal("could not read header:", err)
+	}
+	for _, prototype := range []string{
+		"int32_t add(int32_t a, int32_t b);",
+		"double scale(double x, float factor);",
+		"bool isInitialized(void);",
+		"int64_t sum(int32_t * values, uintptr_t n);",
+		"void allocate(intptr_t n);",
+	} {
+		if !strings.Contains(string(header), prototype) {
+			t.Errorf("header does not contain %q:\n%s", prototype, header)
+		}
+	}
+
+	// Link the archive into a C program and run it.
+	program := filepath.Join(tmpdir, "carchive")
+	cmd := exec.Command(cc, "-I"+tmpdir, "-o", program, "testdata/carchive.c", filepath.Join(tmpdir, "carchive.a"))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to link C program: %v\n%s", err, output)
+	}
+	output, err := exec.Command(program).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run C program: %v\n%s", err, output)
+	}
+
+	expected, err := os.ReadFile("./testdata/carchive.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != string(expected) {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+// TestPlugin builds testdata/plugin.go with -buildmode=plugin, and loads it at
+// runtime in the TinyGo program in testdata/pluginhost.go.
+func TestPlugin(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("-buildmode=plugin is only supported on Linux")
+	}
+
+	tmpdir := t.TempDir()
+	pluginPath := filepath.Join(tmpdir, "plugin.so")
+	options := optionsFromTarget("", sema)
+	options.BuildMode = "plugin"
+	err := Build("./testdata/plugin.go", pluginPath, &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fail()
+		return
+	}
+	header, err := os.ReadFile(filepath.Join(tmpdir, "plugin.h"))
+	if err != nil {
+		t.Fatal("could not read header:", err)
+	}
+	if !strings.Contains(string(header), "int32_t fib(int32_t n);") {
+		t.Errorf("header does not declare fib:\n%s", header)
+	}
+
+	// The host program calls into the plugin, which can't be done from a
+	// goroutine stack.
+	program := filepath.Join(tmpdir, "host")
+	options = optionsFromTarget("", sema)
+	err = Build("./testdata/pluginhost.go", program, &options)
+	if err == nil || !strings.Contains(err.Error(), "-scheduler=none") {
+		t.Errorf("expected host program with the default scheduler to be rejected, got error: %v", err)
+	}
+
+	// Build the host program, which maps the plugin with the plugin package.
+	options.Scheduler = "none"
+	err = Build("./testdata/pluginhost.go", program, &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fail()
+		return
+	}
+	output, err := exec.Command(program, pluginPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run host program: %v\n%s", err, output)
+	}
+
+	expected, err := os.ReadFile("./testdata/plugin.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != string(expected) {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+// TestPGO builds testdata/pgo.go with and without the CPU profile in
+// pgo/testdata/fib.pprof, which was made from the same code with the standard
+// Go toolchain, and checks that the profile changes how the code is optimized.
+func TestPGO(t *testing.T) {
+	t.Parallel()
+
+	tmpdir := t.TempDir()
+	build := func(name, profile string) string {
+		options := optionsFromTarget("", sema)
+		options.PGO = profile
+		outpath := filepath.Join(tmpdir, name+".ll")
+		err := Build("./testdata/pgo.go", outpath, &options)
+		if err != nil {
+			printCompilerError(t.Log, err)
+			t.FailNow()
+		}
+		ir, err := os.ReadFile(outpath)
+		if err != nil {
+			t.Fatal("could not read IR:", err)
+		}
+		return string(ir)
+	}
+	plain := build("plain", "")
+	optimized := build("pgo", "pgo/testdata/fib.pprof")
+
+	// The hot loops are optimized for speed. They may have been inlined into
+	// a different function, so only check that there is a hot function.
+	hotAttrs := regexp.MustCompile(`(?m)^attributes #\d+ = \{( [a-z]+)* hot `)
+	if hotAttrs.MatchString(plain) {
+		t.Error("found hot functions in a build without profile")
+	}
+	if !hotAttrs.MatchString(optimized) {
+		t.Error("found no hot functions in a build with profile")
+	}
+
+	// main.fib is in the profile, but is too cold to be optimized for speed.
+	for _, ir := range []string{plain, optimized} {
+		if attrs := functionAttributes(ir, "main.fib"); !strings.Contains(attrs, " minsize ") {
+			t.Errorf("expected main.fib to be optimized for size, got attributes: %s", attrs)
+		}
+	}
+}
+
+// functionAttributes returns the attribute group of the function with the
+// given name in the LLVM IR, or the empty string if it isn't found.
+func functionAttributes(ir, name string) string {
+	define := regexp.MustCompile(`(?m)^define .*@` + regexp.QuoteMeta(name) + `\(.*\) .*#(\d+)`).FindStringSubmatch(ir)
+	if define == nil {
+		return ""
+	}
+	return regexp.MustCompile(`(?m)^attributes #` + define[1] + ` = .*$`).FindString(ir)
+}
+
+// TestConstantTime checks that the functions in crypto/subtle compile to code
+// without branches that depend on the values being compared. Branches on the
+// length of slices are fine.
+func TestConstantTime(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{"", "cortex-m-qemu"} {
+		target := target
+		name := target
+		if name == "" {
+			name = "host"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			options := optionsFromTarget(target, sema)
+			outpath := filepath.Join(t.TempDir(), "subtle.ll")
+			err := Build("./testdata/subtle.go", outpath, &options)
+			if err != nil {
+				printCompilerError(t.Log, err)
+				t.FailNow()
+			}
+			ir, err := os.ReadFile(outpath)
+			if err != nil {
+				t.Fatal("could not read IR:", err)
+			}
+			for _, fn := range []string{"Compare", "ByteEq", "Eq", "LessOrEq", "Select", "Copy"} {
+				checkConstantTime(t, string(ir), "main.constantTime"+fn)
+			}
+		})
+	}
+}
+
+// checkConstantTime checks that no branch, comparison or select in the given
+// function depends on secret data: the values loaded from memory and the
+// (non-length) parameters of the function.
+func checkConstantTime(t *testing.T, ir, name string) {
+	body := regexp.MustCompile(`(?ms)^define [^\n]*@` + regexp.QuoteMeta(name) + `\((.*?)\) .*?^}`).FindStringSubmatch(ir)
+	if body == nil {
+		t.Errorf("%s: function not found in the IR", name)
+		return
+	}
+	valueRef := regexp.MustCompile(`%("[^"]*"|[-\w.$]+)`)
+	result := regexp.MustCompile(`^\s*%("[^"]*"|[-\w.$]+) = (\w+)`)
+
+	// Parameters are secret, except for the length of slices.
+	secret := make(map[string]bool)
+	for _, param := range valueRef.FindAllStringSubmatch(body[1], -1) {
+		if !strings.HasSuffix(param[1], ".data") && !strings.HasSuffix(param[1], ".len") && !strings.HasSuffix(param[1], ".cap") && param[1] != "context" {
+			secret[param[1]] = true
+		}
+	}
+
+	// Everything that is loaded from memory or computed from a secret value
+	// is secret as well. Repeat until nothing changes, to handle phi nodes
+	// that refer to values defined later in the function.
+	lines := strings.Split(body[0], "\n")[1:]
+	for changed := true; changed; {
+		changed = false
+		for _, line := range lines {
+			def := result.FindStringSubmatch(line)
+			if def == nil || secret[def[1]] {
+				continue
+			}
+			isSecret := def[2] == "load"
+			for _, ref := range valueRef.FindAllStringSubmatch(line[len(def[0]):], -1) {
+				isSecret = isSecret || secret[ref[1]]
+			}
+			if isSecret {
+				secret[def[1]] = true
+				changed = true
+			}
+		}
+	}
+
+	for _, line := range lines {
+		if strings.Contains(line, "@runtime.alloc(") {
+			t.Errorf("%s: unexpected heap allocation: %s", name, strings.TrimSpace(line))
+		}
+		instruction := strings.TrimSpace(line)
+		if def := result.FindStringSubmatch(line); def != nil {
+			instruction = def[2] + line[len(def[0]):]
+		}
+		if !strings.HasPrefix(instruction, "icmp ") && !strings.HasPrefix(instruction, "select ") && !strings.HasPrefix(instruction, "br ") && !strings.HasPrefix(instruction, "switch ") {
+			continue
+		}
+		for _, ref := range valueRef.FindAllStringSubmatch(instruction, -1) {
+			if secret[ref[1]] {
+				t.Errorf("%s: instruction depends on secret value %%%s: %s", name, ref[1], strings.TrimSpace(line))
+				break
+			}
+		}
+	}
+}
+
+// TestDebugLaunch checks how `tinygo gdb` and `tinygo lldb` start a debug
+// session, without actually starting a debugger.
+func TestDebugLaunch(t *testing.T) {
+	t.Parallel()
+
+	// Check which GDB server is used for a few targets.
+	for target, expected := range map[string]string{
+		"":                "native", // host
+		"cortex-m-qemu":   "qemu",
+		"riscv-qemu":      "qemu",
+		"gameboy-advance": "mgba",
+		"atmega1284p":     "simavr",
+		"pca10040":        "openocd",
+	} {
+		options := optionsFromTarget(target, sema)
+		config, err := builder.NewConfig(&options)
+		if err != nil {
+			t.Errorf("%s: %v", target, err)
+			continue
+		}
+		if gdbInterface := debugInterface(config); gdbInterface != expected {
+			t.Errorf("%s: expected interface %s, got %s", target, expected, gdbInterface)
+		}
+	}
+
+	// Check the debugger command lines. They must all load the symbols and
+	// stop at main.main.
+	for _, tc := range []struct {
+		name        string
+		debugger    string
+		port        string
+		gdbCommands []string
+		expected    []string
+	}{
+		{
+			name:     "gdb native",
+			debugger: "gdb",
+			expected: []string{"prog.elf", "-ex", "break main.main", "-ex", "run"},
+		},
+		{
+			name:        "gdb openocd",
+			debugger:    "gdb",
+			port:        ":3333",
+			gdbCommands: []string{"monitor halt", "load", "monitor reset halt"},
+			expected: []string{"prog.elf",
+				"-ex", "target extended-remote :3333",
+				"-ex", "monitor halt",
+				"-ex", "load",
+				"-ex", "monitor reset halt",
+				"-ex", "break main.main",
+				"-ex", "continue"},
+		},
+		{
+			name:     "lldb native",
+			debugger: "lldb",
+			expected: []string{"prog.elf", "--arch", "thumbv7em-unknown-unknown-eabi",
+				"-o", "breakpoint set --name main.main",
+				"-o", "process launch"},
+		},
+		{
+			name:        "lldb jlink",
+			debugger:    "lldb",
+			port:        ":2331",
+			gdbCommands: []string{"load", "monitor reset halt"},
+			expected: []string{"prog.elf", "--arch", "thumbv7em-unknown-unknown-eabi",
+				"-o", "gdb-remote 2331",
+				"-o", "target modules load --load --slide 0",
+				"-o", "process plugin packet monitor reset halt",
+				"-o", "breakpoint set --name main.main",
+				"-o", "process continue"},
+		},
+	} {
+		params, err := debuggerArgs(tc.debugger, "prog.elf", "thumbv7em-unknown-unknown-eabi", tc.port, tc.gdbCommands)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(params, tc.expected) {
+			t.Errorf("%s: unexpected command line:\n%q\nexpected:\n%q", tc.name, params, tc.expected)
+		}
+	}
+
+	// LLDB doesn't know all GDB commands.
+	if _, err := debuggerArgs("lldb", "prog.elf", "thumbv7em-unknown-unknown-eabi", ":1234", []string{"attach 1"}); err == nil {
+		t.Error("expected an error for a GDB command that can't be converted to LLDB")
+	}
+}
+
+// TestAddr2Line checks that -debug=compressed writes a separate symbol file
+// next to the firmware image and that this file can be used to symbolize
+// addresses.
+func TestAddr2Line(t *testing.T) {
+	t.Parallel()
+
+	options := optionsFromTarget("cortex-m-qemu", sema)
+	options.DebugFormat = "compressed"
+	outpath := filepath.Join(t.TempDir(), "alias.hex")
+	err := Build("testdata/alias.go", outpath, &options)
+	if err != nil {
+		t.Fatal("failed to build:", err)
+	}
+
+	// Look up the address of main.main in the symbol file.
+	f, err := elf.Open(outpath + ".debug")
+	if err != nil {
+		t.Fatal("could not open symbol file:", err)
+	}
+	defer f.Close()
+	for _, section := range f.Sections {
+		if strings.HasPrefix(section.Name, ".debug_") && section.Flags&elf.SHF_COMPRESSED == 0 {
+			t.Errorf("section %s is not compressed", section.Name)
+		}
+	}
+	symbols, err := f.Symbols()
+	if err != nil {
+		t.Fatal("could not read symbols:", err)
+	}
+	var addr uint64
+	for _, symbol := range symbols {
+		if symbol.Name == "main.main" {
+			addr = symbol.Value &^ 1 // strip the Thumb bit
+		}
+	}
+	if addr == 0 {
+		t.Fatal("main.main not found in symbol file")
+	}
+
+	buf := &bytes.Buffer{}
+	err = Addr2Line(buf, outpath+".debug", []string{fmt.Sprintf("%#x", addr)})
+	if err != nil {
+		t.Fatal("addr2line failed:", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "main.main" || !strings.Contains(lines[1], "alias.go:") {
+		t.Errorf("unexpected addr2line output:\n%s", buf.String())
+	}
+}
+
+// TestStackUsageReport builds a program for cortex-m-qemu with
+// -stack-usage-report and checks the report printed during the build.
+func TestStackUsageReport(t *testing.T) {
+	// Not parallel: the report is printed to os.Stdout, which is replaced by
+	// a pipe during the build to capture it. Parallel tests only start after
+	// all other tests have finished, so they don't print to this pipe.
+	options := optionsFromTarget("cortex-m-qemu", sema)
+	options.StackReport = true
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	output := make(chan []byte)
+	go func() {
+		buf, _ := io.ReadAll(r)
+		output <- buf
+	}()
+	stdout := os.Stdout
+	os.Stdout = w
+	err = Build("testdata/stackreport.go", filepath.Join(t.TempDir(), "stackreport.elf"), &options)
+	os.Stdout = stdout
+	w.Close()
+	report := string(<-output)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.FailNow()
+	}
+
+	functions, stacks, ok := strings.Cut(report, "\n\n")
+	if !ok {
+		t.Fatalf("no stacks in the report:\n%s", report)
+	}
+
+	// The functions are sorted by frame size, largest first.
+	frameSizes := make(map[string]uint64)
+	lines := strings.Split(functions, "\n")
+	if lines[0] != "function                         frame size (in bytes)" {
+		t.Fatalf("unexpected header in the report:\n%s", report)
+	}
+	previous := uint64(1<<64 - 1)
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "(") {
+			continue // functions without frame size information
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			t.Fatalf("unexpected function line %q in the report", line)
+		}
+		size, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			t.Fatalf("unexpected frame size in %q: %v", line, err)
+		}
+		if size > previous {
+			t.Errorf("function %s is not sorted by frame size", fields[0])
+		}
+		previous = size
+		frameSizes[fields[0]] = size
+	}
+	for _, name := range []string{"main.worker", "main.fill"} {
+		if _, ok := frameSizes[name]; !ok {
+			t.Errorf("function %s is missing from the report", name)
+		}
+	}
+
+	// Each stack with a known size is followed by the call path responsible
+	// for its worst-case usage, and the frames on that path add up to the
+	// total. A goroutine stack may be bigger than that, as it needs at least
+	// the stack of tinygo_startTask.
+	lines = strings.Split(strings.TrimSpace(stacks), "\n")
+	if lines[0] != "stack                            worst-case usage (in bytes)" {
+		t.Fatalf("unexpected header in the report:\n%s", report)
+	}
+	found := make(map[string]bool)
+	for i := 1; i < len(lines); {
+		fields := strings.Fields(lines[i])
+		i++
+		found[fields[0]] = true
+		total, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			if fields[0] == "main.worker" {
+				t.Errorf("no worst-case usage for the goroutine stack: %s", lines[i-1])
+			}
+			continue
+		}
+		var sum uint64
+		for ; i < len(lines) && strings.HasPrefix(lines[i], "    "); i++ {
+			frame := strings.Fields(lines[i])
+			size, _ := strconv.ParseUint(frame[1], 10, 64)
+			if size != frameSizes[frame[0]] {
+				t.Errorf("stack %s: frame size of %s is %d, but %d in the function list", fields[0], frame[0], size, frameSizes[frame[0]])
+			}
+			sum += size
+		}
+		if sum != total && (fields[0] == "Reset_Handler" || sum > total) {
+			t.Errorf("stack %s: frames on the worst-case path add up to %d, want %d", fields[0], sum, total)
+		}
+	}
+	for _, name := range []string{"Reset_Handler", "main.worker"} {
+		if !found[name] {
+			t.Errorf("stack %s is missing from the report:\n%s", name, report)
+		}
+	}
+}
+
+// TestBuildInfoDependency checks that runtime/debug.ReadBuildInfo reports the
+// version of a module dependency and its replacement. The test program is a
+// separate module, with the dependency replaced by a local directory so that no
+// network access is needed.
+func TestBuildInfoDependency(t *testing.T) {
+	t.Parallel()
+
+	options := optionsFromTarget("", sema)
+	options.Directory = filepath.Join(TESTDATA, "buildinfodeps")
+	config, err := builder.NewConfig(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+	err = buildAndRun(".", config, stdout, nil, nil, time.Minute, func(cmd *exec.Cmd, result builder.BuildResult) error {
+		return cmd.Run()
+	})
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.FailNow()
+	}
+	expected := "hello from dep\n" +
+		"path: example.com/buildinfo\n" +
+		"main module: example.com/buildinfo (devel)\n" +
+		"dep: example.com/dep v1.2.3 true\n" +
+		"replaced by: ./dep (devel)\n"
+	if stdout.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", stdout.String(), expected)
+	}
+}
+
 func TestGetListOfPackages(t *testing.T) {
 	opts := optionsFromTarget("", sema)
 	tests := []struct {
@@ -575,6 +1710,34 @@ func TestGetListOfPackages(t *testing.T) {
 	}
 }
 
+// TestGenericPackagesImports checks that the slices, maps and cmp packages are
+// implemented without the reflect package, so that using them on a device
+// doesn't pull it in.
+func TestGenericPackagesImports(t *testing.T) {
+	t.Parallel()
+
+	options := optionsFromTarget("cortex-m-qemu", sema)
+	config, err := builder.NewConfig(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := loader.List(config, []string{"-deps"}, []string{"slices", "maps", "cmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatal("go list failed:", err)
+	}
+	for _, pkg := range strings.Fields(stdout.String()) {
+		if pkg == "reflect" {
+			t.Errorf("slices, maps or cmp imports reflect, dependencies:\n%s", stdout.String())
+		}
+	}
+}
+
 // This TestMain is necessary because TinyGo may also be invoked to run certain
 // LLVM tools in a separate process. Not capturing these invocations would lead
 // to recursive tests.
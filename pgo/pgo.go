@@ -0,0 +1,380 @@
+// Package pgo reads CPU profiles in the pprof format, for profile-guided
+// optimization.
+//
+// Only the information needed by the compiler is extracted: the sampled call
+// stacks and the time spent in each. Profiles are usually created by the
+// standard Go toolchain (for example with `go test -cpuprofile` on the host),
+// so function names are converted to the names TinyGo uses for the same
+// functions.
+package pgo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Profile is a CPU profile: a list of call stacks with the time spent in each.
+type Profile struct {
+	Samples []Sample
+
+	// Total is the sum of all sample weights in the profile.
+	Total int64
+}
+
+// Sample is a single call stack in the profile.
+type Sample struct {
+	// Stack is the list of functions on the call stack, innermost function
+	// first. The names are converted to the names TinyGo uses in the LLVM IR.
+	// Functions that were inlined by the Go toolchain are included.
+	Stack []string
+
+	// Weight is the time spent in this call stack. In profiles created by Go,
+	// this is the CPU time in nanoseconds.
+	Weight int64
+}
+
+// ReadFile reads and parses the profile at the given path.
+func ReadFile(path string) (*Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	prof, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read profile %s: %w", path, err)
+	}
+	return prof, nil
+}
+
+// Parse parses a pprof profile, which may or may not be gzip compressed.
+func Parse(r io.Reader) (*Profile, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	} else {
+		r = br
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseProfile(data)
+}
+
+// Weights returns the time spent in each function, not including the functions
+// it calls (the "flat" time in pprof).
+func (p *Profile) Weights() map[string]int64 {
+	weights := make(map[string]int64)
+	for _, s := range p.Samples {
+		if len(s.Stack) != 0 {
+			weights[s.Stack[0]] += s.Weight
+		}
+	}
+	return weights
+}
+
+// HotFunctions returns the functions that should be optimized for speed. These
+// are the smallest set of functions that together account for at least the
+// given fraction (between 0 and 1) of all samples, and the functions that call
+// them in the profile. The callers are included because the hot code will
+// often end up in them after inlining. Functions that were never sampled are
+// never included.
+func (p *Profile) HotFunctions(fraction float64) map[string]bool {
+	weights := p.Weights()
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if weights[names[i]] != weights[names[j]] {
+			return weights[names[i]] > weights[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	leaves := make(map[string]bool)
+	var sum int64
+	for _, name := range names {
+		if float64(sum) >= fraction*float64(p.Total) || weights[name] <= 0 {
+			break
+		}
+		leaves[name] = true
+		sum += weights[name]
+	}
+
+	hot := make(map[string]bool)
+	for _, s := range p.Samples {
+		if len(s.Stack) == 0 || !leaves[s.Stack[0]] {
+			continue
+		}
+		for _, name := range s.Stack {
+			hot[name] = true
+		}
+	}
+	return hot
+}
+
+// Field numbers of the profile.proto messages that are used here. See:
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileLocation    = 4
+	profileFunction    = 5
+	profileStringTable = 6
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID   = 1
+	functionName = 2
+)
+
+type rawSample struct {
+	locationIDs []uint64
+	values      []uint64
+}
+
+func parseProfile(data []byte) (*Profile, error) {
+	var (
+		numSampleTypes int
+		samples        []rawSample
+		stringTable    []string
+		locations      = make(map[uint64][]uint64) // location ID -> function IDs
+		functions      = make(map[uint64]int64)    // function ID -> name index
+	)
+	err := forEachField(data, func(field int, wireType int, value uint64, buf []byte) error {
+		switch field {
+		case profileSampleType:
+			numSampleTypes++
+		case profileSample:
+			var s rawSample
+			err := forEachField(buf, func(field int, wireType int, value uint64, buf []byte) error {
+				switch field {
+				case sampleLocationID:
+					return appendRepeated(&s.locationIDs, wireType, value, buf)
+				case sampleValue:
+					return appendRepeated(&s.values, wireType, value, buf)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			samples = append(samples, s)
+		case profileLocation:
+			var id uint64
+			var lines []uint64
+			err := forEachField(buf, func(field int, wireType int, value uint64, buf []byte) error {
+				switch field {
+				case locationID:
+					id = value
+				case locationLine:
+					// With inlining, a location has several lines: the
+					// innermost function comes first, followed by the
+					// functions it was inlined into.
+					return forEachField(buf, func(field int, wireType int, value uint64, buf []byte) error {
+						if field == lineFunctionID {
+							lines = append(lines, value)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			locations[id] = lines
+		case profileFunction:
+			var id uint64
+			var name int64
+			err := forEachField(buf, func(field int, wireType int, value uint64, buf []byte) error {
+				switch field {
+				case functionID:
+					id = value
+				case functionName:
+					name = int64(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			functions[id] = name
+		case profileStringTable:
+			stringTable = append(stringTable, string(buf))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if numSampleTypes == 0 {
+		return nil, errors.New("not a pprof profile: no sample types")
+	}
+
+	// Use the last sample value, which for CPU profiles created by Go is the
+	// CPU time (the first is the number of samples).
+	prof := &Profile{}
+	valueIndex := numSampleTypes - 1
+	for _, s := range samples {
+		if len(s.values) != numSampleTypes {
+			return nil, errors.New("invalid profile: sample has the wrong number of values")
+		}
+		sample := Sample{Weight: int64(s.values[valueIndex])}
+		for _, loc := range s.locationIDs {
+			for _, fn := range locations[loc] {
+				nameIndex, ok := functions[fn]
+				if !ok || nameIndex < 0 || nameIndex >= int64(len(stringTable)) {
+					continue
+				}
+				sample.Stack = append(sample.Stack, linkName(stringTable[nameIndex]))
+			}
+		}
+		prof.Total += sample.Weight
+		prof.Samples = append(prof.Samples, sample)
+	}
+	return prof, nil
+}
+
+// forEachField calls fn for each field in the protobuf message in data. For
+// varint fields, value is set. For length-delimited fields, buf is set.
+func forEachField(data []byte, fn func(field int, wireType int, value uint64, buf []byte) error) error {
+	for len(data) != 0 {
+		key, n := readVarint(data)
+		if n <= 0 {
+			return errors.New("invalid profile: bad field key")
+		}
+		data = data[n:]
+		field, wireType := int(key>>3), int(key&7)
+		var value uint64
+		var buf []byte
+		switch wireType {
+		case 0: // varint
+			value, n = readVarint(data)
+			if n <= 0 {
+				return errors.New("invalid profile: bad varint")
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			data = data[8:]
+		case 2: // length-delimited
+			length, n := readVarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return errors.New("invalid profile: bad length")
+			}
+			buf = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("invalid profile: unknown wire type %d", wireType)
+		}
+		if err := fn(field, wireType, value, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendRepeated appends the values of a repeated integer field, which may be
+// either packed (length-delimited) or a single varint.
+func appendRepeated(values *[]uint64, wireType int, value uint64, buf []byte) error {
+	if wireType != 2 {
+		*values = append(*values, value)
+		return nil
+	}
+	for len(buf) != 0 {
+		v, n := readVarint(buf)
+		if n <= 0 {
+			return errors.New("invalid profile: bad packed varint")
+		}
+		*values = append(*values, v)
+		buf = buf[n:]
+	}
+	return nil
+}
+
+// readVarint reads a varint from buf, returning the value and the number of
+// bytes read. It returns n <= 0 on error.
+func readVarint(buf []byte) (uint64, int) {
+	var x uint64
+	var s uint
+	for i, b := range buf {
+		if i == 10 {
+			return 0, -1 // overflow
+		}
+		if b < 0x80 {
+			return x | uint64(b)<<s, i + 1
+		}
+		x |= uint64(b&0x7f) << s
+		s += 7
+	}
+	return 0, 0
+}
+
+// linkName converts a function name as it appears in profiles from the
+// standard Go toolchain to the name TinyGo uses. For example:
+//
+//	main.(*T).Method  -> (*main.T).Method
+//	main.T.Method     -> (main.T).Method
+//	main.main.func1.2 -> main.main$1$2
+//
+// Names that can't be converted are returned unchanged, and will simply not
+// match any function.
+func linkName(name string) string {
+	// Split off the package path: the package name ends at the first dot
+	// after the last slash. Dots in the last path element are escaped by the
+	// Go linker (gopkg.in/yaml%2ev3).
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return name
+	}
+	pkg := strings.ReplaceAll(name[:slash+1+dot], "%2e", ".")
+	parts := strings.Split(name[slash+1+dot+1:], ".")
+
+	// Strip closure suffixes (funcN, or just N for nested closures).
+	var closures string
+	for len(parts) > 1 {
+		last := parts[len(parts)-1]
+		num := strings.TrimPrefix(last, "func")
+		if num == "" || strings.Trim(num, "0123456789") != "" {
+			break
+		}
+		closures = "$" + num + closures
+		parts = parts[:len(parts)-1]
+	}
+
+	switch {
+	case len(parts) == 1:
+		return pkg + "." + parts[0] + closures
+	case len(parts) == 2 && strings.HasPrefix(parts[0], "(*") && strings.HasSuffix(parts[0], ")"):
+		return "(*" + pkg + "." + parts[0][2:] + "." + parts[1] + closures
+	case len(parts) == 2:
+		return "(" + pkg + "." + parts[0] + ")." + parts[1] + closures
+	}
+	return name
+}
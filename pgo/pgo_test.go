@@ -0,0 +1,93 @@
+package pgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	prof, err := ReadFile("testdata/fib.pprof")
+	if err != nil {
+		t.Fatal("could not read profile:", err)
+	}
+	if prof.Total <= 0 {
+		t.Fatalf("expected a positive total, got %d", prof.Total)
+	}
+	var sum int64
+	for _, weight := range prof.Weights() {
+		sum += weight
+	}
+	if sum != prof.Total {
+		t.Errorf("function weights add up to %d, expected %d", sum, prof.Total)
+	}
+
+	// The profile was made with the standard Go toolchain, so the names must
+	// be converted to the names TinyGo uses. The callers of the hot functions
+	// are included, but main.fib (which is called from main.main) is not.
+	hot := prof.HotFunctions(0.9)
+	expected := map[string]bool{
+		"(*main.adder).add": true,
+		"main.main$1":       true,
+		"main.main":         true,
+		"runtime.main":      true,
+	}
+	if !reflect.DeepEqual(hot, expected) {
+		t.Errorf("unexpected hot functions: %v", hot)
+	}
+	if prof.Weights()["main.fib"] == 0 {
+		t.Error("main.fib is missing from the profile")
+	}
+	if !prof.HotFunctions(1)["main.fib"] {
+		t.Error("expected main.fib to be hot with a fraction of 1")
+	}
+	if len(prof.HotFunctions(0)) != 0 {
+		t.Error("expected no hot functions with a fraction of 0")
+	}
+}
+
+func TestParseUncompressed(t *testing.T) {
+	data, err := os.ReadFile("testdata/fib.pprof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prof, err := Parse(gz)
+	if err != nil {
+		t.Fatal("could not parse uncompressed profile:", err)
+	}
+	if prof.Weights()["(*main.adder).add"] == 0 {
+		t.Error("(*main.adder).add is missing from the profile")
+	}
+
+	if _, err := Parse(bytes.NewReader([]byte("not a profile"))); err == nil {
+		t.Error("expected an error for invalid input")
+	}
+}
+
+func TestLinkName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"main.main", "main.main"},
+		{"main.(*T).Method", "(*main.T).Method"},
+		{"main.T.Method", "(main.T).Method"},
+		{"main.main.func1", "main.main$1"},
+		{"main.main.func1.2", "main.main$1$2"},
+		{"main.(*T).Method.func3", "(*main.T).Method$3"},
+		{"github.com/foo/bar.Baz", "github.com/foo/bar.Baz"},
+		{"github.com/foo/bar.(*Baz).Qux", "(*github.com/foo/bar.Baz).Qux"},
+		{"gopkg.in/yaml%2ev3.Marshal", "gopkg.in/yaml.v3.Marshal"},
+		{"runtime", "runtime"},
+	} {
+		if got := linkName(tc.name); got != tc.want {
+			t.Errorf("linkName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// This program generates fib.pprof, a CPU profile used by the tests. Regenerate
+// it with inlining disabled so that each function shows up in the profile:
+//
+//	go build -gcflags=-l -o fib fib.go && ./fib
+package main
+
+import (
+	"os"
+	"runtime/pprof"
+)
+
+type adder struct{ n int }
+
+func (a *adder) add(x int) {
+	for i := 0; i < x; i++ {
+		a.n += i ^ a.n
+	}
+}
+
+func fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+func main() {
+	f, _ := os.Create("fib.pprof")
+	pprof.StartCPUProfile(f)
+	a := &adder{}
+	square := func(x int) int {
+		s := 0
+		for i := 0; i < x; i++ {
+			s += i * i % 7
+		}
+		return s
+	}
+	for i := 0; i < 40; i++ {
+		a.add(fib(28) * 100)
+		a.n += square(2000000)
+	}
+	pprof.StopCPUProfile()
+	f.Close()
+	println(a.n)
+}
@@ -0,0 +1,91 @@
+package bytes_test
+
+import (
+	. "bytes"
+	"testing"
+)
+
+func TestBufferLinearGrowth(t *testing.T) {
+	var buf Buffer
+	var data []byte
+	lastCap := 0
+	for i := 0; i < 5*LinearGrowthThreshold; i++ {
+		c := byte('a' + i%26)
+		buf.WriteByte(c)
+		data = append(data, c)
+		if buf.Cap() == lastCap {
+			continue
+		}
+		switch {
+		case lastCap == 0:
+			if buf.Cap() != SmallBufferSize {
+				t.Errorf("initial capacity: got %d, want %d", buf.Cap(), SmallBufferSize)
+			}
+		case lastCap < LinearGrowthThreshold:
+			// Small buffers double in size, but not beyond the threshold.
+			want := 2 * lastCap
+			if want > LinearGrowthThreshold {
+				want = LinearGrowthThreshold
+			}
+			if buf.Cap() != want {
+				t.Errorf("grow from %d: got capacity %d, want %d", lastCap, buf.Cap(), want)
+			}
+		default:
+			// Large buffers grow linearly.
+			if want := lastCap + LinearGrowthThreshold; buf.Cap() != want {
+				t.Errorf("grow from %d: got capacity %d, want %d", lastCap, buf.Cap(), want)
+			}
+		}
+		lastCap = buf.Cap()
+	}
+	if !Equal(buf.Bytes(), data) {
+		t.Error("buffer contains the wrong data")
+	}
+
+	// Grow must still make room for large requests at once.
+	buf.Grow(10 * LinearGrowthThreshold)
+	if free := buf.Cap() - buf.Len(); free < 10*LinearGrowthThreshold {
+		t.Errorf("Grow: got %d bytes of free space, want at least %d", free, 10*LinearGrowthThreshold)
+	}
+	if !Equal(buf.Bytes(), data) {
+		t.Error("Grow changed the buffer contents")
+	}
+}
+
+func TestBufferResetReuse(t *testing.T) {
+	var buf Buffer
+	buf.Write(make([]byte, 3*LinearGrowthThreshold))
+	capacity := buf.Cap()
+	for i := 0; i < 10; i++ {
+		buf.Reset()
+		if buf.Len() != 0 {
+			t.Fatalf("Reset: got length %d, want 0", buf.Len())
+		}
+		for j := 0; j < 3*LinearGrowthThreshold; j++ {
+			buf.WriteByte(byte(i + j))
+		}
+		if buf.Cap() != capacity {
+			t.Fatalf("capacity changed after Reset: got %d, want %d", buf.Cap(), capacity)
+		}
+		for j, c := range buf.Bytes() {
+			if c != byte(i+j) {
+				t.Fatalf("iteration %d: wrong byte at index %d", i, j)
+			}
+		}
+	}
+}
+
+func BenchmarkBufferWriteReset(b *testing.B) {
+	chunk := make([]byte, 512)
+	var buf Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		for j := 0; j < 16; j++ {
+			buf.Write(chunk)
+		}
+		if buf.Len() != 16*len(chunk) {
+			b.Fatalf("unexpected length %d", buf.Len())
+		}
+	}
+}
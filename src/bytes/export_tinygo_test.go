@@ -0,0 +1,7 @@
+package bytes
+
+// Export constants of the growth policy of Buffer for testing.
+const (
+	SmallBufferSize       = smallBufferSize
+	LinearGrowthThreshold = linearGrowthThreshold
+)
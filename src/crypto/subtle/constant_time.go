@@ -0,0 +1,86 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package subtle implements functions that are often useful in cryptographic
+// code but require careful thought to use correctly.
+//
+// This is the TinyGo version of the package. The upstream implementation
+// relies on the compiler not being clever: LLVM can see that the arithmetic in
+// these functions only produces 0 or 1, and is free to replace it with
+// comparisons and branches (or, for ConstantTimeCompare, an early exit). To
+// prevent that, intermediate values are passed through volatile memory, which
+// the optimizer can't see through.
+package subtle
+
+import "runtime/volatile"
+
+// ConstantTimeCompare returns 1 if the two slices, x and y, have equal contents
+// and 0 otherwise. The time taken is a function of the length of the slices and
+// is independent of the contents. If the lengths of x and y do not match it
+// returns 0 immediately.
+func ConstantTimeCompare(x, y []byte) int {
+	if len(x) != len(y) {
+		return 0
+	}
+
+	// Accumulate the differences in volatile memory, so that the loop can't
+	// be stopped as soon as a difference has been found.
+	var v byte
+	for i := 0; i < len(x); i++ {
+		volatile.StoreUint8(&v, volatile.LoadUint8(&v)|(x[i]^y[i]))
+	}
+
+	return ConstantTimeByteEq(volatile.LoadUint8(&v), 0)
+}
+
+// ConstantTimeSelect returns x if v == 1 and y if v == 0.
+// Its behavior is undefined if v takes any other value.
+func ConstantTimeSelect(v, x, y int) int {
+	v = int(opaque(uint32(v)))
+	return ^(v-1)&x | (v-1)&y
+}
+
+// ConstantTimeByteEq returns 1 if x == y and 0 otherwise.
+func ConstantTimeByteEq(x, y uint8) int {
+	return int((opaque(uint32(x^y)) - 1) >> 31)
+}
+
+// ConstantTimeEq returns 1 if x == y and 0 otherwise.
+func ConstantTimeEq(x, y int32) int {
+	return int((uint64(opaque(uint32(x^y))) - 1) >> 63)
+}
+
+// ConstantTimeCopy copies the contents of y into x (a slice of equal length)
+// if v == 1. If v == 0, x is left unchanged. Its behavior is undefined if v
+// takes any other value.
+func ConstantTimeCopy(v int, x, y []byte) {
+	if len(x) != len(y) {
+		panic("subtle: slices have different lengths")
+	}
+
+	v = int(opaque(uint32(v)))
+	xmask := byte(v - 1)
+	ymask := byte(^(v - 1))
+	for i := 0; i < len(x); i++ {
+		x[i] = x[i]&xmask | y[i]&ymask
+	}
+}
+
+// ConstantTimeLessOrEq returns 1 if x <= y and 0 otherwise.
+// Its behavior is undefined if x or y are negative or > 2**31 - 1.
+func ConstantTimeLessOrEq(x, y int) int {
+	x32 := int32(x)
+	y32 := int32(y)
+	return int((opaque(uint32(x32-y32-1)) >> 31) & 1)
+}
+
+// opaque returns x, after storing it in and loading it from volatile memory.
+// The compiler has to assume the loaded value can be anything, so it can't
+// replace arithmetic on it with a comparison. The volatile operations are done
+// directly on a local variable (instead of using volatile.Register32), so
+// that it stays on the stack.
+func opaque(x uint32) uint32 {
+	volatile.StoreUint32(&x, x)
+	return volatile.LoadUint32(&x)
+}
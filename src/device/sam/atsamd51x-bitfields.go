@@ -70,3 +70,11 @@ const (
 	PCHCTRL_GCLK_SDHC1              = 46 // SDHC1
 	PCHCTRL_GCLK_CM4_TRACE          = 47 // CM4 Trace
 )
+
+// These are the DMAC trigger sources (CHCTRLA.TRIGSRC) used in the machine
+// package. See the DMAC chapter of the datasheet linked above.
+const (
+	DMAC_TRIGSRC_DISABLE = 0x00 // Only software/event triggers
+	DMAC_TRIGSRC_TC2_OVF = 0x32 // TC2 Overflow
+	DMAC_TRIGSRC_TC3_OVF = 0x35 // TC3 Overflow
+)
@@ -0,0 +1,39 @@
+// Example that plays a sine wave on the DAC of a SAMD51 board (such as the
+// PyPortal), using DMA to stream the samples in the background.
+package main
+
+import (
+	"machine"
+	"math"
+	"time"
+)
+
+const (
+	sampleRate = 44100
+	tone       = 441 // Hz
+)
+
+var samples [sampleRate / tone]uint16
+
+func main() {
+	// Calculate one period of the sine wave, as 12-bit samples.
+	for i := range samples {
+		samples[i] = uint16(2047.5 + 2047.5*math.Sin(2*math.Pi*float64(i)/float64(len(samples))))
+	}
+
+	machine.A0.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	machine.DAC0.Configure(machine.DACConfig{})
+
+	for {
+		// Play the tone for one second, then be silent for one second. The CPU
+		// is free to do other work while the tone is playing.
+		err := machine.DAC0.StartStream(samples[:], sampleRate, true)
+		if err != nil {
+			println("could not start stream:", err.Error())
+			return
+		}
+		time.Sleep(time.Second)
+		machine.DAC0.StopStream()
+		time.Sleep(time.Second)
+	}
+}
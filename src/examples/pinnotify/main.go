@@ -0,0 +1,29 @@
+package main
+
+// This example receives pin change events on a channel, so that they can be
+// handled by a regular goroutine instead of in the interrupt handler.
+//
+// Like the pininterrupt example, this doesn't do any debouncing: a single
+// button press usually results in multiple events.
+
+import (
+	"machine"
+)
+
+const (
+	button = machine.BUTTON
+	led    = machine.LED
+)
+
+func main() {
+	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	button.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+
+	for event := range button.NotifyChan(machine.PinToggle) {
+		// Buttons usually short to ground when pressed, so mirror the button
+		// state on the (usually active low) LED.
+		led.Set(event.High)
+		println("button pressed:", !event.High, "dropped events:", machine.PinEventsDropped())
+	}
+	println("could not configure pin interrupt")
+}
@@ -0,0 +1,95 @@
+package main
+
+// This example shows how to run goroutines when TinyGo doesn't own the main
+// loop, for example when it is integrated in a product based on an RTOS. Build
+// it with -scheduler=external.
+//
+// The main function implements a very simple RTOS-like driver: it runs a few
+// periodic tasks in a loop, one of which pumps the Go scheduler by calling
+// runtime.SchedulerStep. In a real system, that task would be created with the
+// API of the RTOS (like xTaskCreate in FreeRTOS) and call into an exported Go
+// function that calls runtime.SchedulerStep.
+//
+// The main function (and every function called from it, like the tasks below)
+// runs outside of a goroutine, so it must not block on a channel or mutex:
+// that results in a runtime panic. Communicate with goroutines using select
+// with a default case, or by starting a goroutine.
+
+import (
+	"runtime"
+	"time"
+)
+
+// rtosTask is a task of the RTOS-like driver, that is run every period. The
+// run function may return a shorter time after which it wants to run again, or
+// -1 to keep the period.
+type rtosTask struct {
+	name   string
+	period time.Duration
+	next   time.Time
+	run    func() time.Duration
+}
+
+func main() {
+	// These goroutines are queued, but don't run until the Go scheduler task
+	// below calls runtime.SchedulerStep.
+	values := make(chan int)
+	go produce(values)
+	go consume(values)
+
+	tasks := []*rtosTask{
+		{
+			name:   "heartbeat",
+			period: 500 * time.Millisecond,
+			run: func() time.Duration {
+				println("rtos: heartbeat, goroutines:", runtime.NumGoroutine())
+				return -1
+			},
+		},
+		{
+			name:   "go scheduler",
+			period: 10 * time.Millisecond,
+			run: func() time.Duration {
+				// Let goroutines run, and find out when they need to run
+				// next. When all goroutines are blocked (-1), a real RTOS
+				// task could wait for a notification from an interrupt.
+				return time.Duration(runtime.SchedulerStep())
+			},
+		},
+	}
+
+	// The main loop of the driver: run every task when it is due, and sleep
+	// until the next one is.
+	for {
+		now := time.Now()
+		next := now.Add(time.Second)
+		for _, task := range tasks {
+			if !now.Before(task.next) {
+				task.next = now.Add(task.period)
+				if wakeup := task.run(); wakeup >= 0 && wakeup < task.period {
+					// The task wants to run sooner.
+					task.next = now.Add(wakeup)
+				}
+			}
+			if task.next.Before(next) {
+				next = task.next
+			}
+		}
+		time.Sleep(time.Until(next))
+	}
+}
+
+// produce sends an incrementing value every 200ms.
+func produce(values chan<- int) {
+	for i := 0; ; i++ {
+		time.Sleep(200 * time.Millisecond)
+		values <- i
+	}
+}
+
+// consume prints all values it receives.
+func consume(values <-chan int) {
+	for value := range values {
+		println("goroutine: received", value)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+// This example cycles the on-board NeoPixel (a WS2812 LED) through red, green
+// and blue. It works on boards that define machine.NEOPIXEL.
+
+import (
+	"machine"
+	"time"
+)
+
+func main() {
+	led := machine.WS2812{Pin: machine.NEOPIXEL}
+	if err := led.Configure(); err != nil {
+		println("failed to configure WS2812:", err.Error())
+		return
+	}
+
+	// The colors in the order the LED expects them: green, red, blue.
+	colors := [][]byte{
+		{0x00, 0x20, 0x00}, // red
+		{0x20, 0x00, 0x00}, // green
+		{0x00, 0x00, 0x20}, // blue
+	}
+	for {
+		for _, color := range colors {
+			led.Write(color)
+			time.Sleep(time.Second / 2)
+		}
+	}
+}
@@ -26,6 +26,47 @@ type Task struct {
 	DeferFrame unsafe.Pointer
 }
 
+// numTasks is the number of goroutines that have been started and that have
+// not exited yet. Goroutines that are blocked forever are not counted, as they
+// will never run again.
+var numTasks int
+
+// Count returns the number of goroutines that currently exist, including the
+// main goroutine.
+func Count() int {
+	return numTasks
+}
+
+// callbackTask is the current task while a function runs outside of a
+// goroutine, see RunCallback. It is never paused: blocking panics with
+// callbackBlockMessage instead.
+var (
+	callbackTask         Task
+	callbackBlockMessage string
+)
+
+// Exited must be called when the current goroutine will never run again, for
+// example because it is blocked forever.
+func Exited() {
+	numTasks--
+}
+
+// Started must be called when a goroutine for which Exited was called will run
+// again after all.
+func Started() {
+	numTasks++
+}
+
+// traceBlock and traceExit are called when the current goroutine pauses or
+// exits, for runtime.SetTraceHandler. They do nothing unless scheduler tracing
+// is enabled.
+
+//go:linkname traceBlock runtime.traceBlock
+func traceBlock(t *Task)
+
+//go:linkname traceExit runtime.traceExit
+func traceExit(t *Task)
+
 // getGoroutineStackSize is a compiler intrinsic that returns the stack size for
 // the given function and falls back to the default stack size. It is replaced
 // with a load from a special section just before codegen.
@@ -29,6 +29,10 @@ type state struct {
 	stackState
 
 	launched bool
+
+	// paused is set when the task paused, instead of returning from its
+	// entry function.
+	paused bool
 }
 
 // stackState is the saved state of a stack while unwound.
@@ -48,7 +52,8 @@ type stackState struct {
 func start(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
 	t := &Task{}
 	t.state.initialize(fn, args, stackSize)
-	runqueuePushBack(t)
+	numTasks++
+	runqueuePushNew(t)
 }
 
 //export tinygo_launch
@@ -72,8 +77,8 @@ func (s *state) initialize(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
 	stack[0] = stackCanary
 }
 
-//go:linkname runqueuePushBack runtime.runqueuePushBack
-func runqueuePushBack(*Task)
+//go:linkname runqueuePushNew runtime.runqueuePushNew
+func runqueuePushNew(*Task)
 
 // currentTask is the current running task, or nil if currently in the scheduler.
 var currentTask *Task
@@ -86,11 +91,20 @@ func Current() *Task {
 // Pause suspends the current task and returns to the scheduler.
 // This function may only be called when running on a goroutine stack, not when running on the system stack.
 func Pause() {
+	if currentTask == nil {
+		runtimePanic("cannot block outside of a goroutine")
+	}
+	if currentTask == &callbackTask {
+		runtimePanic(callbackBlockMessage)
+	}
+	traceBlock(currentTask)
+
 	// This is mildly unsafe but this is also the only place we can do this.
 	if *(*uintptr)(unsafe.Pointer(currentTask.state.asyncifysp)) != stackCanary {
 		runtimePanic("stack overflow")
 	}
 
+	currentTask.state.paused = true
 	currentTask.state.unwind()
 
 	*(*uintptr)(unsafe.Pointer(currentTask.state.asyncifysp)) = stackCanary
@@ -106,6 +120,7 @@ func (t *Task) Resume() {
 	prevTask := currentTask
 	t.gcData.swap()
 	currentTask = t
+	t.state.paused = false
 	if !t.state.launched {
 		t.state.launch()
 		t.state.launched = true
@@ -114,6 +129,10 @@ func (t *Task) Resume() {
 	}
 	currentTask = prevTask
 	t.gcData.swap()
+	if !t.state.paused {
+		// The goroutine returned from its entry function, so it exited.
+		numTasks--
+	}
 	if t.state.asyncifysp > t.state.csp {
 		runtimePanic("stack overflow")
 	}
@@ -125,5 +144,15 @@ func (*state) rewind()
 // OnSystemStack returns whether the caller is running on the system stack.
 func OnSystemStack() bool {
 	// If there is not an active goroutine, then this must be running on the system stack.
-	return Current() == nil
+	return currentTask == nil || currentTask == &callbackTask
+}
+
+// RunCallback calls fn on the system stack, without starting a goroutine for
+// it. fn must not block: blocking results in a runtime panic with the given
+// message. It may use defer and recover, and calls to RunCallback may nest.
+func RunCallback(fn func(), blockMessage string) {
+	prevTask, prevMessage := currentTask, callbackBlockMessage
+	currentTask, callbackBlockMessage = &callbackTask, blockMessage
+	fn()
+	currentTask, callbackBlockMessage = prevTask, prevMessage
 }
@@ -37,3 +37,9 @@ func OnSystemStack() bool {
 	// This scheduler does not do any stack switching.
 	return true
 }
+
+// RunCallback calls fn. This scheduler only has one goroutine, which can't
+// block.
+func RunCallback(fn func(), blockMessage string) {
+	fn()
+}
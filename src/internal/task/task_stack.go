@@ -1,5 +1,5 @@
-//go:build scheduler.tasks
-// +build scheduler.tasks
+//go:build scheduler.tasks || scheduler.external
+// +build scheduler.tasks scheduler.external
 
 package task
 
@@ -8,11 +8,29 @@ import "unsafe"
 //go:linkname runtimePanic runtime.runtimePanic
 func runtimePanic(str string)
 
+// This intrinsic returns the current stack pointer.
+//
+//export llvm.stacksave
+func stacksave() unsafe.Pointer
+
 // Stack canary, to detect a stack overflow. The number is a random number
 // generated by random.org. The bit fiddling dance is necessary because
 // otherwise Go wouldn't allow the cast to a smaller integer size.
 const stackCanary = uintptr(uint64(0x670c1333b83bf575) & uint64(^uintptr(0)))
 
+// Size of the guard region in bytes. The guard region is at the lowest
+// addresses of each goroutine stack and is filled with stackCanary. It is not
+// part of the usable stack: it is allocated in addition to the requested stack
+// size. The number of words in the guard region (stackGuardWords) depends on
+// how a stack overflow is detected, see stackCheck.
+const stackGuardSize = stackGuardWords * unsafe.Sizeof(uintptr(0))
+
+// stackLimit is the top of the guard region of the running goroutine, or zero
+// when running on the system stack. With stackCheck, the compiler inserts a
+// check at the start of every function (outside the runtime) that calls
+// stackOverflow when the stack pointer is below stackLimit.
+var stackLimit uintptr
+
 // state is a structure which holds a reference to the state of the task.
 // When the task is suspended, the registers are stored onto the stack and the stack pointer is stored into sp.
 type state struct {
@@ -20,11 +38,16 @@ type state struct {
 	// When the task is inactive, the saved registers are stored at the top of the stack.
 	sp uintptr
 
-	// canaryPtr points to the top word of the stack (the lowest address).
-	// This is used to detect stack overflows.
-	// When initializing the goroutine, the stackCanary constant is stored there.
-	// If the stack overflowed, the word will likely no longer equal stackCanary.
+	// canaryPtr points to the guard region at the top of the stack (the
+	// lowest address). This is used to detect stack overflows.
+	// When initializing the goroutine, the guard region is filled with the
+	// stackCanary constant. If the stack overflowed, some word will likely no
+	// longer equal stackCanary.
 	canaryPtr *uintptr
+
+	// overflowed is set when a stack overflow was detected. The goroutine is
+	// not resumed anymore and the scheduler panics.
+	overflowed bool
 }
 
 // currentTask is the current running task, or nil if currently in the scheduler.
@@ -38,40 +61,89 @@ func Current() *Task {
 // Pause suspends the current task and returns to the scheduler.
 // This function may only be called when running on a goroutine stack, not when running on the system stack or in an interrupt.
 func Pause() {
-	// Check whether the canary (the lowest address of the stack) is still
-	// valid. If it is not, a stack overflow has occured.
-	if *currentTask.state.canaryPtr != stackCanary {
-		runtimePanic("goroutine stack overflow")
+	if currentTask == nil {
+		runtimePanic("cannot block outside of a goroutine")
 	}
+	if currentTask == &callbackTask {
+		runtimePanic(callbackBlockMessage)
+	}
+	traceBlock(currentTask)
+
+	// Without stackCheck, check whether the stack overflowed into the guard
+	// region. Running any further on this stack may overwrite the memory below
+	// it (including the code that prints the panic message), so instead switch
+	// back to the scheduler which reports the stack overflow from the system
+	// stack.
+	if !stackCheck && currentTask.state.stackOverflowed() {
+		currentTask.state.overflowed = true
+	}
+	currentTask.state.pause()
+}
+
+// stackOverflow is called at the start of a function when the stack pointer is
+// below stackLimit, see stackCheck. It runs in the guard region, and switches
+// back to the scheduler which reports the stack overflow from the system stack.
+func stackOverflow() {
+	currentTask.state.overflowed = true
 	currentTask.state.pause()
 }
 
+// stackOverflowed returns whether the current stack pointer is inside the guard
+// region, or whether the guard region was overwritten. It must be called on the
+// goroutine stack.
+func (s *state) stackOverflowed() bool {
+	guard := uintptr(unsafe.Pointer(s.canaryPtr))
+	if uintptr(stacksave()) < guard+stackGuardSize {
+		return true
+	}
+	for _, word := range (*[stackGuardWords]uintptr)(unsafe.Pointer(guard)) {
+		if word != stackCanary {
+			return true
+		}
+	}
+	return false
+}
+
+// pause is called when a goroutine exits (see tinygo_startTask).
+//
 //export tinygo_pause
 func pause() {
+	numTasks--
+	traceExit(currentTask)
 	Pause()
 }
 
 // Resume the task until it pauses or completes.
 // This may only be called from the scheduler.
 func (t *Task) Resume() {
+	// The previous task is restored afterwards, because the scheduler may be
+	// stepped from a function passed to RunCallback.
+	prevTask, prevLimit := currentTask, stackLimit
 	currentTask = t
+	stackLimit = uintptr(unsafe.Pointer(t.state.canaryPtr)) + stackGuardSize
 	t.gcData.swap()
 	t.state.resume()
 	t.gcData.swap()
-	currentTask = nil
+	currentTask, stackLimit = prevTask, prevLimit
+	if t.state.overflowed {
+		runtimePanic("goroutine stack overflow")
+	}
 }
 
 // initialize the state and prepare to call the specified function with the specified argument bundle.
 func (s *state) initialize(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
-	// Create a stack.
-	stack := make([]uintptr, stackSize/unsafe.Sizeof(uintptr(0)))
-
-	// Set up the stack canary, a random number that should be checked when
-	// switching from the task back to the scheduler. The stack canary pointer
-	// points to the first word of the stack. If it has changed between now and
-	// the next stack switch, there was a stack overflow.
+	// Create a stack, with a guard region below it.
+	stack := make([]uintptr, (stackSize+stackGuardSize)/unsafe.Sizeof(uintptr(0)))
+
+	// Set up the guard region, filled with a random number that should be
+	// checked when switching from the task back to the scheduler. The stack
+	// canary pointer points to the first word of the stack. If the guard region
+	// has changed between now and the next stack switch, there was a stack
+	// overflow.
 	s.canaryPtr = &stack[0]
-	*s.canaryPtr = stackCanary
+	for i := range stack[:stackGuardWords] {
+		stack[i] = stackCanary
+	}
 
 	// Get a pointer to the top of the stack, where the initial register values
 	// are stored. They will be popped off the stack on the first stack switch
@@ -93,19 +165,30 @@ func swapTask(oldStack uintptr, newStack *uintptr)
 //go:extern tinygo_startTask
 var startTask [0]uint8
 
-//go:linkname runqueuePushBack runtime.runqueuePushBack
-func runqueuePushBack(*Task)
+//go:linkname runqueuePushNew runtime.runqueuePushNew
+func runqueuePushNew(*Task)
 
 // start creates and starts a new goroutine with the given function and arguments.
 // The new goroutine is scheduled to run later.
 func start(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
 	t := &Task{}
 	t.state.initialize(fn, args, stackSize)
-	runqueuePushBack(t)
+	numTasks++
+	runqueuePushNew(t)
 }
 
 // OnSystemStack returns whether the caller is running on the system stack.
 func OnSystemStack() bool {
 	// If there is not an active goroutine, then this must be running on the system stack.
-	return Current() == nil
+	return currentTask == nil || currentTask == &callbackTask
+}
+
+// RunCallback calls fn on the system stack, without starting a goroutine for
+// it. fn must not block: blocking results in a runtime panic with the given
+// message. It may use defer and recover, and calls to RunCallback may nest.
+func RunCallback(fn func(), blockMessage string) {
+	prevTask, prevMessage := currentTask, callbackBlockMessage
+	currentTask, callbackBlockMessage = &callbackTask, blockMessage
+	fn()
+	currentTask, callbackBlockMessage = prevTask, prevMessage
 }
@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && 386
-// +build scheduler.tasks,386
+//go:build (scheduler.tasks || scheduler.external) && 386
+// +build scheduler.tasks scheduler.external
+// +build 386
 
 package task
 
@@ -1,5 +1,7 @@
-//go:build scheduler.tasks && amd64 && !windows
-// +build scheduler.tasks,amd64,!windows
+//go:build (scheduler.tasks || scheduler.external) && amd64 && !windows
+// +build scheduler.tasks scheduler.external
+// +build amd64
+// +build !windows
 
 package task
 
@@ -1,5 +1,10 @@
-//go:build scheduler.tasks && arm && !cortexm && !avr && !xtensa && !tinygo.riscv
-// +build scheduler.tasks,arm,!cortexm,!avr,!xtensa,!tinygo.riscv
+//go:build (scheduler.tasks || scheduler.external) && arm && !cortexm && !avr && !xtensa && !tinygo.riscv
+// +build scheduler.tasks scheduler.external
+// +build arm
+// +build !cortexm
+// +build !avr
+// +build !xtensa
+// +build !tinygo.riscv
 
 package task
 
@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && arm64
-// +build scheduler.tasks,arm64
+//go:build (scheduler.tasks || scheduler.external) && arm64
+// +build scheduler.tasks scheduler.external
+// +build arm64
 
 package task
 
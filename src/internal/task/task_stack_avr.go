@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && avr
-// +build scheduler.tasks,avr
+//go:build (scheduler.tasks || scheduler.external) && avr
+// +build scheduler.tasks scheduler.external
+// +build avr
 
 package task
 
@@ -0,0 +1,12 @@
+//go:build (scheduler.tasks || scheduler.external) && tinygo.stackcheck
+// +build scheduler.tasks scheduler.external
+// +build tinygo.stackcheck
+
+package task
+
+// The compiler checks the stack pointer against stackLimit at the start of
+// every function (-stack-check), so a stack overflow is detected when the stack
+// pointer enters the guard region. The guard region must be big enough for the
+// stack frame of the function that detects the overflow, and for switching back
+// to the scheduler.
+const stackCheck = true
@@ -0,0 +1,13 @@
+//go:build (scheduler.tasks || scheduler.external) && tinygo.stackcheck && baremetal
+// +build scheduler.tasks scheduler.external
+// +build tinygo.stackcheck
+// +build baremetal
+
+package task
+
+import "unsafe"
+
+// Size of the guard region in words: 256 bytes, as RAM is scarce. Stack frames
+// on microcontrollers are small, and switching back to the scheduler only
+// needs room for the callee-saved registers.
+const stackGuardWords = 256 / unsafe.Sizeof(uintptr(0))
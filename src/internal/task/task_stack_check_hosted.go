@@ -0,0 +1,11 @@
+//go:build (scheduler.tasks || scheduler.external) && tinygo.stackcheck && !baremetal
+// +build scheduler.tasks scheduler.external
+// +build tinygo.stackcheck
+// +build !baremetal
+
+package task
+
+import "unsafe"
+
+// Size of the guard region in words: 4kB.
+const stackGuardWords = 4096 / unsafe.Sizeof(uintptr(0))
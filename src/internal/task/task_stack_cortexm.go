@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && cortexm
-// +build scheduler.tasks,cortexm
+//go:build (scheduler.tasks || scheduler.external) && cortexm
+// +build scheduler.tasks scheduler.external
+// +build cortexm
 
 package task
 
@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && esp32
-// +build scheduler.tasks,esp32
+//go:build (scheduler.tasks || scheduler.external) && esp32
+// +build scheduler.tasks scheduler.external
+// +build esp32
 
 package task
 
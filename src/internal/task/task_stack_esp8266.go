@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && esp8266
-// +build scheduler.tasks,esp8266
+//go:build (scheduler.tasks || scheduler.external) && esp8266
+// +build scheduler.tasks scheduler.external
+// +build esp8266
 
 package task
 
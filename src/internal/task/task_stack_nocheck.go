@@ -0,0 +1,15 @@
+//go:build (scheduler.tasks || scheduler.external) && !tinygo.stackcheck
+// +build scheduler.tasks scheduler.external
+// +build !tinygo.stackcheck
+
+package task
+
+// A stack overflow is only detected when the goroutine is paused: Pause checks
+// whether the stack pointer is in the guard region or the guard region was
+// overwritten. The guard region must be big enough to absorb the stack frames
+// between two checks, so that a stack overflow is detected before the memory
+// below the stack is overwritten.
+const stackCheck = false
+
+// Size of the guard region in words.
+const stackGuardWords = 32
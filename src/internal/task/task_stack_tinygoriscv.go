@@ -1,5 +1,6 @@
-//go:build scheduler.tasks && tinygo.riscv
-// +build scheduler.tasks,tinygo.riscv
+//go:build (scheduler.tasks || scheduler.external) && tinygo.riscv
+// +build scheduler.tasks scheduler.external
+// +build tinygo.riscv
 
 package task
 
@@ -0,0 +1,67 @@
+//go:build nrf52 || nrf52840 || nrf52833 || !baremetal
+// +build nrf52 nrf52840 nrf52833 !baremetal
+
+package machine
+
+import "errors"
+
+// Hardware abstraction layer for the analog comparator peripheral.
+
+var ErrInvalidComparatorInput = errors.New("machine: invalid comparator input pin")
+
+// ComparatorConfig holds the configuration of an analog comparator.
+type ComparatorConfig struct {
+	// PositiveInput is the analog pin that is compared against the reference.
+	PositiveInput Pin
+
+	// NegativeInput is the analog pin that provides the reference voltage.
+	// Set it to NoPin to use the supply voltage as reference instead.
+	NegativeInput Pin
+
+	// Threshold is the fraction of the reference voltage at which the output
+	// switches, where 0xffff is the full reference voltage. The zero value
+	// switches at half the reference voltage.
+	Threshold uint16
+}
+
+// ComparatorChange is the kind of output change of the comparator that
+// triggers an interrupt.
+type ComparatorChange uint8
+
+const (
+	ComparatorRising  ComparatorChange = 1 << iota // input rises above the threshold
+	ComparatorFalling                              // input falls below the threshold
+	ComparatorToggle  = ComparatorRising | ComparatorFalling
+)
+
+// Comparator is an analog comparator, which compares the voltage on an input
+// pin against a threshold in hardware. It can call a callback when the input
+// crosses the threshold, without the CPU having to poll it.
+type Comparator struct {
+	change   ComparatorChange
+	callback func(above bool)
+}
+
+// Comparator0 is the analog comparator of the chip.
+var Comparator0 = &Comparator{}
+
+// threshold returns the threshold of the config, with the default
+// applied.
+func (config ComparatorConfig) threshold() uint16 {
+	if config.Threshold == 0 {
+		return 0x8000
+	}
+	return config.Threshold
+}
+
+// handleInterrupt calls the callback for the given output change of the
+// comparator, if it was requested with SetInterrupt. It is called from the
+// comparator interrupt.
+func (c *Comparator) handleInterrupt(above bool) {
+	if c.callback == nil {
+		return
+	}
+	if above && c.change&ComparatorRising != 0 || !above && c.change&ComparatorFalling != 0 {
+		c.callback(above)
+	}
+}
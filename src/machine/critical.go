@@ -0,0 +1,55 @@
+package machine
+
+import "runtime/interrupt"
+
+// InterruptState is the interrupt state that is saved by DisableInterrupts.
+type InterruptState = interrupt.State
+
+// DisableInterrupts disables all interrupts on the current core and returns the
+// previous interrupt state, which must be passed to EnableInterrupts when the
+// interrupts may be enabled again. Calls may be nested:
+//
+//	state := machine.DisableInterrupts()
+//	// code that must not be interrupted
+//	machine.EnableInterrupts(state)
+//
+// Note that this does not protect against code running on a different core,
+// use a CriticalSection for that.
+func DisableInterrupts() InterruptState {
+	return interrupt.Disable()
+}
+
+// EnableInterrupts restores the interrupt state that was returned by
+// DisableInterrupts. Interrupts are only enabled again if they were enabled
+// before the matching call to DisableInterrupts.
+func EnableInterrupts(state InterruptState) {
+	interrupt.Restore(state)
+}
+
+// CriticalSection protects data that is shared between interrupts and other
+// code, possibly running on different cores. While a core holds a critical
+// section, interrupts are disabled on that core and other cores that try to
+// enter the same critical section wait until it is released.
+//
+// The zero value is an unlocked critical section. Critical sections are not
+// reentrant: a core that tries to lock a critical section it already holds
+// will deadlock. Different critical sections may be nested.
+type CriticalSection struct {
+	state InterruptState
+	owner uint8 // core that holds the critical section plus one, or 0
+}
+
+// Lock enters the critical section, waiting until no other core holds it.
+func (cs *CriticalSection) Lock() {
+	state := interrupt.Disable()
+	cs.lock()
+	cs.state = state
+}
+
+// Unlock leaves the critical section, and restores the interrupt state from
+// before the call to Lock.
+func (cs *CriticalSection) Unlock() {
+	state := cs.state
+	cs.unlock()
+	interrupt.Restore(state)
+}
@@ -0,0 +1,15 @@
+//go:build !rp2040 && !rp2350
+// +build !rp2040,!rp2350
+
+package machine
+
+// On chips with a single core, disabling interrupts is enough to protect a
+// critical section.
+
+func (cs *CriticalSection) lock() {
+	cs.owner = 1
+}
+
+func (cs *CriticalSection) unlock() {
+	cs.owner = 0
+}
@@ -0,0 +1,306 @@
+package machine
+
+import "errors"
+
+var (
+	errEEPROMNotConfigured = errors.New("machine: EEPROM not configured")
+	errEEPROMConfig        = errors.New("machine: invalid EEPROM configuration")
+	errEEPROMTooLarge      = errors.New("machine: EEPROM does not fit in a flash page")
+	errEEPROMOutOfRange    = errors.New("machine: EEPROM address out of range")
+)
+
+// Layout of the emulated EEPROM in a flash page. A page starts with a header:
+//
+//	magic    uint32
+//	sequence uint32 (incremented each time a new page is used)
+//
+// It is followed by records of 4 bytes, one for each written byte:
+//
+//	address  uint16
+//	value    uint8
+//	check    uint8 (address and value xor'ed with eepromCheck)
+//
+// The header and records are padded to the write block size of the flash.
+// Erased flash (all 0xff) is never a valid record, so the first erased record
+// is where the next record will be written.
+const (
+	eepromMagic      = 0x4d504545 // "EEPM"
+	eepromHeaderLen  = 8
+	eepromRecordLen  = 4
+	eepromCheck      = 0xa5
+	eepromMaxSize    = 1 << 16
+	eepromErasedByte = 0xff
+)
+
+// EEPROMConfig is the configuration of an emulated EEPROM.
+type EEPROMConfig struct {
+	// Device is the flash memory to store the data in, for example Flash on
+	// the nRF chips.
+	Device BlockDevice
+
+	// Start is the offset in Device of the first page to use. It must be a
+	// multiple of the erase block size.
+	Start int64
+
+	// Pages is the number of erase blocks to use, at least 2. The more
+	// pages, the less often each of them is erased. The default is to use
+	// all of Device after Start.
+	Pages int
+
+	// Size is the size of the emulated EEPROM in bytes. A copy of all data
+	// must fit in a single page, which takes 4 bytes (or the write block size
+	// if that is larger) for each byte.
+	Size int
+}
+
+// EEPROM emulates a small EEPROM in flash memory, for chips that don't have a
+// real EEPROM.
+//
+// Writing a byte appends a record to the current flash page, so that pages
+// don't need to be erased on every write. When the page is full, the current
+// contents are copied to the next page, and all pages are used in turn to
+// spread the wear. The page is only used after all data has been copied, so a
+// reset while writing loses at most the byte being written. A copy of the
+// contents is kept in RAM, which makes reads fast.
+type EEPROM struct {
+	dev        BlockDevice
+	start      int64
+	pageSize   int64
+	pages      int
+	headerSize int64
+	recordSize int64
+	page       int    // index of the current page
+	seq        uint32 // sequence number of the current page
+	pos        int64  // offset of the next free record in the current page
+	buf        []byte // buffer for a header or record
+	data       []byte // contents of the EEPROM
+}
+
+// Configure sets up the emulated EEPROM and reads its contents from flash. If
+// there is no EEPROM data in flash yet, the first page is erased and all bytes
+// read as 0xff.
+func (e *EEPROM) Configure(config EEPROMConfig) error {
+	dev := config.Device
+	if dev == nil || config.Size <= 0 || config.Size > eepromMaxSize {
+		return errEEPROMConfig
+	}
+	pageSize := dev.EraseBlockSize()
+	writeSize := dev.WriteBlockSize()
+	if writeSize < 1 {
+		writeSize = 1
+	}
+	pages := config.Pages
+	if config.Start < 0 || config.Start%pageSize != 0 {
+		return errEEPROMConfig
+	}
+	if pages == 0 {
+		pages = int((dev.Size() - config.Start) / pageSize)
+	}
+	if pages < 2 || config.Start+int64(pages)*pageSize > dev.Size() {
+		return errEEPROMConfig
+	}
+	*e = EEPROM{
+		dev:        dev,
+		start:      config.Start,
+		pageSize:   pageSize,
+		pages:      pages,
+		headerSize: alignUp(eepromHeaderLen, writeSize),
+		recordSize: alignUp(eepromRecordLen, writeSize),
+	}
+	if e.headerSize+int64(config.Size)*e.recordSize > pageSize {
+		return errEEPROMTooLarge
+	}
+	e.buf = make([]byte, e.headerSize)
+	data := make([]byte, config.Size)
+	for i := range data {
+		data[i] = eepromErasedByte
+	}
+	e.data = data
+	if err := e.load(); err != nil {
+		e.data = nil
+		return err
+	}
+	return nil
+}
+
+// Size returns the size of the emulated EEPROM in bytes.
+func (e *EEPROM) Size() int64 {
+	return int64(len(e.data))
+}
+
+// ReadAt reads len(p) bytes starting at the given address. Bytes that were
+// never written read as 0xff. Reading past the end of the EEPROM returns the
+// bytes up to the end and an error.
+func (e *EEPROM) ReadAt(p []byte, off int64) (n int, err error) {
+	if e.data == nil {
+		return 0, errEEPROMNotConfigured
+	}
+	if off < 0 || off >= int64(len(e.data)) {
+		return 0, errEEPROMOutOfRange
+	}
+	n = copy(p, e.data[off:])
+	if n < len(p) {
+		err = errEEPROMOutOfRange
+	}
+	return n, err
+}
+
+// WriteAt writes len(p) bytes starting at the given address. Bytes that don't
+// change are not written to flash.
+func (e *EEPROM) WriteAt(p []byte, off int64) (n int, err error) {
+	if e.data == nil {
+		return 0, errEEPROMNotConfigured
+	}
+	if off < 0 || off+int64(len(p)) > int64(len(e.data)) {
+		return 0, errEEPROMOutOfRange
+	}
+	for i, value := range p {
+		addr := int(off) + i
+		old := e.data[addr]
+		if old == value {
+			continue
+		}
+		e.data[addr] = value
+		if err := e.writeRecord(addr, value); err != nil {
+			e.data[addr] = old
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+// load finds the current page and reads the EEPROM contents from it.
+func (e *EEPROM) load() error {
+	found := false
+	for i := 0; i < e.pages; i++ {
+		header := e.buf[:eepromHeaderLen]
+		if _, err := e.dev.ReadAt(header, e.pageOffset(i)); err != nil {
+			return err
+		}
+		if readUint32LE(header) != eepromMagic {
+			continue
+		}
+		seq := readUint32LE(header[4:])
+		if !found || int32(seq-e.seq) > 0 {
+			found = true
+			e.page = i
+			e.seq = seq
+		}
+	}
+	if !found {
+		// Nothing has been stored yet (or the data is corrupted), so start
+		// with an empty first page.
+		e.page = e.pages - 1
+		e.seq = 0
+		return e.compact()
+	}
+
+	offset := e.pageOffset(e.page)
+	record := e.buf[:e.recordSize]
+	for e.pos = e.headerSize; e.pos+e.recordSize <= e.pageSize; e.pos += e.recordSize {
+		if _, err := e.dev.ReadAt(record, offset+e.pos); err != nil {
+			return err
+		}
+		if isErased(record) {
+			break
+		}
+		// Records that don't pass the check were only partially written,
+		// for example because of a reset. Skip them.
+		addr := int(record[0]) | int(record[1])<<8
+		value := record[2]
+		if record[3] == record[0]^record[1]^value^eepromCheck && addr < len(e.data) {
+			e.data[addr] = value
+		}
+	}
+	return nil
+}
+
+// writeRecord stores the given byte in the current page, or moves the data to
+// the next page if the current page is full.
+func (e *EEPROM) writeRecord(addr int, value byte) error {
+	if e.pos+e.recordSize > e.pageSize {
+		// The new value is part of the copy.
+		return e.compact()
+	}
+	e.encodeRecord(addr, value)
+	_, err := e.dev.WriteAt(e.buf[:e.recordSize], e.pageOffset(e.page)+e.pos)
+	// Don't retry this record on error: it may have been partially written.
+	e.pos += e.recordSize
+	return err
+}
+
+// compact erases the next page, and copies the EEPROM contents to it. The
+// header is written last, so that the copy is only used once it is complete.
+func (e *EEPROM) compact() error {
+	next := (e.page + 1) % e.pages
+	if err := e.dev.EraseBlocks(e.start/e.pageSize+int64(next), 1); err != nil {
+		return err
+	}
+	offset := e.pageOffset(next)
+	pos := e.headerSize
+	for addr, value := range e.data {
+		if value == eepromErasedByte {
+			// Bytes without a record read as erased.
+			continue
+		}
+		e.encodeRecord(addr, value)
+		if _, err := e.dev.WriteAt(e.buf[:e.recordSize], offset+pos); err != nil {
+			return err
+		}
+		pos += e.recordSize
+	}
+
+	header := e.buf[:e.headerSize]
+	for i := range header {
+		header[i] = eepromErasedByte
+	}
+	putUint32LE(header, eepromMagic)
+	putUint32LE(header[4:], e.seq+1)
+	if _, err := e.dev.WriteAt(header, offset); err != nil {
+		return err
+	}
+	e.page = next
+	e.seq++
+	e.pos = pos
+	return nil
+}
+
+// encodeRecord writes the record for the given byte to e.buf.
+func (e *EEPROM) encodeRecord(addr int, value byte) {
+	record := e.buf[:e.recordSize]
+	for i := range record {
+		record[i] = eepromErasedByte
+	}
+	record[0] = byte(addr)
+	record[1] = byte(addr >> 8)
+	record[2] = value
+	record[3] = record[0] ^ record[1] ^ value ^ eepromCheck
+}
+
+func (e *EEPROM) pageOffset(page int) int64 {
+	return e.start + int64(page)*e.pageSize
+}
+
+func isErased(buf []byte) bool {
+	for _, b := range buf {
+		if b != eepromErasedByte {
+			return false
+		}
+	}
+	return true
+}
+
+func alignUp(n, align int64) int64 {
+	return (n + align - 1) / align * align
+}
+
+func readUint32LE(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func putUint32LE(b []byte, v uint32) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+}
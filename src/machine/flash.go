@@ -0,0 +1,38 @@
+package machine
+
+import "errors"
+
+var errFlashOutOfRange = errors.New("machine: flash access out of range")
+
+// BlockDevice is the raw device that is meant to store flash data, like the
+// internal flash of a chip or an external SPI flash chip.
+//
+// Flash memory is erased in blocks of EraseBlockSize bytes. Erased memory reads
+// as 0xff, and every byte may only be written once after it has been erased.
+type BlockDevice interface {
+	// ReadAt reads the given number of bytes from the block device, like
+	// io.ReaderAt.
+	ReadAt(p []byte, off int64) (n int, err error)
+
+	// WriteAt writes the given number of bytes to the block device, like
+	// io.WriterAt. The memory must have been erased before.
+	WriteAt(p []byte, off int64) (n int, err error)
+
+	// Size returns the number of bytes in this block device.
+	Size() int64
+
+	// WriteBlockSize returns the block size in which data can be written to
+	// memory. It can be used by a client to optimize writes, non-aligned
+	// writes should always work correctly.
+	WriteBlockSize() int64
+
+	// EraseBlockSize returns the smallest erasable area on this particular
+	// chip in bytes. This is used for the block size in EraseBlocks. It must
+	// be a power of two, and may be as small as 1. A typical size is 4096.
+	EraseBlockSize() int64
+
+	// EraseBlocks erases the given number of blocks. The start and len
+	// parameters are in block numbers, use EraseBlockSize to map addresses to
+	// blocks.
+	EraseBlocks(start, len int64) error
+}
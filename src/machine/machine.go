@@ -9,6 +9,8 @@ var (
 	ErrInvalidClockPin    = errors.New("machine: invalid clock pin")
 	ErrInvalidDataPin     = errors.New("machine: invalid data pin")
 	ErrNoPinChangeChannel = errors.New("machine: no channel available for pin interrupt")
+	ErrPinPull            = errors.New("machine: pin pull resistor not supported")
+	ErrPinDriveStrength   = errors.New("machine: pin drive strength not supported")
 )
 
 // Device is the running program's chip name, such as "ATSAMD51J19A" or
@@ -32,8 +34,46 @@ type PinMode uint8
 
 type PinConfig struct {
 	Mode PinMode
+
+	// Pull overrides the pull resistor that is set by the mode, for example
+	// to add a pull-up to a pin that is used by a peripheral. The default
+	// (PinPullDefault) is to use the pull of the mode.
+	Pull PinPull
+
+	// DriveStrength sets the output drive strength of the pin. The supported
+	// values are target specific, the default (zero) is the reset value of the
+	// hardware.
+	DriveStrength PinDriveStrength
 }
 
+// Validate returns ErrPinPull if the configuration sets a pull the target
+// doesn't support, and ErrPinDriveStrength if it sets a drive strength the
+// target doesn't support. Pin.Configure can't report errors, so it ignores
+// these settings.
+func (config PinConfig) Validate() error {
+	if config.Pull > PinPullDown || (config.Pull != PinPullDefault && !hasPinPull) {
+		return ErrPinPull
+	}
+	if config.DriveStrength > maxPinDriveStrength {
+		return ErrPinDriveStrength
+	}
+	return nil
+}
+
+// PinPull is the pull resistor configuration of a pin, see PinConfig.
+type PinPull uint8
+
+const (
+	PinPullDefault PinPull = iota // pull set by the pin mode
+	PinPullNone                   // no pull resistor
+	PinPullUp                     // pull-up resistor
+	PinPullDown                   // pull-down resistor
+)
+
+// PinDriveStrength is the output drive strength of a pin, see PinConfig. The
+// constants for the supported drive strengths are defined per target.
+type PinDriveStrength uint8
+
 // Pin is a single pin on a chip, which may be connected to other hardware
 // devices. It can either be used directly as GPIO pin or it can be used in
 // other peripherals like ADC, I2C, etc.
@@ -154,9 +154,10 @@ type UART struct {
 	baudRegH *volatile.Register8
 	baudRegL *volatile.Register8
 
-	statusRegA *volatile.Register8
-	statusRegB *volatile.Register8
-	statusRegC *volatile.Register8
+	statusRegA   *volatile.Register8
+	statusRegB   *volatile.Register8
+	statusRegC   *volatile.Register8
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 // Configure the UART on the AVR. Defaults to 9600 baud on Arduino.
@@ -164,13 +165,9 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 9600
 	}
+	uart.writeTimeout = config.WriteTimeout
 
-	// Set baud rate based on prescale formula from
-	// https://www.microchip.com/webdoc/AVRLibcReferenceManual/FAQ_1faq_wrong_baud_rate.html
-	// ((F_CPU + UART_BAUD_RATE * 8L) / (UART_BAUD_RATE * 16L) - 1)
-	ps := ((CPUFrequency()+config.BaudRate*8)/(config.BaudRate*16) - 1)
-	uart.baudRegH.Set(uint8(ps >> 8))
-	uart.baudRegL.Set(uint8(ps & 0xff))
+	uart.SetBaudRate(config.BaudRate)
 
 	// enable RX, TX and RX interrupt
 	uart.statusRegB.Set(avr.UCSR0B_RXEN0 | avr.UCSR0B_TXEN0 | avr.UCSR0B_RXCIE0)
@@ -179,6 +176,17 @@ func (uart *UART) Configure(config UARTConfig) {
 	uart.statusRegC.Set(avr.UCSR0C_UCSZ01 | avr.UCSR0C_UCSZ00)
 }
 
+// SetBaudRate sets the communication speed for the UART. Only the baud rate
+// registers are changed, the rest of the configuration is left as it is.
+func (uart *UART) SetBaudRate(br uint32) {
+	// Set baud rate based on prescale formula from
+	// https://www.microchip.com/webdoc/AVRLibcReferenceManual/FAQ_1faq_wrong_baud_rate.html
+	// ((F_CPU + UART_BAUD_RATE * 8L) / (UART_BAUD_RATE * 16L) - 1)
+	ps := ((CPUFrequency()+br*8)/(br*16) - 1)
+	uart.baudRegH.Set(uint8(ps >> 8))
+	uart.baudRegL.Set(uint8(ps & 0xff))
+}
+
 func (uart *UART) handleInterrupt(intr interrupt.Interrupt) {
 	// Read register to clear it.
 	data := uart.dataReg.Get()
@@ -193,7 +201,11 @@ func (uart *UART) handleInterrupt(intr interrupt.Interrupt) {
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
 	// Wait until UART buffer is not busy.
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for !uart.statusRegA.HasBits(avr.UCSR0A_UDRE0) {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	uart.dataReg.Set(c) // send char
 	return nil
@@ -253,22 +265,25 @@ func (s SPI) Configure(config SPIConfig) error {
 	// slave mode.
 	s.cs.Configure(PinConfig{Mode: PinOutput})
 
-	frequencyDivider := CPUFrequency() / config.Frequency
+	// Pick the smallest divider that doesn't result in a frequency higher than
+	// the requested frequency (or the largest divider if none is large
+	// enough).
+	frequencyDivider := spiClockDivider(CPUFrequency(), config.Frequency)
 
 	switch {
-	case frequencyDivider >= 128:
+	case frequencyDivider > 64:
 		s.spcr.SetBits(avr.SPCR_SPR0 | avr.SPCR_SPR1)
-	case frequencyDivider >= 64:
+	case frequencyDivider > 32:
 		s.spcr.SetBits(avr.SPCR_SPR1)
-	case frequencyDivider >= 32:
+	case frequencyDivider > 16:
 		s.spcr.SetBits(avr.SPCR_SPR1)
 		s.spsr.SetBits(avr.SPSR_SPI2X)
-	case frequencyDivider >= 16:
+	case frequencyDivider > 8:
 		s.spcr.SetBits(avr.SPCR_SPR0)
-	case frequencyDivider >= 8:
+	case frequencyDivider > 4:
 		s.spcr.SetBits(avr.SPCR_SPR0)
 		s.spsr.SetBits(avr.SPSR_SPI2X)
-	case frequencyDivider >= 4:
+	case frequencyDivider > 2:
 		// The clock is already set to all 0's.
 	default: // defaults to fastest which is /2
 		s.spsr.SetBits(avr.SPSR_SPI2X)
@@ -294,6 +309,26 @@ func (s SPI) Configure(config SPIConfig) error {
 	return nil
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured: the CPU
+// frequency divided by one of the dividers 2, 4, 8, 16, 32, 64 or 128.
+func (s SPI) ActualFrequency() uint32 {
+	var divider uint32
+	switch s.spcr.Get() & (avr.SPCR_SPR0 | avr.SPCR_SPR1) {
+	case 0:
+		divider = 4
+	case avr.SPCR_SPR0:
+		divider = 16
+	case avr.SPCR_SPR1:
+		divider = 64
+	default:
+		divider = 128
+	}
+	if s.spsr.HasBits(avr.SPSR_SPI2X) {
+		divider /= 2
+	}
+	return CPUFrequency() / divider
+}
+
 // Transfer writes the byte into the register and returns the read content
 func (s SPI) Transfer(b byte) (byte, error) {
 	s.spdr.Set(uint8(b))
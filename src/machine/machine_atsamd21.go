@@ -503,6 +503,8 @@ type UART struct {
 	Bus       *sam.SERCOM_USART_Type
 	SERCOM    uint8
 	Interrupt interrupt.Interrupt
+
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 const (
@@ -516,6 +518,7 @@ func (uart *UART) Configure(config UARTConfig) error {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	// Use default pins if pins are not set.
 	if config.TX == 0 && config.RX == 0 {
@@ -626,7 +629,11 @@ func (uart *UART) SetBaudRate(br uint32) {
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
 	// wait until ready to receive
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for !uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INTFLAG_DRE) {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	uart.Bus.DATA.Set(uint16(c))
 	return nil
@@ -1245,8 +1252,12 @@ func (spi SPI) Configure(config SPIConfig) error {
 		spi.Bus.CTRLA.ClearBits(sam.SERCOM_SPI_CTRLA_CPOL)
 	}
 
-	// Set synch speed for SPI
-	baudRate := CPUFrequency() / (2 * config.Frequency)
+	// Set synch speed for SPI. The SPI clock is the reference clock divided by
+	// 2 * (BAUD + 1), which must not be faster than the requested frequency.
+	baudRate := spiClockDivider(CPUFrequency(), 2*config.Frequency)
+	if baudRate > 256 {
+		baudRate = 256
+	}
 	if baudRate > 0 {
 		baudRate--
 	}
@@ -1260,6 +1271,12 @@ func (spi SPI) Configure(config SPIConfig) error {
 	return nil
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured, which is
+// the SERCOM reference clock divided by 2 * (BAUD + 1).
+func (spi SPI) ActualFrequency() uint32 {
+	return CPUFrequency() / (2 * (uint32(spi.Bus.BAUD.Get()) + 1))
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi SPI) Transfer(w byte) (byte, error) {
 	// write data
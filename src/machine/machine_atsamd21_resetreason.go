@@ -0,0 +1,36 @@
+//go:build sam && atsamd21
+// +build sam,atsamd21
+
+package machine
+
+import "device/sam"
+
+// Bits in the PM.RCAUSE register.
+const (
+	rcausePOR   = 1 << 0
+	rcauseBOD12 = 1 << 1
+	rcauseBOD33 = 1 << 2
+	rcauseEXT   = 1 << 4
+	rcauseWDT   = 1 << 5
+	rcauseSYST  = 1 << 6
+)
+
+// readResetReason reads the reset cause. The register is updated by hardware
+// on every reset, so it doesn't need to be cleared.
+func readResetReason() ResetReason {
+	rcause := sam.PM.RCAUSE.Get()
+	switch {
+	case rcause&rcausePOR != 0:
+		return ResetReasonPowerOn
+	case rcause&(rcauseBOD12|rcauseBOD33) != 0:
+		return ResetReasonBrownout
+	case rcause&rcauseWDT != 0:
+		return ResetReasonWatchdog
+	case rcause&rcauseSYST != 0:
+		return ResetReasonSoftware
+	case rcause&rcauseEXT != 0:
+		return ResetReasonExternal
+	default:
+		return ResetReasonUnknown
+	}
+}
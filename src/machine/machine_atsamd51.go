@@ -950,6 +950,8 @@ type UART struct {
 	Bus       *sam.SERCOM_USART_INT_Type
 	SERCOM    uint8
 	Interrupt interrupt.Interrupt // RXC interrupt
+
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 var (
@@ -981,6 +983,7 @@ func (uart *UART) Configure(config UARTConfig) error {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	// determine pins
 	if config.TX == 0 && config.RX == 0 {
@@ -1098,7 +1101,11 @@ func (uart *UART) SetBaudRate(br uint32) {
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
 	// wait until ready to receive
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for !uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INT_INTFLAG_DRE) {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	uart.Bus.DATA.Set(uint32(c))
 	return nil
@@ -1498,8 +1505,12 @@ func (spi SPI) Configure(config SPIConfig) error {
 		freqRef = uint32(SERCOM_FREQ_REF)
 	}
 
-	// Set synch speed for SPI
-	baudRate := freqRef / (2 * config.Frequency)
+	// Set synch speed for SPI. The SPI clock is the reference clock divided by
+	// 2 * (BAUD + 1), which must not be faster than the requested frequency.
+	baudRate := spiClockDivider(freqRef, 2*config.Frequency)
+	if baudRate > 256 {
+		baudRate = 256
+	}
 	if baudRate > 0 {
 		baudRate--
 	}
@@ -1513,6 +1524,30 @@ func (spi SPI) Configure(config SPIConfig) error {
 	return nil
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured, which is
+// the SERCOM reference clock divided by 2 * (BAUD + 1).
+func (spi SPI) ActualFrequency() uint32 {
+	freqRef := uint32(SERCOM_FREQ_REF)
+	gen := (sam.GCLK.PCHCTRL[sercomCoreClocks[spi.SERCOM]].Get() & sam.GCLK_PCHCTRL_GEN_Msk) >> sam.GCLK_PCHCTRL_GEN_Pos
+	if gen == sam.GCLK_PCHCTRL_GEN_GCLK0 {
+		freqRef = SERCOM_FREQ_REF_GCLK0
+	}
+	return freqRef / (2 * (uint32(spi.Bus.BAUD.Get()) + 1))
+}
+
+// sercomCoreClocks are the GCLK peripheral channels that provide the core
+// clock of each SERCOM, as set by setSERCOMClockGenerator.
+var sercomCoreClocks = [...]uint8{
+	sam.PCHCTRL_GCLK_SERCOM0_CORE,
+	sam.PCHCTRL_GCLK_SERCOM1_CORE,
+	sam.PCHCTRL_GCLK_SERCOM2_CORE,
+	sam.PCHCTRL_GCLK_SERCOM3_CORE,
+	sam.PCHCTRL_GCLK_SERCOM4_CORE,
+	sam.PCHCTRL_GCLK_SERCOM5_CORE,
+	sam.PCHCTRL_GCLK_SERCOM6_CORE,
+	sam.PCHCTRL_GCLK_SERCOM7_CORE,
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi SPI) Transfer(w byte) (byte, error) {
 	// write data
@@ -0,0 +1,159 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import (
+	"device/sam"
+	"errors"
+	"unsafe"
+)
+
+// This file implements streaming samples to the DAC at a fixed sample rate.
+// Every DAC channel uses its own timer (TC2 for DAC0, TC3 for DAC1) that
+// triggers a DMA channel on every overflow, which writes the next sample to
+// the DAC.
+
+var (
+	ErrInvalidSampleRate = errors.New("machine: sample rate out of range")
+	ErrNoDMAChannel      = errors.New("machine: no free DMA channel")
+)
+
+// The DMA channel of every DAC channel, claimed on the first StartStream.
+var dacDMAChannel = [2]int8{-1, -1}
+
+// Prescaler values supported by the TC, indexed by the PRESCALER field.
+var tcPrescalers = [...]uint32{1, 2, 4, 8, 16, 64, 256, 1024}
+
+// streamTimer returns the timer used to pace the samples of this DAC channel.
+func (dac DAC) streamTimer() *sam.TC_COUNT16_Type {
+	if dac.Channel == 0 {
+		return sam.TC2_COUNT16
+	}
+	return sam.TC3_COUNT16
+}
+
+// StartStream starts writing the given samples to the DAC at a fixed sample
+// rate (in samples per second), in the background. The DAC must already be
+// configured. If loop is true, the samples are repeated until StopStream is
+// called, which is useful for waveform generation.
+//
+// The samples are written to the DAC as-is, so unlike with Set they must be
+// 12-bit values (0-4095). The buffer must stay valid and should not be
+// modified until the stream has finished or has been stopped.
+func (dac DAC) StartStream(samples []uint16, sampleRate uint32, loop bool) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	if len(samples) > 0xffff {
+		return ErrTxInvalidSliceSize
+	}
+	prescaler, period, err := dacStreamPeriod(CPUFrequency(), sampleRate)
+	if err != nil {
+		return err
+	}
+	if dacDMAChannel[dac.Channel] < 0 {
+		ch := dmaClaimChannel()
+		if ch < 0 {
+			return ErrNoDMAChannel
+		}
+		dacDMAChannel[dac.Channel] = int8(ch)
+	}
+
+	// Enable the clocks of the DMAC and of the timer.
+	sam.MCLK.AHBMASK.SetBits(sam.MCLK_AHBMASK_DMAC_)
+	if dac.Channel == 0 {
+		sam.MCLK.APBBMASK.SetBits(sam.MCLK_APBBMASK_TC2_)
+	} else {
+		sam.MCLK.APBBMASK.SetBits(sam.MCLK_APBBMASK_TC3_)
+	}
+	sam.GCLK.PCHCTRL[sam.PCHCTRL_GCLK_TC2].Set((sam.GCLK_PCHCTRL_GEN_GCLK0 << sam.GCLK_PCHCTRL_GEN_Pos) | sam.GCLK_PCHCTRL_CHEN)
+
+	dac.StopStream()
+	dac.startStream(sam.DMAC, dac.streamTimer(), samples, prescaler, period, loop)
+	return nil
+}
+
+// dacStreamPeriod returns the smallest TC prescaler (as PRESCALER field value)
+// for which the timer period of the given sample rate fits in 16 bits, and
+// that period.
+func dacStreamPeriod(clock, sampleRate uint32) (prescaler int, period uint32, err error) {
+	if sampleRate == 0 {
+		return 0, 0, ErrInvalidSampleRate
+	}
+	for i, div := range tcPrescalers {
+		period = clock / div / sampleRate
+		if period <= 0x10000 {
+			if period == 0 {
+				break
+			}
+			return i, period, nil
+		}
+	}
+	return 0, 0, ErrInvalidSampleRate
+}
+
+// startStream sets up the DMA transfer and starts the timer that paces it. The
+// peripherals are passed in so that the tests can use fakes.
+func (dac DAC) startStream(dmac *sam.DMAC_Type, tc *sam.TC_COUNT16_Type, samples []uint16, prescaler int, period uint32, loop bool) {
+	dmaEnable(dmac)
+
+	// Set up the transfer: one 16-bit sample from the buffer to the DAC data
+	// register on every timer overflow.
+	ch := dacDMAChannel[dac.Channel]
+	desc := &dmaDescriptors[ch]
+	desc.btctrl = dmaBtctrlVALID | dmaBtctrlBEATSIZEHWORD | dmaBtctrlSRCINC
+	desc.btcnt = uint16(len(samples))
+	desc.srcaddr = unsafe.Pointer(uintptr(unsafe.Pointer(&samples[0])) + uintptr(len(samples))*2)
+	desc.dstaddr = unsafe.Pointer(&sam.DAC.DATA[dac.Channel])
+	desc.descaddr = nil
+	if loop {
+		desc.descaddr = unsafe.Pointer(desc)
+	}
+	trigsrc := uint32(sam.DMAC_TRIGSRC_TC2_OVF)
+	if dac.Channel != 0 {
+		trigsrc = sam.DMAC_TRIGSRC_TC3_OVF
+	}
+	dmac.CHANNEL[ch].CHCTRLA.Set(trigsrc<<sam.DMAC_CHANNEL_CHCTRLA_TRIGSRC_Pos |
+		sam.DMAC_CHANNEL_CHCTRLA_TRIGACT_BURST<<sam.DMAC_CHANNEL_CHCTRLA_TRIGACT_Pos)
+	dmac.CHANNEL[ch].CHCTRLA.SetBits(sam.DMAC_CHANNEL_CHCTRLA_ENABLE)
+
+	// Start the timer, counting up to CC0.
+	tc.CTRLA.Set(sam.TC_COUNT16_CTRLA_SWRST)
+	for tc.SYNCBUSY.HasBits(sam.TC_COUNT16_SYNCBUSY_SWRST) {
+	}
+	tc.WAVE.Set(sam.TC_COUNT16_WAVE_WAVEGEN_MFRQ << sam.TC_COUNT16_WAVE_WAVEGEN_Pos)
+	tc.CC[0].Set(uint16(period - 1))
+	tc.CTRLA.Set(uint32(prescaler)<<sam.TC_COUNT16_CTRLA_PRESCALER_Pos | sam.TC_COUNT16_CTRLA_ENABLE)
+	for tc.SYNCBUSY.HasBits(sam.TC_COUNT16_SYNCBUSY_ENABLE) {
+	}
+}
+
+// Streaming returns whether a stream started with StartStream is still
+// running.
+func (dac DAC) Streaming() bool {
+	ch := dacDMAChannel[dac.Channel]
+	return ch >= 0 && sam.DMAC.CHANNEL[ch].CHCTRLA.HasBits(sam.DMAC_CHANNEL_CHCTRLA_ENABLE)
+}
+
+// StopStream stops a stream started with StartStream. The DAC keeps the last
+// written value.
+func (dac DAC) StopStream() {
+	dac.stopStream(sam.DMAC, dac.streamTimer())
+}
+
+func (dac DAC) stopStream(dmac *sam.DMAC_Type, tc *sam.TC_COUNT16_Type) {
+	if ch := dacDMAChannel[dac.Channel]; ch >= 0 {
+		chctrla := &dmac.CHANNEL[ch].CHCTRLA
+		if chctrla.HasBits(sam.DMAC_CHANNEL_CHCTRLA_ENABLE) {
+			chctrla.ClearBits(sam.DMAC_CHANNEL_CHCTRLA_ENABLE)
+			for chctrla.HasBits(sam.DMAC_CHANNEL_CHCTRLA_ENABLE) {
+			}
+		}
+	}
+	if tc.CTRLA.HasBits(sam.TC_COUNT16_CTRLA_ENABLE) {
+		tc.CTRLA.ClearBits(sam.TC_COUNT16_CTRLA_ENABLE)
+		for tc.SYNCBUSY.HasBits(sam.TC_COUNT16_SYNCBUSY_ENABLE) {
+		}
+	}
+}
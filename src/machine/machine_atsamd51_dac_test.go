@@ -0,0 +1,114 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import (
+	"device/sam"
+	"testing"
+	"unsafe"
+)
+
+// The DMAC and TC used by these tests are in RAM. The tests are only compiled
+// by the smoketest (-target=itsybitsy-m4), as there is no SAMD51 emulator.
+
+func TestDACStreamPeriod(t *testing.T) {
+	for _, tc := range []struct {
+		sampleRate uint32
+		prescaler  int
+		period     uint32
+		err        error
+	}{
+		{44100, 0, 2721, nil},
+		{1832, 0, 65502, nil}, // still fits without prescaler
+		{1000, 1, 60000, nil},
+		{100, 5, 18750, nil},
+		{1, 0, 0, ErrInvalidSampleRate},
+		{0, 0, 0, ErrInvalidSampleRate},
+		{200e6, 0, 0, ErrInvalidSampleRate},
+	} {
+		prescaler, period, err := dacStreamPeriod(120e6, tc.sampleRate)
+		if prescaler != tc.prescaler || period != tc.period || err != tc.err {
+			t.Errorf("%d samples/s: got prescaler %d, period %d, error %v; want %d, %d, %v", tc.sampleRate, prescaler, period, err, tc.prescaler, tc.period, tc.err)
+		}
+	}
+}
+
+func TestDACStartStream(t *testing.T) {
+	// Use peripherals in RAM instead of the real DMAC and TC.
+	dmac := new(sam.DMAC_Type)
+	tc := new(sam.TC_COUNT16_Type)
+
+	// Pretend that another driver already uses the first DMA channels, which
+	// must not be overwritten.
+	other := dmaDescriptors[0]
+	defer func(used uint32, channel [2]int8) {
+		dmaChannelsUsed = used
+		dacDMAChannel = channel
+		dmaDescriptors[0] = other
+	}(dmaChannelsUsed, dacDMAChannel)
+	dmaChannelsUsed = 0b111
+	dmaDescriptors[0].btcnt = 1234
+	ch := dmaClaimChannel()
+	if ch != 3 {
+		t.Fatalf("claimed DMA channel %d, want 3", ch)
+	}
+	dacDMAChannel[1] = int8(ch)
+
+	samples := make([]uint16, 100)
+	DAC1.startStream(dmac, tc, samples, 5, 18750, true)
+
+	if dmac.BASEADDR.Get() != uint32(uintptr(unsafe.Pointer(&dmaDescriptors))) {
+		t.Errorf("BASEADDR does not point to the shared descriptor table")
+	}
+	if !dmac.CTRL.HasBits(sam.DMAC_CTRL_DMAENABLE) {
+		t.Errorf("DMAC not enabled")
+	}
+	chctrla := dmac.CHANNEL[ch].CHCTRLA.Get()
+	if trigsrc := chctrla & sam.DMAC_CHANNEL_CHCTRLA_TRIGSRC_Msk >> sam.DMAC_CHANNEL_CHCTRLA_TRIGSRC_Pos; trigsrc != sam.DMAC_TRIGSRC_TC3_OVF {
+		t.Errorf("TRIGSRC = %#x, want TC3 overflow", trigsrc)
+	}
+	if chctrla&sam.DMAC_CHANNEL_CHCTRLA_ENABLE == 0 {
+		t.Errorf("DMA channel not enabled")
+	}
+	for i := range dmac.CHANNEL {
+		if i != ch && dmac.CHANNEL[i].CHCTRLA.Get() != 0 {
+			t.Errorf("DMA channel %d was modified", i)
+		}
+	}
+
+	desc := &dmaDescriptors[ch]
+	if desc.btcnt != 100 {
+		t.Errorf("BTCNT = %d, want 100", desc.btcnt)
+	}
+	if desc.srcaddr != unsafe.Pointer(uintptr(unsafe.Pointer(&samples[0]))+200) {
+		t.Errorf("SRCADDR does not point to the end of the samples")
+	}
+	if desc.dstaddr != unsafe.Pointer(&sam.DAC.DATA[1]) {
+		t.Errorf("DSTADDR is not the DAC1 data register")
+	}
+	if desc.descaddr != unsafe.Pointer(desc) {
+		t.Errorf("looping descriptor does not point to itself")
+	}
+	if dmaDescriptors[0].btcnt != 1234 {
+		t.Errorf("descriptor of another DMA channel was overwritten")
+	}
+
+	if cc := tc.CC[0].Get(); cc != 18750-1 {
+		t.Errorf("CC0 = %d, want %d", cc, 18750-1)
+	}
+	if wave := tc.WAVE.Get(); wave != sam.TC_COUNT16_WAVE_WAVEGEN_MFRQ<<sam.TC_COUNT16_WAVE_WAVEGEN_Pos {
+		t.Errorf("WAVE = %#x, want MFRQ", wave)
+	}
+	if ctrla := tc.CTRLA.Get(); ctrla != 5<<sam.TC_COUNT16_CTRLA_PRESCALER_Pos|sam.TC_COUNT16_CTRLA_ENABLE {
+		t.Errorf("CTRLA = %#x, want prescaler 64 and enabled", ctrla)
+	}
+
+	DAC1.stopStream(dmac, tc)
+	if dmac.CHANNEL[ch].CHCTRLA.HasBits(sam.DMAC_CHANNEL_CHCTRLA_ENABLE) {
+		t.Errorf("DMA channel still enabled after stopStream")
+	}
+	if tc.CTRLA.HasBits(sam.TC_COUNT16_CTRLA_ENABLE) {
+		t.Errorf("timer still enabled after stopStream")
+	}
+}
@@ -0,0 +1,64 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import (
+	"device/sam"
+	"runtime/interrupt"
+	"unsafe"
+)
+
+// dmaDescriptor is a DMAC transfer descriptor, as stored in SRAM.
+type dmaDescriptor struct {
+	btctrl   uint16
+	btcnt    uint16
+	srcaddr  unsafe.Pointer // end of the source block when SRCINC is set
+	dstaddr  unsafe.Pointer
+	descaddr unsafe.Pointer // next descriptor, or nil
+}
+
+// Fields of the BTCTRL word of a DMAC transfer descriptor.
+const (
+	dmaBtctrlVALID         = 1 << 0
+	dmaBtctrlBEATSIZEHWORD = 1 << 8
+	dmaBtctrlSRCINC        = 1 << 10
+)
+
+// The DMAC reads the first descriptor of channel n from dmaDescriptors[n] and
+// writes back the state of active channels to dmaWriteback[n]. There is a
+// single table for the whole chip, so every user of the DMAC must claim its
+// channel with dmaClaimChannel and only touch that entry.
+var (
+	//go:align 16
+	dmaDescriptors [32]dmaDescriptor
+	//go:align 16
+	dmaWriteback [32]dmaDescriptor
+
+	dmaChannelsUsed uint32
+)
+
+// dmaClaimChannel reserves a DMA channel and returns its number, or -1 when
+// all channels are in use.
+func dmaClaimChannel() int {
+	mask := interrupt.Disable()
+	defer interrupt.Restore(mask)
+	for ch := 0; ch < len(dmaDescriptors); ch++ {
+		if dmaChannelsUsed&(1<<ch) == 0 {
+			dmaChannelsUsed |= 1 << ch
+			return ch
+		}
+	}
+	return -1
+}
+
+// dmaEnable points the DMAC at the shared descriptor tables and enables it,
+// unless this was already done by another DMA user.
+func dmaEnable(dmac *sam.DMAC_Type) {
+	if dmac.CTRL.HasBits(sam.DMAC_CTRL_DMAENABLE) {
+		return
+	}
+	dmac.BASEADDR.Set(uint32(uintptr(unsafe.Pointer(&dmaDescriptors))))
+	dmac.WRBADDR.Set(uint32(uintptr(unsafe.Pointer(&dmaWriteback))))
+	dmac.CTRL.Set(sam.DMAC_CTRL_DMAENABLE | sam.DMAC_CTRL_LVLEN0 | sam.DMAC_CTRL_LVLEN1 | sam.DMAC_CTRL_LVLEN2 | sam.DMAC_CTRL_LVLEN3)
+}
@@ -0,0 +1,39 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import "device/sam"
+
+// Bits in the RSTC.RCAUSE register.
+const (
+	rcausePOR     = 1 << 0
+	rcauseBODCORE = 1 << 1
+	rcauseBODVDD  = 1 << 2
+	rcauseEXT     = 1 << 4
+	rcauseWDT     = 1 << 5
+	rcauseSYST    = 1 << 6
+	rcauseBACKUP  = 1 << 7 // wakeup from backup mode
+)
+
+// readResetReason reads the reset cause. The register is updated by hardware
+// on every reset, so it doesn't need to be cleared.
+func readResetReason() ResetReason {
+	rcause := sam.RSTC.RCAUSE.Get()
+	switch {
+	case rcause&rcausePOR != 0:
+		return ResetReasonPowerOn
+	case rcause&(rcauseBODCORE|rcauseBODVDD) != 0:
+		return ResetReasonBrownout
+	case rcause&rcauseWDT != 0:
+		return ResetReasonWatchdog
+	case rcause&rcauseSYST != 0:
+		return ResetReasonSoftware
+	case rcause&rcauseEXT != 0:
+		return ResetReasonExternal
+	case rcause&rcauseBACKUP != 0:
+		return ResetReasonWakeup
+	default:
+		return ResetReasonUnknown
+	}
+}
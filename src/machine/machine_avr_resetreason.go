@@ -0,0 +1,35 @@
+//go:build avr
+// +build avr
+
+package machine
+
+import "device/avr"
+
+// Bits in the MCUSR register.
+const (
+	mcusrPORF  = 1 << 0
+	mcusrEXTRF = 1 << 1
+	mcusrBORF  = 1 << 2
+	mcusrWDRF  = 1 << 3
+)
+
+func readResetReason() ResetReason {
+	mcusr := avr.MCUSR.Get()
+
+	// The flags are only cleared by a power-on reset or by writing zero, so
+	// clear them to have a clean state for the next reset.
+	avr.MCUSR.Set(0)
+
+	switch {
+	case mcusr&mcusrWDRF != 0:
+		return ResetReasonWatchdog
+	case mcusr&mcusrBORF != 0:
+		return ResetReasonBrownout
+	case mcusr&mcusrEXTRF != 0:
+		return ResetReasonExternal
+	case mcusr&mcusrPORF != 0:
+		return ResetReasonPowerOn
+	default:
+		return ResetReasonUnknown
+	}
+}
@@ -304,22 +304,34 @@ var (
 )
 
 type UART struct {
-	Bus    *esp.UART_Type
-	Buffer *RingBuffer
+	Bus          *esp.UART_Type
+	Buffer       *RingBuffer
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
-	uart.Bus.CLKDIV.Set(peripheralClock / config.BaudRate)
+	uart.writeTimeout = config.WriteTimeout
+	uart.SetBaudRate(config.BaudRate)
+}
+
+// SetBaudRate sets the communication speed for the UART. Only the clock
+// divider register is changed, the rest of the configuration is left as it is.
+func (uart *UART) SetBaudRate(br uint32) {
+	uart.Bus.CLKDIV.Set(peripheralClock / br)
 }
 
 func (uart *UART) WriteByte(b byte) error {
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for (uart.Bus.STATUS.Get()>>16)&0xff >= 128 {
 		// Read UART_TXFIFO_CNT from the status register, which indicates how
 		// many bytes there are in the transmit buffer. Wait until there are
 		// less than 128 bytes in this buffer (the default buffer size).
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	uart.Bus.TX_FIFO.Set(b)
 	return nil
@@ -359,14 +371,14 @@ func (spi SPI) Configure(config SPIConfig) error {
 
 	// Configure the SPI clock. This assumes a peripheral clock of 80MHz.
 	var clockReg uint32
-	if config.Frequency > 40e6 {
+	if config.Frequency >= 80e6 {
 		// Don't use a prescaler, but directly connect to the APB clock. This
-		// results in a SPI clock frequency of 40MHz.
+		// results in a SPI clock frequency of 80MHz.
 		clockReg |= esp.SPI_CLOCK_CLK_EQU_SYSCLK
 	} else {
-		// Use a prescaler for frequencies below 40MHz. They will get rounded
-		// down to the next possible frequency (20MHz, 13.3MHz, 10MHz, 8MHz,
-		// 6.7MHz, 5.7MHz, 5MHz, etc).
+		// Use a prescaler for frequencies below 80MHz. They will get rounded
+		// down to the next possible frequency (40MHz, 20MHz, 13.3MHz, 10MHz,
+		// 8MHz, 6.7MHz, 5.7MHz, 5MHz, etc).
 		// This code is much simpler than how ESP-IDF configures the frequency,
 		// but should be just as accurate. The only exception is for frequencies
 		// below 4883Hz, which will need special support.
@@ -441,6 +453,19 @@ func (spi SPI) Configure(config SPIConfig) error {
 	return nil
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured, which is
+// the APB clock (80MHz) divided by the prescaler and the number of ticks per
+// SPI clock cycle.
+func (spi SPI) ActualFrequency() uint32 {
+	clockReg := spi.Bus.CLOCK.Get()
+	if clockReg&esp.SPI_CLOCK_CLK_EQU_SYSCLK != 0 {
+		return 80e6
+	}
+	pre := (clockReg&esp.SPI_CLOCK_CLKDIV_PRE_Msk)>>esp.SPI_CLOCK_CLKDIV_PRE_Pos + 1
+	n := (clockReg&esp.SPI_CLOCK_CLKCNT_N_Msk)>>esp.SPI_CLOCK_CLKCNT_N_Pos + 1
+	return 80e6 / (pre * n)
+}
+
 // Transfer writes/reads a single byte using the SPI interface. If you need to
 // transfer larger amounts of data, Tx will be faster.
 func (spi SPI) Transfer(w byte) (byte, error) {
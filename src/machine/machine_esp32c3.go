@@ -259,6 +259,8 @@ type UART struct {
 	ParityErrorDetected  bool // set when parity error detected
 	DataErrorDetected    bool // set when data corruption detected
 	DataOverflowDetected bool // set when data overflow detected in UART FIFO buffer or RingBuffer
+
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 const (
@@ -285,6 +287,7 @@ func (uart *UART) Configure(config UARTConfig) error {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 	if config.TX == config.RX {
 		return errSamePins
 	}
@@ -495,10 +498,14 @@ func (uart *UART) enableReceiver() {
 }
 
 func (uart *UART) WriteByte(b byte) error {
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for (uart.Bus.STATUS.Get()&esp.UART_STATUS_TXFIFO_CNT_Msk)>>esp.UART_STATUS_TXFIFO_CNT_Pos >= 128 {
 		// Read UART_TXFIFO_CNT from the status register, which indicates how
 		// many bytes there are in the transmit buffer. Wait until there are
 		// less than 128 bytes in this buffer (the default buffer size).
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	uart.Bus.FIFO.Set(uint32(b))
 	return nil
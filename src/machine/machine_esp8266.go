@@ -167,10 +167,12 @@ var DefaultUART = UART0
 
 // UART0 is a hardware UART that supports both TX and RX.
 var UART0 = &_UART0
-var _UART0 = UART{Buffer: NewRingBuffer()}
+var _UART0 = UART{Bus: esp.UART0, Buffer: NewRingBuffer()}
 
 type UART struct {
-	Buffer *RingBuffer
+	Bus          *esp.UART_Type
+	Buffer       *RingBuffer
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 // Configure the UART baud rate. TX and RX pins are fixed by the hardware so
@@ -179,15 +181,26 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
-	esp.UART0.UART_CLKDIV.Set(CPUFrequency() / config.BaudRate)
+	uart.writeTimeout = config.WriteTimeout
+	uart.SetBaudRate(config.BaudRate)
+}
+
+// SetBaudRate sets the communication speed for the UART. Only the clock
+// divider register is changed, the rest of the configuration is left as it is.
+func (uart *UART) SetBaudRate(br uint32) {
+	uart.Bus.UART_CLKDIV.Set(CPUFrequency() / br)
 }
 
 // WriteByte writes a single byte to the output buffer. Note that the hardware
 // includes a buffer of 128 bytes which will be used first.
 func (uart *UART) WriteByte(c byte) error {
-	for (esp.UART0.UART_STATUS.Get()>>16)&0xff >= 128 {
+	deadline := uartWriteDeadline(uart.writeTimeout)
+	for (uart.Bus.UART_STATUS.Get()>>16)&0xff >= 128 {
 		// Wait until the TX buffer has room.
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
-	esp.UART0.UART_FIFO.Set(uint32(c))
+	uart.Bus.UART_FIFO.Set(uint32(c))
 	return nil
 }
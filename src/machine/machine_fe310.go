@@ -71,8 +71,9 @@ func (p Pin) PortMaskClear() (*uint32, uint32) {
 }
 
 type UART struct {
-	Bus    *sifive.UART_Type
-	Buffer *RingBuffer
+	Bus          *sifive.UART_Type
+	Buffer       *RingBuffer
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 var (
@@ -84,20 +85,27 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
+	uart.SetBaudRate(config.BaudRate)
+	sifive.UART0.TXCTRL.Set(sifive.UART_TXCTRL_ENABLE)
+	sifive.UART0.RXCTRL.Set(sifive.UART_RXCTRL_ENABLE)
+	sifive.UART0.IE.Set(sifive.UART_IE_RXWM) // enable the receive interrupt (only)
+	intr := interrupt.New(sifive.IRQ_UART0, _UART0.handleInterrupt)
+	intr.SetPriority(5)
+	intr.Enable()
+}
+
+// SetBaudRate sets the communication speed for the UART. Only the divisor
+// register is changed, the rest of the configuration is left as it is.
+func (uart *UART) SetBaudRate(br uint32) {
 	// The divisor is:
 	//   fbaud = fin / (div + 1)
 	// Restating to get the divisor:
 	//   div = fin / fbaud - 1
 	// But we're using integers, so we should take care of rounding:
 	//   div = (fin + fbaud/2) / fbaud - 1
-	divisor := (CPUFrequency()+config.BaudRate/2)/config.BaudRate - 1
+	divisor := (CPUFrequency()+br/2)/br - 1
 	sifive.UART0.DIV.Set(divisor)
-	sifive.UART0.TXCTRL.Set(sifive.UART_TXCTRL_ENABLE)
-	sifive.UART0.RXCTRL.Set(sifive.UART_RXCTRL_ENABLE)
-	sifive.UART0.IE.Set(sifive.UART_IE_RXWM) // enable the receive interrupt (only)
-	intr := interrupt.New(sifive.IRQ_UART0, _UART0.handleInterrupt)
-	intr.SetPriority(5)
-	intr.Enable()
 }
 
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
@@ -112,11 +120,16 @@ func (uart *UART) handleInterrupt(interrupt.Interrupt) {
 	uart.Receive(c)
 }
 
-func (uart *UART) WriteByte(c byte) {
-	for sifive.UART0.TXDATA.Get()&sifive.UART_TXDATA_FULL != 0 {
+func (uart *UART) WriteByte(c byte) error {
+	deadline := uartWriteDeadline(uart.writeTimeout)
+	for uart.Bus.TXDATA.Get()&sifive.UART_TXDATA_FULL != 0 {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 
-	sifive.UART0.TXDATA.Set(uint32(c))
+	uart.Bus.TXDATA.Set(uint32(c))
+	return nil
 }
 
 // SPI on the FE310. The normal SPI0 is actually a quad-SPI meant for flash, so it is best
@@ -154,8 +167,15 @@ func (spi SPI) Configure(config SPIConfig) error {
 		config.Frequency = 4000000 // 4MHz
 	}
 
-	// div = (SPI_CFG(dev)->f_sys / (2 * frequency)) - 1;
-	div := CPUFrequency()/(2*config.Frequency) - 1
+	// The SPI clock is f_sys / (2 * (div + 1)). Round the divisor up, so that
+	// the SPI clock is never faster than the requested frequency.
+	div := spiClockDivider(CPUFrequency(), 2*config.Frequency)
+	if div > 0 {
+		div--
+	}
+	if div > 0xfff {
+		div = 0xfff // the divisor is 12 bits wide
+	}
 	spi.Bus.DIV.Set(div)
 
 	// set mode
@@ -192,6 +212,12 @@ func (spi SPI) Configure(config SPIConfig) error {
 	return nil
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured.
+func (spi SPI) ActualFrequency() uint32 {
+	div := spi.Bus.DIV.Get() & 0xfff
+	return CPUFrequency() / (2 * (div + 1))
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi SPI) Transfer(w byte) (byte, error) {
 	// wait for tx ready
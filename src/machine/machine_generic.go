@@ -3,13 +3,15 @@
 
 package machine
 
+import _ "unsafe" // for go:linkname
+
 // Dummy machine package that calls out to external functions.
 
 const deviceName = "generic"
 
 var (
-	UART0 = &UART{0}
-	USB   = &UART{100}
+	UART0 = &UART{Bus: 0}
+	USB   = &UART{Bus: 100}
 )
 
 // The Serial port always points to the default UART in a simulated environment.
@@ -25,6 +27,13 @@ const (
 	PinInputPulldown
 )
 
+// The whole PinConfig is passed to the host, which may support the pull resistor
+// and drive strength.
+const (
+	hasPinPull          = true
+	maxPinDriveStrength = PinDriveStrength(255)
+)
+
 func (p Pin) Configure(config PinConfig) {
 	gpioConfigure(p, config)
 }
@@ -37,6 +46,53 @@ func (p Pin) Get() bool {
 	return gpioGet(p)
 }
 
+// PinChange is the kind of pin change that triggers the callback set with
+// SetInterrupt.
+type PinChange uint8
+
+// Pin change interrupt constants for SetInterrupt.
+const (
+	PinRising PinChange = 1 << iota
+	PinFalling
+	PinToggle = PinRising | PinFalling
+)
+
+type pinInterrupt struct {
+	change   PinChange
+	callback func(Pin)
+}
+
+// pinInterrupts stores the callbacks set with SetInterrupt. The environment
+// reports every change of a pin, and the callback is only called for the
+// changes it was set for.
+var pinInterrupts = map[Pin]pinInterrupt{}
+
+// SetInterrupt sets the callback that is called when the pin changes state.
+// This replaces a previously set callback on this pin; a nil callback removes
+// it.
+func (p Pin) SetInterrupt(change PinChange, callback func(Pin)) error {
+	if callback == nil {
+		delete(pinInterrupts, p)
+		return nil
+	}
+	pinInterrupts[p] = pinInterrupt{change, callback}
+	return nil
+}
+
+// gpioInterrupt is called by the environment when a pin changes state, like
+// the pin change interrupt on real hardware.
+//
+//export __tinygo_gpio_interrupt
+func gpioInterrupt(pin Pin, high bool) {
+	interrupt, ok := pinInterrupts[pin]
+	if !ok {
+		return
+	}
+	if (high && interrupt.change&PinRising != 0) || (!high && interrupt.change&PinFalling != 0) {
+		interrupt.callback(pin)
+	}
+}
+
 //export __tinygo_gpio_configure
 func gpioConfigure(pin Pin, config PinConfig)
 
@@ -58,8 +114,27 @@ type SPIConfig struct {
 	Mode      uint8
 }
 
+// spiFrequencies stores the frequency each simulated SPI bus was configured
+// with, as there is no hardware to read it back from.
+var spiFrequencies = map[uint8]uint32{}
+
 func (spi SPI) Configure(config SPIConfig) {
 	spiConfigure(spi.Bus, config.SCK, config.SDO, config.SDI)
+	spiFrequencies[spi.Bus] = config.Frequency
+}
+
+// ActualFrequency returns the SPI clock frequency that was configured. The
+// simulated SPI bus can run at any frequency, so this is the frequency passed
+// to Configure.
+func (spi SPI) ActualFrequency() uint32 {
+	return spiFrequencies[spi.Bus]
+}
+
+// SetBaudRate sets the SPI clock frequency. The simulated SPI bus only records
+// it, see ActualFrequency.
+func (spi SPI) SetBaudRate(br uint32) error {
+	spiFrequencies[spi.Bus] = br
+	return nil
 }
 
 // Transfer writes/reads a single byte using the SPI interface.
@@ -90,6 +165,39 @@ func (adc ADC) Get() uint16 {
 //export __tinygo_adc_read
 func adcRead(pin Pin) uint16
 
+// Configure configures the analog comparator inputs and threshold.
+func (c *Comparator) Configure(config ComparatorConfig) error {
+	comparatorConfigure(config.PositiveInput, config.NegativeInput, config.threshold())
+	return nil
+}
+
+// Get returns whether the positive input is currently above the threshold.
+func (c *Comparator) Get() bool {
+	return comparatorGet()
+}
+
+// SetInterrupt sets the callback that is called when the comparator output
+// changes. Use a nil callback to disable the interrupt.
+func (c *Comparator) SetInterrupt(change ComparatorChange, callback func(above bool)) error {
+	c.change = change
+	c.callback = callback
+	return nil
+}
+
+//export __tinygo_comparator_configure
+func comparatorConfigure(positive, negative Pin, threshold uint16)
+
+//export __tinygo_comparator_get
+func comparatorGet() bool
+
+// comparatorInterrupt is called by the environment when the comparator output
+// changes, like the comparator interrupt on real hardware.
+//
+//export __tinygo_comparator_interrupt
+func comparatorInterrupt(above bool) {
+	Comparator0.handleInterrupt(above)
+}
+
 // I2C is a generic implementation of the Inter-IC communication protocol.
 type I2C struct {
 	Bus uint8
@@ -108,6 +216,12 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 	return nil
 }
 
+// SetBaudRate sets the I2C bus speed. The simulated I2C bus doesn't have a bus
+// speed, so this does nothing.
+func (i2c *I2C) SetBaudRate(br uint32) error {
+	return nil
+}
+
 // Tx does a single I2C transaction at the specified address.
 func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 	i2cTransfer(i2c.Bus, &w[0], len(w), &r[0], len(r))
@@ -123,21 +237,47 @@ func i2cTransfer(bus uint8, w *byte, wlen int, r *byte, rlen int) int
 
 type UART struct {
 	Bus uint8
+
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 // Configure the UART.
 func (uart *UART) Configure(config UARTConfig) {
+	uart.writeTimeout = config.WriteTimeout
 	uartConfigure(uart.Bus, config.TX, config.RX)
 }
 
+// SetBaudRate sets the communication speed for the UART. The simulated UART
+// doesn't have a baud rate, so this does nothing.
+func (uart *UART) SetBaudRate(br uint32) {
+}
+
 // Read from the UART.
 func (uart *UART) Read(data []byte) (n int, err error) {
 	return uartRead(uart.Bus, &data[0], len(data)), nil
 }
 
-// Write to the UART.
+// Write to the UART. If a write timeout is set, the remaining data is written
+// until the simulated UART accepts it all or the timeout passes without any
+// progress.
 func (uart *UART) Write(data []byte) (n int, err error) {
-	return uartWrite(uart.Bus, &data[0], len(data)), nil
+	if uart.writeTimeout == 0 {
+		return uartWrite(uart.Bus, &data[0], len(data)), nil
+	}
+	deadline := uartWriteDeadline(uart.writeTimeout)
+	for n < len(data) {
+		written := uartWrite(uart.Bus, &data[n], len(data)-n)
+		if written > 0 {
+			n += written
+			deadline = uartWriteDeadline(uart.writeTimeout)
+			continue
+		}
+		if uartDeadlinePassed(deadline) {
+			return n, ErrUARTWriteTimeout
+		}
+		gosched()
+	}
+	return n, nil
 }
 
 // Buffered returns the number of bytes currently stored in the RX buffer.
@@ -154,8 +294,12 @@ func (uart *UART) ReadByte() (byte, error) {
 
 // WriteByte writes a single byte to the UART.
 func (uart *UART) WriteByte(b byte) error {
-	uartWrite(uart.Bus, &b, 1)
-	return nil
+	if uart.writeTimeout == 0 {
+		uartWrite(uart.Bus, &b, 1)
+		return nil
+	}
+	_, err := uart.Write([]byte{b})
+	return err
 }
 
 //export __tinygo_uart_configure
@@ -167,15 +311,18 @@ func uartRead(bus uint8, buf *byte, bufLen int) int
 //export __tinygo_uart_write
 func uartWrite(bus uint8, buf *byte, bufLen int) int
 
+//go:linkname gosched runtime.Gosched
+func gosched()
+
 // Some objects used by Atmel SAM D chips (samd21, samd51).
 // Defined here (without build tag) for convenience.
 var (
-	sercomUSART0 = UART{0}
-	sercomUSART1 = UART{1}
-	sercomUSART2 = UART{2}
-	sercomUSART3 = UART{3}
-	sercomUSART4 = UART{4}
-	sercomUSART5 = UART{5}
+	sercomUSART0 = UART{Bus: 0}
+	sercomUSART1 = UART{Bus: 1}
+	sercomUSART2 = UART{Bus: 2}
+	sercomUSART3 = UART{Bus: 3}
+	sercomUSART4 = UART{Bus: 4}
+	sercomUSART5 = UART{Bus: 5}
 
 	sercomI2CM0 = &I2C{0}
 	sercomI2CM1 = &I2C{1}
@@ -195,3 +342,9 @@ var (
 	sercomSPIM6 = SPI{6}
 	sercomSPIM7 = SPI{7}
 )
+
+// readResetReason returns ResetReasonUnknown, as a simulated chip has no reset
+// status register.
+func readResetReason() ResetReason {
+	return ResetReasonUnknown
+}
@@ -342,8 +342,9 @@ func (p Pin) SetInterrupt(change PinChange, callback func(Pin)) error {
 }
 
 type UART struct {
-	Bus    *kendryte.UARTHS_Type
-	Buffer *RingBuffer
+	Bus          *kendryte.UARTHS_Type
+	Buffer       *RingBuffer
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 var (
@@ -357,6 +358,7 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	// Use default pins if not set.
 	if config.TX == 0 && config.RX == 0 {
@@ -367,9 +369,7 @@ func (uart *UART) Configure(config UARTConfig) {
 	config.TX.SetFPIOAFunction(FUNC_UARTHS_TX)
 	config.RX.SetFPIOAFunction(FUNC_UARTHS_RX)
 
-	div := CPUFrequency()/config.BaudRate - 1
-
-	uart.Bus.DIV.Set(div)
+	uart.SetBaudRate(config.BaudRate)
 	uart.Bus.TXCTRL.Set(kendryte.UARTHS_TXCTRL_TXEN)
 	uart.Bus.RXCTRL.Set(kendryte.UARTHS_RXCTRL_RXEN)
 
@@ -381,6 +381,13 @@ func (uart *UART) Configure(config UARTConfig) {
 	intr.Enable()
 }
 
+// SetBaudRate sets the communication speed for the UART. Only the divisor
+// register is changed, the rest of the configuration is left as it is.
+func (uart *UART) SetBaudRate(br uint32) {
+	div := CPUFrequency()/br - 1
+	uart.Bus.DIV.Set(div)
+}
+
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
 	rxdata := uart.Bus.RXDATA.Get()
 	c := byte(rxdata)
@@ -393,11 +400,16 @@ func (uart *UART) handleInterrupt(interrupt.Interrupt) {
 	uart.Receive(c)
 }
 
-func (uart *UART) WriteByte(c byte) {
+func (uart *UART) WriteByte(c byte) error {
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for uart.Bus.TXDATA.Get()&kendryte.UARTHS_TXDATA_FULL != 0 {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 
 	uart.Bus.TXDATA.Set(uint32(c))
+	return nil
 }
 
 type SPI struct {
@@ -456,7 +468,15 @@ func (spi SPI) Configure(config SPIConfig) error {
 		config.Frequency = 4000000 // 4MHz
 	}
 
-	baudr := CPUFrequency() / config.Frequency
+	// The divider must be an even number between 2 and 65534. Round it up, so
+	// that the SPI clock is never faster than requested.
+	baudr := spiClockDivider(CPUFrequency(), config.Frequency)
+	baudr += baudr & 1
+	if baudr < 2 {
+		baudr = 2
+	} else if baudr > 0xfffe {
+		baudr = 0xfffe
+	}
 	spi.Bus.BAUDR.Set(baudr)
 
 	// Configure SPI mode 0, standard frame format, 8-bit data, little-endian.
@@ -473,6 +493,15 @@ func (spi SPI) Configure(config SPIConfig) error {
 	return nil
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured.
+func (spi SPI) ActualFrequency() uint32 {
+	baudr := spi.Bus.BAUDR.Get()
+	if baudr == 0 {
+		return 0 // the SPI clock is disabled
+	}
+	return CPUFrequency() / baudr
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi SPI) Transfer(w byte) (byte, error) {
 	spi.Bus.SSIENR.Set(0)
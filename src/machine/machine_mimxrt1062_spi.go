@@ -64,6 +64,9 @@ const (
 		nxp.LPSPI_SR_REF | nxp.LPSPI_SR_DMF | nxp.LPSPI_SR_MBF
 )
 
+// lpspiClock is the LPSPI root clock frequency (PLL2).
+const lpspiClock = 132000000
+
 var (
 	errSPINotConfigured = errors.New("SPI interface is not yet configured")
 )
@@ -135,6 +138,13 @@ func (spi *SPI) Configure(config SPIConfig) {
 	spi.configured = true
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured, which is
+// the LPSPI root clock divided by SCKDIV+2.
+func (spi *SPI) ActualFrequency() uint32 {
+	div := (spi.Bus.CCR.Get() & nxp.LPSPI_CCR_SCKDIV_Msk) >> nxp.LPSPI_CCR_SCKDIV_Pos
+	return lpspiClock / (div + 2)
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi *SPI) Transfer(w byte) (byte, error) {
 	if !spi.configured {
@@ -207,12 +217,11 @@ func (spi *SPI) hasHardwareCSPin() bool {
 //
 //	void SPIClass::setClockDivider_noInline(uint32_t clk)
 func (spi *SPI) getClockDivisor(freq uint32) uint32 {
-	const clock = 132000000 // LPSPI root clock frequency (PLL2)
-	d := uint32(clock)
+	d := uint32(lpspiClock)
 	if freq > 0 {
 		d /= freq
 	}
-	if d > 0 && clock/d > freq {
+	if d > 0 && lpspiClock/d > freq {
 		d++
 	}
 	if d > 257 {
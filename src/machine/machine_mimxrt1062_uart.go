@@ -35,6 +35,7 @@ type UART struct {
 	// auxiliary state data used internally
 	configured   bool
 	transmitting volatile.Register32
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 func (uart *UART) isTransmitting() bool { return uart.transmitting.Get() != 0 }
@@ -56,6 +57,7 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = defaultUartFreq
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	// use default UART pins if not specified
 	if config.RX == 0 && config.TX == 0 {
@@ -81,17 +83,8 @@ func (uart *UART) Configure(config UARTConfig) {
 	// disable until we have finished configuring registers
 	uart.Bus.CTRL.Set(0)
 
-	// determine the baud rate and over-sample divisors
-	sbr, osr := uart.getBaudRateDivisor(uart.baud)
-
 	// set the baud rate, over-sample configuration, stop bits
-	baudBits := (((osr - 1) << nxp.LPUART_BAUD_OSR_Pos) & nxp.LPUART_BAUD_OSR_Msk) |
-		((sbr << nxp.LPUART_BAUD_SBR_Pos) & nxp.LPUART_BAUD_SBR_Msk)
-	if osr <= 8 {
-		// if OSR less than or equal to 8, we must enable sampling on both edges
-		baudBits |= nxp.LPUART_BAUD_BOTHEDGE
-	}
-	uart.Bus.BAUD.Set(baudBits)
+	uart.Bus.BAUD.Set(uart.getBaudBits(uart.baud))
 	uart.Bus.PINCFG.Set(0) // disable triggers
 
 	// configure watermarks, flush and enable TX/RX FIFOs
@@ -165,6 +158,28 @@ func (uart *UART) Disable() {
 	uart.configured = false
 }
 
+// SetBaudRate sets the communication speed for the UART. Only the baud rate and
+// over-sample divisors are changed, the rest of the configuration is left as
+// it is. Buffered data is transmitted at the old baud rate first, as the
+// transmitter and receiver must be disabled while changing the divisors.
+func (uart *UART) SetBaudRate(br uint32) {
+	uart.baud = br
+	if !uart.configured {
+		// The baud rate will be set by Configure.
+		return
+	}
+
+	// wait for any buffered data to send
+	uart.Sync()
+
+	// the BAUD register may only be written while TX/RX are disabled
+	ctrl := uart.Bus.CTRL.Get()
+	uart.Bus.CTRL.ClearBits(nxp.LPUART_CTRL_TE | nxp.LPUART_CTRL_RE)
+	baudMask := uint32(nxp.LPUART_BAUD_OSR_Msk | nxp.LPUART_BAUD_SBR_Msk | nxp.LPUART_BAUD_BOTHEDGE)
+	uart.Bus.BAUD.ReplaceBits(uart.getBaudBits(br), baudMask, 0)
+	uart.Bus.CTRL.Set(ctrl)
+}
+
 // Sync blocks the calling goroutine until all data in the output buffer has
 // been transmitted.
 func (uart *UART) Sync() error {
@@ -176,12 +191,31 @@ func (uart *UART) Sync() error {
 // WriteByte writes a single byte of data to the UART interface.
 func (uart *UART) WriteByte(c byte) error {
 	uart.startTransmitting()
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for !uart.txBuffer.Put(c) {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	uart.Bus.CTRL.SetBits(nxp.LPUART_CTRL_TIE)
 	return nil
 }
 
+// getBaudBits returns the BAUD register bits (baud rate and over-sample
+// divisors) for the given baud rate.
+func (uart *UART) getBaudBits(baudRate uint32) uint32 {
+	// determine the baud rate and over-sample divisors
+	sbr, osr := uart.getBaudRateDivisor(baudRate)
+
+	baudBits := (((osr - 1) << nxp.LPUART_BAUD_OSR_Pos) & nxp.LPUART_BAUD_OSR_Msk) |
+		((sbr << nxp.LPUART_BAUD_SBR_Pos) & nxp.LPUART_BAUD_SBR_Msk)
+	if osr <= 8 {
+		// if OSR less than or equal to 8, we must enable sampling on both edges
+		baudBits |= nxp.LPUART_BAUD_BOTHEDGE
+	}
+	return baudBits
+}
+
 // getBaudRateDivisor finds the greatest over-sampling factor (4..32) and
 // corresponding baud rate divisor (1..8191) that best partition a given baud
 // rate into equal intervals.
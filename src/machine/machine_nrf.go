@@ -30,11 +30,44 @@ const (
 // Callbacks to be called for pins configured with SetInterrupt.
 var pinCallbacks [len(nrf.GPIOTE.CONFIG)]func(Pin)
 
+// Drive strengths for PinConfig.DriveStrength. The reset value is
+// PinDriveStandard.
+const (
+	PinDriveStandard PinDriveStrength = iota + 1 // standard drive for 0 and 1 (S0S1)
+	PinDriveHigh                                 // high drive for 0 and 1 (H0H1)
+)
+
+// The pull resistor and drive strength of PinConfig are supported.
+const (
+	hasPinPull          = true
+	maxPinDriveStrength = PinDriveHigh
+)
+
 // Configure this pin with the given configuration.
 func (p Pin) Configure(config PinConfig) {
-	cfg := config.Mode | nrf.GPIO_PIN_CNF_DRIVE_S0S1 | nrf.GPIO_PIN_CNF_SENSE_Disabled
 	port, pin := p.getPortPin()
-	port.PIN_CNF[pin].Set(uint32(cfg))
+	port.PIN_CNF[pin].Set(pinCNF(config))
+}
+
+// pinCNF returns the PIN_CNF register value for the given configuration. A
+// pull other than PinPullDefault overrides the pull of the pin mode.
+func pinCNF(config PinConfig) uint32 {
+	cfg := uint32(config.Mode) | nrf.GPIO_PIN_CNF_SENSE_Disabled<<nrf.GPIO_PIN_CNF_SENSE_Pos
+	switch config.Pull {
+	case PinPullNone:
+		cfg = cfg&^nrf.GPIO_PIN_CNF_PULL_Msk | nrf.GPIO_PIN_CNF_PULL_Disabled<<nrf.GPIO_PIN_CNF_PULL_Pos
+	case PinPullUp:
+		cfg = cfg&^nrf.GPIO_PIN_CNF_PULL_Msk | nrf.GPIO_PIN_CNF_PULL_Pullup<<nrf.GPIO_PIN_CNF_PULL_Pos
+	case PinPullDown:
+		cfg = cfg&^nrf.GPIO_PIN_CNF_PULL_Msk | nrf.GPIO_PIN_CNF_PULL_Pulldown<<nrf.GPIO_PIN_CNF_PULL_Pos
+	}
+	switch config.DriveStrength {
+	case PinDriveHigh:
+		cfg |= nrf.GPIO_PIN_CNF_DRIVE_H0H1 << nrf.GPIO_PIN_CNF_DRIVE_Pos
+	default:
+		cfg |= nrf.GPIO_PIN_CNF_DRIVE_S0S1 << nrf.GPIO_PIN_CNF_DRIVE_Pos
+	}
+	return cfg
 }
 
 // Set the pin to high or low.
@@ -131,13 +164,15 @@ func (p Pin) SetInterrupt(change PinChange, callback func(Pin)) error {
 
 // UART on the NRF.
 type UART struct {
-	Buffer *RingBuffer
+	Buffer       *RingBuffer
+	Bus          *nrf.UART_Type
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 // UART
 var (
 	// UART0 is the hardware UART on the NRF SoC.
-	_UART0 = UART{Buffer: NewRingBuffer()}
+	_UART0 = UART{Buffer: NewRingBuffer(), Bus: nrf.UART0}
 	UART0  = &_UART0
 )
 
@@ -147,6 +182,7 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	uart.SetBaudRate(config.BaudRate)
 
@@ -158,10 +194,10 @@ func (uart *UART) Configure(config UARTConfig) {
 		uart.setPins(config.TX, config.RX)
 	}
 
-	nrf.UART0.ENABLE.Set(nrf.UART_ENABLE_ENABLE_Enabled)
-	nrf.UART0.TASKS_STARTTX.Set(1)
-	nrf.UART0.TASKS_STARTRX.Set(1)
-	nrf.UART0.INTENSET.Set(nrf.UART_INTENSET_RXDRDY_Msk)
+	uart.Bus.ENABLE.Set(nrf.UART_ENABLE_ENABLE_Enabled)
+	uart.Bus.TASKS_STARTTX.Set(1)
+	uart.Bus.TASKS_STARTRX.Set(1)
+	uart.Bus.INTENSET.Set(nrf.UART_INTENSET_RXDRDY_Msk)
 
 	// Enable RX IRQ.
 	intr := interrupt.New(nrf.IRQ_UART0, _UART0.handleInterrupt)
@@ -181,22 +217,26 @@ func (uart *UART) SetBaudRate(br uint32) {
 	// https://devzone.nordicsemi.com/f/nordic-q-a/391/uart-baudrate-register-values/2046#2046
 	rate := uint32((uint64(br/400)*uint64(400*0xffffffff/16000000) + 0x800) & 0xffffff000)
 
-	nrf.UART0.BAUDRATE.Set(rate)
+	uart.Bus.BAUDRATE.Set(rate)
 }
 
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
-	nrf.UART0.EVENTS_TXDRDY.Set(0)
-	nrf.UART0.TXD.Set(uint32(c))
-	for nrf.UART0.EVENTS_TXDRDY.Get() == 0 {
+	uart.Bus.EVENTS_TXDRDY.Set(0)
+	uart.Bus.TXD.Set(uint32(c))
+	deadline := uartWriteDeadline(uart.writeTimeout)
+	for uart.Bus.EVENTS_TXDRDY.Get() == 0 {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 	return nil
 }
 
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
-	if nrf.UART0.EVENTS_RXDRDY.Get() != 0 {
-		uart.Receive(byte(nrf.UART0.RXD.Get()))
-		nrf.UART0.EVENTS_RXDRDY.Set(0x0)
+	if uart.Bus.EVENTS_RXDRDY.Get() != 0 {
+		uart.Receive(byte(uart.Bus.RXD.Get()))
+		uart.Bus.EVENTS_RXDRDY.Set(0x0)
 	}
 }
 
@@ -248,11 +288,7 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 		(nrf.GPIO_PIN_CNF_DRIVE_S0D1 << nrf.GPIO_PIN_CNF_DRIVE_Pos) |
 		(nrf.GPIO_PIN_CNF_SENSE_Disabled << nrf.GPIO_PIN_CNF_SENSE_Pos))
 
-	if config.Frequency >= 400*KHz {
-		i2c.Bus.FREQUENCY.Set(nrf.TWI_FREQUENCY_FREQUENCY_K400)
-	} else {
-		i2c.Bus.FREQUENCY.Set(nrf.TWI_FREQUENCY_FREQUENCY_K100)
-	}
+	i2c.SetBaudRate(config.Frequency)
 
 	i2c.setPins(config.SCL, config.SDA)
 
@@ -261,6 +297,18 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 	return nil
 }
 
+// SetBaudRate sets the I2C bus speed, without changing the rest of the
+// configuration. The hardware supports 100kHz and 400kHz, speeds below 400kHz
+// use 100kHz.
+func (i2c *I2C) SetBaudRate(br uint32) error {
+	if br >= 400*KHz {
+		i2c.Bus.FREQUENCY.Set(nrf.TWI_FREQUENCY_FREQUENCY_K400)
+	} else {
+		i2c.Bus.FREQUENCY.Set(nrf.TWI_FREQUENCY_FREQUENCY_K100)
+	}
+	return nil
+}
+
 // Tx does a single I2C transaction at the specified address.
 // It clocks out the given address, writes the bytes in w, reads back len(r)
 // bytes and stores them in r, and generates a stop condition on the bus.
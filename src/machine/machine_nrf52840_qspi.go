@@ -0,0 +1,322 @@
+//go:build nrf52840
+// +build nrf52840
+
+package machine
+
+import (
+	"device/nrf"
+	"errors"
+	"unsafe"
+)
+
+// Standard opcodes understood by nearly all QSPI NOR flash chips.
+const (
+	qspiCmdReadStatus = 0x05
+	qspiCmdReadJEDEC  = 0x9F
+)
+
+const (
+	// QSPIPageSize is the maximum number of bytes that can be written in a
+	// single ProgramPage call. Page programs must not cross a page boundary.
+	QSPIPageSize = 256
+
+	// QSPISectorSize is the size of the (smallest) erase unit.
+	QSPISectorSize = 4096
+
+	// qspiXIPAddress is the start of the region where the external flash is
+	// mapped into the address space when the QSPI peripheral is enabled.
+	qspiXIPAddress = 0x12000000
+)
+
+var (
+	errQSPICommandLength = errors.New("machine: QSPI custom instruction can transfer at most 8 bytes")
+	errQSPIPageBoundary  = errors.New("machine: QSPI page program crosses a page boundary")
+	errQSPISectorAlign   = errors.New("machine: QSPI erase address is not sector aligned")
+)
+
+// QSPIConfig is the configuration for the QSPI peripheral.
+type QSPIConfig struct {
+	SCK   Pin
+	CS    Pin
+	DATA0 Pin
+	DATA1 Pin
+
+	// DATA2 and DATA3 are only used in quad mode. Set both to NoPin to use
+	// dual output reads and single line page programs instead.
+	// Note that most flash chips need the QE bit in the status register set
+	// before they accept quad commands, which can be done with Command.
+	DATA2 Pin
+	DATA3 Pin
+
+	// Frequency of the clock signal. It is rounded down to one of the
+	// supported frequencies between 2MHz and 32MHz, and defaults to 8MHz.
+	Frequency uint32
+}
+
+// QSPI is the quad SPI peripheral of the nRF52840, used to talk to external
+// NOR flash. Besides issuing commands, the flash can be read directly from
+// memory (execute in place) at the address returned by XIPAddress.
+type QSPI struct {
+	Bus *nrf.QSPI_Type
+
+	// Bounce buffer for EasyDMA, which can only access word aligned RAM.
+	buf [QSPIPageSize / 4]uint32
+}
+
+// QSPI0 is the only QSPI peripheral on the nRF52840.
+var QSPI0 = &QSPI{Bus: nrf.QSPI}
+
+// Configure sets the pins and clock speed and activates the QSPI peripheral.
+func (q *QSPI) Configure(config QSPIConfig) error {
+	q.Bus.PSEL.SCK.Set(qspiPinSelect(config.SCK))
+	q.Bus.PSEL.CSN.Set(qspiPinSelect(config.CS))
+	q.Bus.PSEL.IO0.Set(qspiPinSelect(config.DATA0))
+	q.Bus.PSEL.IO1.Set(qspiPinSelect(config.DATA1))
+	q.Bus.PSEL.IO2.Set(qspiPinSelect(config.DATA2))
+	q.Bus.PSEL.IO3.Set(qspiPinSelect(config.DATA3))
+
+	ifconfig0, ifconfig1 := config.interfaceConfig()
+	q.Bus.IFCONFIG0.Set(ifconfig0)
+	q.Bus.IFCONFIG1.Set(ifconfig1)
+
+	q.Bus.ENABLE.Set(nrf.QSPI_ENABLE_ENABLE_Enabled << nrf.QSPI_ENABLE_ENABLE_Pos)
+	q.Bus.EVENTS_READY.Set(0)
+	q.Bus.TASKS_ACTIVATE.Set(1)
+	q.wait()
+	return nil
+}
+
+// interfaceConfig returns the values of the IFCONFIG0 and IFCONFIG1 registers
+// for this configuration.
+func (config QSPIConfig) interfaceConfig() (ifconfig0, ifconfig1 uint32) {
+	readOC := uint32(nrf.QSPI_IFCONFIG0_READOC_READ2O)
+	writeOC := uint32(nrf.QSPI_IFCONFIG0_WRITEOC_PP)
+	if config.DATA2 != NoPin && config.DATA3 != NoPin {
+		readOC = nrf.QSPI_IFCONFIG0_READOC_READ4IO
+		writeOC = nrf.QSPI_IFCONFIG0_WRITEOC_PP4O
+	}
+	ifconfig0 = readOC<<nrf.QSPI_IFCONFIG0_READOC_Pos |
+		writeOC<<nrf.QSPI_IFCONFIG0_WRITEOC_Pos |
+		nrf.QSPI_IFCONFIG0_ADDRMODE_24BIT<<nrf.QSPI_IFCONFIG0_ADDRMODE_Pos |
+		nrf.QSPI_IFCONFIG0_PPSIZE_256Bytes<<nrf.QSPI_IFCONFIG0_PPSIZE_Pos
+
+	// The clock is 32MHz / (SCKFREQ + 1), with SCKFREQ in the range 0..15.
+	frequency := config.Frequency
+	if frequency == 0 {
+		frequency = 8000000
+	}
+	div := (32000000 + frequency - 1) / frequency
+	if div > 16 {
+		div = 16
+	} else if div < 1 {
+		div = 1
+	}
+	ifconfig1 = 1<<nrf.QSPI_IFCONFIG1_SCKDELAY_Pos |
+		nrf.QSPI_IFCONFIG1_SPIMODE_MODE0<<nrf.QSPI_IFCONFIG1_SPIMODE_Pos |
+		(div-1)<<nrf.QSPI_IFCONFIG1_SCKFREQ_Pos
+	return ifconfig0, ifconfig1
+}
+
+// qspiPinSelect returns the PSEL value for the given pin, disconnecting the
+// signal for NoPin.
+func qspiPinSelect(pin Pin) uint32 {
+	if pin == NoPin {
+		return 0xffffffff
+	}
+	return uint32(pin)
+}
+
+// wait blocks until the READY event fires, which signals the end of the
+// currently running operation, and clears it.
+func (q *QSPI) wait() {
+	for q.Bus.EVENTS_READY.Get() == 0 {
+	}
+	q.Bus.EVENTS_READY.Set(0)
+}
+
+// Command sends a custom instruction to the flash: the opcode followed by the
+// bytes in tx, after which len(rx) bytes are read back into rx. At most 8
+// bytes can be transferred in total, so tx and rx share the same data
+// registers: the first len(tx) bytes read back are the bytes that were sent.
+// It can be used for commands that are not covered by the other methods, like
+// writing the status register.
+func (q *QSPI) Command(opcode byte, tx, rx []byte) error {
+	n := len(tx)
+	if len(rx) > n {
+		n = len(rx)
+	}
+	if n > 8 {
+		return errQSPICommandLength
+	}
+
+	dat0, dat1 := qspiCommandData(tx)
+	q.Bus.CINSTRDAT0.Set(dat0)
+	q.Bus.CINSTRDAT1.Set(dat1)
+
+	// Writing CINSTRCONF starts the transfer.
+	q.Bus.EVENTS_READY.Set(0)
+	q.Bus.CINSTRCONF.Set(qspiCommandConfig(opcode, n))
+	q.wait()
+
+	if len(rx) != 0 {
+		qspiCommandResult(q.Bus.CINSTRDAT0.Get(), q.Bus.CINSTRDAT1.Get(), rx)
+	}
+	return nil
+}
+
+// qspiCommandConfig returns the CINSTRCONF value that sends a custom
+// instruction with n data bytes. The length includes the opcode. IO2 and IO3
+// are kept high, so that they don't act as active low write protect or hold
+// signals in single line mode.
+func qspiCommandConfig(opcode byte, n int) uint32 {
+	return uint32(opcode)<<nrf.QSPI_CINSTRCONF_OPCODE_Pos |
+		uint32(1+n)<<nrf.QSPI_CINSTRCONF_LENGTH_Pos |
+		nrf.QSPI_CINSTRCONF_LIO2 | nrf.QSPI_CINSTRCONF_LIO3
+}
+
+// qspiCommandData returns the CINSTRDAT0 and CINSTRDAT1 values that hold the
+// (at most 8) bytes to send with a custom instruction.
+func qspiCommandData(tx []byte) (dat0, dat1 uint32) {
+	var data [8]byte
+	copy(data[:], tx)
+	dat0 = uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+	dat1 = uint32(data[4]) | uint32(data[5])<<8 | uint32(data[6])<<16 | uint32(data[7])<<24
+	return dat0, dat1
+}
+
+// qspiCommandResult copies the bytes received with a custom instruction from
+// the CINSTRDAT0 and CINSTRDAT1 values into rx.
+func qspiCommandResult(dat0, dat1 uint32, rx []byte) {
+	for i := range rx {
+		if i < 4 {
+			rx[i] = byte(dat0 >> (8 * i))
+		} else {
+			rx[i] = byte(dat1 >> (8 * (i - 4)))
+		}
+	}
+}
+
+// ReadJEDEC returns the JEDEC ID of the flash chip: the manufacturer ID
+// followed by the two byte device ID.
+func (q *QSPI) ReadJEDEC() ([3]byte, error) {
+	var id [3]byte
+	err := q.Command(qspiCmdReadJEDEC, nil, id[:])
+	return id, err
+}
+
+// waitWhileBusy polls the status register of the flash until the write in
+// progress bit is cleared.
+func (q *QSPI) waitWhileBusy() error {
+	var status [1]byte
+	for {
+		err := q.Command(qspiCmdReadStatus, nil, status[:])
+		if err != nil {
+			return err
+		}
+		if status[0]&1 == 0 {
+			return nil
+		}
+	}
+}
+
+// EraseSector erases the 4kB sector starting at addr, which must be a
+// multiple of QSPISectorSize. It returns once the erase has completed.
+func (q *QSPI) EraseSector(addr uint32) error {
+	if addr%QSPISectorSize != 0 {
+		return errQSPISectorAlign
+	}
+	q.Bus.ERASE.PTR.Set(addr)
+	q.Bus.ERASE.LEN.Set(nrf.QSPI_ERASE_LEN_LEN_4KB << nrf.QSPI_ERASE_LEN_LEN_Pos)
+	q.Bus.EVENTS_READY.Set(0)
+	q.Bus.TASKS_ERASESTART.Set(1)
+	q.wait()
+	return q.waitWhileBusy()
+}
+
+// ProgramPage writes data to the (erased) flash starting at addr. The data
+// must fit in the QSPIPageSize page that contains addr. It returns once the
+// data has been written.
+func (q *QSPI) ProgramPage(addr uint32, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if addr%QSPIPageSize+uint32(len(data)) > QSPIPageSize {
+		return errQSPIPageBoundary
+	}
+
+	start, buf := q.bounce(addr, data)
+	q.Bus.WRITE.DST.Set(start)
+	q.Bus.WRITE.SRC.Set(uint32(uintptr(unsafe.Pointer(&buf[0]))))
+	q.Bus.WRITE.CNT.Set(uint32(len(buf)))
+	q.Bus.EVENTS_READY.Set(0)
+	q.Bus.TASKS_WRITESTART.Set(1)
+	q.wait()
+	return q.waitWhileBusy()
+}
+
+// bounce copies data into the bounce buffer for a page program at addr.
+// EasyDMA needs a word aligned RAM buffer and a length that is a multiple of
+// four, and data may well be stored in flash. The data is padded with 0xff
+// bytes, which leave the flash contents as-is. It returns the (word aligned)
+// flash address and the part of the bounce buffer to write there.
+func (q *QSPI) bounce(addr uint32, data []byte) (start uint32, buf []byte) {
+	start = addr &^ 3
+	end := (addr + uint32(len(data)) + 3) &^ 3
+	buf = q.bytes()[:end-start]
+	for i := range buf {
+		buf[i] = 0xff
+	}
+	copy(buf[addr-start:], data)
+	return start, buf
+}
+
+// Read reads len(buf) bytes from the flash starting at addr.
+func (q *QSPI) Read(addr uint32, buf []byte) error {
+	for len(buf) != 0 {
+		if addr%4 == 0 && len(buf) >= 4 && uintptr(unsafe.Pointer(&buf[0]))%4 == 0 {
+			// Read directly into buf.
+			// The CNT register is 18 bits wide.
+			n := uint32(len(buf)) &^ 3
+			if n > 0x3fffc {
+				n = 0x3fffc
+			}
+			q.read(addr, unsafe.Pointer(&buf[0]), n)
+			addr += n
+			buf = buf[n:]
+			continue
+		}
+
+		// Read through the bounce buffer.
+		start := addr &^ 3
+		bounce := q.bytes()
+		q.read(start, unsafe.Pointer(&bounce[0]), uint32(len(bounce)))
+		n := copy(buf, bounce[addr-start:])
+		addr += uint32(n)
+		buf = buf[n:]
+	}
+	return nil
+}
+
+// read starts an EasyDMA transfer of n bytes from the flash at addr into dst,
+// and waits for it to finish. All parameters must be word aligned.
+func (q *QSPI) read(addr uint32, dst unsafe.Pointer, n uint32) {
+	q.Bus.READ.SRC.Set(addr)
+	q.Bus.READ.DST.Set(uint32(uintptr(dst)))
+	q.Bus.READ.CNT.Set(n)
+	q.Bus.EVENTS_READY.Set(0)
+	q.Bus.TASKS_READSTART.Set(1)
+	q.wait()
+}
+
+// bytes returns the bounce buffer as a byte slice.
+func (q *QSPI) bytes() []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&q.buf[0])), len(q.buf)*4)
+}
+
+// XIPAddress returns the address where the external flash is mapped into
+// memory, so that it can be read (or code executed from it) directly. This
+// only works while the peripheral is enabled and no other operation is in
+// progress.
+func (q *QSPI) XIPAddress() uintptr {
+	return qspiXIPAddress
+}
@@ -0,0 +1,93 @@
+//go:build nrf52840
+// +build nrf52840
+
+package machine
+
+import (
+	"device/nrf"
+	"testing"
+)
+
+// These tests only use a QSPI peripheral in RAM and don't talk to a flash
+// chip. They are compiled by the smoketest (-target=pca10056) but not run.
+
+func TestQSPIInterfaceConfig(t *testing.T) {
+	for _, tc := range []struct {
+		config               QSPIConfig
+		ifconfig0, ifconfig1 uint32
+	}{
+		// Dual output reads and single line page programs, at the default
+		// 8MHz (SCKFREQ=3).
+		{QSPIConfig{DATA2: NoPin, DATA3: NoPin}, 1, 1 | 3<<28},
+		// Quad reads and page programs at 32MHz.
+		{QSPIConfig{DATA2: 22, DATA3: 23, Frequency: 32e6}, 4 | 2<<3, 1},
+		// Frequencies are rounded down, to at least 2MHz.
+		{QSPIConfig{DATA2: NoPin, DATA3: NoPin, Frequency: 10e6}, 1, 1 | 3<<28},
+		{QSPIConfig{DATA2: NoPin, DATA3: NoPin, Frequency: 1e6}, 1, 1 | 15<<28},
+	} {
+		ifconfig0, ifconfig1 := tc.config.interfaceConfig()
+		if ifconfig0 != tc.ifconfig0 || ifconfig1 != tc.ifconfig1 {
+			t.Errorf("%+v: IFCONFIG0 = %#x, IFCONFIG1 = %#x; want %#x, %#x", tc.config, ifconfig0, ifconfig1, tc.ifconfig0, tc.ifconfig1)
+		}
+	}
+	if sel := qspiPinSelect(NoPin); sel != 0xffffffff {
+		t.Errorf("PSEL for NoPin = %#x, want disconnected", sel)
+	}
+	if sel := qspiPinSelect(Pin(32)); sel != 32 {
+		t.Errorf("PSEL for P1.00 = %d, want 32", sel)
+	}
+}
+
+func TestQSPICommand(t *testing.T) {
+	// The JEDEC ID command reads three bytes.
+	if got, want := qspiCommandConfig(0x9f, 3), uint32(0x9f|4<<8|1<<12|1<<13); got != want {
+		t.Errorf("CINSTRCONF = %#x, want %#x", got, want)
+	}
+
+	// The first four bytes go in CINSTRDAT0 in little endian order, and are
+	// read back the same way.
+	dat0, dat1 := qspiCommandData([]byte{1, 2, 3, 4, 5})
+	if dat0 != 0x04030201 || dat1 != 5 {
+		t.Errorf("CINSTRDAT = %#08x %#08x", dat0, dat1)
+	}
+	rx := make([]byte, 6)
+	qspiCommandResult(0x44332211, 0x6655, rx)
+	if string(rx) != "\x11\x22\x33\x44\x55\x66" {
+		t.Errorf("received %#x", rx)
+	}
+
+	// Invalid commands don't touch the peripheral.
+	q := &QSPI{Bus: new(nrf.QSPI_Type)}
+	if err := q.Command(0x31, make([]byte, 9), nil); err != errQSPICommandLength {
+		t.Errorf("sent 9 data bytes: %v", err)
+	}
+	if q.Bus.CINSTRCONF.Get() != 0 {
+		t.Errorf("CINSTRCONF = %#x after an invalid command", q.Bus.CINSTRCONF.Get())
+	}
+}
+
+func TestQSPIProgramPage(t *testing.T) {
+	q := &QSPI{Bus: new(nrf.QSPI_Type)}
+
+	// An unaligned write goes through the bounce buffer, padded with 0xff.
+	data := []byte("hello, flash")
+	addr := uint32(QSPISectorSize + 0x102)
+	start, buf := q.bounce(addr, data)
+	if start != addr-2 || len(buf) != 16 {
+		t.Errorf("bounce buffer at %#x with %d bytes, want %#x with 16", start, len(buf), addr-2)
+	}
+	if want := "\xff\xff" + string(data) + "\xff\xff"; string(buf) != want {
+		t.Errorf("bounce buffer is %q, want %q", buf, want)
+	}
+
+	// Invalid page programs and erases don't touch the peripheral.
+	if err := q.ProgramPage(QSPISectorSize+0xf0, make([]byte, 32)); err != errQSPIPageBoundary {
+		t.Errorf("wrote across a page boundary: %v", err)
+	}
+	if err := q.EraseSector(100); err != errQSPISectorAlign {
+		t.Errorf("erased an unaligned sector: %v", err)
+	}
+	if q.Bus.WRITE.CNT.Get() != 0 || q.Bus.ERASE.PTR.Get() != 0 {
+		t.Error("registers were written for an invalid operation")
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"unsafe"
 )
 
+// Size of a flash page, which is the smallest area of flash that can be erased.
+const flashEraseBlockSize = 4096
+
 func CPUFrequency() uint32 {
 	return 64000000
 }
@@ -146,25 +149,7 @@ func (spi SPI) Configure(config SPIConfig) {
 		config.Frequency = 4000000 // 4MHz
 	}
 
-	// set frequency
-	var freq uint32
-	switch {
-	case config.Frequency >= 8000000:
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M8
-	case config.Frequency >= 4000000:
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M4
-	case config.Frequency >= 2000000:
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M2
-	case config.Frequency >= 1000000:
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M1
-	case config.Frequency >= 500000:
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_K500
-	case config.Frequency >= 250000:
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_K250
-	default: // below 250kHz, default to the lowest speed available
-		freq = nrf.SPIM_FREQUENCY_FREQUENCY_K125
-	}
-	spi.Bus.FREQUENCY.Set(freq)
+	spi.SetBaudRate(config.Frequency)
 
 	var conf uint32
 
@@ -207,6 +192,53 @@ func (spi SPI) Configure(config SPIConfig) {
 	spi.Bus.ENABLE.Set(nrf.SPIM_ENABLE_ENABLE_Enabled)
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured. The
+// peripheral supports a fixed set of frequencies from 125kHz to 8MHz, and
+// Configure picks the fastest one that is not faster than requested.
+func (spi SPI) ActualFrequency() uint32 {
+	switch spi.Bus.FREQUENCY.Get() {
+	case nrf.SPIM_FREQUENCY_FREQUENCY_M8:
+		return 8000000
+	case nrf.SPIM_FREQUENCY_FREQUENCY_M4:
+		return 4000000
+	case nrf.SPIM_FREQUENCY_FREQUENCY_M2:
+		return 2000000
+	case nrf.SPIM_FREQUENCY_FREQUENCY_M1:
+		return 1000000
+	case nrf.SPIM_FREQUENCY_FREQUENCY_K500:
+		return 500000
+	case nrf.SPIM_FREQUENCY_FREQUENCY_K250:
+		return 250000
+	default:
+		return 125000
+	}
+}
+
+// SetBaudRate sets the SPI clock frequency, without changing the rest of the
+// configuration. The frequency is rounded down to one supported by the
+// hardware, with 125kHz as the lowest.
+func (spi SPI) SetBaudRate(br uint32) error {
+	var freq uint32
+	switch {
+	case br >= 8000000:
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M8
+	case br >= 4000000:
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M4
+	case br >= 2000000:
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M2
+	case br >= 1000000:
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_M1
+	case br >= 500000:
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_K500
+	case br >= 250000:
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_K250
+	default: // below 250kHz, default to the lowest speed available
+		freq = nrf.SPIM_FREQUENCY_FREQUENCY_K125
+	}
+	spi.Bus.FREQUENCY.Set(freq)
+	return nil
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi SPI) Transfer(w byte) (byte, error) {
 	buf := spi.buf[:]
@@ -0,0 +1,124 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"runtime/interrupt"
+)
+
+// comparatorInput returns the analog input number (AIN0-AIN7) of the given
+// pin, which is used for both the input and the reference selection of the
+// COMP peripheral.
+func comparatorInput(p Pin) (uint32, bool) {
+	switch p {
+	case 2, 3, 4, 5:
+		return uint32(p - 2), true
+	case 28, 29, 30, 31:
+		return uint32(p-28) + 4, true
+	default:
+		return 0, false
+	}
+}
+
+// Configure sets up the COMP peripheral in single-ended mode and starts it.
+// The threshold has a resolution of 1/64 of the reference voltage.
+func (c *Comparator) Configure(config ComparatorConfig) error {
+	err := configureCOMP(nrf.COMP, config)
+	if err != nil {
+		return err
+	}
+	nrf.COMP.EVENTS_READY.Set(0)
+	nrf.COMP.TASKS_START.Set(1)
+	for nrf.COMP.EVENTS_READY.Get() == 0 {
+	}
+	nrf.COMP.EVENTS_UP.Set(0)
+	nrf.COMP.EVENTS_DOWN.Set(0)
+	return nil
+}
+
+// configureCOMP stops the given COMP peripheral, configures it and enables it
+// again. It doesn't start the comparator.
+func configureCOMP(comp *nrf.COMP_Type, config ComparatorConfig) error {
+	psel, ok := comparatorInput(config.PositiveInput)
+	if !ok {
+		return ErrInvalidComparatorInput
+	}
+	refsel := uint32(nrf.COMP_REFSEL_REFSEL_VDD)
+	var extrefsel uint32
+	if config.NegativeInput != NoPin {
+		extrefsel, ok = comparatorInput(config.NegativeInput)
+		if !ok {
+			return ErrInvalidComparatorInput
+		}
+		refsel = nrf.COMP_REFSEL_REFSEL_ARef
+	}
+
+	// The peripheral must be stopped and disabled while it is configured.
+	comp.TASKS_STOP.Set(1)
+	comp.ENABLE.Set(nrf.COMP_ENABLE_ENABLE_Disabled << nrf.COMP_ENABLE_ENABLE_Pos)
+
+	comp.PSEL.Set(psel)
+	comp.REFSEL.Set(refsel)
+	comp.EXTREFSEL.Set(extrefsel)
+	comp.MODE.Set(nrf.COMP_MODE_SP_Normal<<nrf.COMP_MODE_SP_Pos |
+		nrf.COMP_MODE_MAIN_SE<<nrf.COMP_MODE_MAIN_Pos)
+
+	// The threshold is VREF * (TH+1) / 64. Use the same threshold for both
+	// directions, so there is no hysteresis.
+	th := uint32(config.threshold() >> 10)
+	if th > 0 {
+		th--
+	}
+	comp.TH.Set(th<<nrf.COMP_TH_THUP_Pos | th<<nrf.COMP_TH_THDOWN_Pos)
+
+	comp.ENABLE.Set(nrf.COMP_ENABLE_ENABLE_Enabled << nrf.COMP_ENABLE_ENABLE_Pos)
+	return nil
+}
+
+// Get returns whether the positive input is currently above the threshold.
+func (c *Comparator) Get() bool {
+	nrf.COMP.TASKS_SAMPLE.Set(1)
+	return nrf.COMP.RESULT.Get() == nrf.COMP_RESULT_RESULT_Above
+}
+
+// SetInterrupt sets an interrupt to be executed when the comparator output
+// changes. The callback is called with the new state of the output. Use a nil
+// callback to disable the interrupt.
+//
+// This call will replace a previously set callback. The callback is executed
+// in interrupt context, so keep it short.
+func (c *Comparator) SetInterrupt(change ComparatorChange, callback func(above bool)) error {
+	nrf.COMP.INTENCLR.Set(nrf.COMP_INTENCLR_UP | nrf.COMP_INTENCLR_DOWN)
+	c.change = change
+	c.callback = callback
+	if callback == nil {
+		return nil
+	}
+
+	var inten uint32
+	if change&ComparatorRising != 0 {
+		inten |= nrf.COMP_INTENSET_UP
+	}
+	if change&ComparatorFalling != 0 {
+		inten |= nrf.COMP_INTENSET_DOWN
+	}
+	nrf.COMP.EVENTS_UP.Set(0)
+	nrf.COMP.EVENTS_DOWN.Set(0)
+	nrf.COMP.INTENSET.Set(inten)
+
+	// The COMP and LPCOMP peripherals share an interrupt. It's not a problem if
+	// this happens more than once.
+	interrupt.New(nrf.IRQ_COMP_LPCOMP, func(interrupt.Interrupt) {
+		if nrf.COMP.EVENTS_UP.Get() != 0 {
+			nrf.COMP.EVENTS_UP.Set(0)
+			Comparator0.handleInterrupt(true)
+		}
+		if nrf.COMP.EVENTS_DOWN.Get() != 0 {
+			nrf.COMP.EVENTS_DOWN.Set(0)
+			Comparator0.handleInterrupt(false)
+		}
+	}).Enable()
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"testing"
+)
+
+// This test only checks the registers written to a fake COMP peripheral, and is
+// only compiled by the smoketest. The comparator API is run on the host by
+// testdata/comparator.go, through the generic machine package.
+
+func TestConfigureCOMP(t *testing.T) {
+	for _, tc := range []struct {
+		config    ComparatorConfig
+		psel      uint32
+		refsel    uint32
+		extrefsel uint32
+		th        uint32
+	}{
+		// Half the supply voltage by default: VDD * 32 / 64.
+		{ComparatorConfig{PositiveInput: 2, NegativeInput: NoPin}, 0, nrf.COMP_REFSEL_REFSEL_VDD, 0, 31<<8 | 31},
+		// AIN2 against AIN5, switching at the full reference voltage.
+		{ComparatorConfig{PositiveInput: 4, NegativeInput: 29, Threshold: 0xffff}, 2, nrf.COMP_REFSEL_REFSEL_ARef, 5, 62<<8 | 62},
+		// Thresholds below 1/64 of the reference use the lowest setting.
+		{ComparatorConfig{PositiveInput: 31, NegativeInput: NoPin, Threshold: 0x0100}, 7, nrf.COMP_REFSEL_REFSEL_VDD, 0, 0},
+	} {
+		comp := new(nrf.COMP_Type)
+		if err := configureCOMP(comp, tc.config); err != nil {
+			t.Errorf("%+v: %v", tc.config, err)
+			continue
+		}
+		for _, reg := range []struct {
+			name  string
+			got   uint32
+			value uint32
+		}{
+			{"PSEL", comp.PSEL.Get(), tc.psel},
+			{"REFSEL", comp.REFSEL.Get(), tc.refsel},
+			{"EXTREFSEL", comp.EXTREFSEL.Get(), tc.extrefsel},
+			{"MODE", comp.MODE.Get(), 1}, // single-ended, normal speed
+			{"TH", comp.TH.Get(), tc.th},
+			{"ENABLE", comp.ENABLE.Get(), 2},
+		} {
+			if reg.got != reg.value {
+				t.Errorf("%+v: %s = %#x, want %#x", tc.config, reg.name, reg.got, reg.value)
+			}
+		}
+		if comp.TASKS_START.Get() != 0 {
+			t.Errorf("%+v: comparator started", tc.config)
+		}
+	}
+
+	// Pins without an analog input are rejected before touching the
+	// peripheral.
+	for _, config := range []ComparatorConfig{
+		{PositiveInput: 6, NegativeInput: NoPin},
+		{PositiveInput: 2, NegativeInput: 27},
+		{PositiveInput: NoPin, NegativeInput: NoPin},
+	} {
+		comp := new(nrf.COMP_Type)
+		if err := configureCOMP(comp, config); err != ErrInvalidComparatorInput {
+			t.Errorf("%+v: got %v, want %v", config, err, ErrInvalidComparatorInput)
+		}
+		if comp.TASKS_STOP.Get() != 0 || comp.PSEL.Get() != 0 {
+			t.Errorf("%+v: registers written for an invalid input", config)
+		}
+	}
+}
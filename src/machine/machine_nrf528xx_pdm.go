@@ -0,0 +1,148 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"unsafe"
+)
+
+// pdmMaxSamples is the largest number of samples in a single transfer: the
+// sample count register is 15 bits wide, and in stereo mode the count must stay
+// even.
+const pdmMaxSamples = 0x7ffe
+
+// PDMSampleRate is the number of PCM samples per second (per channel) produced
+// by the PDM peripheral: the default PDM clock of 1.032MHz decimated by 64.
+const PDMSampleRate = 16125
+
+// PDMConfig is the configuration for a PDM microphone.
+type PDMConfig struct {
+	CLK Pin
+	DIN Pin
+
+	// Stereo reads two microphones sharing the same CLK and DIN lines. The
+	// samples are interleaved: the left channel (sampled on the falling edge
+	// of the clock) followed by the right channel.
+	Stereo bool
+
+	// Gain in steps of 0.5dB, from -40 (-20dB) to 40 (+20dB). The default of 0
+	// is the gain recommended for most microphones.
+	Gain int8
+}
+
+// PDM is the pulse density modulation peripheral, which converts the signal
+// of a digital microphone to 16-bit PCM samples.
+type PDM struct {
+	Bus    *nrf.PDM_Type
+	stereo bool
+
+	// The peripheral keeps sampling into this buffer while it is being
+	// stopped, so that the buffer passed to Read isn't overwritten.
+	scratch [2]int16
+}
+
+// PDM0 is the PDM peripheral of the nRF52.
+var PDM0 = &PDM{Bus: nrf.PDM}
+
+// Configure sets up the pins, gain and mode of the PDM peripheral and enables
+// it. It doesn't start sampling yet, which is done by Read.
+func (pdm *PDM) Configure(config PDMConfig) error {
+	// The clock pin is driven low while the peripheral isn't running.
+	config.CLK.Configure(PinConfig{Mode: PinOutput})
+	config.CLK.Low()
+	config.DIN.Configure(PinConfig{Mode: PinInput})
+	pdm.configure(config)
+	return nil
+}
+
+// configure sets the pins, gain and mode of the PDM peripheral and enables it.
+func (pdm *PDM) configure(config PDMConfig) {
+	pdm.Bus.PSEL.CLK.Set(uint32(config.CLK))
+	pdm.Bus.PSEL.DIN.Set(uint32(config.DIN))
+
+	pdm.Bus.PDMCLKCTRL.Set(nrf.PDM_PDMCLKCTRL_FREQ_Default)
+	mode := uint32(nrf.PDM_MODE_OPERATION_Mono)
+	if config.Stereo {
+		mode = nrf.PDM_MODE_OPERATION_Stereo
+	}
+	pdm.Bus.MODE.Set(mode<<nrf.PDM_MODE_OPERATION_Pos |
+		nrf.PDM_MODE_EDGE_LeftFalling<<nrf.PDM_MODE_EDGE_Pos)
+	pdm.stereo = config.Stereo
+
+	// The gain registers are in 0.5dB steps, with 0x28 being 0dB.
+	gain := int32(nrf.PDM_GAINL_GAINL_DefaultGain) + int32(config.Gain)
+	if gain < nrf.PDM_GAINL_GAINL_MinGain {
+		gain = nrf.PDM_GAINL_GAINL_MinGain
+	} else if gain > nrf.PDM_GAINL_GAINL_MaxGain {
+		gain = nrf.PDM_GAINL_GAINL_MaxGain
+	}
+	pdm.Bus.GAINL.Set(uint32(gain))
+	pdm.Bus.GAINR.Set(uint32(gain))
+
+	pdm.Bus.ENABLE.Set(nrf.PDM_ENABLE_ENABLE_Enabled << nrf.PDM_ENABLE_ENABLE_Pos)
+}
+
+// Read fills buf with PCM samples, blocking until it is full. In stereo mode
+// the samples of the two channels are interleaved, so buf should have an even
+// length. The microphone only runs while Read is active, and it takes a few
+// milliseconds after starting before its output is stable, so read large
+// buffers (or discard the first samples) for continuous audio.
+func (pdm *PDM) Read(buf []int16) (int, error) {
+	if pdm.stereo {
+		buf = buf[:len(buf)&^1]
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+
+	// The peripheral latches the buffer pointer and size on the STARTED
+	// event, and restarts with the latched buffer as soon as the current one
+	// is full (the END event). So after every STARTED event the next buffer
+	// is set up, which is the scratch buffer after the last chunk of buf: it
+	// takes the samples that arrive until the peripheral has stopped.
+	chunk := pdm.nextChunk(buf)
+	pdm.Bus.EVENTS_STARTED.Set(0)
+	pdm.Bus.EVENTS_END.Set(0)
+	pdm.Bus.EVENTS_STOPPED.Set(0)
+	pdm.setBuffer(chunk)
+	pdm.Bus.TASKS_START.Set(1)
+	n := 0
+	for {
+		for pdm.Bus.EVENTS_STARTED.Get() == 0 {
+		}
+		pdm.Bus.EVENTS_STARTED.Set(0)
+		n += len(chunk)
+		chunk = pdm.nextChunk(buf[n:])
+		pdm.setBuffer(chunk)
+
+		for pdm.Bus.EVENTS_END.Get() == 0 {
+		}
+		pdm.Bus.EVENTS_END.Set(0)
+		if n == len(buf) {
+			break
+		}
+	}
+	pdm.Bus.TASKS_STOP.Set(1)
+	for pdm.Bus.EVENTS_STOPPED.Get() == 0 {
+	}
+	return n, nil
+}
+
+// nextChunk returns the part of buf that fits in a single transfer, or the
+// scratch buffer if buf is empty.
+func (pdm *PDM) nextChunk(buf []int16) []int16 {
+	if len(buf) == 0 {
+		return pdm.scratch[:]
+	}
+	if len(buf) > pdmMaxSamples {
+		buf = buf[:pdmMaxSamples]
+	}
+	return buf
+}
+
+func (pdm *PDM) setBuffer(buf []int16) {
+	pdm.Bus.SAMPLE.PTR.Set(uint32(uintptr(unsafe.Pointer(&buf[0]))))
+	pdm.Bus.SAMPLE.MAXCNT.Set(uint32(len(buf)))
+}
@@ -0,0 +1,78 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"testing"
+)
+
+// The PDM peripheral in these tests is a fake that never samples. They are
+// only compiled by the smoketest, as there is no nrf emulator to run them.
+
+func TestPDMConfigure(t *testing.T) {
+	for _, tc := range []struct {
+		config PDMConfig
+		mode   uint32
+		gain   uint32
+	}{
+		{PDMConfig{CLK: 26, DIN: 25}, 1, 0x28},
+		{PDMConfig{CLK: 26, DIN: 25, Stereo: true, Gain: 10}, 0, 0x32},
+		{PDMConfig{CLK: 26, DIN: 25, Gain: 100}, 1, 0x50},
+		{PDMConfig{CLK: 26, DIN: 25, Gain: -100}, 1, 0},
+	} {
+		pdm := &PDM{Bus: new(nrf.PDM_Type)}
+		pdm.configure(tc.config)
+		for _, reg := range []struct {
+			name  string
+			got   uint32
+			value uint32
+		}{
+			{"PSEL.CLK", pdm.Bus.PSEL.CLK.Get(), 26},
+			{"PSEL.DIN", pdm.Bus.PSEL.DIN.Get(), 25},
+			{"PDMCLKCTRL", pdm.Bus.PDMCLKCTRL.Get(), 0x08400000}, // 1.032MHz
+			{"MODE", pdm.Bus.MODE.Get(), tc.mode},
+			{"GAINL", pdm.Bus.GAINL.Get(), tc.gain},
+			{"GAINR", pdm.Bus.GAINR.Get(), tc.gain},
+			{"ENABLE", pdm.Bus.ENABLE.Get(), 1},
+		} {
+			if reg.got != reg.value {
+				t.Errorf("%+v: %s = %#x, want %#x", tc.config, reg.name, reg.got, reg.value)
+			}
+		}
+		if pdm.Bus.TASKS_START.Get() != 0 {
+			t.Errorf("%+v: sampling started by Configure", tc.config)
+		}
+	}
+}
+
+func TestPDMChunks(t *testing.T) {
+	pdm := &PDM{Bus: new(nrf.PDM_Type)}
+
+	// Long reads are split, keeping an even number of samples for stereo.
+	buf := make([]int16, 0x7ffe+10)
+	if chunk := pdm.nextChunk(buf); len(chunk) != 0x7ffe || &chunk[0] != &buf[0] {
+		t.Errorf("first chunk has %d samples, want 0x7ffe", len(chunk))
+	}
+	if chunk := pdm.nextChunk(buf[0x7ffe:]); len(chunk) != 10 {
+		t.Errorf("second chunk has %d samples, want 10", len(chunk))
+	}
+
+	// After the last chunk, the peripheral samples into the scratch buffer
+	// until it has stopped.
+	if chunk := pdm.nextChunk(buf[len(buf):]); &chunk[0] != &pdm.scratch[0] {
+		t.Error("chunk after the end of the buffer is not the scratch buffer")
+	}
+	pdm.setBuffer(pdm.scratch[:])
+	if pdm.Bus.SAMPLE.MAXCNT.Get() != uint32(len(pdm.scratch)) {
+		t.Errorf("SAMPLE.MAXCNT = %d, want %d", pdm.Bus.SAMPLE.MAXCNT.Get(), len(pdm.scratch))
+	}
+
+	// Empty reads, or a single sample in stereo mode, don't start the
+	// peripheral.
+	pdm.configure(PDMConfig{CLK: 26, DIN: 25, Stereo: true})
+	if n, err := pdm.Read(make([]int16, 1)); n != 0 || err != nil || pdm.Bus.TASKS_START.Get() != 0 {
+		t.Errorf("empty read: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,109 @@
+//go:build nrf
+// +build nrf
+
+package machine
+
+import (
+	"device/nrf"
+	"runtime/volatile"
+	"unsafe"
+)
+
+//go:extern _flash_data_start
+var flashDataStartSymbol [0]byte
+
+//go:extern _flash_data_end
+var flashDataEndSymbol [0]byte
+
+// Flash is the internal flash after the program, as a BlockDevice. It can be
+// used to store data that must survive a reset, for example with EEPROM. It
+// starts at the first flash page after the program, so its size depends on
+// the size of the program.
+//
+// Flash must not be written or erased while a SoftDevice is enabled, as the
+// SoftDevice controls the flash then.
+var Flash = newFlashBlockDevice(nrf.NVMC, uintptr(unsafe.Pointer(&flashDataStartSymbol)), uintptr(unsafe.Pointer(&flashDataEndSymbol)))
+
+type flashBlockDevice struct {
+	nvmc  *nrf.NVMC_Type
+	start uintptr
+	end   uintptr
+}
+
+func newFlashBlockDevice(nvmc *nrf.NVMC_Type, start, end uintptr) *flashBlockDevice {
+	// The program usually doesn't end at a page boundary.
+	start = (start + flashEraseBlockSize - 1) &^ (flashEraseBlockSize - 1)
+	if start > end {
+		start = end
+	}
+	return &flashBlockDevice{nvmc: nvmc, start: start, end: end}
+}
+
+// ReadAt reads the given number of bytes from the flash.
+func (f *flashBlockDevice) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(p)) > f.Size() {
+		return 0, errFlashOutOfRange
+	}
+	data := unsafe.Slice((*byte)(unsafe.Pointer(f.start+uintptr(off))), len(p))
+	return copy(p, data), nil
+}
+
+// WriteAt writes the given number of bytes to the flash, which must have been
+// erased before.
+func (f *flashBlockDevice) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(p)) > f.Size() {
+		return 0, errFlashOutOfRange
+	}
+	f.waitWhileBusy()
+	f.nvmc.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Wen)
+	address := f.start + uintptr(off)
+	for n < len(p) {
+		// The flash can only be written a word at a time. Writing ones leaves
+		// a bit unchanged, so the bytes around p are padded with 0xff.
+		word := uint32(0xffffffff)
+		for shift := (address % 4) * 8; shift < 32 && n < len(p); shift += 8 {
+			word &^= uint32(^p[n]) << shift
+			address++
+			n++
+		}
+		(*volatile.Register32)(unsafe.Pointer((address - 1) &^ 3)).Set(word)
+		f.waitWhileBusy()
+	}
+	f.nvmc.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Ren)
+	return n, nil
+}
+
+// Size returns the number of bytes of flash after the program.
+func (f *flashBlockDevice) Size() int64 {
+	return int64(f.end - f.start)
+}
+
+// WriteBlockSize returns 4, as the flash is written a word at a time.
+func (f *flashBlockDevice) WriteBlockSize() int64 {
+	return 4
+}
+
+// EraseBlockSize returns the size of a flash page.
+func (f *flashBlockDevice) EraseBlockSize() int64 {
+	return flashEraseBlockSize
+}
+
+// EraseBlocks erases the given flash pages.
+func (f *flashBlockDevice) EraseBlocks(start, len int64) error {
+	if start < 0 || len < 0 || (start+len)*flashEraseBlockSize > f.Size() {
+		return errFlashOutOfRange
+	}
+	f.waitWhileBusy()
+	f.nvmc.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Een)
+	for block := start; block < start+len; block++ {
+		f.nvmc.ERASEPAGE.Set(uint32(f.start + uintptr(block*flashEraseBlockSize)))
+		f.waitWhileBusy()
+	}
+	f.nvmc.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Ren)
+	return nil
+}
+
+func (f *flashBlockDevice) waitWhileBusy() {
+	for f.nvmc.READY.Get() == nrf.NVMC_READY_READY_Busy {
+	}
+}
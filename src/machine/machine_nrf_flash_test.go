@@ -0,0 +1,68 @@
+//go:build nrf
+// +build nrf
+
+package machine
+
+import (
+	"device/nrf"
+	"testing"
+	"unsafe"
+)
+
+// The flash in this test is a buffer in RAM and the NVMC is a fake that is
+// always ready. It is only compiled by the smoketest, not run. The EEPROM
+// emulation on top of a block device is run by testdata/eeprom.go.
+
+func TestFlashBlockDevice(t *testing.T) {
+	// Use two pages of RAM as flash, starting after some program bytes.
+	mem := make([]byte, 4*flashEraseBlockSize)
+	for i := range mem {
+		mem[i] = 0xff
+	}
+	memStart := uintptr(unsafe.Pointer(&mem[0]))
+	page := (memStart + flashEraseBlockSize - 1) &^ (flashEraseBlockSize - 1)
+	nvmc := new(nrf.NVMC_Type)
+	nvmc.READY.Set(nrf.NVMC_READY_READY_Ready)
+	flash := newFlashBlockDevice(nvmc, page-flashEraseBlockSize+10, page+2*flashEraseBlockSize)
+	if flash.start != page || flash.Size() != 2*flashEraseBlockSize {
+		t.Fatalf("flash starts at %#x with size %d, want %#x with size %d", flash.start, flash.Size(), page, 2*flashEraseBlockSize)
+	}
+	data := mem[page-memStart:]
+
+	// Unaligned writes are padded with 0xff to whole words.
+	if n, err := flash.WriteAt([]byte{1, 2, 3, 4, 5}, 2); n != 5 || err != nil {
+		t.Errorf("WriteAt: %d, %v", n, err)
+	}
+	want := []byte{0xff, 0xff, 1, 2, 3, 4, 5, 0xff, 0xff}
+	if got := data[:len(want)]; string(got) != string(want) {
+		t.Errorf("flash contents are %v, want %v", got, want)
+	}
+	buf := make([]byte, 5)
+	if n, err := flash.ReadAt(buf, 2); n != 5 || err != nil || string(buf) != string(want[2:7]) {
+		t.Errorf("ReadAt: %d, %v, %v", n, err, buf)
+	}
+	if config := nvmc.CONFIG.Get(); config != nrf.NVMC_CONFIG_WEN_Ren {
+		t.Errorf("CONFIG = %d after writing, want read-only", config)
+	}
+
+	if err := flash.EraseBlocks(1, 1); err != nil {
+		t.Errorf("EraseBlocks: %v", err)
+	}
+	if address := nvmc.ERASEPAGE.Get(); address != uint32(page+flashEraseBlockSize) {
+		t.Errorf("erased page at %#x, want %#x", address, page+flashEraseBlockSize)
+	}
+	if config := nvmc.CONFIG.Get(); config != nrf.NVMC_CONFIG_WEN_Ren {
+		t.Errorf("CONFIG = %d after erasing, want read-only", config)
+	}
+
+	// Nothing after the end of the flash is touched.
+	if _, err := flash.WriteAt(make([]byte, 4), flash.Size()-2); err != errFlashOutOfRange {
+		t.Errorf("WriteAt past the end: %v", err)
+	}
+	if err := flash.EraseBlocks(1, 2); err != errFlashOutOfRange {
+		t.Errorf("EraseBlocks past the end: %v", err)
+	}
+	if _, err := flash.ReadAt(buf, -1); err != errFlashOutOfRange {
+		t.Errorf("ReadAt before the start: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build nrf
+// +build nrf
+
+package machine
+
+import (
+	"device/nrf"
+)
+
+func readResetReason() ResetReason {
+	return resetReasonFromPower(nrf.POWER)
+}
+
+// resetReasonFromPower reads the reset cause from the POWER peripheral and
+// clears it.
+func resetReasonFromPower(power *nrf.POWER_Type) ResetReason {
+	reas := power.RESETREAS.Get()
+
+	// The bits are cleared by writing a 1. Bits that aren't cleared accumulate
+	// over multiple resets.
+	power.RESETREAS.Set(reas)
+
+	switch {
+	case reas&nrf.POWER_RESETREAS_DOG != 0:
+		return ResetReasonWatchdog
+	case reas&nrf.POWER_RESETREAS_LOCKUP != 0:
+		return ResetReasonLockup
+	case reas&nrf.POWER_RESETREAS_SREQ != 0:
+		return ResetReasonSoftware
+	case reas&nrf.POWER_RESETREAS_RESETPIN != 0:
+		return ResetReasonExternal
+	case reas&nrf.POWER_RESETREAS_OFF != 0:
+		return ResetReasonWakeup
+	case reas == 0:
+		// None of the reset sources are flagged, which means the chip was
+		// reset by the on-chip reset generator: a power-on or brownout reset.
+		return ResetReasonPowerOn
+	default:
+		return ResetReasonUnknown
+	}
+}
@@ -0,0 +1,117 @@
+//go:build nrf
+// +build nrf
+
+package machine
+
+import (
+	"device/nrf"
+	"testing"
+)
+
+// These tests check the register values written to fake nrf peripherals. They
+// are only compiled by the smoketest: there is no nrf emulator to run them.
+
+func TestUARTSetBaudRate(t *testing.T) {
+	// Register values from the nRF52832 product specification.
+	for _, tc := range []struct {
+		baudRate uint32
+		value    uint32
+	}{
+		{1200, nrf.UART_BAUDRATE_BAUDRATE_Baud1200},
+		{9600, nrf.UART_BAUDRATE_BAUDRATE_Baud9600},
+		{57600, nrf.UART_BAUDRATE_BAUDRATE_Baud57600},
+		{115200, nrf.UART_BAUDRATE_BAUDRATE_Baud115200},
+		{250000, nrf.UART_BAUDRATE_BAUDRATE_Baud250000},
+		{1000000, nrf.UART_BAUDRATE_BAUDRATE_Baud1M},
+	} {
+		uart := &UART{Bus: new(nrf.UART_Type)}
+		uart.SetBaudRate(tc.baudRate)
+		if value := uart.Bus.BAUDRATE.Get(); value != tc.value {
+			t.Errorf("baud rate %d: BAUDRATE = %#08x, want %#08x", tc.baudRate, value, tc.value)
+		}
+	}
+}
+
+func TestUARTSetBaudRateWhileRunning(t *testing.T) {
+	// Changing the baud rate of a running UART must not stop it or change
+	// any other setting.
+	uart := &UART{Bus: new(nrf.UART_Type)}
+	uart.SetBaudRate(115200)
+	uart.Bus.ENABLE.Set(nrf.UART_ENABLE_ENABLE_Enabled)
+	uart.Bus.INTENSET.Set(nrf.UART_INTENSET_RXDRDY_Msk)
+
+	uart.SetBaudRate(9600)
+	if value := uart.Bus.BAUDRATE.Get(); value != nrf.UART_BAUDRATE_BAUDRATE_Baud9600 {
+		t.Errorf("BAUDRATE = %#08x, want %#08x", value, nrf.UART_BAUDRATE_BAUDRATE_Baud9600)
+	}
+	if uart.Bus.ENABLE.Get() != nrf.UART_ENABLE_ENABLE_Enabled || uart.Bus.INTENSET.Get() != nrf.UART_INTENSET_RXDRDY_Msk {
+		t.Error("SetBaudRate changed the configuration of the UART")
+	}
+	if uart.Bus.TASKS_STOPTX.Get() != 0 || uart.Bus.TASKS_STOPRX.Get() != 0 {
+		t.Error("SetBaudRate stopped the UART")
+	}
+}
+
+func TestPinCNF(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config PinConfig
+		value  uint32
+	}{
+		{"input", PinConfig{Mode: PinInput}, uint32(PinInput)},
+		{"input with pull-down", PinConfig{Mode: PinInput, Pull: PinPullDown}, uint32(PinInputPulldown)},
+		{"pull-up removed", PinConfig{Mode: PinInputPullup, Pull: PinPullNone}, uint32(PinInput)},
+		{"pull-up mode", PinConfig{Mode: PinInputPullup}, uint32(PinInputPullup)},
+		{"output high drive", PinConfig{Mode: PinOutput, DriveStrength: PinDriveHigh},
+			uint32(PinOutput) | nrf.GPIO_PIN_CNF_DRIVE_H0H1<<nrf.GPIO_PIN_CNF_DRIVE_Pos},
+		{"output high drive with pull-up", PinConfig{Mode: PinOutput, Pull: PinPullUp, DriveStrength: PinDriveHigh},
+			uint32(PinOutput) | nrf.GPIO_PIN_CNF_PULL_Pullup<<nrf.GPIO_PIN_CNF_PULL_Pos | nrf.GPIO_PIN_CNF_DRIVE_H0H1<<nrf.GPIO_PIN_CNF_DRIVE_Pos},
+		{"output standard drive", PinConfig{Mode: PinOutput, DriveStrength: PinDriveStandard}, uint32(PinOutput)},
+	} {
+		if value := pinCNF(tc.config); value != tc.value {
+			t.Errorf("%s: PIN_CNF = %#x, want %#x", tc.name, value, tc.value)
+		}
+	}
+}
+
+func TestPinConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config PinConfig
+		err    error
+	}{
+		{PinConfig{Pull: PinPullUp, DriveStrength: PinDriveHigh}, nil},
+		{PinConfig{Pull: PinPullDown + 1}, ErrPinPull},
+		{PinConfig{DriveStrength: PinDriveHigh + 1}, ErrPinDriveStrength},
+	} {
+		if err := tc.config.Validate(); err != tc.err {
+			t.Errorf("%+v: Validate() = %v, want %v", tc.config, err, tc.err)
+		}
+	}
+}
+
+func TestResetReason(t *testing.T) {
+	for _, tc := range []struct {
+		reas   uint32
+		reason ResetReason
+	}{
+		{0, ResetReasonPowerOn},
+		{nrf.POWER_RESETREAS_RESETPIN, ResetReasonExternal},
+		{nrf.POWER_RESETREAS_DOG, ResetReasonWatchdog},
+		{nrf.POWER_RESETREAS_DOG | nrf.POWER_RESETREAS_RESETPIN, ResetReasonWatchdog},
+		{nrf.POWER_RESETREAS_SREQ, ResetReasonSoftware},
+		{nrf.POWER_RESETREAS_LOCKUP, ResetReasonLockup},
+		{nrf.POWER_RESETREAS_OFF, ResetReasonWakeup},
+		{1 << 17, ResetReasonUnknown},
+	} {
+		power := new(nrf.POWER_Type)
+		power.RESETREAS.Set(tc.reas)
+		if reason := resetReasonFromPower(power); reason != tc.reason {
+			t.Errorf("RESETREAS %#x: reset reason %s, want %s", tc.reas, reason, tc.reason)
+		}
+		// The flags are cleared by writing them back, which leaves them in
+		// RAM.
+		if reas := power.RESETREAS.Get(); reas != tc.reas {
+			t.Errorf("RESETREAS %#x: wrote %#x to clear it", tc.reas, reas)
+		}
+	}
+}
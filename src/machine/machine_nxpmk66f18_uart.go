@@ -100,6 +100,7 @@ type UART struct {
 	Configured   bool
 	Transmitting volatile.Register8
 	Interrupt    interrupt.Interrupt
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 var (
@@ -129,6 +130,8 @@ func (u *UART) Configure(config UARTConfig) {
 }
 
 func (u *UART) configure(config UARTConfig, canSched bool) {
+	u.writeTimeout = config.WriteTimeout
+
 	// from: serial_begin
 
 	if !u.Configured {
@@ -148,27 +151,7 @@ func (u *UART) configure(config UARTConfig, canSched bool) {
 		config.BaudRate = 115200
 	}
 
-	// copied from teensy core's BAUD2DIV macro
-	divisor := ((CPUFrequency() * 2) + (config.BaudRate >> 1)) / config.BaudRate
-	if divisor < 32 {
-		divisor = 32
-	}
-
-	if u.Configured {
-		// don't change baud rate mid transmit
-		if canSched {
-			u.Flush()
-		} else {
-			for u.Transmitting.Get() != 0 {
-				// busy wait flush
-			}
-		}
-	}
-
-	// set the divisor
-	u.BDH.Set(uint8((divisor >> 13) & 0x1F))
-	u.BDL.Set(uint8((divisor >> 5) & 0xFF))
-	u.C4.Set(uint8(divisor & 0x1F))
+	u.setBaudRate(config.BaudRate, canSched)
 
 	if !u.Configured {
 		u.Configured = true
@@ -189,6 +172,36 @@ func (u *UART) configure(config UARTConfig, canSched bool) {
 	}
 }
 
+// SetBaudRate sets the communication speed for the UART. Data that is still
+// being transmitted is sent at the old speed first.
+func (u *UART) SetBaudRate(br uint32) {
+	u.setBaudRate(br, true)
+}
+
+func (u *UART) setBaudRate(br uint32, canSched bool) {
+	// copied from teensy core's BAUD2DIV macro
+	divisor := ((CPUFrequency() * 2) + (br >> 1)) / br
+	if divisor < 32 {
+		divisor = 32
+	}
+
+	if u.Configured {
+		// don't change baud rate mid transmit
+		if canSched {
+			u.Flush()
+		} else {
+			for u.Transmitting.Get() != 0 {
+				// busy wait flush
+			}
+		}
+	}
+
+	// set the divisor, the fine adjust is in the low bits of C4
+	u.BDH.Set(uint8((divisor >> 13) & 0x1F))
+	u.BDL.Set(uint8((divisor >> 5) & 0xFF))
+	u.C4.ReplaceBits(uint8(divisor&0x1F), 0x1F, 0)
+}
+
 func (u *UART) Disable() {
 	// from: serial_end
 
@@ -301,8 +314,12 @@ func (u *UART) WriteByte(c byte) error {
 		return ErrNotConfigured
 	}
 
+	deadline := uartWriteDeadline(u.writeTimeout)
 	for !u.TXBuffer.Put(c) {
 		gosched()
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 
 	u.Transmitting.Set(1)
@@ -0,0 +1,61 @@
+//go:build atsamd21 || atsamd51 || atsame5x || esp32c3 || k210 || mimxrt1062 || nrf || rp2040 || rp2350 || stm32 || !baremetal
+// +build atsamd21 atsamd51 atsame5x esp32c3 k210 mimxrt1062 nrf rp2040 rp2350 stm32 !baremetal
+
+package machine
+
+import "sync/atomic"
+
+// pinEventBufferSize is the number of events that NotifyChan buffers before it
+// starts dropping them.
+const pinEventBufferSize = 16
+
+// PinEvent is a pin change that was seen by the pin change interrupt.
+type PinEvent struct {
+	Pin Pin
+
+	// High is the state of the pin read from the interrupt handler, right
+	// after the change. With a very short pulse the pin may already have
+	// changed back, so PinToggle events may not alternate.
+	High bool
+}
+
+var pinEventsDropped uint32
+
+// NotifyChan is like SetInterrupt, but instead of calling a callback from the
+// interrupt handler it sends an event on the returned channel, which can then
+// be processed by a regular goroutine:
+//
+//	for event := range button.NotifyChan(machine.PinFalling) {
+//		println("pressed:", event.Pin)
+//	}
+//
+// Events are sent without blocking: the channel buffers a few events, and new
+// events are dropped when the goroutine doesn't keep up. Dropped events can be
+// counted with PinEventsDropped.
+//
+// Like SetInterrupt, this replaces a previously set interrupt on this pin.
+// Call SetInterrupt with a nil callback to stop notifications; the channel is
+// not closed. If the interrupt can't be configured (for example because all
+// pin change interrupts are in use), the returned channel is closed right away.
+func (p Pin) NotifyChan(change PinChange) <-chan PinEvent {
+	ch := make(chan PinEvent, pinEventBufferSize)
+	err := p.SetInterrupt(change, func(p Pin) {
+		// Sending on a channel from an interrupt is allowed, as long as it
+		// doesn't block.
+		select {
+		case ch <- PinEvent{Pin: p, High: p.Get()}:
+		default:
+			atomic.AddUint32(&pinEventsDropped, 1)
+		}
+	})
+	if err != nil {
+		close(ch)
+	}
+	return ch
+}
+
+// PinEventsDropped returns the number of pin events that were dropped by
+// NotifyChan because a channel was full, since the program started.
+func PinEventsDropped() uint32 {
+	return atomic.LoadUint32(&pinEventsDropped)
+}
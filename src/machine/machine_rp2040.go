@@ -9,6 +9,11 @@ import (
 
 const deviceName = rp.Device
 
+// RESETS_RESET_Msk is bitmask to reset all peripherals
+//
+// TODO: This field is not available in the device file.
+const RESETS_RESET_Msk = 0x01ffffff
+
 const (
 	// GPIO pins
 	GPIO0  Pin = 0  // peripherals: PWM0 channel A
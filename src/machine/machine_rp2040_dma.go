@@ -0,0 +1,57 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"errors"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+var ErrNoDMAChannel = errors.New("machine: no free DMA channel")
+
+const dmaNumChannels = 12
+
+type dmaChannelType struct {
+	readAddr   volatile.Register32
+	writeAddr  volatile.Register32
+	transCount volatile.Register32
+	ctrlTrig   volatile.Register32
+	_          [12]volatile.Register32 // alias registers
+}
+
+type dmaType struct {
+	ch        [dmaNumChannels]dmaChannelType
+	_         [(0x444 - dmaNumChannels*0x40) / 4]volatile.Register32
+	chanAbort volatile.Register32
+}
+
+var dma = (*dmaType)(unsafe.Pointer(rp.DMA))
+
+// Bitmap of the DMA channels claimed by drivers.
+var dmaChannelsUsed uint16
+
+// dmaClaimChannel reserves a DMA channel and returns its number, or -1 when
+// all channels are in use.
+func dmaClaimChannel() int {
+	mask := interrupt.Disable()
+	defer interrupt.Restore(mask)
+	for ch := 0; ch < dmaNumChannels; ch++ {
+		if dmaChannelsUsed&(1<<ch) == 0 {
+			dmaChannelsUsed |= 1 << ch
+			return ch
+		}
+	}
+	return -1
+}
+
+// abortChannel stops any transfer on the given channel, leaving the other
+// channels running.
+func (dma *dmaType) abortChannel(ch uint8) {
+	dma.chanAbort.Set(1 << ch)
+	for dma.chanAbort.HasBits(1 << ch) {
+	}
+}
@@ -88,6 +88,20 @@ const (
 	PinSPI
 )
 
+// Drive strengths for PinConfig.DriveStrength. The reset value is 4mA.
+const (
+	PinDrive2mA PinDriveStrength = iota + 1
+	PinDrive4mA
+	PinDrive8mA
+	PinDrive12mA
+)
+
+// The pull resistor and drive strength of PinConfig are supported.
+const (
+	hasPinPull          = true
+	maxPinDriveStrength = PinDrive12mA
+)
+
 func (p Pin) PortMaskSet() (*uint32, uint32) {
 	return (*uint32)(unsafe.Pointer(&rp.SIO.GPIO_OUT_SET)), 1 << p
 }
@@ -204,6 +218,25 @@ func (p Pin) Configure(config PinConfig) {
 	case PinSPI:
 		p.setFunc(fnSPI)
 	}
+	configurePad(p.padCtrl(), config.Pull, config.DriveStrength)
+}
+
+// configurePad applies the pull and drive strength of a PinConfig to the pad
+// control register of a pin. PinPullDefault and a zero drive strength leave the
+// current setting alone.
+func configurePad(pad *volatile.Register32, pull PinPull, strength PinDriveStrength) {
+	switch pull {
+	case PinPullNone:
+		pad.ClearBits(rp.PADS_BANK0_GPIO0_PUE | rp.PADS_BANK0_GPIO0_PDE)
+	case PinPullUp:
+		pad.ReplaceBits(rp.PADS_BANK0_GPIO0_PUE, rp.PADS_BANK0_GPIO0_PUE|rp.PADS_BANK0_GPIO0_PDE, 0)
+	case PinPullDown:
+		pad.ReplaceBits(rp.PADS_BANK0_GPIO0_PDE, rp.PADS_BANK0_GPIO0_PUE|rp.PADS_BANK0_GPIO0_PDE, 0)
+	}
+	if strength != 0 {
+		// The register values for 2mA, 4mA, 8mA and 12mA are 0 to 3.
+		pad.ReplaceBits(uint32(strength-1)<<rp.PADS_BANK0_GPIO0_DRIVE_Pos, rp.PADS_BANK0_GPIO0_DRIVE_Msk, 0)
+	}
 }
 
 // Set drives the pin high if value is true else drives it low.
@@ -0,0 +1,52 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"runtime/volatile"
+	"testing"
+)
+
+// configurePad is tested on a pad register in RAM. Like the other rp2040
+// tests, this file is only compiled by the smoketest and not run.
+
+func TestConfigurePad(t *testing.T) {
+	// Reset value: input enabled, 4mA, pull-down, Schmitt trigger.
+	const reset = rp.PADS_BANK0_GPIO0_IE | 1<<rp.PADS_BANK0_GPIO0_DRIVE_Pos | rp.PADS_BANK0_GPIO0_PDE | rp.PADS_BANK0_GPIO0_SCHMITT
+	for _, tc := range []struct {
+		name     string
+		pull     PinPull
+		strength PinDriveStrength
+		value    uint32
+	}{
+		{"default", PinPullDefault, 0, reset},
+		{"pull-up", PinPullUp, 0, reset&^rp.PADS_BANK0_GPIO0_PDE | rp.PADS_BANK0_GPIO0_PUE},
+		{"no pull", PinPullNone, 0, reset &^ rp.PADS_BANK0_GPIO0_PDE},
+		{"pull-down 2mA", PinPullDown, PinDrive2mA, reset &^ rp.PADS_BANK0_GPIO0_DRIVE_Msk},
+		{"pull-up 12mA", PinPullUp, PinDrive12mA, reset&^rp.PADS_BANK0_GPIO0_PDE | rp.PADS_BANK0_GPIO0_PUE | 3<<rp.PADS_BANK0_GPIO0_DRIVE_Pos},
+	} {
+		var pad volatile.Register32
+		pad.Set(reset)
+		configurePad(&pad, tc.pull, tc.strength)
+		if got := pad.Get(); got != tc.value {
+			t.Errorf("%s: pad = %#02x, want %#02x", tc.name, got, tc.value)
+		}
+	}
+}
+
+func TestPinConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config PinConfig
+		err    error
+	}{
+		{PinConfig{Pull: PinPullNone, DriveStrength: PinDrive12mA}, nil},
+		{PinConfig{Pull: PinPullDown + 1}, ErrPinPull},
+		{PinConfig{DriveStrength: PinDrive12mA + 1}, ErrPinDriveStrength},
+	} {
+		if err := tc.config.Validate(); err != tc.err {
+			t.Errorf("%+v: Validate() = %v, want %v", tc.config, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"errors"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+var (
+	ErrNoPIOStateMachine = errors.New("machine: no free PIO state machine")
+	ErrNoPIOProgramSpace = errors.New("machine: not enough free PIO instruction memory")
+)
+
+const (
+	pioNumStateMachines = 4
+	pioNumInstructions  = 32
+
+	pioInstrJmp     = 0x0000 // jmp <addr>, or'ed with the address
+	pioInstrJmpMask = 0xe000
+
+	// DMA request numbers of the TX FIFO of state machine 0, see the DMA
+	// chapter of the RP2040 datasheet.
+	dreqPIO0TX0 = 0
+	dreqPIO1TX0 = 8
+)
+
+type pioStateMachineType struct {
+	clkdiv    volatile.Register32
+	execctrl  volatile.Register32
+	shiftctrl volatile.Register32
+	addr      volatile.Register32
+	instr     volatile.Register32
+	pinctrl   volatile.Register32
+}
+
+type pioType struct {
+	ctrl            volatile.Register32
+	fstat           volatile.Register32
+	fdebug          volatile.Register32
+	flevel          volatile.Register32
+	txf             [pioNumStateMachines]volatile.Register32
+	rxf             [pioNumStateMachines]volatile.Register32
+	irq             volatile.Register32
+	irqForce        volatile.Register32
+	inputSyncBypass volatile.Register32
+	dbgPadout       volatile.Register32
+	dbgPadoe        volatile.Register32
+	dbgCfginfo      volatile.Register32
+	instrMem        [pioNumInstructions]volatile.Register32
+	sm              [pioNumStateMachines]pioStateMachineType
+}
+
+// pioBlock is one of the two PIO blocks. Drivers that use a PIO block must
+// claim their state machines and load their programs through it, so that they
+// don't overwrite each other.
+type pioBlock struct {
+	regs     *pioType
+	pinFunc  pinFunc
+	dreqTX0  uint8  // DMA request number of the TX FIFO of state machine 0
+	smUsed   uint8  // bitmap of claimed state machines
+	instUsed uint32 // bitmap of used instruction memory slots
+	programs []pioLoadedProgram
+}
+
+// pioLoadedProgram is a program in the instruction memory of a PIO block.
+type pioLoadedProgram struct {
+	program *uint16 // first instruction of the (unrelocated) program
+	offset  uint8
+}
+
+var (
+	pio0 = &pioBlock{regs: (*pioType)(unsafe.Pointer(rp.PIO0)), pinFunc: fnPIO0, dreqTX0: dreqPIO0TX0}
+	pio1 = &pioBlock{regs: (*pioType)(unsafe.Pointer(rp.PIO1)), pinFunc: fnPIO1, dreqTX0: dreqPIO1TX0}
+)
+
+// claimStateMachine returns a state machine that is not used yet.
+func (pio *pioBlock) claimStateMachine() (uint8, error) {
+	mask := interrupt.Disable()
+	defer interrupt.Restore(mask)
+	for sm := uint8(0); sm < pioNumStateMachines; sm++ {
+		if pio.smUsed&(1<<sm) == 0 {
+			pio.smUsed |= 1 << sm
+			return sm, nil
+		}
+	}
+	return 0, ErrNoPIOStateMachine
+}
+
+// releaseStateMachine stops the given state machine and makes it available
+// again.
+func (pio *pioBlock) releaseStateMachine(sm uint8) {
+	pio.regs.ctrl.ClearBits(1 << (rp.PIO0_CTRL_SM_ENABLE_Pos + sm))
+	pio.smUsed &^= 1 << sm
+}
+
+// addProgram loads the given program in free instruction memory and returns
+// its offset. The addresses of jmp instructions are relative to the start of
+// the program, they are adjusted for the offset it is loaded at. A program
+// that was already loaded (the same array) is loaded only once, so drivers
+// running the same program on several state machines share it.
+func (pio *pioBlock) addProgram(program []uint16) (uint8, error) {
+	mask := interrupt.Disable()
+	defer interrupt.Restore(mask)
+	for _, loaded := range pio.programs {
+		if loaded.program == &program[0] {
+			return loaded.offset, nil
+		}
+	}
+
+	// Like the Pico SDK, use the free space closest to the end of the
+	// instruction memory.
+	programMask := uint32(1)<<len(program) - 1
+	for offset := pioNumInstructions - len(program); offset >= 0; offset-- {
+		if pio.instUsed&(programMask<<offset) != 0 {
+			continue
+		}
+		for i, instr := range program {
+			if instr&pioInstrJmpMask == pioInstrJmp {
+				instr += uint16(offset)
+			}
+			pio.regs.instrMem[offset+i].Set(uint32(instr))
+		}
+		pio.instUsed |= programMask << offset
+		pio.programs = append(pio.programs, pioLoadedProgram{&program[0], uint8(offset)})
+		return uint8(offset), nil
+	}
+	return 0, ErrNoPIOProgramSpace
+}
@@ -0,0 +1,37 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+)
+
+func readResetReason() ResetReason {
+	return resetReasonFromRegisters(rp.WATCHDOG, rp.VREG_AND_CHIP_RESET)
+}
+
+// resetReasonFromRegisters reads the reset cause. The registers are read-only:
+// they are updated by hardware on every reset.
+func resetReasonFromRegisters(watchdog *rp.WATCHDOG_Type, vregAndChipReset *rp.VREG_AND_CHIP_RESET_Type) ResetReason {
+	// A watchdog reset doesn't reset the chip-level reset flags, so check the
+	// watchdog first. Its reason is cleared by a chip-level reset.
+	reason := watchdog.REASON.Get()
+	switch {
+	case reason&rp.WATCHDOG_REASON_TIMER != 0:
+		return ResetReasonWatchdog
+	case reason&rp.WATCHDOG_REASON_FORCE != 0:
+		return ResetReasonSoftware
+	}
+
+	chipReset := vregAndChipReset.CHIP_RESET.Get()
+	switch {
+	case chipReset&rp.VREG_AND_CHIP_RESET_CHIP_RESET_HAD_RUN != 0:
+		return ResetReasonExternal
+	case chipReset&rp.VREG_AND_CHIP_RESET_CHIP_RESET_HAD_POR != 0:
+		// This includes brownout resets.
+		return ResetReasonPowerOn
+	default:
+		return ResetReasonUnknown
+	}
+}
@@ -0,0 +1,34 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"testing"
+)
+
+// The watchdog and chip reset registers are fakes in RAM. This test is only
+// compiled by the smoketest, there is no rp2040 emulator to run it.
+
+func TestResetReason(t *testing.T) {
+	for _, tc := range []struct {
+		watchdogReason uint32
+		chipReset      uint32
+		reason         ResetReason
+	}{
+		{0, rp.VREG_AND_CHIP_RESET_CHIP_RESET_HAD_POR, ResetReasonPowerOn},
+		{0, rp.VREG_AND_CHIP_RESET_CHIP_RESET_HAD_RUN, ResetReasonExternal},
+		{rp.WATCHDOG_REASON_TIMER, rp.VREG_AND_CHIP_RESET_CHIP_RESET_HAD_POR, ResetReasonWatchdog},
+		{rp.WATCHDOG_REASON_FORCE, rp.VREG_AND_CHIP_RESET_CHIP_RESET_HAD_RUN, ResetReasonSoftware},
+		{0, 0, ResetReasonUnknown},
+	} {
+		watchdog := new(rp.WATCHDOG_Type)
+		watchdog.REASON.Set(tc.watchdogReason)
+		vreg := new(rp.VREG_AND_CHIP_RESET_Type)
+		vreg.CHIP_RESET.Set(tc.chipReset)
+		if reason := resetReasonFromRegisters(watchdog, vreg); reason != tc.reason {
+			t.Errorf("REASON %#x, CHIP_RESET %#x: reset reason %s, want %s", tc.watchdogReason, tc.chipReset, reason, tc.reason)
+		}
+	}
+}
@@ -145,6 +145,12 @@ func (spi SPI) GetBaudRate() uint32 {
 	return freqin / (prescale * postdiv)
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured, which is
+// the same as GetBaudRate.
+func (spi SPI) ActualFrequency() uint32 {
+	return spi.GetBaudRate()
+}
+
 // Configure is intended to setup/initialize the SPI interface.
 // Default baudrate of 115200 is used if Frequency == 0. Default
 // word length (data bits) is 8.
@@ -0,0 +1,199 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"errors"
+	"runtime/interrupt"
+)
+
+// SPISlaveConfig is used to configure an SPISlave.
+// The serial clock is provided by the SPI master, so there is no frequency.
+type SPISlaveConfig struct {
+	// Mode's two most LSB are CPOL and CPHA. i.e. Mode==2 (0b10) is CPOL=1, CPHA=0
+	// Note that with CPHA=0 the PL022 requires the master to deassert CS
+	// between every frame.
+	Mode uint8
+	// Number of data bits per transfer. Valid values 4..8, default is 8.
+	DataBits uint8
+	// Serial clock pin
+	SCK Pin
+	// TX or Serial Data Out (MISO as rp2040 is slave)
+	SDO Pin
+	// RX or Serial Data In (MOSI as rp2040 is slave)
+	SDI Pin
+	// Chip select pin, driven by the master. Defaults to the CSn pin next to
+	// the SCK pin (SCK-1).
+	CS Pin
+}
+
+var ErrSPISlaveDataBits = errors.New("SPI slave mode only supports 4 to 8 data bits")
+
+// SPISlave is an SPI bus in slave (peripheral) mode, driven by an external SPI
+// master. Received bytes are buffered by the SPI interrupt and can be read using
+// Read, bytes queued with Write are sent to the master on the next transfers.
+// An SPI bus is used either as SPI (master) or as SPISlave.
+type SPISlave struct {
+	Bus *rp.SPI0_Type
+	rx  RingBuffer
+	tx  RingBuffer
+}
+
+// SPI buses in slave mode.
+var (
+	SPI0Slave = &SPISlave{Bus: rp.SPI0}
+	SPI1Slave = &SPISlave{Bus: rp.SPI1}
+)
+
+// Configure sets up the SPI bus as a slave to an external SPI master.
+//
+// If SCK is not set, the default SPI pins of the board are used. Frames of more
+// than 8 bits are not supported in slave mode.
+func (spi *SPISlave) Configure(config SPISlaveConfig) error {
+	if config.DataBits == 0 {
+		config.DataBits = 8
+	}
+	if config.DataBits < 4 || config.DataBits > 8 {
+		return ErrSPISlaveDataBits
+	}
+	if config.SCK == 0 {
+		// set default pins if config zero valued or invalid clock pin supplied.
+		switch spi.Bus {
+		case rp.SPI0:
+			config.SCK = SPI0_SCK_PIN
+			config.SDO = SPI0_SDO_PIN
+			config.SDI = SPI0_SDI_PIN
+		case rp.SPI1:
+			config.SCK = SPI1_SCK_PIN
+			config.SDO = SPI1_SDO_PIN
+			config.SDI = SPI1_SDI_PIN
+		}
+	}
+	if config.CS == 0 {
+		// On the rp2040, the CSn function is always on the pin just before
+		// the SCK pin.
+		config.CS = config.SCK - 1
+	}
+	// SPI pin configuration
+	config.SCK.setFunc(fnSPI)
+	config.SDO.setFunc(fnSPI)
+	config.SDI.setFunc(fnSPI)
+	config.CS.setFunc(fnSPI)
+
+	SPI{Bus: spi.Bus}.reset()
+	spi.configure(config.Mode, config.DataBits)
+
+	switch spi.Bus {
+	case rp.SPI0:
+		intr := interrupt.New(rp.IRQ_SPI0_IRQ, func(interrupt.Interrupt) {
+			SPI0Slave.handleInterrupt()
+		})
+		intr.SetPriority(0x80)
+		intr.Enable()
+	case rp.SPI1:
+		intr := interrupt.New(rp.IRQ_SPI1_IRQ, func(interrupt.Interrupt) {
+			SPI1Slave.handleInterrupt()
+		})
+		intr.SetPriority(0x80)
+		intr.Enable()
+	}
+	return nil
+}
+
+// configure sets the frame format of the SPI bus, which was just reset,
+// selects slave mode and enables it.
+func (spi *SPISlave) configure(mode, dataBits uint8) {
+	spi.rx.Clear()
+	spi.tx.Clear()
+
+	// Motorola frame format, with CPHA and CPOL from the mode.
+	cpha := uint32(mode) & 1
+	cpol := uint32(mode>>1) & 1
+	spi.Bus.SSPCR0.Set(cpha<<rp.SPI0_SSPCR0_SPH_Pos |
+		cpol<<rp.SPI0_SSPCR0_SPO_Pos |
+		uint32(dataBits-1)<<rp.SPI0_SSPCR0_DSS_Pos)
+
+	// Select slave mode, then enable the SPI. The mode may only be changed
+	// while the SPI is disabled.
+	spi.Bus.SSPCR1.SetBits(rp.SPI0_SSPCR1_MS)
+	spi.Bus.SSPCR1.SetBits(rp.SPI0_SSPCR1_SSE)
+
+	// Interrupt on received data, including when there is less data in the
+	// FIFO than the interrupt threshold (receive timeout).
+	spi.Bus.SSPIMSC.Set(rp.SPI0_SSPIMSC_RXIM | rp.SPI0_SSPIMSC_RTIM)
+}
+
+// Read reads bytes received from the SPI master into p. It does not block: it
+// returns the number of bytes that were available, which may be zero.
+func (spi *SPISlave) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		b, ok := spi.rx.Get()
+		if !ok {
+			break
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
+
+// Buffered returns the number of bytes received from the SPI master that are
+// waiting to be read.
+func (spi *SPISlave) Buffered() int {
+	return int(spi.rx.Used())
+}
+
+// Write queues bytes to be sent to the SPI master during the next transfers.
+// When the master clocks in more bytes than were queued, zeroes are sent. It
+// returns the number of bytes queued, which is less than len(p) if the transmit
+// buffer is full.
+func (spi *SPISlave) Write(p []byte) (n int, err error) {
+	for _, b := range p {
+		if !spi.tx.Put(b) {
+			break
+		}
+		n++
+	}
+	// Load the TX FIFO now, and let the TX interrupt refill it.
+	mask := interrupt.Disable()
+	spi.fillTX()
+	interrupt.Restore(mask)
+	return n, nil
+}
+
+// fillTX moves as many queued bytes as possible into the TX FIFO. The TX
+// interrupt is only enabled while there are bytes queued, as it would otherwise
+// keep firing while the FIFO is not full.
+func (spi *SPISlave) fillTX() {
+	for spi.Bus.SSPSR.HasBits(rp.SPI0_SSPSR_TNF) {
+		b, ok := spi.tx.Get()
+		if !ok {
+			break
+		}
+		spi.Bus.SSPDR.Set(uint32(b))
+	}
+	if spi.tx.Used() != 0 {
+		spi.Bus.SSPIMSC.SetBits(rp.SPI0_SSPIMSC_TXIM)
+	} else {
+		spi.Bus.SSPIMSC.ClearBits(rp.SPI0_SSPIMSC_TXIM)
+	}
+}
+
+// handleInterrupt moves received bytes from the RX FIFO into the receive
+// buffer and refills the TX FIFO from the transmit buffer.
+func (spi *SPISlave) handleInterrupt() {
+	// Read at most one FIFO worth of bytes, so that a master that keeps
+	// clocking in data can't keep the CPU in this interrupt. The interrupt
+	// fires again if there is more.
+	const fifoDepth = 8
+	for i := 0; i < fifoDepth && spi.Bus.SSPSR.HasBits(rp.SPI0_SSPSR_RNE); i++ {
+		// Bytes are dropped when the receive buffer is full.
+		spi.rx.Put(uint8(spi.Bus.SSPDR.Get()))
+	}
+	spi.fillTX()
+	// Clear the receive timeout and overrun interrupts, the other interrupts
+	// are cleared by reading from or writing to the FIFOs.
+	spi.Bus.SSPICR.Set(rp.SPI0_SSPICR_RTIC | rp.SPI0_SSPICR_RORIC)
+}
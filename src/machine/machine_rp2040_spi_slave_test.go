@@ -0,0 +1,99 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"testing"
+)
+
+// These tests drive a fake SPI0_Type. They are only compiled by the smoketest,
+// as there is no rp2040 emulator to run them on.
+
+func TestSPISlaveConfigure(t *testing.T) {
+	spi := &SPISlave{Bus: new(rp.SPI0_Type)}
+	spi.configure(3, 8)
+	if want := uint32(1<<rp.SPI0_SSPCR0_SPH_Pos | 1<<rp.SPI0_SSPCR0_SPO_Pos | 7); spi.Bus.SSPCR0.Get() != want {
+		t.Errorf("SSPCR0 = %#x, want %#x", spi.Bus.SSPCR0.Get(), want)
+	}
+	if want := uint32(rp.SPI0_SSPCR1_MS | rp.SPI0_SSPCR1_SSE); spi.Bus.SSPCR1.Get() != want {
+		t.Errorf("SSPCR1 = %#x, want %#x", spi.Bus.SSPCR1.Get(), want)
+	}
+	if want := uint32(rp.SPI0_SSPIMSC_RXIM | rp.SPI0_SSPIMSC_RTIM); spi.Bus.SSPIMSC.Get() != want {
+		t.Errorf("SSPIMSC = %#x, want %#x", spi.Bus.SSPIMSC.Get(), want)
+	}
+
+	spi = &SPISlave{Bus: new(rp.SPI0_Type)}
+	spi.configure(0, 4)
+	if spi.Bus.SSPCR0.Get() != 3 {
+		t.Errorf("SSPCR0 = %#x, want 0x3", spi.Bus.SSPCR0.Get())
+	}
+	for _, dataBits := range []uint8{3, 9, 16} {
+		if err := spi.Configure(SPISlaveConfig{DataBits: dataBits}); err != ErrSPISlaveDataBits {
+			t.Errorf("Configure with %d data bits: got %v, want %v", dataBits, err, ErrSPISlaveDataBits)
+		}
+	}
+}
+
+func TestSPISlaveWrite(t *testing.T) {
+	spi := &SPISlave{Bus: new(rp.SPI0_Type)}
+	spi.configure(0, 8)
+
+	// The TX FIFO is full, so the bytes are queued and the TX interrupt is
+	// enabled to load them later.
+	msg := []byte("hello")
+	if n, err := spi.Write(msg); n != len(msg) || err != nil {
+		t.Errorf("Write: %d, %v", n, err)
+	}
+	if spi.Bus.SSPDR.Get() != 0 {
+		t.Errorf("wrote %#x to the full TX FIFO", spi.Bus.SSPDR.Get())
+	}
+	if !spi.Bus.SSPIMSC.HasBits(rp.SPI0_SSPIMSC_TXIM) {
+		t.Error("TX interrupt not enabled while bytes are queued")
+	}
+
+	// Once there is room, the interrupt moves the bytes to the FIFO and
+	// disables the TX interrupt.
+	spi.Bus.SSPSR.Set(rp.SPI0_SSPSR_TNF)
+	spi.handleInterrupt()
+	if spi.Bus.SSPDR.Get() != 'o' {
+		t.Errorf("last byte written to the TX FIFO is %#x, want %#x", spi.Bus.SSPDR.Get(), 'o')
+	}
+	if spi.tx.Used() != 0 {
+		t.Errorf("%d bytes still queued", spi.tx.Used())
+	}
+	if spi.Bus.SSPIMSC.HasBits(rp.SPI0_SSPIMSC_TXIM) {
+		t.Error("TX interrupt still enabled after the buffer was sent")
+	}
+	if icr := spi.Bus.SSPICR.Get(); icr != rp.SPI0_SSPICR_RTIC|rp.SPI0_SSPICR_RORIC {
+		t.Errorf("SSPICR = %#x after the interrupt", icr)
+	}
+}
+
+func TestSPISlaveRead(t *testing.T) {
+	spi := &SPISlave{Bus: new(rp.SPI0_Type)}
+	spi.configure(0, 8)
+
+	// Nothing is read while the RX FIFO is empty.
+	spi.Bus.SSPDR.Set(0x5a)
+	spi.handleInterrupt()
+	if n := spi.Buffered(); n != 0 {
+		t.Errorf("buffered %d bytes from an empty RX FIFO", n)
+	}
+
+	// The fake RX FIFO never becomes empty, so the interrupt reads one FIFO
+	// worth of bytes.
+	spi.Bus.SSPSR.Set(rp.SPI0_SSPSR_RNE)
+	spi.handleInterrupt()
+	if n := spi.Buffered(); n != 8 {
+		t.Errorf("buffered %d bytes, want 8", n)
+	}
+	buf := make([]byte, 16)
+	if n, _ := spi.Read(buf); n != 8 || buf[0] != 0x5a || buf[7] != 0x5a {
+		t.Errorf("read %x", buf[:n])
+	}
+	if n, _ := spi.Read(buf); n != 0 {
+		t.Errorf("read %d bytes from an empty buffer", n)
+	}
+}
@@ -4,6 +4,7 @@
 package machine
 
 import (
+	"device/arm"
 	"device/rp"
 )
 
@@ -71,3 +72,36 @@ func irqSetMask(mask uint32, enabled bool) {
 		rp.PPB.NVIC_ICER.Set(mask)
 	}
 }
+
+// sioSpinlock is hardware spinlock 14 of the SIO, which is reserved for
+// operating systems in the Pico SDK (PICO_SPINLOCK_ID_OS1). The Cortex-M0+ has
+// no exclusive load and store instructions, so a hardware spinlock is needed.
+type sioSpinlock struct{}
+
+// criticalSectionLock protects the owner field of all critical sections.
+var criticalSectionLock sioSpinlock
+
+func (sioSpinlock) tryLock() bool {
+	if rp.SIO.SPINLOCK14.Get() == 0 {
+		return false
+	}
+	// Don't let memory accesses of the critical section move before the
+	// lock was taken.
+	arm.Asm("dmb")
+	return true
+}
+
+func (sioSpinlock) unlock() {
+	// Complete the memory accesses of the critical section before releasing
+	// the lock.
+	arm.Asm("dmb")
+	rp.SIO.SPINLOCK14.Set(0)
+}
+
+func (cs *CriticalSection) lock() {
+	cs.lockWith(criticalSectionLock, uint8(CurrentCore())+1)
+}
+
+func (cs *CriticalSection) unlock() {
+	cs.unlockWith(criticalSectionLock)
+}
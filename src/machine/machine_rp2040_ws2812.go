@@ -0,0 +1,165 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"unsafe"
+)
+
+// WS2812 drives a strip of WS2812 (NeoPixel) LEDs connected to a single pin.
+//
+// On the RP2040 the waveform is generated by a PIO state machine that is fed
+// by a DMA channel, so the timing does not depend on the CPU and interrupts do
+// not need to be disabled while sending. Every configured WS2812 claims a PIO
+// state machine (of PIO0, or PIO1 when PIO0 is full) and a DMA channel. The
+// WS2812 program is loaded once per PIO block and shared.
+type WS2812 struct {
+	Pin Pin
+	pio *pioBlock
+	sm  uint8
+	dma uint8
+}
+
+// The WS2812 program from the pico-examples repository:
+//
+//	.program ws2812
+//	.side_set 1
+//	.wrap_target
+//	bitloop:
+//	    out x, 1       side 0 [2]
+//	    jmp !x do_zero side 1 [1]
+//	do_one:
+//	    jmp bitloop    side 1 [4]
+//	do_zero:
+//	    nop            side 0 [4]
+//	.wrap
+//
+// Every bit takes 10 PIO cycles: the line is high for 2 cycles for a zero bit
+// and for 7 cycles for a one bit. The jump addresses are relative to the start
+// of the program, see pioBlock.addProgram.
+var ws2812Program = [...]uint16{
+	0x6221, // out x, 1 side 0 [2]
+	0x1123, // jmp !x, 3 side 1 [1]
+	0x1400, // jmp 0 side 1 [4]
+	0xa442, // nop side 0 [4]
+}
+
+const (
+	ws2812CyclesPerBit = 10
+	ws2812Frequency    = 800e3 // bits per second
+
+	pioInstrSetPindirsOut = 0xe081 // set pindirs, 1
+)
+
+// Configure sets up the pin, loads the WS2812 program (if no other WS2812
+// uses the same PIO block) and starts a state machine running it. The other
+// state machines and DMA channels are left alone.
+func (ws *WS2812) Configure() error {
+	pio := pio0
+	sm, err := pio.claimStateMachine()
+	if err != nil {
+		pio = pio1
+		sm, err = pio.claimStateMachine()
+		if err != nil {
+			return err
+		}
+	}
+	offset, err := pio.addProgram(ws2812Program[:])
+	if err != nil {
+		pio.releaseStateMachine(sm)
+		return err
+	}
+	ch := dmaClaimChannel()
+	if ch < 0 {
+		pio.releaseStateMachine(sm)
+		return ErrNoDMAChannel
+	}
+	ws.pio = pio
+	ws.sm = sm
+	ws.dma = uint8(ch)
+	dma.abortChannel(ws.dma)
+
+	// Hand the pin to the PIO block.
+	ws.Pin.setFunc(pio.pinFunc)
+	ws2812ConfigureSM(pio.regs, sm, offset, ws.Pin, CPUFrequency())
+	return nil
+}
+
+// ws2812ConfigureSM configures the given state machine to run the WS2812
+// program, loaded at the given offset, on the pin and starts it. The pin must
+// already be handed to the PIO block.
+func ws2812ConfigureSM(pio *pioType, sm, offset uint8, pin Pin, cpuFrequency uint32) {
+	regs := &pio.sm[sm]
+
+	// Make sure the state machine is stopped while it is being configured.
+	pio.ctrl.ClearBits(1 << (rp.PIO0_CTRL_SM_ENABLE_Pos + sm))
+
+	// Make the pin an output, using a 'set' instruction that is executed
+	// directly.
+	regs.pinctrl.Set(1<<rp.PIO0_SM0_PINCTRL_SET_COUNT_Pos | uint32(pin)<<rp.PIO0_SM0_PINCTRL_SET_BASE_Pos)
+	regs.instr.Set(pioInstrSetPindirsOut)
+
+	// Run the state machine at 10 cycles per bit. The clock divider is a 16.8
+	// fixed point number in the upper 24 bits of CLKDIV.
+	div := uint64(cpuFrequency) * 256 / (ws2812Frequency * ws2812CyclesPerBit)
+	regs.clkdiv.Set(uint32(div) << rp.PIO0_SM0_CLKDIV_FRAC_Pos)
+	regs.execctrl.Set(uint32(offset+uint8(len(ws2812Program))-1)<<rp.PIO0_SM0_EXECCTRL_WRAP_TOP_Pos |
+		uint32(offset)<<rp.PIO0_SM0_EXECCTRL_WRAP_BOTTOM_Pos)
+	// Shift out MSB first and pull a new word after 8 bits: the DMA writes
+	// bytes to the FIFO which the bus replicates to all four byte lanes, so
+	// the top 8 bits of every FIFO word are the byte to send.
+	regs.shiftctrl.Set(rp.PIO0_SM0_SHIFTCTRL_FJOIN_TX |
+		8<<rp.PIO0_SM0_SHIFTCTRL_PULL_THRESH_Pos |
+		rp.PIO0_SM0_SHIFTCTRL_AUTOPULL)
+	regs.pinctrl.Set(1<<rp.PIO0_SM0_PINCTRL_SIDESET_COUNT_Pos | uint32(pin)<<rp.PIO0_SM0_PINCTRL_SIDESET_BASE_Pos)
+
+	// Restart the state machine at the start of the program and enable it.
+	pio.ctrl.SetBits(1<<(rp.PIO0_CTRL_SM_RESTART_Pos+sm) | 1<<(rp.PIO0_CTRL_CLKDIV_RESTART_Pos+sm))
+	regs.instr.Set(pioInstrJmp | uint32(offset))
+	pio.ctrl.SetBits(1 << (rp.PIO0_CTRL_SM_ENABLE_Pos + sm))
+}
+
+// Write sends the given buffer to the LED strip. The buffer contains the raw
+// bytes in the order the LEDs expect them, which is green, red, blue for every
+// LED (followed by white for RGBW LEDs). Write returns when all bits have been
+// sent. The LEDs latch the new colors when the line stays low for at least
+// 280µs after that, so wait at least that long before calling Write again.
+func (ws *WS2812) Write(buf []byte) (n int, err error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	pio := ws.pio.regs
+	ws2812StartDMA(pio, dma, ws.sm, ws.dma, ws.pio.dreqTX0+ws.sm, buf)
+
+	// Wait until the DMA has filled the FIFO with the last byte, and then
+	// until the state machine has shifted out that byte and stalls on an
+	// empty FIFO (with the line low).
+	for dma.ch[ws.dma].ctrlTrig.HasBits(rp.DMA_CH0_CTRL_TRIG_BUSY) {
+		gosched()
+	}
+	for !pio.fstat.HasBits(1 << (rp.PIO0_FSTAT_TXEMPTY_Pos + ws.sm)) {
+	}
+	for !pio.fdebug.HasBits(1 << (rp.PIO0_FDEBUG_TXSTALL_Pos + ws.sm)) {
+	}
+	return len(buf), nil
+}
+
+// ws2812StartDMA starts a DMA transfer of buf to the TX FIFO of the state
+// machine, paced by the given data request of that FIFO.
+func ws2812StartDMA(pio *pioType, dma *dmaType, sm, ch, dreq uint8, buf []byte) {
+	pio.fdebug.Set(1 << (rp.PIO0_FDEBUG_TXSTALL_Pos + sm)) // write 1 to clear
+	regs := &dma.ch[ch]
+	regs.readAddr.Set(uint32(uintptr(unsafe.Pointer(&buf[0]))))
+	regs.writeAddr.Set(uint32(uintptr(unsafe.Pointer(&pio.txf[sm]))))
+	regs.transCount.Set(uint32(len(buf)))
+	regs.ctrlTrig.Set(rp.DMA_CH0_CTRL_TRIG_EN |
+		rp.DMA_CH0_CTRL_TRIG_DATA_SIZE_SIZE_BYTE<<rp.DMA_CH0_CTRL_TRIG_DATA_SIZE_Pos |
+		rp.DMA_CH0_CTRL_TRIG_INCR_READ |
+		uint32(ch)<<rp.DMA_CH0_CTRL_TRIG_CHAIN_TO_Pos | // chaining to itself disables chaining
+		uint32(dreq)<<rp.DMA_CH0_CTRL_TRIG_TREQ_SEL_Pos)
+}
+
+//go:linkname gosched runtime.Gosched
+func gosched()
@@ -0,0 +1,143 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// These tests use a PIO block in RAM. They are only compiled by the smoketest,
+// as there is no rp2040 emulator to run them on.
+
+func TestPIOAddProgram(t *testing.T) {
+	// Use a PIO block in RAM instead of PIO0 or PIO1.
+	pio := &pioBlock{regs: new(pioType)}
+
+	// The program is loaded at the end of the instruction memory, with the
+	// jump addresses adjusted.
+	offset, err := pio.addProgram(ws2812Program[:])
+	if err != nil || offset != 28 {
+		t.Fatalf("loaded program at %d, %v; want 28", offset, err)
+	}
+	for i, instr := range []uint32{0x6221, 0x1123 + 28, 0x1400 + 28, 0xa442} {
+		if got := pio.regs.instrMem[28+i].Get(); got != instr {
+			t.Errorf("instruction %d = %#04x, want %#04x", 28+i, got, instr)
+		}
+	}
+
+	// Loading the same program again shares it.
+	pio.regs.instrMem[28].Set(0)
+	if offset, err := pio.addProgram(ws2812Program[:]); err != nil || offset != 28 {
+		t.Errorf("loaded program again at %d, %v; want 28", offset, err)
+	}
+	if pio.regs.instrMem[28].Get() != 0 {
+		t.Error("shared program was loaded again")
+	}
+
+	// Other programs don't overwrite it.
+	other := make([]uint16, 28)
+	if offset, err := pio.addProgram(other); err != nil || offset != 0 {
+		t.Errorf("loaded other program at %d, %v; want 0", offset, err)
+	}
+	if _, err := pio.addProgram(make([]uint16, 1)); err != ErrNoPIOProgramSpace {
+		t.Errorf("loaded a program in full instruction memory: %v", err)
+	}
+}
+
+func TestPIOClaimStateMachine(t *testing.T) {
+	pio := &pioBlock{regs: new(pioType), smUsed: 0b0001}
+	for _, want := range []uint8{1, 2, 3} {
+		if sm, err := pio.claimStateMachine(); err != nil || sm != want {
+			t.Errorf("claimed state machine %d, %v; want %d", sm, err, want)
+		}
+	}
+	if _, err := pio.claimStateMachine(); err != ErrNoPIOStateMachine {
+		t.Errorf("claimed a fifth state machine: %v", err)
+	}
+	pio.releaseStateMachine(2)
+	if sm, err := pio.claimStateMachine(); err != nil || sm != 2 {
+		t.Errorf("claimed state machine %d, %v; want the released 2", sm, err)
+	}
+}
+
+func TestWS2812ConfigureSM(t *testing.T) {
+	pio := new(pioType)
+	ws2812ConfigureSM(pio, 1, 28, 16, 125e6)
+
+	for _, reg := range []struct {
+		name  string
+		got   uint32
+		value uint32
+	}{
+		// 125MHz / (800kHz * 10) = 15.625
+		{"CLKDIV", pio.sm[1].clkdiv.Get(), 15<<16 | 160<<8},
+		// Wrap from the last instruction to the first, at offset 28.
+		{"EXECCTRL", pio.sm[1].execctrl.Get(), 31<<12 | 28<<7},
+		{"SHIFTCTRL", pio.sm[1].shiftctrl.Get(), 1<<30 | 8<<25 | 1<<17},
+		{"PINCTRL", pio.sm[1].pinctrl.Get(), 1<<29 | 16<<10},
+		// The last instruction executed jumps to the start of the program.
+		{"INSTR", pio.sm[1].instr.Get(), pioInstrJmp | 28},
+	} {
+		if reg.got != reg.value {
+			t.Errorf("SM1 %s = %#08x, want %#08x", reg.name, reg.got, reg.value)
+		}
+	}
+	if ctrl := pio.ctrl.Get(); ctrl&0xf != 1<<1 {
+		t.Errorf("CTRL = %#x, want only SM1 enabled", ctrl)
+	}
+	for _, sm := range []int{0, 2, 3} {
+		if pio.sm[sm] != (pioStateMachineType{}) {
+			t.Errorf("registers of SM%d were written", sm)
+		}
+	}
+}
+
+func TestWS2812StartDMA(t *testing.T) {
+	defer func(used uint16) {
+		dmaChannelsUsed = used
+	}(dmaChannelsUsed)
+
+	// Another driver already uses DMA channels 0 and 1, so the state machine
+	// and DMA channel numbers differ.
+	dmaChannelsUsed = 0b11
+	ch := dmaClaimChannel()
+	if ch != 2 {
+		t.Fatalf("claimed DMA channel %d, want 2", ch)
+	}
+
+	pio := new(pioType)
+	dma := new(dmaType)
+	dma.ch[0].ctrlTrig.Set(1) // channel 0 is busy
+	buf := []byte{0x12, 0x34, 0x56}
+	ws2812StartDMA(pio, dma, 1, uint8(ch), dreqPIO1TX0+1, buf)
+
+	for _, reg := range []struct {
+		name  string
+		got   uint32
+		value uint32
+	}{
+		{"READ_ADDR", dma.ch[ch].readAddr.Get(), uint32(uintptr(unsafe.Pointer(&buf[0])))},
+		{"WRITE_ADDR", dma.ch[ch].writeAddr.Get(), uint32(uintptr(unsafe.Pointer(&pio.txf[1])))},
+		{"TRANS_COUNT", dma.ch[ch].transCount.Get(), 3},
+		// Byte transfers with increasing read address, chained to itself
+		// (no chaining) and paced by the TX FIFO of PIO1 SM1.
+		{"CTRL_TRIG", dma.ch[ch].ctrlTrig.Get(), 1<<0 | 1<<4 | 2<<11 | 9<<15},
+	} {
+		if reg.got != reg.value {
+			t.Errorf("DMA channel %d %s = %#08x, want %#08x", ch, reg.name, reg.got, reg.value)
+		}
+	}
+	if dma.ch[0].ctrlTrig.Get() != 1 {
+		t.Error("DMA channel 0 was changed")
+	}
+	if got := pio.fdebug.Get(); got != 1<<(24+1) {
+		t.Errorf("FDEBUG = %#x, want the TX stall flag of SM1 cleared", got)
+	}
+
+	dmaChannelsUsed = 1<<dmaNumChannels - 1
+	if ch := dmaClaimChannel(); ch != -1 {
+		t.Errorf("claimed DMA channel %d while all are in use", ch)
+	}
+}
@@ -0,0 +1,382 @@
+//go:build rp2350
+// +build rp2350
+
+package machine
+
+import (
+	"device/rp"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+const _NUMBANK0_GPIOS = 30
+
+type ioType struct {
+	status volatile.Register32
+	ctrl   volatile.Register32
+}
+
+// The interrupt registers cover all 48 GPIOs of the RP2350B, with 8 GPIOs in
+// each register.
+type irqCtrl struct {
+	intE [6]volatile.Register32
+	intF [6]volatile.Register32
+	intS [6]volatile.Register32
+}
+
+type ioBank0Type struct {
+	io                 [48]ioType
+	_                  [32]volatile.Register32
+	irqSummary         [12]volatile.Register32
+	intR               [6]volatile.Register32
+	proc0IRQctrl       irqCtrl
+	proc1IRQctrl       irqCtrl
+	dormantWakeIRQctrl irqCtrl
+}
+
+var ioBank0 = (*ioBank0Type)(unsafe.Pointer(rp.IO_BANK0))
+
+type padsBank0Type struct {
+	voltageSelect volatile.Register32
+	io            [48]volatile.Register32
+}
+
+var padsBank0 = (*padsBank0Type)(unsafe.Pointer(rp.PADS_BANK0))
+
+// pinFunc represents a GPIO function.
+//
+// Each GPIO can have one function selected at a time.
+// Likewise, each peripheral input (e.g. UART0 RX) should only be  selected
+// on one GPIO at a time. If the same peripheral input is connected to multiple GPIOs,
+// the peripheral sees the logical OR of these GPIO inputs.
+type pinFunc uint8
+
+// GPIO function selectors
+const (
+	fnHSTX pinFunc = 0 // High-speed transmit, only on GPIO12 to GPIO19
+	fnSPI  pinFunc = 1 // Connect one of the internal PL022 SPI peripherals to GPIO
+	fnUART pinFunc = 2
+	fnI2C  pinFunc = 3
+	// Connect a PWM slice to GPIO. There are twelve PWM slices,
+	// each with two output channels (A/B). The B pin can also be used as an input,
+	// for frequency and duty cycle measurement
+	fnPWM pinFunc = 4
+	// Software control of GPIO, from the single-cycle IO (SIO) block.
+	// The SIO function (F5) must be selected for the processors to drive a GPIO,
+	// but the input is always connected, so software can check the state of GPIOs at any time.
+	fnSIO pinFunc = 5
+	// Connect one of the programmable IO blocks (PIO) to GPIO.
+	fnPIO0, fnPIO1, fnPIO2 pinFunc = 6, 7, 8
+	// General purpose clock inputs/outputs, or the second chip select of the
+	// QSPI memory interface.
+	fnGPCK pinFunc = 9
+	// USB power control signals to/from the internal USB controller
+	fnUSB pinFunc = 10
+	// UART CTS/RTS pins used as an extra pair of UART TX/RX pins.
+	fnUARTAux pinFunc = 11
+	fnNULL    pinFunc = 0x1f
+)
+
+const (
+	PinOutput PinMode = iota
+	PinInput
+	PinInputPulldown
+	PinInputPullup
+	PinAnalog
+	PinUART
+	PinPWM
+	PinI2C
+	PinSPI
+)
+
+// Drive strengths for PinConfig.DriveStrength. The reset value is 4mA.
+const (
+	PinDrive2mA PinDriveStrength = iota + 1
+	PinDrive4mA
+	PinDrive8mA
+	PinDrive12mA
+)
+
+// The pull resistor and drive strength of PinConfig are supported.
+const (
+	hasPinPull          = true
+	maxPinDriveStrength = PinDrive12mA
+)
+
+func (p Pin) PortMaskSet() (*uint32, uint32) {
+	return (*uint32)(unsafe.Pointer(&rp.SIO.GPIO_OUT_SET)), 1 << p
+}
+
+// set drives the pin high
+func (p Pin) set() {
+	mask := uint32(1) << p
+	rp.SIO.GPIO_OUT_SET.Set(mask)
+}
+
+func (p Pin) PortMaskClear() (*uint32, uint32) {
+	return (*uint32)(unsafe.Pointer(&rp.SIO.GPIO_OUT_CLR)), 1 << p
+}
+
+// clr drives the pin low
+func (p Pin) clr() {
+	mask := uint32(1) << p
+	rp.SIO.GPIO_OUT_CLR.Set(mask)
+}
+
+// xor toggles the pin
+func (p Pin) xor() {
+	mask := uint32(1) << p
+	rp.SIO.GPIO_OUT_XOR.Set(mask)
+}
+
+// get returns the pin value
+func (p Pin) get() bool {
+	return rp.SIO.GPIO_IN.HasBits(1 << p)
+}
+
+func (p Pin) ioCtrl() *volatile.Register32 {
+	return &ioBank0.io[p].ctrl
+}
+
+func (p Pin) padCtrl() *volatile.Register32 {
+	return &padsBank0.io[p]
+}
+
+func (p Pin) pullup() {
+	p.padCtrl().SetBits(rp.PADS_BANK0_GPIO0_PUE)
+	p.padCtrl().ClearBits(rp.PADS_BANK0_GPIO0_PDE)
+}
+
+func (p Pin) pulldown() {
+	p.padCtrl().SetBits(rp.PADS_BANK0_GPIO0_PDE)
+	p.padCtrl().ClearBits(rp.PADS_BANK0_GPIO0_PUE)
+}
+
+func (p Pin) pulloff() {
+	p.padCtrl().ClearBits(rp.PADS_BANK0_GPIO0_PDE)
+	p.padCtrl().ClearBits(rp.PADS_BANK0_GPIO0_PUE)
+}
+
+// setSlew sets pad slew rate control.
+// true sets to fast. false sets to slow.
+func (p Pin) setSlew(sr bool) {
+	p.padCtrl().ReplaceBits(boolToBit(sr)<<rp.PADS_BANK0_GPIO0_SLEWFAST_Pos, rp.PADS_BANK0_GPIO0_SLEWFAST_Msk, 0)
+}
+
+// setSchmitt enables or disables Schmitt trigger.
+func (p Pin) setSchmitt(trigger bool) {
+	p.padCtrl().ReplaceBits(boolToBit(trigger)<<rp.PADS_BANK0_GPIO0_SCHMITT_Pos, rp.PADS_BANK0_GPIO0_SCHMITT_Msk, 0)
+}
+
+// setDriveStrength sets the output drive strength of the pad.
+func (p Pin) setDriveStrength(strength PinDriveStrength) {
+	p.padCtrl().ReplaceBits(uint32(strength-1)<<rp.PADS_BANK0_GPIO0_DRIVE_Pos, rp.PADS_BANK0_GPIO0_DRIVE_Msk, 0)
+}
+
+// setFunc will set pin function to fn.
+func (p Pin) setFunc(fn pinFunc) {
+	// Set input enable, Clear output disable
+	p.padCtrl().ReplaceBits(rp.PADS_BANK0_GPIO0_IE,
+		rp.PADS_BANK0_GPIO0_IE_Msk|rp.PADS_BANK0_GPIO0_OD_Msk, 0)
+
+	// Zero all fields apart from fsel; we want this IO to do what the peripheral tells it.
+	// This doesn't affect e.g. pullup/pulldown, as these are in pad controls.
+	p.ioCtrl().Set(uint32(fn) << rp.IO_BANK0_GPIO0_CTRL_FUNCSEL_Pos)
+
+	// Pads are isolated from the peripherals after a reset, which keeps the
+	// pad state while the chip is in a low-power state. Remove the isolation
+	// now that the function has been selected.
+	p.padCtrl().ClearBits(rp.PADS_BANK0_GPIO0_ISO)
+}
+
+// init initializes the gpio pin
+func (p Pin) init() {
+	mask := uint32(1) << p
+	rp.SIO.GPIO_OE_CLR.Set(mask)
+	p.clr()
+}
+
+// Configure configures the gpio pin as per mode.
+func (p Pin) Configure(config PinConfig) {
+	p.init()
+	mask := uint32(1) << p
+	switch config.Mode {
+	case PinOutput:
+		p.setFunc(fnSIO)
+		rp.SIO.GPIO_OE_SET.Set(mask)
+	case PinInput:
+		p.setFunc(fnSIO)
+		p.pulloff()
+	case PinInputPulldown:
+		p.setFunc(fnSIO)
+		p.pulldown()
+	case PinInputPullup:
+		p.setFunc(fnSIO)
+		p.pullup()
+	case PinAnalog:
+		p.setFunc(fnNULL)
+		p.pulloff()
+		// Disable the digital input, as recommended in the datasheet.
+		p.padCtrl().ClearBits(rp.PADS_BANK0_GPIO0_IE)
+	case PinUART:
+		p.setFunc(fnUART)
+	case PinPWM:
+		p.setFunc(fnPWM)
+	case PinI2C:
+		// IO config as recommended for I2C in the rp2350 datasheet.
+		p.setFunc(fnI2C)
+		p.pullup()
+		p.setSchmitt(true)
+		p.setSlew(false)
+	case PinSPI:
+		p.setFunc(fnSPI)
+	}
+	switch config.Pull {
+	case PinPullNone:
+		p.pulloff()
+	case PinPullUp:
+		p.pullup()
+	case PinPullDown:
+		p.pulldown()
+	}
+	if config.DriveStrength != 0 {
+		p.setDriveStrength(config.DriveStrength)
+	}
+}
+
+// Set drives the pin high if value is true else drives it low.
+func (p Pin) Set(value bool) {
+	if value {
+		p.set()
+	} else {
+		p.clr()
+	}
+}
+
+// Get reads the pin value.
+func (p Pin) Get() bool {
+	return p.get()
+}
+
+// PinChange represents one or more trigger events that can happen on a given GPIO pin
+// on the RP2350. ORed PinChanges are valid input to most IRQ functions.
+type PinChange uint8
+
+// Pin change interrupt constants for SetInterrupt.
+const (
+	// Edge falling
+	PinFalling PinChange = 4 << iota
+	// Edge rising
+	PinRising
+)
+
+// Callbacks to be called for pins configured with SetInterrupt.
+var (
+	pinCallbacks [2][_NUMBANK0_GPIOS]func(Pin)
+	setInt       [2][_NUMBANK0_GPIOS]bool
+)
+
+// SetInterrupt sets an interrupt to be executed when a particular pin changes
+// state. The pin should already be configured as an input, including a pull up
+// or down if no external pull is provided.
+//
+// This call will replace a previously set callback on this pin. You can pass a
+// nil func to unset the pin change interrupt. If you do so, the change
+// parameter is ignored and can be set to any value (such as 0).
+func (p Pin) SetInterrupt(change PinChange, callback func(Pin)) error {
+	if p >= _NUMBANK0_GPIOS {
+		return ErrInvalidInputPin
+	}
+	core := CurrentCore()
+	if callback == nil {
+		// disable current interrupt
+		p.setInterrupt(change, false)
+		pinCallbacks[core][p] = nil
+		return nil
+	}
+
+	if pinCallbacks[core][p] != nil {
+		// Callback already configured. Should disable callback by passing a nil callback first.
+		return ErrNoPinChangeChannel
+	}
+	p.setInterrupt(change, true)
+	pinCallbacks[core][p] = callback
+
+	if setInt[core][p] {
+		// interrupt has already been set. Exit.
+		return nil
+	}
+	setInt[core][p] = true
+	interrupt.New(rp.IRQ_IO_IRQ_BANK0, gpioHandleInterrupt).Enable()
+	return nil
+}
+
+// gpioHandleInterrupt finds the corresponding pin for the interrupt.
+// C SDK equivalent of gpio_irq_handler
+func gpioHandleInterrupt(intr interrupt.Interrupt) {
+	core := CurrentCore()
+	base := irqCtrlForCore(core)
+	var gpio Pin
+	for gpio = 0; gpio < _NUMBANK0_GPIOS; gpio++ {
+		statreg := &base.intS[gpio>>3]
+		change := getIntChange(gpio, statreg.Get())
+		if change != 0 {
+			gpio.acknowledgeInterrupt(change)
+			callback := pinCallbacks[core][gpio]
+			if callback != nil {
+				callback(gpio)
+			}
+		}
+	}
+}
+
+// irqCtrlForCore returns the interrupt registers for the given core.
+func irqCtrlForCore(core int) *irqCtrl {
+	if core == 1 {
+		return &ioBank0.proc1IRQctrl
+	}
+	return &ioBank0.proc0IRQctrl
+}
+
+// Clears interrupt flag on a pin
+func (p Pin) acknowledgeInterrupt(change PinChange) {
+	ioBank0.intR[p>>3].Set(p.ioIntBit(change))
+}
+
+// Basic interrupt setting via ioBANK0 for GPIO interrupts. There are separate
+// interrupt enable registers for each core, so this only affects the core that
+// calls it.
+func (p Pin) setInterrupt(change PinChange, enabled bool) {
+	p.acknowledgeInterrupt(change)
+	enReg := &irqCtrlForCore(CurrentCore()).intE[p>>3]
+	if enabled {
+		enReg.SetBits(p.ioIntBit(change))
+	} else {
+		enReg.ClearBits(p.ioIntBit(change))
+	}
+}
+
+// events returns the bit representation of the pin change for the rp2350.
+func (change PinChange) events() uint32 {
+	return uint32(change)
+}
+
+// intBit is the bit storage form of a PinChange for a given Pin
+// in the IO_BANK0 interrupt registers.
+func (p Pin) ioIntBit(change PinChange) uint32 {
+	return change.events() << (4 * (p % 8))
+}
+
+// Acquire interrupt data from a INT status register.
+func getIntChange(p Pin, status uint32) PinChange {
+	return PinChange(status>>(4*(p%8))) & 0xf
+}
+
+//go:inline
+func boolToBit(a bool) uint32 {
+	if a {
+		return 1
+	}
+	return 0
+}
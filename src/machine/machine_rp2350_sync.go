@@ -0,0 +1,17 @@
+//go:build rp2350
+// +build rp2350
+
+package machine
+
+// The SIO spinlocks of the RP2350 can be released by unrelated writes to the
+// SIO (erratum RP2350-E2), so like the Pico SDK a spinlock in memory is used
+// instead. The Cortex-M33 has exclusive load and store instructions for it.
+var criticalSectionLock atomicSpinlock
+
+func (cs *CriticalSection) lock() {
+	cs.lockWith(&criticalSectionLock, uint8(CurrentCore())+1)
+}
+
+func (cs *CriticalSection) unlock() {
+	cs.unlockWith(&criticalSectionLock)
+}
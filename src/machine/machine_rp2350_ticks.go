@@ -0,0 +1,38 @@
+//go:build rp2350
+// +build rp2350
+
+package machine
+
+import (
+	"device/rp"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// tickGenerator divides clkRef down to the 1MHz tick used by a peripheral.
+type tickGenerator struct {
+	ctrl   volatile.Register32
+	cycles volatile.Register32
+	count  volatile.Register32
+}
+
+type ticksType struct {
+	proc0    tickGenerator
+	proc1    tickGenerator
+	timer0   tickGenerator
+	timer1   tickGenerator
+	watchdog tickGenerator
+	riscv    tickGenerator
+}
+
+var tickGenerators = (*ticksType)(unsafe.Pointer(rp.TICKS))
+
+// start starts the tick generator.
+// cycles needs to be a divider that when applied to the xosc input,
+// produces a 1MHz clock. So if the xosc frequency is 12MHz,
+// this will need to be 12.
+func (tg *tickGenerator) start(cycles uint32) {
+	tg.ctrl.ClearBits(rp.TICKS_TIMER0_CTRL_ENABLE)
+	tg.cycles.Set(cycles)
+	tg.ctrl.SetBits(rp.TICKS_TIMER0_CTRL_ENABLE)
+}
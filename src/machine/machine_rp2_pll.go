@@ -1,5 +1,5 @@
-//go:build rp2040
-// +build rp2040
+//go:build rp2040 || rp2350
+// +build rp2040 rp2350
 
 package machine
 
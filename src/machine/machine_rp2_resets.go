@@ -1,5 +1,5 @@
-//go:build rp2040
-// +build rp2040
+//go:build rp2040 || rp2350
+// +build rp2040 rp2350
 
 package machine
 
@@ -9,11 +9,6 @@ import (
 	"unsafe"
 )
 
-// RESETS_RESET_Msk is bitmask to reset all peripherals
-//
-// TODO: This field is not available in the device file.
-const RESETS_RESET_Msk = 0x01ffffff
-
 type resetsType struct {
 	reset     volatile.Register32
 	wdSel     volatile.Register32
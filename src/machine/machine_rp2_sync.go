@@ -0,0 +1,58 @@
+//go:build rp2040 || rp2350
+// +build rp2040 rp2350
+
+package machine
+
+import (
+	"runtime/volatile"
+	"sync/atomic"
+)
+
+// spinlock is a lock that is shared between all cores. It only protects the
+// owner field of a CriticalSection, for a few instructions at a time. A
+// successful tryLock must act as an acquire barrier and unlock as a release
+// barrier, so that memory accesses inside the critical section are not moved
+// before or after it.
+type spinlock interface {
+	tryLock() bool
+	unlock()
+}
+
+// lockWith waits until no other core holds the critical section, and marks it
+// as held by the given core (the core number plus one). The spinlock is
+// released right away, so that different critical sections can be nested.
+func (cs *CriticalSection) lockWith(lock spinlock, core uint8) {
+	for {
+		for !lock.tryLock() {
+		}
+		if volatile.LoadUint8(&cs.owner) == 0 {
+			volatile.StoreUint8(&cs.owner, core)
+			lock.unlock()
+			return
+		}
+		lock.unlock()
+	}
+}
+
+// unlockWith releases the critical section, so that other cores can enter it.
+func (cs *CriticalSection) unlockWith(lock spinlock) {
+	for !lock.tryLock() {
+	}
+	volatile.StoreUint8(&cs.owner, 0)
+	lock.unlock()
+}
+
+// atomicSpinlock is a spinlock in memory, for chips with exclusive load and
+// store instructions. The atomic operations are sequentially consistent, so
+// they include the needed barriers.
+type atomicSpinlock struct {
+	locked uint32
+}
+
+func (l *atomicSpinlock) tryLock() bool {
+	return atomic.CompareAndSwapUint32(&l.locked, 0, 1)
+}
+
+func (l *atomicSpinlock) unlock() {
+	atomic.StoreUint32(&l.locked, 0)
+}
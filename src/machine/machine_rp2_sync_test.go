@@ -0,0 +1,116 @@
+//go:build rp2040 || rp2350
+// +build rp2040 rp2350
+
+package machine
+
+import (
+	"runtime"
+	"testing"
+)
+
+// These tests are only compiled by the smoketest (-target=pico and
+// -target=pico2). There is no emulator to run them on.
+
+// yieldingSpinlock lets other goroutines run on every access to the spinlock,
+// so that they interleave as much as possible.
+type yieldingSpinlock struct {
+	atomicSpinlock
+}
+
+func (l *yieldingSpinlock) tryLock() bool {
+	runtime.Gosched()
+	return l.atomicSpinlock.tryLock()
+}
+
+func (l *yieldingSpinlock) unlock() {
+	runtime.Gosched()
+	l.atomicSpinlock.unlock()
+}
+
+func TestCriticalSectionInterleaved(t *testing.T) {
+	// Two goroutines, pretending to run on different cores, enter the same
+	// critical section many times. They only interleave at the Gosched calls
+	// and don't really run in parallel, so this checks the locking protocol
+	// and not the memory barriers. Another critical section is nested inside.
+	var (
+		lock          yieldingSpinlock
+		outer, nested CriticalSection
+		inside        [2]int
+		entered       [2]int
+	)
+	const iterations = 100
+	done := make(chan uint8)
+	for core := uint8(0); core < 2; core++ {
+		go func(core uint8) {
+			for i := 0; i < iterations; i++ {
+				outer.lockWith(&lock, core+1)
+				inside[core]++
+				if inside[0]+inside[1] != 1 {
+					t.Errorf("core %d entered the critical section held by core %d", core, 1-core)
+				}
+				if outer.owner != core+1 {
+					t.Errorf("core %d holds a critical section owned by %d", core, outer.owner)
+				}
+				nested.lockWith(&lock, core+1)
+				runtime.Gosched()
+				nested.unlockWith(&lock)
+				entered[core]++
+				inside[core]--
+				outer.unlockWith(&lock)
+			}
+			done <- core
+		}(core)
+	}
+	<-done
+	<-done
+
+	if entered != [2]int{iterations, iterations} {
+		t.Errorf("entered the critical section %d times, want %d for both cores", entered, iterations)
+	}
+	if outer.owner != 0 || nested.owner != 0 || lock.locked != 0 {
+		t.Errorf("locks still held: owners %d and %d, spinlock %d", outer.owner, nested.owner, lock.locked)
+	}
+}
+
+func TestCriticalSectionWaits(t *testing.T) {
+	// A core that tries to enter a critical section held by another core
+	// waits until it is released. The goroutine pretends to be core 1.
+	var (
+		lock yieldingSpinlock
+		cs   CriticalSection
+	)
+	cs.lockWith(&lock, 1)
+	entered := make(chan struct{})
+	go func() {
+		cs.lockWith(&lock, 2)
+		close(entered)
+	}()
+	for i := 0; i < 10; i++ {
+		runtime.Gosched()
+	}
+	select {
+	case <-entered:
+		t.Fatal("core 1 entered the critical section held by core 0")
+	default:
+	}
+	cs.unlockWith(&lock)
+	<-entered
+	if cs.owner != 2 {
+		t.Errorf("critical section owned by %d, want core 1", cs.owner)
+	}
+}
+
+func TestCriticalSectionLock(t *testing.T) {
+	// Different critical sections may be nested.
+	var a, b CriticalSection
+	a.Lock()
+	b.Lock()
+	if a.owner == 0 || b.owner == 0 {
+		t.Error("critical section not held after Lock")
+	}
+	b.Unlock()
+	a.Unlock()
+	if a.owner != 0 || b.owner != 0 {
+		t.Error("critical section still held after Unlock")
+	}
+}
@@ -1,5 +1,5 @@
-//go:build rp2040
-// +build rp2040
+//go:build rp2040 || rp2350
+// +build rp2040 rp2350
 
 package machine
 
@@ -8,11 +8,13 @@ import (
 	"runtime/interrupt"
 )
 
-// UART on the RP2040.
+// UART on the RP2040 and RP2350.
 type UART struct {
 	Buffer    *RingBuffer
 	Bus       *rp.UART0_Type
 	Interrupt interrupt.Interrupt
+
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 // Configure the UART.
@@ -23,6 +25,7 @@ func (uart *UART) Configure(config UARTConfig) error {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	// Use default pins if pins are not set.
 	if config.TX == 0 && config.RX == 0 {
@@ -57,7 +60,8 @@ func (uart *UART) Configure(config UARTConfig) error {
 
 // SetBaudRate sets the baudrate to be used for the UART.
 func (uart *UART) SetBaudRate(br uint32) {
-	div := 8 * 125 * MHz / br
+	// The UART is clocked by clkPeri, which runs at the CPU frequency.
+	div := 8 * CPUFrequency() / br
 
 	ibrd := div >> 7
 	var fbrd uint32
@@ -85,7 +89,11 @@ func (uart *UART) SetBaudRate(br uint32) {
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
 	// wait until buffer is not full
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for uart.Bus.UARTFR.HasBits(rp.UART0_UARTFR_TXFF) {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
 
 	// write data
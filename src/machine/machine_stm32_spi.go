@@ -59,7 +59,10 @@ func (spi SPI) Configure(config SPIConfig) {
 	spi.configurePins(config)
 
 	// Get SPI baud rate based on the bus speed it's attached to
-	var conf uint32 = spi.getBaudRate(config)
+	if config.Frequency == 0 {
+		config.Frequency = 4e6
+	}
+	var conf uint32 = spi.getBaudRate(config.Frequency)
 
 	// set bit transfer order
 	if config.LSBFirst {
@@ -96,6 +99,24 @@ func (spi SPI) Configure(config SPIConfig) {
 	spi.Bus.CR1.SetBits(stm32.SPI_CR1_SPE)
 }
 
+// ActualFrequency returns the SPI clock frequency that was configured: the
+// clock of the bus the SPI peripheral is attached to, divided by the baud rate
+// prescaler.
+func (spi SPI) ActualFrequency() uint32 {
+	br := (spi.Bus.CR1.Get() & stm32.SPI_CR1_BR_Msk) >> stm32.SPI_CR1_BR_Pos
+	return spi.clockFrequency() >> (br + 1)
+}
+
+// getBaudRate returns the SPI_CR1 BR bits for the fastest SPI clock that is not
+// faster than freq. The prescaler is a power of two between 2 and 256, so
+// frequencies below clock/256 will result in clock/256.
+func (spi SPI) getBaudRate(freq uint32) uint32 {
+	// The BR bits store the log2 of the divider minus one, as the least
+	// divider is 2.
+	br := spiClockShift(spi.clockFrequency(), freq, 8) - 1
+	return uint32(br) << stm32.SPI_CR1_BR_Pos
+}
+
 // Transfer writes/reads a single byte using the SPI interface.
 func (spi SPI) Transfer(w byte) (byte, error) {
 
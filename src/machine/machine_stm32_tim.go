@@ -136,7 +136,7 @@ func (t *TIM) setPeriod(period uint64, updatePrescaler bool) error {
 	if period == 0 {
 		top = ARR_MAX
 	} else {
-		top = (period / 1000) * (t.busFreq / 1000) / 1000
+		top = (period / 1000) * (t.clockFrequency() / 1000) / 1000
 	}
 
 	var psc uint64
@@ -339,3 +339,13 @@ func (t *TIM) channelCCMR(channel uint8) (reg *volatile.Register32, offset uint8
 func ceil(num uint64, denom uint64) uint64 {
 	return (num + denom - 1) / denom
 }
+
+// clockFrequency returns the frequency of the timer clock. It is derived from
+// the core clock through the AHB and APB prescalers, so it changes with the
+// core clock frequency set with SetTickFrequency.
+func (t *TIM) clockFrequency() uint64 {
+	if coreClockFrequency == 0 {
+		return t.busFreq
+	}
+	return t.busFreq * uint64(coreClockFrequency) / uint64(CPUFrequency())
+}
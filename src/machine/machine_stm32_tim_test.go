@@ -0,0 +1,48 @@
+//go:build stm32
+// +build stm32
+
+package machine
+
+import (
+	"device/stm32"
+	"runtime/volatile"
+	"testing"
+)
+
+// The timer is a fake in RAM. This test is only compiled by the smoketest
+// (-target=bluepill and stm32f4disco), it doesn't run on an emulator.
+
+func TestTIMClockFrequency(t *testing.T) {
+	defer func(hz uint32) {
+		coreClockFrequency = hz
+	}(coreClockFrequency)
+
+	tim := &TIM{
+		EnableRegister: new(volatile.Register32),
+		EnableFlag:     1,
+		Device:         new(stm32.TIM_Type),
+		busFreq:        64e6,
+	}
+	// A period of 10ms is 640000 cycles of the 64MHz timer clock.
+	coreClockFrequency = 0
+	if err := tim.Configure(PWMConfig{Period: 10e6}); err != nil {
+		t.Fatal("Configure:", err)
+	}
+	cycles := (tim.Device.PSC.Get() + 1) * (tim.Device.ARR.Get() + 1)
+	if cycles != 640000 {
+		t.Errorf("period is %d timer cycles, want 640000", cycles)
+	}
+
+	// When the core clock runs at half the speed, so does the timer clock.
+	coreClockFrequency = CPUFrequency() / 2
+	if hz := tim.clockFrequency(); hz != 32e6 {
+		t.Errorf("timer clock is %dHz, want 32MHz", hz)
+	}
+	if err := tim.Configure(PWMConfig{Period: 10e6}); err != nil {
+		t.Fatal("Configure:", err)
+	}
+	cycles = (tim.Device.PSC.Get() + 1) * (tim.Device.ARR.Get() + 1)
+	if cycles != 320000 {
+		t.Errorf("period is %d timer cycles at half the core clock, want 320000", cycles)
+	}
+}
@@ -21,10 +21,11 @@ type UART struct {
 	RxAltFuncSelector uint8
 
 	// Registers specific to the chip
-	rxReg       *volatile.Register32
-	txReg       *volatile.Register32
-	statusReg   *volatile.Register32
-	txEmptyFlag uint32
+	rxReg        *volatile.Register32
+	txReg        *volatile.Register32
+	statusReg    *volatile.Register32
+	txEmptyFlag  uint32
+	writeTimeout uint64 // see UARTConfig.WriteTimeout
 }
 
 // Configure the UART.
@@ -33,6 +34,7 @@ func (uart *UART) Configure(config UARTConfig) {
 	if config.BaudRate == 0 {
 		config.BaudRate = 115200
 	}
+	uart.writeTimeout = config.WriteTimeout
 
 	// Set the GPIO pins to defaults if they're not set
 	if config.TX == 0 && config.RX == 0 {
@@ -76,9 +78,14 @@ func (uart *UART) SetBaudRate(br uint32) {
 
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
-	uart.txReg.Set(uint32(c))
-
+	// Wait until the transmit data register is empty.
+	deadline := uartWriteDeadline(uart.writeTimeout)
 	for !uart.statusReg.HasBits(uart.txEmptyFlag) {
+		if uartDeadlinePassed(deadline) {
+			return ErrUARTWriteTimeout
+		}
 	}
+
+	uart.txReg.Set(uint32(c))
 	return nil
 }
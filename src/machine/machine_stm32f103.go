@@ -335,30 +335,12 @@ func (spi SPI) config8Bits() {
 	// no-op on this series
 }
 
-// Set baud rate for SPI
-func (spi SPI) getBaudRate(config SPIConfig) uint32 {
-	var conf uint32
-
-	// set frequency dependent on PCLK2 prescaler (div 1)
-	switch {
-	case config.Frequency < 125000:
-		// Note: impossible to achieve lower frequency with current PCLK2!
-		conf |= stm32.SPI_CR1_BR_Div256
-	case config.Frequency < 250000:
-		conf |= stm32.SPI_CR1_BR_Div256
-	case config.Frequency < 500000:
-		conf |= stm32.SPI_CR1_BR_Div128
-	case config.Frequency < 1000000:
-		conf |= stm32.SPI_CR1_BR_Div64
-	case config.Frequency < 2000000:
-		conf |= stm32.SPI_CR1_BR_Div32
-	case config.Frequency < 4000000:
-		conf |= stm32.SPI_CR1_BR_Div16
-	default:
-		// When its bigger than Div16, just round to the maximum frequency.
-		conf |= stm32.SPI_CR1_BR_Div8
+// clockFrequency returns the frequency of the peripheral clock of this SPI bus.
+func (spi SPI) clockFrequency() uint32 {
+	if spi.Bus == stm32.SPI1 {
+		return CPUFrequency() // APB2 (PCLK2 = HCLK)
 	}
-	return conf << stm32.SPI_CR1_BR_Pos
+	return CPUFrequency() / 2 // APB1 (PCLK1 = HCLK/2)
 }
 
 // Configure SPI pins for input output and clock
@@ -237,54 +237,10 @@ func (spi SPI) config8Bits() {
 	// no-op on this series
 }
 
-// Set baud rate for SPI
-func (spi SPI) getBaudRate(config SPIConfig) uint32 {
-	var conf uint32
-
-	localFrequency := config.Frequency
-
-	// Default
-	if config.Frequency == 0 {
-		config.Frequency = 4e6
-	}
-
-	if spi.Bus != stm32.SPI1 {
-		// Assume it's SPI2 or SPI3 on APB1 at 1/2 the clock frequency of APB2, so
-		//  we want to pretend to request 2x the baudrate asked for
-		localFrequency = localFrequency * 2
-	}
-
-	// set frequency dependent on PCLK prescaler. Since these are rather weird
-	// speeds due to the CPU freqency, pick a range up to that frquency for
-	// clients to use more human-understandable numbers, e.g. nearest 100KHz
-
-	// These are based on APB2 clock frquency (84MHz on the discovery board)
-	// TODO: also include the MCU/APB clock setting in the equation
-	switch {
-	case localFrequency < 328125:
-		conf = stm32.SPI_CR1_BR_Div256
-	case localFrequency < 656250:
-		conf = stm32.SPI_CR1_BR_Div128
-	case localFrequency < 1312500:
-		conf = stm32.SPI_CR1_BR_Div64
-	case localFrequency < 2625000:
-		conf = stm32.SPI_CR1_BR_Div32
-	case localFrequency < 5250000:
-		conf = stm32.SPI_CR1_BR_Div16
-	case localFrequency < 10500000:
-		conf = stm32.SPI_CR1_BR_Div8
-		// NOTE: many SPI components won't operate reliably (or at all) above 10MHz
-		// Check the datasheet of the part
-	case localFrequency < 21000000:
-		conf = stm32.SPI_CR1_BR_Div4
-	case localFrequency < 42000000:
-		conf = stm32.SPI_CR1_BR_Div2
-	default:
-		// None of the specific baudrates were selected; choose the lowest speed
-		conf = stm32.SPI_CR1_BR_Div256
-	}
-
-	return conf << stm32.SPI_CR1_BR_Pos
+// clockFrequency returns the frequency of the peripheral clock of this SPI bus.
+// Both APB1 (SPI2) and APB2 (SPI1) run at half the CPU frequency.
+func (spi SPI) clockFrequency() uint32 {
+	return CPUFrequency() / 2
 }
 
 // Configure SPI pins for input output and clock
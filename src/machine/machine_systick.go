@@ -0,0 +1,38 @@
+//go:build mimxrt1062 || (nxp && mk66f18) || stm32
+// +build mimxrt1062 nxp,mk66f18 stm32
+
+package machine
+
+import (
+	"runtime/interrupt"
+	_ "unsafe" // for go:linkname
+)
+
+// SetTickFrequency tells the runtime that the core clock now runs at the given
+// frequency in Hz. The timer used for time keeping is derived from the core
+// clock, which the runtime assumes to run at the frequency returned by
+// CPUFrequency. Call this after changing the clock configuration to keep
+// time.Now and time.Sleep accurate. The time may jump forward by up to one
+// tick interrupt period of the runtime (1ms, or 10ms on the STM32).
+//
+// On the STM32 the timer clock is derived from the core clock through the AHB
+// and APB prescalers, which must not have been changed. Only the time keeping
+// is updated: other peripherals, like the UART baud rate, still assume the
+// frequency returned by CPUFrequency.
+//
+// This is only needed on the targets where it is defined. The other targets
+// keep time with a timer running from a fixed clock, like the 32.768kHz RTC
+// on the nRF and SAMD chips or the 1MHz timer on the RP2040.
+func SetTickFrequency(hz uint32) {
+	mask := interrupt.Disable()
+	coreClockFrequency = hz
+	setTickFrequency(hz)
+	interrupt.Restore(mask)
+}
+
+// coreClockFrequency is the frequency set with SetTickFrequency, or zero if
+// the core clock runs at CPUFrequency.
+var coreClockFrequency uint32
+
+//go:linkname setTickFrequency runtime.setTickFrequency
+func setTickFrequency(hz uint32)
@@ -0,0 +1,47 @@
+//go:build mimxrt1062 || (nxp && mk66f18) || stm32
+// +build mimxrt1062 nxp,mk66f18 stm32
+
+package machine
+
+import (
+	"testing"
+	"time"
+)
+
+// These tests need the real SysTick timer, but there is no emulator for these
+// chips. They are only compiled by the smoketest.
+
+func TestSetTickFrequency(t *testing.T) {
+	defer func() {
+		coreClockFrequency = 0
+	}()
+
+	// The core clock doesn't change, so after the runtime reconfigured its
+	// timer the time runs at the same rate as before. It may jump forward by
+	// one tick interrupt period, but never backwards.
+	before := time.Now()
+	SetTickFrequency(CPUFrequency())
+	if elapsed := time.Since(before); elapsed < 0 || elapsed > 20*time.Millisecond {
+		t.Errorf("SetTickFrequency moved the time by %s", elapsed)
+	}
+
+	start := time.Now()
+	time.Sleep(50 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 70*time.Millisecond {
+		t.Errorf("time.Sleep(50ms) took %s", elapsed)
+	}
+}
+
+func TestTimeResolution(t *testing.T) {
+	// The time between tick interrupts is computed from a cycle counter, so
+	// two calls to time.Now a few microseconds apart differ by less than a
+	// millisecond, but not by zero.
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+		for time.Since(start) == 0 {
+		}
+		if elapsed := time.Since(start); elapsed <= 0 || elapsed >= time.Millisecond {
+			t.Errorf("time.Now advanced by %s, want less than a millisecond", elapsed)
+		}
+	}
+}
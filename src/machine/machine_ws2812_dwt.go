@@ -0,0 +1,66 @@
+//go:build nrf52 || nrf52840 || nrf52833 || (sam && atsamd51) || (sam && atsame5x) || stm32f4
+// +build nrf52 nrf52840 nrf52833 sam,atsamd51 sam,atsame5x stm32f4
+
+package machine
+
+import (
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// WS2812 drives a strip of WS2812 (NeoPixel) LEDs connected to a single pin.
+//
+// On this chip the waveform is bit-banged, using the DWT cycle counter of the
+// Cortex-M core to time every edge. Interrupts are disabled while sending, for
+// about 30µs per LED.
+type WS2812 struct {
+	Pin Pin
+}
+
+var (
+	// Debug Exception and Monitor Control Register, for TRCENA.
+	ws2812DEMCR = (*volatile.Register32)(unsafe.Pointer(uintptr(0xe000edfc)))
+	// DWT control register and cycle counter.
+	ws2812DWTCtrl   = (*volatile.Register32)(unsafe.Pointer(uintptr(0xe0001000)))
+	ws2812DWTCycCnt = (*volatile.Register32)(unsafe.Pointer(uintptr(0xe0001004)))
+)
+
+const (
+	demcrTRCENA      = 1 << 24
+	dwtCtrlCYCCNTENA = 1 << 0
+)
+
+// Configure sets the pin to output low and enables the cycle counter.
+func (ws *WS2812) Configure() error {
+	ws.Pin.Configure(PinConfig{Mode: PinOutput})
+	ws.Pin.Low()
+	ws2812DEMCR.SetBits(demcrTRCENA)
+	ws2812DWTCtrl.SetBits(dwtCtrlCYCCNTENA)
+	return nil
+}
+
+// Write sends the given buffer to the LED strip. The buffer contains the raw
+// bytes in the order the LEDs expect them, which is green, red, blue for every
+// LED (followed by white for RGBW LEDs). Write returns when all bits have been
+// sent. The LEDs latch the new colors when the line stays low for at least
+// 280µs after that, so wait at least that long before calling Write again.
+func (ws *WS2812) Write(buf []byte) (n int, err error) {
+	t0h, t1h, period := ws2812Cycles(CPUFrequency())
+	setReg, setMask := ws.Pin.PortMaskSet()
+	clearReg, clearMask := ws.Pin.PortMaskClear()
+	set := (*volatile.Register32)(unsafe.Pointer(setReg))
+	clear := (*volatile.Register32)(unsafe.Pointer(clearReg))
+
+	mask := interrupt.Disable()
+	ws2812Send(dwtClock{}, set, clear, setMask, clearMask, buf, t0h, t1h, period)
+	interrupt.Restore(mask)
+	return len(buf), nil
+}
+
+// dwtClock reads the DWT cycle counter.
+type dwtClock struct{}
+
+func (dwtClock) now() uint32 {
+	return ws2812DWTCycCnt.Get()
+}
@@ -0,0 +1,11 @@
+//go:build baremetal && !nrf && !rp2040 && !rp2350
+// +build baremetal,!nrf,!rp2040,!rp2350
+
+package machine
+
+// The pull resistor and drive strength of PinConfig are not supported, they
+// are ignored by Pin.Configure.
+const (
+	hasPinPull          = false
+	maxPinDriveStrength = PinDriveStrength(0)
+)
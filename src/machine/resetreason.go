@@ -0,0 +1,74 @@
+//go:build nrf || (sam && atsamd21) || (sam && atsamd51) || (sam && atsame5x) || rp2040 || avr || !baremetal
+// +build nrf sam,atsamd21 sam,atsamd51 sam,atsame5x rp2040 avr !baremetal
+
+package machine
+
+// ResetReason is the cause of the last reset of the chip, as returned by
+// GetResetReason.
+type ResetReason uint8
+
+const (
+	// The cause of the reset is not known.
+	ResetReasonUnknown ResetReason = iota
+
+	// The chip was powered on.
+	ResetReasonPowerOn
+
+	// The reset pin was pulled low.
+	ResetReasonExternal
+
+	// The watchdog timer expired.
+	ResetReasonWatchdog
+
+	// The supply voltage dropped below the brownout level.
+	ResetReasonBrownout
+
+	// The program requested a reset, for example with arm.SystemReset.
+	ResetReasonSoftware
+
+	// The CPU locked up, for example after a fault in a fault handler.
+	ResetReasonLockup
+
+	// The chip woke up from a deep sleep mode (like System OFF on the nRF).
+	ResetReasonWakeup
+)
+
+// String returns a short description of the reset reason, like "watchdog".
+func (r ResetReason) String() string {
+	switch r {
+	case ResetReasonPowerOn:
+		return "power-on"
+	case ResetReasonExternal:
+		return "external"
+	case ResetReasonWatchdog:
+		return "watchdog"
+	case ResetReasonBrownout:
+		return "brownout"
+	case ResetReasonSoftware:
+		return "software"
+	case ResetReasonLockup:
+		return "lockup"
+	case ResetReasonWakeup:
+		return "wakeup"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	resetReason     ResetReason
+	resetReasonRead bool
+)
+
+// GetResetReason returns the cause of the last reset of the chip.
+//
+// The first call reads the reset status register of the chip, and clears it
+// where the hardware allows this so that the next reset has a clean status.
+// Later calls return the same value.
+func GetResetReason() ResetReason {
+	if !resetReasonRead {
+		resetReason = readResetReason()
+		resetReasonRead = true
+	}
+	return resetReason
+}
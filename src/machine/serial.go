@@ -1,9 +1,16 @@
 package machine
 
-import "errors"
+import (
+	"errors"
+	_ "unsafe" // for go:linkname
+)
 
 var errNoByte = errors.New("machine: no byte read")
 
+// ErrUARTWriteTimeout is returned by UART writes when there was no room in the
+// transmit buffer within the WriteTimeout set in UARTConfig.
+var ErrUARTWriteTimeout = errors.New("machine: UART write timeout")
+
 // UARTConfig is a struct with which a UART (or similar object) can be
 // configured. The baud rate is usually respected, but TX and RX may be ignored
 // depending on the chip and the type of object.
@@ -11,8 +18,32 @@ type UARTConfig struct {
 	BaudRate uint32
 	TX       Pin
 	RX       Pin
+
+	// WriteTimeout is how long (in nanoseconds) a write waits for room in the
+	// transmit buffer before giving up. Write then returns the number of bytes
+	// written so far together with ErrUARTWriteTimeout. The default (zero) is
+	// to wait forever.
+	WriteTimeout uint64
+}
+
+// uartWriteDeadline returns the time (as returned by nanotime) at which a UART
+// write with the given timeout should give up, or 0 if it should wait forever.
+func uartWriteDeadline(timeout uint64) int64 {
+	if timeout == 0 {
+		return 0
+	}
+	return nanotime() + int64(timeout)
 }
 
+// uartDeadlinePassed returns whether the deadline returned by
+// uartWriteDeadline has passed.
+func uartDeadlinePassed(deadline int64) bool {
+	return deadline != 0 && nanotime() >= deadline
+}
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
 // NullSerial is a serial version of /dev/null (or null router): it drops
 // everything that is written to it.
 type NullSerial struct {
@@ -44,3 +75,96 @@ func (ns NullSerial) Buffered() int {
 func (ns NullSerial) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
+
+// SerialDevice is a serial device that can be used in a MultiSerial. It is
+// implemented by UARTs, USBCDC and NullSerial.
+type SerialDevice interface {
+	WriteByte(c byte) error
+	Write(data []byte) (n int, err error)
+	Buffered() int
+	ReadByte() (byte, error)
+}
+
+// MultiSerial is a serial device that sends all output to several serial
+// devices at once, for example to both USB-CDC and a UART during bring-up.
+// Input is read from the first device that has data available.
+//
+// It implements the Serialer interface, so it can be assigned to Serial when
+// Serial is an interface (with -serial=usb) to send the output of println to
+// all devices:
+//
+//	machine.DefaultUART.Configure(machine.UARTConfig{})
+//	machine.Serial = machine.MultiSerial{machine.USBCDC, machine.DefaultUART}
+type MultiSerial []SerialDevice
+
+// Configure does nothing: the devices usually need a different configuration,
+// so they must be configured individually.
+func (ms MultiSerial) Configure(config UARTConfig) error {
+	return nil
+}
+
+// WriteByte writes a byte to all devices. It returns the first error that
+// occurred, but always writes to all devices.
+func (ms MultiSerial) WriteByte(c byte) error {
+	var err error
+	for _, dev := range ms {
+		if e := dev.WriteByte(c); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Write writes the data to all devices. Like WriteByte, it returns the first
+// error that occurred. The returned count is always len(data): a device that
+// can't keep up shouldn't stop the others from receiving the output.
+func (ms MultiSerial) Write(data []byte) (n int, err error) {
+	for _, dev := range ms {
+		if _, e := dev.Write(data); e != nil && err == nil {
+			err = e
+		}
+	}
+	return len(data), err
+}
+
+// Buffered returns the number of bytes that can be read from all devices
+// together.
+func (ms MultiSerial) Buffered() int {
+	n := 0
+	for _, dev := range ms {
+		n += dev.Buffered()
+	}
+	return n
+}
+
+// ReadByte reads a byte from the first device that has data available.
+func (ms MultiSerial) ReadByte() (byte, error) {
+	for _, dev := range ms {
+		if dev.Buffered() != 0 {
+			return dev.ReadByte()
+		}
+	}
+	return 0, errNoByte
+}
+
+// DTR returns whether any of the devices that support it has DTR set. Devices
+// like UARTs that don't have a DTR signal are ignored.
+func (ms MultiSerial) DTR() bool {
+	for _, dev := range ms {
+		if dev, ok := dev.(interface{ DTR() bool }); ok && dev.DTR() {
+			return true
+		}
+	}
+	return false
+}
+
+// RTS returns whether any of the devices that support it has RTS set. Devices
+// like UARTs that don't have an RTS signal are ignored.
+func (ms MultiSerial) RTS() bool {
+	for _, dev := range ms {
+		if dev, ok := dev.(interface{ RTS() bool }); ok && dev.RTS() {
+			return true
+		}
+	}
+	return false
+}
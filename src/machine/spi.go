@@ -3,7 +3,10 @@
 
 package machine
 
-import "errors"
+import (
+	"errors"
+	"math/bits"
+)
 
 // SPI phase and polarity configs CPOL and CPHA
 const (
@@ -17,3 +20,22 @@ var (
 	ErrTxInvalidSliceSize      = errors.New("SPI write and read slices must be same size")
 	errSPIInvalidMachineConfig = errors.New("SPI port was not configured properly by the machine")
 )
+
+// spiClockDivider returns the smallest clock divider that results in an SPI
+// clock that is not faster than freq.
+func spiClockDivider(clock, freq uint32) uint32 {
+	return (clock + freq - 1) / freq
+}
+
+// spiClockShift returns the smallest n between 1 and max for which clock>>n is
+// not faster than freq, for peripherals that can only divide the clock by a
+// power of two. It returns max if even clock>>max is too fast.
+func spiClockShift(clock, freq uint32, max int) int {
+	n := bits.Len32(spiClockDivider(clock, freq) - 1)
+	if n < 1 {
+		n = 1
+	} else if n > max {
+		n = max
+	}
+	return n
+}
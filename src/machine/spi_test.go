@@ -0,0 +1,64 @@
+//go:build !baremetal || atmega || esp32 || fe310 || k210 || nrf || (nxp && !mk66f18) || rp2040 || sam || (stm32 && !stm32f7x2 && !stm32l5x2)
+// +build !baremetal atmega esp32 fe310 k210 nrf nxp,!mk66f18 rp2040 sam stm32,!stm32f7x2,!stm32l5x2
+
+package machine
+
+import "testing"
+
+func TestSPIClockDivider(t *testing.T) {
+	for _, tc := range []struct {
+		clock   uint32
+		freq    uint32
+		divider uint32
+	}{
+		// SAMD21 at 48MHz, where the SERCOM divides by 2*(BAUD+1): 5MHz isn't
+		// possible, the next slower frequency is 4.8MHz.
+		{48e6, 2 * 5e6, 5},
+		{48e6, 2 * 4e6, 6},
+		{48e6, 2 * 24e6, 1},
+		{48e6, 2 * 30e6, 1},
+		// K210 at 390MHz.
+		{390e6, 4e6, 98},
+		{390e6, 390e6, 1},
+	} {
+		divider := spiClockDivider(tc.clock, tc.freq)
+		if divider != tc.divider {
+			t.Errorf("%d Hz from %d Hz: divider %d, want %d", tc.freq, tc.clock, divider, tc.divider)
+		}
+		if tc.clock/divider > tc.freq {
+			t.Errorf("%d Hz from %d Hz: divider %d gives %d Hz, which is too fast", tc.freq, tc.clock, divider, tc.clock/divider)
+		}
+	}
+}
+
+func TestSPIClockShift(t *testing.T) {
+	for _, tc := range []struct {
+		clock  uint32
+		freq   uint32
+		max    int
+		shift  int
+		actual uint32
+	}{
+		// SPI1 of the STM32F4 at 84MHz (APB2): 5MHz isn't possible, the next
+		// slower frequency is 84MHz / 32.
+		{84e6, 5e6, 8, 5, 2625000},
+		{84e6, 42e6, 8, 1, 42e6},
+		{84e6, 100e6, 8, 1, 42e6},
+		{84e6, 21e6, 8, 2, 21e6},
+		{84e6, 20999999, 8, 3, 10.5e6},
+		{84e6, 100e3, 8, 8, 328125}, // the slowest possible frequency
+		// STM32L0 at 32MHz.
+		{32e6, 4e6, 8, 3, 4e6},
+		{32e6, 1e6, 8, 5, 1e6},
+		// ATmega328p at 16MHz, which divides by a power of two up to 128.
+		{16e6, 1e6, 7, 4, 1e6},
+		{16e6, 3e6, 7, 3, 2e6},
+		{16e6, 10e3, 7, 7, 125e3},
+	} {
+		shift := spiClockShift(tc.clock, tc.freq, tc.max)
+		actual := tc.clock >> shift
+		if shift != tc.shift || actual != tc.actual {
+			t.Errorf("%d Hz from %d Hz: shift %d (%d Hz), want %d (%d Hz)", tc.freq, tc.clock, shift, actual, tc.shift, tc.actual)
+		}
+	}
+}
@@ -1,5 +1,5 @@
-//go:build atmega || esp || nrf || sam || sifive || stm32 || k210 || nxp || rp2040
-// +build atmega esp nrf sam sifive stm32 k210 nxp rp2040
+//go:build atmega || esp || nrf || sam || sifive || stm32 || k210 || nxp || rp2040 || rp2350
+// +build atmega esp nrf sam sifive stm32 k210 nxp rp2040 rp2350
 
 package machine
 
@@ -60,10 +60,14 @@ func (uart *UART) Read(data []byte) (n int, err error) {
 	return size, nil
 }
 
-// Write data to the UART.
+// Write data to the UART. If a byte could not be written, for example because
+// the WriteTimeout set in UARTConfig passed, it returns the number of bytes
+// written before it and the error.
 func (uart *UART) Write(data []byte) (n int, err error) {
-	for _, v := range data {
-		uart.WriteByte(v)
+	for i, v := range data {
+		if err := uart.WriteByte(v); err != nil {
+			return i, err
+		}
 	}
 	return len(data), nil
 }
@@ -0,0 +1,60 @@
+package machine
+
+import "runtime/volatile"
+
+// Helpers to bit-bang the WS2812 waveform, see machine_ws2812_dwt.go. They
+// don't depend on the chip, so that they can be tested on the host.
+
+// WS2812 bit timings in nanoseconds. The high time for a zero and a one bit is
+// the middle of the range accepted by both the original WS2812 and the newer
+// WS2812B.
+const (
+	ws2812T0H    = 400
+	ws2812T1H    = 800
+	ws2812Period = 1250
+)
+
+// ws2812Cycles converts the WS2812 bit timings to CPU cycles, rounding up.
+func ws2812Cycles(cpuFrequency uint32) (t0h, t1h, period uint32) {
+	freq := cpuFrequency / MHz
+	t0h = (ws2812T0H*freq + 999) / 1000
+	t1h = (ws2812T1H*freq + 999) / 1000
+	period = (ws2812Period*freq + 999) / 1000
+	return
+}
+
+// ws2812Clock is a free running counter that is read to time the edges. It is
+// used as a type parameter and not as an interface, so that reading the clock
+// is inlined in the loop in ws2812Send.
+type ws2812Clock interface {
+	now() uint32
+}
+
+// ws2812Send sends the bits in buf, MSB first, by writing setMask to set and
+// clearMask to clear to make the line high and low. The times are in ticks of
+// the clock.
+func ws2812Send[C ws2812Clock](clock C, set, clear *volatile.Register32, setMask, clearMask uint32, buf []byte, t0h, t1h, period uint32) {
+	// Pretend the previous bit started a full period ago, so the first bit
+	// starts right away.
+	start := clock.now() - period
+	for _, c := range buf {
+		for i := 0; i < 8; i++ {
+			high := t0h
+			if c&0x80 != 0 {
+				high = t1h
+			}
+			c <<= 1
+			// Wait for the end of the previous bit. The subtraction is
+			// correct even when the counter wraps around.
+			for clock.now()-start < period {
+			}
+			start = clock.now()
+			set.Set(setMask)
+			for clock.now()-start < high {
+			}
+			clear.Set(clearMask)
+		}
+	}
+	for clock.now()-start < period {
+	}
+}
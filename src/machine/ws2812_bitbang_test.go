@@ -0,0 +1,85 @@
+package machine
+
+import (
+	"runtime/volatile"
+	"testing"
+)
+
+func TestWS2812Cycles(t *testing.T) {
+	for _, tc := range []struct {
+		frequency        uint32
+		t0h, t1h, period uint32
+	}{
+		{64e6, 26, 52, 80},    // nRF52: 25.6, 51.2 and 80 cycles
+		{120e6, 48, 96, 150},  // SAMD51
+		{168e6, 68, 135, 210}, // STM32F4: 67.2, 134.4 and 210 cycles
+	} {
+		t0h, t1h, period := ws2812Cycles(tc.frequency)
+		if t0h != tc.t0h || t1h != tc.t1h || period != tc.period {
+			t.Errorf("%dMHz: got %d, %d, %d cycles; want %d, %d, %d", tc.frequency/1e6, t0h, t1h, period, tc.t0h, tc.t1h, tc.period)
+		}
+	}
+}
+
+// fakeWS2812Clock advances by one tick on every read, and records the writes
+// to the set and clear registers (in RAM) as edges of the waveform.
+type fakeWS2812Clock struct {
+	ticks      uint32
+	set, clear volatile.Register32
+	edges      []ws2812Edge
+}
+
+type ws2812Edge struct {
+	high bool
+	time uint32
+}
+
+func (c *fakeWS2812Clock) now() uint32 {
+	// A write happened between the previous read and this one.
+	if c.set.Get() != 0 {
+		c.set.Set(0)
+		c.edges = append(c.edges, ws2812Edge{true, c.ticks})
+	}
+	if c.clear.Get() != 0 {
+		c.clear.Set(0)
+		c.edges = append(c.edges, ws2812Edge{false, c.ticks})
+	}
+	c.ticks++
+	return c.ticks
+}
+
+func TestWS2812Waveform(t *testing.T) {
+	const t0h, t1h, period = 26, 52, 80
+	clock := &fakeWS2812Clock{ticks: 0xfffffff0} // check wrapping as well
+	buf := []byte{0xa5, 0x0f}
+	ws2812Send(clock, &clock.set, &clock.clear, 1<<3, 1<<3, buf, t0h, t1h, period)
+
+	if len(clock.edges) != 2*8*len(buf) {
+		t.Fatalf("got %d edges, want %d", len(clock.edges), 2*8*len(buf))
+	}
+	for i := 0; i < 8*len(buf); i++ {
+		rise, fall := clock.edges[2*i], clock.edges[2*i+1]
+		if !rise.high || fall.high {
+			t.Fatalf("bit %d: edges are not a rising edge followed by a falling edge", i)
+		}
+		want := uint32(t0h)
+		if buf[i/8]&(0x80>>(i%8)) != 0 {
+			want = t1h
+		}
+		if high := fall.time - rise.time; high != want {
+			t.Errorf("bit %d: high for %d ticks, want %d", i, high, want)
+		}
+		// Starting the next bit reads the clock once more, so with this
+		// clock a bit takes one tick longer than the period.
+		if i > 0 {
+			if bit := rise.time - clock.edges[2*i-2].time; bit < period || bit > period+1 {
+				t.Errorf("bit %d: took %d ticks, want %d", i-1, bit, period)
+			}
+		}
+	}
+	// The line stays low for at least the rest of the last bit.
+	last := clock.edges[len(clock.edges)-2].time
+	if clock.ticks-last < period {
+		t.Errorf("Write returned %d ticks after the start of the last bit, want at least %d", clock.ticks-last, period)
+	}
+}
@@ -0,0 +1,81 @@
+package maps_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var m1 = map[int]int{1: 2, 2: 4, 4: 8, 8: 16}
+
+func TestKeysValues(t *testing.T) {
+	keys := maps.Keys(m1)
+	slices.Sort(keys)
+	if want := []int{1, 2, 4, 8}; !slices.Equal(keys, want) {
+		t.Errorf("Keys = %v, want %v", keys, want)
+	}
+	values := maps.Values(m1)
+	slices.Sort(values)
+	if want := []int{2, 4, 8, 16}; !slices.Equal(values, want) {
+		t.Errorf("Values = %v, want %v", values, want)
+	}
+	if len(maps.Keys(map[string]bool(nil))) != 0 {
+		t.Error("Keys of a nil map is not empty")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	if !maps.Equal(m1, m1) {
+		t.Error("m1 is not equal to itself")
+	}
+	if !maps.Equal(map[int]int(nil), map[int]int{}) {
+		t.Error("nil map is not equal to an empty map")
+	}
+	m2 := map[int]int{1: 2, 2: 4, 4: 8, 8: 15}
+	if maps.Equal(m1, m2) {
+		t.Error("m1 is equal to a map with a different value")
+	}
+	m2 = map[int]int{1: 2, 2: 4, 4: 8}
+	if maps.Equal(m1, m2) {
+		t.Error("m1 is equal to a smaller map")
+	}
+
+	lower := map[int]string{1: "a", 2: "b"}
+	upper := map[int]string{1: "A", 2: "B"}
+	if !maps.EqualFunc(lower, upper, strings.EqualFold) {
+		t.Error("EqualFunc with EqualFold returned false")
+	}
+	if maps.EqualFunc(lower, upper, func(a, b string) bool { return a == b }) {
+		t.Error("EqualFunc with == returned true")
+	}
+}
+
+func TestCloneCopy(t *testing.T) {
+	if maps.Clone(map[int]int(nil)) != nil {
+		t.Error("Clone of a nil map is not nil")
+	}
+	c := maps.Clone(m1)
+	if !maps.Equal(c, m1) {
+		t.Errorf("Clone = %v, want %v", c, m1)
+	}
+	c[16] = 32
+	if _, ok := m1[16]; ok {
+		t.Error("Clone shares the map with the original")
+	}
+
+	dst := map[int]int{1: 0, 3: 6}
+	maps.Copy(dst, m1)
+	want := map[int]int{1: 2, 2: 4, 3: 6, 4: 8, 8: 16}
+	if !maps.Equal(dst, want) {
+		t.Errorf("Copy = %v, want %v", dst, want)
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	m := maps.Clone(m1)
+	maps.DeleteFunc(m, func(k, v int) bool { return k > 2 })
+	if want := map[int]int{1: 2, 2: 4}; !maps.Equal(m, want) {
+		t.Errorf("DeleteFunc = %v, want %v", m, want)
+	}
+}
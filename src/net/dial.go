@@ -16,8 +16,39 @@ func Dial(network, address string) (Conn, error) {
 	return nil, ErrNotImplemented
 }
 
+// ListenerProvider is implemented by network drivers that can accept incoming
+// connections, such as a driver for a WiFi chip with a TCP/IP stack. See
+// RegisterListenerProvider.
+type ListenerProvider interface {
+	// Listen announces on the local network address, like the Listen
+	// function in this package. The connections returned by the Accept method
+	// of the listener must implement the deadline methods of Conn.
+	Listen(network, address string) (Listener, error)
+}
+
+var listenerProvider ListenerProvider
+
+// RegisterListenerProvider sets the provider that Listen uses to create
+// listeners. Only one provider can be registered at a time: a later call
+// replaces the previous provider. Passing nil unregisters the provider.
+//
+// This function is specific to TinyGo.
+func RegisterListenerProvider(p ListenerProvider) {
+	listenerProvider = p
+}
+
+// Listen announces on the local network address, using the provider registered
+// with RegisterListenerProvider. It returns ErrNotImplemented if no provider
+// is registered.
 func Listen(network, address string) (Listener, error) {
-	return nil, ErrNotImplemented
+	if listenerProvider == nil {
+		return nil, ErrNotImplemented
+	}
+	l, err := listenerProvider.Listen(network, address)
+	if err != nil {
+		return nil, &OpError{Op: "listen", Net: network, Err: err}
+	}
+	return l, nil
 }
 
 func (d *Dialer) DialContext(ctx context.Context, network, address string) (Conn, error) {
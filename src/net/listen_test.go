@@ -0,0 +1,121 @@
+package net
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// loopbackProvider is a ListenerProvider that accepts connections made with
+// its dial method, which are connected using Pipe.
+type loopbackProvider struct {
+	listener *loopbackListener
+}
+
+func (p *loopbackProvider) Listen(network, address string) (Listener, error) {
+	if network != "tcp" {
+		return nil, errors.New("unsupported network")
+	}
+	p.listener = &loopbackListener{
+		conns: make(chan Conn),
+		done:  make(chan struct{}),
+	}
+	return p.listener, nil
+}
+
+func (p *loopbackProvider) dial() Conn {
+	client, server := Pipe()
+	p.listener.conns <- server
+	return client
+}
+
+type loopbackListener struct {
+	conns chan Conn
+	done  chan struct{}
+}
+
+func (l *loopbackListener) Accept() (Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, errClosed
+	}
+}
+
+func (l *loopbackListener) Close() error {
+	close(l.done)
+	return nil
+}
+
+func (l *loopbackListener) Addr() Addr {
+	return pipeAddr{}
+}
+
+func TestListen(t *testing.T) {
+	RegisterListenerProvider(nil)
+	if _, err := Listen("tcp", ":80"); err != ErrNotImplemented {
+		t.Fatalf("Listen without provider: got %v, want %v", err, ErrNotImplemented)
+	}
+
+	provider := &loopbackProvider{}
+	RegisterListenerProvider(provider)
+	defer RegisterListenerProvider(nil)
+
+	if _, err := Listen("udp", ":80"); err == nil {
+		t.Error("Listen on unsupported network: expected an error")
+	} else if opErr, ok := err.(*OpError); !ok || opErr.Op != "listen" || opErr.Net != "udp" {
+		t.Errorf("Listen on unsupported network: unexpected error %#v", err)
+	}
+
+	l, err := Listen("tcp", ":80")
+	if err != nil {
+		t.Fatal("Listen:", err)
+	}
+
+	// Echo all data of accepted connections.
+	serverErr := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer c.Close()
+		_, err = io.Copy(c, c)
+		serverErr <- err
+	}()
+
+	c := provider.dial()
+	if err := c.SetDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal("SetDeadline:", err)
+	}
+	const msg = "hello, world"
+	go c.Write([]byte(msg))
+	buf := make([]byte, len(msg))
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal("read echo:", err)
+	}
+	if string(buf) != msg {
+		t.Errorf("echo: got %q, want %q", buf, msg)
+	}
+
+	// A read deadline in the past makes Read fail immediately.
+	c.SetReadDeadline(time.Now().Add(-time.Second))
+	if _, err := c.Read(buf); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("read after deadline: got %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	c.Close()
+	if err := <-serverErr; err != nil {
+		t.Error("server:", err)
+	}
+
+	// Closing the listener unblocks Accept.
+	go l.Close()
+	if _, err := l.Accept(); err == nil {
+		t.Error("Accept after Close: expected an error")
+	}
+}
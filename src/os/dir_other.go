@@ -1,5 +1,5 @@
-//go:build baremetal || js || wasi || windows
-// +build baremetal js wasi windows
+//go:build baremetal || js || windows
+// +build baremetal js windows
 
 // Copyright 2009 The Go Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
@@ -0,0 +1,188 @@
+//go:build wasi
+// +build wasi
+
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os
+
+import (
+	"io"
+	_ "unsafe"
+)
+
+// Auxiliary information if the File describes a directory
+type dirInfo struct {
+	buf    []byte // buffer for directory I/O
+	nbuf   int    // length of buf; return value from fd_readdir
+	bufp   int    // location of next record in buf
+	cookie uint64 // position of the first entry after buf
+	eof    bool   // whether buf contains the last entry in the directory
+}
+
+const (
+	// Large enough for a few dozen entries, and for the longest file name
+	// most file systems allow (255 bytes).
+	blockSize = 4096
+
+	// Size of the fixed part of a __wasi_dirent_t, which is followed by the
+	// name:
+	//   d_next   uint64
+	//   d_ino    uint64
+	//   d_namlen uint32
+	//   d_type   uint8
+	direntSize = 24
+)
+
+// File types as used in __wasi_dirent_t.d_type.
+const (
+	wasiFiletypeUnknown         = 0
+	wasiFiletypeBlockDevice     = 1
+	wasiFiletypeCharacterDevice = 2
+	wasiFiletypeDirectory       = 3
+	wasiFiletypeRegularFile     = 4
+	wasiFiletypeSocketDgram     = 5
+	wasiFiletypeSocketStream    = 6
+	wasiFiletypeSymbolicLink    = 7
+)
+
+func (d *dirInfo) close() {
+	d.buf = nil
+}
+
+func (f *File) readdir(n int, mode readdirMode) (names []string, dirents []DirEntry, infos []FileInfo, err error) {
+	// If this file has no dirinfo, create one.
+	if f.dirinfo == nil {
+		f.dirinfo = &dirInfo{buf: make([]byte, blockSize)}
+	}
+	d := f.dirinfo
+
+	// Change the meaning of n for the implementation below.
+	//
+	// The n above was for the public interface of "if n <= 0,
+	// Readdir returns all the FileInfo from the directory in a
+	// single slice".
+	//
+	// But below, we use only negative to mean looping until the
+	// end and positive to mean bounded, with positive
+	// terminating at 0.
+	if n == 0 {
+		n = -1
+	}
+
+	for n != 0 {
+		// Refill the buffer if necessary. The WASI file descriptor doesn't
+		// keep track of the position in the directory, so continue reading
+		// after the last entry that was fully read.
+		if d.bufp >= d.nbuf {
+			if d.eof {
+				break
+			}
+			d.bufp = 0
+			var errno error
+			d.nbuf, errno = syscallReaddir(syscallFd(f.handle.(unixFileHandle)), d.buf, d.cookie)
+			if errno != nil {
+				d.nbuf = 0
+				return names, dirents, infos, &PathError{Op: "readdirent", Path: f.name, Err: errno}
+			}
+			d.eof = d.nbuf < len(d.buf)
+			if d.nbuf <= 0 {
+				break // EOF
+			}
+		}
+
+		// Drain the buffer
+		rec := d.buf[d.bufp:d.nbuf]
+		var namlen int
+		if len(rec) >= direntSize {
+			namlen = int(readUint32LE(rec[16:]))
+		}
+		if len(rec) < direntSize || direntSize+namlen > len(rec) {
+			// The last entry was truncated, so read it again.
+			if d.eof {
+				break
+			}
+			if d.bufp == 0 {
+				// The entry doesn't even fit in an empty buffer.
+				d.buf = make([]byte, 2*len(d.buf)+namlen)
+			}
+			d.bufp = d.nbuf
+			continue
+		}
+		d.cookie = readUint64LE(rec)
+		d.bufp += direntSize + namlen
+		typ := rec[20]
+		name := rec[direntSize : direntSize+namlen]
+
+		// Check for useless names before allocating a string.
+		if string(name) == "." || string(name) == ".." {
+			continue
+		}
+		if n > 0 { // see 'n == 0' comment above
+			n--
+		}
+		if mode == readdirName {
+			names = append(names, string(name))
+		} else if mode == readdirDirEntry {
+			de, err := newUnixDirent(f.name, string(name), wasiFiletypeToType(typ))
+			if IsNotExist(err) {
+				// File disappeared between readdir and stat.
+				// Treat as if it didn't exist.
+				continue
+			}
+			if err != nil {
+				return nil, dirents, nil, err
+			}
+			dirents = append(dirents, de)
+		} else {
+			info, err := lstat(f.name + "/" + string(name))
+			if IsNotExist(err) {
+				// File disappeared between readdir + stat.
+				// Treat as if it didn't exist.
+				continue
+			}
+			if err != nil {
+				return nil, nil, infos, err
+			}
+			infos = append(infos, info)
+		}
+	}
+
+	if n > 0 && len(names)+len(dirents)+len(infos) == 0 {
+		return nil, nil, nil, io.EOF
+	}
+	return names, dirents, infos, nil
+}
+
+func wasiFiletypeToType(typ uint8) FileMode {
+	switch typ {
+	case wasiFiletypeBlockDevice:
+		return ModeDevice
+	case wasiFiletypeCharacterDevice:
+		return ModeDevice | ModeCharDevice
+	case wasiFiletypeDirectory:
+		return ModeDir
+	case wasiFiletypeRegularFile:
+		return 0
+	case wasiFiletypeSocketDgram, wasiFiletypeSocketStream:
+		return ModeSocket
+	case wasiFiletypeSymbolicLink:
+		return ModeSymlink
+	}
+	return ^FileMode(0)
+}
+
+func readUint32LE(b []byte) uint32 {
+	_ = b[3] // bounds check hint to compiler; see golang.org/issue/14808
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func readUint64LE(b []byte) uint64 {
+	return uint64(readUint32LE(b)) | uint64(readUint32LE(b[4:]))<<32
+}
+
+// Implemented in syscall/syscall_libc_wasi.go.
+
+//go:linkname syscallReaddir syscall.readdir
+func syscallReaddir(fd int, buf []byte, cookie uint64) (n int, err error)
@@ -135,6 +135,10 @@ func (f *File) ReadAt(b []byte, offset int64) (n int, err error) {
 	return
 }
 
+// runtime_writeRedirectedStdout writes b to the writer set with
+// runtime.SetStdout. It returns false if standard output is not redirected.
+func runtime_writeRedirectedStdout(b []byte) (n int, err error, redirected bool)
+
 // Write writes len(b) bytes to the File. It returns the number of bytes written
 // and an error, if any. Write returns a non-nil error when n != len(b).
 func (f *File) Write(b []byte) (n int, err error) {
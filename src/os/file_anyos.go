@@ -108,6 +108,11 @@ func (f unixFileHandle) Read(b []byte) (n int, err error) {
 // Write writes len(b) bytes to the File. It returns the number of bytes written
 // and an error, if any. Write returns a non-nil error when n != len(b).
 func (f unixFileHandle) Write(b []byte) (n int, err error) {
+	if syscallFd(f) == syscall.Stdout {
+		if n, err, ok := runtime_writeRedirectedStdout(b); ok {
+			return n, err
+		}
+	}
 	n, err = syscall.Write(syscallFd(f), b)
 	err = handleSyscallError(err)
 	return
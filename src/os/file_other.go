@@ -69,6 +69,11 @@ func (f stdioFileHandle) ReadAt(b []byte, off int64) (n int, err error) {
 func (f stdioFileHandle) Write(b []byte) (n int, err error) {
 	switch f {
 	case 1, 2: // stdout, stderr
+		if f == 1 {
+			if n, err, ok := runtime_writeRedirectedStdout(b); ok {
+				return n, err
+			}
+		}
 		for _, c := range b {
 			putchar(c)
 		}
@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build !baremetal && !js && !wasi
-// +build !baremetal,!js,!wasi
+//go:build !baremetal && !js
+// +build !baremetal,!js
 
 package os
 
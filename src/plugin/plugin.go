@@ -0,0 +1,69 @@
+// Package plugin implements loading of plugins built by TinyGo with
+// -buildmode=plugin. See https://pkg.go.dev/plugin for the Go package.
+//
+// TinyGo plugins differ from Go plugins in a few ways:
+//
+//   - A plugin exports the functions of its main package that are marked with
+//     //export, as C functions. Lookup returns the address of such a function
+//     as an unsafe.Pointer, which can be called through CGo.
+//   - A plugin contains its own runtime (including its own heap), so pointers
+//     to memory allocated by the plugin must not be kept by the host program
+//     after the call returns, and the other way around.
+//   - Plugins can only be loaded by programs built by TinyGo for Linux with
+//     -scheduler=none. The plugin uses the libc of the host program, so it
+//     cannot be loaded by programs linked against a different libc.
+package plugin
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+// Plugin is a loaded plugin.
+type Plugin struct {
+	path    string
+	symbols map[string]unsafe.Pointer
+}
+
+// Symbol is a pointer to a function or variable exported by a plugin. In
+// TinyGo it always holds an unsafe.Pointer.
+type Symbol any
+
+var (
+	pluginsLock sync.Mutex
+	plugins     map[string]*Plugin
+)
+
+// Open opens a plugin and runs its initialization code. If the path has
+// already been opened, the existing *Plugin is returned.
+func Open(path string) (*Plugin, error) {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+
+	if p := plugins[path]; p != nil {
+		return p, nil
+	}
+	symbols, err := open(path)
+	if err != nil {
+		return nil, errors.New("plugin.Open(" + path + "): " + err.Error())
+	}
+	p := &Plugin{
+		path:    path,
+		symbols: symbols,
+	}
+	if plugins == nil {
+		plugins = make(map[string]*Plugin)
+	}
+	plugins[path] = p
+	return p, nil
+}
+
+// Lookup searches for a symbol named symName in plugin p, and returns its
+// address as an unsafe.Pointer. It reports an error if the symbol is not found.
+func (p *Plugin) Lookup(symName string) (Symbol, error) {
+	if ptr, ok := p.symbols[symName]; ok {
+		return ptr, nil
+	}
+	return nil, errors.New("plugin: symbol " + symName + " not found in plugin " + p.path)
+}
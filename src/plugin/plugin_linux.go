@@ -0,0 +1,483 @@
+//go:build linux && !baremetal && !nintendoswitch && !wasi
+// +build linux,!baremetal,!nintendoswitch,!wasi
+
+package plugin
+
+// This file loads plugins on Linux. TinyGo programs are statically linked, so
+// there is no dynamic linker that can do this for us: the plugin (a shared
+// object) is mapped and relocated here instead. Symbols that are not defined in
+// the plugin, like those of the libc, are looked up in the dynamic symbol table
+// of the host program. The builder only adds this table to programs that
+// import this package.
+
+/*
+// Run an initialization function (constructor) of a plugin.
+static void callInitFunction(void *fn) {
+	((void (*)(void))fn)();
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// Relevant constants from the ELF specification.
+// See: https://refspecs.linuxfoundation.org/elf/elf.pdf
+const (
+	ET_DYN = 3 // file type: shared object or position-independent executable
+
+	PT_LOAD    = 1
+	PT_DYNAMIC = 2
+	PT_TLS     = 7
+
+	PF_X = 0x1
+	PF_W = 0x2
+	PF_R = 0x4
+
+	DT_NULL         = 0
+	DT_NEEDED       = 1
+	DT_PLTRELSZ     = 2
+	DT_HASH         = 4
+	DT_STRTAB       = 5
+	DT_SYMTAB       = 6
+	DT_RELA         = 7
+	DT_RELASZ       = 8
+	DT_INIT         = 12
+	DT_REL          = 17
+	DT_RELSZ        = 18
+	DT_PLTREL       = 20
+	DT_JMPREL       = 23
+	DT_INIT_ARRAY   = 25
+	DT_INIT_ARRAYSZ = 27
+
+	SHN_UNDEF = 0
+	STB_LOCAL = 0
+	STB_WEAK  = 2
+
+	EM_386     = 3
+	EM_ARM     = 40
+	EM_X86_64  = 62
+	EM_AARCH64 = 183
+)
+
+const is64bit = unsafe.Sizeof(uintptr(0)) == 8
+
+// The ELF header, see the runtime package.
+type elfHeader struct {
+	ident_magic      uint32
+	ident_class      uint8
+	ident_data       uint8
+	ident_version    uint8
+	ident_osabi      uint8
+	ident_abiversion uint8
+	_                [7]byte // reserved
+	filetype         uint16
+	machine          uint16
+	version          uint32
+	entry            uintptr
+	phoff            uintptr
+	shoff            uintptr
+	flags            uint32
+	ehsize           uint16
+	phentsize        uint16
+	phnum            uint16
+	shentsize        uint16
+	shnum            uint16
+	shstrndx         uint16
+}
+
+// The ELF program headers, see the runtime package.
+type elfProgramHeader64 struct {
+	_type  uint32
+	flags  uint32
+	offset uintptr
+	vaddr  uintptr
+	paddr  uintptr
+	filesz uintptr
+	memsz  uintptr
+	align  uintptr
+}
+
+type elfProgramHeader32 struct {
+	_type  uint32
+	offset uintptr
+	vaddr  uintptr
+	paddr  uintptr
+	filesz uintptr
+	memsz  uintptr
+	flags  uint32
+	align  uintptr
+}
+
+// programHeader contains the fields of a program header that are used here,
+// for both 32-bit and 64-bit ELF files.
+type programHeader struct {
+	_type  uint32
+	flags  uint32
+	offset uintptr
+	vaddr  uintptr
+	filesz uintptr
+	memsz  uintptr
+}
+
+// ELF header of the host program.
+//
+//go:extern __ehdr_start
+var hostHeader elfHeader
+
+// Symbols defined by the host program, by name. Loaded on the first Open.
+var hostSymbols map[string]uintptr
+
+// image is an ELF file (the host program or a plugin) in memory.
+type image struct {
+	base    uintptr // address the file was loaded at
+	dynamic [32]uintptr
+	nsyms   int
+}
+
+func open(path string) (map[string]unsafe.Pointer, error) {
+	if hostSymbols == nil {
+		symbols, err := loadHostSymbols()
+		if err != nil {
+			return nil, err
+		}
+		hostSymbols = symbols
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < int(unsafe.Sizeof(elfHeader{})) || string(data[:4]) != "\x7fELF" {
+		return nil, errors.New("not an ELF file")
+	}
+	header := (*elfHeader)(unsafe.Pointer(&data[0]))
+	if header.filetype != ET_DYN || header.machine != hostHeader.machine || header.ident_class != hostHeader.ident_class {
+		return nil, errors.New("not a shared object for this architecture")
+	}
+	phdrs := programHeaders(header)
+
+	// Map all segments in a single block of memory, at the same distance from
+	// each other as in the file.
+	var size uintptr
+	for _, ph := range phdrs {
+		switch ph._type {
+		case PT_LOAD:
+			if ph.offset+ph.filesz > uintptr(len(data)) || ph.filesz > ph.memsz {
+				return nil, errors.New("invalid program header")
+			}
+			if ph.vaddr+ph.memsz > size {
+				size = ph.vaddr + ph.memsz
+			}
+		case PT_TLS:
+			return nil, errors.New("thread-local storage is not supported")
+		}
+	}
+	mem, err := syscall.Mmap(-1, 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return nil, err
+	}
+	plugin := image{base: uintptr(unsafe.Pointer(&mem[0]))}
+	var dynamic uintptr
+	for _, ph := range phdrs {
+		switch ph._type {
+		case PT_LOAD:
+			// The rest of the segment (the .bss) is already zero.
+			copy(mem[ph.vaddr:], data[ph.offset:ph.offset+ph.filesz])
+		case PT_DYNAMIC:
+			dynamic = plugin.base + ph.vaddr
+		}
+	}
+	if dynamic == 0 {
+		syscall.Munmap(mem)
+		return nil, errors.New("no dynamic section")
+	}
+	plugin.readDynamic(dynamic)
+	if plugin.dynamic[DT_NEEDED] != 0 {
+		syscall.Munmap(mem)
+		return nil, errors.New("shared library dependencies are not supported")
+	}
+	if plugin.dynamic[DT_HASH] == 0 {
+		syscall.Munmap(mem)
+		return nil, errors.New("no DT_HASH symbol table, link with --hash-style=sysv")
+	}
+
+	// Apply all relocations.
+	err = plugin.relocate(header.machine, plugin.dynamic[DT_RELA], plugin.dynamic[DT_RELASZ], true)
+	if err == nil {
+		err = plugin.relocate(header.machine, plugin.dynamic[DT_REL], plugin.dynamic[DT_RELSZ], false)
+	}
+	if err == nil {
+		err = plugin.relocate(header.machine, plugin.dynamic[DT_JMPREL], plugin.dynamic[DT_PLTRELSZ], plugin.dynamic[DT_PLTREL] == DT_RELA)
+	}
+	if err != nil {
+		syscall.Munmap(mem)
+		return nil, err
+	}
+
+	// Now that the relocations are done, set the permissions of every segment
+	// as requested in the file.
+	pageSize := uintptr(syscall.Getpagesize())
+	for _, ph := range phdrs {
+		if ph._type != PT_LOAD {
+			continue
+		}
+		start := ph.vaddr &^ (pageSize - 1)
+		end := (ph.vaddr + ph.memsz + pageSize - 1) &^ (pageSize - 1)
+		if end > uintptr(len(mem)) {
+			// The block is rounded up to whole pages by the kernel, but the
+			// slice isn't.
+			end = uintptr(len(mem))
+		}
+		if start >= end {
+			continue
+		}
+		prot := 0
+		if ph.flags&PF_R != 0 {
+			prot |= syscall.PROT_READ
+		}
+		if ph.flags&PF_W != 0 {
+			prot |= syscall.PROT_WRITE
+		}
+		if ph.flags&PF_X != 0 {
+			prot |= syscall.PROT_EXEC
+		}
+		err := syscall.Mprotect(mem[start:end], prot)
+		if err != nil {
+			syscall.Munmap(mem)
+			return nil, err
+		}
+	}
+
+	// Initialize the plugin: this initializes its runtime and runs the init
+	// functions of all its packages.
+	if fn := plugin.dynamic[DT_INIT]; fn != 0 {
+		C.callInitFunction(unsafe.Pointer(plugin.base + fn))
+	}
+	initArray := plugin.base + plugin.dynamic[DT_INIT_ARRAY]
+	for i := uintptr(0); i < plugin.dynamic[DT_INIT_ARRAYSZ]; i += unsafe.Sizeof(uintptr(0)) {
+		C.callInitFunction(*(*unsafe.Pointer)(unsafe.Pointer(initArray + i)))
+	}
+
+	// Collect the exported symbols.
+	symbols := make(map[string]unsafe.Pointer)
+	for i := 1; i < plugin.nsyms; i++ {
+		name, value, shndx, bind := plugin.symbol(i)
+		if shndx != SHN_UNDEF && bind != STB_LOCAL {
+			symbols[name] = unsafe.Pointer(plugin.base + value)
+		}
+	}
+	return symbols, nil
+}
+
+// loadHostSymbols returns the symbols defined by the host program, from its
+// dynamic symbol table.
+func loadHostSymbols() (map[string]uintptr, error) {
+	// The addresses in a position-independent executable are relative to the
+	// address of the ELF header, just like in a shared object.
+	var host image
+	if hostHeader.filetype == ET_DYN {
+		host.base = uintptr(unsafe.Pointer(&hostHeader))
+	}
+	for _, ph := range programHeaders(&hostHeader) {
+		if ph._type == PT_DYNAMIC {
+			host.readDynamic(host.base + ph.vaddr)
+		}
+	}
+	if host.dynamic[DT_HASH] == 0 {
+		return nil, errors.New("host program has no dynamic symbol table")
+	}
+	symbols := make(map[string]uintptr)
+	for i := 1; i < host.nsyms; i++ {
+		name, value, shndx, bind := host.symbol(i)
+		if shndx != SHN_UNDEF && bind != STB_LOCAL {
+			symbols[name] = host.base + value
+		}
+	}
+	return symbols, nil
+}
+
+// programHeaders returns the program headers of the ELF file that starts with
+// the given header.
+func programHeaders(header *elfHeader) []programHeader {
+	phdrs := make([]programHeader, header.phnum)
+	ptr := uintptr(unsafe.Pointer(header)) + header.phoff
+	for i := range phdrs {
+		if is64bit {
+			h := (*elfProgramHeader64)(unsafe.Pointer(ptr))
+			phdrs[i] = programHeader{h._type, h.flags, h.offset, h.vaddr, h.filesz, h.memsz}
+		} else {
+			h := (*elfProgramHeader32)(unsafe.Pointer(ptr))
+			phdrs[i] = programHeader{h._type, h.flags, h.offset, h.vaddr, h.filesz, h.memsz}
+		}
+		ptr += uintptr(header.phentsize)
+	}
+	return phdrs
+}
+
+// readDynamic reads the dynamic section at the given address. Only the tags
+// used here are stored, and only the presence of DT_NEEDED.
+func (img *image) readDynamic(addr uintptr) {
+	for {
+		tag := *(*uintptr)(unsafe.Pointer(addr))
+		value := *(*uintptr)(unsafe.Pointer(addr + unsafe.Sizeof(uintptr(0))))
+		if tag == DT_NULL {
+			break
+		}
+		if tag < uintptr(len(img.dynamic)) {
+			img.dynamic[tag] = value
+		}
+		addr += 2 * unsafe.Sizeof(uintptr(0))
+	}
+	if img.dynamic[DT_HASH] != 0 {
+		// The second word of the hash table is the number of symbols.
+		img.nsyms = int(*(*uint32)(unsafe.Pointer(img.base + img.dynamic[DT_HASH] + 4)))
+	}
+}
+
+// symbol returns the name, value, section index and binding of the symbol with
+// the given index in the dynamic symbol table.
+func (img *image) symbol(index int) (name string, value uintptr, shndx uint16, bind uint8) {
+	var nameOffset uint32
+	var info uint8
+	if is64bit {
+		sym := img.base + img.dynamic[DT_SYMTAB] + uintptr(index)*24
+		nameOffset = *(*uint32)(unsafe.Pointer(sym))
+		info = *(*uint8)(unsafe.Pointer(sym + 4))
+		shndx = *(*uint16)(unsafe.Pointer(sym + 6))
+		value = *(*uintptr)(unsafe.Pointer(sym + 8))
+	} else {
+		sym := img.base + img.dynamic[DT_SYMTAB] + uintptr(index)*16
+		nameOffset = *(*uint32)(unsafe.Pointer(sym))
+		value = *(*uintptr)(unsafe.Pointer(sym + 4))
+		info = *(*uint8)(unsafe.Pointer(sym + 12))
+		shndx = *(*uint16)(unsafe.Pointer(sym + 14))
+	}
+
+	// Copy the NUL-terminated name from the string table.
+	start := img.base + img.dynamic[DT_STRTAB] + uintptr(nameOffset)
+	length := 0
+	for *(*byte)(unsafe.Pointer(start + uintptr(length))) != 0 {
+		length++
+	}
+	name = string(unsafe.Slice((*byte)(unsafe.Pointer(start)), length))
+	return name, value, shndx, info >> 4
+}
+
+// How a relocation is applied. S is the address of the symbol, A the addend
+// and B the base address of the plugin.
+const (
+	relocationUnsupported = iota
+	relocationNone
+	relocationRelative // B + A
+	relocationAbsolute // S + A
+	relocationSlot     // S (+ A if the addend is explicit), for GOT and PLT entries
+)
+
+// relocationKind returns how relocations of the given type are applied, for
+// the architectures supported by TinyGo on Linux.
+func relocationKind(machine uint16, typ uintptr) int {
+	switch machine {
+	case EM_386, EM_X86_64:
+		switch typ {
+		case 0: // R_X86_64_NONE
+			return relocationNone
+		case 1: // R_X86_64_64 or R_386_32
+			return relocationAbsolute
+		case 6, 7: // R_X86_64_GLOB_DAT, R_X86_64_JUMP_SLOT
+			return relocationSlot
+		case 8: // R_X86_64_RELATIVE
+			return relocationRelative
+		}
+	case EM_ARM:
+		switch typ {
+		case 0: // R_ARM_NONE
+			return relocationNone
+		case 2: // R_ARM_ABS32
+			return relocationAbsolute
+		case 21, 22: // R_ARM_GLOB_DAT, R_ARM_JUMP_SLOT
+			return relocationSlot
+		case 23: // R_ARM_RELATIVE
+			return relocationRelative
+		}
+	case EM_AARCH64:
+		switch typ {
+		case 0: // R_AARCH64_NONE
+			return relocationNone
+		case 257: // R_AARCH64_ABS64
+			return relocationAbsolute
+		case 1025, 1026: // R_AARCH64_GLOB_DAT, R_AARCH64_JUMP_SLOT
+			return relocationSlot
+		case 1027: // R_AARCH64_RELATIVE
+			return relocationRelative
+		}
+	}
+	return relocationUnsupported
+}
+
+// relocate applies the relocations in the given table. With rela set the
+// entries have an explicit addend (Elf_Rela), otherwise the addend is stored at
+// the location to relocate (Elf_Rel).
+func (img *image) relocate(machine uint16, table, size uintptr, rela bool) error {
+	const wordSize = unsafe.Sizeof(uintptr(0))
+	entrySize := 2 * wordSize
+	if rela {
+		entrySize = 3 * wordSize
+	}
+	for offset := uintptr(0); offset < size; offset += entrySize {
+		entry := img.base + table + offset
+		location := (*uintptr)(unsafe.Pointer(img.base + *(*uintptr)(unsafe.Pointer(entry))))
+		info := *(*uintptr)(unsafe.Pointer(entry + wordSize))
+		var symIndex int
+		var typ uintptr
+		if is64bit {
+			symIndex, typ = int(info>>32), info&0xffffffff
+		} else {
+			symIndex, typ = int(info>>8), info&0xff
+		}
+		addend := *location
+		if rela {
+			addend = *(*uintptr)(unsafe.Pointer(entry + 2*wordSize))
+		}
+
+		kind := relocationKind(machine, typ)
+		switch kind {
+		case relocationNone:
+		case relocationRelative:
+			*location = img.base + addend
+		case relocationAbsolute, relocationSlot:
+			address, err := img.resolve(symIndex)
+			if err != nil {
+				return err
+			}
+			if kind == relocationAbsolute || rela {
+				address += addend
+			}
+			*location = address
+		default:
+			return errors.New("unsupported relocation type " + strconv.Itoa(int(typ)))
+		}
+	}
+	return nil
+}
+
+// resolve returns the address of the given symbol: either a symbol defined in
+// the plugin itself or one defined in the host program.
+func (img *image) resolve(index int) (uintptr, error) {
+	name, value, shndx, bind := img.symbol(index)
+	if shndx != SHN_UNDEF {
+		return img.base + value, nil
+	}
+	if address, ok := hostSymbols[name]; ok {
+		return address, nil
+	}
+	if bind == STB_WEAK {
+		return 0, nil
+	}
+	return 0, errors.New("undefined symbol " + name + " (not linked into the host program)")
+}
@@ -0,0 +1,13 @@
+//go:build !linux || baremetal || nintendoswitch || wasi
+// +build !linux baremetal nintendoswitch wasi
+
+package plugin
+
+import (
+	"errors"
+	"unsafe"
+)
+
+func open(path string) (map[string]unsafe.Pointer, error) {
+	return nil, errors.New("plugin: not implemented")
+}
@@ -53,8 +53,6 @@ func init() {
 	cycleMap3["different"] = cycleMap3
 }
 
-// Note: all tests involving maps have been commented out because they aren't
-// supported yet.
 var deepEqualTests = []DeepEqualTest{
 	// Equalities
 	{nil, nil, true},
@@ -67,7 +65,7 @@ var deepEqualTests = []DeepEqualTest{
 	{&[3]int{1, 2, 3}, &[3]int{1, 2, 3}, true},
 	{Basic{1, 0.5}, Basic{1, 0.5}, true},
 	{error(nil), error(nil), true},
-	//{map[int]string{1: "one", 2: "two"}, map[int]string{2: "two", 1: "one"}, true},
+	{map[int]string{1: "one", 2: "two"}, map[int]string{2: "two", 1: "one"}, true},
 	{fn1, fn2, true},
 	{[]byte{1, 2, 3}, []byte{1, 2, 3}, true},
 	{[]MyByte{1, 2, 3}, []MyByte{1, 2, 3}, true},
@@ -83,10 +81,10 @@ var deepEqualTests = []DeepEqualTest{
 	{&[3]int{1, 2, 3}, &[3]int{1, 2, 4}, false},
 	{Basic{1, 0.5}, Basic{1, 0.6}, false},
 	{Basic{1, 0}, Basic{2, 0}, false},
-	//{map[int]string{1: "one", 3: "two"}, map[int]string{2: "two", 1: "one"}, false},
-	//{map[int]string{1: "one", 2: "txo"}, map[int]string{2: "two", 1: "one"}, false},
-	//{map[int]string{1: "one"}, map[int]string{2: "two", 1: "one"}, false},
-	//{map[int]string{2: "two", 1: "one"}, map[int]string{1: "one"}, false},
+	{map[int]string{1: "one", 3: "two"}, map[int]string{2: "two", 1: "one"}, false},
+	{map[int]string{1: "one", 2: "txo"}, map[int]string{2: "two", 1: "one"}, false},
+	{map[int]string{1: "one"}, map[int]string{2: "two", 1: "one"}, false},
+	{map[int]string{2: "two", 1: "one"}, map[int]string{1: "one"}, false},
 	{nil, 1, false},
 	{1, nil, false},
 	{fn1, fn3, false},
@@ -100,16 +98,16 @@ var deepEqualTests = []DeepEqualTest{
 	{&[1]float64{math.NaN()}, self{}, true},
 	{[]float64{math.NaN()}, []float64{math.NaN()}, false},
 	{[]float64{math.NaN()}, self{}, true},
-	//{map[float64]float64{math.NaN(): 1}, map[float64]float64{1: 2}, false},
-	//{map[float64]float64{math.NaN(): 1}, self{}, true},
+	{map[float64]float64{math.NaN(): 1}, map[float64]float64{1: 2}, false},
+	{map[float64]float64{math.NaN(): 1}, self{}, true},
 
 	// Nil vs empty: not the same.
 	{[]int{}, []int(nil), false},
 	{[]int{}, []int{}, true},
 	{[]int(nil), []int(nil), true},
-	//{map[int]int{}, map[int]int(nil), false},
-	//{map[int]int{}, map[int]int{}, true},
-	//{map[int]int(nil), map[int]int(nil), true},
+	{map[int]int{}, map[int]int(nil), false},
+	{map[int]int{}, map[int]int{}, true},
+	{map[int]int(nil), map[int]int(nil), true},
 
 	// Mismatched types
 	{1, 1.0, false},
@@ -123,11 +121,32 @@ var deepEqualTests = []DeepEqualTest{
 	{[]MyByte{1, 2, 3}, MyBytes{1, 2, 3}, false},
 	{[]byte{1, 2, 3}, MyBytes{1, 2, 3}, false},
 
+	// Nested values.
+	{map[string][]int{"a": {1, 2}, "b": nil}, map[string][]int{"b": nil, "a": {1, 2}}, true},
+	{map[string][]int{"a": {1, 2}}, map[string][]int{"a": {1, 3}}, false},
+	{map[string][]int{"a": nil}, map[string][]int{"a": {}}, false},
+	{map[string][]int{"a": nil}, map[string][]int{"b": nil}, false},
+	{map[Basic]*Basic{{1, 2}: {3, 4}}, map[Basic]*Basic{{1, 2}: {3, 4}}, true},
+	{map[Basic]*Basic{{1, 2}: {3, 4}}, map[Basic]*Basic{{1, 2}: {3, 5}}, false},
+	{map[interface{}]interface{}{1: "one", "two": 2.0}, map[interface{}]interface{}{"two": 2.0, 1: "one"}, true},
+	{map[interface{}]interface{}{1: "one"}, map[interface{}]interface{}{int64(1): "one"}, false},
+	{[]map[int]string{{1: "a"}, nil}, []map[int]string{{1: "a"}, nil}, true},
+	{[]map[int]string{{1: "a"}, nil}, []map[int]string{{1: "a"}, {}}, false},
+	{&nested{Name: "x", Items: []item{{1, map[string]float64{"f": 0.5}}}}, &nested{Name: "x", Items: []item{{1, map[string]float64{"f": 0.5}}}}, true},
+	{&nested{Name: "x", Items: []item{{1, map[string]float64{"f": 0.5}}}}, &nested{Name: "x", Items: []item{{1, map[string]float64{"g": 0.5}}}}, false},
+	{&nested{Items: []item{{1, nil}}}, &nested{Items: []item{{2, nil}}}, false},
+	{&nested{Next: &nested{Name: "a"}}, &nested{Next: &nested{Name: "a"}}, true},
+	{&nested{Next: &nested{Name: "a"}}, &nested{Next: &nested{Name: "b"}}, false},
+	{map[string]float64{"nan": math.NaN()}, map[string]float64{"nan": math.NaN()}, false},
+	{map[string]float64{"nan": math.NaN()}, self{}, true},
+	{map[string]interface{}{"a": []interface{}{1, "x", nil}}, map[string]interface{}{"a": []interface{}{1, "x", nil}}, true},
+	{map[string]interface{}{"a": []interface{}{1, "x", nil}}, map[string]interface{}{"a": []interface{}{1, "x", 0}}, false},
+
 	// Possible loops.
 	{&loopy1, &loopy1, true},
 	{&loopy1, &loopy2, true},
-	//{&cycleMap1, &cycleMap2, true},
-	//{&cycleMap1, &cycleMap3, false},
+	{&cycleMap1, &cycleMap2, true},
+	{&cycleMap1, &cycleMap3, false},
 }
 
 func TestDeepEqual(t *testing.T) {
@@ -141,6 +160,17 @@ func TestDeepEqual(t *testing.T) {
 	}
 }
 
+type item struct {
+	ID     int
+	Values map[string]float64
+}
+
+type nested struct {
+	Name  string
+	Items []item
+	Next  *nested
+}
+
 type Recursive struct {
 	x int
 	r *Recursive
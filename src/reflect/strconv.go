@@ -231,6 +231,32 @@ func unquote(s string) (string, error) {
 	return string(buf), nil
 }
 
+// quote returns a double-quoted Go string literal for s, like strconv.Quote.
+// Unlike strconv.Quote, it only escapes ASCII control characters, which is
+// enough for struct tags.
+func quote(s string) string {
+	const hex = "0123456789abcdef"
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c == '\n':
+			buf = append(buf, '\\', 'n')
+		case c == '\t':
+			buf = append(buf, '\\', 't')
+		case c < ' ' || c == 0x7f:
+			buf = append(buf, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			buf = append(buf, c)
+		}
+	}
+	buf = append(buf, '"')
+	return string(buf)
+}
+
 // contains reports whether the string contains the byte c.
 func contains(s string, c byte) bool {
 	return indexByteString(s, c) != -1
@@ -5,6 +5,7 @@
 package reflect
 
 import (
+	"internal/itoa"
 	"unsafe"
 )
 
@@ -295,7 +296,7 @@ type Type interface {
 	// to the index sequence. It is equivalent to calling Field
 	// successively for each index i.
 	// It panics if the type's Kind is not Struct.
-	//FieldByIndex(index []int) StructField
+	FieldByIndex(index []int) StructField
 
 	// FieldByName returns the struct field with the given name
 	// and a boolean indicating if the field was found.
@@ -313,7 +314,7 @@ type Type interface {
 	// and FieldByNameFunc returns no match.
 	// This behavior mirrors Go's handling of name lookup in
 	// structs containing embedded fields.
-	//FieldByNameFunc(match func(string) bool) (StructField, bool)
+	FieldByNameFunc(match func(string) bool) (StructField, bool)
 
 	// In returns the type of a function type's i'th input parameter.
 	// It panics if the type's Kind is not Func.
@@ -349,6 +350,10 @@ type Type interface {
 // The typecode as used in an interface{}.
 type rawType uintptr
 
+// emptyInterfaceType is the type code of interface{}, which the compiler always
+// assigns the same number (see getNonBasicTypeCode in transform/reflect.go).
+const emptyInterfaceType rawType = 0<<5 | 0b00011
+
 func TypeOf(i interface{}) Type {
 	return ValueOf(i).typecode
 }
@@ -366,8 +371,82 @@ func PointerTo(t Type) Type {
 	return ptrType
 }
 
+// String returns a string representation of the type, like "[]int" or
+// "main.T". Note that the method set of unnamed interface types is not stored,
+// so these are all shown as "interface {}". Names of named types can be left
+// out of the binary with the reflect_notypenames build tag, in which case named
+// basic types are shown as their underlying type and other named types as "T".
 func (t rawType) String() string {
-	return "T"
+	if t.isNamed() {
+		if name := t.typeName(); name != "" {
+			return name
+		}
+		if t%2 != 0 {
+			// Don't describe the underlying type: it may refer to this type
+			// (for example in a linked list).
+			return "T"
+		}
+	}
+	switch t.Kind() {
+	case Chan:
+		return "chan " + t.elem().String()
+	case Interface:
+		return "interface {}"
+	case Pointer:
+		return "*" + t.elem().String()
+	case Slice:
+		return "[]" + t.elem().String()
+	case Array:
+		return "[" + itoa.Itoa(t.Len()) + "]" + t.elem().String()
+	case Func:
+		return "func"
+	case Map:
+		key, elem := t.mapTypes()
+		return "map[" + key.String() + "]" + elem.String()
+	case Struct:
+		numField := t.NumField()
+		if numField == 0 {
+			return "struct {}"
+		}
+		s := "struct {"
+		for i := 0; i < numField; i++ {
+			if i != 0 {
+				s += ";"
+			}
+			field := t.rawField(i)
+			s += " "
+			if !field.Anonymous {
+				s += field.Name + " "
+			}
+			s += field.Type.String()
+			if field.Tag != "" {
+				s += " " + quote(string(field.Tag))
+			}
+		}
+		return s + " }"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// isNamed returns whether this is a named type (including the predeclared
+// basic types like int).
+func (t rawType) isNamed() bool {
+	if t%2 == 0 {
+		// Named basic types have the upper bits set.
+		return t>>6 != 0
+	}
+	// Named non-basic types have the 'n' bit set.
+	return (t>>4)%2 != 0
+}
+
+// typeName returns the name of a named type including the package name (like
+// "main.T"), or the empty string if it is not known.
+func (t rawType) typeName() string {
+	if t%2 == 0 {
+		return namedBasicTypeName(uintptr(t >> 6))
+	}
+	return namedNonBasicTypeName(uintptr(t >> 5))
 }
 
 func (t rawType) Kind() Kind {
@@ -393,11 +472,23 @@ func (t rawType) elem() rawType {
 		index := t.stripPrefix()
 		elem, _ := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&arrayTypesSidetable)) + uintptr(index)))
 		return rawType(elem)
-	default: // not implemented: Map
+	case Map:
+		_, elem := t.mapTypes()
+		return elem
+	default: // not implemented: Func, Interface
 		panic("unimplemented: (reflect.Type).Elem()")
 	}
 }
 
+// mapTypes returns the key and element type of this map type. It must only be
+// called on map types.
+func (t rawType) mapTypes() (key, elem rawType) {
+	index := t.stripPrefix()
+	k, p := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&mapTypesSidetable)) + uintptr(index)))
+	e, _ := readVarint(p)
+	return rawType(k), rawType(e)
+}
+
 // stripPrefix removes the "prefix" (the low 5 bits of the type code) from
 // the type code. If this is a named type, it will resolve the underlying type
 // (which is the data for this named type). If it is not, the lower bits are
@@ -417,6 +508,21 @@ func (t rawType) stripPrefix() rawType {
 	return t >> 5
 }
 
+// underlying returns the underlying type of this type: the type itself for
+// unnamed types and the type it is based on for named types.
+func (t rawType) underlying() rawType {
+	if t%2 == 0 {
+		// Basic type. Strip the bits that indicate the named type.
+		return t.Kind().basicType()
+	}
+	if (t>>4)%2 != 0 {
+		// Named non-basic type. Look up the underlying type and clear the 'n'
+		// bit.
+		return t.stripPrefix()<<5 | t&0xf
+	}
+	return t
+}
+
 // Field returns the type of the i'th field of this struct type. It panics if t
 // is not a struct type.
 func (t rawType) Field(i int) StructField {
@@ -428,9 +534,30 @@ func (t rawType) Field(i int) StructField {
 		Tag:       field.Tag,
 		Anonymous: field.Anonymous,
 		Offset:    field.Offset,
+		Index:     []int{i},
 	}
 }
 
+// FieldByIndex returns the nested field corresponding to the index sequence,
+// like calling Field for each index in turn. Embedded pointers to structs are
+// followed. It panics if t is not a struct type.
+func (t rawType) FieldByIndex(index []int) StructField {
+	var field StructField
+	for i, x := range index {
+		if i > 0 {
+			// The previous field was an embedded struct (or pointer to a
+			// struct).
+			t = field.Type.(rawType)
+			if t.Kind() == Pointer && t.elem().Kind() == Struct {
+				t = t.elem()
+			}
+		}
+		field = t.Field(x)
+	}
+	field.Index = index
+	return field
+}
+
 // rawField returns nearly the same value as Field but without converting the
 // Type member to an interface.
 //
@@ -659,16 +786,24 @@ func (t rawType) AssignableTo(u Type) bool {
 		return true
 	}
 	if u.Kind() == Interface {
-		panic("reflect: unimplemented: AssignableTo with interface")
+		return t.Implements(u)
 	}
 	return false
 }
 
+// Implements reports whether the type t implements the interface type u. At
+// run time this is only known when u is the empty interface or the same type as
+// t, as method sets are not kept. Calls with an interface type that is known at
+// compile time are replaced with a type assert by the compiler instead, see
+// OptimizeReflectImplements.
 func (t rawType) Implements(u Type) bool {
 	if u.Kind() != Interface {
 		panic("reflect: non-interface type passed to Type.Implements")
 	}
-	return t.AssignableTo(u)
+	if t == u.(rawType) || u.(rawType).underlying() == emptyInterfaceType {
+		return true
+	}
+	panic("reflect: unimplemented: Implements with non-empty interface")
 }
 
 // Comparable returns whether values of this type can be compared to each other.
@@ -733,12 +868,38 @@ func (t rawType) NumMethod() int {
 	panic("unimplemented: (reflect.Type).NumMethod()")
 }
 
+// Name returns the name of a named type within its package, like "T", or the
+// empty string for unnamed types. The name of predeclared types like int is
+// the type itself.
 func (t rawType) Name() string {
-	panic("unimplemented: (reflect.Type).Name()")
+	if !t.isNamed() {
+		if t.Kind() <= UnsafePointer {
+			return t.Kind().String()
+		}
+		return ""
+	}
+	name := t.typeName()
+	// Strip the package name. Type parameters (like "T[main.U]") may contain
+	// a dot as well, so only look at the part before them.
+	prefix := name
+	if i := indexByteString(name, '['); i >= 0 {
+		prefix = name[:i]
+	}
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] == '.' {
+			return name[i+1:]
+		}
+	}
+	return name
 }
 
+// Key returns a map type's key type. It panics if the type's Kind is not Map.
 func (t rawType) Key() Type {
-	panic("unimplemented: (reflect.Type).Key()")
+	if t.Kind() != Map {
+		panic(&TypeError{"Key"})
+	}
+	key, _ := t.mapTypes()
+	return key
 }
 
 func (t rawType) In(i int) Type {
@@ -757,8 +918,92 @@ func (t rawType) PkgPath() string {
 	panic("unimplemented: (reflect.Type).PkgPath()")
 }
 
+// FieldByName returns the struct field with the given name, which may be in an
+// embedded struct. It panics if t is not a struct type.
 func (t rawType) FieldByName(name string) (StructField, bool) {
-	panic("unimplemented: (reflect.Type).FieldByName()")
+	return t.FieldByNameFunc(func(s string) bool {
+		return s == name
+	})
+}
+
+// FieldByNameFunc returns the struct field with a name for which match returns
+// true. Like for field selectors in Go, the fields in the struct itself are
+// considered first, and then those in embedded structs (breadth first). If
+// several fields at the same depth match, none of them is returned. It panics
+// if t is not a struct type.
+func (t rawType) FieldByNameFunc(match func(string) bool) (StructField, bool) {
+	if t.Kind() != Struct {
+		panic(&TypeError{"FieldByNameFunc"})
+	}
+
+	type embedded struct {
+		typ   rawType
+		index []int
+	}
+	current := []embedded{{typ: t}}
+	// count records how often each struct type in current is embedded at
+	// this depth. A field found in a type that is embedded several times is
+	// ambiguous, just like two different fields with the same name.
+	count := map[rawType]int{}
+	visited := map[rawType]bool{}
+	for len(current) != 0 {
+		var next []embedded
+		nextCount := map[rawType]int{}
+		var result StructField
+		found := false
+		for _, e := range current {
+			if visited[e.typ] {
+				// Already seen at a shallower depth, so any fields would
+				// be hidden anyway.
+				continue
+			}
+			visited[e.typ] = true
+			numField := e.typ.NumField()
+			for i := 0; i < numField; i++ {
+				field := e.typ.rawField(i)
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+				if match(field.Name) {
+					if found || count[e.typ] > 1 {
+						// Ambiguous selector.
+						return StructField{}, false
+					}
+					found = true
+					result = e.typ.Field(i)
+					result.Index = index
+					continue
+				}
+				if found || !field.Anonymous {
+					continue
+				}
+				fieldType := field.Type
+				if fieldType.Kind() == Pointer {
+					fieldType = fieldType.elem()
+				}
+				if fieldType.Kind() != Struct {
+					continue
+				}
+				if nextCount[fieldType] > 0 {
+					// Embedded more than once at the next depth: only
+					// look at it once, but remember it's ambiguous.
+					nextCount[fieldType] = 2
+					continue
+				}
+				nextCount[fieldType] = 1
+				if count[e.typ] > 1 {
+					nextCount[fieldType] = 2
+				}
+				next = append(next, embedded{fieldType, index})
+			}
+		}
+		if found {
+			return result, true
+		}
+		current = next
+		count = nextCount
+	}
+	return StructField{}, false
 }
 
 // A StructField describes a single field in a struct.
@@ -0,0 +1,31 @@
+//go:build !reflect_notypenames
+// +build !reflect_notypenames
+
+package reflect
+
+import "unsafe"
+
+// These sidetables contain the names of named types, one entry per named type
+// number. Each entry is an index into structNamesSidetable. They are created
+// by the compiler and may be left out with the reflect_notypenames build tag,
+// to reduce binary size.
+
+//go:extern reflect.namedBasicTypeNamesSidetable
+var namedBasicTypeNamesSidetable uintptr
+
+//go:extern reflect.namedNonBasicTypeNamesSidetable
+var namedNonBasicTypeNamesSidetable uintptr
+
+// namedBasicTypeName returns the name of the named basic type with the given
+// number.
+func namedBasicTypeName(num uintptr) string {
+	index := *(*uintptr)(unsafe.Pointer(uintptr(unsafe.Pointer(&namedBasicTypeNamesSidetable)) + num*unsafe.Sizeof(uintptr(0))))
+	return readStringSidetable(unsafe.Pointer(&structNamesSidetable), index)
+}
+
+// namedNonBasicTypeName returns the name of the named non-basic type with the
+// given number.
+func namedNonBasicTypeName(num uintptr) string {
+	index := *(*uintptr)(unsafe.Pointer(uintptr(unsafe.Pointer(&namedNonBasicTypeNamesSidetable)) + num*unsafe.Sizeof(uintptr(0))))
+	return readStringSidetable(unsafe.Pointer(&structNamesSidetable), index)
+}
@@ -0,0 +1,15 @@
+//go:build reflect_notypenames
+// +build reflect_notypenames
+
+package reflect
+
+// Type names are not stored in the binary with the reflect_notypenames build
+// tag.
+
+func namedBasicTypeName(num uintptr) string {
+	return ""
+}
+
+func namedNonBasicTypeName(num uintptr) string {
+	return ""
+}
@@ -13,6 +13,7 @@ type valueFlags uint8
 const (
 	valueFlagIndirect valueFlags = 1 << iota
 	valueFlagExported
+	valueFlagEmbedRO // unexported embedded field, its exported fields can still be accessed
 )
 
 type Value struct {
@@ -125,8 +126,10 @@ func (v Value) IsNil() bool {
 		if v.value == nil {
 			return true
 		}
-		_, val := decomposeInterface(*(*interface{})(v.value))
-		return val == nil
+		// Only the type code is checked: small values (such as an int 0) are
+		// stored directly in the interface and may have a nil value field.
+		typecode, _ := decomposeInterface(*(*interface{})(v.value))
+		return typecode == 0
 	default:
 		panic(&ValueError{Method: "IsNil"})
 	}
@@ -167,6 +170,93 @@ func (v Value) IsValid() bool {
 	return v.typecode != 0
 }
 
+// Comparable reports whether the value v is comparable. Unlike
+// Type.Comparable, this looks at the dynamic type of interface values: an
+// interface holding a slice is not comparable, while a nil interface is.
+func (v Value) Comparable() bool {
+	switch v.Kind() {
+	case Invalid:
+		return false
+	case Interface:
+		return v.IsNil() || v.Elem().Comparable()
+	case Array:
+		if v.Len() == 0 {
+			return v.typecode.elem().Comparable()
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !v.Index(i).Comparable() {
+				return false
+			}
+		}
+		return true
+	case Struct:
+		numField := v.NumField()
+		for i := 0; i < numField; i++ {
+			if !v.Field(i).Comparable() {
+				return false
+			}
+		}
+		return true
+	default:
+		return v.typecode.Comparable()
+	}
+}
+
+// Equal reports whether v is equal to u, like the == operator. Interface
+// values are compared by their dynamic type and value. It panics if the values
+// have the same type but are not comparable, just like comparing two interface
+// values holding such values would.
+func (v Value) Equal(u Value) bool {
+	if v.Kind() == Interface {
+		v = v.Elem()
+	}
+	if u.Kind() == Interface {
+		u = u.Elem()
+	}
+	if !v.IsValid() || !u.IsValid() {
+		return v.IsValid() == u.IsValid()
+	}
+	if v.typecode != u.typecode {
+		return false
+	}
+
+	switch v.Kind() {
+	case Bool:
+		return v.Bool() == u.Bool()
+	case Int, Int8, Int16, Int32, Int64:
+		return v.Int() == u.Int()
+	case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		return v.Uint() == u.Uint()
+	case Float32, Float64:
+		return v.Float() == u.Float()
+	case Complex64, Complex128:
+		return v.Complex() == u.Complex()
+	case String:
+		return v.String() == u.String()
+	case Chan, Ptr, UnsafePointer:
+		return v.pointer() == u.pointer()
+	case Array:
+		if v.Len() == 0 && !v.typecode.elem().Comparable() {
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !v.Index(i).Equal(u.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case Struct:
+		numField := v.NumField()
+		for i := 0; i < numField; i++ {
+			if !v.Field(i).Equal(u.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	panic("reflect.Value.Equal: values of type " + v.Type().String() + " are not comparable")
+}
+
 func (v Value) CanInterface() bool {
 	return v.isExported()
 }
@@ -175,8 +265,17 @@ func (v Value) CanAddr() bool {
 	return v.flags&(valueFlagIndirect) == valueFlagIndirect
 }
 
+// Addr returns a pointer to the value v. It panics if v is not addressable,
+// see CanAddr.
 func (v Value) Addr() Value {
-	panic("unimplemented: (reflect.Value).Addr()")
+	if !v.CanAddr() {
+		panic("reflect.Value.Addr of unaddressable value")
+	}
+	return Value{
+		typecode: PointerTo(v.typecode).(rawType),
+		value:    v.value,
+		flags:    v.flags &^ valueFlagIndirect,
+	}
 }
 
 func (v Value) CanSet() bool {
@@ -336,8 +435,13 @@ func (v Value) String() string {
 	}
 }
 
+// Bytes returns v's underlying value. It panics if v's underlying value is not
+// a slice of bytes.
 func (v Value) Bytes() []byte {
-	panic("unimplemented: (reflect.Value).Bytes()")
+	if v.Kind() != Slice || v.typecode.elem().Kind() != Uint8 {
+		panic(&ValueError{Method: "Bytes", Kind: v.Kind()})
+	}
+	return *(*[]byte)(v.value)
 }
 
 func (v Value) Slice(i, j int) Value {
@@ -414,7 +518,7 @@ func (v Value) Elem() Value {
 		return Value{
 			typecode: typecode,
 			value:    value,
-			flags:    v.flags &^ valueFlagIndirect,
+			flags:    v.flags &^ (valueFlagIndirect | valueFlagEmbedRO),
 		}
 	default:
 		panic(&ValueError{Method: "Elem"})
@@ -425,9 +529,17 @@ func (v Value) Elem() Value {
 func (v Value) Field(i int) Value {
 	structField := v.typecode.rawField(i)
 	flags := v.flags
+	if flags&valueFlagEmbedRO != 0 {
+		// This struct is an unexported embedded field, but its own exported
+		// fields are promoted and can be accessed like any other field.
+		flags = flags&^valueFlagEmbedRO | valueFlagExported
+	}
 	if structField.PkgPath != "" {
 		// The fact that PkgPath is present means that this field is not
 		// exported.
+		if structField.Anonymous && flags&valueFlagExported != 0 {
+			flags |= valueFlagEmbedRO
+		}
 		flags &^= valueFlagExported
 	}
 
@@ -466,7 +578,7 @@ func (v Value) Field(i int) Value {
 		ptr := unsafe.Pointer(uintptr(v.value) + structField.Offset)
 		value := unsafe.Pointer(loadValue(ptr, fieldSize))
 		return Value{
-			flags:    0,
+			flags:    flags,
 			typecode: fieldType,
 			value:    value,
 		}
@@ -483,6 +595,8 @@ func (v Value) Field(i int) Value {
 }
 
 func (v Value) Index(i int) Value {
+	// Elements of an unexported embedded field are never exported.
+	v.flags &^= valueFlagEmbedRO
 	switch v.Kind() {
 	case Slice:
 		// Extract an element from the slice.
@@ -597,31 +711,232 @@ func (v Value) OverflowFloat(x float64) bool {
 	panic("unimplemented: (reflect.Value).OverflowFloat()")
 }
 
+//go:linkname hashmapNewIterator runtime.hashmapNewIterator
+func hashmapNewIterator() unsafe.Pointer
+
+//go:linkname hashmapNext runtime.hashmapNextUnsafePointer
+func hashmapNext(m, it, key, value unsafe.Pointer) bool
+
+//go:linkname hashmapBinaryGet runtime.hashmapBinaryGetUnsafePointer
+func hashmapBinaryGet(m, key, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapStringGet runtime.hashmapStringGetUnsafePointer
+func hashmapStringGet(m unsafe.Pointer, key string, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapInterfaceGet runtime.hashmapInterfaceGetUnsafePointer
+func hashmapInterfaceGet(m unsafe.Pointer, key interface{}, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapMake runtime.hashmapMakeUnsafePointer
+func hashmapMake(keySize, valueSize uint8, sizeHint uintptr, alg uint8) unsafe.Pointer
+
+//go:linkname hashmapBinarySet runtime.hashmapBinarySetUnsafePointer
+func hashmapBinarySet(m, key, value unsafe.Pointer)
+
+//go:linkname hashmapBinaryDelete runtime.hashmapBinaryDeleteUnsafePointer
+func hashmapBinaryDelete(m, key unsafe.Pointer)
+
+//go:linkname hashmapStringSet runtime.hashmapStringSetUnsafePointer
+func hashmapStringSet(m unsafe.Pointer, key string, value unsafe.Pointer)
+
+//go:linkname hashmapStringDelete runtime.hashmapStringDeleteUnsafePointer
+func hashmapStringDelete(m unsafe.Pointer, key string)
+
+//go:linkname hashmapInterfaceSet runtime.hashmapInterfaceSetUnsafePointer
+func hashmapInterfaceSet(m unsafe.Pointer, key interface{}, value unsafe.Pointer)
+
+//go:linkname hashmapInterfaceDelete runtime.hashmapInterfaceDeleteUnsafePointer
+func hashmapInterfaceDelete(m unsafe.Pointer, key interface{})
+
+// mapKeyAlgorithm returns how keys of the given type are stored in a map. This
+// must match the choice made by the compiler (see compiler/map.go).
+func mapKeyAlgorithm(key rawType) hashmapAlgorithm {
+	if key.Kind() == String {
+		return hashmapAlgorithmString
+	}
+	if key.isBinary() {
+		return hashmapAlgorithmBinary
+	}
+	return hashmapAlgorithmInterface
+}
+
+type hashmapAlgorithm uint8
+
+// This must be kept in sync with runtime.hashmapAlgorithm.
+const (
+	hashmapAlgorithmBinary hashmapAlgorithm = iota
+	hashmapAlgorithmString
+	hashmapAlgorithmInterface
+)
+
+// isBinary returns whether values of this type can be compared and hashed
+// using their raw bytes, like the hashmapIsBinaryKey function in the compiler.
+func (t rawType) isBinary() bool {
+	switch t.Kind() {
+	case Bool, Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		return true
+	case Pointer:
+		return true
+	case Struct:
+		numField := t.NumField()
+		for i := 0; i < numField; i++ {
+			if !t.rawField(i).Type.isBinary() {
+				return false
+			}
+		}
+		return true
+	case Array:
+		return t.elem().isBinary()
+	default:
+		return false
+	}
+}
+
+// valueFromPointer returns a Value for a copy of the data at ptr, which must be
+// of the given type. The returned value is not addressable.
+func valueFromPointer(typ rawType, ptr unsafe.Pointer, flags valueFlags) Value {
+	if typ.Size() > unsafe.Sizeof(uintptr(0)) {
+		// Too big to fit in a pointer, so refer to it indirectly.
+		return Value{
+			typecode: typ,
+			value:    ptr,
+			flags:    flags,
+		}
+	}
+	return Value{
+		typecode: typ,
+		value:    unsafe.Pointer(loadValue(ptr, typ.Size())),
+		flags:    flags,
+	}
+}
+
+// MapKeys returns a slice containing all the keys present in the map, in
+// unspecified order. It panics if v's Kind is not Map. It returns an empty
+// slice if v represents a nil map.
 func (v Value) MapKeys() []Value {
-	panic("unimplemented: (reflect.Value).MapKeys()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapKeys", Kind: v.Kind()})
+	}
+	keys := make([]Value, 0, v.Len())
+	it := v.MapRange()
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	return keys
 }
 
+// MapIndex returns the value associated with key in the map v. It panics if
+// v's Kind is not Map. It returns the zero Value if key is not found in the map
+// or if v represents a nil map.
 func (v Value) MapIndex(key Value) Value {
-	panic("unimplemented: (reflect.Value).MapIndex()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapIndex", Kind: v.Kind()})
+	}
+	keyType, elemType := v.typecode.mapTypes()
+	if key.typecode != keyType && keyType.Kind() != Interface {
+		panic("reflect: map key type mismatch")
+	}
+	elemSize := elemType.Size()
+	elem := alloc(elemSize, nil)
+	var ok bool
+	switch mapKeyAlgorithm(keyType) {
+	case hashmapAlgorithmString:
+		ok = hashmapStringGet(v.pointer(), *(*string)(key.value), elem, elemSize)
+	case hashmapAlgorithmBinary:
+		keyPtr := key.value
+		if !key.isIndirect() && keyType.Size() <= unsafe.Sizeof(uintptr(0)) {
+			keyPtr = unsafe.Pointer(&key.value)
+		}
+		ok = hashmapBinaryGet(v.pointer(), keyPtr, elem, elemSize)
+	default:
+		if keyType.Kind() != Interface {
+			// The compiler stores these keys in an interface using the
+			// underlying type, so do the same here.
+			key.typecode = keyType.underlying()
+		}
+		ok = hashmapInterfaceGet(v.pointer(), valueInterfaceUnsafe(key), elem, elemSize)
+	}
+	if !ok {
+		return Value{}
+	}
+	return valueFromPointer(elemType, elem, v.flags&valueFlagExported)
 }
 
+// MapRange returns a range iterator for a map. It panics if v's Kind is not
+// Map.
 func (v Value) MapRange() *MapIter {
-	panic("unimplemented: (reflect.Value).MapRange()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapRange", Kind: v.Kind()})
+	}
+	return &MapIter{
+		m:  v,
+		it: hashmapNewIterator(),
+	}
 }
 
+// A MapIter is an iterator for ranging over a map. See Value.MapRange.
 type MapIter struct {
+	m     Value
+	it    unsafe.Pointer
+	key   Value
+	value Value
+	valid bool
 }
 
+// Key returns the key of iter's current map entry.
 func (it *MapIter) Key() Value {
-	panic("unimplemented: (*reflect.MapIter).Key()")
+	if !it.valid {
+		panic("reflect: MapIter.Key called before Next")
+	}
+	return it.key
 }
 
+// Value returns the value of iter's current map entry.
 func (it *MapIter) Value() Value {
-	panic("unimplemented: (*reflect.MapIter).Value()")
+	if !it.valid {
+		panic("reflect: MapIter.Value called before Next")
+	}
+	return it.value
 }
 
+// Next advances the map iterator and reports whether there is another entry.
+// It returns false when iter is exhausted; subsequent calls to Key, Value, or
+// Next will panic.
 func (it *MapIter) Next() bool {
-	panic("unimplemented: (*reflect.MapIter).Next()")
+	if it.it == nil {
+		panic("reflect: MapIter.Next called on exhausted iterator")
+	}
+	keyType, elemType := it.m.typecode.mapTypes()
+	alg := mapKeyAlgorithm(keyType)
+
+	// Keys that are not stored directly are stored as an interface.
+	keySize := keyType.Size()
+	if alg == hashmapAlgorithmInterface {
+		keySize = unsafe.Sizeof(interface{}(nil))
+	}
+	key := alloc(keySize, nil)
+	elem := alloc(elemType.Size(), nil)
+	if !hashmapNext(it.m.pointer(), it.it, key, elem) {
+		it.it = nil
+		it.valid = false
+		return false
+	}
+
+	flags := it.m.flags & valueFlagExported
+	if alg == hashmapAlgorithmInterface && keyType.Kind() != Interface {
+		// Extract the key from the interface it is stored in. This interface
+		// holds the underlying type of the key, not the key type itself.
+		_, value := decomposeInterface(*(*interface{})(key))
+		it.key = Value{
+			typecode: keyType,
+			value:    value,
+			flags:    flags,
+		}
+	} else {
+		it.key = valueFromPointer(keyType, key, flags)
+	}
+	it.value = valueFromPointer(elemType, elem, flags)
+	it.valid = true
+	return true
 }
 
 func (v Value) Set(x Value) {
@@ -720,16 +1035,32 @@ func (v Value) SetString(x string) {
 	}
 }
 
+// SetBytes sets v's underlying value. It panics if v's underlying value is not
+// a slice of bytes.
 func (v Value) SetBytes(x []byte) {
-	panic("unimplemented: (reflect.Value).SetBytes()")
+	v.checkAddressable()
+	if v.Kind() != Slice || v.typecode.elem().Kind() != Uint8 {
+		panic(&ValueError{Method: "SetBytes", Kind: v.Kind()})
+	}
+	*(*[]byte)(v.value) = x
 }
 
 func (v Value) SetCap(n int) {
 	panic("unimplemented: (reflect.Value).SetCap()")
 }
 
+// SetLen sets v's length to n. It panics if v's Kind is not Slice or if n is
+// negative or greater than the capacity of the slice.
 func (v Value) SetLen(n int) {
-	panic("unimplemented: (reflect.Value).SetLen()")
+	v.checkAddressable()
+	if v.Kind() != Slice {
+		panic(&ValueError{Method: "SetLen", Kind: v.Kind()})
+	}
+	slice := (*sliceHeader)(v.value)
+	if n < 0 || uintptr(n) > slice.cap {
+		panic("reflect: slice length out of range in SetLen")
+	}
+	slice.len = uintptr(n)
 }
 
 func (v Value) checkAddressable() {
@@ -750,12 +1081,45 @@ func (v Value) Convert(t Type) Value {
 	panic("unimplemented: (reflect.Value).Convert()")
 }
 
+// MakeSlice creates a new zero-initialized slice value for the specified slice
+// type, length, and capacity.
 func MakeSlice(typ Type, len, cap int) Value {
-	panic("unimplemented: reflect.MakeSlice()")
+	if typ.Kind() != Slice {
+		panic("reflect.MakeSlice of non-slice type")
+	}
+	if len < 0 || cap < len {
+		panic("reflect.MakeSlice: len out of range")
+	}
+	rtype := typ.(rawType)
+	elemSize := rtype.elem().Size()
+	slice := &sliceHeader{
+		data: alloc(elemSize*uintptr(cap), nil),
+		len:  uintptr(len),
+		cap:  uintptr(cap),
+	}
+	return Value{
+		typecode: rtype,
+		value:    unsafe.Pointer(slice),
+		flags:    valueFlagExported,
+	}
 }
 
+// Zero returns a Value representing the zero value for the specified type. The
+// returned value is neither addressable nor settable.
 func Zero(typ Type) Value {
-	panic("unimplemented: reflect.Zero()")
+	rtype := typ.(rawType)
+	if rtype.Size() <= unsafe.Sizeof(uintptr(0)) {
+		// Small values are stored directly in the value field.
+		return Value{
+			typecode: rtype,
+			flags:    valueFlagExported,
+		}
+	}
+	return Value{
+		typecode: rtype,
+		value:    alloc(rtype.Size(), nil),
+		flags:    valueFlagExported,
+	}
 }
 
 // New is the reflect equivalent of the new(T) keyword, returning a pointer to a
@@ -811,6 +1175,14 @@ func (e *ValueError) Error() string {
 	return "reflect: call of " + e.Method + " on " + e.Kind.String() + " Value"
 }
 
+// errorString is a simple error, like the ones returned by errors.New. The
+// errors package can't be used here as it imports reflect.
+type errorString string
+
+func (e errorString) Error() string {
+	return string(e)
+}
+
 //go:linkname memcpy runtime.memcpy
 func memcpy(dst, src unsafe.Pointer, size uintptr)
 
@@ -829,7 +1201,26 @@ func Copy(dst, src Value) int {
 // Append appends the values x to a slice s and returns the resulting slice.
 // As in Go, each x's value must be assignable to the slice's element type.
 func Append(s Value, x ...Value) Value {
-	panic("unimplemented: reflect.Append()")
+	if s.typecode.Kind() != Slice {
+		panic("reflect.Append: not a slice")
+	}
+	if !s.isExported() {
+		panic("reflect.Append: unexported")
+	}
+	elemType := s.typecode.elem()
+	elemSize := elemType.Size()
+	slice := *(*sliceHeader)(s.value)
+	for _, elem := range x {
+		if elem.typecode != elemType {
+			panic("reflect.Append: element type mismatch")
+		}
+		slice.data, slice.len, slice.cap = sliceAppend(slice.data, elem.valuePointer(), slice.len, slice.cap, 1, elemSize)
+	}
+	return Value{
+		typecode: s.typecode,
+		value:    unsafe.Pointer(&slice),
+		flags:    valueFlagExported,
+	}
 }
 
 // AppendSlice appends a slice t to a slice s and returns the resulting slice.
@@ -860,27 +1251,147 @@ func AppendSlice(s, t Value) Value {
 	}
 }
 
+// SetMapIndex sets the element associated with key in the map v to elem. It
+// panics if v's Kind is not Map. If elem is the zero Value, SetMapIndex deletes
+// the key from the map. Otherwise if v holds a nil map, SetMapIndex will panic.
+//
+// If the element type of the map is an interface type, elem must implement it
+// and is stored in the map as an interface value. See Type.Implements for the
+// interface types that are supported.
 func (v Value) SetMapIndex(key, elem Value) {
-	panic("unimplemented: (reflect.Value).SetMapIndex()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "SetMapIndex", Kind: v.Kind()})
+	}
+	if !v.isExported() || !key.isExported() || (elem.IsValid() && !elem.isExported()) {
+		panic("reflect.SetMapIndex: unexported")
+	}
+	keyType, elemType := v.typecode.mapTypes()
+	if key.typecode != keyType && keyType.Kind() != Interface {
+		panic("reflect: map key type mismatch")
+	}
+	var elemPtr unsafe.Pointer
+	if elem.IsValid() {
+		if elem.typecode == elemType {
+			elemPtr = elem.valuePointer()
+		} else if elemType.Kind() == Interface {
+			if !elem.typecode.Implements(elemType) {
+				panic("reflect: map element type mismatch")
+			}
+			iface := valueInterfaceUnsafe(elem)
+			elemPtr = unsafe.Pointer(&iface)
+		} else {
+			panic("reflect: map element type mismatch")
+		}
+	}
+	m := v.pointer()
+	switch mapKeyAlgorithm(keyType) {
+	case hashmapAlgorithmString:
+		if elemPtr == nil {
+			hashmapStringDelete(m, *(*string)(key.value))
+		} else {
+			hashmapStringSet(m, *(*string)(key.value), elemPtr)
+		}
+	case hashmapAlgorithmBinary:
+		keyPtr := key.valuePointer()
+		if elemPtr == nil {
+			hashmapBinaryDelete(m, keyPtr)
+		} else {
+			hashmapBinarySet(m, keyPtr, elemPtr)
+		}
+	default:
+		if keyType.Kind() != Interface {
+			// The compiler stores these keys in an interface using the
+			// underlying type, so do the same here.
+			key.typecode = keyType.underlying()
+		}
+		if elemPtr == nil {
+			hashmapInterfaceDelete(m, valueInterfaceUnsafe(key))
+		} else {
+			hashmapInterfaceSet(m, valueInterfaceUnsafe(key), elemPtr)
+		}
+	}
 }
 
-// FieldByIndex returns the nested field corresponding to index.
+// valuePointer returns a pointer to (a copy of) the value of v.
+func (v Value) valuePointer() unsafe.Pointer {
+	if v.isIndirect() || v.typecode.Size() > unsafe.Sizeof(uintptr(0)) {
+		return v.value
+	}
+	value := v.value
+	return unsafe.Pointer(&value)
+}
+
+// FieldByIndex returns the nested field corresponding to index. It panics if
+// it needs to go through a nil pointer to an embedded struct.
 func (v Value) FieldByIndex(index []int) Value {
-	panic("unimplemented: (reflect.Value).FieldByIndex()")
+	v, err := v.FieldByIndexErr(index)
+	if err != nil {
+		panic(err)
+	}
+	return v
 }
 
-// FieldByIndexErr returns the nested field corresponding to index.
+// FieldByIndexErr returns the nested field corresponding to index. It returns
+// an error if it needs to go through a nil pointer to an embedded struct.
 func (v Value) FieldByIndexErr(index []int) (Value, error) {
-	return Value{}, &ValueError{Method: "FieldByIndexErr"}
+	for i, x := range index {
+		if i > 0 && v.Kind() == Pointer && v.typecode.elem().Kind() == Struct {
+			if v.IsNil() {
+				return Value{}, errorString("reflect: indirection through nil pointer to embedded struct field " + v.typecode.elem().Name())
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
 }
 
+// FieldByName returns the struct field with the given name, which may be in an
+// embedded struct. It returns the zero Value if no field was found.
 func (v Value) FieldByName(name string) Value {
-	panic("unimplemented: (reflect.Value).FieldByName()")
+	if field, ok := v.typecode.FieldByName(name); ok {
+		return v.FieldByIndex(field.Index)
+	}
+	return Value{}
+}
+
+// FieldByNameFunc returns the struct field with a name for which match returns
+// true, see Type.FieldByNameFunc. It returns the zero Value if no field was
+// found.
+func (v Value) FieldByNameFunc(match func(string) bool) Value {
+	if field, ok := v.typecode.FieldByNameFunc(match); ok {
+		return v.FieldByIndex(field.Index)
+	}
+	return Value{}
 }
 
 // MakeMap creates a new map with the specified type.
 func MakeMap(typ Type) Value {
-	panic("unimplemented: reflect.MakeMap()")
+	return MakeMapWithSize(typ, 8)
+}
+
+// MakeMapWithSize creates a new map with the specified type and initial space
+// for approximately n elements.
+func MakeMapWithSize(typ Type, n int) Value {
+	if typ.Kind() != Map {
+		panic(&ValueError{Method: "MakeMap", Kind: typ.Kind()})
+	}
+	if n < 0 {
+		panic("reflect.MakeMapWithSize: negative size hint")
+	}
+	rtype := typ.(rawType)
+	keyType, elemType := rtype.mapTypes()
+	alg := mapKeyAlgorithm(keyType)
+	keySize := keyType.Size()
+	if alg == hashmapAlgorithmInterface {
+		// Keys that are not stored directly are stored as an interface.
+		keySize = unsafe.Sizeof(interface{}(nil))
+	}
+	return Value{
+		typecode: rtype,
+		value:    hashmapMake(uint8(keySize), uint8(elemType.Size()), uintptr(n), uint8(alg)),
+		flags:    valueFlagExported,
+	}
 }
 
 func (v Value) Call(in []Value) []Value {
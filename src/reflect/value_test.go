@@ -30,3 +30,437 @@ func TestIndirectPointers(t *testing.T) {
 		t.Errorf("bad indirect array index via reflect")
 	}
 }
+
+type mapKey struct {
+	A int
+	B float32
+}
+
+func TestMap(t *testing.T) {
+	m := map[mapKey]string{{1, 0.5}: "one", {2, 1.5}: "two"}
+	v := ValueOf(m)
+	if v.Type().Key() != TypeOf(mapKey{}) || v.Type().Elem() != TypeOf("") {
+		t.Errorf("unexpected key or element type for map")
+	}
+
+	keys := v.MapKeys()
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	for _, key := range keys {
+		k := key.Interface().(mapKey)
+		if value := v.MapIndex(key); !value.IsValid() || value.String() != m[k] {
+			t.Errorf("unexpected value for key %v", k)
+		}
+	}
+	if v.MapIndex(ValueOf(mapKey{3, 0})).IsValid() {
+		t.Errorf("found a value for a key that is not in the map")
+	}
+
+	seen := map[string]bool{}
+	iter := v.MapRange()
+	for iter.Next() {
+		if m[iter.Key().Interface().(mapKey)] != iter.Value().String() {
+			t.Errorf("unexpected map entry: %v: %v", iter.Key().Interface(), iter.Value().Interface())
+		}
+		seen[iter.Value().String()] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected to see 2 map entries, got %d", len(seen))
+	}
+
+	var nilMap map[string]int
+	v = ValueOf(nilMap)
+	if len(v.MapKeys()) != 0 || v.MapIndex(ValueOf("a")).IsValid() || v.MapRange().Next() {
+		t.Errorf("expected nil map to be empty")
+	}
+}
+
+func TestAddr(t *testing.T) {
+	type pair struct {
+		A, B int
+		C    [4]int64
+	}
+	p := &pair{A: 1, B: 2}
+	v := ValueOf(p).Elem().Field(1)
+	if !v.CanAddr() {
+		t.Fatal("expected field of pointer to be addressable")
+	}
+	ptr := v.Addr()
+	if ptr.Type() != PointerTo(v.Type()) || ptr.Interface().(*int) != &p.B {
+		t.Errorf("Addr returned the wrong pointer")
+	}
+	*ptr.Interface().(*int) = 5
+	if p.B != 5 {
+		t.Errorf("could not modify the value through Addr: got %d", p.B)
+	}
+
+	// Small fields of a struct that isn't stored directly in the interface
+	// can't be addressed, but must still be usable.
+	v = ValueOf(*p).Field(0)
+	if v.CanAddr() || v.Interface().(int) != 1 {
+		t.Errorf("unexpected field of struct value: %v", v.Interface())
+	}
+}
+
+func TestMakeSlice(t *testing.T) {
+	v := MakeSlice(TypeOf([]int16{}), 2, 4)
+	if v.Len() != 2 || v.Cap() != 4 {
+		t.Fatalf("unexpected len/cap: %d/%d", v.Len(), v.Cap())
+	}
+	v = Append(v, ValueOf(int16(3)), ValueOf(int16(4)), ValueOf(int16(5)))
+	s := v.Interface().([]int16)
+	if len(s) != 5 || s[0] != 0 || s[1] != 0 || s[2] != 3 || s[4] != 5 {
+		t.Errorf("unexpected slice after Append: %v", s)
+	}
+
+	p := New(TypeOf([]string{}))
+	p.Elem().Set(MakeSlice(p.Elem().Type(), 0, 3))
+	p.Elem().Set(Append(p.Elem(), ValueOf("a"), ValueOf("b")))
+	p.Elem().SetLen(1)
+	if sp := p.Interface().(*[]string); len(*sp) != 1 || (*sp)[0] != "a" || cap(*sp) < 2 {
+		t.Errorf("unexpected slice after SetLen: %v", *sp)
+	}
+}
+
+func TestZero(t *testing.T) {
+	if Zero(TypeOf(0)).Int() != 0 || Zero(TypeOf("")).String() != "" {
+		t.Errorf("unexpected zero value for int or string")
+	}
+	if z := Zero(TypeOf(mapKey{})).Interface().(mapKey); z != (mapKey{}) {
+		t.Errorf("unexpected zero value for struct: %v", z)
+	}
+	if !Zero(TypeOf(map[int]int{})).IsNil() {
+		t.Errorf("zero value of a map is not nil")
+	}
+}
+
+func TestMakeMap(t *testing.T) {
+	strMap := MakeMap(TypeOf(map[string]int{}))
+	strMap.SetMapIndex(ValueOf("a"), ValueOf(1))
+	strMap.SetMapIndex(ValueOf("b"), ValueOf(2))
+	strMap.SetMapIndex(ValueOf("b"), Value{})
+	if m := strMap.Interface().(map[string]int); len(m) != 1 || m["a"] != 1 {
+		t.Errorf("unexpected string map: %v", m)
+	}
+
+	binMap := MakeMapWithSize(TypeOf(map[uint32][2]string{}), 20)
+	for i := 0; i < 20; i++ {
+		binMap.SetMapIndex(ValueOf(uint32(i)), ValueOf([2]string{"x", "y"}))
+	}
+	binMap.SetMapIndex(ValueOf(uint32(3)), Value{})
+	if m := binMap.Interface().(map[uint32][2]string); len(m) != 19 || m[7][1] != "y" {
+		t.Errorf("unexpected binary map: %v", m)
+	}
+
+	keyMap := MakeMap(TypeOf(map[mapKey]bool{}))
+	keyMap.SetMapIndex(ValueOf(mapKey{1, 0.5}), ValueOf(true))
+	if m := keyMap.Interface().(map[mapKey]bool); !m[mapKey{1, 0.5}] || len(m) != 1 {
+		t.Errorf("unexpected struct key map: %v", m)
+	}
+
+	ifaceMap := MakeMap(TypeOf(map[interface{}]int{}))
+	ifaceMap.SetMapIndex(ValueOf("a"), ValueOf(1))
+	ifaceMap.SetMapIndex(ValueOf(2), ValueOf(2))
+	if m := ifaceMap.Interface().(map[interface{}]int); len(m) != 2 || m["a"] != 1 || m[2] != 2 {
+		t.Errorf("unexpected interface key map: %v", m)
+	}
+}
+
+func TestImplements(t *testing.T) {
+	emptyInterface := TypeOf((*interface{})(nil)).Elem()
+	for _, typ := range []Type{TypeOf(0), TypeOf(""), TypeOf(mapKey{}), TypeOf(&mapKey{}), emptyInterface} {
+		if !typ.Implements(emptyInterface) {
+			t.Errorf("%s does not implement interface{}", typ)
+		}
+	}
+
+	m := MakeMap(TypeOf(map[string]interface{}{}))
+	m.SetMapIndex(ValueOf("a"), ValueOf(mapKey{1, 0.5}))
+	if v := m.Interface().(map[string]interface{})["a"]; v != (mapKey{1, 0.5}) {
+		t.Errorf("unexpected interface element: %v", v)
+	}
+}
+
+type comparableStruct struct {
+	A int
+	B string
+	c [2]float64
+}
+
+type notComparableStruct struct {
+	A int
+	B []byte
+}
+
+func TestComparable(t *testing.T) {
+	var iface interface{} = 3
+	for _, tc := range []struct {
+		v    interface{}
+		want bool
+	}{
+		{1, true},
+		{uint8(2), true},
+		{1.5, true},
+		{2i, true},
+		{"foo", true},
+		{true, true},
+		{&iface, true},
+		{make(chan int), true},
+		{[2]string{"a", "b"}, true},
+		{comparableStruct{}, true},
+		{[]int{1}, false},
+		{map[string]int{}, false},
+		{func() {}, false},
+		{notComparableStruct{}, false},
+		{[1][]int{}, false},
+		{struct{ S comparableStruct }{}, true},
+		{struct{ S notComparableStruct }{}, false},
+	} {
+		if got := TypeOf(tc.v).Comparable(); got != tc.want {
+			t.Errorf("TypeOf(%T).Comparable() = %v, want %v", tc.v, got, tc.want)
+		}
+		if got := ValueOf(tc.v).Comparable(); got != tc.want {
+			t.Errorf("ValueOf(%T).Comparable() = %v, want %v", tc.v, got, tc.want)
+		}
+	}
+
+	// The interface type itself is comparable, but the value it holds may not
+	// be.
+	ifaces := []interface{}{1, []int{1}, nil}
+	v := ValueOf(ifaces)
+	if !v.Index(0).Type().Comparable() {
+		t.Error("interface type should be comparable")
+	}
+	if !v.Index(0).Comparable() || v.Index(1).Comparable() || !v.Index(2).Comparable() {
+		t.Error("unexpected Value.Comparable result for interface values")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	x, y := 1, 1
+	for _, tc := range []struct {
+		a, b interface{}
+		want bool
+	}{
+		{1, 1, true},
+		{1, 2, false},
+		{1, int64(1), false},
+		{"foo", "foo", true},
+		{"foo", "bar", false},
+		{1.5, 1.5, true},
+		{&x, &x, true},
+		{&x, &y, false},
+		{[2]string{"a", "b"}, [2]string{"a", "b"}, true},
+		{[2]string{"a", "b"}, [2]string{"a", "c"}, false},
+		{comparableStruct{A: 1, c: [2]float64{1, 2}}, comparableStruct{A: 1, c: [2]float64{1, 2}}, true},
+		{comparableStruct{A: 1, c: [2]float64{1, 2}}, comparableStruct{A: 1, c: [2]float64{1, 3}}, false},
+	} {
+		if got := ValueOf(tc.a).Equal(ValueOf(tc.b)); got != tc.want {
+			t.Errorf("ValueOf(%#v).Equal(%#v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+	if !(Value{}).Equal(Value{}) || ValueOf(1).Equal(Value{}) {
+		t.Error("unexpected Equal result for invalid values")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Equal on slices to panic")
+		}
+	}()
+	ValueOf([]int{1}).Equal(ValueOf([]int{1}))
+}
+
+type telemetryPosition struct {
+	Lat, Lon float64
+}
+
+type telemetryDevice struct {
+	ID   string
+	Boot uint32
+}
+
+type telemetryStatus struct {
+	telemetryDevice
+	*telemetryPosition
+	Battery uint8
+	Pos     telemetryPosition
+	Tags    []string
+	Err     error
+	count   int
+}
+
+// structToMap converts a struct to a map with an entry for each exported
+// field. Nested structs become nested maps, while the fields of embedded
+// structs are flattened into the map itself.
+func structToMap(v Value) map[string]interface{} {
+	m := MakeMap(TypeOf(map[string]interface{}(nil)))
+	addFields(m, v)
+	return m.Interface().(map[string]interface{})
+}
+
+func addFields(m, v Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if field.Anonymous {
+			if value.Kind() == Pointer {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == Struct {
+				addFields(m, value)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if value.Kind() == Struct {
+			m.SetMapIndex(ValueOf(field.Name), ValueOf(structToMap(value)))
+		} else {
+			m.SetMapIndex(ValueOf(field.Name), value)
+		}
+	}
+}
+
+func TestStructToMap(t *testing.T) {
+	status := telemetryStatus{
+		telemetryDevice:   telemetryDevice{ID: "sensor-1", Boot: 3},
+		telemetryPosition: &telemetryPosition{Lat: 52.1, Lon: 4.3},
+		Battery:           87,
+		Pos:               telemetryPosition{Lat: 1.5, Lon: -2.5},
+		Tags:              []string{"a", "b"},
+		count:             5,
+	}
+	m := structToMap(ValueOf(status))
+
+	if len(m) != 8 {
+		t.Errorf("expected 8 keys, got %d: %v", len(m), m)
+	}
+	if id, ok := m["ID"].(string); !ok || id != "sensor-1" {
+		t.Errorf("unexpected ID: %#v", m["ID"])
+	}
+	if boot, ok := m["Boot"].(uint32); !ok || boot != 3 {
+		t.Errorf("unexpected Boot: %#v", m["Boot"])
+	}
+	if lat, ok := m["Lat"].(float64); !ok || lat != 52.1 {
+		t.Errorf("unexpected Lat: %#v", m["Lat"])
+	}
+	if battery, ok := m["Battery"].(uint8); !ok || battery != 87 {
+		t.Errorf("unexpected Battery: %#v", m["Battery"])
+	}
+	pos, ok := m["Pos"].(map[string]interface{})
+	if !ok || len(pos) != 2 || pos["Lat"] != 1.5 || pos["Lon"] != -2.5 {
+		t.Errorf("unexpected Pos: %#v", m["Pos"])
+	}
+	if tags, ok := m["Tags"].([]string); !ok || len(tags) != 2 || tags[1] != "b" {
+		t.Errorf("unexpected Tags: %#v", m["Tags"])
+	}
+	if err, ok := m["Err"]; !ok || err != nil {
+		t.Errorf("unexpected Err: %#v", m["Err"])
+	}
+	if _, ok := m["count"]; ok {
+		t.Error("unexported field is present in the map")
+	}
+
+	// A nil embedded pointer has no fields to add.
+	status.telemetryPosition = nil
+	if m := structToMap(ValueOf(status)); len(m) != 6 {
+		t.Errorf("expected 6 keys with a nil embedded pointer, got %d: %v", len(m), m)
+	}
+}
+
+func TestFieldByName(t *testing.T) {
+	typ := TypeOf(telemetryStatus{})
+	for _, tc := range []struct {
+		name  string
+		index []int
+	}{
+		{"Battery", []int{2}},
+		{"ID", []int{0, 0}},
+		{"telemetryDevice", []int{0}},
+		{"Lon", []int{1, 1}},
+		{"count", []int{6}},
+	} {
+		field, ok := typ.FieldByName(tc.name)
+		if !ok || field.Name != tc.name || len(field.Index) != len(tc.index) {
+			t.Errorf("FieldByName(%q) = %v, %v", tc.name, field.Index, ok)
+			continue
+		}
+		for i := range tc.index {
+			if field.Index[i] != tc.index[i] {
+				t.Errorf("FieldByName(%q).Index = %v, want %v", tc.name, field.Index, tc.index)
+			}
+		}
+		if f := typ.FieldByIndex(tc.index); f.Name != tc.name || f.Type != field.Type {
+			t.Errorf("FieldByIndex(%v) = %s %s, want %s %s", tc.index, f.Name, f.Type, tc.name, field.Type)
+		}
+	}
+	if _, ok := typ.FieldByName("Missing"); ok {
+		t.Error("found a field that doesn't exist")
+	}
+
+	// Two fields with the same name at the same depth cancel each other out,
+	// but a field at a shallower depth wins.
+	type A struct{ X, Y int }
+	type B struct{ X int }
+	type C struct {
+		A
+		B
+		Y string
+	}
+	if _, ok := TypeOf(C{}).FieldByName("X"); ok {
+		t.Error("found an ambiguous field")
+	}
+	if field, ok := TypeOf(C{}).FieldByName("Y"); !ok || field.Type != TypeOf("") {
+		t.Errorf("unexpected field for Y: %v %v", field.Type, ok)
+	}
+
+	// The same struct embedded twice at the same depth is ambiguous as well.
+	type D struct{ X int }
+	type E struct{ D }
+	type F struct{ D }
+	type G struct {
+		E
+		F
+	}
+	if _, ok := TypeOf(G{}).FieldByName("X"); ok {
+		t.Error("found an ambiguous field in a struct embedded twice")
+	}
+	type H struct {
+		E
+		D
+	}
+	if field, ok := TypeOf(H{}).FieldByName("X"); !ok || len(field.Index) != 2 || field.Index[0] != 1 {
+		t.Errorf("unexpected field for X: %v %v", field.Index, ok)
+	}
+
+	status := telemetryStatus{
+		telemetryDevice:   telemetryDevice{ID: "sensor-1"},
+		telemetryPosition: &telemetryPosition{Lat: 52.1, Lon: 4.3},
+	}
+	v := ValueOf(status)
+	if id := v.FieldByName("ID"); id.String() != "sensor-1" {
+		t.Errorf("unexpected value for ID: %v", id)
+	}
+	// Fields promoted from an unexported embedded struct are exported, but the
+	// embedded struct itself is not.
+	if !v.FieldByName("ID").CanInterface() || v.Field(0).CanInterface() {
+		t.Error("unexpected CanInterface for promoted field")
+	}
+	if lon := v.FieldByName("Lon"); lon.Float() != 4.3 {
+		t.Errorf("unexpected value for Lon: %v", lon)
+	}
+	if v.FieldByName("Missing").IsValid() {
+		t.Error("found a value for a field that doesn't exist")
+	}
+	status.telemetryPosition = nil
+	if _, err := ValueOf(status).FieldByIndexErr([]int{1, 0}); err == nil {
+		t.Error("expected an error for a nil embedded pointer")
+	}
+}
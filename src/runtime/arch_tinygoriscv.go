@@ -28,8 +28,11 @@ func procUnpin() {
 }
 
 func waitForEvents() {
+	// Check the runqueue with interrupts disabled, so that an interrupt that
+	// wakes up a goroutine just before the wfi instruction isn't missed. The
+	// wfi instruction itself still returns when an interrupt is pending.
 	mask := riscv.DisableInterrupts()
-	if !runqueue.Empty() {
+	if runqueue.Empty() {
 		riscv.Asm("wfi")
 	}
 	riscv.EnableInterrupts(mask)
@@ -22,6 +22,22 @@ package runtime
 // the 'comma-ok' value to true.
 // A receive operation on a closed channel is completed by zeroing the data
 // element of the receiving task and setting the 'comma-ok' value to false.
+//
+// All channel state is modified with interrupts disabled. This makes it
+// possible to use non-blocking channel operations (a select statement with a
+// default case, as well as len and cap) from interrupt handlers, for example to
+// pass data from an interrupt to a goroutine:
+//
+//	select {
+//	case ch <- value:
+//	default:
+//		// receiver is running behind
+//	}
+//
+// A goroutine that is woken up this way is only pushed onto the runqueue, it
+// will run once the interrupt has returned and the scheduler picks it up.
+// Blocking channel operations must not be used in an interrupt handler, as
+// there is no goroutine to pause.
 
 import (
 	"internal/task"
@@ -145,7 +161,14 @@ func chanLen(c *channel) int {
 	if c == nil {
 		return 0
 	}
-	return int(c.bufUsed)
+	// The buffer may be modified by an interrupt handler that sends on this
+	// channel. Read the length with interrupts disabled, like all other
+	// channel operations, so that it can't be torn (a uintptr needs two loads
+	// on AVR) and isn't cached by the compiler across a polling loop.
+	i := interrupt.Disable()
+	n := c.bufUsed
+	interrupt.Restore(i)
+	return int(n)
 }
 
 // wrapper for use in reflect
@@ -196,7 +219,7 @@ func (ch *channel) resumeRX(ok bool) unsafe.Pointer {
 	}
 
 	// push task onto runqueue
-	runqueue.Push(b.t)
+	runqueuePushBack(b.t)
 
 	return dst
 }
@@ -223,7 +246,7 @@ func (ch *channel) resumeTX() unsafe.Pointer {
 	}
 
 	// push task onto runqueue
-	runqueue.Push(b.t)
+	runqueuePushBack(b.t)
 
 	return src
 }
@@ -343,7 +366,7 @@ func (ch *channel) trySend(value unsafe.Pointer) bool {
 		return false
 	case chanStateClosed:
 		interrupt.Restore(i)
-		runtimePanic("send on closed channel")
+		runtimeErrorPanic("send on closed channel")
 	default:
 		interrupt.Restore(i)
 		runtimePanic("invalid channel state")
@@ -539,14 +562,14 @@ func chanRecv(ch *channel, value unsafe.Pointer, blockedlist *channelBlockedList
 func chanClose(ch *channel) {
 	if ch == nil {
 		// Not allowed by the language spec.
-		runtimePanic("close of nil channel")
+		runtimeErrorPanic("close of nil channel")
 	}
 	i := interrupt.Disable()
 	switch ch.state {
 	case chanStateClosed:
 		// Not allowed by the language spec.
 		interrupt.Restore(i)
-		runtimePanic("close of closed channel")
+		runtimeErrorPanic("close of closed channel")
 	case chanStateSend:
 		// This panic should ideally on the sending side, not in this goroutine.
 		// But when a goroutine tries to send while the channel is being closed,
@@ -572,8 +595,9 @@ func chanClose(ch *channel) {
 // perhaps the most complicated statement in the Go spec. It returns the
 // selected index and the 'comma-ok' value.
 //
-// TODO: do this in a round-robin fashion (as specified in the Go spec) instead
-// of picking the first one that can proceed.
+// If several cases can proceed immediately, one of them is picked
+// pseudo-randomly (see tryChanSelect). Otherwise the first case to become ready
+// is selected.
 func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelBlockedList) (uintptr, bool) {
 	istate := interrupt.Disable()
 
@@ -640,25 +664,57 @@ func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelB
 	return (uintptr(t.Ptr) - uintptr(unsafe.Pointer(&states[0]))) / unsafe.Sizeof(chanSelectState{}), t.Data != 0
 }
 
+// chanTrySend sends a single value over the channel, if that can be done
+// without blocking. It returns whether the value was sent. It is used for select
+// statements with a single send case and a default case.
+// May panic if the channel is closed.
+func chanTrySend(ch *channel, value unsafe.Pointer) bool {
+	i := interrupt.Disable()
+	sent := ch.trySend(value)
+	if sent {
+		chanDebug(ch)
+	}
+	interrupt.Restore(i)
+	return sent
+}
+
+// chanTryRecv receives a single value over the channel, if one is immediately
+// available. It returns whether a value was received and the comma-ok value. It
+// is used for select statements with a single receive case and a default case.
+func chanTryRecv(ch *channel, value unsafe.Pointer) (bool, bool) {
+	i := interrupt.Disable()
+	rx, ok := ch.tryRecv(value)
+	if rx {
+		chanDebug(ch)
+	}
+	interrupt.Restore(i)
+	return rx, ok
+}
+
 // tryChanSelect is like chanSelect, but it does a non-blocking select operation.
+// The cases are tried in a pseudo-random order, so that a case that is always
+// ready doesn't starve the others, as required by the Go specification.
 func tryChanSelect(recvbuf unsafe.Pointer, states []chanSelectState) (uintptr, bool) {
 	istate := interrupt.Disable()
 
 	// See whether we can receive from one of the channels.
-	for i, state := range states {
+	n := uintptr(len(states))
+	i, step := selectOrder(n)
+	for j := uintptr(0); j < n; j, i = j+1, (i+step)%n {
+		state := states[i]
 		if state.value == nil {
 			// A receive operation.
 			if rx, ok := state.ch.tryRecv(recvbuf); rx {
 				chanDebug(state.ch)
 				interrupt.Restore(istate)
-				return uintptr(i), ok
+				return i, ok
 			}
 		} else {
 			// A send operation: state.value is not nil.
 			if state.ch.trySend(state.value) {
 				chanDebug(state.ch)
 				interrupt.Restore(istate)
-				return uintptr(i), true
+				return i, true
 			}
 		}
 	}
@@ -666,3 +722,31 @@ func tryChanSelect(recvbuf unsafe.Pointer, states []chanSelectState) (uintptr, b
 	interrupt.Restore(istate)
 	return ^uintptr(0), false
 }
+
+// selectOrder returns a pseudo-random order in which to try the n cases of a
+// select statement: the first case to try, and the distance to the next case
+// (modulo n). The step is coprime with n, so that all cases are visited once.
+// This doesn't produce every possible permutation, but it does make sure that
+// of any two ready cases, each is tried first about half of the time.
+func selectOrder(n uintptr) (start, step uintptr) {
+	if n <= 1 {
+		return 0, 1
+	}
+	r := fastrand()
+	start = uintptr(r) % n
+	step = uintptr(r>>16)%(n-1) + 1
+	for gcd(step, n) != 1 {
+		step++
+		if step == n {
+			step = 1
+		}
+	}
+	return start, step
+}
+
+func gcd(a, b uintptr) uintptr {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
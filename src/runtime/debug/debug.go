@@ -1,4 +1,5 @@
-// Package debug is a dummy package that is not yet implemented.
+// Package debug contains facilities for programs to debug themselves while
+// they are running. Only a part of it is implemented.
 package debug
 
 // SetMaxStack sets the maximum amount of memory that can be used by a single
@@ -9,34 +10,20 @@ func SetMaxStack(n int) int {
 	return n
 }
 
+// SetGCPercent sets the garbage collection target percentage: a collection is
+// triggered when the ratio of freshly allocated data to live data remaining
+// after the previous collection reaches this percentage. A negative percentage
+// disables automatic garbage collection. SetGCPercent returns the previous
+// setting. The initial setting is 100.
+//
+// With the conservative GC, a collection is only triggered before the heap is
+// full when the percentage is below 100. Higher values leave more headroom when
+// growing the heap, on systems where this is possible.
+func SetGCPercent(percent int) int // implemented in the runtime
+
 // Stack returns a formatted stack trace of the goroutine that calls it.
 //
 // Not implemented.
 func Stack() []byte {
 	return nil
 }
-
-// ReadBuildInfo returns the build information embedded
-// in the running binary. The information is available only
-// in binaries built with module support.
-//
-// Not implemented.
-func ReadBuildInfo() (info *BuildInfo, ok bool) {
-	return nil, false
-}
-
-// BuildInfo represents the build information read from
-// the running binary.
-type BuildInfo struct {
-	Path string    // The main package path
-	Main Module    // The module containing the main package
-	Deps []*Module // Module dependencies
-}
-
-// Module represents a module.
-type Module struct {
-	Path    string  // module path
-	Version string  // module version
-	Sum     string  // checksum
-	Replace *Module // replaced by this module
-}
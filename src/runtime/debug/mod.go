@@ -0,0 +1,130 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+// modinfo is the build information of the program, in the text format of
+// BuildInfo.String in Go. It is set by the compiler.
+var modinfo string
+
+// ReadBuildInfo returns the build information embedded
+// in the running binary. The information is available only
+// in binaries built with module support.
+func ReadBuildInfo() (info *BuildInfo, ok bool) {
+	if modinfo == "" {
+		return nil, false
+	}
+	return parseBuildInfo(modinfo), true
+}
+
+// BuildInfo represents the build information read from a Go binary.
+type BuildInfo struct {
+	// GoVersion is the version of the Go toolchain that built the binary
+	// (for example, "go1.19.2").
+	GoVersion string
+
+	// Path is the package path of the main package for the binary
+	// (for example, "golang.org/x/tools/cmd/stringer").
+	Path string
+
+	// Main describes the module that contains the main package for the binary.
+	Main Module
+
+	// Deps describes all the dependency modules, both direct and indirect,
+	// that contributed packages to the build of this binary.
+	Deps []*Module
+
+	// Settings describes the build settings used to build the binary.
+	Settings []BuildSetting
+}
+
+// A Module describes a single module included in a build.
+type Module struct {
+	Path    string  // module path
+	Version string  // module version
+	Sum     string  // checksum
+	Replace *Module // replaced by this module
+}
+
+// A BuildSetting is a key-value pair describing one setting that influenced a build.
+//
+// Defined keys include:
+//
+//   - -buildmode: the buildmode flag used (typically "exe")
+//   - -compiler: the compiler toolchain flag used (typically "gc")
+//   - CGO_ENABLED: the effective CGO_ENABLED environment variable
+//   - CGO_CFLAGS: the effective CGO_CFLAGS environment variable
+//   - CGO_CPPFLAGS: the effective CGO_CPPFLAGS environment variable
+//   - CGO_CXXFLAGS:  the effective CGO_CPPFLAGS environment variable
+//   - CGO_LDFLAGS: the effective CGO_CPPFLAGS environment variable
+//   - GOARCH: the architecture target
+//   - GOAMD64/GOARM/GO386/etc: the architecture feature level for GOARCH
+//   - GOOS: the operating system target
+//   - vcs: the version control system for the source tree where the build ran
+//   - vcs.revision: the revision identifier for the current commit or checkout
+//   - vcs.time: the modification time associated with vcs.revision, in RFC3339 format
+//   - vcs.modified: true or false indicating whether the source tree had local modifications
+type BuildSetting struct {
+	// Key and Value describe the build setting.
+	// Key must not contain an equals sign, space, tab, or newline.
+	// Value must not contain newlines ('\n').
+	Key, Value string
+}
+
+// parseBuildInfo parses the build information stored by the compiler. This is
+// a simpler version of ParseBuildInfo in Go, which also parses quoted keys and
+// values. It is written without the fmt, strconv and strings packages, so that
+// importing runtime/debug doesn't pull them in. The compiler never quotes a key
+// or value, and only writes valid build information.
+func parseBuildInfo(data string) *BuildInfo {
+	bi := new(BuildInfo)
+	var last *Module
+	for data != "" {
+		var line, kind, elem string
+		line, data = cut(data, '\n')
+		kind, elem = cut(line, '\t')
+		switch kind {
+		case "go":
+			bi.GoVersion = elem
+		case "path":
+			bi.Path = elem
+		case "mod":
+			last = &bi.Main
+			*last = parseModule(elem)
+		case "dep":
+			last = new(Module)
+			bi.Deps = append(bi.Deps, last)
+			*last = parseModule(elem)
+		case "=>":
+			if last != nil {
+				replace := parseModule(elem)
+				last.Replace = &replace
+				last = nil
+			}
+		case "build":
+			key, value := cut(elem, '=')
+			bi.Settings = append(bi.Settings, BuildSetting{Key: key, Value: value})
+		}
+	}
+	return bi
+}
+
+// parseModule parses the tab separated path, version and checksum of a module.
+func parseModule(elem string) Module {
+	var m Module
+	m.Path, elem = cut(elem, '\t')
+	m.Version, m.Sum = cut(elem, '\t')
+	return m
+}
+
+// cut slices s around the first instance of sep, returning the text before and
+// after it. If sep doesn't appear in s, it returns s and the empty string.
+func cut(s string, sep byte) (before, after string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == sep {
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
@@ -6,3 +6,10 @@ type Error interface {
 
 	RuntimeError()
 }
+
+// These errors are used by math/bits (through //go:linkname) when a division
+// overflows or divides by zero.
+var (
+	divideError   = error(runtimeError{"integer divide by zero"})
+	overflowError = error(runtimeError{"integer overflow"})
+)
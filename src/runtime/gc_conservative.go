@@ -56,6 +56,11 @@ var (
 	gcTotalAlloc  uint64         // total number of bytes allocated
 	gcMallocs     uint64         // total number of allocations
 	gcFrees       uint64         // total number of objects freed
+	gcNumGC       uint32         // number of completed GC cycles
+	gcPercent     = 100          // see debug.SetGCPercent
+	gcLiveBytes   uintptr        // bytes in use directly after the last GC cycle
+	gcHeapInUse   uintptr        // bytes in use (live and garbage) at the moment
+	gcNextTrigger uintptr        // run a GC cycle when gcHeapInUse exceeds this
 )
 
 // zeroSizedAlloc is just a sentinel that gets returned when allocating 0 bytes.
@@ -195,6 +200,8 @@ func initHeap() {
 	// Set all block states to 'free'.
 	metadataSize := heapEnd - uintptr(metadataStart)
 	memzero(unsafe.Pointer(metadataStart), metadataSize)
+
+	updateGCTrigger()
 }
 
 // setHeapEnd is called to expand the heap. The heap can only grow, not shrink.
@@ -226,6 +233,8 @@ func setHeapEnd(newHeapEnd uintptr) {
 	if gcAsserts && uintptr(metadataStart) < uintptr(oldMetadataStart)+oldMetadataSize {
 		panic("gc: heap did not grow enough at once")
 	}
+
+	updateGCTrigger()
 }
 
 // calculateHeapAddresses initializes variables such as metadataStart and
@@ -270,8 +279,19 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 	gcTotalAlloc += uint64(size)
 	gcMallocs++
 
+	if heapGuard && size >= heapGuardMinSize {
+		return allocGuarded(size)
+	}
+
 	neededBlocks := (size + (bytesPerBlock - 1)) / bytesPerBlock
 
+	// Run a GC cycle early if the heap has grown too much since the last
+	// cycle, as configured by debug.SetGCPercent.
+	gcHeapInUse += neededBlocks * bytesPerBlock
+	if gcPercent >= 0 && gcHeapInUse > gcNextTrigger {
+		runGC()
+	}
+
 	// Continue looping until a run of free blocks has been found that fits the
 	// requested size.
 	index := nextAlloc
@@ -286,13 +306,30 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 				// could be found. Run a garbage collection cycle to reclaim
 				// free memory and try again.
 				heapScanCount = 2
-				freeBytes := runGC()
-				heapSize := uintptr(metadataStart) - heapStart
-				if freeBytes < heapSize/3 {
-					// Ensure there is at least 33% headroom.
-					// This percentage was arbitrarily chosen, and may need to
-					// be tuned in the future.
-					growHeap()
+				if gcPercent < 0 {
+					// Automatic garbage collection has been disabled, so the
+					// only option left is to grow the heap.
+					if !growHeap() {
+						runtimePanic("out of memory")
+					}
+				} else {
+					// With a large GC percentage, the next GC cycle may only
+					// be triggered beyond the end of the heap. Grow the heap
+					// in that case instead of running a GC cycle, if that is
+					// possible on this system.
+					heapSize := uintptr(metadataStart) - heapStart
+					if gcNextTrigger <= heapSize || !growHeap() {
+						freeBytes := runGC()
+						heapSize = uintptr(metadataStart) - heapStart
+						if uint64(freeBytes)*uint64(gcPercent+200) < uint64(heapSize)*uint64(gcPercent) {
+							// Ensure there is enough headroom: 33% with the
+							// default GC percentage of 100, more if the GC
+							// percentage is set to a higher value.
+							// This percentage was arbitrarily chosen, and may
+							// need to be tuned in the future.
+							growHeap()
+						}
+					}
 				}
 			} else {
 				// Even after garbage collection, no free memory could be found.
@@ -351,6 +388,9 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 			// Return a pointer to this allocation.
 			pointer := thisAlloc.pointer()
 			memzero(pointer, size)
+			if heapProfile {
+				profileAlloc(uintptr(pointer), size)
+			}
 			return pointer
 		}
 	}
@@ -362,11 +402,16 @@ func realloc(ptr unsafe.Pointer, size uintptr) unsafe.Pointer {
 	}
 
 	ptrAddress := uintptr(ptr)
-	endOfTailAddress := blockFromAddr(ptrAddress).findNext().address()
+	var oldSize uintptr
+	if heapGuard && !looksLikePointer(ptrAddress) {
+		oldSize = guardedObjectSize(ptrAddress)
+	} else {
+		endOfTailAddress := blockFromAddr(ptrAddress).findNext().address()
 
-	// this might be a few bytes longer than the original size of
-	// ptr, because we align to full blocks of size bytesPerBlock
-	oldSize := endOfTailAddress - ptrAddress
+		// this might be a few bytes longer than the original size of
+		// ptr, because we align to full blocks of size bytesPerBlock
+		oldSize = endOfTailAddress - ptrAddress
+	}
 	if size <= oldSize {
 		return ptr
 	}
@@ -395,6 +440,9 @@ func runGC() (freeBytes uintptr) {
 		println("running collection cycle...")
 	}
 
+	// Objects in a sync.Pool may be freed in every GC cycle.
+	clearPools()
+
 	// Mark phase: mark all reachable objects, recursively.
 	markStack()
 	markGlobals()
@@ -430,9 +478,23 @@ func runGC() (freeBytes uintptr) {
 		finishMark()
 	}
 
+	// Find out which sampled objects in the heap profile are about to be freed.
+	if heapProfile {
+		sweepProfile()
+	}
+
 	// Sweep phase: free all non-marked objects and unmark marked objects for
 	// the next collection cycle.
 	freeBytes = sweep()
+	if heapGuard {
+		sweepGuarded()
+	}
+
+	// Calculate when the next GC cycle should be triggered.
+	gcNumGC++
+	gcLiveBytes = uintptr(metadataStart) - heapStart - freeBytes
+	gcHeapInUse = gcLiveBytes
+	updateGCTrigger()
 
 	// Show how much has been sweeped, for debugging.
 	if gcDebug {
@@ -442,6 +504,25 @@ func runGC() (freeBytes uintptr) {
 	return
 }
 
+// updateGCTrigger recalculates gcNextTrigger, the heap usage at which the next
+// GC cycle will run. The heap may grow to gcPercent percent above the live heap
+// of the previous cycle, but a GC cycle is never triggered before gcPercent
+// percent of the entire heap is in use. This means that with the default of 100
+// a GC cycle only happens when the heap is full. With a larger percentage the
+// trigger may be beyond the end of the heap: when the heap is full, it is grown
+// instead of running a GC cycle (if the system supports growing the heap).
+func updateGCTrigger() {
+	heapSize := uint64(uintptr(metadataStart) - heapStart)
+	trigger := uint64(gcLiveBytes) + uint64(gcLiveBytes)*uint64(gcPercent)/100
+	if minTrigger := heapSize * uint64(gcPercent) / 100; trigger < minTrigger {
+		trigger = minTrigger
+	}
+	if trigger > uint64(^uintptr(0)) {
+		trigger = uint64(^uintptr(0))
+	}
+	gcNextTrigger = uintptr(trigger)
+}
+
 // markRoots reads all pointers from start to end (exclusive) and if they look
 // like a heap pointer and are unmarked, marks them and scans that object as
 // well (recursively). The start and end parameters must be valid pointers and
@@ -498,6 +579,9 @@ func startMark(root gcBlock) {
 
 			if !looksLikePointer(word) {
 				// Not a heap pointer.
+				if heapGuard {
+					markGuarded(word)
+				}
 				continue
 			}
 
@@ -546,17 +630,25 @@ func startMark(root gcBlock) {
 
 // finishMark finishes the marking process by processing all stack overflows.
 func finishMark() {
-	for stackOverflow {
-		// Re-mark all blocks.
-		stackOverflow = false
-		for block := gcBlock(0); block < endBlock; block++ {
-			if block.state() != blockStateMark {
-				// Block is not marked, so we do not need to rescan it.
-				continue
+	for {
+		for stackOverflow {
+			// Re-mark all blocks.
+			stackOverflow = false
+			for block := gcBlock(0); block < endBlock; block++ {
+				if block.state() != blockStateMark {
+					// Block is not marked, so we do not need to rescan it.
+					continue
+				}
+
+				// Re-mark the block.
+				startMark(block)
 			}
+		}
 
-			// Re-mark the block.
-			startMark(block)
+		// Scanning guarded objects may mark new heap blocks, which may in
+		// turn overflow the mark stack.
+		if !heapGuard || !scanGuarded() {
+			break
 		}
 	}
 }
@@ -578,6 +670,8 @@ func markRoot(addr, root uintptr) {
 			}
 			startMark(head)
 		}
+	} else if heapGuard {
+		markGuarded(root)
 	}
 }
 
@@ -641,6 +735,24 @@ func dumpHeap() {
 	}
 }
 
+// setGCPercent sets the garbage collection target percentage: a collection is
+// triggered when the ratio of freshly allocated data to live data remaining
+// after the previous collection reaches this percentage. A negative percentage
+// disables automatic garbage collection. It returns the previous setting.
+//
+//go:linkname setGCPercent runtime/debug.SetGCPercent
+func setGCPercent(percent int) int {
+	old := gcPercent
+	if percent < 0 {
+		percent = -1
+	}
+	gcPercent = percent
+	if percent >= 0 {
+		updateGCTrigger()
+	}
+	return old
+}
+
 func KeepAlive(x interface{}) {
 	// Unimplemented. Only required with SetFinalizer().
 }
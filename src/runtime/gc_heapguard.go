@@ -0,0 +1,193 @@
+//go:build gc.conservative && tinygo.heapguard
+// +build gc.conservative,tinygo.heapguard
+
+package runtime
+
+// This file implements guard pages for large heap allocations, enabled with the
+// -heap-guard flag. It is meant for debugging memory corruption on hosted
+// systems: every allocation of at least heapGuardMinSize bytes gets its own
+// memory mapping outside the regular heap, placed so that the object ends right
+// before an inaccessible page. Another inaccessible page sits right before the
+// mapping. Any read or write just past the end (or before the start) of such an
+// object therefore results in a segmentation fault at the exact instruction
+// that caused the overrun, instead of silently corrupting other objects.
+//
+// Guarded objects are still garbage collected: the conservative GC marks them
+// like regular heap objects (see markGuarded) and unmaps them when they are no
+// longer referenced.
+
+import "unsafe"
+
+const heapGuard = true
+
+// Allocations smaller than this are allocated on the regular heap. Every
+// guarded allocation needs at least three pages of address space and two extra
+// memory mappings, so guarding small allocations would be very expensive.
+const heapGuardMinSize = 256
+
+// Run a GC cycle when this many bytes have been mapped for guarded objects
+// since the last cycle, even if the regular heap isn't full yet.
+const heapGuardMinTrigger = 4 * 1024 * 1024
+
+const flag_PROT_NONE = 0x0
+
+//export mprotect
+func mprotect(addr unsafe.Pointer, length uintptr, prot int) int
+
+//export munmap
+func munmap(addr unsafe.Pointer, length uintptr) int
+
+// Mark state of a guarded object.
+const (
+	guardUnmarked = iota // not (yet) found to be reachable
+	guardMarked          // reachable, but the contents haven't been scanned yet
+	guardScanned         // reachable and scanned
+)
+
+// guardedObject describes a single guarded allocation. These descriptors live
+// on the regular heap and are kept alive by the guardedObjects list.
+//
+// The object itself spans end-size..end. The start of the object is
+// deliberately not stored: the GC scans these descriptors like any other heap
+// object, and a pointer to the start would keep every object alive.
+type guardedObject struct {
+	next        *guardedObject
+	mapping     uintptr // start of the memory mapping, including the guard page
+	mappingSize uintptr
+	end         uintptr // end of the object, which is the start of a guard page
+	size        uintptr
+	state       uint8
+}
+
+var (
+	guardedObjects     *guardedObject // linked list of all guarded objects
+	guardedLow         uintptr        // lowest address of any guarded object
+	guardedHigh        uintptr        // highest address of any guarded object
+	guardedBytes       uintptr        // bytes mapped for guarded objects
+	guardedNextTrigger uintptr        = heapGuardMinTrigger
+	guardMarkPending   bool           // some guarded objects are marked but not scanned
+)
+
+// allocGuarded allocates an object in a separate memory mapping with a guard
+// page on either side. The returned memory is zeroed.
+func allocGuarded(size uintptr) unsafe.Pointer {
+	// Only round up to a multiple of the pointer alignment (instead of using
+	// align), so that overruns are detected as soon as possible.
+	size = (size + unsafe.Alignof(size) - 1) &^ (unsafe.Alignof(size) - 1)
+	pageSize := uintptr(libc_getpagesize())
+	objectPages := (size + pageSize - 1) &^ (pageSize - 1)
+	mappingSize := pageSize + objectPages + pageSize
+
+	guardedBytes += mappingSize
+	if gcPercent >= 0 && guardedBytes > guardedNextTrigger {
+		runGC()
+		guardedBytes += mappingSize
+	}
+
+	// Map the whole region inaccessible, and then make the object accessible.
+	addr := mmap(nil, mappingSize, flag_PROT_NONE, flag_MAP_PRIVATE|flag_MAP_ANONYMOUS, -1, 0)
+	if addr == unsafe.Pointer(^uintptr(0)) {
+		runtimePanic("out of memory")
+	}
+	mapping := uintptr(addr)
+	if mprotect(unsafe.Pointer(mapping+pageSize), objectPages, flag_PROT_READ|flag_PROT_WRITE) != 0 {
+		runtimePanic("heap guard: could not map object")
+	}
+
+	end := mapping + pageSize + objectPages
+	start := end - size
+	guardedObjects = &guardedObject{
+		next:        guardedObjects,
+		mapping:     mapping,
+		mappingSize: mappingSize,
+		end:         end,
+		size:        size,
+	}
+	if guardedLow == 0 || start < guardedLow {
+		guardedLow = start
+	}
+	if end > guardedHigh {
+		guardedHigh = end
+	}
+
+	// Fresh anonymous mappings are already zeroed.
+	return unsafe.Pointer(start)
+}
+
+// findGuarded returns the guarded object that contains the given address, or
+// nil if there is none.
+func findGuarded(addr uintptr) *guardedObject {
+	if addr < guardedLow || addr >= guardedHigh {
+		// Fast path for most values that are not pointers.
+		return nil
+	}
+	for obj := guardedObjects; obj != nil; obj = obj.next {
+		if addr >= obj.end-obj.size && addr < obj.end {
+			return obj
+		}
+	}
+	return nil
+}
+
+// guardedObjectSize returns the number of bytes from ptr to the end of the
+// guarded object containing ptr.
+func guardedObjectSize(ptr uintptr) uintptr {
+	obj := findGuarded(ptr)
+	if obj == nil {
+		runtimePanic("heap guard: invalid pointer")
+	}
+	return obj.end - ptr
+}
+
+// markGuarded marks the guarded object containing the given address, if there
+// is one. The contents of the object are scanned later, in scanGuarded,
+// rather than recursively.
+func markGuarded(addr uintptr) {
+	obj := findGuarded(addr)
+	if obj != nil && obj.state == guardUnmarked {
+		obj.state = guardMarked
+		guardMarkPending = true
+	}
+}
+
+// scanGuarded scans all guarded objects that have been marked but not yet
+// scanned. It returns whether it scanned any object, in which case new objects
+// may have been marked.
+func scanGuarded() bool {
+	if !guardMarkPending {
+		return false
+	}
+	guardMarkPending = false
+	for obj := guardedObjects; obj != nil; obj = obj.next {
+		if obj.state == guardMarked {
+			obj.state = guardScanned
+			markRoots(obj.end-obj.size, obj.end)
+		}
+	}
+	return true
+}
+
+// sweepGuarded unmaps all guarded objects that were not marked in the last GC
+// cycle and unmarks the others.
+func sweepGuarded() {
+	guardedBytes = 0
+	prev := &guardedObjects
+	for obj := guardedObjects; obj != nil; obj = obj.next {
+		if obj.state == guardUnmarked {
+			munmap(unsafe.Pointer(obj.mapping), obj.mappingSize)
+			*prev = obj.next
+			gcFrees++
+			continue
+		}
+		obj.state = guardUnmarked
+		guardedBytes += obj.mappingSize
+		prev = &obj.next
+	}
+
+	// Allow the guarded objects to grow by gcPercent before the next cycle,
+	// like the regular heap.
+	guardedNextTrigger = guardedBytes + guardedBytes*uintptr(gcPercent)/100
+	if guardedNextTrigger < heapGuardMinTrigger {
+		guardedNextTrigger = heapGuardMinTrigger
+	}
+}
@@ -0,0 +1,30 @@
+//go:build gc.conservative && !tinygo.heapguard
+// +build gc.conservative,!tinygo.heapguard
+
+package runtime
+
+// Heap guard pages are disabled. See gc_heapguard.go.
+
+import "unsafe"
+
+const heapGuard = false
+
+const heapGuardMinSize = 0
+
+func allocGuarded(size uintptr) unsafe.Pointer {
+	return nil
+}
+
+func guardedObjectSize(ptr uintptr) uintptr {
+	return 0
+}
+
+func markGuarded(addr uintptr) {
+}
+
+func scanGuarded() bool {
+	return false
+}
+
+func sweepGuarded() {
+}
@@ -0,0 +1,250 @@
+//go:build gc.conservative && tinygo.heapprofile
+// +build gc.conservative,tinygo.heapprofile
+
+package runtime
+
+// This file implements sampling of heap allocations for runtime.MemProfile,
+// enabled with the -heap-profile flag. It is meant for finding out where a
+// hosted program allocates its memory, using runtime/pprof and go tool pprof.
+//
+// On average one allocation is sampled per MemProfileRate bytes allocated. For
+// every sampled allocation, the stack is recorded by following the chain of
+// frame pointers (-heap-profile makes sure all functions keep a frame pointer).
+// Allocations with the same stack are counted together in a bucket. The
+// sampled objects are remembered until the GC finds they are no longer
+// reachable, at which point they are counted as freed in their bucket.
+//
+// All profiling data is stored in fixed-size tables, so that sampling an
+// allocation never allocates itself. When the tables are full, new samples are
+// dropped.
+
+import (
+	"internal/task"
+	"unsafe"
+)
+
+const heapProfile = true
+
+const (
+	heapProfileBuckets = 512  // number of different allocation stacks that can be recorded
+	heapProfileObjects = 4096 // number of sampled objects that can be live at the same time
+	heapProfileDepth   = len(MemProfileRecord{}.Stack0)
+)
+
+// heapProfileBucket counts the sampled allocations with a particular stack.
+type heapProfileBucket struct {
+	stack       [heapProfileDepth]uintptr // zero-terminated if shorter than heapProfileDepth
+	hash        uintptr
+	allocs      int64
+	allocBytes  int64
+	frees       int64
+	freeBytes   int64
+	initialized bool
+}
+
+// heapProfileObject is a sampled object that has not been freed yet. The
+// address of the object is stored inverted: these descriptors are scanned by
+// the GC like any other global, and the object address would keep every
+// sampled object alive.
+type heapProfileObject struct {
+	invertedAddr uintptr
+	size         uintptr
+	bucket       *heapProfileBucket
+}
+
+var (
+	heapProfileBucketTable [heapProfileBuckets]heapProfileBucket // hash table, using linear probing
+	heapProfileObjectList  [heapProfileObjects]heapProfileObject
+	heapProfileNumObjects  int
+	heapProfileNextSample  uintptr // number of bytes to allocate before the next sample
+
+	// Stack of the allocation that is being sampled. This is a global, as a
+	// local variable might be allocated on the heap.
+	heapProfileStackBuf [heapProfileDepth]uintptr
+)
+
+// Return the frame address of the current function (or of a parent function for
+// levels above 0). This intrinsic only returns the frame pointer if the
+// function keeps one, which is ensured by the -heap-profile flag.
+//
+//export llvm.frameaddress.p0i8
+func frameaddress(level int32) unsafe.Pointer
+
+// profileAlloc is called for every allocation on the regular heap, and decides
+// whether to sample it. It is never inlined, so that the number of profiler
+// frames on the stack is always the same (see heapProfileStack).
+//
+//go:noinline
+func profileAlloc(ptr, size uintptr) {
+	rate := MemProfileRate
+	if rate <= 0 {
+		return
+	}
+	if rate != 1 {
+		if size < heapProfileNextSample {
+			heapProfileNextSample -= size
+			return
+		}
+		heapProfileNextSample = heapProfileSampleDistance(rate)
+	}
+	if heapProfileNumObjects == len(heapProfileObjectList) {
+		// Too many sampled objects are still alive.
+		return
+	}
+
+	heapProfileStack(&heapProfileStackBuf)
+	bucket := heapProfileLookupBucket(&heapProfileStackBuf)
+	if bucket == nil {
+		// Too many different stacks have already been recorded.
+		return
+	}
+	bucket.allocs++
+	bucket.allocBytes += int64(size)
+	heapProfileObjectList[heapProfileNumObjects] = heapProfileObject{
+		invertedAddr: ^ptr,
+		size:         size,
+		bucket:       bucket,
+	}
+	heapProfileNumObjects++
+}
+
+// heapProfileStack stores the return addresses of the caller of alloc and its
+// parents in stack, by following the chain of frame pointers. It must be called
+// directly from profileAlloc, and is never inlined for the same reason.
+//
+// On all supported architectures, a frame pointer points to the saved frame
+// pointer of the parent frame, directly followed by the return address.
+//
+//go:noinline
+func heapProfileStack(stack *[heapProfileDepth]uintptr) {
+	// The main stack ends at stackTop. Goroutine stacks end with a zero frame
+	// pointer, see task.state.archInit.
+	top := ^uintptr(0)
+	if task.OnSystemStack() {
+		top = stackTop
+	}
+
+	// Skip the return addresses into profileAlloc and alloc.
+	fp := uintptr(frameaddress(0))
+	skip := 2
+	n := 0
+	*stack = [heapProfileDepth]uintptr{}
+	for n < len(stack) && fp != 0 && fp%unsafe.Alignof(fp) == 0 {
+		pc := *(*uintptr)(unsafe.Pointer(fp + unsafe.Sizeof(fp)))
+		if pc == 0 {
+			break
+		}
+		if skip > 0 {
+			skip--
+		} else {
+			stack[n] = pc
+			n++
+		}
+		next := *(*uintptr)(unsafe.Pointer(fp))
+		if next <= fp || next >= top {
+			// Stacks grow down, so this isn't a valid parent frame.
+			break
+		}
+		fp = next
+	}
+}
+
+// heapProfileLookupBucket returns the bucket for the given stack, creating it
+// if needed. It returns nil if the bucket table is full.
+func heapProfileLookupBucket(stack *[heapProfileDepth]uintptr) *heapProfileBucket {
+	// FNV-1a hash of the return addresses.
+	hash := uintptr(2166136261)
+	for _, pc := range stack {
+		if pc == 0 {
+			break
+		}
+		hash = (hash ^ pc) * 16777619
+	}
+
+	index := hash % heapProfileBuckets
+	for i := 0; i < heapProfileBuckets; i++ {
+		bucket := &heapProfileBucketTable[index]
+		if !bucket.initialized {
+			bucket.initialized = true
+			bucket.hash = hash
+			bucket.stack = *stack
+			return bucket
+		}
+		if bucket.hash == hash && bucket.stack == *stack {
+			return bucket
+		}
+		index = (index + 1) % heapProfileBuckets
+	}
+	return nil
+}
+
+// heapProfileSampleDistance returns the number of bytes to allocate before the
+// next sample. Like upstream Go, it is taken from an exponential distribution
+// with the given mean, so that the chance that an allocation is sampled scales
+// with its size, independent of the sizes of earlier allocations.
+func heapProfileSampleDistance(mean int) uintptr {
+	// A random number in the range (0, 1].
+	u := float64(fastrand()>>8+1) / (1 << 24)
+	return uintptr(-heapProfileLog(u) * float64(mean))
+}
+
+// heapProfileLog returns the natural logarithm of x, for 0 < x <= 1. It is
+// accurate to about 1e-5, which is plenty for picking a sample distance.
+func heapProfileLog(x float64) float64 {
+	// Split x into m * 2^exp with 1 <= m < 2.
+	bits := float64bits(x)
+	exp := int(bits>>52&0x7ff) - 1023
+	m := float64frombits(bits&^(0x7ff<<52) | 1023<<52)
+
+	// ln(m) = 2*atanh(z) with z = (m-1)/(m+1), where 0 <= z < 1/3.
+	z := (m - 1) / (m + 1)
+	z2 := z * z
+	return float64(exp)*0.6931471805599453 + 2*z*(1+z2*(1.0/3+z2*(1.0/5+z2*(1.0/7))))
+}
+
+// sweepProfile counts all sampled objects that were not marked in the last
+// mark phase as freed. It must be called between the mark and sweep phases.
+func sweepProfile() {
+	for i := 0; i < heapProfileNumObjects; {
+		obj := &heapProfileObjectList[i]
+		if blockFromAddr(^obj.invertedAddr).state() == blockStateMark {
+			// Still reachable.
+			i++
+			continue
+		}
+		obj.bucket.frees++
+		obj.bucket.freeBytes += int64(obj.size)
+
+		// Replace this object with the last one in the list.
+		heapProfileNumObjects--
+		*obj = heapProfileObjectList[heapProfileNumObjects]
+		heapProfileObjectList[heapProfileNumObjects] = heapProfileObject{}
+	}
+}
+
+func memProfile(p []MemProfileRecord, inuseZero bool) (n int, ok bool) {
+	for i := range heapProfileBucketTable {
+		bucket := &heapProfileBucketTable[i]
+		if bucket.initialized && (inuseZero || bucket.allocBytes != bucket.freeBytes) {
+			n++
+		}
+	}
+	if n > len(p) {
+		return n, false
+	}
+	index := 0
+	for i := range heapProfileBucketTable {
+		bucket := &heapProfileBucketTable[i]
+		if bucket.initialized && (inuseZero || bucket.allocBytes != bucket.freeBytes) {
+			p[index] = MemProfileRecord{
+				AllocBytes:   bucket.allocBytes,
+				FreeBytes:    bucket.freeBytes,
+				AllocObjects: bucket.allocs,
+				FreeObjects:  bucket.frees,
+				Stack0:       bucket.stack,
+			}
+			index++
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,18 @@
+//go:build !gc.conservative || !tinygo.heapprofile
+// +build !gc.conservative !tinygo.heapprofile
+
+package runtime
+
+// Heap profiling is disabled. See gc_heapprofile.go.
+
+const heapProfile = false
+
+func profileAlloc(ptr, size uintptr) {
+}
+
+func sweepProfile() {
+}
+
+func memProfile(p []MemProfileRecord, inuseZero bool) (n int, ok bool) {
+	return 0, true
+}
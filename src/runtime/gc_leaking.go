@@ -69,6 +69,17 @@ func GC() {
 	// No-op.
 }
 
+// There is no garbage collection to tune, but remember the setting so that
+// debug.SetGCPercent behaves as expected.
+var gcPercent = 100
+
+//go:linkname setGCPercent runtime/debug.SetGCPercent
+func setGCPercent(percent int) int {
+	old := gcPercent
+	gcPercent = percent
+	return old
+}
+
 func KeepAlive(x interface{}) {
 	// Unimplemented. Only required with SetFinalizer().
 }
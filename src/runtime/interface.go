@@ -77,7 +77,7 @@ func reflectValueEqual(x, y reflect.Value) bool {
 	case reflect.Interface:
 		return reflectValueEqual(x.Elem(), y.Elem())
 	default:
-		runtimePanic("comparing un-comparable type")
+		runtimeErrorPanic("comparing un-comparable type")
 		return false // unreachable
 	}
 }
@@ -86,7 +86,7 @@ func reflectValueEqual(x, y reflect.Value) bool {
 // returns false.
 func interfaceTypeAssert(ok bool) {
 	if !ok {
-		runtimePanic("type assert failed")
+		runtimeErrorPanic("type assert failed")
 	}
 }
 
@@ -107,7 +107,8 @@ type typecodeID struct {
 	// * interface: null
 	// * chan/pointer/slice/array: the element type
 	// * struct: bitcast of global with structField array
-	// * func/map: TODO
+	// * map: bitcast of global with the key and element type
+	// * func: TODO
 	references *typecodeID
 
 	// The array length, for array types.
@@ -51,3 +51,11 @@ func Disable() (state State) {
 func Restore(state State) {
 	arm.EnableInterrupts(uintptr(state))
 }
+
+// In returns whether the system is currently running in an interrupt handler
+// (or an exception handler such as HardFault).
+func In() bool {
+	// The IPSR register holds the number of the active exception, or 0 in
+	// thread mode.
+	return arm.AsmFull("mrs {}, IPSR", nil) != 0
+}
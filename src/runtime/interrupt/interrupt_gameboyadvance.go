@@ -116,3 +116,11 @@ func Restore(state State) {
 	// Restore interrupts to the previous state.
 	regGlobalInterruptEnable.Set(uint16(state))
 }
+
+// In returns whether the system is currently running in an interrupt handler.
+//
+// Warning: this always returns false on the Game Boy Advance, as the runtime
+// doesn't keep track of whether an interrupt handler is running.
+func In() bool {
+	return false
+}
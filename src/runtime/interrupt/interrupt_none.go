@@ -24,3 +24,9 @@ func Disable() (state State) {
 // calling Disable, this will not re-enable interrupts, allowing for nested
 // cricital sections.
 func Restore(state State) {}
+
+// In returns whether the system is currently running in an interrupt handler.
+// There are no interrupts on these systems, so it always returns false.
+func In() bool {
+	return false
+}
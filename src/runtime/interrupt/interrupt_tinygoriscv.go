@@ -28,3 +28,11 @@ func Disable() (state State) {
 func Restore(state State) {
 	riscv.EnableInterrupts(uintptr(state))
 }
+
+// In returns whether the system is currently running in an interrupt handler.
+//
+// Warning: this always returns false on RISC-V, as the runtime doesn't keep
+// track of whether an interrupt handler is running.
+func In() bool {
+	return false
+}
@@ -30,3 +30,11 @@ func Restore(state State) {
 		"state": state,
 	})
 }
+
+// In returns whether the system is currently running in an interrupt handler.
+//
+// Warning: this always returns false on Xtensa, as the runtime doesn't keep
+// track of whether an interrupt handler is running.
+func In() bool {
+	return false
+}
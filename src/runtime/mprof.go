@@ -0,0 +1,61 @@
+package runtime
+
+// Heap profiling API, modelled after the upstream Go runtime. Allocations are
+// only sampled when the program is built with -heap-profile, see
+// gc_heapprofile.go.
+
+// MemProfileRate controls the fraction of memory allocations that are recorded
+// and reported in the memory profile. The profiler aims to sample an average
+// of one allocation per MemProfileRate bytes allocated.
+//
+// To include every allocated block in the profile, set MemProfileRate to 1. To
+// turn off profiling entirely, set MemProfileRate to 0.
+//
+// Unlike upstream Go, allocations are only sampled in programs built with the
+// -heap-profile flag.
+var MemProfileRate int = 512 * 1024
+
+// A MemProfileRecord describes the live objects allocated by a particular call
+// sequence (stack trace).
+type MemProfileRecord struct {
+	AllocBytes, FreeBytes     int64       // number of bytes allocated, freed
+	AllocObjects, FreeObjects int64       // number of objects allocated, freed
+	Stack0                    [32]uintptr // stack trace for this record; ends at first 0 entry
+}
+
+// InUseBytes returns the number of bytes in use (AllocBytes - FreeBytes).
+func (r *MemProfileRecord) InUseBytes() int64 { return r.AllocBytes - r.FreeBytes }
+
+// InUseObjects returns the number of objects in use (AllocObjects - FreeObjects).
+func (r *MemProfileRecord) InUseObjects() int64 {
+	return r.AllocObjects - r.FreeObjects
+}
+
+// Stack returns the stack trace associated with the record, a prefix of
+// r.Stack0.
+func (r *MemProfileRecord) Stack() []uintptr {
+	for i, v := range r.Stack0 {
+		if v == 0 {
+			return r.Stack0[0:i]
+		}
+	}
+	return r.Stack0[0:]
+}
+
+// MemProfile returns a profile of memory allocated and freed per allocation
+// site.
+//
+// MemProfile returns n, the number of records in the current memory profile.
+// If len(p) >= n, MemProfile copies the profile into p and returns n, true. If
+// len(p) < n, MemProfile does not change p and returns n, false.
+//
+// If inuseZero is true, the profile includes allocation records where
+// r.AllocBytes > 0 but r.AllocBytes == r.FreeBytes. These are sites where
+// memory was allocated, but it has all been released back to the runtime.
+//
+// The returned profile is as of the last garbage collection: objects are only
+// known to be freed after a garbage collection cycle. The record counts are not
+// scaled by MemProfileRate, which is left to tools like pprof.
+func MemProfile(p []MemProfileRecord, inuseZero bool) (n int, ok bool) {
+	return memProfile(p, inuseZero)
+}
@@ -57,4 +57,9 @@ type MemStats struct {
 
 	// GCSys is bytes of memory in garbage collection metadata.
 	GCSys uint64
+
+	// Garbage collector statistics.
+
+	// NumGC is the number of completed GC cycles.
+	NumGC uint32
 }
@@ -84,10 +84,19 @@ func markGlobals() {
 	// Relevant constants from the ELF specification.
 	// See: https://refspecs.linuxfoundation.org/elf/elf.pdf
 	const (
+		ET_DYN  = 3 // file type: shared object or position-independent executable
 		PT_LOAD = 1
 		PF_W    = 0x2 // program flag: write access
 	)
 
+	// The addresses in the program header of a position-independent
+	// executable are relative to the address it was loaded at, which is the
+	// address of the ELF header (at the start of the first segment).
+	var base uintptr
+	if ehdr_start.filetype == ET_DYN {
+		base = uintptr(unsafe.Pointer(&ehdr_start))
+	}
+
 	headerPtr := unsafe.Pointer(uintptr(unsafe.Pointer(&ehdr_start)) + ehdr_start.phoff)
 	for i := 0; i < int(ehdr_start.phnum); i++ {
 		// Look for a writable segment and scan its contents.
@@ -98,14 +107,14 @@ func markGlobals() {
 		if TargetBits == 64 {
 			header := (*elfProgramHeader64)(headerPtr)
 			if header._type == PT_LOAD && header.flags&PF_W != 0 {
-				start := header.vaddr
+				start := base + header.vaddr
 				end := start + header.memsz
 				markRoots(start, end)
 			}
 		} else {
 			header := (*elfProgramHeader32)(headerPtr)
 			if header._type == PT_LOAD && header.flags&PF_W != 0 {
-				start := header.vaddr
+				start := base + header.vaddr
 				end := start + header.memsz
 				markRoots(start, end)
 			}
@@ -114,9 +123,6 @@ func markGlobals() {
 	}
 }
 
-//export getpagesize
-func libc_getpagesize() int
-
 //go:linkname syscall_Getpagesize syscall.Getpagesize
 func syscall_Getpagesize() int {
 	return libc_getpagesize()
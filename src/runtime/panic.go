@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"internal/task"
+	"runtime/interrupt"
 	"unsafe"
 )
 
@@ -46,19 +47,101 @@ func _panic(message interface{}) {
 			// unreachable
 		}
 	}
+	callUnhandledPanicHandler(message)
 	printstring("panic: ")
 	printitf(message)
 	printnl()
 	abort()
 }
 
-// Cause a runtime panic, which is (currently) always a string.
+// Cause a runtime panic that can be recovered, like an index out of range. The
+// panic value passed to recover implements runtime.Error.
+func runtimeErrorPanic(msg string) {
+	if supportsRecover() && !interrupt.In() && !(hasScheduler && task.OnSystemStack()) {
+		// Only unwind to a defer frame of the current goroutine. In an
+		// interrupt the defer frames belong to the interrupted goroutine, and
+		// on the system stack (in the scheduler for example) there may not
+		// even be a current task.
+		if t := task.Current(); t != nil && t.DeferFrame != nil {
+			_panic(runtimeError{msg})
+		}
+	}
+	callUnhandledPanicHandler(runtimeError{msg})
+	runtimePanic(msg)
+}
+
+// Cause a runtime panic that cannot be recovered. This is used for fatal errors
+// inside the runtime, like running out of memory, where it isn't safe to
+// continue running the program. The unhandled panic handler is not called.
 func runtimePanic(msg string) {
 	printstring("panic: runtime error: ")
 	println(msg)
 	abort()
 }
 
+// runtimeError is the panic value of runtime panics (like an out of range
+// index) as passed to the unhandled panic handler.
+type runtimeError struct {
+	msg string
+}
+
+func (e runtimeError) Error() string {
+	return "runtime error: " + e.msg
+}
+
+func (e runtimeError) RuntimeError() {}
+
+// The handler set with SetUnhandledPanicHandler, or nil.
+var unhandledPanicHandler func(v interface{})
+
+// Set while the unhandled panic handler is running, to avoid calling it
+// recursively when it panics itself.
+var inUnhandledPanicHandler bool
+
+// SetUnhandledPanicHandler sets a function that is called when a goroutine
+// panics and the panic is not recovered, instead of printing the panic value
+// and aborting the program. This gives the program a chance to log the panic
+// and shut down in a controlled way, for example by resetting the chip.
+//
+// The handler is called on the panicking goroutine with the panic value. For
+// runtime panics (such as a nil pointer dereference) this value implements
+// runtime.Error. If the handler returns, the panicking goroutine is stopped
+// without running its deferred calls while other goroutines keep running. This
+// is also the case for the main goroutine: the program only exits when another
+// goroutine calls os.Exit. Without a scheduler there are no other goroutines,
+// so the program aborts as usual after the handler returns.
+//
+// The handler is not called for fatal errors inside the runtime (such as
+// running out of memory), for panics in an interrupt or outside of a
+// goroutine, or if the handler itself panics or another goroutine panics while
+// the handler is running. The program aborts as usual in these cases. Passing
+// nil restores the default behavior.
+func SetUnhandledPanicHandler(handler func(v interface{})) {
+	unhandledPanicHandler = handler
+}
+
+// callUnhandledPanicHandler calls the handler set with
+// SetUnhandledPanicHandler, if there is one. When it returns, the current
+// goroutine is stopped. If no handler is set (or it cannot be called), this
+// function returns and the caller should abort the program.
+func callUnhandledPanicHandler(v interface{}) {
+	handler := unhandledPanicHandler
+	if handler == nil || inUnhandledPanicHandler {
+		return
+	}
+	if interrupt.In() || (hasScheduler && task.OnSystemStack()) {
+		// Not running in a goroutine but in the scheduler or in an interrupt,
+		// which cannot be stopped.
+		return
+	}
+	inUnhandledPanicHandler = true
+	handler(v)
+	inUnhandledPanicHandler = false
+	if hasScheduler {
+		deadlock()
+	}
+}
+
 // Called at the start of a function that includes a deferred call.
 // It gets passed in the stack-allocated defer frame and configures it.
 // Note that the frame is not zeroed yet, so we need to initialize all values
@@ -119,49 +202,55 @@ func _recover(useParentFrame bool) interface{} {
 
 // Panic when trying to dereference a nil pointer.
 func nilPanic() {
-	runtimePanic("nil pointer dereference")
+	runtimeErrorPanic("nil pointer dereference")
 }
 
 // Panic when trying to add an entry to a nil map
 func nilMapPanic() {
-	runtimePanic("assignment to entry in nil map")
+	runtimeErrorPanic("assignment to entry in nil map")
 }
 
 // Panic when trying to acces an array or slice out of bounds.
 func lookupPanic() {
-	runtimePanic("index out of range")
+	runtimeErrorPanic("index out of range")
 }
 
 // Panic when trying to slice a slice out of bounds.
 func slicePanic() {
-	runtimePanic("slice out of range")
+	runtimeErrorPanic("slice out of range")
 }
 
 // Panic when trying to convert a slice to an array pointer (Go 1.17+) and the
 // slice is shorter than the array.
 func sliceToArrayPointerPanic() {
-	runtimePanic("slice smaller than array")
+	runtimeErrorPanic("slice smaller than array")
 }
 
 // Panic when calling unsafe.Slice() (Go 1.17+) with a len that's too large
 // (which includes if the ptr is nil and len is nonzero).
 func unsafeSlicePanic() {
-	runtimePanic("unsafe.Slice: len out of range")
+	runtimeErrorPanic("unsafe.Slice: len out of range")
 }
 
 // Panic when trying to create a new channel that is too big.
 func chanMakePanic() {
-	runtimePanic("new channel is too big")
+	runtimeErrorPanic("new channel is too big")
 }
 
 // Panic when a shift value is negative.
 func negativeShiftPanic() {
-	runtimePanic("negative shift")
+	runtimeErrorPanic("negative shift")
 }
 
 // Panic when there is a divide by zero.
 func divideByZeroPanic() {
-	runtimePanic("divide by zero")
+	runtimeErrorPanic("divide by zero")
+}
+
+// Panic on signed integer overflow, with -int-overflow=trap or //go:overflow
+// trap.
+func overflowPanic() {
+	runtimeErrorPanic("integer overflow")
 }
 
 func blockingPanic() {
@@ -0,0 +1,18 @@
+package runtime
+
+// Function registered by the sync package that drops all objects stored in a
+// sync.Pool, or nil if no objects were ever put in a pool.
+var poolCleanup func()
+
+//go:linkname sync_runtime_registerPoolCleanup sync.runtime_registerPoolCleanup
+func sync_runtime_registerPoolCleanup(cleanup func()) {
+	poolCleanup = cleanup
+}
+
+// clearPools drops all objects stored in a sync.Pool, so that they can be freed
+// by the GC. It must be called at the start of a GC cycle, before marking.
+func clearPools() {
+	if poolCleanup != nil {
+		poolCleanup()
+	}
+}
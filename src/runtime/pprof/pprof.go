@@ -1,16 +1,29 @@
 package pprof
 
-// TinyGo does not implement pprof. However, a dummy shell is needed for the
-// testing package (and testing/internal/pprof).
+// TinyGo does not implement CPU profiling. However, a dummy shell is needed for
+// the testing package (and testing/internal/pprof).
+//
+// Heap profiles are supported in programs built with -heap-profile. They are
+// written in the legacy text format, which go tool pprof can read as well.
 
 import (
+	"bytes"
 	"errors"
 	"io"
+	"os"
+	"runtime"
+	"strconv"
 )
 
 var ErrUnimplemented = errors.New("runtime/pprof: unimplemented")
 
-type Profile struct{}
+// A Profile is a collection of stack traces. The only supported profile is the
+// "heap" profile.
+type Profile struct {
+	name string
+}
+
+var heapProfile = &Profile{name: "heap"}
 
 func StartCPUProfile(w io.Writer) error {
 	return nil
@@ -19,26 +32,145 @@ func StartCPUProfile(w io.Writer) error {
 func StopCPUProfile() {
 }
 
+// WriteHeapProfile is shorthand for Lookup("heap").WriteTo(w, 0).
 func WriteHeapProfile(w io.Writer) error {
-	return nil
+	return heapProfile.WriteTo(w, 0)
 }
 
+// Lookup returns the profile with the given name, or nil if no such profile
+// exists.
 func Lookup(name string) *Profile {
+	if name == "heap" {
+		return heapProfile
+	}
 	return nil
 }
 
+// Name returns the profile's name.
 func (p *Profile) Name() string {
-	return ""
+	if p == nil {
+		return ""
+	}
+	return p.name
 }
 
+// Count returns the number of records in the profile.
 func (p *Profile) Count() int {
-	return 0
+	if p != heapProfile {
+		return 0
+	}
+	n, _ := runtime.MemProfile(nil, true)
+	return n
 }
 
+// WriteTo writes a pprof-formatted snapshot of the profile to w. The debug
+// parameter is ignored: the heap profile is always written in the legacy text
+// format, which is both human-readable and understood by go tool pprof.
 func (p *Profile) WriteTo(w io.Writer, debug int) error {
-	return ErrUnimplemented
+	if p != heapProfile {
+		return ErrUnimplemented
+	}
+	return writeHeapProfile(w)
 }
 
+// Profiles returns a slice of all the known profiles.
 func Profiles() []*Profile {
-	return nil
+	return []*Profile{heapProfile}
+}
+
+// writeHeapProfile writes the heap profile in the legacy text format:
+//
+//	heap profile: <inuse objects>: <inuse bytes> [<alloc objects>: <alloc bytes>] @ heap/<2*MemProfileRate>
+//	<inuse objects>: <inuse bytes> [<alloc objects>: <alloc bytes>] @ <pc> <pc> ...
+//	...
+//
+// This is followed by the memory mappings of the process, if known, so that
+// addresses in position-independent executables can be symbolized.
+func writeHeapProfile(w io.Writer) error {
+	// The profile is only updated during a GC cycle. Make sure it's up to date.
+	runtime.GC()
+
+	var records []runtime.MemProfileRecord
+	n, ok := runtime.MemProfile(nil, true)
+	for {
+		records = make([]runtime.MemProfileRecord, n+50)
+		n, ok = runtime.MemProfile(records, true)
+		if ok {
+			records = records[:n]
+			break
+		}
+	}
+
+	var total runtime.MemProfileRecord
+	for i := range records {
+		r := &records[i]
+		total.AllocBytes += r.AllocBytes
+		total.AllocObjects += r.AllocObjects
+		total.FreeBytes += r.FreeBytes
+		total.FreeObjects += r.FreeObjects
+	}
+
+	buf := []byte("heap profile: ")
+	buf = appendCounts(buf, &total)
+	buf = append(buf, " @ heap/"...)
+	buf = strconv.AppendInt(buf, 2*int64(runtime.MemProfileRate), 10)
+	buf = append(buf, '\n')
+	for i := range records {
+		r := &records[i]
+		buf = appendCounts(buf, r)
+		buf = append(buf, " @"...)
+		for _, pc := range r.Stack() {
+			buf = append(buf, " 0x"...)
+			buf = strconv.AppendUint(buf, uint64(pc), 16)
+		}
+		buf = append(buf, '\n')
+	}
+
+	if maps, err := os.ReadFile("/proc/self/maps"); err == nil {
+		buf = append(buf, "\nMAPPED_LIBRARIES:\n"...)
+		buf = appendExecutableMappings(buf, maps)
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// appendExecutableMappings appends the executable mappings from the contents
+// of /proc/self/maps, in the same format. The file offsets are left out (set to
+// zero): go tool pprof computes the load address of a binary from the start of
+// its code mapping, and gets it wrong if the code doesn't start at the beginning
+// of the file. This is normally the case for TinyGo binaries, where the code
+// follows the read-only data.
+func appendExecutableMappings(buf, maps []byte) []byte {
+	for len(maps) != 0 {
+		line := maps
+		if i := bytes.IndexByte(maps, '\n'); i >= 0 {
+			line = maps[:i+1]
+		}
+		maps = maps[len(line):]
+
+		// Each line looks like this (the path is optional):
+		//   00401000-0040d000 r-xp 00001000 fe:00 1234 /path/to/binary
+		fields := bytes.Fields(line)
+		if len(fields) < 5 || bytes.IndexByte(fields[1], 'x') < 0 {
+			continue
+		}
+		fields[2] = []byte("00000000")
+		buf = append(buf, bytes.Join(fields, []byte(" "))...)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// appendCounts appends the counts of r in the form used by the heap profile.
+func appendCounts(buf []byte, r *runtime.MemProfileRecord) []byte {
+	buf = strconv.AppendInt(buf, r.InUseObjects(), 10)
+	buf = append(buf, ": "...)
+	buf = strconv.AppendInt(buf, r.InUseBytes(), 10)
+	buf = append(buf, " ["...)
+	buf = strconv.AppendInt(buf, r.AllocObjects, 10)
+	buf = append(buf, ": "...)
+	buf = strconv.AppendInt(buf, r.AllocBytes, 10)
+	buf = append(buf, ']')
+	return buf
 }
@@ -0,0 +1,22 @@
+package runtime
+
+// preinitHook is called at the very start of the program, before .data and
+// .bss are initialized, before the heap is set up and before any package is
+// initialized. It does nothing by default: a program (like a bootloader) can
+// replace it by defining its own function with the same link name:
+//
+//	//export tinygo_preinit
+//	func preinit() {
+//		// check a reset reason, jump to an application, etc
+//	}
+//
+// Because the runtime hasn't been set up yet, such a function must be very
+// careful: it must not allocate, must not use global variables (except those
+// in a .noinit section, see //go:section), and must not block or panic. On
+// most microcontrollers it runs at the reset clock speed, before the clocks are
+// configured. This hook is not called on WebAssembly.
+//
+//export tinygo_preinit
+//go:weak
+func preinitHook() {
+}
@@ -51,6 +51,9 @@ func main() {
 }
 
 func preinit() {
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	// Initialize .bss: zero-initialized global variables.
 	ptr := unsafe.Pointer(&_sbss)
 	for ptr != unsafe.Pointer(&_ebss) {
@@ -23,6 +23,9 @@ var _sidata [0]byte
 var _edata [0]byte
 
 func preinit() {
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	// Initialize .bss: zero-initialized global variables.
 	ptr := unsafe.Pointer(&_sbss)
 	for ptr != unsafe.Pointer(&_ebss) {
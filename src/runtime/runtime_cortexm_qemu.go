@@ -14,11 +14,29 @@ import (
 
 type timeUnit int64
 
+// timestamp is the time spent sleeping. Sleeping doesn't actually wait, so that
+// tests with lots of sleeps run quickly.
+//
+// The clock returned by ticks is the sum of this fake sleep time and the real
+// time that passed since startup, as reported by QEMU. So a time.Sleep appears
+// to take exactly as long as requested, while code that doesn't sleep (like a
+// benchmark loop) is measured in real time.
 var timestamp timeUnit
 
+// Frequency of the semihosting tick counter, or 0 if it isn't supported.
+var semihostingTickFreq int64
+
+// Value of the semihosting tick counter at startup.
+var semihostingTickBase int64
+
+// Result of SYS_ELAPSED (low word first). It is a global so that it doesn't
+// need to be allocated on each call to ticks.
+var semihostingElapsed [2]uint32
+
 //export Reset_Handler
 func main() {
 	preinit()
+	initSemihostingClock()
 	run()
 
 	// Signal successful exit.
@@ -39,7 +57,50 @@ func sleepTicks(d timeUnit) {
 }
 
 func ticks() timeUnit {
-	return timestamp
+	return timestamp + timeUnit(elapsedNanoseconds())
+}
+
+// initSemihostingClock reads the frequency and the start value of the
+// semihosting tick counter, so that ticks only needs a single semihosting call.
+// Older QEMU versions don't support it, and then only sleeping advances the
+// clock.
+func initSemihostingClock() {
+	freq := int64(arm.SemihostingCall(arm.SemihostingTickFreq, 0))
+	if freq <= 0 {
+		return
+	}
+	count, ok := semihostingTicks()
+	if !ok {
+		return
+	}
+	semihostingTickFreq = freq
+	semihostingTickBase = count
+}
+
+// semihostingTicks returns the current value of the semihosting tick counter.
+func semihostingTicks() (int64, bool) {
+	if arm.SemihostingCall(arm.SemihostingElapsed, uintptr(unsafe.Pointer(&semihostingElapsed))) != 0 {
+		return 0, false
+	}
+	return int64(semihostingElapsed[0]) | int64(semihostingElapsed[1])<<32, true
+}
+
+// elapsedNanoseconds returns the real time since startup, or 0 if the
+// semihosting tick counter isn't supported.
+func elapsedNanoseconds() int64 {
+	if semihostingTickFreq == 0 {
+		return 0
+	}
+	count, ok := semihostingTicks()
+	if !ok {
+		return 0
+	}
+	count -= semihostingTickBase
+	if semihostingTickFreq == 1e9 {
+		// QEMU always counts in nanoseconds.
+		return count
+	}
+	return count/semihostingTickFreq*1e9 + count%semihostingTickFreq*1e9/semihostingTickFreq
 }
 
 // UART0 output register.
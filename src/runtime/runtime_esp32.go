@@ -42,6 +42,9 @@ func main() {
 	// which is still running at 80MHz.
 	esp.DPORT.CPU_PER_CONF.Set(esp.DPORT_CPU_PER_CONF_CPUPERIOD_SEL_SEL_160)
 
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	// Clear .bss section. .data has already been loaded by the ROM bootloader.
 	// Do this after increasing the CPU clock to possibly make startup slightly
 	// faster.
@@ -49,6 +49,9 @@ func main() {
 	// power. It is set here to keep the default on reset.
 	esp.SYSTEM.CPU_PER_CONF.Set(esp.SYSTEM_CPU_PER_CONF_CPU_WAIT_MODE_FORCE_ON | esp.SYSTEM_CPU_PER_CONF_PLL_FREQ_SEL | 1<<esp.SYSTEM_CPU_PER_CONF_CPUPERIOD_SEL_Pos)
 
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	clearbss()
 
 	// Configure interrupt handler
@@ -13,10 +13,7 @@ import (
 
 type timeUnit int64
 
-const (
-	lastCycle      = SYSTICK_FREQ/1000 - 1
-	cyclesPerMicro = CORE_FREQ / 1000000
-)
+const lastCycle = SYSTICK_FREQ/1000 - 1
 
 const (
 	pitFreq           = OSC_FREQ // PIT/GPT are muxed to 24 MHz OSC
@@ -29,6 +26,10 @@ var (
 	cycleCount volatile.Register32
 	pitActive  volatile.Register32
 	pitTimeout interrupt.Interrupt
+
+	// Number of core clock cycles (counted by DWT_CYCCNT) per microsecond.
+	// SysTick itself runs from the fixed 100kHz reference clock.
+	cyclesPerMicro uint32 = CORE_FREQ / 1000000
 )
 
 var (
@@ -94,6 +95,14 @@ func initRTC() {
 	}
 }
 
+// setTickFrequency is called by machine.SetTickFrequency, with interrupts
+// disabled, after the core clock has been changed to the given frequency. The
+// millisecond ticks don't depend on the core clock, but the cycle counter used
+// for the fraction of the current millisecond does.
+func setTickFrequency(hz uint32) {
+	cyclesPerMicro = hz / 1000000
+}
+
 //go:export SysTick_Handler
 func tick() {
 	tickCount.Set(tickCount.Get() + 1)
@@ -112,7 +121,7 @@ func ticks() timeUnit {
 	} else {
 		diff = curr - cycs
 	}
-	frac := uint64(diff*0xFFFFFFFF/cyclesPerMicro) >> 32
+	frac := uint64(diff / cyclesPerMicro)
 	if frac > 1000 {
 		frac = 1000
 	}
@@ -45,6 +45,9 @@ var (
 )
 
 func preinit() {
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	// Unsafe to use heap here
 	setupEnv()
 	setupHeap()
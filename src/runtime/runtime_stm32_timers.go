@@ -104,6 +104,22 @@ func initTickTimer(tim *machine.TIM) {
 	tickTimer.SetWraparoundInterrupt(handleTick)
 }
 
+// setTickFrequency is called by machine.SetTickFrequency, with interrupts
+// disabled, after the core clock has been changed to the given frequency. The
+// machine package derives the timer clock from the new frequency, so
+// configuring the tick timer again makes it count at NS_PER_TICK again. This
+// restarts the current tick interrupt period, which is counted as a full
+// period so that the time doesn't go backwards.
+func setTickFrequency(hz uint32) {
+	tickTimer.Configure(machine.PWMConfig{Period: TICK_INTR_PERIOD_NS})
+	countMax = tickTimer.Top()
+
+	// Configure loads the new prescaler with an update event, which sets the
+	// update flag. Clear it, so that it isn't handled as a tick interrupt.
+	tickTimer.Device.SR.ClearBits(stm32.TIM_SR_UIF)
+	tickCount.Set(tickCount.Get() + 1)
+}
+
 func handleTick() {
 	// increment tick count
 	tickCount.Set(tickCount.Get() + 1)
@@ -22,6 +22,9 @@ func usleep(usec uint) int
 //export mmap
 func mmap(addr unsafe.Pointer, length uintptr, prot, flags, fd int, offset int64) unsafe.Pointer
 
+//export getpagesize
+func libc_getpagesize() int
+
 //export abort
 func abort()
 
@@ -66,26 +69,6 @@ type timespec struct {
 
 var stackTop uintptr
 
-// Entry point for Go. Initialize all packages and call main.main().
-//
-//export main
-func main(argc int32, argv *unsafe.Pointer) int {
-	preinit()
-
-	// Store argc and argv for later use.
-	main_argc = argc
-	main_argv = argv
-
-	// Obtain the initial stack pointer right before calling the run() function.
-	// The run function has been moved to a separate (non-inlined) function so
-	// that the correct stack pointer is read.
-	stackTop = getCurrentStackPointer()
-	runMain()
-
-	// For libc compatibility.
-	return 0
-}
-
 var (
 	main_argc int32
 	main_argv *unsafe.Pointer
@@ -114,13 +97,6 @@ func os_runtime_args() []string {
 	return args
 }
 
-// Must be a separate function to get the correct stack pointer.
-//
-//go:noinline
-func runMain() {
-	run()
-}
-
 //go:extern environ
 var environ *unsafe.Pointer
 
@@ -219,6 +195,9 @@ var heapMaxSize uintptr
 var heapStart, heapEnd uintptr
 
 func preinit() {
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	// Allocate a large chunk of virtual memory. Because it is virtual, it won't
 	// really be allocated in RAM. Memory will only be allocated when it is
 	// first touched.
@@ -0,0 +1,27 @@
+//go:build linux && !baremetal && !wasi && !nintendoswitch && tinygo.carchive
+// +build linux,!baremetal,!wasi,!nintendoswitch,tinygo.carchive
+
+package runtime
+
+// This file implements the entry point for -buildmode=c-archive and
+// -buildmode=plugin. The C program has its own main function, so the runtime
+// and all packages are initialized from a constructor instead (created by the
+// builder), before the C main function runs or when the plugin is loaded. The
+// Go main function is never called.
+
+import "unsafe"
+
+// initCArchive initializes the heap and all packages. It is called from a
+// constructor, see the builder package.
+func initCArchive() {
+	preinit()
+
+	// Exported functions are called from somewhere below the C main function,
+	// so the stack pointer in this constructor is not necessarily the top of
+	// the stack. The environment is stored by the kernel above the initial
+	// stack frame, so use that instead.
+	stackTop = uintptr(unsafe.Pointer(environ))
+
+	initHeap()
+	initAll()
+}
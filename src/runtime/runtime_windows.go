@@ -101,6 +101,9 @@ var heapMaxSize uintptr
 var heapStart, heapEnd uintptr
 
 func preinit() {
+	// Allow the program to run some code before anything is initialized.
+	preinitHook()
+
 	// Allocate a large chunk of virtual memory. Because it is virtual, it won't
 	// really be allocated in RAM. Memory will only be allocated when it is
 	// first touched.
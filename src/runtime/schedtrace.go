@@ -0,0 +1,139 @@
+package runtime
+
+// This file implements tracing of goroutine state transitions, for debugging
+// concurrency problems. Tracing is only compiled in with the scheduler_trace
+// build tag. Without it, schedulerTrace is false and all trace calls are
+// optimized away.
+
+import (
+	"internal/task"
+	"unsafe"
+)
+
+// TraceEventKind is the kind of goroutine state transition in a TraceEvent.
+type TraceEventKind uint8
+
+const (
+	// The goroutine was created with a go statement. It is runnable, but has
+	// not run yet.
+	TraceGoCreate TraceEventKind = iota + 1
+
+	// The scheduler switched to the goroutine.
+	TraceGoStart
+
+	// The goroutine paused and switched back to the scheduler, because it
+	// blocks (for example on a channel or in time.Sleep) or yields with
+	// Gosched.
+	TraceGoBlock
+
+	// The goroutine was made runnable again, for example because a channel
+	// operation it was blocked on can now proceed.
+	TraceGoUnblock
+
+	// The goroutine exited.
+	TraceGoEnd
+)
+
+// String returns the name of the event kind, like "GoCreate".
+func (k TraceEventKind) String() string {
+	switch k {
+	case TraceGoCreate:
+		return "GoCreate"
+	case TraceGoStart:
+		return "GoStart"
+	case TraceGoBlock:
+		return "GoBlock"
+	case TraceGoUnblock:
+		return "GoUnblock"
+	case TraceGoEnd:
+		return "GoEnd"
+	default:
+		return "TraceEventKind?"
+	}
+}
+
+// TraceEvent is a goroutine state transition, passed to the trace handler.
+type TraceEvent struct {
+	Kind TraceEventKind
+
+	// Goroutine identifies the goroutine. It is unique among the goroutines
+	// that currently exist, but may be reused after a goroutine has exited.
+	Goroutine uintptr
+
+	// Time is the monotonic time of the event in nanoseconds.
+	Time int64
+}
+
+var (
+	traceHandler func(ev TraceEvent)
+
+	// traceBlocked is set when the running goroutine pauses. If it isn't set
+	// when the goroutine returns to the scheduler, the goroutine exited.
+	traceBlocked bool
+
+	// traceExiting is set when the running goroutine exits, so that its final
+	// pause isn't reported as blocking.
+	traceExiting bool
+)
+
+// SetTraceHandler sets the function that is called for every goroutine state
+// transition, or disables tracing if handler is nil. It has no effect unless
+// the program is built with the scheduler_trace build tag.
+//
+// The handler is called by the scheduler in the middle of a state transition,
+// sometimes from an interrupt. It must return quickly and must not block, for
+// example by sending on a channel or taking a lock.
+func SetTraceHandler(handler func(ev TraceEvent)) {
+	if schedulerTrace {
+		traceHandler = handler
+	}
+}
+
+// traceGoroutine calls the trace handler, if tracing is enabled. It is also
+// called from the internal/task package.
+func traceGoroutine(kind TraceEventKind, t *task.Task) {
+	if !schedulerTrace {
+		return
+	}
+	if kind == TraceGoBlock {
+		traceBlocked = true
+	}
+	if traceHandler != nil {
+		traceHandler(TraceEvent{
+			Kind:      kind,
+			Goroutine: uintptr(unsafe.Pointer(t)),
+			Time:      ticksToNanoseconds(ticks()),
+		})
+	}
+}
+
+// resumeTask runs the goroutine t until it pauses or exits. This may only be
+// called from the scheduler.
+func resumeTask(t *task.Task) {
+	if !schedulerTrace {
+		t.Resume()
+		return
+	}
+	traceGoroutine(TraceGoStart, t)
+	traceBlocked = false
+	traceExiting = false
+	t.Resume()
+	if !traceBlocked {
+		traceGoroutine(TraceGoEnd, t)
+	}
+}
+
+// traceBlock is called by internal/task when the current goroutine pauses.
+func traceBlock(t *task.Task) {
+	if !traceExiting {
+		traceGoroutine(TraceGoBlock, t)
+	}
+}
+
+// traceExit is called by internal/task when the current goroutine exits. Not
+// all schedulers call it, resumeTask also detects exited goroutines.
+func traceExit(t *task.Task) {
+	if schedulerTrace {
+		traceExiting = true
+	}
+}
@@ -0,0 +1,6 @@
+//go:build scheduler_trace
+// +build scheduler_trace
+
+package runtime
+
+const schedulerTrace = true
@@ -60,6 +60,9 @@ func scheduleLogChan(msg string, ch *channel, t *task.Task) {
 //
 //go:noinline
 func deadlock() {
+	// This goroutine will never run again, so don't count it anymore.
+	task.Exited()
+
 	// call yield without requesting a wakeup
 	task.Pause()
 	panic("unreachable")
@@ -77,6 +80,13 @@ func Goexit() {
 
 // Add this task to the end of the run queue.
 func runqueuePushBack(t *task.Task) {
+	traceGoroutine(TraceGoUnblock, t)
+	runqueue.Push(t)
+}
+
+// Add a newly created task to the end of the run queue.
+func runqueuePushNew(t *task.Task) {
+	traceGoroutine(TraceGoCreate, t)
 	runqueue.Push(t)
 }
 
@@ -175,7 +185,7 @@ func scheduler() {
 			sleepQueueBaseTime += timeUnit(t.Data)
 			sleepQueue = t.Next
 			t.Next = nil
-			runqueue.Push(t)
+			runqueuePushBack(t)
 		}
 
 		// Check for expired timers to trigger.
@@ -233,7 +243,7 @@ func scheduler() {
 
 		// Run the given task.
 		scheduleLogTask("  run:", t)
-		t.Resume()
+		resumeTask(t)
 	}
 }
 
@@ -250,7 +260,7 @@ func minSched() {
 		}
 
 		scheduleLogTask("  run:", t)
-		t.Resume()
+		resumeTask(t)
 	}
 	scheduleLog("stop nested scheduler")
 }
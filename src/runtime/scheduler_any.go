@@ -1,5 +1,5 @@
-//go:build !scheduler.none
-// +build !scheduler.none
+//go:build !scheduler.none && !scheduler.external
+// +build !scheduler.none,!scheduler.external
 
 package runtime
 
@@ -30,3 +30,10 @@ func run() {
 }
 
 const hasScheduler = true
+
+// NumGoroutine returns the number of goroutines that currently exist.
+// Goroutines that are blocked forever (for example on a nil channel) are not
+// counted.
+func NumGoroutine() int {
+	return task.Count()
+}
@@ -0,0 +1,132 @@
+//go:build scheduler.external
+// +build scheduler.external
+
+package runtime
+
+// This file implements the external scheduler. Goroutines are implemented in
+// the same way as with the tasks scheduler (each goroutine has its own stack),
+// but TinyGo doesn't run the scheduler loop itself. Instead, the main function
+// runs directly on the system stack, and the host system (for example a task
+// of an RTOS) is expected to call SchedulerStep periodically to let goroutines
+// make progress.
+//
+// The contract with the host is:
+//   - The main function (and package initialization) runs on the stack of
+//     the thread or task that started the program. It must not block on
+//     channels, mutexes and the like, as there is no scheduler loop that could
+//     run other goroutines in the meantime: doing so results in a runtime
+//     panic. time.Sleep is allowed, it sleeps without running goroutines.
+//   - SchedulerStep must be called from that same thread or task, never from
+//     a goroutine or from an interrupt.
+
+import "internal/task"
+
+// Pause the current task for a given time. Outside of a goroutine (for
+// example in the main function), this sleeps without running goroutines.
+//
+//go:linkname sleep time.Sleep
+func sleep(duration int64) {
+	if duration <= 0 {
+		return
+	}
+
+	if task.OnSystemStack() {
+		sleepTicks(nanosecondsToTicks(duration))
+		return
+	}
+	addSleepTask(task.Current(), nanosecondsToTicks(duration))
+	task.Pause()
+}
+
+// run is called by the program entry point to execute the go program.
+// With the external scheduler, init and the main function are invoked directly
+// and goroutines only run when SchedulerStep is called.
+func run() {
+	initHeap()
+	task.RunCallback(func() {
+		initAll()
+		callMain()
+	}, "cannot block outside of a goroutine with -scheduler=external, start a goroutine instead")
+}
+
+const hasScheduler = true
+
+// NumGoroutine returns the number of goroutines that currently exist,
+// including the main function. Goroutines that are blocked forever (for
+// example on a nil channel) are not counted.
+func NumGoroutine() int {
+	return task.Count() + 1
+}
+
+// SchedulerStep runs all goroutines that are ready to run until they block,
+// starting with those that are done sleeping and expired timers. Goroutines
+// that become ready while SchedulerStep is running are run in the next call.
+// It is only available with -scheduler=external and must not be called from
+// a goroutine. The calling function runs outside of a goroutine, like main: it
+// must not block itself, see the top of this file.
+//
+// The returned value is the time in nanoseconds until SchedulerStep needs to
+// be called again: 0 if there are goroutines ready to run, or -1 if all
+// goroutines are blocked on something other than a sleep or timer (for
+// example a channel operation). The caller may sleep for at most this time, but
+// note that goroutines may also become ready because of an interrupt.
+func SchedulerStep() int64 {
+	if !task.OnSystemStack() {
+		runtimePanic("SchedulerStep called from a goroutine")
+	}
+
+	// Add tasks that are done sleeping to the end of the runqueue, and run
+	// the callbacks of expired timers.
+	now := ticks()
+	for sleepQueue != nil && now-sleepQueueBaseTime >= timeUnit(sleepQueue.Data) {
+		t := sleepQueue
+		scheduleLogTask("  awake:", t)
+		sleepQueueBaseTime += timeUnit(t.Data)
+		sleepQueue = t.Next
+		t.Next = nil
+		runqueuePushBack(t)
+	}
+	for timerQueue != nil && now >= timerQueue.whenTicks() {
+		scheduleLog("--- timer awoke")
+		tn := timerQueue
+		timerQueue = tn.next
+		tn.next = nil
+		tn.callback(tn)
+	}
+
+	// Run only the goroutines that are ready now, so that a goroutine that
+	// keeps yielding with Gosched doesn't prevent this function from
+	// returning.
+	var ready task.Queue
+	ready.Append(&runqueue)
+	for t := ready.Pop(); t != nil; t = ready.Pop() {
+		scheduleLogTask("  run:", t)
+		resumeTask(t)
+	}
+
+	// Determine when this function should be called again.
+	if !runqueue.Empty() {
+		return 0
+	}
+	if sleepQueue == nil && timerQueue == nil {
+		return -1
+	}
+	now = ticks()
+	var timeLeft timeUnit
+	if sleepQueue != nil {
+		if now-sleepQueueBaseTime >= timeUnit(sleepQueue.Data) {
+			return 0
+		}
+		timeLeft = timeUnit(sleepQueue.Data) - (now - sleepQueueBaseTime)
+	}
+	if timerQueue != nil {
+		if now >= timerQueue.whenTicks() {
+			return 0
+		}
+		timeLeftForTimer := timerQueue.whenTicks() - now
+		if sleepQueue == nil || timeLeftForTimer < timeLeft {
+			timeLeft = timeLeftForTimer
+		}
+	}
+	return ticksToNanoseconds(timeLeft)
+}
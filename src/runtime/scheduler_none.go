@@ -27,3 +27,13 @@ func run() {
 }
 
 const hasScheduler = false
+
+// runAfterFunc is never called without a scheduler, as timers don't fire.
+func runAfterFunc(f func()) {
+}
+
+// NumGoroutine returns the number of goroutines that currently exist, which is
+// always one without a scheduler.
+func NumGoroutine() int {
+	return 1
+}
@@ -0,0 +1,79 @@
+package runtime
+
+// This file implements redirecting standard output to an arbitrary writer.
+
+// stdoutWriter is the writer set with SetStdout, or nil when the default output
+// of the target (putchar) is used.
+var stdoutWriter interface {
+	Write(p []byte) (n int, err error)
+}
+
+// Output of print and println is collected in this buffer and written to
+// stdoutWriter at every newline, so that the writer isn't called for every
+// single byte.
+var (
+	stdoutBuf    [64]byte
+	stdoutBufLen int
+)
+
+// stdoutBusy is set while stdoutWriter is being called. Output written in the
+// meantime (for example by a println in the writer itself) goes to the default
+// output to avoid infinite recursion.
+var stdoutBusy bool
+
+// SetStdout redirects standard output to w: everything printed with print and
+// println (including panic messages) and everything written to os.Stdout (and
+// therefore fmt.Print etc) is written to w instead of the default output of the
+// target, such as a UART. The default output is restored when w is nil.
+//
+// Output of print and println is buffered until the end of the line. The
+// writer may be called from any goroutine, and must not block for a long time.
+func SetStdout(w interface {
+	Write(p []byte) (n int, err error)
+}) {
+	flushStdout()
+	stdoutWriter = w
+}
+
+// printByte writes a single byte of print or println output to standard
+// output.
+func printByte(c byte) {
+	if stdoutWriter == nil || stdoutBusy {
+		putchar(c)
+		return
+	}
+	stdoutBuf[stdoutBufLen] = c
+	stdoutBufLen++
+	if c == '\n' || stdoutBufLen == len(stdoutBuf) {
+		flushStdout()
+	}
+}
+
+// flushStdout writes the output of print and println that is still buffered to
+// stdoutWriter.
+func flushStdout() {
+	if stdoutBufLen == 0 {
+		return
+	}
+	buf := stdoutBuf[:stdoutBufLen]
+	stdoutBufLen = 0
+	stdoutBusy = true
+	stdoutWriter.Write(buf)
+	stdoutBusy = false
+}
+
+// writeRedirectedStdout writes b to the writer set with SetStdout, and returns
+// false if standard output is not redirected. It is used by the os package for
+// os.Stdout.
+//
+//go:linkname writeRedirectedStdout os.runtime_writeRedirectedStdout
+func writeRedirectedStdout(b []byte) (n int, err error, redirected bool) {
+	if stdoutWriter == nil || stdoutBusy {
+		return 0, nil, false
+	}
+	flushStdout()
+	stdoutBusy = true
+	n, err = stdoutWriter.Write(b)
+	stdoutBusy = false
+	return n, err, true
+}
@@ -66,6 +66,33 @@ func stringConcat(x, y _string) _string {
 	}
 }
 
+// Add several strings together, for expressions like a + b + c. Unlike
+// repeated calls to stringConcat, this only allocates the result.
+func stringConcatN(strs []_string) _string {
+	length := uintptr(0)
+	nonEmpty := 0
+	var last _string
+	for _, s := range strs {
+		if s.length != 0 {
+			length += s.length
+			nonEmpty++
+			last = s
+		}
+	}
+	if nonEmpty <= 1 {
+		// There is nothing to concatenate, the result is the only non-empty
+		// string (if any).
+		return last
+	}
+	buf := alloc(length, nil)
+	offset := uintptr(0)
+	for _, s := range strs {
+		memcpy(unsafe.Pointer(uintptr(buf)+offset), unsafe.Pointer(s.ptr), s.length)
+		offset += s.length
+	}
+	return _string{ptr: (*byte)(buf), length: length}
+}
+
 // Create a string from a []byte slice.
 func stringFromBytes(x struct {
 	ptr *byte
@@ -156,6 +183,9 @@ func encodeUTF8(x rune) ([4]byte, uintptr) {
 	// https://stackoverflow.com/questions/6240055/manually-converting-unicode-codepoints-into-utf-8-and-utf-16
 	// Note: this code can probably be optimized (in size and speed).
 	switch {
+	case x < 0:
+		// Negative runes are not valid code points.
+		return [4]byte{0xef, 0xbf, 0xbd, 0}, 3
 	case x <= 0x7f:
 		return [4]byte{byte(x), 0, 0, 0}, 1
 	case x <= 0x7ff:
@@ -33,10 +33,17 @@ func startTimer(tim *timer) {
 // If timerQueue doesn't get optimized away, small programs (that don't call
 // time.NewTimer etc) would still pay the cost of these timers.
 func timerCallback(tn *timerNode) {
-	// Run timer function (implemented in the time package).
-	// The seq parameter to the f function is not used in the time
-	// package so is left zero.
-	tn.timer.f(tn.timer.arg, 0)
+	if f, ok := tn.timer.arg.(func()); ok && hasScheduler {
+		// This timer was created by time.AfterFunc. The time package would
+		// start a new goroutine to run f, run it on an idle AfterFunc
+		// goroutine instead.
+		runAfterFunc(f)
+	} else {
+		// Run timer function (implemented in the time package).
+		// The seq parameter to the f function is not used in the time
+		// package so is left zero.
+		tn.timer.f(tn.timer.arg, 0)
+	}
 
 	// If this is a periodic timer (a ticker), re-add it to the queue.
 	if tn.timer.period != 0 {
@@ -0,0 +1,64 @@
+//go:build !scheduler.none
+// +build !scheduler.none
+
+package runtime
+
+import "internal/task"
+
+// State of the goroutines that run time.AfterFunc functions, see runAfterFunc.
+var (
+	afterFuncIdle *task.Task // goroutine waiting for the next function
+	afterFuncSync *task.Task // goroutine run by runAfterFunc that hasn't blocked
+	afterFuncNext func()     // function for the goroutine in afterFuncSync
+)
+
+// runAfterFunc runs the function of an expired time.AfterFunc timer.
+//
+// Most of these functions return without blocking, so starting a new goroutine
+// for each of them is wasteful. Instead, an idle goroutine is resumed directly
+// from the scheduler to run f, and it becomes idle again when f returns. This
+// goroutine is not counted by NumGoroutine while it is idle or running f. Only
+// when f blocks does it become a regular goroutine, and a new idle goroutine
+// will be started for the next function.
+func runAfterFunc(f func()) {
+	t := afterFuncIdle
+	if t == nil {
+		// No idle goroutine, so start one. It runs f later, like the time
+		// package would.
+		go afterFuncGoroutine(f)
+		return
+	}
+	afterFuncIdle = nil
+	afterFuncSync = t
+	afterFuncNext = f
+	resumeTask(t)
+	if afterFuncSync == t {
+		// f blocked, so t continues as a regular goroutine.
+		afterFuncSync = nil
+		task.Started()
+	}
+}
+
+// afterFuncGoroutine runs f and then waits to be resumed by runAfterFunc to
+// run the next time.AfterFunc function.
+func afterFuncGoroutine(f func()) {
+	for {
+		f()
+		t := task.Current()
+		if afterFuncSync == t {
+			// Resumed by runAfterFunc and f didn't block: return to it.
+			afterFuncSync = nil
+		} else {
+			// Running as a regular goroutine.
+			if afterFuncIdle != nil {
+				// There already is an idle goroutine.
+				return
+			}
+			task.Exited()
+		}
+		afterFuncIdle = t
+		task.Pause()
+		f = afterFuncNext
+		afterFuncNext = nil
+	}
+}
@@ -56,6 +56,9 @@ func nanosecondsToTicks(ns int64) timeUnit {
 // A value of freq/1000 generates a tick (irq) every millisecond (1/1000 s).
 var cyclesPerMilli = machine.CPUFrequency() / 1000
 
+// cyclesPerMicro is used to calculate the microseconds since the last tick.
+var cyclesPerMicro = machine.CPUFrequency() / 1000000
+
 // number of systick irqs (milliseconds) since boot
 var systickCount volatile.Register64
 
@@ -70,6 +73,19 @@ func initSysTick() {
 	nxp.SystemControl.SHPR3.Set((32 << nxp.SystemControl_SHPR3_PRI_15_Pos) | (32 << nxp.SystemControl_SHPR3_PRI_14_Pos)) // set systick and pendsv priority to 32
 }
 
+// setTickFrequency is called by machine.SetTickFrequency, with interrupts
+// disabled, after the core clock has been changed to the given frequency. It
+// reprograms the SysTick reload value so that it keeps generating an interrupt
+// every millisecond. This restarts the current (partial) millisecond, which is
+// counted as a full millisecond so that the time doesn't go backwards.
+func setTickFrequency(hz uint32) {
+	cyclesPerMilli = hz / 1000
+	cyclesPerMicro = hz / 1000000
+	nxp.SysTick.RVR.Set(cyclesPerMilli - 1)
+	nxp.SysTick.CVR.Set(0)
+	systickCount.Set(systickCount.Get() + 1)
+}
+
 func initSleepTimer() {
 	nxp.SIM.SCGC5.SetBits(nxp.SIM_SCGC5_LPTMR)
 	nxp.LPTMR0.CSR.Set(nxp.LPTMR0_CSR_TIE)
@@ -98,7 +114,6 @@ func ticks() timeUnit {
 		micros += 1000
 	} else {
 		cycles := cyclesPerMilli - 1 - current // number of cycles since last 1ms tick
-		cyclesPerMicro := machine.CPUFrequency() / 1000000
 		micros += timeUnit(cycles / cyclesPerMicro)
 	}
 
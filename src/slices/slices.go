@@ -0,0 +1,327 @@
+// Package slices defines various functions useful with slices of any type.
+//
+// This is a TinyGo implementation of the slices package that was added in Go
+// 1.21, so that it can also be used with older Go versions. It is written
+// using generics only: no function in this package uses the reflect package.
+package slices
+
+import (
+	"cmp"
+	"unsafe"
+)
+
+// Equal reports whether two slices are equal: the same length and all
+// elements equal. If the lengths are different, Equal returns false.
+// Otherwise, the elements are compared in increasing index order, and the
+// comparison stops at the first unequal pair. Floating point NaNs are not
+// considered equal.
+func Equal[S ~[]E, E comparable](s1, s2 S) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualFunc reports whether two slices are equal using an equality function
+// on each pair of elements. If the lengths are different, EqualFunc returns
+// false. Otherwise, the elements are compared in increasing index order, and
+// the comparison stops at the first index for which eq returns false.
+func EqualFunc[S1 ~[]E1, S2 ~[]E2, E1, E2 any](s1 S1, s2 S2, eq func(E1, E2) bool) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i, v1 := range s1 {
+		if !eq(v1, s2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare compares the elements of s1 and s2, using cmp.Compare on each pair
+// of elements. The elements are compared sequentially, starting at index 0,
+// until one element is not equal to the other. The result of comparing the
+// first non-matching elements is returned. If both slices are equal until one
+// of them ends, the shorter slice is considered less than the longer one. The
+// result is 0 if s1 == s2, -1 if s1 < s2, and +1 if s1 > s2.
+func Compare[S ~[]E, E cmp.Ordered](s1, s2 S) int {
+	for i, v1 := range s1 {
+		if i >= len(s2) {
+			return +1
+		}
+		if c := cmp.Compare(v1, s2[i]); c != 0 {
+			return c
+		}
+	}
+	if len(s1) < len(s2) {
+		return -1
+	}
+	return 0
+}
+
+// CompareFunc is like Compare but uses a custom comparison function on each
+// pair of elements. The result is the first non-zero result of cmp; if cmp
+// always returns 0 the result is 0 if len(s1) == len(s2), -1 if len(s1) <
+// len(s2), and +1 if len(s1) > len(s2).
+func CompareFunc[S1 ~[]E1, S2 ~[]E2, E1, E2 any](s1 S1, s2 S2, cmp func(E1, E2) int) int {
+	for i, v1 := range s1 {
+		if i >= len(s2) {
+			return +1
+		}
+		if c := cmp(v1, s2[i]); c != 0 {
+			return c
+		}
+	}
+	if len(s1) < len(s2) {
+		return -1
+	}
+	return 0
+}
+
+// Index returns the index of the first occurrence of v in s, or -1 if not
+// present.
+func Index[S ~[]E, E comparable](s S, v E) int {
+	for i := range s {
+		if v == s[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// IndexFunc returns the first index i satisfying f(s[i]), or -1 if none do.
+func IndexFunc[S ~[]E, E any](s S, f func(E) bool) int {
+	for i := range s {
+		if f(s[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Contains reports whether v is present in s.
+func Contains[S ~[]E, E comparable](s S, v E) bool {
+	return Index(s, v) >= 0
+}
+
+// ContainsFunc reports whether at least one element e of s satisfies f(e).
+func ContainsFunc[S ~[]E, E any](s S, f func(E) bool) bool {
+	return IndexFunc(s, f) >= 0
+}
+
+// Insert inserts the values v... into s at index i, returning the modified
+// slice. The elements at s[i:] are shifted up to make room. In the returned
+// slice r, r[i] == v[0], and r[i+len(v)] == value originally at r[i]. Insert
+// panics if i is out of range. This function is O(len(s) + len(v)).
+func Insert[S ~[]E, E any](s S, i int, v ...E) S {
+	_ = s[i:] // bounds check
+
+	m := len(v)
+	if m == 0 {
+		return s
+	}
+	n := len(s)
+	if i == n {
+		return append(s, v...)
+	}
+	if n+m > cap(s) {
+		// Use append rather than make so that the capacity is rounded up to
+		// the allocation size, like a regular append.
+		s2 := append(s[:i], make(S, n+m-i)...)
+		copy(s2[i:], v)
+		copy(s2[i+m:], s[i:])
+		return s2
+	}
+	s = s[:n+m]
+	if overlaps(v, s[i:]) {
+		// The elements to insert would be overwritten while shifting the
+		// tail, so make a copy first.
+		v = Clone(v)
+	}
+	copy(s[i+m:], s[i:n])
+	copy(s[i:], v)
+	return s
+}
+
+// Delete removes the elements s[i:j] from s, returning the modified slice.
+// Delete panics if j > len(s) or s[i:j] is not a valid slice of s. Delete is
+// O(len(s)-i), so if many items must be deleted, it is better to make a single
+// call deleting them all together than to delete one at a time. The elements
+// that are no longer part of the returned slice are zeroed, so that they don't
+// keep other objects alive.
+func Delete[S ~[]E, E any](s S, i, j int) S {
+	_ = s[i:j:len(s)] // bounds check
+
+	if i == j {
+		return s
+	}
+	oldlen := len(s)
+	s = append(s[:i], s[j:]...)
+	clearTail(s, oldlen)
+	return s
+}
+
+// DeleteFunc removes any elements from s for which del returns true,
+// returning the modified slice. The elements that are no longer part of the
+// returned slice are zeroed.
+func DeleteFunc[S ~[]E, E any](s S, del func(E) bool) S {
+	i := IndexFunc(s, del)
+	if i == -1 {
+		return s
+	}
+	for j := i + 1; j < len(s); j++ {
+		if v := s[j]; !del(v) {
+			s[i] = v
+			i++
+		}
+	}
+	clearTail(s[:i], len(s))
+	return s[:i]
+}
+
+// Replace replaces the elements s[i:j] by the given v, and returns the
+// modified slice. Replace panics if s[i:j] is not a valid slice of s. The
+// elements that are no longer part of the returned slice are zeroed.
+func Replace[S ~[]E, E any](s S, i, j int, v ...E) S {
+	_ = s[i:j] // bounds check
+
+	if i == j {
+		return Insert(s, i, v...)
+	}
+	if j == len(s) {
+		s2 := append(s[:i], v...)
+		clearTail(s2, len(s))
+		return s2
+	}
+
+	tot := len(s[:i]) + len(v) + len(s[j:])
+	if tot > cap(s) {
+		// Too large to fit in place: allocate a new slice.
+		s2 := append(s[:i], make(S, tot-i)...)
+		copy(s2[i:], v)
+		copy(s2[i+len(v):], s[j:])
+		return s2
+	}
+
+	r := s[:tot]
+	if overlaps(v, r[i:]) {
+		// The elements to insert would be overwritten while moving the
+		// tail, so make a copy first.
+		v = Clone(v)
+	}
+	copy(r[i+len(v):], s[j:])
+	copy(r[i:], v)
+	clearTail(r, len(s))
+	return r
+}
+
+// Clone returns a copy of the slice. The elements are copied using
+// assignment, so this is a shallow clone. The result may have additional
+// unused capacity.
+func Clone[S ~[]E, E any](s S) S {
+	// The s[:0:0] preserves nil in case it matters.
+	return append(s[:0:0], s...)
+}
+
+// Compact replaces consecutive runs of equal elements with a single copy.
+// This is like the uniq command found on Unix. Compact modifies the contents
+// of the slice s and returns the modified slice, which may have a smaller
+// length. The elements that are no longer part of the returned slice are
+// zeroed.
+func Compact[S ~[]E, E comparable](s S) S {
+	if len(s) < 2 {
+		return s
+	}
+	i := 1
+	for k := 1; k < len(s); k++ {
+		if s[k] != s[k-1] {
+			if i != k {
+				s[i] = s[k]
+			}
+			i++
+		}
+	}
+	clearTail(s[:i], len(s))
+	return s[:i]
+}
+
+// CompactFunc is like Compact but uses an equality function to compare
+// elements. For runs of elements that compare equal, CompactFunc keeps the
+// first one.
+func CompactFunc[S ~[]E, E any](s S, eq func(E, E) bool) S {
+	if len(s) < 2 {
+		return s
+	}
+	i := 1
+	for k := 1; k < len(s); k++ {
+		if !eq(s[k], s[k-1]) {
+			if i != k {
+				s[i] = s[k]
+			}
+			i++
+		}
+	}
+	clearTail(s[:i], len(s))
+	return s[:i]
+}
+
+// Grow increases the slice's capacity, if necessary, to guarantee space for
+// another n elements. After Grow(n), at least n elements can be appended to
+// the slice without another allocation. If n is negative or too large to
+// allocate the memory, Grow panics.
+func Grow[S ~[]E, E any](s S, n int) S {
+	if n < 0 {
+		panic("cannot be negative")
+	}
+	if n -= cap(s) - len(s); n > 0 {
+		s = append(s[:cap(s)], make([]E, n)...)[:len(s)]
+	}
+	return s
+}
+
+// Clip removes unused capacity from the slice, returning s[:len(s):len(s)].
+func Clip[S ~[]E, E any](s S) S {
+	return s[:len(s):len(s)]
+}
+
+// Reverse reverses the elements of the slice in place.
+func Reverse[S ~[]E, E any](s S) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// clearTail zeroes the elements between len(s) and oldlen, which are no
+// longer part of the slice but may still point to other objects. It does
+// nothing if the slice didn't shrink.
+func clearTail[S ~[]E, E any](s S, oldlen int) {
+	if oldlen <= len(s) {
+		return
+	}
+	var zero E
+	tail := s[len(s):oldlen]
+	for i := range tail {
+		tail[i] = zero
+	}
+}
+
+// overlaps reports whether the memory ranges a[0:len(a)] and b[0:len(b)]
+// overlap.
+func overlaps[E any](a, b []E) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	elemSize := unsafe.Sizeof(a[0])
+	if elemSize == 0 {
+		return false
+	}
+	aStart := uintptr(unsafe.Pointer(&a[0]))
+	bStart := uintptr(unsafe.Pointer(&b[0]))
+	return aStart <= bStart+uintptr(len(b)-1)*elemSize &&
+		bStart <= aStart+uintptr(len(a)-1)*elemSize
+}
@@ -0,0 +1,327 @@
+package slices_test
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestEqualCompare(t *testing.T) {
+	tests := []struct {
+		s1, s2 []int
+		cmp    int
+	}{
+		{nil, nil, 0},
+		{nil, []int{}, 0},
+		{[]int{1, 2}, []int{1, 2}, 0},
+		{[]int{1, 2}, []int{1, 3}, -1},
+		{[]int{1, 2, 3}, []int{1, 2}, +1},
+		{[]int{1}, []int{1, 2}, -1},
+		{[]int{2}, []int{1, 2}, +1},
+	}
+	for _, tc := range tests {
+		if got := slices.Compare(tc.s1, tc.s2); got != tc.cmp {
+			t.Errorf("Compare(%v, %v) = %d, want %d", tc.s1, tc.s2, got, tc.cmp)
+		}
+		if got := slices.Equal(tc.s1, tc.s2); got != (tc.cmp == 0) {
+			t.Errorf("Equal(%v, %v) = %v", tc.s1, tc.s2, got)
+		}
+		eq := slices.EqualFunc(tc.s1, tc.s2, func(a, b int) bool { return a == b })
+		if eq != (tc.cmp == 0) {
+			t.Errorf("EqualFunc(%v, %v) = %v", tc.s1, tc.s2, eq)
+		}
+		if got := slices.CompareFunc(tc.s1, tc.s2, cmp.Compare[int]); got != tc.cmp {
+			t.Errorf("CompareFunc(%v, %v) = %d, want %d", tc.s1, tc.s2, got, tc.cmp)
+		}
+	}
+
+	nan := []float64{math.NaN()}
+	if slices.Equal(nan, nan) {
+		t.Error("Equal treats NaN as equal")
+	}
+	if slices.Compare(nan, nan) != 0 {
+		t.Error("Compare does not treat NaN as equal")
+	}
+}
+
+func TestIndexContains(t *testing.T) {
+	s := []string{"a", "b", "c", "b"}
+	if i := slices.Index(s, "b"); i != 1 {
+		t.Errorf("Index(b) = %d, want 1", i)
+	}
+	if i := slices.Index(s, "d"); i != -1 {
+		t.Errorf("Index(d) = %d, want -1", i)
+	}
+	if i := slices.IndexFunc(s, func(v string) bool { return v > "b" }); i != 2 {
+		t.Errorf("IndexFunc = %d, want 2", i)
+	}
+	if !slices.Contains(s, "c") || slices.Contains(s, "x") {
+		t.Error("Contains returned the wrong result")
+	}
+	if slices.Contains([]int(nil), 0) {
+		t.Error("nil slice contains 0")
+	}
+	if !slices.ContainsFunc(s, func(v string) bool { return v == "a" }) {
+		t.Error("ContainsFunc returned false")
+	}
+}
+
+func TestInsert(t *testing.T) {
+	s := []int{1, 2, 3}
+	s = slices.Insert(s, 1, 10, 11)
+	if want := []int{1, 10, 11, 2, 3}; !slices.Equal(s, want) {
+		t.Errorf("Insert = %v, want %v", s, want)
+	}
+	s = slices.Insert(s, len(s), 4)
+	if want := []int{1, 10, 11, 2, 3, 4}; !slices.Equal(s, want) {
+		t.Errorf("Insert at end = %v, want %v", s, want)
+	}
+
+	// Insert in place, where the inserted values are part of the slice.
+	s = make([]int, 4, 10)
+	for i := range s {
+		s[i] = i
+	}
+	s = slices.Insert(s, 1, s[2:]...)
+	if want := []int{0, 2, 3, 1, 2, 3}; !slices.Equal(s, want) {
+		t.Errorf("overlapping Insert = %v, want %v", s, want)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	s := []int{0, 1, 2, 3, 4}
+	s2 := slices.Delete(s, 1, 3)
+	if want := []int{0, 3, 4}; !slices.Equal(s2, want) {
+		t.Errorf("Delete = %v, want %v", s2, want)
+	}
+	if s[3] != 0 || s[4] != 0 {
+		t.Errorf("Delete did not clear the tail: %v", s)
+	}
+
+	s = []int{0, 1, 2, 3, 4, 5}
+	s = slices.DeleteFunc(s, func(v int) bool { return v%2 == 1 })
+	if want := []int{0, 2, 4}; !slices.Equal(s, want) {
+		t.Errorf("DeleteFunc = %v, want %v", s, want)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		i, j int
+		v    []int
+		want []int
+	}{
+		{1, 3, []int{10}, []int{0, 10, 3, 4}},
+		{1, 2, []int{10, 11, 12}, []int{0, 10, 11, 12, 2, 3, 4}},
+		{2, 2, []int{10}, []int{0, 1, 10, 2, 3, 4}},
+		{3, 5, []int{10}, []int{0, 1, 2, 10}},
+		{0, 5, nil, []int{}},
+	}
+	for _, tc := range tests {
+		s := []int{0, 1, 2, 3, 4}
+		got := slices.Replace(s, tc.i, tc.j, tc.v...)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("Replace(%d, %d, %v) = %v, want %v", tc.i, tc.j, tc.v, got, tc.want)
+		}
+	}
+
+	// Replace in place with a longer slice that overlaps the tail.
+	s := make([]int, 5, 10)
+	for i := range s {
+		s[i] = i
+	}
+	s = slices.Replace(s, 1, 2, s[3:]...)
+	if want := []int{0, 3, 4, 2, 3, 4}; !slices.Equal(s, want) {
+		t.Errorf("overlapping Replace = %v, want %v", s, want)
+	}
+}
+
+func TestCloneCompact(t *testing.T) {
+	if slices.Clone([]int(nil)) != nil {
+		t.Error("Clone(nil) is not nil")
+	}
+	s := []int{1, 1, 2, 3, 3, 3, 1}
+	c := slices.Clone(s)
+	c[0] = 5
+	if s[0] != 1 {
+		t.Error("Clone shares the backing array")
+	}
+
+	s = slices.Compact(s)
+	if want := []int{1, 2, 3, 1}; !slices.Equal(s, want) {
+		t.Errorf("Compact = %v, want %v", s, want)
+	}
+	words := []string{"a", "A", "b", "B", "b"}
+	words = slices.CompactFunc(words, func(a, b string) bool { return a[0]|0x20 == b[0]|0x20 })
+	if want := []string{"a", "b"}; !slices.Equal(words, want) {
+		t.Errorf("CompactFunc = %v, want %v", words, want)
+	}
+}
+
+func TestGrowClipReverse(t *testing.T) {
+	s := []int{1, 2}
+	s = slices.Grow(s, 10)
+	if len(s) != 2 || cap(s) < 12 {
+		t.Errorf("Grow: len %d cap %d", len(s), cap(s))
+	}
+	s = slices.Clip(s)
+	if cap(s) != 2 {
+		t.Errorf("Clip: cap %d", cap(s))
+	}
+	s = []int{1, 2, 3, 4, 5}
+	slices.Reverse(s)
+	if want := []int{5, 4, 3, 2, 1}; !slices.Equal(s, want) {
+		t.Errorf("Reverse = %v, want %v", s, want)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	s := []int{3, 1, 4, 1, 5, 9, 2, 6}
+	if m := slices.Min(s); m != 1 {
+		t.Errorf("Min = %d", m)
+	}
+	if m := slices.Max(s); m != 9 {
+		t.Errorf("Max = %d", m)
+	}
+	if m := slices.Max([]float64{1, math.NaN(), 3}); !math.IsNaN(m) {
+		t.Errorf("Max with NaN = %v", m)
+	}
+
+	type item struct {
+		key, id int
+	}
+	items := []item{{2, 0}, {1, 1}, {3, 2}, {1, 3}, {3, 4}}
+	byKey := func(a, b item) int { return cmp.Compare(a.key, b.key) }
+	if m := slices.MinFunc(items, byKey); m.id != 1 {
+		t.Errorf("MinFunc = %v", m)
+	}
+	if m := slices.MaxFunc(items, byKey); m.id != 2 {
+		t.Errorf("MaxFunc = %v", m)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Min of an empty slice did not panic")
+		}
+	}()
+	slices.Min([]int{})
+}
+
+// random returns a deterministic pseudo-random slice of n numbers in the range
+// [0, max).
+func random(n, max int) []int {
+	s := make([]int, n)
+	x := uint32(n)
+	for i := range s {
+		x = x*1664525 + 1013904223
+		s[i] = int(x>>8) % max
+	}
+	return s
+}
+
+func TestSort(t *testing.T) {
+	inputs := [][]int{
+		nil,
+		{1},
+		{2, 1},
+		random(10, 5),
+		random(100, 1000),
+		random(1000, 10),
+		random(1000, 1000000),
+	}
+	// Already sorted, reverse sorted and all equal inputs.
+	sorted := make([]int, 500)
+	reversed := make([]int, 500)
+	equal := make([]int, 500)
+	for i := range sorted {
+		sorted[i] = i
+		reversed[i] = len(reversed) - i
+		equal[i] = 7
+	}
+	inputs = append(inputs, sorted, reversed, equal)
+
+	for _, in := range inputs {
+		s := slices.Clone(in)
+		slices.Sort(s)
+		if !slices.IsSorted(s) {
+			t.Errorf("Sort of %d elements is not sorted", len(s))
+		}
+		s = slices.Clone(in)
+		slices.SortFunc(s, func(a, b int) int { return cmp.Compare(b, a) })
+		if !slices.IsSortedFunc(s, func(a, b int) int { return cmp.Compare(b, a) }) {
+			t.Errorf("SortFunc of %d elements is not sorted", len(s))
+		}
+		if len(s) > 1 && s[0] < s[len(s)-1] {
+			t.Errorf("SortFunc of %d elements is not in descending order", len(s))
+		}
+	}
+
+	strs := []string{"banana", "apple", "cherry", "", "apple"}
+	slices.Sort(strs)
+	if want := []string{"", "apple", "apple", "banana", "cherry"}; !slices.Equal(strs, want) {
+		t.Errorf("Sort = %v, want %v", strs, want)
+	}
+
+	floats := []float64{3, math.NaN(), -1, math.Inf(1), math.NaN(), 0}
+	slices.Sort(floats)
+	if !math.IsNaN(floats[0]) || !math.IsNaN(floats[1]) || floats[2] != -1 || floats[5] != math.Inf(1) {
+		t.Errorf("Sort with NaN = %v", floats)
+	}
+}
+
+func TestSortStable(t *testing.T) {
+	type item struct {
+		key, index int
+	}
+	for _, n := range []int{0, 1, 19, 20, 21, 100, 1000} {
+		keys := random(n, 10)
+		items := make([]item, n)
+		for i, key := range keys {
+			items[i] = item{key, i}
+		}
+		slices.SortStableFunc(items, func(a, b item) int { return cmp.Compare(a.key, b.key) })
+		for i := 1; i < n; i++ {
+			a, b := items[i-1], items[i]
+			if a.key > b.key || a.key == b.key && a.index > b.index {
+				t.Fatalf("SortStableFunc of %d elements: %v before %v", n, a, b)
+			}
+		}
+	}
+}
+
+func TestBinarySearch(t *testing.T) {
+	s := []int{1, 3, 3, 5, 8}
+	tests := []struct {
+		target int
+		pos    int
+		found  bool
+	}{
+		{0, 0, false},
+		{1, 0, true},
+		{3, 1, true},
+		{4, 3, false},
+		{8, 4, true},
+		{9, 5, false},
+	}
+	for _, tc := range tests {
+		pos, found := slices.BinarySearch(s, tc.target)
+		if pos != tc.pos || found != tc.found {
+			t.Errorf("BinarySearch(%d) = %d, %v, want %d, %v", tc.target, pos, found, tc.pos, tc.found)
+		}
+	}
+
+	names := []string{"1", "2", "10", "20"}
+	pos, found := slices.BinarySearchFunc(names, 10, func(name string, target int) int {
+		n, _ := strconv.Atoi(name)
+		return cmp.Compare(n, target)
+	})
+	if pos != 2 || !found {
+		t.Errorf("BinarySearchFunc = %d, %v, want 2, true", pos, found)
+	}
+
+	if pos, found := slices.BinarySearch([]int(nil), 1); pos != 0 || found {
+		t.Errorf("BinarySearch on nil slice = %d, %v", pos, found)
+	}
+}
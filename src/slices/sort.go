@@ -0,0 +1,384 @@
+package slices
+
+import (
+	"cmp"
+	"math/bits"
+)
+
+// The sorting functions in this file use an introsort: a quicksort that falls
+// back to heapsort when the recursion gets too deep, and to insertion sort for
+// short ranges. This keeps the code small while avoiding the O(n^2) worst case
+// of a plain quicksort. The stable sort is an insertion sort on small blocks
+// followed by in-place merging of those blocks (SymMerge), so it doesn't need
+// to allocate.
+
+// Sort sorts a slice of any ordered type in ascending order. When sorting
+// floating-point numbers, NaNs are ordered before other values.
+func Sort[S ~[]E, E cmp.Ordered](x S) {
+	quickSort(x, cmp.Less[E], maxDepth(len(x)))
+}
+
+// SortFunc sorts the slice x in ascending order as determined by the cmp
+// function. This sort is not guaranteed to be stable. cmp(a, b) should return
+// a negative number when a < b, a positive number when a > b and zero when a
+// == b.
+//
+// SortFunc requires that cmp is a strict weak ordering.
+func SortFunc[S ~[]E, E any](x S, cmp func(a, b E) int) {
+	quickSort(x, func(a, b E) bool { return cmp(a, b) < 0 }, maxDepth(len(x)))
+}
+
+// SortStableFunc sorts the slice x while keeping the original order of equal
+// elements, using cmp to compare elements in the same way as SortFunc.
+func SortStableFunc[S ~[]E, E any](x S, cmp func(a, b E) int) {
+	stableSort(x, func(a, b E) bool { return cmp(a, b) < 0 })
+}
+
+// IsSorted reports whether x is sorted in ascending order.
+func IsSorted[S ~[]E, E cmp.Ordered](x S) bool {
+	for i := len(x) - 1; i > 0; i-- {
+		if cmp.Less(x[i], x[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSortedFunc reports whether x is sorted in ascending order, with cmp as
+// the comparison function as defined by SortFunc.
+func IsSortedFunc[S ~[]E, E any](x S, cmp func(a, b E) int) bool {
+	for i := len(x) - 1; i > 0; i-- {
+		if cmp(x[i], x[i-1]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Min returns the minimal value in x. It panics if x is empty. For
+// floating-point numbers, Min propagates NaNs (any NaN value in x forces the
+// output to be NaN).
+func Min[S ~[]E, E cmp.Ordered](x S) E {
+	if len(x) < 1 {
+		panic("slices.Min: empty list")
+	}
+	m := x[0]
+	for i := 1; i < len(x); i++ {
+		if x[i] != x[i] {
+			return x[i]
+		}
+		if x[i] < m {
+			m = x[i]
+		}
+	}
+	return m
+}
+
+// MinFunc returns the minimal value in x, using cmp to compare elements. It
+// panics if x is empty. If there is more than one minimal element according
+// to the cmp function, MinFunc returns the first one.
+func MinFunc[S ~[]E, E any](x S, cmp func(a, b E) int) E {
+	if len(x) < 1 {
+		panic("slices.MinFunc: empty list")
+	}
+	m := x[0]
+	for i := 1; i < len(x); i++ {
+		if cmp(x[i], m) < 0 {
+			m = x[i]
+		}
+	}
+	return m
+}
+
+// Max returns the maximal value in x. It panics if x is empty. For
+// floating-point E, Max propagates NaNs (any NaN value in x forces the output
+// to be NaN).
+func Max[S ~[]E, E cmp.Ordered](x S) E {
+	if len(x) < 1 {
+		panic("slices.Max: empty list")
+	}
+	m := x[0]
+	for i := 1; i < len(x); i++ {
+		if x[i] != x[i] {
+			return x[i]
+		}
+		if x[i] > m {
+			m = x[i]
+		}
+	}
+	return m
+}
+
+// MaxFunc returns the maximal value in x, using cmp to compare elements. It
+// panics if x is empty. If there is more than one maximal element according
+// to the cmp function, MaxFunc returns the first one.
+func MaxFunc[S ~[]E, E any](x S, cmp func(a, b E) int) E {
+	if len(x) < 1 {
+		panic("slices.MaxFunc: empty list")
+	}
+	m := x[0]
+	for i := 1; i < len(x); i++ {
+		if cmp(x[i], m) > 0 {
+			m = x[i]
+		}
+	}
+	return m
+}
+
+// BinarySearch searches for target in a sorted slice and returns the position
+// where target is found, or the position where target would appear in the
+// sort order; it also returns a bool saying whether the target is really
+// found in the slice. The slice must be sorted in increasing order.
+func BinarySearch[S ~[]E, E cmp.Ordered](x S, target E) (int, bool) {
+	n := len(x)
+	i, j := 0, n
+	for i < j {
+		h := int(uint(i+j) >> 1) // avoid overflow when computing h
+		if cmp.Less(x[h], target) {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i, i < n && cmp.Compare(x[i], target) == 0
+}
+
+// BinarySearchFunc works like BinarySearch, but uses a custom comparison
+// function. The slice must be sorted in increasing order, where "increasing"
+// is defined by cmp. cmp should return 0 if the slice element matches the
+// target, a negative number if the slice element precedes the target, or a
+// positive number if the slice element follows the target. cmp must implement
+// the same ordering as the slice, such that if cmp(a, t) < 0 and cmp(b, t) >=
+// 0, then a must precede b in the slice.
+func BinarySearchFunc[S ~[]E, E, T any](x S, target T, cmp func(E, T) int) (int, bool) {
+	n := len(x)
+	i, j := 0, n
+	for i < j {
+		h := int(uint(i+j) >> 1) // avoid overflow when computing h
+		if cmp(x[h], target) < 0 {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i, i < n && cmp(x[i], target) == 0
+}
+
+// maxDepth returns the recursion depth at which quickSort switches to
+// heapsort.
+func maxDepth(n int) int {
+	return 2 * bits.Len(uint(n))
+}
+
+func quickSort[E any](x []E, less func(a, b E) bool, depth int) {
+	for len(x) > 12 {
+		if depth == 0 {
+			heapSort(x, less)
+			return
+		}
+		depth--
+		p := partition(x, less)
+		// Recurse into the smaller half and loop for the larger half, so that
+		// the stack depth is at most O(log n).
+		if p < len(x)-p {
+			quickSort(x[:p], less, depth)
+			x = x[p+1:]
+		} else {
+			quickSort(x[p+1:], less, depth)
+			x = x[:p]
+		}
+	}
+	insertionSort(x, less)
+}
+
+// partition moves the median of the first, middle and last element to x[0]
+// and partitions x around it. It returns the final index of the pivot: all
+// elements before it are not greater and all elements after it are not less
+// than the pivot.
+func partition[E any](x []E, less func(a, b E) bool) int {
+	n := len(x)
+	m := n / 2
+	if less(x[0], x[m]) {
+		x[0], x[m] = x[m], x[0]
+	}
+	if less(x[n-1], x[0]) {
+		x[0], x[n-1] = x[n-1], x[0]
+		if less(x[0], x[m]) {
+			x[0], x[m] = x[m], x[0]
+		}
+	}
+	pivot := x[0]
+
+	i, j := 0, n
+	for {
+		i++
+		for i < n && less(x[i], pivot) {
+			i++
+		}
+		j--
+		for less(pivot, x[j]) {
+			j--
+		}
+		if i >= j {
+			break
+		}
+		x[i], x[j] = x[j], x[i]
+	}
+	x[0], x[j] = x[j], x[0]
+	return j
+}
+
+func insertionSort[E any](x []E, less func(a, b E) bool) {
+	for i := 1; i < len(x); i++ {
+		for j := i; j > 0 && less(x[j], x[j-1]); j-- {
+			x[j], x[j-1] = x[j-1], x[j]
+		}
+	}
+}
+
+func heapSort[E any](x []E, less func(a, b E) bool) {
+	n := len(x)
+	for i := (n - 1) / 2; i >= 0; i-- {
+		siftDown(x, less, i, n)
+	}
+	for i := n - 1; i > 0; i-- {
+		x[0], x[i] = x[i], x[0]
+		siftDown(x, less, 0, i)
+	}
+}
+
+// siftDown implements the heap property on x[root:n].
+func siftDown[E any](x []E, less func(a, b E) bool, root, n int) {
+	for {
+		child := 2*root + 1
+		if child >= n {
+			return
+		}
+		if child+1 < n && less(x[child], x[child+1]) {
+			child++
+		}
+		if !less(x[root], x[child]) {
+			return
+		}
+		x[root], x[child] = x[child], x[root]
+		root = child
+	}
+}
+
+func stableSort[E any](x []E, less func(a, b E) bool) {
+	const blockSize = 20
+	n := len(x)
+	a, b := 0, blockSize
+	for b <= n {
+		insertionSort(x[a:b], less)
+		a = b
+		b += blockSize
+	}
+	insertionSort(x[a:], less)
+
+	for blockSize := blockSize; blockSize < n; blockSize *= 2 {
+		a, b = 0, 2*blockSize
+		for b <= n {
+			symMerge(x, less, a, a+blockSize, b)
+			a = b
+			b += 2 * blockSize
+		}
+		if m := a + blockSize; m < n {
+			symMerge(x, less, a, m, n)
+		}
+	}
+}
+
+// symMerge merges the two sorted subsequences x[a:m] and x[m:b] using the
+// SymMerge algorithm from Pok-Son Kim and Arne Kutzner, "Stable Minimum
+// Storage Merging by Symmetric Comparisons", in Susanne Albers and Tomasz
+// Radzik, editors, Algorithms - ESA 2004, volume 3221 of Lecture Notes in
+// Computer Science, pages 714-723. Springer, 2004.
+func symMerge[E any](x []E, less func(a, b E) bool, a, m, b int) {
+	// Insert x[a] into x[m:b] with a binary search if x[a:m] has only one
+	// element, and the other way around.
+	if m-a == 1 {
+		i, j := m, b
+		for i < j {
+			h := int(uint(i+j) >> 1)
+			if less(x[h], x[a]) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := a; k < i-1; k++ {
+			x[k], x[k+1] = x[k+1], x[k]
+		}
+		return
+	}
+	if b-m == 1 {
+		i, j := a, m
+		for i < j {
+			h := int(uint(i+j) >> 1)
+			if !less(x[m], x[h]) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := m; k > i; k-- {
+			x[k], x[k-1] = x[k-1], x[k]
+		}
+		return
+	}
+
+	mid := int(uint(a+b) >> 1)
+	n := mid + m
+	var start, r int
+	if m > mid {
+		start = n - b
+		r = mid
+	} else {
+		start = a
+		r = m
+	}
+	p := n - 1
+	for start < r {
+		c := int(uint(start+r) >> 1)
+		if !less(x[p-c], x[c]) {
+			start = c + 1
+		} else {
+			r = c
+		}
+	}
+
+	end := n - start
+	if start < m && m < end {
+		rotate(x, start, m, end)
+	}
+	if a < start && start < mid {
+		symMerge(x, less, a, start, mid)
+	}
+	if mid < end && end < b {
+		symMerge(x, less, mid, end, b)
+	}
+}
+
+// rotate rotates two consecutive blocks u = x[a:m] and v = x[m:b] so that
+// they are in the order v, u.
+func rotate[E any](x []E, a, m, b int) {
+	i := m - a
+	j := b - m
+	for i != j {
+		if i > j {
+			swapRange(x, m-i, m, j)
+			i -= j
+		} else {
+			swapRange(x, m-i, m+j-i, i)
+			j -= i
+		}
+	}
+	swapRange(x, m-i, m, i)
+}
+
+func swapRange[E any](x []E, a, b, n int) {
+	for i := 0; i < n; i++ {
+		x[a+i], x[b+i] = x[b+i], x[a+i]
+	}
+}
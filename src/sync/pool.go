@@ -1,19 +1,81 @@
 package sync
 
-// Pool is a very simple implementation of sync.Pool. It does not actually
-// implement a pool.
+import _ "unsafe" // for go:linkname
+
+// Pool is a set of temporary objects that may be individually saved and
+// retrieved, to reduce the number of allocations.
+//
+// Unlike the Go implementation, a pool holds at most poolMaxItems objects:
+// objects that are put in a full pool are dropped. All objects in all pools
+// are dropped at the start of every GC cycle, so pooled objects never keep the
+// heap from being reclaimed when memory runs low.
 type Pool struct {
 	New func() interface{}
+
+	items  []interface{}
+	next   *Pool // next pool in allPools
+	inList bool  // whether this pool is in allPools
 }
 
-// Get returns the value of calling Pool.New().
+// Maximum number of objects stored in a single pool.
+const poolMaxItems = 8
+
+// Linked list of all pools that contain (or may contain) objects, so that they
+// can be emptied at the start of a GC cycle. A linked list is used so that
+// adding a pool to the list doesn't need to allocate.
+var allPools *Pool
+
+// Get returns an arbitrary object from the pool and removes it from the pool.
+// If the pool is empty, it returns the result of calling p.New, or nil if
+// p.New is nil.
 func (p *Pool) Get() interface{} {
+	for len(p.items) > 0 {
+		n := len(p.items) - 1
+		x := p.items[n]
+		p.items[n] = nil
+		p.items = p.items[:n]
+		if x != nil {
+			// The value may be nil when the GC ran while Put was growing the
+			// slice.
+			return x
+		}
+	}
 	if p.New == nil {
 		return nil
 	}
 	return p.New()
 }
 
-// Put drops the value put into the pool.
+// Put adds x to the pool, unless the pool is full.
 func (p *Pool) Put(x interface{}) {
+	if x == nil || len(p.items) >= poolMaxItems {
+		return
+	}
+	p.items = append(p.items, x)
+
+	// Add the pool to the list only after appending: appending may trigger a
+	// GC cycle, which empties all pools and clears the list.
+	if !p.inList {
+		p.inList = true
+		p.next = allPools
+		allPools = p
+		runtime_registerPoolCleanup(poolCleanup)
+	}
+}
+
+// poolCleanup drops all objects from all pools. It is called by the runtime at
+// the start of a GC cycle, so it must not allocate.
+func poolCleanup() {
+	for p := allPools; p != nil; {
+		next := p.next
+		p.items = nil
+		p.next = nil
+		p.inList = false
+		p = next
+	}
+	allPools = nil
 }
+
+// Register the function to call at the start of a GC cycle. It is implemented
+// in the runtime.
+func runtime_registerPoolCleanup(cleanup func())
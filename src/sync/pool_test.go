@@ -0,0 +1,89 @@
+package sync_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestPoolReuse checks that objects put in a pool are returned again by Get.
+func TestPoolReuse(t *testing.T) {
+	created := 0
+	p := sync.Pool{
+		New: func() interface{} {
+			created++
+			return new([64]byte)
+		},
+	}
+
+	for i := 0; i < 100; i++ {
+		buf := p.Get().(*[64]byte)
+		buf[0] = byte(i)
+		p.Put(buf)
+	}
+	if created > 2 {
+		t.Errorf("expected objects to be reused, but New was called %d times", created)
+	}
+
+	// A pool without New returns nil when it is empty.
+	var empty sync.Pool
+	if x := empty.Get(); x != nil {
+		t.Errorf("expected nil from an empty pool, got %v", x)
+	}
+}
+
+// TestPoolGC checks that pooled objects are dropped when the GC runs because
+// the heap is under pressure.
+func TestPoolGC(t *testing.T) {
+	created := 0
+	p := sync.Pool{
+		New: func() interface{} {
+			created++
+			return new([1024]byte)
+		},
+	}
+	p.Put(p.Get())
+	p.Put(p.Get())
+	if created != 1 {
+		t.Fatalf("expected New to be called once, got %d", created)
+	}
+
+	// Allocate garbage until the GC has run at least twice (the Go
+	// implementation keeps objects for one extra cycle).
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	startGC := stats.NumGC
+	for i := 0; i < 1e6; i++ {
+		sink = make([]byte, 1024)
+		if i%64 == 0 {
+			runtime.ReadMemStats(&stats)
+			if stats.NumGC >= startGC+2 {
+				break
+			}
+		}
+	}
+	if stats.NumGC < startGC+2 {
+		t.Skip("GC did not run")
+	}
+
+	p.Get()
+	if created != 2 {
+		t.Errorf("expected pooled object to be dropped by the GC, New was called %d times", created)
+	}
+}
+
+var sink []byte
+
+func BenchmarkPool(b *testing.B) {
+	p := sync.Pool{
+		New: func() interface{} {
+			return new([64]byte)
+		},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := p.Get().(*[64]byte)
+		buf[0] = byte(i)
+		p.Put(buf)
+	}
+}
@@ -272,6 +272,27 @@ func ReadDirent(fd int, buf []byte) (n int, err error) {
 	return -1, ENOSYS
 }
 
+// readdir reads directory entries using the WASI fd_readdir call, starting at
+// the entry identified by cookie (0 for the first entry). Unlike ReadDirent,
+// the position in the directory is not stored in the file descriptor: it is
+// the d_next field of the last entry that was read. The last entry in buf may
+// be truncated. When fewer than len(buf) bytes are returned, the end of the
+// directory has been reached.
+//
+// This is used by os.File.readdir.
+func readdir(fd int, buf []byte, cookie uint64) (n int, err error) {
+	var nwritten uint32
+	errno := fd_readdir(int32(fd), unsafe.Pointer(&buf[0]), uint32(len(buf)), cookie, &nwritten)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(nwritten), nil
+}
+
+//go:wasm-module wasi_snapshot_preview1
+//export fd_readdir
+func fd_readdir(fd int32, buf unsafe.Pointer, bufLen uint32, cookie uint64, nwritten *uint32) Errno
+
 func Stat(path string, p *Stat_t) (err error) {
 	data := cstring(path)
 	n := libc_stat(&data[0], unsafe.Pointer(p))
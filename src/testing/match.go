@@ -176,7 +176,7 @@ func isSpace(r rune) bool {
 // Inflexible, but saves 50KB of flash and 50KB of RAM per -size full,
 // and lets tests pass on cortex-m.
 func fakeMatchString(pat, str string) (bool, error) {
-	if pat == ".*" {
+	if pat == ".*" || pat == "." {
 		return true, nil
 	}
 	matched := strings.Contains(str, pat)
@@ -0,0 +1,39 @@
+//go:build !scheduler.none
+// +build !scheduler.none
+
+package testing
+
+// runSubtest runs the subtest in a new goroutine, so that it can be paused by
+// Parallel. It returns when the subtest has finished or has been paused.
+func runSubtest(t *T, fn func(t *T)) {
+	t.signal = make(chan bool, 1)
+	go func() {
+		tRunner(t, fn)
+		t.signal <- true
+	}()
+	<-t.signal
+}
+
+// waitParallel lets the parent test continue, and waits until the test
+// function of the parent has returned.
+func (t *T) waitParallel() {
+	parent := t.parent
+	if parent.barrier == nil {
+		parent.barrier = make(chan bool)
+	}
+	parent.parallelSubs = append(parent.parallelSubs, t.signal)
+	t.signal <- true
+	<-parent.barrier
+}
+
+// runParallelSubtests starts the paused parallel subtests and waits until they
+// have all finished.
+func (c *common) runParallelSubtests() {
+	if c.barrier == nil {
+		return
+	}
+	close(c.barrier)
+	for _, signal := range c.parallelSubs {
+		<-signal
+	}
+}
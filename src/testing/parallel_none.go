@@ -0,0 +1,18 @@
+//go:build scheduler.none
+// +build scheduler.none
+
+package testing
+
+// runSubtest runs the subtest to completion. Without a scheduler there is no
+// way to pause it, so it runs in the current goroutine.
+func runSubtest(t *T, fn func(t *T)) {
+	tRunner(t, fn)
+}
+
+// waitParallel does nothing: the parallel test keeps running right away.
+func (t *T) waitParallel() {
+}
+
+// runParallelSubtests does nothing, as parallel subtests have already finished.
+func (c *common) runParallelSubtests() {
+}
@@ -57,6 +57,11 @@ type common struct {
 
 	hasSub bool // TODO: should be atomic
 
+	isParallel   bool        // Test has called Parallel.
+	signal       chan bool   // To signal a test is done or paused by Parallel.
+	barrier      chan bool   // Closed when parallel subtests may start.
+	parallelSubs []chan bool // Signal channels of paused parallel subtests.
+
 	parent   *common
 	level    int       // Nesting depth of test or benchmark.
 	name     string    // Name of test or benchmark.
@@ -73,6 +78,16 @@ func Short() bool {
 	return flagShort
 }
 
+// Testing reports whether the current code is being run in a test. It returns
+// false until MainStart has been called, so it is not reliable in package
+// initializers.
+func Testing() bool {
+	return testBinary
+}
+
+// testBinary is set by MainStart.
+var testBinary bool
+
 // CoverMode reports what the test coverage mode is set to.
 //
 // Test coverage is not supported; this returns the empty string.
@@ -346,9 +361,33 @@ func (c *common) runCleanup() {
 	}
 }
 
-// Parallel is not implemented, it is only provided for compatibility.
+// Parallel signals that this test is to be run in parallel with (and only with)
+// other parallel tests. The test is paused until the test function of its
+// parent has returned, and then runs together with the other parallel
+// subtests of the parent.
+//
+// Without a scheduler, tests can't be paused and Parallel has no effect: the
+// test keeps running and finishes before the next test starts.
 func (t *T) Parallel() {
-	// Unimplemented.
+	if t.isParallel {
+		panic("testing: t.Parallel called multiple times")
+	}
+	t.isParallel = true
+	if t.parent == nil {
+		// The fake top-level test is never run in parallel.
+		return
+	}
+
+	// The time spent waiting for the parent is not part of the test.
+	t.duration += time.Since(t.start)
+	if flagVerbose {
+		fmt.Fprintf(&t.parent.output, "=== PAUSE %s\n", t.name)
+	}
+	t.waitParallel()
+	if flagVerbose {
+		fmt.Fprintf(&t.parent.output, "=== CONT  %s\n", t.name)
+	}
+	t.start = time.Now()
 }
 
 // InternalTest is a reference to a test that should be called during a test suite run.
@@ -367,6 +406,9 @@ func tRunner(t *T, fn func(t *T)) {
 	fn(t)
 	t.duration += time.Since(t.start) // TODO: capture cleanup time, too.
 
+	// Parallel subtests only start once the test function has returned.
+	t.runParallelSubtests()
+
 	t.report() // Report after all subtests have finished.
 	if t.parent != nil && !t.hasSub {
 		t.setRan()
@@ -392,13 +434,13 @@ func (t *T) Run(name string, f func(t *T)) bool {
 		context: t.context,
 	}
 	if t.level > 0 {
-		sub.indent = sub.indent + "    "
+		sub.indent = t.indent + "    "
 	}
 	if flagVerbose {
 		fmt.Fprintf(&t.output, "=== RUN   %s\n", sub.name)
 	}
 
-	tRunner(&sub, f)
+	runSubtest(&sub, f)
 	return !sub.failed
 }
 
@@ -516,6 +558,7 @@ type InternalExample struct {
 // It is not meant to be called directly and is not subject to the Go 1 compatibility document.
 // It may change signature from release to release.
 func MainStart(deps interface{}, tests []InternalTest, benchmarks []InternalBenchmark, fuzzTargets []InternalFuzzTarget, examples []InternalExample) *M {
+	testBinary = true
 	Init()
 	return &M{
 		Tests:      tests,
@@ -0,0 +1,8 @@
+package time
+
+// Export the general formatting and parsing code, which is used for layouts
+// other than RFC3339 and RFC3339Nano, to compare the fast path against it.
+var (
+	AppendFormatGeneral = Time.appendFormat
+	ParseGeneral        = parse
+)
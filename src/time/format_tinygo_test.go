@@ -0,0 +1,121 @@
+package time_test
+
+import (
+	"testing"
+	. "time"
+)
+
+var rfc3339Times = []Time{
+	Date(2006, 1, 2, 15, 4, 5, 0, UTC),
+	Date(2006, 1, 2, 15, 4, 5, 123456789, UTC),
+	Date(2006, 1, 2, 15, 4, 5, 120000000, UTC),
+	Date(2006, 1, 2, 15, 4, 5, 1, UTC),
+	Date(1999, 12, 31, 23, 59, 59, 999999999, FixedZone("CET", 3600)),
+	Date(2024, 2, 29, 0, 0, 0, 500, FixedZone("", -(7*3600+30*60))),
+	Date(2024, 7, 1, 8, 9, 10, 0, FixedZone("odd", 5*3600+45*60+30)),
+	Date(1, 1, 1, 0, 0, 0, 0, UTC),
+	Date(9999, 12, 31, 23, 59, 59, 0, UTC),
+	Date(10000, 1, 1, 0, 0, 0, 0, UTC),
+	Date(-1, 1, 1, 0, 0, 0, 0, UTC),
+	Unix(1700000000, 42).UTC(),
+}
+
+func TestFormatRFC3339FastPath(t *testing.T) {
+	for _, tm := range rfc3339Times {
+		for _, layout := range []string{RFC3339, RFC3339Nano} {
+			got := tm.Format(layout)
+			if want := string(AppendFormatGeneral(tm, nil, layout)); got != want {
+				t.Errorf("%v.Format(%q) = %q, want %q", tm, layout, got, want)
+			}
+		}
+	}
+}
+
+func TestParseRFC3339FastPath(t *testing.T) {
+	var inputs []string
+	for _, tm := range rfc3339Times[:9] {
+		inputs = append(inputs, tm.Format(RFC3339), tm.Format(RFC3339Nano))
+	}
+	formatted := len(inputs)
+	// More than nine digits are truncated, and a comma works as well.
+	inputs = append(inputs, "2006-01-02T15:04:05.1234567891Z", "2006-01-02T15:04:05,123456789+01:00")
+	for i, s := range inputs {
+		got, err := Parse(RFC3339, s)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", s, err)
+			continue
+		}
+		want, err := ParseGeneral(RFC3339, s, UTC, Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, gotOffset := got.Zone()
+		_, wantOffset := want.Zone()
+		if !got.Equal(want) || got.Location().String() != want.Location().String() || gotOffset != wantOffset {
+			t.Errorf("Parse(%q) = %v, want %v", s, got, want)
+		}
+		// Round trip.
+		if i < formatted && got.Format(RFC3339Nano) != s && got.Format(RFC3339) != s {
+			t.Errorf("Parse(%q) does not round trip: %s", s, got.Format(RFC3339Nano))
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"2006-01-02",
+		"2006-01-02 15:04:05Z",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05z",
+		"2006-01-02T15:04:05+0700",
+		"2006-01-02T15:04:05+07:00Z",
+		"2006-01-02T15:04:05.Z",
+		"2006-01-0xT15:04:05Z",
+		"2006-13-02T15:04:05Z",
+		"2006-02-29T15:04:05Z",
+		"2006-02-30T15:04:05Z",
+		"2006-01-02T24:04:05Z",
+		"2006-01-02T15:60:05Z",
+		"2006-01-02T15:04:60Z",
+	} {
+		_, err := Parse(RFC3339, s)
+		_, wantErr := ParseGeneral(RFC3339, s, UTC, Local)
+		if err == nil || wantErr == nil || err.Error() != wantErr.Error() {
+			t.Errorf("Parse(%q): got error %v, want %v", s, err, wantErr)
+		}
+	}
+}
+
+// The benchmarks below compare the RFC 3339 fast path against the general
+// code that interprets the layout string.
+
+var benchmarkTime = Date(2006, 1, 2, 15, 4, 5, 123456789, FixedZone("", 3600))
+
+func BenchmarkAppendFormatRFC3339(b *testing.B) {
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = benchmarkTime.AppendFormat(buf[:0], RFC3339Nano)
+	}
+}
+
+func BenchmarkAppendFormatRFC3339General(b *testing.B) {
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = AppendFormatGeneral(benchmarkTime, buf[:0], RFC3339Nano)
+	}
+}
+
+func BenchmarkParseRFC3339(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Parse(RFC3339, "2006-01-02T15:04:05.123456789+01:00")
+	}
+}
+
+func BenchmarkParseRFC3339General(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseGeneral(RFC3339, "2006-01-02T15:04:05.123456789+01:00", UTC, Local)
+	}
+}
@@ -0,0 +1,116 @@
+// Package arena implements a bump allocator on top of a caller-provided
+// buffer, for scratch memory that is allocated in many small pieces and freed
+// all at once.
+//
+// Allocating from an arena never calls into the garbage collector: it only
+// moves an offset forward, so it takes a predictable amount of time and never
+// triggers a collection. Reset makes all memory available again in one go.
+//
+//	var scratch [4096]byte
+//	a := arena.New(scratch[:])
+//	for {
+//		p := arena.Alloc[point](a)
+//		coords := arena.MakeSlice[int32](a, 0, 64)
+//		...
+//		a.Reset()
+//	}
+//
+// The buffer is only a block of bytes to the garbage collector, which scans it
+// conservatively like any other memory. Values stored in the arena therefore
+// keep the heap objects they point to alive, until Reset clears the memory.
+// The other way around doesn't hold: the arena does not keep anything alive by
+// itself, so the buffer must not be freed while allocations are in use, and
+// pointers to arena memory must not be used after Reset.
+package arena
+
+import "unsafe"
+
+// Arena hands out memory from a buffer. Use New to create one.
+type Arena struct {
+	buf  []byte
+	used uintptr // number of bytes in buf that have been handed out
+}
+
+// New returns an arena that allocates from buf, which is cleared first. The
+// arena takes ownership of the buffer: it should not be used for anything else
+// while the arena is in use.
+func New(buf []byte) *Arena {
+	a := &Arena{buf: buf, used: uintptr(len(buf))}
+	a.Reset()
+	return a
+}
+
+// Raw returns a pointer to size bytes of zeroed memory, aligned to align bytes
+// which must be a power of two. It returns nil if there is not enough space
+// left in the arena.
+func (a *Arena) Raw(size, align uintptr) unsafe.Pointer {
+	if len(a.buf) == 0 {
+		return nil
+	}
+	base := unsafe.Pointer(&a.buf[0])
+	start := (uintptr(base) + a.used + align - 1) &^ (align - 1)
+	offset := start - uintptr(base)
+	if offset > uintptr(len(a.buf)) || size > uintptr(len(a.buf))-offset {
+		return nil
+	}
+	a.used = offset + size
+	// No need to clear the memory: New and Reset already did.
+	return unsafe.Add(base, offset)
+}
+
+// Alloc returns a pointer to a new zero value of type T in the arena. It
+// panics if there is not enough space left.
+func Alloc[T any](a *Arena) *T {
+	var zero T
+	p := a.Raw(unsafe.Sizeof(zero), unsafe.Alignof(zero))
+	if p == nil {
+		outOfMemory()
+	}
+	return (*T)(p)
+}
+
+// MakeSlice returns a slice with the given length and capacity backed by zeroed
+// memory in the arena, like make([]T, len, cap). It panics if there is not
+// enough space left, or if len is greater than cap. Appending to the slice
+// beyond its capacity allocates a new array on the heap, as usual.
+func MakeSlice[T any](a *Arena, len, cap int) []T {
+	if len < 0 || len > cap {
+		panic("arena: invalid slice length")
+	}
+	var zero T
+	size := unsafe.Sizeof(zero)
+	if size != 0 && uintptr(cap) > (^uintptr(0))/size {
+		outOfMemory()
+	}
+	p := a.Raw(size*uintptr(cap), unsafe.Alignof(zero))
+	if p == nil {
+		outOfMemory()
+	}
+	return unsafe.Slice((*T)(p), cap)[:len]
+}
+
+// Reset frees all memory allocated from the arena at once, so that it can be
+// allocated again. The memory is cleared, so the garbage collector doesn't see
+// stale pointers in it anymore.
+func (a *Arena) Reset() {
+	used := a.buf[:a.used]
+	for i := range used {
+		used[i] = 0
+	}
+	a.used = 0
+}
+
+// Len returns the number of bytes allocated from the arena, including padding
+// for alignment.
+func (a *Arena) Len() int {
+	return int(a.used)
+}
+
+// Cap returns the size of the buffer of the arena.
+func (a *Arena) Cap() int {
+	return len(a.buf)
+}
+
+func outOfMemory() {
+	panic("arena: out of memory")
+}
@@ -0,0 +1,108 @@
+package arena_test
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"tinygo/arena"
+)
+
+type node struct {
+	value int32
+	next  *node
+}
+
+func TestAlloc(t *testing.T) {
+	buf := make([]byte, 256)
+	for i := range buf {
+		buf[i] = 0xaa
+	}
+	a := arena.New(buf)
+
+	b := arena.Alloc[byte](a)
+	n := arena.Alloc[node](a)
+	if *b != 0 || n.value != 0 || n.next != nil {
+		t.Error("allocation is not zeroed")
+	}
+	if uintptr(unsafe.Pointer(n))%unsafe.Alignof(*n) != 0 {
+		t.Errorf("allocation %p is not aligned", n)
+	}
+	if uintptr(unsafe.Pointer(n)) <= uintptr(unsafe.Pointer(b)) {
+		t.Error("allocations overlap")
+	}
+
+	s := arena.MakeSlice[int16](a, 3, 10)
+	if len(s) != 3 || cap(s) != 10 {
+		t.Errorf("unexpected slice len/cap: %d/%d", len(s), cap(s))
+	}
+	if a.Len() > a.Cap() || a.Cap() != len(buf) {
+		t.Errorf("unexpected arena len/cap: %d/%d", a.Len(), a.Cap())
+	}
+}
+
+func TestOutOfMemory(t *testing.T) {
+	a := arena.New(make([]byte, 16))
+	if p := a.Raw(17, 1); p != nil {
+		t.Error("expected Raw to fail")
+	}
+	if p := a.Raw(16, 1); p == nil {
+		t.Error("expected Raw to succeed")
+	}
+	if p := a.Raw(1, 1); p != nil {
+		t.Error("expected Raw to fail on a full arena")
+	}
+
+	defer func() {
+		if r := recover(); r != "arena: out of memory" {
+			t.Errorf("unexpected panic: %v", r)
+		}
+	}()
+	arena.MakeSlice[byte](a, 1, 1)
+	t.Error("expected MakeSlice to panic")
+}
+
+func TestReset(t *testing.T) {
+	a := arena.New(make([]byte, 64))
+	first := arena.Alloc[node](a)
+	first.value = 5
+	first.next = first
+	a.Reset()
+	if a.Len() != 0 {
+		t.Errorf("expected empty arena after Reset, got %d bytes", a.Len())
+	}
+	second := arena.Alloc[node](a)
+	if second != first {
+		t.Error("expected memory to be reused after Reset")
+	}
+	if second.value != 0 || second.next != nil {
+		t.Error("memory was not cleared by Reset")
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	a := arena.New(make([]byte, 4096))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 100; i++ {
+		var list *node
+		for j := 0; j < 100; j++ {
+			n := arena.Alloc[node](a)
+			n.value = int32(j)
+			n.next = list
+			list = n
+		}
+		s := arena.MakeSlice[int32](a, 0, 64)
+		for n := list; n != nil && len(s) < cap(s); n = n.next {
+			s = append(s, n.value)
+		}
+		if list.value != 99 || s[63] != 36 {
+			t.Fatalf("unexpected values: %d, %d", list.value, s[63])
+		}
+		a.Reset()
+	}
+	runtime.ReadMemStats(&after)
+	if allocs := after.Mallocs - before.Mallocs; allocs != 0 {
+		t.Errorf("expected no allocations, got %d", allocs)
+	}
+}
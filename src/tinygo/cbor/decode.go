@@ -0,0 +1,675 @@
+package cbor
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// maxDepth is the maximum nesting depth of arrays, maps and tags that is
+// decoded, to bound the stack usage on small devices.
+const maxDepth = 32
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// The argument to Unmarshal must be a non-nil pointer.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "cbor: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return "cbor: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "cbor: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnmarshalTypeError describes a CBOR value that was not appropriate for a
+// value of a specific Go type.
+type UnmarshalTypeError struct {
+	Value  string       // description of the CBOR value, like "text string"
+	Type   reflect.Type // type of the Go value it could not be assigned to
+	Offset int          // offset of the data item in the input
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "cbor: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+// A SyntaxError describes malformed CBOR data.
+type SyntaxError struct {
+	msg    string
+	Offset int // offset in the input where the error was detected
+}
+
+func (e *SyntaxError) Error() string {
+	return "cbor: " + e.msg
+}
+
+// Unmarshal parses the CBOR-encoded data and stores the result in the value
+// pointed to by v. The data must contain exactly one data item.
+//
+// Unmarshal uses the inverse of the encodings that Marshal uses, allocating
+// maps, slices, and pointers as necessary. Map keys are matched to struct
+// fields by name, preferring an exact match but also accepting a case
+// insensitive match. Unknown keys are ignored. Integers can be decoded into
+// integer and float types as long as they don't overflow, and null leaves
+// values other than pointers, interfaces, maps and slices unchanged. Tags are
+// skipped, so that the tagged data item is decoded as is.
+//
+// To decode into an empty interface value, Unmarshal stores one of these:
+//
+//	bool, for booleans
+//	uint64, for positive integers
+//	int64, for negative integers
+//	float64, for floats
+//	string, for text strings
+//	[]byte, for byte strings
+//	[]interface{}, for arrays
+//	map[interface{}]interface{}, for maps
+//	nil, for null and undefined
+//
+// Only the empty interface type is supported as an interface type.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := decoder{data: data}
+	if err := d.value(rv.Elem(), 0); err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return &SyntaxError{"unexpected data after top-level value", d.off}
+	}
+	return nil
+}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// decoder holds the input and the current read offset.
+type decoder struct {
+	data []byte
+	off  int
+}
+
+// head reads the initial bytes of a data item. It returns the major type, the
+// additional information in the lower five bits of the initial byte and the
+// argument. For indefinite-length items, the argument is zero.
+func (d *decoder) head() (major, info byte, arg uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, d.errEOF()
+	}
+	b := d.data[d.off]
+	major = b & 0xe0
+	info = b & 0x1f
+	d.off++
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	case info == 31 && major != majorUint && major != majorNegInt && major != majorTag:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, &SyntaxError{"invalid additional information", d.off - 1}
+	}
+	if len(d.data)-d.off < size {
+		return 0, 0, 0, d.errEOF()
+	}
+	for _, c := range d.data[d.off : d.off+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.off += size
+	return major, info, arg, nil
+}
+
+func (d *decoder) errEOF() error {
+	return &SyntaxError{"unexpected end of input", d.off}
+}
+
+// atBreak consumes a break byte, which ends an indefinite-length item, and
+// returns whether there was one.
+func (d *decoder) atBreak() bool {
+	if d.off < len(d.data) && d.data[d.off] == simpleBreak {
+		d.off++
+		return true
+	}
+	return false
+}
+
+// checkLen returns an error if a definite-length array or map with n data
+// items can't possibly fit in the remaining input. This prevents excessive
+// allocations when reading invalid input.
+func (d *decoder) checkLen(n uint64) error {
+	if n > uint64(len(d.data)-d.off) {
+		return d.errEOF()
+	}
+	return nil
+}
+
+// str reads the content of a definite or indefinite-length byte or text
+// string. The returned slice may refer to the input data.
+func (d *decoder) str(major, info byte, n uint64) ([]byte, error) {
+	if info != 31 {
+		if n > uint64(len(d.data)-d.off) {
+			return nil, d.errEOF()
+		}
+		b := d.data[d.off : d.off+int(n)]
+		d.off += int(n)
+		return b, nil
+	}
+	// Indefinite-length string: a sequence of definite-length chunks of the
+	// same major type.
+	var buf []byte
+	for !d.atBreak() {
+		start := d.off
+		chunkMajor, chunkInfo, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, &SyntaxError{"invalid indefinite-length string chunk", start}
+		}
+		chunk, err := d.str(chunkMajor, chunkInfo, n)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+	}
+	if buf == nil {
+		buf = []byte{}
+	}
+	return buf, nil
+}
+
+// float decodes the argument of a float data item.
+func float(info byte, arg uint64) float64 {
+	switch info {
+	case 25:
+		return float16ToFloat64(uint16(arg))
+	case 26:
+		return float64(math.Float32frombits(uint32(arg)))
+	default:
+		return math.Float64frombits(arg)
+	}
+}
+
+// float16ToFloat64 converts an IEEE 754 binary16 value to a float64.
+func float16ToFloat64(half uint16) float64 {
+	exp := int(half>>10) & 0x1f
+	mant := float64(half & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if half&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// description returns a description of a data item, for use in errors.
+func description(major, info byte) string {
+	switch major {
+	case majorUint:
+		return "positive integer"
+	case majorNegInt:
+		return "negative integer"
+	case majorBytes:
+		return "byte string"
+	case majorText:
+		return "text string"
+	case majorArray:
+		return "array"
+	case majorMap:
+		return "map"
+	}
+	switch major | info {
+	case simpleFalse, simpleTrue:
+		return "boolean"
+	case simpleFloat16, simpleFloat32, simpleFloat64:
+		return "float"
+	}
+	return "simple value"
+}
+
+// value decodes the next data item into v, which must be settable.
+func (d *decoder) value(v reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return &SyntaxError{"exceeded max nesting depth", d.off}
+	}
+	start := d.off
+	major, info, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+	if major == majorTag {
+		return d.value(v, depth+1)
+	}
+	kind := v.Kind()
+
+	// Handle null and undefined, and allocate pointers as needed.
+	if major == majorSimple && (info == 22 || info == 23) {
+		switch kind {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	if kind == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.off = start
+		return d.value(v.Elem(), depth+1)
+	}
+	if kind == reflect.Interface {
+		if v.Type() != interfaceType {
+			return typeError(major, info, v, start)
+		}
+		d.off = start
+		x, err := d.any(depth)
+		if err != nil {
+			return err
+		}
+		*v.Addr().Interface().(*interface{}) = x
+		return nil
+	}
+
+	switch major {
+	case majorUint, majorNegInt:
+		if !setInt(v, major == majorNegInt, arg) {
+			return typeError(major, info, v, start)
+		}
+		return nil
+	case majorBytes:
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return err
+		}
+		switch {
+		case kind == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(append([]byte{}, b...))
+		case kind == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+			n := v.Len()
+			for i := 0; i < n; i++ {
+				var c byte
+				if i < len(b) {
+					c = b[i]
+				}
+				v.Index(i).SetUint(uint64(c))
+			}
+		default:
+			return typeError(major, info, v, start)
+		}
+		return nil
+	case majorText:
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return err
+		}
+		if kind != reflect.String {
+			return typeError(major, info, v, start)
+		}
+		v.SetString(string(b))
+		return nil
+	case majorArray:
+		switch kind {
+		case reflect.Slice:
+			return d.slice(v, info, arg, depth)
+		case reflect.Array:
+			return d.array(v, info, arg, depth)
+		}
+		return typeError(major, info, v, start)
+	case majorMap:
+		switch kind {
+		case reflect.Map:
+			return d.mapValue(v, info, arg, depth)
+		case reflect.Struct:
+			return d.structValue(v, info, arg, depth)
+		}
+		return typeError(major, info, v, start)
+	default: // majorSimple
+		switch info {
+		case 20, 21:
+			if kind != reflect.Bool {
+				return typeError(major, info, v, start)
+			}
+			v.SetBool(info == 21)
+			return nil
+		case 25, 26, 27:
+			if kind != reflect.Float32 && kind != reflect.Float64 {
+				return typeError(major, info, v, start)
+			}
+			v.SetFloat(float(info, arg))
+			return nil
+		case 31:
+			return &SyntaxError{"unexpected break", start}
+		}
+		return typeError(major, info, v, start)
+	}
+}
+
+// typeError returns an error for a data item that can't be stored in v.
+func typeError(major, info byte, v reflect.Value, offset int) error {
+	return &UnmarshalTypeError{description(major, info), v.Type(), offset}
+}
+
+// setInt stores the integer with the given argument in v, which may be an
+// integer or a float. It returns false if the integer can't be stored in v.
+func setInt(v reflect.Value, negative bool, arg uint64) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Check that the value fits in v: a positive value must be at most
+		// max, and a negative value -1-arg must be at least -1-max.
+		max := uint64(1)<<(v.Type().Bits()-1) - 1
+		if arg > max {
+			return false
+		}
+		if negative {
+			v.SetInt(-1 - int64(arg))
+		} else {
+			v.SetInt(int64(arg))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := v.Type().Bits()
+		if negative || bits < 64 && arg>>bits != 0 {
+			return false
+		}
+		v.SetUint(arg)
+	case reflect.Float32, reflect.Float64:
+		if negative {
+			v.SetFloat(-1 - float64(arg))
+		} else {
+			v.SetFloat(float64(arg))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// slice decodes the elements of an array into the slice v.
+func (d *decoder) slice(v reflect.Value, info byte, n uint64, depth int) error {
+	if info == 31 {
+		s := reflect.MakeSlice(v.Type(), 0, 0)
+		for !d.atBreak() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem, depth+1); err != nil {
+				return err
+			}
+			s = reflect.Append(s, elem)
+		}
+		v.Set(s)
+		return nil
+	}
+	if err := d.checkLen(n); err != nil {
+		return err
+	}
+	s := reflect.MakeSlice(v.Type(), int(n), int(n))
+	for i := 0; i < int(n); i++ {
+		if err := d.value(s.Index(i), depth+1); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+// array decodes the elements of an array into the Go array v. Extra elements
+// are skipped, and missing elements are set to the zero value.
+func (d *decoder) array(v reflect.Value, info byte, n uint64, depth int) error {
+	length := v.Len()
+	i := 0
+	for ; info == 31 && !d.atBreak() || info != 31 && uint64(i) < n; i++ {
+		var err error
+		if i < length {
+			err = d.value(v.Index(i), depth+1)
+		} else {
+			err = d.skip(depth + 1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if i < length {
+		zero := reflect.Zero(v.Type().Elem())
+		for ; i < length; i++ {
+			v.Index(i).Set(zero)
+		}
+	}
+	return nil
+}
+
+// mapValue decodes the entries of a map into the Go map v.
+func (d *decoder) mapValue(v reflect.Value, info byte, n uint64, depth int) error {
+	t := v.Type()
+	if v.IsNil() {
+		if info != 31 {
+			if err := d.checkLen(n); err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.MakeMapWithSize(t, int(n)))
+	}
+	for i := uint64(0); info == 31 && !d.atBreak() || info != 31 && i < n; i++ {
+		key := reflect.New(t.Key()).Elem()
+		if err := d.value(key, depth+1); err != nil {
+			return err
+		}
+		if err := checkHashable(key, d.off); err != nil {
+			return err
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.value(elem, depth+1); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+// structValue decodes the entries of a map into the fields of the struct v.
+func (d *decoder) structValue(v reflect.Value, info byte, n uint64, depth int) error {
+	for i := uint64(0); info == 31 && !d.atBreak() || info != 31 && i < n; i++ {
+		start := d.off
+		major, keyInfo, arg, err := d.head()
+		if err != nil {
+			return err
+		}
+		if major != majorText {
+			// Only text keys are used as field names.
+			d.off = start
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+			continue
+		}
+		key, err := d.str(major, keyInfo, arg)
+		if err != nil {
+			return err
+		}
+		field, ok := findField(v, string(key))
+		if !ok {
+			err = d.skip(depth + 1)
+		} else {
+			err = d.value(field, depth+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findField returns the field of the struct v with the given CBOR name,
+// preferring an exact match over a case insensitive match. Fields of embedded
+// structs are included, allocating embedded pointers as needed.
+func findField(v reflect.Value, name string) (reflect.Value, bool) {
+	if field, ok := lookupField(v, name, false); ok {
+		return field, true
+	}
+	return lookupField(v, name, true)
+}
+
+func lookupField(v reflect.Value, name string, foldCase bool) (reflect.Value, bool) {
+	t := v.Type()
+	numField := t.NumField()
+	for i := 0; i < numField; i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("cbor")
+		if tag == "-" {
+			continue
+		}
+		fieldName, _ := parseTag(tag)
+		if field.Anonymous && fieldName == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				embedded := v.Field(i)
+				if embedded.Kind() == reflect.Ptr {
+					if embedded.IsNil() {
+						if !embedded.CanSet() {
+							continue
+						}
+						embedded.Set(reflect.New(embeddedType))
+					}
+					embedded = embedded.Elem()
+				}
+				if f, ok := lookupField(embedded, name, foldCase); ok {
+					return f, true
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		if fieldName == name || foldCase && strings.EqualFold(fieldName, name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// checkHashable returns an error if key is an interface value that can't be
+// used as a map key.
+func checkHashable(key reflect.Value, offset int) error {
+	if key.Kind() != reflect.Interface || key.IsNil() {
+		return nil
+	}
+	switch kind := key.Elem().Kind(); kind {
+	case reflect.Slice, reflect.Map:
+		return &SyntaxError{"unhashable map key of type " + kind.String(), offset}
+	}
+	return nil
+}
+
+// any decodes the next data item into one of the types that are stored in an
+// empty interface value.
+func (d *decoder) any(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, &SyntaxError{"exceeded max nesting depth", d.off}
+	}
+	start := d.off
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		return arg, nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, &UnmarshalTypeError{"negative integer", reflect.TypeOf(int64(0)), start}
+		}
+		return -1 - int64(arg), nil
+	case majorBytes:
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case majorText:
+		b, err := d.str(major, info, arg)
+		return string(b), err
+	case majorArray:
+		var s []interface{}
+		d.off = start
+		err := d.value(reflect.ValueOf(&s).Elem(), depth)
+		return s, err
+	case majorMap:
+		var m map[interface{}]interface{}
+		d.off = start
+		err := d.value(reflect.ValueOf(&m).Elem(), depth)
+		return m, err
+	case majorTag:
+		return d.any(depth + 1)
+	}
+	switch info {
+	case 20, 21:
+		return info == 21, nil
+	case 22, 23:
+		return nil, nil
+	case 25, 26, 27:
+		return float(info, arg), nil
+	case 31:
+		return nil, &SyntaxError{"unexpected break", start}
+	}
+	return nil, &SyntaxError{"unsupported simple value", start}
+}
+
+// skip skips over the next data item.
+func (d *decoder) skip(depth int) error {
+	if depth > maxDepth {
+		return &SyntaxError{"exceeded max nesting depth", d.off}
+	}
+	start := d.off
+	major, info, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case majorBytes, majorText:
+		_, err := d.str(major, info, arg)
+		return err
+	case majorArray, majorMap:
+		if major == majorMap {
+			arg *= 2
+		}
+		for i := uint64(0); info == 31 && !d.atBreak() || info != 31 && i < arg; i++ {
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case majorTag:
+		return d.skip(depth + 1)
+	case majorSimple:
+		if info == 31 {
+			return &SyntaxError{"unexpected break", start}
+		}
+	}
+	return nil
+}
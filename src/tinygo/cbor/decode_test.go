@@ -0,0 +1,220 @@
+package cbor_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"tinygo/cbor"
+)
+
+// decodeVectors are the examples from RFC 8949, Appendix A, with the value
+// they are expected to decode to when decoding into an empty interface.
+var decodeVectors = []struct {
+	hex   string
+	value interface{}
+}{
+	{"00", uint64(0)},
+	{"01", uint64(1)},
+	{"0a", uint64(10)},
+	{"17", uint64(23)},
+	{"1818", uint64(24)},
+	{"1819", uint64(25)},
+	{"1864", uint64(100)},
+	{"1903e8", uint64(1000)},
+	{"1a000f4240", uint64(1000000)},
+	{"1b000000e8d4a51000", uint64(1000000000000)},
+	{"1bffffffffffffffff", uint64(18446744073709551615)},
+	{"20", int64(-1)},
+	{"29", int64(-10)},
+	{"3863", int64(-100)},
+	{"3903e7", int64(-1000)},
+	{"f90000", 0.0},
+	{"f98000", math.Copysign(0, -1)},
+	{"f93c00", 1.0},
+	{"fb3ff199999999999a", 1.1},
+	{"f93e00", 1.5},
+	{"f97bff", 65504.0},
+	{"fa47c35000", 100000.0},
+	{"fa7f7fffff", 3.4028234663852886e+38},
+	{"fb7e37e43c8800759c", 1.0e+300},
+	{"f90001", 5.960464477539063e-8},
+	{"f90400", 0.00006103515625},
+	{"f9c400", -4.0},
+	{"fbc010666666666666", -4.1},
+	{"f97c00", math.Inf(1)},
+	{"f9fc00", math.Inf(-1)},
+	{"fa7f800000", math.Inf(1)},
+	{"faff800000", math.Inf(-1)},
+	{"fb7ff0000000000000", math.Inf(1)},
+	{"fbfff0000000000000", math.Inf(-1)},
+	{"f4", false},
+	{"f5", true},
+	{"f6", nil},
+	{"f7", nil},
+	{"c074323031332d30332d32315432303a30343a30305a", "2013-03-21T20:04:00Z"},
+	{"c11a514b67b0", uint64(1363896240)},
+	{"c1fb41d452d9ec200000", 1363896240.5},
+	{"d74401020304", []byte{1, 2, 3, 4}},
+	{"d818456449455446", []byte("dIETF")},
+	{"40", []byte{}},
+	{"4401020304", []byte{1, 2, 3, 4}},
+	{"60", ""},
+	{"6161", "a"},
+	{"6449455446", "IETF"},
+	{"62225c", "\"\\"},
+	{"62c3bc", "ü"},
+	{"63e6b0b4", "水"},
+	{"64f0908591", "\U00010151"},
+	{"80", []interface{}{}},
+	{"83010203", []interface{}{uint64(1), uint64(2), uint64(3)}},
+	{"8301820203820405", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+	{"98190102030405060708090a0b0c0d0e0f101112131415161718181819", numbers(1, 25)},
+	{"a0", map[interface{}]interface{}{}},
+	{"a201020304", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): uint64(4)}},
+	{"a26161016162820203", map[interface{}]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+	{"826161a161626163", []interface{}{"a", map[interface{}]interface{}{"b": "c"}}},
+	{"a56161614161626142616361436164614461656145", map[interface{}]interface{}{"a": "A", "b": "B", "c": "C", "d": "D", "e": "E"}},
+	{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+	{"7f657374726561646d696e67ff", "streaming"},
+	{"9fff", []interface{}{}},
+	{"9f018202039f0405ffff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+	{"9f01820203820405ff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+	{"83018202039f0405ff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+	{"83019f0203ff820405", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+	{"9f0102030405060708090a0b0c0d0e0f101112131415161718181819ff", numbers(1, 25)},
+	{"bf61610161629f0203ffff", map[interface{}]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+	{"826161bf61626163ff", []interface{}{"a", map[interface{}]interface{}{"b": "c"}}},
+	{"bf6346756ef563416d7421ff", map[interface{}]interface{}{"Fun": true, "Amt": int64(-2)}},
+}
+
+// numbers returns the numbers from start to end (inclusive) as a slice.
+func numbers(start, end uint64) []interface{} {
+	var s []interface{}
+	for i := start; i <= end; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
+func TestUnmarshalVectors(t *testing.T) {
+	for _, tc := range decodeVectors {
+		data, err := hex.DecodeString(tc.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v interface{}
+		if err := cbor.Unmarshal(data, &v); err != nil {
+			t.Errorf("failed to decode %s: %v", tc.hex, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.value) {
+			t.Errorf("unexpected value for %s\nexpected: %#v\nactual:   %#v", tc.hex, tc.value, v)
+		}
+		if f, ok := v.(float64); ok && math.Signbit(f) != math.Signbit(tc.value.(float64)) {
+			t.Errorf("unexpected sign for %s: %v", tc.hex, f)
+		}
+	}
+
+	// NaN is never equal to itself, so check it separately.
+	for _, s := range []string{"f97e00", "fa7fc00000", "fb7ff8000000000000"} {
+		data, _ := hex.DecodeString(s)
+		var f float64
+		if err := cbor.Unmarshal(data, &f); err != nil || !math.IsNaN(f) {
+			t.Errorf("failed to decode %s as NaN: %v, %v", s, f, err)
+		}
+	}
+}
+
+type measurement struct {
+	Name   string
+	Values []int16  `cbor:"values"`
+	Unit   *string  `cbor:"unit"`
+	Flags  [2]bool  `cbor:"flags"`
+	Scale  float32  `cbor:"scale"`
+	Labels []string `cbor:"-"`
+}
+
+func TestUnmarshalTyped(t *testing.T) {
+	// {"name": "t", "values": [_ 1, -2], "UNIT": "C", "flags": [true, false,
+	// true], "scale": 2, "other": {1: [2]}, "-": "x"}
+	data, _ := hex.DecodeString("a7646e616d6561746676616c7565739f0121ff64554e4954614365666c61677383f5f4f5657363616c6502656f74686572a1018102612d6178")
+	var m measurement
+	m.Labels = []string{"keep"}
+	if err := cbor.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "t" || len(m.Values) != 2 || m.Values[0] != 1 || m.Values[1] != -2 ||
+		m.Unit == nil || *m.Unit != "C" || m.Flags != [2]bool{true, false} || m.Scale != 2 ||
+		len(m.Labels) != 1 {
+		t.Errorf("unexpected value: %+v", m)
+	}
+
+	// Null resets pointers and slices.
+	data, _ = hex.DecodeString("a264756e6974f66676616c756573f6")
+	if err := cbor.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Unit != nil || m.Values != nil || m.Name != "t" {
+		t.Errorf("unexpected value after decoding null: %+v", m)
+	}
+
+	var strMap map[string][]byte
+	data, _ = hex.DecodeString("a26161420102616240")
+	if err := cbor.Unmarshal(data, &strMap); err != nil {
+		t.Fatal(err)
+	}
+	if len(strMap) != 2 || string(strMap["a"]) != "\x01\x02" || strMap["b"] == nil {
+		t.Errorf("unexpected map: %v", strMap)
+	}
+
+	var f float64
+	if err := cbor.Unmarshal([]byte{0x38, 0x63}, &f); err != nil || f != -100 {
+		t.Errorf("unexpected float value: %v, %v", f, err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var i8 int8
+	var u uint
+	var s string
+	var v interface{}
+	tests := []struct {
+		hex   string
+		value interface{}
+	}{
+		{"1880", &i8},              // 128 overflows int8
+		{"3880", &i8},              // -129 overflows int8
+		{"20", &u},                 // negative into unsigned
+		{"6161", &i8},              // text into integer
+		{"01", &s},                 // integer into string
+		{"1a0001", &u},             // truncated argument
+		{"62c3", &s},               // truncated string
+		{"8301", &v},               // truncated array
+		{"0101", &v},               // trailing data
+		{"1c", &v},                 // reserved additional information
+		{"ff", &v},                 // unexpected break
+		{"5f6161ff", &v},           // text chunk in a byte string
+		{"3bffffffffffffffff", &v}, // overflows int64
+		{"9bffffffffffffffff", &v}, // impossible array length
+		{"a18080", &v},             // unhashable map key
+		{"818181818181818181818181818181818181818181818181818181818181818181", &v}, // nested too deeply
+	}
+	for _, tc := range tests {
+		data, _ := hex.DecodeString(tc.hex)
+		if err := cbor.Unmarshal(data, tc.value); err == nil {
+			t.Errorf("expected an error when decoding %s into %T", tc.hex, tc.value)
+		}
+	}
+
+	err := cbor.Unmarshal([]byte{0x61, 0x61}, &i8)
+	var typeErr *cbor.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || err.Error() != "cbor: cannot unmarshal text string into Go value of type int8" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := cbor.Unmarshal([]byte{0}, i8); err == nil || err.Error() != "cbor: Unmarshal(non-pointer int8)" {
+		t.Errorf("unexpected error for a non-pointer: %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+// Package cbor implements a compact encoder and decoder for CBOR, the Concise
+// Binary Object Representation defined in RFC 8949.
+//
+// CBOR is a binary format with the same data model as JSON, but its encoding
+// is a lot smaller and easier to parse, which makes it a good fit for
+// messaging on constrained devices. Like tinygo/json, this package doesn't
+// keep any encoder state: the struct layout (field names, tags, offsets) is
+// read directly from the type information emitted by the compiler, and when
+// the buffer passed to MarshalAppend is reused and has enough capacity,
+// encoding a value does not allocate at all.
+//
+// Supported are booleans, integers, floats, strings, byte slices and arrays,
+// structs, arrays, slices, maps, pointers and interfaces containing one of
+// these. Channels, functions and complex numbers are not supported.
+//
+// Values are encoded as follows:
+//
+//   - Integers use the smallest possible encoding.
+//   - Floats use the shortest of float16, float32 and float64 that represents
+//     the value exactly.
+//   - Strings are encoded as text strings, byte slices and byte arrays as
+//     byte strings.
+//   - Structs are encoded as maps with text keys, using the same rules for
+//     field names as encoding/json, but with the "cbor" struct tag. The
+//     "omitempty" option and the "-" name are supported. The fields of
+//     embedded structs are included in the parent map.
+//   - Nil pointers, interfaces, slices and maps are encoded as null.
+//
+// Map entries are encoded in iteration order, so the encoding of a map with
+// more than one entry is not deterministic.
+package cbor
+
+import (
+	"math"
+	"reflect"
+)
+
+// The CBOR major types, stored in the upper three bits of the initial byte of
+// a data item.
+const (
+	majorUint   = 0 << 5
+	majorNegInt = 1 << 5
+	majorBytes  = 2 << 5
+	majorText   = 3 << 5
+	majorArray  = 4 << 5
+	majorMap    = 5 << 5
+	majorTag    = 6 << 5
+	majorSimple = 7 << 5
+)
+
+// Simple values and floats, with major type 7.
+const (
+	simpleFalse     = majorSimple | 20
+	simpleTrue      = majorSimple | 21
+	simpleNull      = majorSimple | 22
+	simpleUndefined = majorSimple | 23
+	simpleFloat16   = majorSimple | 25
+	simpleFloat32   = majorSimple | 26
+	simpleFloat64   = majorSimple | 27
+	simpleBreak     = majorSimple | 31
+)
+
+// An UnsupportedTypeError is returned by MarshalAppend when attempting to
+// encode an unsupported value type.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "cbor: unsupported type: " + e.Type.String()
+}
+
+// Marshal returns the CBOR encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	return MarshalAppend(nil, v)
+}
+
+// MarshalAppend appends the CBOR encoding of v to buf and returns the extended
+// buffer. To avoid allocating, pass a pointer to the value to encode and reuse
+// the returned buffer (truncated to zero length) in the next call.
+//
+// On error, the returned buffer may contain a partial encoding.
+func MarshalAppend(buf []byte, v interface{}) ([]byte, error) {
+	return appendValue(buf, reflect.ValueOf(v))
+}
+
+// appendValue appends the CBOR encoding of v to buf.
+func appendValue(buf []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(buf, simpleNull), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, simpleTrue), nil
+		}
+		return append(buf, simpleFalse), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x := v.Int()
+		if x < 0 {
+			// Negative integers are encoded as -1-x.
+			return appendHead(buf, majorNegInt, ^uint64(x)), nil
+		}
+		return appendHead(buf, majorUint, uint64(x)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendHead(buf, majorUint, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return appendFloat(buf, v.Float()), nil
+	case reflect.String:
+		s := v.String()
+		buf = appendHead(buf, majorText, uint64(len(s)))
+		return append(buf, s...), nil
+	case reflect.Struct:
+		buf, n, _ := appendFields(buf, v, false, 0)
+		buf = appendHead(buf, majorMap, uint64(n))
+		buf, _, err := appendFields(buf, v, true, 0)
+		return buf, err
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(buf, simpleNull), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := v.Bytes()
+			buf = appendHead(buf, majorBytes, uint64(len(b)))
+			return append(buf, b...), nil
+		}
+		return appendArray(buf, v)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			n := v.Len()
+			buf = appendHead(buf, majorBytes, uint64(n))
+			for i := 0; i < n; i++ {
+				buf = append(buf, uint8(v.Index(i).Uint()))
+			}
+			return buf, nil
+		}
+		return appendArray(buf, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return append(buf, simpleNull), nil
+		}
+		buf = appendHead(buf, majorMap, uint64(v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			var err error
+			buf, err = appendValue(buf, iter.Key())
+			if err != nil {
+				return buf, err
+			}
+			buf, err = appendValue(buf, iter.Value())
+			if err != nil {
+				return buf, err
+			}
+		}
+		return buf, nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return append(buf, simpleNull), nil
+		}
+		return appendValue(buf, v.Elem())
+	default:
+		return buf, &UnsupportedTypeError{v.Type()}
+	}
+}
+
+// appendHead appends the initial bytes of a data item with the given major
+// type and argument, using the shortest possible encoding.
+func appendHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(buf, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(buf, major|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// appendFields appends the exported fields of the struct v as map entries with
+// the field name as key. The fields of embedded structs are included in the
+// parent map. When encode is false, nothing is appended and only the number of
+// map entries is counted; this is used to encode the map length, which
+// precedes the entries. It returns the number of entries plus n.
+func appendFields(buf []byte, v reflect.Value, encode bool, n int) ([]byte, int, error) {
+	t := v.Type()
+	numField := t.NumField()
+	for i := 0; i < numField; i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("cbor")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+		fieldValue := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			// Embedded struct (or pointer to struct) without a name in the tag:
+			// include the fields in the parent map.
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				var err error
+				buf, n, err = appendFields(buf, embedded, encode, n)
+				if err != nil {
+					return buf, n, err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		if hasOption(opts, "omitempty") && isEmptyValue(fieldValue) {
+			continue
+		}
+		n++
+		if !encode {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		buf = appendHead(buf, majorText, uint64(len(name)))
+		buf = append(buf, name...)
+		var err error
+		buf, err = appendValue(buf, fieldValue)
+		if err != nil {
+			return buf, n, err
+		}
+	}
+	return buf, n, nil
+}
+
+// appendArray appends the elements of the array or slice v as a CBOR array.
+func appendArray(buf []byte, v reflect.Value) ([]byte, error) {
+	n := v.Len()
+	buf = appendHead(buf, majorArray, uint64(n))
+	for i := 0; i < n; i++ {
+		var err error
+		buf, err = appendValue(buf, v.Index(i))
+		if err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+// appendFloat appends f using the shortest float encoding that doesn't lose
+// precision. All NaNs are encoded as the same quiet NaN.
+func appendFloat(buf []byte, f float64) []byte {
+	if f != f {
+		return append(buf, simpleFloat16, 0x7e, 0x00)
+	}
+	f32 := float32(f)
+	if float64(f32) != f {
+		bits := math.Float64bits(f)
+		return append(buf, simpleFloat64, byte(bits>>56), byte(bits>>48), byte(bits>>40), byte(bits>>32),
+			byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+	}
+	bits := math.Float32bits(f32)
+	if half, ok := float32ToFloat16(bits); ok {
+		return append(buf, simpleFloat16, byte(half>>8), byte(half))
+	}
+	return append(buf, simpleFloat32, byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+}
+
+// float32ToFloat16 converts the bits of a (non-NaN) float32 to a float16
+// (IEEE 754 binary16). The returned bool is false if the value can't be
+// represented exactly.
+func float32ToFloat16(bits uint32) (uint16, bool) {
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+	switch {
+	case exp == 0 && mant == 0:
+		// Positive or negative zero.
+		return sign, true
+	case exp == 0xff:
+		// Infinity (NaNs are handled by the caller).
+		return sign | 0x7c00, mant == 0
+	}
+	exp -= 127
+	switch {
+	case exp >= -14 && exp <= 15:
+		// Normal float16.
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>13), true
+	case exp >= -24 && exp < -14:
+		// Subnormal float16, with a value of m * 2^-24.
+		mant |= 0x800000
+		shift := uint(-exp - 1)
+		if mant&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(mant>>shift), true
+	}
+	return 0, false
+}
+
+// isEmptyValue returns whether v is empty for the purpose of the omitempty
+// option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// parseTag splits a struct field's cbor tag into its name and the
+// comma-separated options.
+func parseTag(tag string) (name, opts string) {
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			return tag[:i], tag[i+1:]
+		}
+	}
+	return tag, ""
+}
+
+// hasOption returns whether the comma-separated list of options contains the
+// given option.
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts = parseTag(opts)
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package cbor_test
+
+import (
+	"encoding/hex"
+	stdjson "encoding/json"
+	"math"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"tinygo/cbor"
+)
+
+type position struct {
+	Lat, Lon float64
+}
+
+type Header struct {
+	Version uint8  `cbor:"v"`
+	Device  string `cbor:"device"`
+}
+
+type telemetry struct {
+	Header
+	Sequence    uint32            `cbor:"seq"`
+	Temperature float32           `cbor:"temp"`
+	Humidity    float64           `cbor:"humidity,omitempty"`
+	Offset      int32             `cbor:"offset"`
+	Online      bool              `cbor:"online"`
+	Position    position          `cbor:"pos"`
+	Previous    *position         `cbor:"prev"`
+	Readings    [3]int16          `cbor:"readings"`
+	Tags        []string          `cbor:"tags"`
+	Counters    map[string]uint16 `cbor:"counters"`
+	Raw         []byte            `cbor:"raw"`
+	ID          [4]byte           `cbor:"id"`
+	Note        string            `cbor:"note,omitempty"`
+	Extra       interface{}       `cbor:"extra"`
+	Ignored     int               `cbor:"-"`
+	internal    int
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		Header:      Header{Version: 2, Device: "sensor-1"},
+		Sequence:    12345,
+		Temperature: 21.5,
+		Offset:      -300,
+		Online:      true,
+		Position:    position{Lat: 52.0907, Lon: 5.1214},
+		Previous:    &position{Lat: 52.09, Lon: 5.12},
+		Readings:    [3]int16{-1, 0, 1000},
+		Tags:        []string{"a", "b"},
+		Counters:    map[string]uint16{"rx": 10, "tx": 300},
+		Raw:         []byte{0, 1, 2, 3, 4, 250},
+		ID:          [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Extra:       "x",
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := newTelemetry()
+	data, err := cbor.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out telemetry
+	if err := cbor.Unmarshal(data, &out); err != nil {
+		t.Fatalf("could not decode %x: %v", data, err)
+	}
+	if !reflect.DeepEqual(&out, in) {
+		t.Errorf("round trip through %x:\ngot:  %+v\nwant: %+v", data, out, *in)
+	}
+
+	jsonData, err := stdjson.Marshal(in)
+	if err != nil {
+		t.Fatal("encoding/json:", err)
+	}
+	if len(data) >= len(jsonData) {
+		t.Errorf("CBOR encoding is %d bytes, not smaller than the %d bytes of JSON", len(data), len(jsonData))
+	}
+}
+
+func TestMarshalAppend(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		hex   string
+	}{
+		{0, "00"},
+		{uint8(23), "17"},
+		{int16(24), "1818"},
+		{1000, "1903e8"},
+		{uint32(1000000), "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{int8(-100), "3863"},
+		{-1000, "3903e7"},
+		{int64(math.MinInt64), "3b7fffffffffffffff"},
+		{0.0, "f90000"},
+		{math.Copysign(0, -1), "f98000"},
+		{1.5, "f93e00"},
+		{float32(65504), "f97bff"},
+		{100000.0, "fa47c35000"},
+		{math.MaxFloat32, "fa7f7fffff"},
+		{1.1, "fb3ff199999999999a"},
+		{float32(1.1), "fa3f8ccccd"},
+		{1.0e+300, "fb7e37e43c8800759c"},
+		{5.960464477539063e-8, "f90001"},
+		{0.00006103515625, "f90400"},
+		{-4.0, "f9c400"},
+		{math.Inf(1), "f97c00"},
+		{math.Inf(-1), "f9fc00"},
+		{math.NaN(), "f97e00"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"", "60"},
+		{"IETF", "6449455446"},
+		{"水", "63e6b0b4"},
+		{[]byte{}, "40"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[2]byte{1, 2}, "420102"},
+		{[]int{}, "80"},
+		{[]int(nil), "f6"},
+		{[]interface{}{1, []int{2, 3}, [2]uint{4, 5}}, "8301820203820405"},
+		{map[int]int{1: 2}, "a10102"},
+		{map[string]int(nil), "f6"},
+		{(*position)(nil), "f6"},
+		{&struct{ A, B int }{1, -2}, "a2614101614221"},
+		{struct {
+			A string `cbor:"a,omitempty"`
+			B []int  `cbor:"b,omitempty"`
+			C bool   `cbor:",omitempty"`
+			d int
+		}{}, "a0"},
+	}
+	for _, tc := range tests {
+		data, err := cbor.MarshalAppend(nil, tc.value)
+		if err != nil {
+			t.Errorf("failed to encode %#v: %v", tc.value, err)
+			continue
+		}
+		if actual := hex.EncodeToString(data); actual != tc.hex {
+			t.Errorf("unexpected output for %#v\nexpected: %s\nactual:   %s", tc.value, tc.hex, actual)
+		}
+	}
+}
+
+func TestMarshalAppendErrors(t *testing.T) {
+	if _, err := cbor.Marshal(complex(1, 2)); err == nil {
+		t.Error("expected an error when encoding a complex number")
+	}
+	if _, err := cbor.Marshal(map[string]interface{}{"ch": make(chan int)}); err == nil {
+		t.Error("expected an error when encoding a channel")
+	}
+}
+
+func TestMarshalAppendAllocs(t *testing.T) {
+	v := newTelemetry()
+	v.Counters = nil
+	buf, err := cbor.MarshalAppend(nil, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The buffer is now big enough, so encoding again should not allocate.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 100; i++ {
+		buf, err = cbor.MarshalAppend(buf[:0], v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	runtime.ReadMemStats(&after)
+	if allocs := after.Mallocs - before.Mallocs; allocs != 0 {
+		t.Errorf("expected no allocations, got %d", allocs)
+	}
+}
+
+func BenchmarkMarshalAppend(b *testing.B) {
+	v := newTelemetry()
+	buf, _ := cbor.MarshalAppend(nil, v)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = cbor.MarshalAppend(buf[:0], v)
+	}
+}
@@ -0,0 +1,203 @@
+// Package fixed implements Q15 and Q31 fixed-point numbers, for targets
+// without a floating point unit.
+//
+// A Q15 number is a 16-bit signed integer that represents a value in the range
+// [-1, 1) in steps of 2^-15, and a Q31 number is the 32-bit equivalent. These
+// are the common formats for audio samples and other DSP data. All operations
+// only use integer instructions: multiplying two Q15 numbers is a single 32-bit
+// multiply and a shift, which is many times faster than a software floating
+// point multiply on a chip like the Cortex-M0.
+//
+// Arithmetic saturates instead of wrapping around: a result that is too large
+// to be represented is clamped to the largest (or smallest) value, which is
+// usually what DSP code wants. Products are rounded to the nearest value, with
+// halfway cases rounded up.
+//
+//	gain := fixed.Q15FromFloat(0.5)
+//	for i, sample := range samples {
+//		samples[i] = sample.Mul(gain)
+//	}
+package fixed
+
+// Q15 is a fixed-point number in the range [-1, 1) with 15 fractional bits.
+type Q15 int16
+
+// Q31 is a fixed-point number in the range [-1, 1) with 31 fractional bits.
+type Q31 int32
+
+const (
+	// MaxQ15 and MinQ15 are the largest and smallest Q15 values, just below 1
+	// and exactly -1.
+	MaxQ15 Q15 = 1<<15 - 1
+	MinQ15 Q15 = -1 << 15
+
+	// MaxQ31 and MinQ31 are the largest and smallest Q31 values, just below 1
+	// and exactly -1.
+	MaxQ31 Q31 = 1<<31 - 1
+	MinQ31 Q31 = -1 << 31
+)
+
+// Q15FromFloat converts f to the nearest Q15 number, saturating values outside
+// [-1, 1).
+func Q15FromFloat(f float64) Q15 {
+	x := roundFloat(f * (1 << 15))
+	if x > int64(MaxQ15) {
+		return MaxQ15
+	}
+	if x < int64(MinQ15) {
+		return MinQ15
+	}
+	return Q15(x)
+}
+
+// Q31FromFloat converts f to the nearest Q31 number, saturating values outside
+// [-1, 1).
+func Q31FromFloat(f float64) Q31 {
+	return Q31(saturate32(roundFloat(f * (1 << 31))))
+}
+
+// Float returns the value of q as a floating point number.
+func (q Q15) Float() float64 {
+	return float64(q) / (1 << 15)
+}
+
+// Float returns the value of q as a floating point number.
+func (q Q31) Float() float64 {
+	return float64(q) / (1 << 31)
+}
+
+// Add returns q+r, saturated.
+func (q Q15) Add(r Q15) Q15 {
+	return Q15(saturate16(int32(q) + int32(r)))
+}
+
+// Add returns q+r, saturated.
+func (q Q31) Add(r Q31) Q31 {
+	return Q31(saturate32(int64(q) + int64(r)))
+}
+
+// Sub returns q-r, saturated.
+func (q Q15) Sub(r Q15) Q15 {
+	return Q15(saturate16(int32(q) - int32(r)))
+}
+
+// Sub returns q-r, saturated.
+func (q Q31) Sub(r Q31) Q31 {
+	return Q31(saturate32(int64(q) - int64(r)))
+}
+
+// Neg returns -q, saturated: the negation of -1 is MaxQ15.
+func (q Q15) Neg() Q15 {
+	if q == MinQ15 {
+		return MaxQ15
+	}
+	return -q
+}
+
+// Neg returns -q, saturated: the negation of -1 is MaxQ31.
+func (q Q31) Neg() Q31 {
+	if q == MinQ31 {
+		return MaxQ31
+	}
+	return -q
+}
+
+// Mul returns q*r, rounded to the nearest value. The only product that
+// doesn't fit is -1 * -1, which saturates to MaxQ15.
+func (q Q15) Mul(r Q15) Q15 {
+	// This is a single 32-bit multiply, which all targets have.
+	p := (int32(q)*int32(r) + 1<<14) >> 15
+	if p > int32(MaxQ15) {
+		return MaxQ15
+	}
+	return Q15(p)
+}
+
+// Mul returns q*r, rounded to the nearest value. The only product that
+// doesn't fit is -1 * -1, which saturates to MaxQ31.
+func (q Q31) Mul(r Q31) Q31 {
+	p := (int64(q)*int64(r) + 1<<30) >> 31
+	if p > int64(MaxQ31) {
+		return MaxQ31
+	}
+	return Q31(p)
+}
+
+// Div returns q/r, truncated towards zero and saturated. The result is only
+// in range if |q| < |r|. Division by zero saturates to MaxQ15 or MinQ15
+// depending on the sign of q (and returns 0 for 0/0).
+func (q Q15) Div(r Q15) Q15 {
+	if r == 0 {
+		return Q15(saturate16(int32(q) << 16))
+	}
+	return Q15(saturate16(int32(q) << 15 / int32(r)))
+}
+
+// Div returns q/r, truncated towards zero and saturated. The result is only
+// in range if |q| < |r|. Division by zero saturates to MaxQ31 or MinQ31
+// depending on the sign of q (and returns 0 for 0/0).
+func (q Q31) Div(r Q31) Q31 {
+	if r == 0 {
+		return Q31(saturate32(int64(q) << 32))
+	}
+	return Q31(saturate32((int64(q) << 31) / int64(r)))
+}
+
+// Q31 converts q to a Q31 number. This is exact.
+func (q Q15) Q31() Q31 {
+	return Q31(q) << 16
+}
+
+// Q15 converts q to the nearest Q15 number.
+func (q Q31) Q15() Q15 {
+	return Q15(saturate16(int32((int64(q) + 1<<15) >> 16)))
+}
+
+// MulAdd returns acc + q*r, where the product is calculated with full
+// precision and the sum is saturated. It is the basic operation of FIR filters
+// and dot products.
+func (q Q15) MulAdd(r Q15, acc Q31) Q31 {
+	// The product of two Q15 numbers is a Q30 number, so it can be converted
+	// to Q31 exactly (except for -1 * -1, which saturates).
+	return acc.Add(Q31(saturate32(int64(int32(q)*int32(r)) << 1)))
+}
+
+// roundFloat rounds f to the nearest integer, with halfway cases rounded up
+// like Mul does. Very large values are clamped to ±2^62, which is enough for
+// saturating them later.
+func roundFloat(f float64) int64 {
+	switch {
+	case f >= 1<<62:
+		return 1 << 62
+	case f <= -1<<62:
+		return -1 << 62
+	case f != f: // NaN
+		return 0
+	}
+	f += 0.5
+	n := int64(f) // rounds towards zero
+	if float64(n) > f {
+		n--
+	}
+	return n
+}
+
+func saturate16(x int32) int32 {
+	if x > int32(MaxQ15) {
+		return int32(MaxQ15)
+	}
+	if x < int32(MinQ15) {
+		return int32(MinQ15)
+	}
+	return x
+}
+
+func saturate32(x int64) int64 {
+	if x > int64(MaxQ31) {
+		return int64(MaxQ31)
+	}
+	if x < int64(MinQ31) {
+		return int64(MinQ31)
+	}
+	return x
+}
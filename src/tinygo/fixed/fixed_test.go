@@ -0,0 +1,185 @@
+package fixed_test
+
+import (
+	"math"
+	"testing"
+
+	"tinygo/fixed"
+)
+
+func TestQ15FromFloat(t *testing.T) {
+	for _, tc := range []struct {
+		f    float64
+		want fixed.Q15
+	}{
+		{0, 0},
+		{0.5, 0x4000},
+		{-0.5, -0x4000},
+		{0.25, 0x2000},
+		{-1, fixed.MinQ15},
+		{1, fixed.MaxQ15},
+		{2, fixed.MaxQ15},
+		{-2, fixed.MinQ15},
+		{1.0 / 32768, 1},
+		{0.4 / 32768, 0},
+		{0.6 / 32768, 1},
+		{-0.6 / 32768, -1},
+		{-0.5 / 32768, 0}, // halfway cases round up
+		{1.5 / 32768, 2},
+		{math.Inf(1), fixed.MaxQ15},
+		{math.Inf(-1), fixed.MinQ15},
+		{math.NaN(), 0},
+	} {
+		if got := fixed.Q15FromFloat(tc.f); got != tc.want {
+			t.Errorf("Q15FromFloat(%v) = %d, want %d", tc.f, got, tc.want)
+		}
+	}
+	if f := fixed.Q15(0x6000).Float(); f != 0.75 {
+		t.Errorf("Q15(0x6000).Float() = %v, want 0.75", f)
+	}
+	if f := fixed.MinQ15.Float(); f != -1 {
+		t.Errorf("MinQ15.Float() = %v, want -1", f)
+	}
+}
+
+func TestQ31FromFloat(t *testing.T) {
+	for _, tc := range []struct {
+		f    float64
+		want fixed.Q31
+	}{
+		{0, 0},
+		{0.5, 0x40000000},
+		{-0.5, -0x40000000},
+		{-1, fixed.MinQ31},
+		{1, fixed.MaxQ31},
+		{1e10, fixed.MaxQ31},
+		{-1e10, fixed.MinQ31},
+		{1.0 / (1 << 31), 1},
+		{math.NaN(), 0},
+	} {
+		if got := fixed.Q31FromFloat(tc.f); got != tc.want {
+			t.Errorf("Q31FromFloat(%v) = %d, want %d", tc.f, got, tc.want)
+		}
+	}
+	if f := fixed.Q31(0x20000000).Float(); f != 0.25 {
+		t.Errorf("Q31(0x20000000).Float() = %v, want 0.25", f)
+	}
+}
+
+func TestQ15Arithmetic(t *testing.T) {
+	half := fixed.Q15(0x4000)
+	quarter := fixed.Q15(0x2000)
+	for _, tc := range []struct {
+		name string
+		got  fixed.Q15
+		want fixed.Q15
+	}{
+		{"0.5+0.25", half.Add(quarter), 0x6000},
+		{"0.5+0.5", half.Add(half), fixed.MaxQ15},
+		{"-1+-0.5", fixed.MinQ15.Add(-half), fixed.MinQ15},
+		{"0.25-0.5", quarter.Sub(half), -0x2000},
+		{"-1-0.5", fixed.MinQ15.Sub(half), fixed.MinQ15},
+		{"0.5-(-1)", half.Sub(fixed.MinQ15), fixed.MaxQ15},
+		{"-(0.5)", half.Neg(), -0x4000},
+		{"-(-1)", fixed.MinQ15.Neg(), fixed.MaxQ15},
+		{"0.5*0.5", half.Mul(half), quarter},
+		{"0.5*-0.5", half.Mul(-half), -quarter},
+		{"-1*-1", fixed.MinQ15.Mul(fixed.MinQ15), fixed.MaxQ15},
+		{"-1*0.5", fixed.MinQ15.Mul(half), -half},
+		{"max*max", fixed.MaxQ15.Mul(fixed.MaxQ15), 0x7ffe},
+		{"round up", fixed.Q15(1).Mul(half), 1},     // 0.5 LSB rounds up
+		{"round down", fixed.Q15(1).Mul(0x3fff), 0}, // just below 0.5 LSB
+		{"0.25/0.5", quarter.Div(half), half},
+		{"-0.25/0.5", (-quarter).Div(half), -half},
+		{"0.5/0.25", half.Div(quarter), fixed.MaxQ15},
+		{"-0.5/0.25", (-half).Div(quarter), fixed.MinQ15},
+		{"-1/-1", fixed.MinQ15.Div(fixed.MinQ15), fixed.MaxQ15},
+		{"0.5/0", half.Div(0), fixed.MaxQ15},
+		{"-0.5/0", (-half).Div(0), fixed.MinQ15},
+		{"0/0", fixed.Q15(0).Div(0), 0},
+		{"Q31->Q15", fixed.Q31(0x12348000).Q15(), 0x1235},
+		{"Q31->Q15 max", fixed.MaxQ31.Q15(), fixed.MaxQ15},
+		{"Q31->Q15 min", fixed.MinQ31.Q15(), fixed.MinQ15},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %#x, want %#x", tc.name, tc.got, tc.want)
+		}
+	}
+	if got := half.Q31(); got != 0x40000000 {
+		t.Errorf("Q15(0.5).Q31() = %#x", got)
+	}
+}
+
+func TestQ31Arithmetic(t *testing.T) {
+	half := fixed.Q31(0x40000000)
+	quarter := fixed.Q31(0x20000000)
+	for _, tc := range []struct {
+		name string
+		got  fixed.Q31
+		want fixed.Q31
+	}{
+		{"0.5+0.25", half.Add(quarter), 0x60000000},
+		{"0.5+0.5", half.Add(half), fixed.MaxQ31},
+		{"-1-0.5", fixed.MinQ31.Sub(half), fixed.MinQ31},
+		{"-(-1)", fixed.MinQ31.Neg(), fixed.MaxQ31},
+		{"0.5*0.5", half.Mul(half), quarter},
+		{"0.5*-0.25", half.Mul(-quarter), -0x10000000},
+		{"-1*-1", fixed.MinQ31.Mul(fixed.MinQ31), fixed.MaxQ31},
+		{"max*max", fixed.MaxQ31.Mul(fixed.MaxQ31), 0x7ffffffe},
+		{"0.25/0.5", quarter.Div(half), half},
+		{"0.5/0.25", half.Div(quarter), fixed.MaxQ31},
+		{"-0.5/0", (-half).Div(0), fixed.MinQ31},
+		{"MulAdd", fixed.Q15(0x4000).MulAdd(0x4000, quarter), half},
+		{"MulAdd -1*-1", fixed.MinQ15.MulAdd(fixed.MinQ15, 0), fixed.MaxQ31},
+		{"MulAdd saturate", fixed.Q15(0x4000).MulAdd(0x7fff, fixed.MaxQ31), fixed.MaxQ31},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %#x, want %#x", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+// TestQ15MulReference compares the product of many pairs of values with the
+// product calculated using floating point.
+func TestQ15MulReference(t *testing.T) {
+	for a := -32768; a < 32768; a += 97 {
+		for b := -32768; b < 32768; b += 89 {
+			got := fixed.Q15(a).Mul(fixed.Q15(b))
+			want := fixed.Q15FromFloat(fixed.Q15(a).Float() * fixed.Q15(b).Float())
+			if got != want {
+				t.Errorf("%d * %d: got %d, want %d", a, b, got, want)
+				return
+			}
+		}
+	}
+}
+
+var (
+	benchQ15   = []fixed.Q15{1000, -2000, 3000, -4000, 5000, -6000, 7000, -8000}
+	benchFloat = []float32{0.03, -0.06, 0.09, -0.12, 0.15, -0.18, 0.21, -0.24}
+	sinkQ15    fixed.Q15
+	sinkFloat  float32
+)
+
+// The benchmarks below compare a multiply-accumulate in fixed point with the
+// same in float32. They are run on the Cortex-M3 emulated by cortex-m-qemu (see
+// make tinygo-baremetal), which has no FPU. QEMU doesn't emulate cycle timing,
+// so the numbers only give an indication of the difference on real hardware.
+
+func BenchmarkQ15Mul(b *testing.B) {
+	gain := fixed.Q15FromFloat(0.7)
+	acc := fixed.Q15(0)
+	for i := 0; i < b.N; i++ {
+		acc = acc.Add(benchQ15[i%len(benchQ15)].Mul(gain))
+	}
+	sinkQ15 = acc
+}
+
+func BenchmarkFloat32Mul(b *testing.B) {
+	gain := float32(0.7)
+	acc := float32(0)
+	for i := 0; i < b.N; i++ {
+		acc += benchFloat[i%len(benchFloat)] * gain
+	}
+	sinkFloat = acc
+}
@@ -0,0 +1,886 @@
+package json
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal a JSON
+// description of themselves. It is the same as json.Unmarshaler in
+// encoding/json.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+// A SyntaxError is a description of a JSON syntax error. Unmarshal returns it
+// before storing anything if the input is not valid JSON.
+type SyntaxError struct {
+	msg    string
+	Offset int64 // error occurred after reading Offset bytes
+}
+
+func (e *SyntaxError) Error() string { return e.msg }
+
+// An UnmarshalTypeError describes a JSON value that was not appropriate for a
+// value of a specific Go type.
+type UnmarshalTypeError struct {
+	Value  string       // description of JSON value - "bool", "array", "number -5"
+	Type   reflect.Type // type of Go value it could not be assigned to
+	Offset int64        // error occurred after reading Offset bytes
+	Struct string       // name of the struct type containing the field
+	Field  string       // the full path from root node to the field
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	if e.Struct != "" || e.Field != "" {
+		return "json: cannot unmarshal " + e.Value + " into Go struct field " + e.Struct + "." + e.Field + " of type " + e.Type.String()
+	}
+	return "json: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// The argument to Unmarshal must be a non-nil pointer.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "json: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return "json: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "json: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value
+// pointed to by v, like encoding/json does.
+//
+// The same types as MarshalAppend are supported, and types that implement
+// Unmarshaler or encoding.TextUnmarshaler are decoded with their UnmarshalJSON
+// or UnmarshalText method. JSON objects are only decoded into structs, and
+// into map[string]interface{} when the target is an empty interface. Object
+// keys are matched to field names like encoding/json does, preferring an exact
+// match over a case-insensitive one.
+//
+// If a JSON value is not appropriate for the target type, Unmarshal skips it,
+// decodes the rest of the input and returns the first such error.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	// Check the syntax first, so that nothing is stored on a syntax error.
+	d := decodeState{data: data}
+	if err := d.checkValid(); err != nil {
+		return err
+	}
+	d.off = 0
+	d.value(rv)
+	return d.savedError
+}
+
+// The maximum nesting depth of arrays and objects, like encoding/json.
+const maxNestingDepth = 10000
+
+// decodeState is the state of Unmarshal. The input is checked by checkValid
+// first, so the decoding functions can assume it is valid JSON.
+type decodeState struct {
+	data       []byte
+	off        int // next byte to read
+	depth      int
+	savedError error
+
+	// The struct and field that is being decoded, for error messages.
+	errorStruct reflect.Type
+	errorField  string
+}
+
+// saveError saves the first error that occurs while decoding.
+func (d *decodeState) saveError(err error) {
+	if d.savedError == nil {
+		d.savedError = err
+	}
+}
+
+// saveTypeError saves an UnmarshalTypeError for the value starting at start.
+func (d *decodeState) saveTypeError(what string, t reflect.Type, start int) {
+	err := &UnmarshalTypeError{Value: what, Type: t, Offset: int64(start)}
+	if d.errorStruct != nil {
+		err.Struct = d.errorStruct.Name()
+		err.Field = d.errorField
+	}
+	d.saveError(err)
+}
+
+func (d *decodeState) skipSpace() {
+	for d.off < len(d.data) {
+		switch d.data[d.off] {
+		case ' ', '\t', '\n', '\r':
+			d.off++
+		default:
+			return
+		}
+	}
+}
+
+// checkValid checks that the input is a single valid JSON value, optionally
+// surrounded by whitespace.
+func (d *decodeState) checkValid() error {
+	if err := d.skipValue(); err != nil {
+		return err
+	}
+	d.skipSpace()
+	if d.off < len(d.data) {
+		return d.syntaxError("after top-level value")
+	}
+	return nil
+}
+
+// syntaxError returns the error for an unexpected character at the current
+// position, or for the end of the input.
+func (d *decodeState) syntaxError(context string) error {
+	if d.off >= len(d.data) {
+		return &SyntaxError{"unexpected end of JSON input", int64(len(d.data))}
+	}
+	return &SyntaxError{"invalid character " + quoteChar(d.data[d.off]) + " " + context, int64(d.off + 1)}
+}
+
+// literalSyntaxError is like syntaxError, for an error inside a literal or
+// number. Like encoding/json, the end of the input is reported as a space
+// there.
+func (d *decodeState) literalSyntaxError(context string) error {
+	if d.off >= len(d.data) {
+		return &SyntaxError{"invalid character ' ' " + context, int64(len(d.data))}
+	}
+	return d.syntaxError(context)
+}
+
+// quoteChar formats c as a quoted character literal, like encoding/json.
+func quoteChar(c byte) string {
+	switch c {
+	case '\'':
+		return `'\''`
+	case '"':
+		return `'"'`
+	}
+	s := strconv.Quote(string(rune(c)))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+// skipValue skips over the next JSON value and the whitespace before it,
+// checking its syntax.
+func (d *decodeState) skipValue() error {
+	d.skipSpace()
+	if d.off >= len(d.data) {
+		return d.syntaxError("")
+	}
+	switch c := d.data[d.off]; {
+	case c == '{':
+		return d.skipComposite('}', "object")
+	case c == '[':
+		return d.skipComposite(']', "array")
+	case c == '"':
+		return d.skipString()
+	case c == 't':
+		return d.skipLiteral("true")
+	case c == 'f':
+		return d.skipLiteral("false")
+	case c == 'n':
+		return d.skipLiteral("null")
+	case c == '-' || c >= '0' && c <= '9':
+		return d.skipNumber()
+	default:
+		return d.syntaxError("looking for beginning of value")
+	}
+}
+
+// skipComposite skips over an object or array, whose opening character is at
+// the current position.
+func (d *decodeState) skipComposite(end byte, kind string) error {
+	d.depth++
+	if d.depth > maxNestingDepth {
+		return &SyntaxError{"exceeded max depth", int64(d.off)}
+	}
+	d.off++
+	d.skipSpace()
+	if d.off < len(d.data) && d.data[d.off] == end {
+		d.off++
+		d.depth--
+		return nil
+	}
+	for {
+		if end == '}' {
+			d.skipSpace()
+			if d.off >= len(d.data) || d.data[d.off] != '"' {
+				return d.syntaxError("looking for beginning of object key string")
+			}
+			if err := d.skipString(); err != nil {
+				return err
+			}
+			d.skipSpace()
+			if d.off >= len(d.data) || d.data[d.off] != ':' {
+				return d.syntaxError("after object key")
+			}
+			d.off++
+		}
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		d.skipSpace()
+		if d.off < len(d.data) {
+			switch d.data[d.off] {
+			case ',':
+				d.off++
+				continue
+			case end:
+				d.off++
+				d.depth--
+				return nil
+			}
+		}
+		if end == '}' {
+			return d.syntaxError("after object key:value pair")
+		}
+		return d.syntaxError("after " + kind + " element")
+	}
+}
+
+// skipString skips over a string literal, whose opening quote is at the
+// current position.
+func (d *decodeState) skipString() error {
+	d.off++
+	for d.off < len(d.data) {
+		c := d.data[d.off]
+		switch {
+		case c == '"':
+			d.off++
+			return nil
+		case c == '\\':
+			d.off++
+			if d.off >= len(d.data) {
+				return d.syntaxError("")
+			}
+			switch d.data[d.off] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				d.off++
+			case 'u':
+				d.off++
+				for i := 0; i < 4; i++ {
+					if d.off >= len(d.data) || unhex(d.data[d.off]) < 0 {
+						return d.syntaxError("in \\u hexadecimal character escape")
+					}
+					d.off++
+				}
+			default:
+				return d.syntaxError("in string escape code")
+			}
+		case c < 0x20:
+			return d.syntaxError("in string literal")
+		default:
+			d.off++
+		}
+	}
+	return d.syntaxError("")
+}
+
+// skipLiteral skips over the literal true, false or null.
+func (d *decodeState) skipLiteral(literal string) error {
+	for i := 0; i < len(literal); i++ {
+		if d.off >= len(d.data) || d.data[d.off] != literal[i] {
+			if i == 0 {
+				return d.syntaxError("looking for beginning of value")
+			}
+			return d.literalSyntaxError("in literal " + literal + " (expecting " + quoteChar(literal[i]) + ")")
+		}
+		d.off++
+	}
+	return nil
+}
+
+// skipNumber skips over a number, which starts with a digit or minus sign.
+func (d *decodeState) skipNumber() error {
+	if d.data[d.off] == '-' {
+		d.off++
+		if d.off >= len(d.data) || !isDigit(d.data[d.off]) {
+			return d.literalSyntaxError("in numeric literal")
+		}
+	}
+	if d.data[d.off] == '0' {
+		d.off++
+	} else {
+		d.skipDigits()
+	}
+	if d.off < len(d.data) && d.data[d.off] == '.' {
+		d.off++
+		if d.off >= len(d.data) || !isDigit(d.data[d.off]) {
+			return d.literalSyntaxError("after decimal point in numeric literal")
+		}
+		d.skipDigits()
+	}
+	if d.off < len(d.data) && (d.data[d.off] == 'e' || d.data[d.off] == 'E') {
+		d.off++
+		if d.off < len(d.data) && (d.data[d.off] == '+' || d.data[d.off] == '-') {
+			d.off++
+		}
+		if d.off >= len(d.data) || !isDigit(d.data[d.off]) {
+			return d.literalSyntaxError("in exponent of numeric literal")
+		}
+		d.skipDigits()
+	}
+	return nil
+}
+
+func (d *decodeState) skipDigits() {
+	for d.off < len(d.data) && isDigit(d.data[d.off]) {
+		d.off++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// next skips over the next value and returns it, for values that are passed to
+// UnmarshalJSON or skipped because of a type error.
+func (d *decodeState) next() []byte {
+	d.skipSpace()
+	start := d.off
+	d.skipValue() // the input was already checked
+	return d.data[start:d.off]
+}
+
+// emptyInterfaceType is the type of interface{}, the only interface type that
+// values are decoded into (apart from ones that contain a pointer).
+var emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// value decodes the next JSON value into v.
+func (d *decodeState) value(v reflect.Value) {
+	d.skipSpace()
+	start := d.off
+	c := d.data[d.off]
+	u, tu, v := indirect(v, c == 'n')
+	if u != nil {
+		if err := u.UnmarshalJSON(d.next()); err != nil {
+			d.saveError(err)
+		}
+		return
+	}
+	if tu != nil {
+		if c != '"' {
+			d.next()
+			d.saveTypeError(valueKind(c), v.Type(), start)
+			return
+		}
+		if err := tu.UnmarshalText([]byte(unquote(d.next()))); err != nil {
+			d.saveError(err)
+		}
+		return
+	}
+	switch c {
+	case '{':
+		d.object(v, start)
+	case '[':
+		d.array(v, start)
+	default:
+		d.literal(v, d.next(), start)
+	}
+}
+
+// valueKind describes the JSON value starting with c, for an
+// UnmarshalTypeError.
+func valueKind(c byte) string {
+	switch c {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// indirect walks down v, allocating pointers as needed, until it gets to a
+// non-pointer. It stops early at a value that implements Unmarshaler or
+// encoding.TextUnmarshaler. If decodingNull is set, it stops at the last
+// pointer so that it can be set to nil. Like encoding/json, methods with a
+// pointer receiver are also used for addressable values.
+func indirect(v reflect.Value, decodingNull bool) (Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	if v.Kind() != reflect.Ptr && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for {
+		// Decode into the value of an interface if it is a non-nil pointer,
+		// like encoding/json.
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Ptr && !e.IsNil() && (!decodingNull || e.Elem().Kind() == reflect.Ptr) {
+				v = e
+				continue
+			}
+		}
+		if v.Kind() != reflect.Ptr {
+			break
+		}
+		if decodingNull && v.CanSet() {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.CanInterface() {
+			switch m := v.Interface().(type) {
+			case Unmarshaler:
+				return m, nil, reflect.Value{}
+			case encoding.TextUnmarshaler:
+				if !decodingNull {
+					return nil, m, v.Elem()
+				}
+			}
+		}
+		v = v.Elem()
+	}
+	return nil, nil, v
+}
+
+// setInterface stores x in v, which is an empty interface. The value is set
+// through a pointer to x so that it has the interface type.
+func setInterface(v reflect.Value, x interface{}) {
+	v.Set(reflect.ValueOf(&x).Elem())
+}
+
+// object decodes a JSON object into v.
+func (d *decodeState) object(v reflect.Value, start int) {
+	if v.Kind() == reflect.Interface && v.Type() == emptyInterfaceType {
+		setInterface(v, d.valueInterface())
+		return
+	}
+	if v.Kind() != reflect.Struct {
+		d.next()
+		d.saveTypeError("object", v.Type(), start)
+		return
+	}
+
+	errorStruct, errorField := d.errorStruct, d.errorField
+	d.off++ // '{'
+	for {
+		d.skipSpace()
+		if d.data[d.off] == '}' {
+			d.off++
+			break
+		}
+		key := unquote(d.next())
+		d.skipSpace()
+		d.off++ // ':'
+
+		field, quoted, ok := fieldByJSONName(v, key)
+		if !ok {
+			d.next()
+		} else {
+			d.errorStruct = v.Type()
+			d.errorField = key
+			if errorField != "" {
+				d.errorField = errorField + "." + key
+			}
+			if quoted {
+				d.quotedLiteral(field)
+			} else {
+				d.value(field)
+			}
+			d.errorStruct, d.errorField = errorStruct, errorField
+		}
+		d.skipSpace()
+		if d.data[d.off] == ',' {
+			d.off++
+		}
+	}
+}
+
+// quotedLiteral decodes a string that contains a boolean or number, for the
+// ",string" field tag option.
+func (d *decodeState) quotedLiteral(v reflect.Value) {
+	item := d.next()
+	if item[0] != '"' {
+		if item[0] != 'n' {
+			d.saveError(errors.New("json: invalid use of ,string struct tag, trying to unmarshal unquoted value into " + v.Type().String()))
+		}
+		return
+	}
+	s := unquote(item)
+	inner := decodeState{data: []byte(s)}
+	if s == "" || s[0] == '"' || s[0] == '{' || s[0] == '[' || inner.checkValid() != nil {
+		d.saveError(errors.New("json: invalid use of ,string struct tag, trying to unmarshal " + strconv.Quote(s) + " into " + v.Type().String()))
+		return
+	}
+	inner.off = 0
+	inner.errorStruct, inner.errorField = d.errorStruct, d.errorField
+	inner.value(v)
+	if inner.savedError != nil {
+		d.saveError(inner.savedError)
+	}
+}
+
+// fieldByJSONName returns the field of the struct v that the object key name
+// is decoded into, allocating embedded struct pointers as needed. It also
+// returns whether the field has the ",string" option.
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool, bool) {
+	path, quoted, ok := findField(v.Type(), name, false, nil)
+	if !ok {
+		path, quoted, ok = findField(v.Type(), name, true, nil)
+		if !ok {
+			return reflect.Value{}, false, false
+		}
+	}
+	for _, i := range path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					// Embedded pointer to an unexported struct type.
+					return reflect.Value{}, false, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, quoted, true
+}
+
+// findField looks up the field for the object key name in the struct type t,
+// and returns the field indices to get there starting at t. The fields of
+// embedded structs are searched after the fields of t, like MarshalAppend
+// includes them. With fold set, the name is compared case-insensitively.
+func findField(t reflect.Type, name string, fold bool, path []int) ([]int, bool, bool) {
+	numField := t.NumField()
+	for i := 0; i < numField; i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName, opts := parseTag(tag)
+		if field.Anonymous && fieldName == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, quoted, ok := findField(embedded, name, fold, append(path, i)); ok {
+					return found, quoted, true
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		if fieldName == name || fold && strings.EqualFold(fieldName, name) {
+			quoted := false
+			if hasOption(opts, "string") {
+				switch field.Type.Kind() {
+				case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+					reflect.Float32, reflect.Float64:
+					quoted = true
+				}
+			}
+			return append(path, i), quoted, true
+		}
+	}
+	return nil, false, false
+}
+
+// array decodes a JSON array into v.
+func (d *decodeState) array(v reflect.Value, start int) {
+	if v.Kind() == reflect.Interface && v.Type() == emptyInterfaceType {
+		setInterface(v, d.valueInterface())
+		return
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		d.next()
+		d.saveTypeError("array", v.Type(), start)
+		return
+	}
+
+	d.off++ // '['
+	i := 0
+	for {
+		d.skipSpace()
+		if d.data[d.off] == ']' {
+			d.off++
+			break
+		}
+		if v.Kind() == reflect.Slice && i >= v.Len() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		if i < v.Len() {
+			d.value(v.Index(i))
+		} else {
+			// Extra elements of an array are ignored.
+			d.next()
+		}
+		i++
+		d.skipSpace()
+		if d.data[d.off] == ',' {
+			d.off++
+		}
+	}
+	if i < v.Len() {
+		if v.Kind() == reflect.Array {
+			for ; i < v.Len(); i++ {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+		} else {
+			v.SetLen(i)
+		}
+	}
+	if i == 0 && v.Kind() == reflect.Slice && v.IsNil() {
+		// An empty array is decoded into an empty slice, not a nil slice.
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+}
+
+// literal decodes the literal item (a string, number, true, false or null) into
+// v.
+func (d *decodeState) literal(v reflect.Value, item []byte, start int) {
+	switch c := item[0]; c {
+	case 'n':
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		// Otherwise null has no effect, like in encoding/json.
+	case 't', 'f':
+		value := c == 't'
+		switch {
+		case v.Kind() == reflect.Bool:
+			v.SetBool(value)
+		case v.Kind() == reflect.Interface && v.Type() == emptyInterfaceType:
+			setInterface(v, value)
+		default:
+			d.saveTypeError("bool", v.Type(), start)
+		}
+	case '"':
+		s := unquote(item)
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(s)
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				d.saveError(err)
+				return
+			}
+			v.SetBytes(b)
+		case v.Kind() == reflect.Interface && v.Type() == emptyInterfaceType:
+			setInterface(v, s)
+		default:
+			d.saveTypeError("string", v.Type(), start)
+		}
+	default:
+		s := string(item)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+			if err != nil {
+				d.saveTypeError("number "+s, v.Type(), start)
+				return
+			}
+			v.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+			if err != nil {
+				d.saveTypeError("number "+s, v.Type(), start)
+				return
+			}
+			v.SetUint(n)
+		case reflect.Float32, reflect.Float64:
+			n, err := strconv.ParseFloat(s, v.Type().Bits())
+			if err != nil {
+				d.saveTypeError("number "+s, v.Type(), start)
+				return
+			}
+			v.SetFloat(n)
+		case reflect.Interface:
+			if v.Type() != emptyInterfaceType {
+				d.saveTypeError("number", v.Type(), start)
+				return
+			}
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				d.saveTypeError("number "+s, v.Type(), start)
+				return
+			}
+			setInterface(v, n)
+		default:
+			d.saveTypeError("number", v.Type(), start)
+		}
+	}
+}
+
+// valueInterface decodes the next JSON value as a bool, float64, string,
+// []interface{}, map[string]interface{} or nil, like encoding/json does for an
+// empty interface.
+func (d *decodeState) valueInterface() interface{} {
+	d.skipSpace()
+	switch d.data[d.off] {
+	case '{':
+		m := make(map[string]interface{})
+		d.off++
+		for {
+			d.skipSpace()
+			if d.data[d.off] == '}' {
+				d.off++
+				return m
+			}
+			key := unquote(d.next())
+			d.skipSpace()
+			d.off++ // ':'
+			m[key] = d.valueInterface()
+			d.skipSpace()
+			if d.data[d.off] == ',' {
+				d.off++
+			}
+		}
+	case '[':
+		s := []interface{}{}
+		d.off++
+		for {
+			d.skipSpace()
+			if d.data[d.off] == ']' {
+				d.off++
+				return s
+			}
+			s = append(s, d.valueInterface())
+			d.skipSpace()
+			if d.data[d.off] == ',' {
+				d.off++
+			}
+		}
+	}
+	item := d.next()
+	switch item[0] {
+	case 'n':
+		return nil
+	case 't':
+		return true
+	case 'f':
+		return false
+	case '"':
+		return unquote(item)
+	}
+	n, err := strconv.ParseFloat(string(item), 64)
+	if err != nil {
+		d.saveTypeError("number "+string(item), reflect.TypeOf(0.0), d.off-len(item))
+	}
+	return n
+}
+
+// unquote returns the contents of the string literal item, which has already
+// been checked. Invalid UTF-8 and invalid surrogate pairs are replaced with
+// U+FFFD, like encoding/json does.
+func unquote(item []byte) string {
+	s := item[1 : len(item)-1]
+	simple := true
+	for _, c := range s {
+		if c == '\\' || c >= utf8.RuneSelf {
+			simple = false
+			break
+		}
+	}
+	if simple {
+		return string(s)
+	}
+
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\':
+			switch e := s[i+1]; e {
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r := rune(getu4(s[i+2:]))
+				i += 6
+				if utf16.IsSurrogate(r) {
+					r2 := rune(-1)
+					if i+6 <= len(s) && s[i] == '\\' && s[i+1] == 'u' {
+						r2 = rune(getu4(s[i+2:]))
+					}
+					if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+						r = dec
+						i += 6
+					} else {
+						r = utf8.RuneError
+					}
+				}
+				buf = utf8.AppendRune(buf, r)
+				continue
+			default: // '"', '\\' or '/'
+				buf = append(buf, e)
+			}
+			i += 2
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			i++
+		default:
+			r, size := utf8.DecodeRune(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf = append(buf, "\ufffd"...)
+			} else {
+				buf = append(buf, s[i:i+size]...)
+			}
+			i += size
+		}
+	}
+	return string(buf)
+}
+
+// getu4 decodes the four hexadecimal digits at the start of s.
+func getu4(s []byte) int {
+	n := 0
+	for _, c := range s[:4] {
+		n = n<<4 | unhex(c)
+	}
+	return n
+}
+
+// unhex returns the value of the hexadecimal digit c, or -1.
+func unhex(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c - 'a' + 10)
+	case c >= 'A' && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
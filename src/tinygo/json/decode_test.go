@@ -0,0 +1,226 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"tinygo/json"
+)
+
+// unmarshalPoint implements json.Unmarshaler, for the output of point's
+// MarshalJSON.
+type unmarshalPoint struct {
+	X, Y int
+}
+
+func (p *unmarshalPoint) UnmarshalJSON(data []byte) error {
+	var values []interface{}
+	if err := stdjson.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if len(values) < 2 {
+		return errors.New("point: too few coordinates")
+	}
+	p.X = int(values[0].(float64))
+	p.Y = int(values[1].(float64))
+	return nil
+}
+
+type unmarshalAlarm struct {
+	Level    level           `json:"level"`
+	Previous *level          `json:"previous"`
+	Levels   []level         `json:"levels"`
+	Location unmarshalPoint  `json:"location"`
+	Origin   *unmarshalPoint `json:"origin"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	// Every value is decoded by both encoding/json and this package, and the
+	// results must be the same.
+	for _, tc := range []struct {
+		input string
+		new   func() interface{}
+	}{
+		{`true`, func() interface{} { return new(bool) }},
+		{` -42 `, func() interface{} { return new(int) }},
+		{`18446744073709551615`, func() interface{} { return new(uint64) }},
+		{`3.14`, func() interface{} { return new(float32) }},
+		{`-1.5e-7`, func() interface{} { return new(float64) }},
+		{"\"a\\\"b\\\\c\\/\\b\\f\\n\\r\\t\\u00e9😀 \\ud83d\\ude00 \\ud800 \xff é\"", func() interface{} { return new(string) }},
+		{`"AAECAwT6"`, func() interface{} { return new([]byte) }},
+		{`null`, func() interface{} { return new(*position) }},
+		{`[]`, func() interface{} { return new([]int) }},
+		{`[1, 2, 3]`, func() interface{} { return new([2]int16) }},
+		{`{"Lat": 52.0907, "lon": 5.1214, "unknown": {"a": [1, {}]}}`, func() interface{} { return new(position) }},
+		{`{"a": [1, "b", true, null, {"c": {}}], "d": 1e3}`, func() interface{} { return new(interface{}) }},
+		{`{"level": "high <3>", "previous": "low", "levels": ["medium", "low"], "location": [3, 4, "x"], "origin": [-1, 0]}`, func() interface{} { return new(unmarshalAlarm) }},
+		{`{"level": "low", "previous": null, "levels": null, "origin": null}`, func() interface{} { return new(unmarshalAlarm) }},
+	} {
+		expected := tc.new()
+		if err := stdjson.Unmarshal([]byte(tc.input), expected); err != nil {
+			t.Fatalf("encoding/json could not decode %s: %v", tc.input, err)
+		}
+		actual := tc.new()
+		if err := json.Unmarshal([]byte(tc.input), actual); err != nil {
+			t.Errorf("could not decode %s: %v", tc.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("decoded %s\nexpected: %#v\nactual:   %#v", tc.input, reflect.ValueOf(expected).Elem().Interface(), reflect.ValueOf(actual).Elem().Interface())
+		}
+	}
+}
+
+func TestUnmarshalRoundTrip(t *testing.T) {
+	in := newTelemetry()
+	in.Extra = nil
+	in.internal = 0
+	data, err := json.MarshalAppend(nil, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &telemetry{Ignored: 3, Tags: []string{"x", "y", "z"}}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("could not decode %s: %v", data, err)
+	}
+	// The string written by MarshalAppend has invalid UTF-8 replaced.
+	in.Device = "sensor <1> & \"co\"\n\u2028\ufffd"
+	in.Ignored = 3
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip through %s\nexpected: %+v\nactual:   %+v", data, in, out)
+	}
+}
+
+func TestUnmarshalReuse(t *testing.T) {
+	// Like encoding/json, values that are not in the input are kept, slices
+	// are reused and arrays are zeroed after the last element.
+	buf := make([]int, 4, 8)
+	v := struct {
+		A, B  int
+		Slice []int
+		Array [3]int
+		Ptr   *int
+	}{A: 1, B: 2, Slice: buf, Array: [3]int{1, 2, 3}}
+	existing := 5
+	v.Ptr = &existing
+	if err := json.Unmarshal([]byte(`{"B": 3, "Slice": [7, 8], "Array": [9], "Ptr": 6}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 1 || v.B != 3 || len(v.Slice) != 2 || v.Slice[1] != 8 || &v.Slice[0] != &buf[0] || v.Array != [3]int{9, 0, 0} || v.Ptr != &existing || existing != 6 {
+		t.Errorf("unexpected result: %+v", v)
+	}
+}
+
+func TestUnmarshalFieldNames(t *testing.T) {
+	type Inner struct {
+		Name  string `json:"name"`
+		Count int
+	}
+	var v struct {
+		*Inner
+		Name    string
+		Renamed int  `json:"other"`
+		Skipped int  `json:"-"`
+		Quoted  int  `json:",string"`
+		Flag    bool `json:"flag,string"`
+	}
+	input := `{"name": "embedded", "Name": "outer", "count": 2, "Renamed": 1, "other": 3, "Skipped": 4, "Quoted": "-12", "flag": "true"}`
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Inner == nil || v.Inner.Name != "embedded" || v.Count != 2 || v.Name != "outer" || v.Renamed != 3 || v.Skipped != 0 || v.Quoted != -12 || !v.Flag {
+		t.Errorf("unexpected result: %+v (embedded %+v)", v, v.Inner)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	// The error messages are the same as those of encoding/json in Go 1.21.
+	// Later versions describe some syntax errors differently.
+	type quoted struct {
+		Quoted int `json:",string"`
+	}
+	for _, tc := range []struct {
+		input string
+		value interface{}
+		err   string
+	}{
+		// Syntax errors.
+		{``, new(interface{}), "unexpected end of JSON input"},
+		{`{"a": 1,}`, new(interface{}), "invalid character '}' looking for beginning of object key string"},
+		{`[1 2]`, new(interface{}), "invalid character '2' after array element"},
+		{`{"a" 1}`, new(interface{}), "invalid character '1' after object key"},
+		{`{"a": 1 "b"}`, new(interface{}), "invalid character '\"' after object key:value pair"},
+		{`{1: 2}`, new(interface{}), "invalid character '1' looking for beginning of object key string"},
+		{`"abc`, new(interface{}), "unexpected end of JSON input"},
+		{"\"a\tb\"", new(interface{}), "invalid character '\\t' in string literal"},
+		{`"\x"`, new(interface{}), "invalid character 'x' in string escape code"},
+		{`"\u12"`, new(interface{}), "invalid character '\"' in \\u hexadecimal character escape"},
+		{`tru`, new(interface{}), "invalid character ' ' in literal true (expecting 'e')"},
+		{`nulL`, new(interface{}), "invalid character 'L' in literal null (expecting 'l')"},
+		{`01`, new(interface{}), "invalid character '1' after top-level value"},
+		{`-`, new(interface{}), "invalid character ' ' in numeric literal"},
+		{`1.x`, new(interface{}), "invalid character 'x' after decimal point in numeric literal"},
+		{`1e+`, new(interface{}), "invalid character ' ' in exponent of numeric literal"},
+		{`'a'`, new(interface{}), "invalid character '\\'' looking for beginning of value"},
+
+		// Type errors.
+		{`"abc"`, new(int), "json: cannot unmarshal string into Go value of type int"},
+		{`1.5`, new(int), "json: cannot unmarshal number 1.5 into Go value of type int"},
+		{`300`, new(uint8), "json: cannot unmarshal number 300 into Go value of type uint8"},
+		{`-1`, new(uint), "json: cannot unmarshal number -1 into Go value of type uint"},
+		{`{"Lat": "north", "Lon": 1}`, new(position), "json: cannot unmarshal string into Go struct field position.Lat of type float64"},
+		{`{}`, new([]int), "json: cannot unmarshal object into Go value of type []int"},
+		{`[]`, new(position), "json: cannot unmarshal array into Go value of type json_test.position"},
+		{`1`, new(level), "json: cannot unmarshal number into Go value of type json_test.level"},
+		{`"extreme"`, new(level), "unknown level extreme"},
+		{`[1]`, new(unmarshalPoint), "point: too few coordinates"},
+		{`{"Quoted": 5}`, new(quoted), "json: invalid use of ,string struct tag, trying to unmarshal unquoted value into int"},
+		{`{"Quoted": "x"}`, new(quoted), `json: invalid use of ,string struct tag, trying to unmarshal "x" into int`},
+	} {
+		err := json.Unmarshal([]byte(tc.input), tc.value)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("decoding %s into %T\nexpected: %s\nactual:   %v", tc.input, tc.value, tc.err, err)
+		}
+	}
+
+	var x int
+	for _, tc := range []struct {
+		value interface{}
+		err   string
+	}{
+		{nil, "json: Unmarshal(nil)"},
+		{x, "json: Unmarshal(non-pointer int)"},
+		{(*int)(nil), "json: Unmarshal(nil *int)"},
+	} {
+		err := json.Unmarshal([]byte(`1`), tc.value)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("decoding into %#v\nexpected: %s\nactual:   %v", tc.value, tc.err, err)
+		}
+	}
+
+	// The decoding continues after a type error.
+	var p position
+	err := json.Unmarshal([]byte(`{"Lat": "north", "Lon": 1.5}`), &p)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "Lat" || typeErr.Offset != 8 || p.Lon != 1.5 {
+		t.Errorf("unexpected result %+v and error %v", p, err)
+	}
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal([]byte(`[1, 2`), &p); !errors.As(err, &syntaxErr) || syntaxErr.Offset != 5 {
+		t.Errorf("unexpected syntax error %v", err)
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data, _ := json.MarshalAppend(nil, newTelemetry())
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		var v telemetry
+		if err := json.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+// Package json implements a JSON encoder that appends to a caller-provided
+// buffer without allocating, and a decoder for the same types.
+//
+// The output matches the encoding/json package for the supported types, but
+// unlike encoding/json no encoder state is kept on the heap: the struct layout
+// (field names, tags, offsets) is read directly from the type information
+// emitted by the compiler. When the buffer passed to MarshalAppend is reused
+// and has enough capacity, encoding a value does not allocate at all.
+//
+// Supported are booleans, integers, floats, strings, structs, arrays, slices,
+// pointers and interfaces containing one of these. Maps, channels, functions
+// and complex numbers are not supported. Types that implement Marshaler or
+// encoding.TextMarshaler are encoded with their MarshalJSON or MarshalText
+// method. Note that these methods usually allocate, and that the output of
+// MarshalJSON is compacted but not validated. The ",string" tag option is only
+// applied to booleans and numbers, and conflicting field names of embedded
+// structs are not resolved as encoding/json does.
+//
+// Unmarshal decodes JSON like encoding/json does, and uses the Unmarshaler and
+// encoding.TextUnmarshaler interfaces. Unlike the encoder it allocates, for
+// example for strings and slices. See Unmarshal for the supported types.
+package json
+
+import (
+	"encoding"
+	"encoding/base64"
+	"math"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Marshaler is the interface implemented by types that can marshal themselves
+// into valid JSON. It is the same as json.Marshaler in encoding/json.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// An UnsupportedTypeError is returned by MarshalAppend when attempting to
+// encode an unsupported value type.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "json: unsupported type: " + e.Type.String()
+}
+
+// An UnsupportedValueError is returned by MarshalAppend when attempting to
+// encode an unsupported value, such as a NaN float.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	return "json: unsupported value: " + e.Str
+}
+
+// A MarshalerError is returned by MarshalAppend when the MarshalJSON or
+// MarshalText method of a value returns an error.
+type MarshalerError struct {
+	Type       reflect.Type
+	Err        error
+	sourceFunc string
+}
+
+func (e *MarshalerError) Error() string {
+	return "json: error calling " + e.sourceFunc + " for type " + e.Type.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *MarshalerError) Unwrap() error { return e.Err }
+
+// MarshalAppend appends the JSON encoding of v to buf and returns the extended
+// buffer. To avoid allocating, pass a pointer to the value to encode and reuse
+// the returned buffer (truncated to zero length) in the next call.
+//
+// On error, the returned buffer may contain a partial encoding.
+func MarshalAppend(buf []byte, v interface{}) ([]byte, error) {
+	return appendValue(buf, reflect.ValueOf(v), false)
+}
+
+// appendValue appends the JSON encoding of v to buf. When quoted is set, the
+// value is encoded as a JSON string, for the ",string" field tag option.
+func appendValue(buf []byte, v reflect.Value, quoted bool) ([]byte, error) {
+	if !v.IsValid() {
+		return append(buf, "null"...), nil
+	}
+	if buf, ok, err := appendMarshaler(buf, v); ok {
+		return buf, err
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if quoted {
+			buf = append(buf, '"')
+		}
+		buf = strconv.AppendBool(buf, v.Bool())
+		if quoted {
+			buf = append(buf, '"')
+		}
+		return buf, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if quoted {
+			buf = append(buf, '"')
+		}
+		buf = strconv.AppendInt(buf, v.Int(), 10)
+		if quoted {
+			buf = append(buf, '"')
+		}
+		return buf, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if quoted {
+			buf = append(buf, '"')
+		}
+		buf = strconv.AppendUint(buf, v.Uint(), 10)
+		if quoted {
+			buf = append(buf, '"')
+		}
+		return buf, nil
+	case reflect.Float32, reflect.Float64:
+		if quoted {
+			buf = append(buf, '"')
+		}
+		var err error
+		buf, err = appendFloat(buf, v)
+		if err != nil {
+			return buf, err
+		}
+		if quoted {
+			buf = append(buf, '"')
+		}
+		return buf, nil
+	case reflect.String:
+		return appendString(buf, v.String()), nil
+	case reflect.Struct:
+		var err error
+		buf = append(buf, '{')
+		buf, _, err = appendFields(buf, v, true)
+		if err != nil {
+			return buf, err
+		}
+		return append(buf, '}'), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(buf, "null"...), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendBytes(buf, v), nil
+		}
+		return appendArray(buf, v)
+	case reflect.Array:
+		return appendArray(buf, v)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return append(buf, "null"...), nil
+		}
+		return appendValue(buf, v.Elem(), quoted)
+	default:
+		return buf, &UnsupportedTypeError{v.Type()}
+	}
+}
+
+// appendMarshaler appends v using its MarshalJSON or MarshalText method, if it
+// has one. The returned bool is false if neither method is implemented. Like
+// encoding/json, methods with a pointer receiver are also used for addressable
+// values, and nil pointers are encoded as null without calling the method.
+func appendMarshaler(buf []byte, v reflect.Value) ([]byte, bool, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		// The dynamic value is checked by appendValue.
+		return buf, false, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return buf, false, nil
+		}
+	}
+	if !v.CanInterface() {
+		return buf, false, nil
+	}
+	m := v.Interface()
+	if _, ok := m.(Marshaler); !ok {
+		if _, ok := m.(encoding.TextMarshaler); !ok && v.Kind() != reflect.Ptr && v.CanAddr() {
+			m = v.Addr().Interface()
+		}
+	}
+	switch m := m.(type) {
+	case Marshaler:
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return buf, true, &MarshalerError{v.Type(), err, "MarshalJSON"}
+		}
+		return appendCompact(buf, b), true, nil
+	case encoding.TextMarshaler:
+		b, err := m.MarshalText()
+		if err != nil {
+			return buf, true, &MarshalerError{v.Type(), err, "MarshalText"}
+		}
+		return appendString(buf, string(b)), true, nil
+	}
+	return buf, false, nil
+}
+
+// appendCompact appends the JSON text src without insignificant whitespace.
+// Like encoding/json, the HTML characters <, > and & and the characters U+2028
+// and U+2029 are escaped inside strings.
+func appendCompact(buf, src []byte) []byte {
+	inString := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if !inString {
+			switch c {
+			case ' ', '\t', '\n', '\r':
+				continue
+			case '"':
+				inString = true
+			}
+			buf = append(buf, c)
+			continue
+		}
+		switch {
+		case c == '\\' && i+1 < len(src):
+			buf = append(buf, c, src[i+1])
+			i++
+		case c == '"':
+			inString = false
+			buf = append(buf, c)
+		case c == '<' || c == '>' || c == '&':
+			buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		case c == 0xe2 && i+2 < len(src) && src[i+1] == 0x80 && src[i+2]&^1 == 0xa8:
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[src[i+2]&0xf])
+			i += 2
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// appendFields appends the fields of the struct v as JSON object members. The
+// fields of embedded structs are included in the parent object. It returns
+// whether the next member is the first in the object, so that members can be
+// separated with commas.
+func appendFields(buf []byte, v reflect.Value, first bool) ([]byte, bool, error) {
+	t := v.Type()
+	numField := t.NumField()
+	for i := 0; i < numField; i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+		fieldValue := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			// Embedded struct (or pointer to struct) without a name in the tag:
+			// include the fields in the parent object.
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				var err error
+				buf, first, err = appendFields(buf, embedded, first)
+				if err != nil {
+					return buf, first, err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		if hasOption(opts, "omitempty") && isEmptyValue(fieldValue) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		buf = appendString(buf, name)
+		buf = append(buf, ':')
+		var err error
+		buf, err = appendValue(buf, fieldValue, hasOption(opts, "string"))
+		if err != nil {
+			return buf, first, err
+		}
+	}
+	return buf, first, nil
+}
+
+// appendArray appends the elements of the array or slice v as a JSON array.
+func appendArray(buf []byte, v reflect.Value) ([]byte, error) {
+	buf = append(buf, '[')
+	n := v.Len()
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf = append(buf, ',')
+		}
+		var err error
+		buf, err = appendValue(buf, v.Index(i), false)
+		if err != nil {
+			return buf, err
+		}
+	}
+	return append(buf, ']'), nil
+}
+
+// appendBytes appends the byte slice v as a base64 encoded JSON string, like
+// encoding/json does.
+func appendBytes(buf []byte, v reflect.Value) []byte {
+	buf = append(buf, '"')
+	n := v.Len()
+	var chunk [3]byte
+	var encoded [4]byte
+	for i := 0; i < n; i += 3 {
+		length := n - i
+		if length > 3 {
+			length = 3
+		}
+		for j := 0; j < length; j++ {
+			chunk[j] = uint8(v.Index(i + j).Uint())
+		}
+		base64.StdEncoding.Encode(encoded[:], chunk[:length])
+		buf = append(buf, encoded[:]...)
+	}
+	return append(buf, '"')
+}
+
+// appendFloat appends the float v in the same format as encoding/json.
+func appendFloat(buf []byte, v reflect.Value) ([]byte, error) {
+	f := v.Float()
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return buf, &UnsupportedValueError{v, strconv.FormatFloat(f, 'g', -1, v.Type().Bits())}
+	}
+
+	// Use the shortest representation, but switch to exponential notation for
+	// very small and very large numbers, like ES6 does.
+	bits := v.Type().Bits()
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, nil
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends s as a JSON string. Like encoding/json, the HTML
+// characters <, > and & are escaped, as are U+2028 and U+2029. Invalid UTF-8
+// is replaced with U+FFFD.
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '\\', '"':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// isEmptyValue returns whether v is empty for the purpose of the omitempty
+// option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// parseTag splits a struct field's json tag into its name and the
+// comma-separated options.
+func parseTag(tag string) (name, opts string) {
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			return tag[:i], tag[i+1:]
+		}
+	}
+	return tag, ""
+}
+
+// hasOption returns whether the comma-separated list of options contains the
+// given option.
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts = parseTag(opts)
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"math"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"tinygo/json"
+)
+
+type position struct {
+	Lat, Lon float64
+}
+
+type Header struct {
+	Version uint8  `json:"v"`
+	Device  string `json:"device"`
+}
+
+type telemetry struct {
+	Header
+	Sequence    uint32   `json:"seq"`
+	Temperature float32  `json:"temp"`
+	Humidity    float64  `json:"humidity,omitempty"`
+	Pressure    int64    `json:"pressure,string"`
+	Online      bool     `json:"online"`
+	Position    position `json:"pos"`
+	Previous    *position
+	Readings    [3]int16 `json:"readings"`
+	Tags        []string `json:"tags"`
+	Raw         []byte   `json:"raw"`
+	Note        string   `json:"note,omitempty"`
+	Extra       interface{}
+	Ignored     int `json:"-"`
+	internal    int
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		Header:      Header{Version: 2, Device: "sensor <1> & \"co\"\n\u2028\xff"},
+		Sequence:    12345,
+		Temperature: 21.5,
+		Pressure:    101325,
+		Online:      true,
+		Position:    position{Lat: 52.0907, Lon: 5.1214},
+		Readings:    [3]int16{-1, 0, 1},
+		Tags:        []string{"a", "b\tc"},
+		Raw:         []byte{0, 1, 2, 3, 4, 250},
+		Extra:       1e-7,
+		internal:    5,
+	}
+}
+
+func TestMarshalAppend(t *testing.T) {
+	values := []interface{}{
+		newTelemetry(),
+		&telemetry{},
+		true,
+		-42,
+		uint64(math.MaxUint64),
+		float32(3.14),
+		1e21,
+		123456.789,
+		"hello, world",
+		[]int(nil),
+		[]int{},
+		[]byte("hi"),
+		(*position)(nil),
+		&struct{ Value interface{} }{0},
+	}
+	for _, v := range values {
+		expected, err := stdjson.Marshal(v)
+		if err != nil {
+			t.Fatal("encoding/json:", err)
+		}
+		actual, err := json.MarshalAppend(nil, v)
+		if err != nil {
+			t.Errorf("failed to encode %s: %v", expected, err)
+			continue
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("unexpected output\nexpected: %s\nactual:   %s", expected, actual)
+		}
+	}
+}
+
+// level implements encoding.TextMarshaler and encoding.TextUnmarshaler.
+type level int
+
+var levelNames = []string{"low", "medium", "high <3>"}
+
+func (l level) MarshalText() ([]byte, error) {
+	if l < 0 || int(l) >= len(levelNames) {
+		return nil, errors.New("invalid level")
+	}
+	return []byte(levelNames[l]), nil
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	for i, name := range levelNames {
+		if name == string(text) {
+			*l = level(i)
+			return nil
+		}
+	}
+	return errors.New("unknown level " + string(text))
+}
+
+// point implements json.Marshaler with a pointer receiver.
+type point struct {
+	X, Y int
+}
+
+func (p *point) MarshalJSON() ([]byte, error) {
+	return []byte(`[ ` + strconv.Itoa(p.X) + `, ` + strconv.Itoa(p.Y) + ` , "<\u2028\"\\>" ]`), nil
+}
+
+type alarm struct {
+	Level    level       `json:"level"`
+	Previous *level      `json:"previous"`
+	Levels   []level     `json:"levels"`
+	Location point       `json:"location"`
+	Origin   *point      `json:"origin"`
+	Value    interface{} `json:"value"`
+}
+
+func TestMarshalAppendMarshaler(t *testing.T) {
+	previous := level(1)
+	values := []interface{}{
+		level(2),
+		&alarm{
+			Level:    2,
+			Previous: &previous,
+			Levels:   []level{0, 1, 2},
+			Location: point{3, 4},
+			Origin:   &point{-1, 0},
+			Value:    level(0),
+		},
+		&alarm{},
+		alarm{Location: point{5, 6}}, // not addressable: MarshalJSON isn't used
+	}
+	for _, v := range values {
+		expected, err := stdjson.Marshal(v)
+		if err != nil {
+			t.Fatal("encoding/json:", err)
+		}
+		actual, err := json.MarshalAppend(nil, v)
+		if err != nil {
+			t.Errorf("failed to encode %s: %v", expected, err)
+			continue
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("unexpected output\nexpected: %s\nactual:   %s", expected, actual)
+		}
+	}
+}
+
+type levelSettings struct {
+	Level    level
+	Previous *level
+	Levels   []level
+}
+
+func TestMarshalAppendTextRoundTrip(t *testing.T) {
+	previous := level(0)
+	in := levelSettings{Level: 2, Previous: &previous, Levels: []level{1, 2}}
+	data, err := json.MarshalAppend(nil, &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unmarshal := range []func([]byte, interface{}) error{stdjson.Unmarshal, json.Unmarshal} {
+		var out levelSettings
+		if err := unmarshal(data, &out); err != nil {
+			t.Fatalf("could not decode %s: %v", data, err)
+		}
+		if out.Level != in.Level || out.Previous == nil || *out.Previous != *in.Previous || len(out.Levels) != 2 || out.Levels[0] != 1 || out.Levels[1] != 2 {
+			t.Errorf("round trip through %s: got %+v, want %+v", data, out, in)
+		}
+	}
+}
+
+func TestMarshalAppendErrors(t *testing.T) {
+	if _, err := json.MarshalAppend(nil, math.NaN()); err == nil {
+		t.Error("expected an error when encoding NaN")
+	}
+	if _, err := json.MarshalAppend(nil, map[string]int{}); err == nil {
+		t.Error("expected an error when encoding a map")
+	}
+	_, err := json.MarshalAppend(nil, []level{1, 5})
+	var marshalerErr *json.MarshalerError
+	if !errors.As(err, &marshalerErr) || err.Error() != "json: error calling MarshalText for type json_test.level: invalid level" {
+		t.Errorf("unexpected error for an invalid level: %v", err)
+	}
+}
+
+func TestMarshalAppendAllocs(t *testing.T) {
+	v := newTelemetry()
+	buf, err := json.MarshalAppend(nil, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The buffer is now big enough, so encoding again should not allocate.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 100; i++ {
+		buf, err = json.MarshalAppend(buf[:0], v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	runtime.ReadMemStats(&after)
+	if allocs := after.Mallocs - before.Mallocs; allocs != 0 {
+		t.Errorf("expected no allocations, got %d", allocs)
+	}
+}
+
+func BenchmarkMarshalAppend(b *testing.B) {
+	v := newTelemetry()
+	buf, _ := json.MarshalAppend(nil, v)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = json.MarshalAppend(buf[:0], v)
+	}
+}
@@ -0,0 +1,104 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package jspromise connects JavaScript promises to goroutines.
+//
+// WebAssembly in the browser has only one thread, shared with the JavaScript
+// event loop. When all goroutines are blocked, the TinyGo scheduler returns
+// control to the browser and is called again when a timer expires or a
+// js.Func is invoked by JavaScript. Await uses this to block the calling
+// goroutine on a promise: while it waits the browser keeps handling events,
+// other goroutines keep running, and the goroutine resumes once the promise
+// settles.
+//
+//	resp, err := jspromise.Await(js.Global().Call("fetch", "/data.json"))
+//	if err != nil {
+//		return err
+//	}
+//	text, err := jspromise.Await(resp.Call("text"))
+//
+// A js.Func callback is called synchronously by JavaScript, so it must not
+// block: JavaScript only sees its return value once it has returned. Callbacks
+// that need to wait on something (for example by calling Await) should use New
+// to return a promise instead, and do the blocking work in its function.
+package jspromise
+
+import "syscall/js"
+
+// RejectedError is returned by Await when the promise was rejected.
+type RejectedError struct {
+	// Reason is the value the promise was rejected with, usually a JavaScript
+	// Error object.
+	Reason js.Value
+}
+
+func (e *RejectedError) Error() string {
+	if e.Reason.Type() == js.TypeObject && e.Reason.Get("message").Type() == js.TypeString {
+		return "promise rejected: " + e.Reason.Get("message").String()
+	}
+	return "promise rejected: " + e.Reason.String()
+}
+
+type result struct {
+	value    js.Value
+	rejected bool
+}
+
+// Await blocks the calling goroutine until the promise p settles, and returns
+// the value it was fulfilled with. If p was rejected, a *RejectedError holding
+// the reason is returned. Values that are not a promise (or another thenable)
+// are returned as-is, like the JavaScript await operator does.
+func Await(p js.Value) (js.Value, error) {
+	if p.Type() != js.TypeObject || p.Get("then").Type() != js.TypeFunction {
+		return p, nil
+	}
+
+	ch := make(chan result, 1)
+	onFulfilled := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- result{value: arg(args)}
+		return nil
+	})
+	defer onFulfilled.Release()
+	onRejected := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- result{value: arg(args), rejected: true}
+		return nil
+	})
+	defer onRejected.Release()
+	p.Call("then", onFulfilled, onRejected)
+
+	r := <-ch
+	if r.rejected {
+		return js.Undefined(), &RejectedError{Reason: r.value}
+	}
+	return r.value, nil
+}
+
+// New returns a JavaScript promise that is settled with the result of fn,
+// which is called in a new goroutine and may block. The promise is fulfilled
+// with the returned value (converted with js.ValueOf) if the error is nil, and
+// rejected with a JavaScript Error containing the error message otherwise.
+func New(fn func() (interface{}, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		executor.Release()
+		resolve, reject := args[0], args[1]
+		go func() {
+			value, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(value)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// arg returns the first argument passed to a callback, or undefined.
+func arg(args []js.Value) js.Value {
+	if len(args) == 0 {
+		return js.Undefined()
+	}
+	return args[0]
+}
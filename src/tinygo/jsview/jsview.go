@@ -0,0 +1,71 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package jsview exposes Go slices to JavaScript as typed arrays, without
+// copying them.
+//
+// js.CopyBytesToJS and js.CopyBytesToGo copy the data every time they are
+// called, which is expensive for large buffers that are passed to JavaScript
+// often, like a framebuffer drawn to a canvas on every frame. The functions in
+// this package instead return a typed array that is a view on the WebAssembly
+// memory backing the slice: creating one takes the same (short) time no matter
+// how big the slice is, and changes made on either side are immediately
+// visible on the other side.
+//
+// A view is only valid for a limited time, so it should be created right
+// before it is passed to JavaScript and not be stored there:
+//
+//   - JavaScript does not keep the Go slice alive. The slice must stay
+//     referenced from Go (for example with runtime.KeepAlive) for as long as
+//     the view is used, or the memory may be reused for something else.
+//   - When the WebAssembly memory grows, which can happen on any heap
+//     allocation, the old memory buffer is detached and all views on it
+//     become empty (with a length of zero).
+package jsview
+
+import (
+	"syscall/js"
+	"unsafe"
+)
+
+type ref uint64
+
+//go:linkname makeValue syscall/js.makeValue
+func makeValue(r ref) js.Value
+
+// newView calls the JavaScript typed array constructor with the given name on
+// the WebAssembly memory buffer, starting at ptr and with the given number of
+// elements.
+//
+//export tinygo/jsview.newView
+func newView(constructor string, ptr unsafe.Pointer, length uintptr) ref
+
+// Uint8Array returns a JavaScript Uint8Array that shares its memory with b.
+func Uint8Array(b []byte) js.Value {
+	if len(b) == 0 {
+		return view("Uint8Array", nil, 0)
+	}
+	return view("Uint8Array", unsafe.Pointer(&b[0]), len(b))
+}
+
+// Uint8ClampedArray returns a JavaScript Uint8ClampedArray that shares its
+// memory with b. This is the array type used by ImageData for canvas pixels.
+func Uint8ClampedArray(b []byte) js.Value {
+	if len(b) == 0 {
+		return view("Uint8ClampedArray", nil, 0)
+	}
+	return view("Uint8ClampedArray", unsafe.Pointer(&b[0]), len(b))
+}
+
+// Float32Array returns a JavaScript Float32Array that shares its memory with
+// f, for example to pass vertex data to WebGL.
+func Float32Array(f []float32) js.Value {
+	if len(f) == 0 {
+		return view("Float32Array", nil, 0)
+	}
+	return view("Float32Array", unsafe.Pointer(&f[0]), len(f))
+}
+
+func view(constructor string, ptr unsafe.Pointer, length int) js.Value {
+	return makeValue(newView(constructor, ptr, uintptr(length)))
+}
@@ -0,0 +1,216 @@
+// Package ringlog implements a logger that keeps the most recent messages in a
+// fixed-size ring buffer in memory, for example to be read out later over a
+// serial port or a debugger.
+//
+// All memory is allocated when the logger is created: logging a message does
+// not allocate, it only copies the message into the ring buffer, overwriting
+// the oldest message when the buffer is full. Messages that are longer than
+// the maximum message size are truncated.
+//
+// A Logger implements io.Writer, storing each write as a separate message.
+// This makes it possible to use it as the output of a log.Logger:
+//
+//	rl := ringlog.New(32, 80)
+//	logger := log.New(rl, "", 0)
+//
+// Note that formatting log messages with the log or fmt packages usually
+// allocates. To avoid this, pass constant strings to Log (or Info, Warn, etc),
+// or format messages into a reused buffer and pass it to LogBytes.
+//
+// Messages may be logged from interrupts: interrupts are disabled while a
+// message is copied into the ring buffer.
+package ringlog
+
+import (
+	"io"
+	"runtime/interrupt"
+)
+
+// Level is the severity of a log message.
+type Level uint8
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the name of the level, like "INFO".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "LEVEL?"
+	}
+}
+
+// Logger stores log messages in a ring buffer. Use New to create one.
+type Logger struct {
+	data    []byte   // message storage: one slot of size bytes per message
+	lengths []uint16 // length of the message in each slot
+	levels  []Level  // level of the message in each slot
+	size    int      // maximum message size
+	next    int      // slot for the next message
+	count   int      // number of messages in the buffer
+	dropped uint32   // number of messages that were overwritten
+	level   Level    // minimum level of messages to store
+}
+
+// New returns a logger that holds the last n messages of up to size bytes
+// each. All messages are stored, use SetLevel to ignore less severe messages.
+func New(n, size int) *Logger {
+	if n <= 0 || size <= 0 || size > 0xffff {
+		panic("ringlog: invalid buffer size")
+	}
+	return &Logger{
+		data:    make([]byte, n*size),
+		lengths: make([]uint16, n),
+		levels:  make([]Level, n),
+		size:    size,
+	}
+}
+
+// SetLevel sets the minimum level of messages to store. Less severe messages
+// are ignored.
+func (l *Logger) SetLevel(level Level) {
+	l.level = level
+}
+
+// Log stores a message with the given level.
+func (l *Logger) Log(level Level, msg string) {
+	if level < l.level {
+		return
+	}
+	state := interrupt.Disable()
+	n := copy(l.slot(l.next), msg)
+	l.store(level, n)
+	interrupt.Restore(state)
+}
+
+// LogBytes stores a message with the given level. The message is copied, so
+// msg may be reused after LogBytes returns.
+func (l *Logger) LogBytes(level Level, msg []byte) {
+	if level < l.level {
+		return
+	}
+	state := interrupt.Disable()
+	n := copy(l.slot(l.next), msg)
+	l.store(level, n)
+	interrupt.Restore(state)
+}
+
+// Debug stores a message with LevelDebug.
+func (l *Logger) Debug(msg string) {
+	l.Log(LevelDebug, msg)
+}
+
+// Info stores a message with LevelInfo.
+func (l *Logger) Info(msg string) {
+	l.Log(LevelInfo, msg)
+}
+
+// Warn stores a message with LevelWarn.
+func (l *Logger) Warn(msg string) {
+	l.Log(LevelWarn, msg)
+}
+
+// Error stores a message with LevelError.
+func (l *Logger) Error(msg string) {
+	l.Log(LevelError, msg)
+}
+
+// Write stores p as a message with LevelInfo, without the trailing newline if
+// there is one. It always returns len(p) and a nil error.
+func (l *Logger) Write(p []byte) (int, error) {
+	msg := p
+	if len(msg) != 0 && msg[len(msg)-1] == '\n' {
+		msg = msg[:len(msg)-1]
+	}
+	l.LogBytes(LevelInfo, msg)
+	return len(p), nil
+}
+
+// slot returns the storage for the message in the given slot.
+func (l *Logger) slot(index int) []byte {
+	return l.data[index*l.size : (index+1)*l.size]
+}
+
+// store finishes storing a message of n bytes in the next slot. It must be
+// called with interrupts disabled.
+func (l *Logger) store(level Level, n int) {
+	l.lengths[l.next] = uint16(n)
+	l.levels[l.next] = level
+	l.next++
+	if l.next == len(l.lengths) {
+		l.next = 0
+	}
+	if l.count == len(l.lengths) {
+		l.dropped++
+	} else {
+		l.count++
+	}
+}
+
+// Len returns the number of messages in the buffer.
+func (l *Logger) Len() int {
+	return l.count
+}
+
+// Dropped returns the number of messages that were overwritten by newer
+// messages since the logger was created or last reset.
+func (l *Logger) Dropped() uint32 {
+	return l.dropped
+}
+
+// Reset removes all messages from the buffer.
+func (l *Logger) Reset() {
+	state := interrupt.Disable()
+	l.next = 0
+	l.count = 0
+	l.dropped = 0
+	interrupt.Restore(state)
+}
+
+// Each calls fn for each message in the buffer, from oldest to newest. The msg
+// slice points into the ring buffer and is only valid during the call: don't
+// log new messages from fn.
+func (l *Logger) Each(fn func(level Level, msg []byte)) {
+	first := l.next - l.count
+	if first < 0 {
+		first += len(l.lengths)
+	}
+	for i := 0; i < l.count; i++ {
+		index := (first + i) % len(l.lengths)
+		fn(l.levels[index], l.slot(index)[:l.lengths[index]])
+	}
+}
+
+// WriteTo writes all messages in the buffer to w, from oldest to newest, one
+// per line and prefixed with the level. For example:
+//
+//	INFO starting
+//	WARN low battery
+func (l *Logger) WriteTo(w io.Writer) (n int64, err error) {
+	var line []byte
+	l.Each(func(level Level, msg []byte) {
+		if err != nil {
+			return
+		}
+		line = append(line[:0], level.String()...)
+		line = append(line, ' ')
+		line = append(line, msg...)
+		line = append(line, '\n')
+		var written int
+		written, err = w.Write(line)
+		n += int64(written)
+	})
+	return n, err
+}
@@ -0,0 +1,132 @@
+package ringlog_test
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"tinygo/ringlog"
+)
+
+// messages returns all messages in the logger, formatted as "LEVEL msg".
+func messages(l *ringlog.Logger) []string {
+	var msgs []string
+	l.Each(func(level ringlog.Level, msg []byte) {
+		msgs = append(msgs, level.String()+" "+string(msg))
+	})
+	return msgs
+}
+
+func checkMessages(t *testing.T, l *ringlog.Logger, want ...string) {
+	t.Helper()
+	got := messages(l)
+	if len(got) != len(want) || l.Len() != len(want) {
+		t.Fatalf("got %d messages (Len %d), want %d: %q", len(got), l.Len(), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWraparound(t *testing.T) {
+	l := ringlog.New(3, 16)
+	checkMessages(t, l)
+
+	l.Info("one")
+	l.Warn("two")
+	checkMessages(t, l, "INFO one", "WARN two")
+
+	l.Error("three")
+	l.Debug("four")
+	l.Info("five")
+	checkMessages(t, l, "ERROR three", "DEBUG four", "INFO five")
+	if l.Dropped() != 2 {
+		t.Errorf("Dropped: got %d, want 2", l.Dropped())
+	}
+
+	// Wrap around many times.
+	for i := 0; i < 100; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+	checkMessages(t, l, "INFO 97", "INFO 98", "INFO 99")
+
+	l.Reset()
+	checkMessages(t, l)
+	l.Info("after reset")
+	checkMessages(t, l, "INFO after reset")
+}
+
+func TestTruncate(t *testing.T) {
+	l := ringlog.New(2, 5)
+	l.Info("hello, world")
+	l.LogBytes(ringlog.LevelWarn, []byte("hi"))
+	checkMessages(t, l, "INFO hello", "WARN hi")
+}
+
+func TestLevel(t *testing.T) {
+	l := ringlog.New(4, 16)
+	l.SetLevel(ringlog.LevelWarn)
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+	checkMessages(t, l, "WARN warn", "ERROR error")
+}
+
+func TestWriteTo(t *testing.T) {
+	l := ringlog.New(2, 32)
+	l.Info("starting")
+	l.Warn("low battery")
+	var buf bytes.Buffer
+	n, err := l.WriteTo(&buf)
+	if err != nil {
+		t.Fatal("WriteTo:", err)
+	}
+	const want = "INFO starting\nWARN low battery\n"
+	if buf.String() != want || n != int64(len(want)) {
+		t.Errorf("WriteTo: got %q (%d bytes), want %q", buf.String(), n, want)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	l := ringlog.New(2, 32)
+	logger := log.New(l, "app: ", 0)
+	logger.Println("first")
+	logger.Printf("value %d", 42)
+	checkMessages(t, l, "INFO app: first", "INFO app: value 42")
+}
+
+func TestAllocs(t *testing.T) {
+	l := ringlog.New(8, 32)
+	scratch := make([]byte, 0, 32)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 1000; i++ {
+		l.Info("tick")
+		// Note: strconv.AppendInt may allocate in TinyGo.
+		scratch = append(append(scratch[:0], "digit "...), byte('0'+i%10))
+		l.LogBytes(ringlog.LevelDebug, scratch)
+	}
+	runtime.ReadMemStats(&after)
+	if allocs := after.Mallocs - before.Mallocs; allocs != 0 {
+		t.Errorf("expected no allocations, got %d", allocs)
+	}
+	checkMessages(t, l,
+		"INFO tick", "DEBUG digit 6",
+		"INFO tick", "DEBUG digit 7",
+		"INFO tick", "DEBUG digit 8",
+		"INFO tick", "DEBUG digit 9")
+}
+
+func BenchmarkLog(b *testing.B) {
+	l := ringlog.New(16, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message")
+	}
+}
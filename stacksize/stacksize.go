@@ -323,3 +323,29 @@ func (node *CallNode) determineStackSize(parents map[*CallNode]struct{}) {
 		panic("unknown frame size type") // unreachable
 	}
 }
+
+// WorstCasePath returns the chain of calls that uses the most stack space,
+// starting at this node and ending at a leaf function. The sum of the frame
+// sizes along the path is the stack size returned by StackSize. It returns nil
+// if the stack size is not bounded.
+func (node *CallNode) WorstCasePath() []*CallNode {
+	if _, sizeType, _ := node.StackSize(); sizeType != Bounded {
+		return nil
+	}
+	path := []*CallNode{node}
+	for {
+		var deepest *CallNode
+		for _, child := range node.Children {
+			// All children have a bounded stack size, or this node wouldn't
+			// have one.
+			if deepest == nil || child.stackSize > deepest.stackSize {
+				deepest = child
+			}
+		}
+		if deepest == nil {
+			return path
+		}
+		path = append(path, deepest)
+		node = deepest
+	}
+}
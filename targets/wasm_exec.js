@@ -452,6 +452,12 @@
 						setInt64(num_bytes_copied_addr, toCopy.length);
 						mem().setUint8(returned_status_addr, 1); // Return "ok" status
 					},
+
+					// func newView(constructor string, ptr unsafe.Pointer, length uintptr) ref
+					"tinygo/jsview.newView": (ret_addr, constructor_ptr, constructor_len, ptr, length) => {
+						const constructor = global[loadString(constructor_ptr, constructor_len)];
+						storeValue(ret_addr, new constructor(this._inst.exports.memory.buffer, ptr, length));
+					},
 				}
 			};
 		}
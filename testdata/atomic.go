@@ -1,6 +1,8 @@
 package main
 
 import (
+	"runtime"
+	"sync"
 	"sync/atomic"
 	"unsafe"
 
@@ -86,6 +88,10 @@ func main() {
 
 	// Test atomic and volatile operations as deferred values.
 	testDefer()
+
+	// Test the generic atomic.Pointer type.
+	testPointer()
+	testPointerInterleaved()
 }
 
 func testValue(values ...interface{}) {
@@ -109,3 +115,58 @@ func testDefer() {
 	defer atomic.AddInt32(&n1, 3)
 	defer volatile.StoreUint32(&n2, 22)
 }
+
+type pointerNode struct {
+	value int
+	next  *pointerNode
+}
+
+func testPointer() {
+	var p atomic.Pointer[pointerNode]
+	println("Pointer.Load (zero):", p.Load() == nil)
+
+	a := &pointerNode{value: 1}
+	b := &pointerNode{value: 2}
+	p.Store(a)
+	println("Pointer.Store:", p.Load() == a, p.Load().value)
+	println("Pointer.Swap:", p.Swap(b) == a, p.Load().value)
+	println("Pointer.CompareAndSwap:", p.CompareAndSwap(a, a), p.Load().value)
+	println("Pointer.CompareAndSwap:", p.CompareAndSwap(b, a), p.Load().value)
+}
+
+// testPointerInterleaved pushes nodes onto a lock-free stack from multiple
+// goroutines. The goroutines don't run in parallel, but yielding between the
+// load and the compare-and-swap interleaves them so that some of the
+// compare-and-swap operations fail and have to be retried.
+func testPointerInterleaved() {
+	const goroutines = 4
+	const pushes = 25
+	var head atomic.Pointer[pointerNode]
+	var retries int32
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func(i int) {
+			for j := 0; j < pushes; j++ {
+				n := &pointerNode{value: i*pushes + j}
+				for {
+					n.next = head.Load()
+					runtime.Gosched()
+					if head.CompareAndSwap(n.next, n) {
+						break
+					}
+					atomic.AddInt32(&retries, 1)
+				}
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	count, sum := 0, 0
+	for n := head.Load(); n != nil; n = n.next {
+		count++
+		sum += n.value
+	}
+	println("Pointer interleaved push:", count, sum, atomic.LoadInt32(&retries) > 0)
+}
@@ -0,0 +1,59 @@
+package main
+
+import "bufio"
+
+// A writer that records every write it gets, like a UART that sends each
+// write as a separate transfer.
+type recorder struct {
+	data   []byte
+	writes int
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	println("  write:", string(p))
+	r.data = append(r.data, p...)
+	r.writes++
+	return len(p), nil
+}
+
+func main() {
+	rec := &recorder{}
+	w := bufio.NewWriterSize(rec, 4)
+	println("size:", w.Size())
+
+	// Small writes are collected until the buffer is full.
+	println("write ab")
+	w.WriteString("ab")
+	println("write cd")
+	w.WriteString("cd")
+	println("buffered:", w.Buffered(), "available:", w.Available())
+	println("write e")
+	w.WriteString("e")
+	println("buffered:", w.Buffered(), "available:", w.Available())
+
+	// A write that doesn't fit fills up the buffer first.
+	println("write fghij")
+	w.WriteString("fghij")
+	println("buffered:", w.Buffered())
+
+	// Writes larger than the buffer go straight through when it is empty.
+	println("flush")
+	w.Flush()
+	println("buffered:", w.Buffered(), "available:", w.Available())
+	println("write klmnopq")
+	w.Write([]byte("klmnopq"))
+	println("buffered:", w.Buffered())
+
+	// A write of exactly the buffer size.
+	println("write rstu")
+	w.WriteString("rstu")
+	println("buffered:", w.Buffered())
+	println("write byte v")
+	w.WriteByte('v')
+	println("flush")
+	w.Flush()
+	println("flush empty")
+	w.Flush()
+
+	println("result:", string(rec.data), rec.writes)
+}
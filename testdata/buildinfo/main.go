@@ -0,0 +1,25 @@
+package main
+
+import "runtime/debug"
+
+func main() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		println("no build info")
+		return
+	}
+	println("path:", info.Path)
+	println("main module:", info.Main.Path, info.Main.Version)
+	println("has go version:", len(info.GoVersion) > 2 && info.GoVersion[:2] == "go")
+
+	var goos, goarch bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "GOOS":
+			goos = setting.Value != ""
+		case "GOARCH":
+			goarch = setting.Value != ""
+		}
+	}
+	println("has GOOS and GOARCH:", goos, goarch)
+}
@@ -0,0 +1,5 @@
+package dep
+
+func Hello() string {
+	return "hello from dep"
+}
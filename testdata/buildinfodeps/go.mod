@@ -0,0 +1,7 @@
+module example.com/buildinfo
+
+go 1.18
+
+require example.com/dep v1.2.3
+
+replace example.com/dep => ./dep
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"runtime/debug"
+
+	"example.com/dep"
+)
+
+func main() {
+	println(dep.Hello())
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		println("no build info")
+		return
+	}
+	println("path:", info.Path)
+	println("main module:", info.Main.Path, info.Main.Version)
+	for _, m := range info.Deps {
+		println("dep:", m.Path, m.Version, m.Sum == "")
+		if m.Replace != nil {
+			println("replaced by:", m.Replace.Path, m.Replace.Version)
+		}
+	}
+}
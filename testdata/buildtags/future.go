@@ -0,0 +1,8 @@
+//go:build tinygo0.999
+
+package main
+
+// Not included: this release doesn't exist yet.
+func init() {
+	included = append(included, "future.go")
+}
@@ -0,0 +1,12 @@
+package main
+
+// Test that the tinygo build tag and the TinyGo release tags (like
+// tinygo0.26) can be used in build constraints.
+
+var included []string
+
+func main() {
+	for _, file := range included {
+		println("included:", file)
+	}
+}
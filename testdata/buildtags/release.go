@@ -0,0 +1,8 @@
+//go:build tinygo0.26
+
+package main
+
+// TinyGo 0.26 and later.
+func init() {
+	included = append(included, "release.go")
+}
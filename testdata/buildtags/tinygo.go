@@ -0,0 +1,7 @@
+//go:build tinygo
+
+package main
+
+func init() {
+	included = append(included, "tinygo.go")
+}
@@ -0,0 +1,15 @@
+// C program that calls the functions exported from carchive.go.
+
+#include <stdio.h>
+#include "carchive.h"
+
+int main(void) {
+	int32_t values[] = {1, 2, 3, 4};
+	printf("initialized: %d\n", isInitialized());
+	printf("add: %d\n", add(3, 4));
+	printf("scale: %.1f\n", scale(2.5, 4));
+	printf("sum: %lld\n", (long long)sum(values, 4));
+	fflush(stdout);
+	allocate(1000);
+	return 0;
+}
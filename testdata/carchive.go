@@ -0,0 +1,51 @@
+package main
+
+// Exported functions for the C program in carchive.c, built with
+// -buildmode=c-archive.
+
+import "unsafe"
+
+var initialized bool
+
+func init() {
+	initialized = true
+}
+
+//export add
+func add(a, b int32) int32 {
+	return a + b
+}
+
+//export scale
+func scale(x float64, factor float32) float64 {
+	return x * float64(factor)
+}
+
+//export isInitialized
+func isInitialized() bool {
+	return initialized
+}
+
+//export sum
+func sum(values *int32, n uintptr) int64 {
+	var total int64
+	for _, v := range unsafe.Slice(values, n) {
+		total += int64(v)
+	}
+	return total
+}
+
+//export allocate
+func allocate(n int) {
+	// Allocate some memory to check that the heap and GC work.
+	var buffers [][]byte
+	for i := 0; i < n; i++ {
+		buffers = append(buffers, make([]byte, 1024))
+	}
+	println("allocated buffers:", len(buffers))
+}
+
+func main() {
+	// The main function is not called in a C archive.
+	println("unreachable")
+}
@@ -154,6 +154,40 @@ func main() {
 	close(ch)
 	wg.Wait()
 
+	// Test non-blocking selects with a single case.
+	ch = make(chan int, 1)
+	select {
+	case n := <-ch:
+		println("unreachable:", n)
+	default:
+		println("non-blocking recv: default")
+	}
+	select {
+	case ch <- 7:
+		println("non-blocking send: sent")
+	default:
+		println("unreachable")
+	}
+	select {
+	case ch <- 8:
+		println("unreachable")
+	default:
+		println("non-blocking send: default")
+	}
+	select {
+	case n := <-ch:
+		println("non-blocking recv:", n)
+	default:
+		println("unreachable")
+	}
+	close(ch)
+	select {
+	case n, ok := <-ch:
+		println("non-blocking recv from closed chan:", n, ok)
+	default:
+		println("unreachable")
+	}
+
 	// test non-concurrent buffered channels
 	ch = make(chan int, 2)
 	ch <- 1
@@ -229,6 +263,163 @@ func main() {
 	}
 	wg.Wait()
 	println("blocking select sum:", sum)
+
+	testChannelLen()
+	testSelectFairness()
+}
+
+// testChannelLen checks that len(ch) is the number of values in the buffer
+// after every operation, also when observed from a different goroutine.
+func testChannelLen() {
+	ch := make(chan int, 4)
+	print("len while filling:")
+	for i := 0; i < cap(ch); i++ {
+		print(" ", len(ch))
+		ch <- i
+	}
+	println("", len(ch), "cap:", cap(ch))
+
+	// A failed send doesn't change the length.
+	select {
+	case ch <- 10:
+		println("unreachable")
+	default:
+	}
+	println("len after failed send:", len(ch))
+
+	// Blocked senders don't count, but their values are moved into the buffer
+	// as soon as there is space.
+	wg.Add(1)
+	go func() {
+		ch <- 4
+		ch <- 5
+		wg.Done()
+	}()
+	runtime.Gosched()
+	print("len with blocked sender:", " ", len(ch))
+	<-ch
+	print(" ", len(ch))
+	runtime.Gosched() // let the sender block again
+	<-ch
+	println("", len(ch))
+	wg.Wait()
+
+	print("len while draining:")
+	for len(ch) != 0 {
+		print(" ", len(ch))
+		<-ch
+	}
+	println("", len(ch))
+
+	// Check the length from another goroutine, after every send and receive.
+	step := make(chan bool)
+	lengths := make(chan int)
+	wg.Add(1)
+	go func() {
+		for range step {
+			lengths <- len(ch)
+		}
+		wg.Done()
+	}()
+	print("len from other goroutine:")
+	for i := 0; i < 3; i++ {
+		ch <- i
+		step <- true
+		print(" ", <-lengths)
+	}
+	for i := 0; i < 3; i++ {
+		<-ch
+		step <- true
+		print(" ", <-lengths)
+	}
+	println()
+	close(step)
+	wg.Wait()
+
+	// Values that are still buffered count after the channel is closed.
+	ch <- 1
+	ch <- 2
+	close(ch)
+	print("len after close:", " ", len(ch))
+	<-ch
+	print(" ", len(ch))
+	<-ch
+	print(" ", len(ch))
+	<-ch
+	println("", len(ch), "cap:", cap(ch))
+}
+
+// testSelectFairness checks that when several cases of a select statement are
+// ready, each of them is picked about equally often.
+func testSelectFairness() {
+	const n = 10000
+	balanced := func(count int, cases int) bool {
+		return count > n/cases*8/10 && count < n/cases*12/10
+	}
+
+	// Receive from two closed channels, which are always ready.
+	ch1 := make(chan int)
+	ch2 := make(chan int)
+	close(ch1)
+	close(ch2)
+	var counts [3]int
+	for i := 0; i < n; i++ {
+		select {
+		case <-ch1:
+			counts[0]++
+		case <-ch2:
+			counts[1]++
+		}
+	}
+	println("select fairness (recv):", balanced(counts[0], 2), balanced(counts[1], 2))
+
+	// Send to two buffered channels that always have space.
+	sch1 := make(chan int, 1)
+	sch2 := make(chan int, 1)
+	counts = [3]int{}
+	for i := 0; i < n; i++ {
+		select {
+		case sch1 <- i:
+			<-sch1
+			counts[0]++
+		case sch2 <- i:
+			<-sch2
+			counts[1]++
+		}
+	}
+	println("select fairness (send):", balanced(counts[0], 2), balanced(counts[1], 2))
+
+	// Two ready cases that aren't next to each other, with a default case.
+	var never chan int
+	counts = [3]int{}
+	for i := 0; i < n; i++ {
+		select {
+		case <-ch1:
+			counts[0]++
+		case <-never:
+			counts[1]++
+		case <-ch2:
+			counts[2]++
+		default:
+			println("unreachable")
+		}
+	}
+	println("select fairness (non-adjacent):", balanced(counts[0], 2), counts[1] == 0, balanced(counts[2], 2))
+
+	// Three ready cases.
+	counts = [3]int{}
+	for i := 0; i < n; i++ {
+		select {
+		case <-ch1:
+			counts[0]++
+		case <-ch2:
+			counts[1]++
+		case sch1 <- i:
+			<-sch1
+			counts[2]++
+		}
+	}
+	println("select fairness (3 cases):", balanced(counts[0], 3), balanced(counts[1], 3), balanced(counts[2], 3))
 }
 
 func send(ch chan<- int) {
@@ -0,0 +1,85 @@
+package main
+
+import "machine"
+
+// A fake analog comparator. These functions implement the hooks used by the
+// generic machine package, and setInput simulates the hardware: it signals the
+// comparator interrupt when the input crosses the threshold.
+
+var (
+	compPositive  machine.Pin
+	compNegative  machine.Pin
+	compThreshold uint16
+	compInput     uint16
+	compAbove     bool
+)
+
+//export __tinygo_comparator_configure
+func comparatorConfigure(positive, negative machine.Pin, threshold uint16) {
+	compPositive = positive
+	compNegative = negative
+	compThreshold = threshold
+	compAbove = compInput > compThreshold
+}
+
+//export __tinygo_comparator_get
+func comparatorGet() bool {
+	return compAbove
+}
+
+// comparatorInterrupt is the interrupt handler of the machine package.
+//
+//export __tinygo_comparator_interrupt
+func comparatorInterrupt(above bool)
+
+func setInput(value uint16) {
+	compInput = value
+	above := compInput > compThreshold
+	if above != compAbove {
+		compAbove = above
+		comparatorInterrupt(above)
+	}
+}
+
+const (
+	inputPin     = machine.Pin(2)
+	referencePin = machine.Pin(3)
+)
+
+func main() {
+	comp := machine.Comparator0
+	comp.Configure(machine.ComparatorConfig{
+		PositiveInput: inputPin,
+		NegativeInput: machine.NoPin,
+		Threshold:     0x4000,
+	})
+	println("inputs configured:", compPositive == inputPin, compNegative == machine.NoPin)
+	println("threshold:", compThreshold)
+
+	comp.SetInterrupt(machine.ComparatorToggle, func(above bool) {
+		println("crossing, above:", above)
+	})
+	setInput(0x3000) // below the threshold, no crossing
+	setInput(0x5000)
+	println("get:", comp.Get())
+	setInput(0x6000) // still above, no crossing
+	setInput(0x1000)
+	println("get:", comp.Get())
+
+	// Only rising edges.
+	comp.SetInterrupt(machine.ComparatorRising, func(above bool) {
+		println("rising edge, above:", above)
+	})
+	setInput(0x8000)
+	setInput(0x0000)
+
+	// The default threshold is half the reference.
+	comp.SetInterrupt(machine.ComparatorToggle, nil)
+	comp.Configure(machine.ComparatorConfig{
+		PositiveInput: referencePin,
+		NegativeInput: inputPin,
+	})
+	println("reconfigured:", compPositive == referencePin, compNegative == inputPin, compThreshold)
+	setInput(0x9000) // interrupt disabled
+	println("get:", comp.Get())
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"machine"
+)
+
+// fakeFlash is an in-memory flash chip. Like real flash memory, bytes can only
+// be written once after they have been erased.
+type fakeFlash struct {
+	data       []byte
+	blockSize  int64
+	writeSize  int64
+	erases     []int
+	writesLeft int // fail once this many writes have been done (if > 0)
+}
+
+var errPowerLoss = errors.New("power loss")
+
+func newFakeFlash(blocks int, blockSize, writeSize int64) *fakeFlash {
+	f := &fakeFlash{
+		data:      make([]byte, int64(blocks)*blockSize),
+		blockSize: blockSize,
+		writeSize: writeSize,
+		erases:    make([]int, blocks),
+	}
+	for i := range f.data {
+		f.data[i] = 0xff
+	}
+	return f
+}
+
+func (f *fakeFlash) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, f.data[off:]), nil
+}
+
+func (f *fakeFlash) WriteAt(p []byte, off int64) (int, error) {
+	if off%f.writeSize != 0 || int64(len(p))%f.writeSize != 0 {
+		panic("unaligned write")
+	}
+	if f.writesLeft > 0 {
+		f.writesLeft--
+		if f.writesLeft == 0 {
+			// Only write part of the data, like when the power is cut.
+			p = p[:len(p)/2]
+			copy(f.data[off:], p)
+			return len(p), errPowerLoss
+		}
+	}
+	for i, b := range p {
+		if f.data[off+int64(i)] != 0xff {
+			panic("write to flash that was not erased")
+		}
+		f.data[off+int64(i)] = b
+	}
+	return len(p), nil
+}
+
+func (f *fakeFlash) Size() int64           { return int64(len(f.data)) }
+func (f *fakeFlash) WriteBlockSize() int64 { return f.writeSize }
+func (f *fakeFlash) EraseBlockSize() int64 { return f.blockSize }
+
+func (f *fakeFlash) EraseBlocks(start, len int64) error {
+	for i := start; i < start+len; i++ {
+		f.erases[i]++
+		for j := i * f.blockSize; j < (i+1)*f.blockSize; j++ {
+			f.data[j] = 0xff
+		}
+	}
+	return nil
+}
+
+// reset configures a new EEPROM on the same flash, like after a reset.
+func reset(flash *fakeFlash, size int) *machine.EEPROM {
+	eeprom := &machine.EEPROM{}
+	err := eeprom.Configure(machine.EEPROMConfig{Device: flash, Size: size})
+	if err != nil {
+		println("could not configure EEPROM:", err.Error())
+	}
+	return eeprom
+}
+
+func check(name string, eeprom *machine.EEPROM, expected []byte) {
+	buf := make([]byte, len(expected))
+	n, err := eeprom.ReadAt(buf, 0)
+	if n != len(buf) || err != nil {
+		println(name+": read failed:", n, err)
+		return
+	}
+	println(name+":", string(buf) == string(expected))
+}
+
+func main() {
+	const size = 32
+	flash := newFakeFlash(4, 256, 4)
+	eeprom := reset(flash, size)
+
+	// A new EEPROM is erased.
+	buf := make([]byte, 4)
+	eeprom.ReadAt(buf, 0)
+	println("empty:", buf[0], buf[1], buf[2], buf[3])
+
+	eeprom.WriteAt([]byte("hello"), 3)
+	eeprom.WriteAt([]byte{0}, size-1)
+	n, err := eeprom.ReadAt(buf, 3)
+	println("read:", n, err == nil, string(buf))
+	eeprom = reset(flash, size)
+	n, err = eeprom.ReadAt(buf, 3)
+	println("read after reset:", n, err == nil, string(buf))
+	eeprom.ReadAt(buf[:1], size-1)
+	println("last byte after reset:", buf[0])
+
+	// Reads and writes outside the EEPROM.
+	n, err = eeprom.ReadAt(buf, size-2)
+	println("read past end:", n, err != nil)
+	n, err = eeprom.WriteAt(buf, size-2)
+	println("write past end:", n, err != nil)
+
+	// Write a lot, so that all pages are used several times.
+	expected := make([]byte, size)
+	eeprom.ReadAt(expected, 0)
+	x := uint32(1)
+	for i := 0; i < 2000; i++ {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		addr := int(x % size)
+		value := byte(x >> 8)
+		eeprom.WriteAt([]byte{value}, int64(addr))
+		expected[addr] = value
+		if i%300 == 0 {
+			eeprom = reset(flash, size)
+		}
+	}
+	check("after many writes", eeprom, expected)
+	check("after many writes and reset", reset(flash, size), expected)
+	minErases, maxErases := flash.erases[0], flash.erases[0]
+	for _, n := range flash.erases {
+		if n < minErases {
+			minErases = n
+		}
+		if n > maxErases {
+			maxErases = n
+		}
+	}
+	println("all pages erased:", minErases > 10, "wear spread:", maxErases-minErases <= 1)
+
+	// Lose power while writing. The byte being written may be lost, but the
+	// rest of the data must be intact.
+	for writes := 1; writes < 40; writes += 7 {
+		eeprom = reset(flash, size)
+		eeprom.ReadAt(expected, 0)
+		flash.writesLeft = writes
+		for i := 0; i < 100 && flash.writesLeft > 0; i++ {
+			addr := i * 5 % size
+			value := expected[addr] + 1
+			if _, err := eeprom.WriteAt([]byte{value}, int64(addr)); err != nil {
+				break
+			}
+			expected[addr] = value
+		}
+		flash.writesLeft = 0
+		check("after power loss", reset(flash, size), expected)
+	}
+
+	// Configuration errors.
+	println("too large:", (&machine.EEPROM{}).Configure(machine.EEPROMConfig{Device: flash, Size: 64}) != nil)
+	println("too few pages:", (&machine.EEPROM{}).Configure(machine.EEPROMConfig{Device: flash, Size: size, Start: 768}) != nil)
+	println("unaligned:", (&machine.EEPROM{}).Configure(machine.EEPROMConfig{Device: flash, Size: size, Start: 100}) != nil)
+	n, err = (&machine.EEPROM{}).ReadAt(buf, 0)
+	println("not configured:", n, err != nil)
+}
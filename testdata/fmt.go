@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type Celsius float64
+
+type Point struct {
+	X, Y int
+}
+
+type Shape struct {
+	Name   string
+	Origin Point
+	Points []Point
+	Next   *Shape
+	Tags   map[string]int
+	Temp   Celsius
+	hidden bool
+}
+
+type Timed struct {
+	Label string `json:"label"`
+	time.Duration
+}
+
+func main() {
+	s := Shape{
+		Name:   "triangle",
+		Origin: Point{1, 2},
+		Points: []Point{{0, 0}, {3, 4}},
+		Tags:   map[string]int{"sides": 3},
+		Temp:   21.5,
+	}
+
+	// Struct fields with and without names.
+	fmt.Printf("%v\n", s)
+	fmt.Printf("%+v\n", s)
+	fmt.Printf("%#v\n", s)
+
+	// Go syntax representation of other types.
+	fmt.Printf("%#v\n", []int{1, 2})
+	fmt.Printf("%#v\n", [2]bool{true, false})
+	fmt.Printf("%#v\n", map[Point]string{{1, 2}: "a"})
+	fmt.Printf("%#v\n", Timed{Label: "t", Duration: time.Second})
+	fmt.Printf("%#v\n", struct{ A, b int }{1, 2})
+	fmt.Printf("%#v %#v\n", (*Point)(nil), Celsius(3))
+
+	// Type names.
+	fmt.Printf("%T %T %T %T\n", s, &s, s.Points, s.Tags)
+	fmt.Printf("%T %T %T\n", time.Second, struct{}{}, []interface{}{})
+}
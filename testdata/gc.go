@@ -1,5 +1,11 @@
 package main
 
+import (
+	"runtime"
+	"runtime/debug"
+	"unsafe"
+)
+
 var xorshift32State uint32 = 1
 
 func xorshift32(x uint32) uint32 {
@@ -17,6 +23,9 @@ func randuint32() uint32 {
 
 func main() {
 	testNonPointerHeap()
+	testGCPercent()
+	testGCPercentCycles()
+	testInteriorPointer()
 }
 
 var scalarSlices [4][]byte
@@ -64,3 +73,108 @@ func testNonPointerHeap() {
 	}
 	println("ok")
 }
+
+func testGCPercent() {
+	// Run the same test with a more aggressive GC setting.
+	println("gc percent:", debug.SetGCPercent(20))
+	testNonPointerHeap()
+	println("gc percent:", debug.SetGCPercent(100))
+}
+
+// gcCycles returns the number of GC cycles while allocating size bytes of
+// garbage in small chunks.
+func gcCycles(size int) uint32 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	numGC := ms.NumGC
+	for i := 0; i < size/64; i++ {
+		garbage = make([]uint32, 16)
+	}
+	garbage = nil
+	runtime.ReadMemStats(&ms)
+	return ms.NumGC - numGC
+}
+
+func testGCPercentCycles() {
+	// Start with a fresh GC cycle, so that the heap usage is the same for
+	// every test. Allocate a few times the size of the heap, so that the GC
+	// has to run with the default setting.
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	size := 4 * int(ms.HeapSys)
+	cycles100 := gcCycles(size)
+	println("gc percent 100 runs cycles:", cycles100 > 0)
+
+	runtime.GC()
+	debug.SetGCPercent(20)
+	cycles20 := gcCycles(size)
+	println("gc percent 20 runs more cycles:", cycles20 > cycles100)
+
+	// With a large percentage the heap grows instead, if the system allows
+	// it. On baremetal systems the heap can't grow, so the GC still runs
+	// whenever the heap is full.
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	heapSys := ms.HeapSys
+	debug.SetGCPercent(1000)
+	cycles1000 := gcCycles(size)
+	runtime.ReadMemStats(&ms)
+	heapGrown := ms.HeapSys > heapSys
+	println("gc percent 1000 runs fewer cycles:", cycles1000 < cycles100 || !heapGrown && cycles1000 == cycles100)
+
+	// Automatic garbage collection can be disabled entirely. Allocate less
+	// than the size of the heap, so that it also works when the heap can't
+	// grow.
+	runtime.GC()
+	debug.SetGCPercent(-1)
+	cyclesOff := gcCycles(int(heapSys) / 4)
+	println("gc percent -1 runs no cycles:", cyclesOff == 0)
+	debug.SetGCPercent(100)
+}
+
+var (
+	interiorPointer *uint32
+	garbage         []uint32
+)
+
+// allocInteriorPointer allocates an array of n words and returns a pointer to
+// the middle of it, so that no pointer to the start of the array remains.
+//
+//go:noinline
+func allocInteriorPointer(n int) *uint32 {
+	array := make([]uint32, n)
+	for i := range array {
+		array[i] = uint32(i) ^ 0x5a5a5a5a
+	}
+	return &array[n/2]
+}
+
+func testInteriorPointer() {
+	n := 256
+	if ^uintptr(0) <= 0xffff {
+		n = 16
+	}
+	interiorPointer = allocInteriorPointer(n)
+
+	// Allocate lots of garbage, so that the array would be overwritten if it
+	// was freed.
+	for i := 0; i < 100; i++ {
+		garbage = make([]uint32, n)
+		for j := range garbage {
+			garbage[j] = 0xffffffff
+		}
+		runtime.GC()
+	}
+	garbage = nil
+
+	// The whole array must be kept alive, not just the part after the
+	// pointer.
+	start := unsafe.Add(unsafe.Pointer(interiorPointer), -uintptr(n/2)*unsafe.Sizeof(uint32(0)))
+	for i, v := range unsafe.Slice((*uint32)(start), n) {
+		if v != uint32(i)^0x5a5a5a5a {
+			panic("array was freed while an interior pointer was live!")
+		}
+	}
+	println("interior pointer: ok")
+}
@@ -0,0 +1,28 @@
+package main
+
+// This program deliberately writes past the end of a heap allocation. With
+// -heap-guard, this must result in a segmentation fault at the first byte past
+// the end of the buffer.
+
+import (
+	"os"
+	"unsafe"
+)
+
+var buf []byte
+
+func main() {
+	// Use a global, so that the buffer is allocated on the heap.
+	buf = make([]byte, 1000)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	println("in bounds:", buf[0], buf[len(buf)-1])
+
+	// Bypass the bounds check.
+	p := (*byte)(unsafe.Add(unsafe.Pointer(&buf[0]), len(buf)))
+	// The buffer ends right before the guard page.
+	println("overrun at start of page:", uintptr(unsafe.Pointer(p))%uintptr(os.Getpagesize()) == 0)
+	*p = 1
+	println("out of bounds write did not fault")
+}
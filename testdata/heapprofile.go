@@ -0,0 +1,88 @@
+package main
+
+// This program checks that allocations are recorded in the heap profile, when
+// built with -heap-profile. The profile is also written to the file given as
+// the first argument, so that the test can parse it with go tool pprof.
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+var (
+	kept [100][]byte
+	sink []byte
+)
+
+//go:noinline
+func allocKept() {
+	for i := range kept {
+		kept[i] = make([]byte, 1000)
+	}
+}
+
+//go:noinline
+func allocGarbage() {
+	for i := 0; i < 1000; i++ {
+		sink = make([]byte, 100)
+	}
+	sink = nil
+}
+
+func main() {
+	// Record every allocation.
+	runtime.MemProfileRate = 1
+
+	allocKept()
+	allocGarbage()
+	runtime.GC()
+
+	n, _ := runtime.MemProfile(nil, true)
+	records := make([]runtime.MemProfileRecord, n+10)
+	n, ok := runtime.MemProfile(records, true)
+	println("read profile:", ok)
+	// The order of the records is not defined, so find them by their size
+	// before printing them.
+	var keptRecord, garbageRecord *runtime.MemProfileRecord
+	unused := 0
+	for i := range records[:n] {
+		r := &records[i]
+		if r.InUseBytes() == 0 {
+			unused++
+		}
+		switch {
+		case r.AllocObjects == 100 && r.AllocBytes == 100*1000:
+			keptRecord = r
+		case r.AllocObjects == 1000 && r.AllocBytes == 1000*100:
+			garbageRecord = r
+		}
+	}
+	if keptRecord != nil {
+		r := keptRecord
+		println("kept: in use", r.InUseObjects(), "objects and", r.InUseBytes(), "bytes, has stack:", len(r.Stack()) != 0)
+	}
+	if garbageRecord != nil {
+		// The conservative GC might not free every single object.
+		r := garbageRecord
+		println("garbage: most objects freed:", r.FreeObjects >= 990, "has stack:", len(r.Stack()) != 0)
+	}
+
+	// Records without any memory in use are left out when inuseZero is false.
+	inuse, _ := runtime.MemProfile(nil, false)
+	println("records without memory in use left out:", inuse == n-unused)
+
+	// A profile that doesn't fit is not copied.
+	_, ok = runtime.MemProfile(records[:1], true)
+	println("short slice:", ok)
+
+	var buf bytes.Buffer
+	err := pprof.WriteHeapProfile(&buf)
+	println("write profile:", err == nil, bytes.HasPrefix(buf.Bytes(), []byte("heap profile: ")))
+	println("sampling rate in header:", bytes.Contains(buf.Bytes(), []byte(" @ heap/2\n")))
+	if len(os.Args) > 1 {
+		err = os.WriteFile(os.Args[1], buf.Bytes(), 0o666)
+		println("write profile file:", err == nil)
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
-import "time"
+import (
+	"runtime"
+	"time"
+)
 
 func main() {
 	thing := &Thing{"foo"}
@@ -107,6 +110,32 @@ func main() {
 	println("slept 1ms")
 	blockStatic(SleepBlocker(time.Millisecond))
 	println("slept 1ms")
+
+	testSmallValueAllocs()
+}
+
+var itfSink interface{}
+
+// testSmallValueAllocs checks that values that fit in a pointer are stored
+// directly in the interface, without a heap allocation, and that they can be
+// asserted back.
+func testSmallValueAllocs() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	mallocs := ms.Mallocs
+	sum := 0
+	for i := 0; i < 100; i++ {
+		itfSink = i
+		sum += itfSink.(int)
+		itfSink = i%2 == 0
+		if itfSink.(bool) {
+			sum++
+		}
+		itfSink = SmallPair{3, byte(i)}
+		sum += int(itfSink.(SmallPair).b)
+	}
+	runtime.ReadMemStats(&ms)
+	println("small values in interface:", sum, "allocs:", ms.Mallocs-mallocs)
 }
 
 func printItf(val interface{}) {
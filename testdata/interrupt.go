@@ -0,0 +1,54 @@
+package main
+
+// This test checks that values sent over a channel from an interrupt handler
+// arrive in order and intact in the receiving goroutine. It uses the SysTick
+// interrupt, which is available on all Cortex-M chips (including QEMU).
+
+import (
+	"device/arm"
+)
+
+const numValues = 1000
+
+type sample struct {
+	seq   uint32
+	check uint32 // ^seq, to detect torn values
+}
+
+var (
+	samples = make(chan sample, 8)
+	nextSeq uint32
+)
+
+func main() {
+	// Fire the interrupt very often, so that it regularly interrupts the
+	// receiving goroutine and the scheduler.
+	arm.SetupSystemTimer(1000)
+
+	received := 0
+	for received < numValues {
+		s := <-samples
+		if s.seq != uint32(received) || s.check != ^s.seq {
+			println("unexpected sample:", s.seq, s.check, "expected:", received)
+			return
+		}
+		received++
+	}
+	arm.SetupSystemTimer(0)
+
+	println("received", received, "samples")
+}
+
+//export SysTick_Handler
+func timerISR() {
+	if nextSeq == numValues {
+		return
+	}
+	select {
+	case samples <- sample{nextSeq, ^nextSeq}:
+		nextSeq++
+	default:
+		// The receiver is running behind, send this value again on the next
+		// interrupt.
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+// This program is built with and without -int-overflow=trap, see
+// TestIntOverflow.
+
+func main() {
+	// No overflow.
+	try("add", func() int32 { return addInt32(1<<30, 1<<30-1) })
+	try("mul", func() int32 { return mulInt32(-1<<15, 1<<16) })
+
+	// Signed overflow, which only panics with -int-overflow=trap.
+	try("add overflow", func() int32 { return addInt32(1<<31-1, 1) })
+	try("sub overflow", func() int32 { return subInt32(-1<<31, 1) })
+	try("mul overflow", func() int32 { return mulInt32(1<<16, 1<<16) })
+	try("neg overflow", func() int32 { return negInt32(-1 << 31) })
+
+	// Unsigned integers always wrap around.
+	try("unsigned", func() int32 { return int32(addUint32(1<<32-1, 2)) })
+
+	// The default can be changed for a single function.
+	try("wrap pragma", func() int32 { return addWrap(1<<31-1, 1) })
+	try("trap pragma", func() int32 { return addTrap(1<<31-1, 1) })
+	try("trap pragma closure", func() int32 { return addTrapClosure(1<<31-1, 1) })
+}
+
+func try(name string, f func() int32) {
+	defer func() {
+		if err := recover(); err != nil {
+			println(name+": panic:", err.(error).Error())
+		}
+	}()
+	println(name+":", f())
+}
+
+//go:noinline
+func addInt32(x, y int32) int32 {
+	return x + y
+}
+
+//go:noinline
+func subInt32(x, y int32) int32 {
+	return x - y
+}
+
+//go:noinline
+func mulInt32(x, y int32) int32 {
+	return x * y
+}
+
+//go:noinline
+func negInt32(x int32) int32 {
+	return -x
+}
+
+//go:noinline
+func addUint32(x, y uint32) uint32 {
+	return x + y
+}
+
+//go:noinline
+//go:overflow wrap
+func addWrap(x, y int32) int32 {
+	return x + y
+}
+
+//go:noinline
+//go:overflow trap
+func addTrap(x, y int32) int32 {
+	return x + y
+}
+
+//go:noinline
+//go:overflow trap
+func addTrapClosure(x, y int32) int32 {
+	add := func() int32 {
+		return x + y
+	}
+	return add()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math/bits"
+	_ "unsafe"
+)
+
+// Functions and globals from the runtime can be accessed with //go:linkname,
+// like some libraries do.
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//go:linkname runtimeOverflowError runtime.overflowError
+var runtimeOverflowError error
+
+func main() {
+	t1 := nanotime()
+	t2 := nanotime()
+	println("nanotime is monotonic:", t2 >= t1)
+
+	println("overflow error:", runtimeOverflowError.Error())
+
+	// math/bits also uses //go:linkname to get this error (and divideError)
+	// in case of an overflow or division by zero.
+	q, r := bits.Div(0, 7, 2)
+	println("bits.Div:", q, r)
+}
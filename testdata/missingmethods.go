@@ -0,0 +1,36 @@
+package main
+
+// This program doesn't compile: it is used to test the error message for types
+// that don't implement an interface.
+
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+	Scale(factor float64) Shape
+}
+
+type Square struct {
+	side float64
+}
+
+func (s Square) Area() float64 {
+	return s.side * s.side
+}
+
+func (s Square) Perimeter() float64 {
+	return 4 * s.side
+}
+
+type Circle struct {
+	radius float64
+}
+
+func (c Circle) Area() float64 {
+	return 3.14159 * c.radius * c.radius
+}
+
+func main() {
+	var s Shape = Square{2}
+	var c Shape = Circle{1}
+	println(s, c)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"machine"
+)
+
+// fakeSerial is an in-memory serial device.
+type fakeSerial struct {
+	name   string
+	output []byte
+	input  []byte
+	err    error
+}
+
+func (s *fakeSerial) WriteByte(c byte) error {
+	s.output = append(s.output, c)
+	return s.err
+}
+
+func (s *fakeSerial) Write(data []byte) (int, error) {
+	s.output = append(s.output, data...)
+	return len(data), s.err
+}
+
+func (s *fakeSerial) Buffered() int {
+	return len(s.input)
+}
+
+func (s *fakeSerial) ReadByte() (byte, error) {
+	if len(s.input) == 0 {
+		return 0, errors.New("no data")
+	}
+	c := s.input[0]
+	s.input = s.input[1:]
+	return c, nil
+}
+
+// fakeUSB is a serial device with DTR and RTS signals, like USB-CDC.
+type fakeUSB struct {
+	fakeSerial
+	dtr bool
+}
+
+func (s *fakeUSB) DTR() bool { return s.dtr }
+func (s *fakeUSB) RTS() bool { return false }
+
+// console is the interface the runtime uses to write to machine.Serial.
+type console interface {
+	machine.SerialDevice
+	DTR() bool
+	RTS() bool
+}
+
+// putchar writes to the console like the runtime does for println.
+func putchar(serial console, c byte) {
+	serial.WriteByte(c)
+}
+
+func main() {
+	usb := &fakeUSB{fakeSerial: fakeSerial{name: "usb"}}
+	uart := &fakeSerial{name: "uart"}
+	var serial console = machine.MultiSerial{usb, uart}
+
+	for _, c := range []byte("hello\n") {
+		putchar(serial, c)
+	}
+	n, err := serial.Write([]byte("world\n"))
+	println("write:", n, err == nil)
+	for _, dev := range []*fakeSerial{&usb.fakeSerial, uart} {
+		print(dev.name, ": ", string(dev.output))
+	}
+
+	// Errors are reported, but all devices still receive the output.
+	uart.err = errors.New("uart error")
+	err = serial.WriteByte('!')
+	println("error:", err != nil, string(usb.output[len(usb.output)-1:]), string(uart.output[len(uart.output)-1:]))
+	n, err = serial.Write([]byte("abc"))
+	println("write with error:", n, err != nil, len(usb.output), len(uart.output))
+
+	// Input is read from the first device with data.
+	uart.input = []byte("xy")
+	usb.input = []byte("z")
+	println("buffered:", serial.Buffered())
+	for serial.Buffered() != 0 {
+		c, err := serial.ReadByte()
+		println("read:", string(c), err == nil)
+	}
+	_, err = serial.ReadByte()
+	println("read empty:", err != nil)
+
+	println("DTR:", serial.DTR())
+	usb.dtr = true
+	println("DTR:", serial.DTR(), "RTS:", serial.RTS())
+	println("configure:", serial.(machine.MultiSerial).Configure(machine.UARTConfig{}) == nil)
+}
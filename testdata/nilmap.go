@@ -0,0 +1,10 @@
+package main
+
+// This program is expected to panic: writing to a nil map is not allowed.
+
+func main() {
+	var m map[string]int
+	println("writing to nil map")
+	m["foo"] = 1
+	println("unreachable")
+}
@@ -0,0 +1,40 @@
+package main
+
+// This test checks that globals in a .noinit section keep their value across a
+// reset, while other globals are zeroed or initialized as usual. It resets the
+// chip twice using the system reset of the Cortex-M core.
+
+import (
+	"device/arm"
+)
+
+// Set to magic once the values below are valid. RAM has an unknown value at
+// power on.
+//
+//go:section .noinit
+var marker uint32
+
+//go:section .noinit
+var resets uint32
+
+const magic = 0x600dcafe
+
+var (
+	zeroed      uint32
+	initialized uint32 = 5
+)
+
+func main() {
+	if marker != magic {
+		marker = magic
+		resets = 0
+	}
+	println("resets:", resets, "zeroed:", zeroed, "initialized:", initialized)
+	if resets < 2 {
+		resets++
+		zeroed = 1
+		initialized = 1
+		arm.SystemReset()
+	}
+	println("done")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"runtime"
+)
+
+var panics = make(chan interface{})
+
+func main() {
+	runtime.SetUnhandledPanicHandler(func(v interface{}) {
+		// Pass the panic value to the main goroutine, which shuts down the
+		// program in a controlled way. This goroutine is stopped afterwards.
+		panics <- v
+	})
+
+	go func() {
+		panic("goroutine panic")
+	}()
+	v := <-panics
+	println("handler received:", v.(string))
+
+	go func() {
+		var m map[string]int
+		m["foo"] = 1
+	}()
+	v = <-panics
+	if err, ok := v.(runtime.Error); ok {
+		println("handler received runtime error:", err.Error())
+	} else {
+		println("handler received unexpected value")
+	}
+
+	// Other goroutines keep running after a goroutine panicked.
+	done := make(chan struct{})
+	go func() {
+		println("other goroutine still running")
+		close(done)
+	}()
+	<-done
+
+	println("shutting down")
+}
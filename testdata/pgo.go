@@ -0,0 +1,35 @@
+package main
+
+// This is the same code as pgo/testdata/fib.go without the profiling, so that
+// the function names match the profile in pgo/testdata/fib.pprof.
+
+type adder struct{ n int }
+
+func (a *adder) add(x int) {
+	for i := 0; i < x; i++ {
+		a.n += i ^ a.n
+	}
+}
+
+func fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+func main() {
+	a := &adder{}
+	square := func(x int) int {
+		s := 0
+		for i := 0; i < x; i++ {
+			s += i * i % 7
+		}
+		return s
+	}
+	for i := 0; i < 40; i++ {
+		a.add(fib(28) * 100)
+		a.n += square(2000000)
+	}
+	println(a.n)
+}
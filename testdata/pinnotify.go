@@ -0,0 +1,93 @@
+package main
+
+import "machine"
+
+// Fake pins. These functions implement the hooks used by the generic machine
+// package, and setPin simulates the hardware: it signals the pin change
+// interrupt when the level of a pin changes.
+
+var pinLevels = map[machine.Pin]bool{}
+
+//export __tinygo_gpio_configure
+func gpioConfigure(pin machine.Pin, config machine.PinConfig) {
+}
+
+//export __tinygo_gpio_set
+func gpioSet(pin machine.Pin, value bool) {
+}
+
+//export __tinygo_gpio_get
+func gpioGet(pin machine.Pin) bool {
+	return pinLevels[pin]
+}
+
+// gpioInterrupt is the interrupt handler of the machine package.
+//
+//export __tinygo_gpio_interrupt
+func gpioInterrupt(pin machine.Pin, high bool)
+
+func setPin(pin machine.Pin, high bool) {
+	if pinLevels[pin] != high {
+		pinLevels[pin] = high
+		gpioInterrupt(pin, high)
+	}
+}
+
+// toggle injects the given number of edges on the pin.
+func toggle(pin machine.Pin, edges int) {
+	for i := 0; i < edges; i++ {
+		setPin(pin, !pinLevels[pin])
+	}
+}
+
+const (
+	button = machine.Pin(4)
+	other  = machine.Pin(5)
+)
+
+func main() {
+	// A goroutine receives the events in the order of the edges, and only
+	// for the requested change.
+	events := button.NotifyChan(machine.PinFalling)
+	done := make(chan struct{})
+	go func() {
+		n := 0
+		for event := range events {
+			println("event:", event.Pin, event.High)
+			n++
+			if n == 3 {
+				break
+			}
+		}
+		close(done)
+	}()
+	toggle(button, 6)
+	setPin(other, true)
+	<-done
+
+	// Events that don't fit in the channel are dropped, the others are kept
+	// in order.
+	events = button.NotifyChan(machine.PinToggle)
+	toggle(button, 20)
+	println("dropped:", machine.PinEventsDropped())
+	high := true
+	inOrder := true
+	for i := 0; i < 16; i++ {
+		event := <-events
+		inOrder = inOrder && event.High == high
+		high = !high
+	}
+	println("buffered events in order:", inOrder, len(events))
+
+	// Notifications stop when the interrupt is removed, and the channel
+	// isn't closed.
+	button.SetInterrupt(0, nil)
+	toggle(button, 2)
+	select {
+	case _, ok := <-events:
+		println("unexpected event, channel open:", ok)
+	default:
+		println("no events after SetInterrupt(nil)")
+	}
+	println("dropped:", machine.PinEventsDropped())
+}
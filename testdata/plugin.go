@@ -0,0 +1,41 @@
+package main
+
+// Exported functions for the host program in pluginhost.go, which loads this
+// package built with -buildmode=plugin at runtime.
+
+var greeting string
+
+func init() {
+	greeting = "hello from the plugin"
+}
+
+//export pluginGreeting
+func pluginGreeting() *byte {
+	// Return a NUL-terminated copy that the host program can print.
+	buf := make([]byte, len(greeting)+1)
+	copy(buf, greeting)
+	return &buf[0]
+}
+
+//export fib
+func fib(n int32) int32 {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+//export allocate
+func allocate(n int) {
+	// Allocate some memory to check that the heap and GC work.
+	var buffers [][]byte
+	for i := 0; i < n; i++ {
+		buffers = append(buffers, make([]byte, 1024))
+	}
+	println("allocated buffers:", len(buffers))
+}
+
+func main() {
+	// The main function is not called in a plugin.
+	println("unreachable")
+}
@@ -0,0 +1,56 @@
+package main
+
+// Host program that loads the plugin built from plugin.go, looks up its
+// exported functions and calls them.
+
+/*
+#include <stdint.h>
+
+static const char *callGreeting(void *fn) {
+	return ((const char *(*)(void))fn)();
+}
+
+static int32_t callFib(void *fn, int32_t n) {
+	return ((int32_t (*)(int32_t))fn)(n);
+}
+
+static void callAllocate(void *fn, intptr_t n) {
+	((void (*)(intptr_t))fn)(n);
+}
+*/
+import "C"
+
+import (
+	"os"
+	"plugin"
+	"unsafe"
+)
+
+func main() {
+	p, err := plugin.Open(os.Args[1])
+	if err != nil {
+		println("could not open plugin:", err.Error())
+		return
+	}
+	if p2, _ := plugin.Open(os.Args[1]); p2 != p {
+		println("plugin opened twice")
+	}
+	if _, err := p.Lookup("notExported"); err == nil {
+		println("found a symbol that is not exported")
+	}
+
+	// The greeting is allocated by the plugin, so copy it right away.
+	greeting := C.GoString(C.callGreeting(lookup(p, "pluginGreeting")))
+	println("greeting:", greeting)
+	println("fib:", C.callFib(lookup(p, "fib"), 20))
+	C.callAllocate(lookup(p, "allocate"), 1000)
+}
+
+func lookup(p *plugin.Plugin, name string) unsafe.Pointer {
+	sym, err := p.Lookup(name)
+	if err != nil {
+		println("could not look up symbol:", err.Error())
+		os.Exit(1)
+	}
+	return sym.(unsafe.Pointer)
+}
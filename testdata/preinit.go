@@ -0,0 +1,29 @@
+package main
+
+import _ "unsafe" // for //go:section
+
+// The marker is in a .noinit section, because .bss and .data are initialized
+// after the preinit hook has run.
+//
+//go:section .noinit
+var preinitMarker uint32
+
+//export tinygo_preinit
+func preinit() {
+	preinitMarker = 0xc0ffee
+}
+
+var initMarker = readMarker()
+
+func readMarker() uint32 {
+	return preinitMarker
+}
+
+func init() {
+	println("marker in init:", preinitMarker == 0xc0ffee)
+}
+
+func main() {
+	println("marker in global initializer:", initMarker == 0xc0ffee)
+	println("marker in main:", preinitMarker == 0xc0ffee)
+}
@@ -1,5 +1,7 @@
 package main
 
+import "runtime"
+
 func main() {
 	println("# simple recover")
 	recoverSimple()
@@ -19,6 +21,12 @@ func main() {
 
 	println("\n# panic replace")
 	panicReplace()
+
+	println("\n# recover concrete type")
+	recoverConcreteType()
+
+	println("\n# recover runtime error")
+	recoverRuntimeError()
 }
 
 func recoverSimple() {
@@ -89,6 +97,100 @@ func panicReplace() {
 	panic("panic 1")
 }
 
+type customError struct {
+	code int
+	msg  string
+}
+
+func (e *customError) Error() string {
+	return e.msg
+}
+
+type errorCode int
+
+func recoverConcreteType() {
+	for _, f := range []func(){
+		func() { panic(&customError{code: 42, msg: "custom"}) },
+		func() { panic(errorCode(7)) },
+		func() {
+			// The static type is error, the dynamic type must be preserved.
+			var err error = &customError{code: 3, msg: "wrapped"}
+			panic(err)
+		},
+		func() {
+			// Re-panicking with the recovered value keeps its type.
+			defer func() {
+				panic(recover())
+			}()
+			panic(&customError{code: 5, msg: "re-panic"})
+		},
+	} {
+		printRecovered(catch(f))
+	}
+}
+
+func recoverRuntimeError() {
+	var nilSlice []int
+	var nilPointer *customError
+	var nilMap map[string]int
+	var itf interface{} = 3
+	zero := 0
+	for _, f := range []func(){
+		func() { _ = nilSlice[3] },
+		func() { _ = nilPointer.code },
+		func() { nilMap["foo"] = 1 },
+		func() { _ = itf.(string) },
+		func() { _ = 1 / zero },
+	} {
+		printRecovered(catch(f))
+	}
+
+	// The runtime panic happens in the function with the deferred call.
+	printRecovered(indexInDeferFunction(nilSlice, 5))
+	var ch chan int
+	printRecovered(closeInDeferFunction(ch))
+}
+
+func indexInDeferFunction(s []int, index int) (recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	_ = s[index]
+	return nil
+}
+
+func closeInDeferFunction(ch chan int) (recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	close(ch)
+	return nil
+}
+
+// catch calls f and returns the value it panicked with.
+func catch(f func()) (recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	f()
+	return nil
+}
+
+func printRecovered(v interface{}) {
+	switch v := v.(type) {
+	case *customError:
+		println("customError:", v.code, v.msg)
+	case errorCode:
+		println("errorCode:", int(v))
+	case runtime.Error:
+		println("runtime.Error:", v.Error())
+	case nil:
+		println("nil")
+	default:
+		println("unexpected type")
+	}
+}
+
 func printitf(msg string, itf interface{}) {
 	switch itf := itf.(type) {
 	case string:
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"runtime"
+	"time"
+)
+
+var events []runtime.TraceEvent
+
+func record(ev runtime.TraceEvent) {
+	// The handler must not block, but it may allocate.
+	events = append(events, ev)
+}
+
+func worker(ch chan int, done chan bool) {
+	v := <-ch
+	time.Sleep(time.Millisecond)
+	done <- v == 1
+}
+
+func main() {
+	runtime.SetTraceHandler(record)
+	ch := make(chan int)
+	done := make(chan bool)
+	go worker(ch, done)
+	ch <- 1
+	ok := <-done
+	runtime.Gosched()
+	runtime.SetTraceHandler(nil)
+	println("worker ok:", ok)
+
+	// The first goroutine that is created is the worker, events for other
+	// goroutines are from the main goroutine.
+	var workerID uintptr
+	ordered := true
+	for i, ev := range events {
+		if ev.Kind == runtime.TraceGoCreate && workerID == 0 {
+			workerID = ev.Goroutine
+		}
+		name := "main"
+		if ev.Goroutine == workerID {
+			name = "worker"
+		}
+		if i > 0 && ev.Time < events[i-1].Time {
+			ordered = false
+		}
+		println(ev.Kind.String(), name)
+	}
+	println("timestamps ordered:", ordered)
+}
@@ -0,0 +1,63 @@
+package main
+
+// Test the external scheduler (-scheduler=external). The main function acts
+// as the host system: goroutines only run when it calls runtime.SchedulerStep.
+
+import (
+	"runtime"
+	"time"
+)
+
+func main() {
+	ch := make(chan int)
+	done := make(chan struct{}, 1)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(10 * time.Millisecond)
+			ch <- i
+		}
+		close(ch)
+	}()
+	go func() {
+		for v := range ch {
+			println("received:", v)
+		}
+		done <- struct{}{}
+	}()
+	go func() {
+		// A goroutine that keeps yielding must not hang SchedulerStep.
+		for i := 0; i < 3; i++ {
+			println("yield:", i)
+			runtime.Gosched()
+		}
+	}()
+	time.AfterFunc(time.Millisecond, func() {
+		println("timer fired")
+	})
+	println("goroutines started:", runtime.NumGoroutine())
+
+	// Nothing runs until the scheduler is stepped.
+	println("first step")
+	if next := runtime.SchedulerStep(); next != 0 {
+		println("expected goroutines to be ready, got", next)
+	}
+
+	// Pump the scheduler like an RTOS task would, sleeping in between.
+	for {
+		next := runtime.SchedulerStep()
+		select {
+		case <-done:
+			println("done")
+			println("next step:", runtime.SchedulerStep())
+			println("goroutines left:", runtime.NumGoroutine())
+			return
+		default:
+		}
+		if next < 0 {
+			println("unexpected deadlock")
+			return
+		}
+		time.Sleep(time.Duration(next))
+	}
+}
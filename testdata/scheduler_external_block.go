@@ -0,0 +1,22 @@
+package main
+
+// With -scheduler=external the main function runs outside of a goroutine, so
+// blocking in it must result in a runtime panic instead of a crash.
+
+import (
+	"runtime"
+	"time"
+)
+
+func main() {
+	ch := make(chan int)
+	go func() {
+		time.Sleep(time.Millisecond)
+		ch <- 1
+	}()
+	// Stepping the scheduler from main is fine.
+	runtime.SchedulerStep()
+	println("blocking in main")
+	<-ch
+	println("fail: received from the channel")
+}
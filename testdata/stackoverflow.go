@@ -0,0 +1,22 @@
+package main
+
+import "time"
+
+// Recurse in a goroutine until its stack overflows. The recursion doesn't
+// yield to the scheduler: the stack overflow must be detected as it happens,
+// which needs -stack-check.
+func main() {
+	println("starting goroutine")
+	go func() {
+		println(recurse(0))
+	}()
+	time.Sleep(time.Second)
+	println("the stack overflow was not detected")
+}
+
+//go:noinline
+func recurse(depth int) int {
+	var buf [8]int
+	buf[depth%8] = depth
+	return recurse(depth+1) + buf[(depth+1)%8]
+}
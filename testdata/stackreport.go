@@ -0,0 +1,38 @@
+package main
+
+// This program is built with -stack-usage-report to check the report (see
+// TestStackUsageReport). It starts a goroutine so that the report includes
+// the stack of a goroutine next to the stack of the reset handler.
+
+var sink [64]byte
+
+func main() {
+	done := make(chan struct{})
+	go worker(done)
+	<-done
+	println("sum:", sum(3))
+}
+
+//go:noinline
+func worker(done chan struct{}) {
+	fill(sink[:])
+	close(done)
+}
+
+//go:noinline
+func fill(buf []byte) {
+	var local [32]byte
+	for i := range local {
+		local[i] = byte(i)
+	}
+	copy(buf, local[:])
+}
+
+//go:noinline
+func sum(n int) int {
+	total := 0
+	for _, b := range sink[:n] {
+		total += int(b)
+	}
+	return total
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func main() {
+	println("before redirect")
+
+	var buf bytes.Buffer
+	runtime.SetStdout(&buf)
+	println("println:", 1, true)
+	print("print without newline")
+	fmt.Println("fmt.Println:", 2)
+	fmt.Fprint(os.Stdout, "fmt.Fprint to os.Stdout\n")
+	os.Stdout.WriteString("os.Stdout.WriteString\n")
+	runtime.SetStdout(nil)
+
+	println("after redirect")
+	fmt.Printf("captured:\n%s", buf.String())
+}
@@ -0,0 +1,143 @@
+package main
+
+// Integer parsing edge cases. The expected output is the output of the same
+// program built with the standard Go toolchain.
+
+import (
+	"errors"
+	"strconv"
+)
+
+var intTests = []struct {
+	s       string
+	base    int
+	bitSize int
+}{
+	// Base 0 prefixes.
+	{"0", 0, 64},
+	{"0x1f", 0, 64},
+	{"0X1F", 0, 64},
+	{"0o17", 0, 64},
+	{"0O17", 0, 64},
+	{"017", 0, 64},
+	{"0b101", 0, 64},
+	{"0B101", 0, 64},
+	{"-0x80", 0, 8},
+	{"+0b1", 0, 8},
+	{"0x", 0, 64},
+	{"0b", 0, 64},
+	{"0o", 0, 64},
+	{"0b2", 0, 64},
+	{"08", 0, 64},
+	{"0x1f", 16, 64},
+	{"0b101", 2, 64},
+	{"1f", 16, 64},
+	{"zz", 36, 64},
+	{"ZZ", 36, 64},
+	{"10", 1, 64},
+	{"10", 37, 64},
+	{"10", -1, 64},
+
+	// Underscores are only allowed with base 0.
+	{"1_000", 0, 64},
+	{"0x_1f", 0, 64},
+	{"0_17", 0, 64},
+	{"0b_1_0", 0, 64},
+	{"1__000", 0, 64},
+	{"_1000", 0, 64},
+	{"1000_", 0, 64},
+	{"0x1f_", 0, 64},
+	{"1_000", 10, 64},
+	{"-1_000", 0, 16},
+
+	// Empty and sign-only strings.
+	{"", 10, 64},
+	{"-", 10, 64},
+	{"+", 10, 64},
+	{"+-1", 10, 64},
+	{" 1", 10, 64},
+
+	// Overflow edge cases.
+	{"127", 10, 8},
+	{"128", 10, 8},
+	{"-128", 10, 8},
+	{"-129", 10, 8},
+	{"32767", 10, 16},
+	{"-32769", 10, 16},
+	{"2147483647", 10, 32},
+	{"2147483648", 10, 32},
+	{"-2147483648", 10, 32},
+	{"9223372036854775807", 10, 64},
+	{"9223372036854775808", 10, 64},
+	{"-9223372036854775808", 10, 64},
+	{"-9223372036854775809", 10, 64},
+	{"0x7fffffffffffffff", 0, 64},
+	{"0x8000000000000000", 0, 64},
+	{"-0x8000000000000000", 0, 64},
+	{"99999999999999999999999", 10, 64},
+	{"-99999999999999999999999", 10, 64},
+	{"100", 10, 0},
+	{"1x", 10, 64},
+	{"99999999999999999999x", 10, 64},
+}
+
+var uintTests = []struct {
+	s       string
+	base    int
+	bitSize int
+}{
+	{"255", 10, 8},
+	{"256", 10, 8},
+	{"0xff", 0, 8},
+	{"0x100", 0, 8},
+	{"-1", 10, 64},
+	{"+1", 10, 64},
+	{"4294967295", 10, 32},
+	{"4294967296", 10, 32},
+	{"18446744073709551615", 10, 64},
+	{"18446744073709551616", 10, 64},
+	{"0xffffffffffffffff", 0, 64},
+	{"0x1_0000_0000_0000_0000", 0, 64},
+	{"0o1777777777777777777777", 0, 64},
+	{"0o2000000000000000000000", 0, 64},
+	{"0b" + "1111111111111111111111111111111111111111111111111111111111111111", 0, 64},
+	{"0b" + "10000000000000000000000000000000000000000000000000000000000000000", 0, 64},
+	{"3w5e11264sgsf", 36, 64},
+	{"3w5e11264sgsg", 36, 64},
+	{"1_2", 0, 64},
+	{"1_2", 16, 64},
+}
+
+func main() {
+	for _, tc := range intTests {
+		n, err := strconv.ParseInt(tc.s, tc.base, tc.bitSize)
+		println("ParseInt", strconv.Quote(tc.s), tc.base, tc.bitSize, "=", n, describe(err))
+	}
+	for _, tc := range uintTests {
+		n, err := strconv.ParseUint(tc.s, tc.base, tc.bitSize)
+		println("ParseUint", strconv.Quote(tc.s), tc.base, tc.bitSize, "=", n, describe(err))
+	}
+	for _, s := range []string{"0", "-0", "+12", "0x10", "1_0", "abc", ""} {
+		n, err := strconv.Atoi(s)
+		println("Atoi", strconv.Quote(s), "=", n, describe(err))
+	}
+}
+
+// describe returns the error message along with which sentinel error it
+// wraps, so that the exact error values are checked too.
+func describe(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	kind := "other"
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		kind = "ErrRange"
+	case errors.Is(err, strconv.ErrSyntax):
+		kind = "ErrSyntax"
+	}
+	if _, ok := err.(*strconv.NumError); !ok {
+		kind += " (not a *NumError)"
+	}
+	return kind + ": " + err.Error()
+}
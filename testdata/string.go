@@ -1,5 +1,10 @@
 package main
 
+import (
+	"runtime"
+	"unicode/utf8"
+)
+
 func testRangeString() {
 	for i, c := range "abcü¢€𐍈°x" {
 		println(i, c)
@@ -17,11 +22,85 @@ func testRunesToString(r []rune) {
 	println("string from runes:", string(r))
 }
 
+// testInvalidUTF8 checks that invalid UTF-8 is decoded the same way as the
+// unicode/utf8 package (and the gc toolchain) does: every invalid sequence
+// results in utf8.RuneError and is skipped one byte at a time.
+func testInvalidUTF8() {
+	for _, s := range []string{
+		"\x80",               // lone continuation byte
+		"\xbf",               // lone continuation byte
+		"\xc0\x80",           // overlong encoding of U+0000
+		"\xc1\xbf",           // overlong encoding of U+007F
+		"\xc2",               // truncated two-byte sequence
+		"\xc2\x7f",           // invalid continuation byte
+		"\xe0\x80\x80",       // overlong encoding of U+0000
+		"\xe0\x9f\xbf",       // overlong encoding of U+07FF
+		"\xe2\x82",           // truncated three-byte sequence
+		"\xed\xa0\x80",       // surrogate half U+D800
+		"\xed\xbf\xbf",       // surrogate half U+DFFF
+		"\xf0\x80\x80\x80",   // overlong encoding of U+0000
+		"\xf0\x8f\xbf\xbf",   // overlong encoding of U+FFFF
+		"\xf0\x90\x80",       // truncated four-byte sequence
+		"\xf4\x90\x80\x80",   // U+110000, out of range
+		"\xf5\x80\x80\x80",   // invalid start byte
+		"\xff",               // invalid start byte
+		"a\xe2\x82\xacb\xe2", // valid sequences around a truncated one
+	} {
+		r, size := utf8.DecodeRuneInString(s)
+		print("invalid UTF-8: DecodeRune=", r, ",", size, " range=")
+		for i, c := range s {
+			print(" ", i, ":", c)
+		}
+		print(" runes=", len([]rune(s)))
+		println()
+	}
+
+	// Runes that are not valid code points are encoded as utf8.RuneError.
+	for _, r := range []rune{-1, 0xd800, 0xdfff, 0x110000} {
+		println("invalid rune:", r, len(string(r)), string([]rune{r}) == "\uFFFD")
+	}
+
+	// Integers of other types are converted by value, without being truncated
+	// or sign-extended to a (different) valid rune.
+	var u8 uint8 = 200
+	var u32 uint32 = 0x80000041
+	var i64 int64 = 1<<32 + 'A'
+	var u64 uint64 = 1<<63 + 'A'
+	println("integer to string:", string(u8) == "\u00c8", string(u32) == "\uFFFD", string(i64) == "\uFFFD", string(u64) == "\uFFFD")
+}
+
+// testStringConcat checks that concatenating several strings produces the
+// right result, and does so with a single allocation.
+func testStringConcat(a, b, c, empty string) {
+	println("concat:", a+", "+b+"!")
+	println("concat empty:", empty+a+empty+b+empty)
+	println("concat nested:", a+(b+c)+a)
+	println("concat one:", empty+a+empty) // no allocation needed
+	println("concat named:", myString(a)+myString(c)+"!")
+	x := a + b // named intermediate, referenced by the debug info
+	println("concat intermediate:", x+c)
+
+	s := ""
+	for i := 0; i < 3; i++ {
+		s = s + a + c
+	}
+	println("concat loop:", s)
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	mallocs := ms.Mallocs
+	s = a + b + c + a
+	runtime.ReadMemStats(&ms)
+	println("concat allocs:", ms.Mallocs-mallocs, len(s))
+}
+
 type myString string
 
 func main() {
 	testRangeString()
 	testStringToRunes()
 	testRunesToString([]rune{97, 98, 99, 252, 162, 8364, 66376, 176, 120})
+	testInvalidUTF8()
+	testStringConcat("hello", "world", "xyz", "")
 	var _ = len([]byte(myString("foobar"))) // issue 1246
 }
@@ -0,0 +1,77 @@
+package main
+
+import "crypto/subtle"
+
+func main() {
+	println("ConstantTimeCompare:")
+	for _, n := range []int{0, 1, 3, 8, 15, 16, 33} {
+		x := makeBytes(n)
+		y := makeBytes(n)
+		print("  len ", n, ": equal=", constantTimeCompare(x, y))
+		for _, i := range []int{0, n / 2, n - 1} {
+			if i < 0 || i >= n {
+				continue
+			}
+			y[i] ^= 0x80
+			print(" diff@", i, "=", constantTimeCompare(x, y))
+			y[i] ^= 0x80
+		}
+		print(" shorter=", constantTimeCompare(x, y[:n/2]))
+		println()
+	}
+
+	println("ConstantTimeByteEq:", constantTimeByteEq(0, 0), constantTimeByteEq(0xff, 0xff), constantTimeByteEq(1, 0), constantTimeByteEq(0, 0x80), constantTimeByteEq(0x7f, 0xff))
+	println("ConstantTimeEq:", constantTimeEq(0, 0), constantTimeEq(-1, -1), constantTimeEq(1<<31-1, -1<<31), constantTimeEq(5, 4), constantTimeEq(-1, 1))
+	println("ConstantTimeLessOrEq:", constantTimeLessOrEq(0, 0), constantTimeLessOrEq(1, 2), constantTimeLessOrEq(2, 1), constantTimeLessOrEq(1<<31-1, 1<<31-1), constantTimeLessOrEq(1<<31-1, 0))
+	println("ConstantTimeSelect:", constantTimeSelect(1, 10, 20), constantTimeSelect(0, 10, 20), constantTimeSelect(1, -1, 0), constantTimeSelect(0, -1, 0))
+
+	x := makeBytes(5)
+	y := []byte{'a', 'b', 'c', 'd', 'e'}
+	constantTimeCopy(0, x, y)
+	println("ConstantTimeCopy(0):", string(x) == string(makeBytes(5)))
+	constantTimeCopy(1, x, y)
+	println("ConstantTimeCopy(1):", string(x))
+}
+
+// makeBytes returns a slice with some non-constant content, so that the
+// compiler can't evaluate the comparisons at compile time.
+func makeBytes(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i*7 + n)
+	}
+	return buf
+}
+
+// The functions below wrap the crypto/subtle functions, so that the code they
+// compile to can be inspected in the IR (see TestConstantTime).
+
+//go:noinline
+func constantTimeCompare(x, y []byte) int {
+	return subtle.ConstantTimeCompare(x, y)
+}
+
+//go:noinline
+func constantTimeByteEq(x, y uint8) int {
+	return subtle.ConstantTimeByteEq(x, y)
+}
+
+//go:noinline
+func constantTimeEq(x, y int32) int {
+	return subtle.ConstantTimeEq(x, y)
+}
+
+//go:noinline
+func constantTimeLessOrEq(x, y int) int {
+	return subtle.ConstantTimeLessOrEq(x, y)
+}
+
+//go:noinline
+func constantTimeSelect(v, x, y int) int {
+	return subtle.ConstantTimeSelect(v, x, y)
+}
+
+//go:noinline
+func constantTimeCopy(v int, x, y []byte) {
+	subtle.ConstantTimeCopy(v, x, y)
+}
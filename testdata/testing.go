@@ -47,10 +47,39 @@ func TestAllLowercase(t *testing.T) {
 	}
 }
 
+func TestParallel(t *testing.T) {
+	t.Run("Batch", func(t *testing.T) {
+		parentReturned := false
+		t.Run("Ok", func(t *testing.T) {
+			t.Parallel()
+			if !parentReturned {
+				t.Error("parallel subtest started before its parent returned")
+			}
+		})
+		t.Run("Bad", func(t *testing.T) {
+			t.Parallel()
+			t.Error("failed in parallel")
+		})
+		t.Run("Nested", func(t *testing.T) {
+			t.Run("Inner", func(t *testing.T) {
+				t.Parallel()
+				t.Log("log Inner")
+				t.Error("failed in nested parallel subtest")
+			})
+			t.Run("Serial", func(t *testing.T) {
+				t.Log("log Serial")
+			})
+		})
+		t.Log("log Batch end")
+		parentReturned = true
+	})
+}
+
 var tests = []testing.InternalTest{
 	{"TestFoo", TestFoo},
 	{"TestBar", TestBar},
 	{"TestAllLowercase", TestAllLowercase},
+	{"TestParallel", TestParallel},
 }
 
 var benchmarks = []testing.InternalBenchmark{}
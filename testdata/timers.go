@@ -1,6 +1,9 @@
 package main
 
-import "time"
+import (
+	"runtime"
+	"time"
+)
 
 func main() {
 	// Test ticker.
@@ -51,4 +54,59 @@ func main() {
 	<-timer.C
 	println("waited on timer at 500ms")
 	time.Sleep(time.Millisecond * 500)
+
+	// Test AfterFunc. The first function runs in a new goroutine, which is
+	// then reused for the next functions. It isn't counted while it's waiting
+	// or running a function that doesn't block, so the callbacks must not see
+	// any extra goroutines.
+	warmup := make(chan struct{})
+	time.AfterFunc(0, func() {
+		close(warmup)
+	})
+	<-warmup
+	time.Sleep(time.Millisecond)
+	numGoroutine := runtime.NumGoroutine()
+	start := time.Now()
+	fired := 0
+	early := 0
+	late := 0
+	extraGoroutines := 0
+	for i := 0; i < 100; i++ {
+		d := time.Duration(i) * time.Millisecond
+		time.AfterFunc(d, func() {
+			elapsed := time.Since(start)
+			if elapsed < d {
+				early++
+			} else if elapsed > d+100*time.Millisecond {
+				late++
+			}
+			if runtime.NumGoroutine() != numGoroutine {
+				extraGoroutines++
+			}
+			fired++
+		})
+	}
+	stoppedFunc := time.AfterFunc(time.Millisecond*50, func() {
+		println("fail: stopped AfterFunc was called")
+	})
+	println("AfterFunc stopped:", stoppedFunc.Stop())
+	resetFunc := time.AfterFunc(time.Hour, func() {
+		println("AfterFunc was reset")
+	})
+	println("AfterFunc reset:", resetFunc.Reset(time.Millisecond*150))
+	time.Sleep(time.Millisecond * 200)
+	println("AfterFunc fired:", fired, "early:", early, "late:", late, "extra goroutines:", extraGoroutines)
+	println("AfterFunc stopped after firing:", resetFunc.Stop())
+
+	// An AfterFunc function may block. It then continues as a regular
+	// goroutine.
+	done := make(chan string)
+	time.AfterFunc(time.Millisecond, func() {
+		done <- "sent from AfterFunc"
+	})
+	time.Sleep(time.Millisecond * 10)
+	println("AfterFunc blocked, extra goroutines:", runtime.NumGoroutine()-numGoroutine)
+	println("AfterFunc sent:", <-done)
+	time.Sleep(time.Millisecond)
+	println("AfterFunc returned, extra goroutines:", runtime.NumGoroutine()-numGoroutine)
 }
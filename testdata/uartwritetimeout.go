@@ -0,0 +1,60 @@
+package main
+
+import (
+	"machine"
+	"time"
+	"unsafe"
+)
+
+// A fake UART with a transmit buffer of 8 bytes, which only drains when told
+// to. These functions implement the hooks used by the generic machine package.
+
+var (
+	txBuf   []byte
+	txDrain bool
+)
+
+const txBufSize = 8
+
+//export __tinygo_uart_configure
+func uartConfigure(bus uint8, tx machine.Pin, rx machine.Pin) {
+}
+
+//export __tinygo_uart_read
+func uartRead(bus uint8, buf *byte, bufLen int) int {
+	return 0
+}
+
+//export __tinygo_uart_write
+func uartWrite(bus uint8, buf *byte, bufLen int) int {
+	if txDrain {
+		txBuf = txBuf[:0]
+	}
+	n := 0
+	for ; n < bufLen && len(txBuf) < txBufSize; n++ {
+		txBuf = append(txBuf, *buf)
+		buf = (*byte)(unsafe.Add(unsafe.Pointer(buf), 1))
+	}
+	return n
+}
+
+func main() {
+	uart := machine.UART0
+	uart.Configure(machine.UARTConfig{WriteTimeout: uint64(50 * time.Millisecond)})
+
+	// The transmit buffer never drains, so the write stops when it is full.
+	start := time.Now()
+	n, err := uart.Write([]byte("hello world, this is a long message"))
+	elapsed := time.Since(start)
+	println("partial write:", n, err == machine.ErrUARTWriteTimeout)
+	println("waited for timeout:", elapsed >= 50*time.Millisecond && elapsed < 5*time.Second)
+	println("sent:", string(txBuf))
+
+	err = uart.WriteByte('x')
+	println("write byte:", err == machine.ErrUARTWriteTimeout)
+
+	// Once the buffer drains, writes succeed again.
+	txDrain = true
+	n, err = uart.Write([]byte("more data than fits in the buffer"))
+	println("full write:", n, err == nil)
+}
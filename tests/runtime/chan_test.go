@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+// The compiler lowers a select with a single case and a default to a direct
+// non-blocking send or receive. BenchmarkSelectTwoCases goes through the
+// generic select implementation, for comparison.
+
+func BenchmarkSelectTrySend(b *testing.B) {
+	ch := make(chan int, 1)
+	ch <- 0
+	sent := 0
+	for i := 0; i < b.N; i++ {
+		// The channel is full, so this never sends.
+		select {
+		case ch <- i:
+			sent++
+		default:
+		}
+	}
+	if sent != 0 {
+		b.Fatal("sent on a full channel")
+	}
+}
+
+func BenchmarkSelectTryRecv(b *testing.B) {
+	ch := make(chan int, 1)
+	received := 0
+	for i := 0; i < b.N; i++ {
+		// The channel is empty, so this never receives.
+		select {
+		case <-ch:
+			received++
+		default:
+		}
+	}
+	if received != 0 {
+		b.Fatal("received from an empty channel")
+	}
+}
+
+func BenchmarkSelectTrySendRecv(b *testing.B) {
+	ch := make(chan int, 1)
+	for i := 0; i < b.N; i++ {
+		select {
+		case ch <- i:
+		default:
+			b.Fatal("could not send")
+		}
+		select {
+		case v := <-ch:
+			if v != i {
+				b.Fatal("received the wrong value")
+			}
+		default:
+			b.Fatal("could not receive")
+		}
+	}
+}
+
+func BenchmarkSelectTwoCases(b *testing.B) {
+	ch1 := make(chan int, 1)
+	ch2 := make(chan int, 1)
+	received := 0
+	for i := 0; i < b.N; i++ {
+		select {
+		case <-ch1:
+			received++
+		case <-ch2:
+			received++
+		default:
+		}
+	}
+	if received != 0 {
+		b.Fatal("received from an empty channel")
+	}
+}
@@ -0,0 +1,110 @@
+//go:build wasi
+// +build wasi
+
+package runtime_wasi
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestDirectories checks that directories can be created, listed and removed
+// inside the preopened TMPDIR.
+func TestDirectories(t *testing.T) {
+	tmpdir, err := os.MkdirTemp("", "TestDirectories")
+	if err != nil {
+		t.Fatal("could not create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	a := filepath.Join(tmpdir, "a")
+	if err := os.MkdirAll(filepath.Join(a, "b", "c"), 0755); err != nil {
+		t.Fatal("MkdirAll:", err)
+	}
+	// Creating the same directories again is not an error.
+	if err := os.MkdirAll(filepath.Join(a, "b", "c"), 0755); err != nil {
+		t.Fatal("MkdirAll on existing directory:", err)
+	}
+	if err := os.WriteFile(filepath.Join(a, "file.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	entries, err := os.ReadDir(a)
+	if err != nil {
+		t.Fatal("ReadDir:", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries in %s, got %d", a, len(entries))
+	}
+	if name := entries[0].Name(); name != "b" || !entries[0].IsDir() {
+		t.Errorf("expected directory b, got %s (dir: %v)", name, entries[0].IsDir())
+	}
+	if name := entries[1].Name(); name != "file.txt" || !entries[1].Type().IsRegular() {
+		t.Errorf("expected regular file file.txt, got %s (type: %v)", name, entries[1].Type())
+	}
+	if info, err := entries[1].Info(); err != nil || info.Size() != 5 {
+		t.Errorf("expected file.txt to be 5 bytes, got %v (error: %v)", info, err)
+	}
+
+	entries, err = os.ReadDir(filepath.Join(a, "b"))
+	if err != nil {
+		t.Fatal("ReadDir:", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "c" {
+		t.Errorf("expected only c in a/b, got %v", entries)
+	}
+
+	// A non-empty directory can't be removed with Remove.
+	if err := os.Remove(a); err == nil {
+		t.Error("Remove of non-empty directory succeeded")
+	}
+	if err := os.RemoveAll(a); err != nil {
+		t.Fatal("RemoveAll:", err)
+	}
+	if _, err := os.Stat(a); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", a, err)
+	}
+}
+
+// TestReadDirMany checks that directories with more entries than fit in a
+// single fd_readdir call are read completely.
+func TestReadDirMany(t *testing.T) {
+	tmpdir, err := os.MkdirTemp("", "TestReadDirMany")
+	if err != nil {
+		t.Fatal("could not create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	const numFiles = 300
+	for i := 0; i < numFiles; i++ {
+		name := filepath.Join(tmpdir, "some-long-file-name-to-fill-the-buffer-"+strconv.Itoa(i))
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal("WriteFile:", err)
+		}
+	}
+
+	f, err := os.Open(tmpdir)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer f.Close()
+	seen := make(map[string]bool)
+	for {
+		// Read in small batches, to test continuing after a partial read.
+		names, err := f.Readdirnames(7)
+		for _, name := range names {
+			if seen[name] {
+				t.Errorf("duplicate entry: %s", name)
+			}
+			seen[name] = true
+		}
+		if err != nil {
+			break
+		}
+	}
+	if len(seen) != numFiles {
+		t.Errorf("expected %d entries, got %d", numFiles, len(seen))
+	}
+}
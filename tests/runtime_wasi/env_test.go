@@ -0,0 +1,48 @@
+//go:build wasi
+// +build wasi
+
+package runtime_wasi
+
+import (
+	"os"
+	"testing"
+)
+
+// TestEnv checks that environment variables passed by the host (here, TMPDIR
+// passed with wasmtime --env by tinygo test) are visible through the os
+// package, and that changes made with os.Setenv are seen by os.Environ.
+func TestEnv(t *testing.T) {
+	tmpdir, ok := os.LookupEnv("TMPDIR")
+	if !ok || tmpdir == "" {
+		t.Fatal("TMPDIR not set by the host")
+	}
+	if !inEnviron("TMPDIR=" + tmpdir) {
+		t.Errorf("TMPDIR=%s not found in os.Environ()", tmpdir)
+	}
+
+	if err := os.Setenv("TINYGO_TEST_ENV", "bar"); err != nil {
+		t.Fatal("could not set environment variable:", err)
+	}
+	if v := os.Getenv("TINYGO_TEST_ENV"); v != "bar" {
+		t.Errorf("expected TINYGO_TEST_ENV=bar, got %q", v)
+	}
+	if !inEnviron("TINYGO_TEST_ENV=bar") {
+		t.Error("TINYGO_TEST_ENV=bar not found in os.Environ()")
+	}
+
+	if err := os.Unsetenv("TINYGO_TEST_ENV"); err != nil {
+		t.Fatal("could not unset environment variable:", err)
+	}
+	if _, ok := os.LookupEnv("TINYGO_TEST_ENV"); ok {
+		t.Error("TINYGO_TEST_ENV still set after os.Unsetenv")
+	}
+}
+
+func inEnviron(keyval string) bool {
+	for _, s := range os.Environ() {
+		if s == keyval {
+			return true
+		}
+	}
+	return false
+}
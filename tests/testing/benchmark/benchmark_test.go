@@ -0,0 +1,24 @@
+package benchmark_test
+
+import (
+	"testing"
+	"time"
+)
+
+// BenchmarkSleep takes a known amount of time per iteration, so that the
+// scaling of b.N can be checked.
+func BenchmarkSleep(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+var sink int
+
+// BenchmarkLoop is very fast, and needs many iterations to reach the benchmark
+// time.
+func BenchmarkLoop(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sink += i
+	}
+}
@@ -0,0 +1,38 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestAwait(t *testing.T) {
+
+	wasmTmpDir, server := startServer(t)
+
+	err := run(t, "tinygo build -o "+wasmTmpDir+"/await.wasm -target wasm testdata/await.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := chromectx(t)
+
+	var log1 string
+	err = chromedp.Run(ctx,
+		chromedp.Navigate(server.URL+"/run?file=await.wasm"),
+		waitLog(`1
+timeout
+slept true
+status 200
+contains Go: true
+promise rejected: boom
+resolved 42 true
+promise rejected: failed
+2`),
+		chromedp.InnerHTML("#log", &log1),
+	)
+	t.Logf("log1: %s", log1)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
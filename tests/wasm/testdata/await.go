@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"tinygo/jspromise"
+)
+
+func main() {
+	println("1")
+
+	// The setTimeout callback only runs if the browser event loop runs while
+	// main is waiting for the promise, which is resolved by a later timer.
+	js.Global().Call("setTimeout", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		println("timeout")
+		return nil
+	}), 0)
+	sleep := js.Global().Get("Promise").New(js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		js.Global().Call("setTimeout", args[0], 20, "slept")
+		return nil
+	}))
+	v, err := jspromise.Await(sleep)
+	println(v.String(), err == nil)
+
+	// Wait for the browser to fetch a file.
+	resp, err := jspromise.Await(js.Global().Call("fetch", "/wasm_exec.js"))
+	if err != nil {
+		println("fetch:", err.Error())
+		return
+	}
+	println("status", resp.Get("status").Int())
+	text, err := jspromise.Await(resp.Call("text"))
+	if err != nil {
+		println("text:", err.Error())
+		return
+	}
+	println("contains Go:", strings.Contains(text.String(), "class Go"))
+
+	// A rejected promise results in an error.
+	_, err = jspromise.Await(js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New("boom")))
+	println(err.Error())
+
+	// A promise backed by a blocking goroutine.
+	v, err = jspromise.Await(jspromise.New(func() (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	}))
+	println("resolved", v.Int(), err == nil)
+	_, err = jspromise.Await(jspromise.New(func() (interface{}, error) {
+		return nil, errors.New("failed")
+	}))
+	println(err.Error())
+
+	println("2")
+}
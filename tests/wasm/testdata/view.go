@@ -0,0 +1,59 @@
+package main
+
+import (
+	"runtime"
+	"syscall/js"
+
+	"tinygo/jsview"
+)
+
+const size = 1 << 20
+
+func main() {
+	buf := make([]byte, size)
+	for i := range buf {
+		buf[i] = byte(i * 7)
+	}
+	var want int32
+	for _, b := range buf {
+		want += int32(b)
+	}
+
+	sum := js.Global().Get("Function").New("a", "let s = 0; for (let i = 0; i < a.length; i++) { s = (s + a[i]) | 0; } return s;")
+	now := js.Global().Get("performance").Get("now")
+	const iterations = 100
+
+	// Copy the buffer to JavaScript on every iteration.
+	dst := js.Global().Get("Uint8Array").New(size)
+	start := now.Invoke().Float()
+	for i := 0; i < iterations; i++ {
+		js.CopyBytesToJS(dst, buf)
+	}
+	copyTime := now.Invoke().Float() - start
+	println("copy sum ok:", sum.Invoke(dst).Int() == int(want))
+
+	// Create a view on the buffer on every iteration.
+	var v js.Value
+	start = now.Invoke().Float()
+	for i := 0; i < iterations; i++ {
+		v = jsview.Uint8Array(buf)
+	}
+	viewTime := now.Invoke().Float() - start
+	println("view length:", v.Get("length").Int())
+	println("view sum ok:", sum.Invoke(v).Int() == int(want))
+
+	// Changes are visible on both sides.
+	buf[10] = 1
+	println("view sees Go write:", v.Index(10).Int())
+	v.SetIndex(11, 2)
+	println("Go sees view write:", buf[11])
+	runtime.KeepAlive(buf)
+
+	floats := []float32{0.5, 1.5, -2}
+	fv := jsview.Float32Array(floats)
+	println("float view:", fv.Get("length").Int(), fv.Index(2).Float())
+	runtime.KeepAlive(floats)
+
+	println("view faster:", viewTime < copyTime)
+	println("copy ms:", int(copyTime), "view ms:", int(viewTime))
+}
@@ -0,0 +1,38 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestView(t *testing.T) {
+
+	wasmTmpDir, server := startServer(t)
+
+	err := run(t, "tinygo build -o "+wasmTmpDir+"/view.wasm -target wasm testdata/view.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := chromectx(t)
+
+	var log1 string
+	err = chromedp.Run(ctx,
+		chromedp.Navigate(server.URL+"/run?file=view.wasm"),
+		waitLogRe(`^copy sum ok: true
+view length: 1048576
+view sum ok: true
+view sees Go write: 1
+Go sees view write: 2
+float view: 3 -2
+view faster: true
+copy ms: \d+ view ms: \d+
+$`),
+		chromedp.InnerHTML("#log", &log1),
+	)
+	t.Logf("log1: %s", log1)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
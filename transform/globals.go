@@ -18,3 +18,61 @@ func ApplyFunctionSections(mod llvm.Module) {
 		llvmFn = llvm.NextFunction(llvmFn)
 	}
 }
+
+// KeepFramePointers makes sure every function maintains a frame pointer, so
+// that a stack trace can be obtained at runtime by following the chain of frame
+// pointers. It is the equivalent of passing -fno-omit-frame-pointer to a C
+// compiler.
+func KeepFramePointers(mod llvm.Module) {
+	ctx := mod.Context()
+	for llvmFn := mod.FirstFunction(); !llvmFn.IsNil(); llvmFn = llvm.NextFunction(llvmFn) {
+		if !llvmFn.IsDeclaration() {
+			llvmFn.AddFunctionAttr(ctx.CreateStringAttribute("frame-pointer", "all"))
+		}
+	}
+}
+
+// MarkHotFunctions applies a CPU profile (the -pgo flag) to the module. The
+// hot functions are optimized for speed instead of size, are preferred by the
+// inliner, and are moved to a .text.hot.* section (when function sections are
+// used) so that the linker can place them close together.
+func MarkHotFunctions(mod llvm.Module, hot map[string]bool) {
+	ctx := mod.Context()
+	optsize := llvm.AttributeKindID("optsize")
+	minsize := llvm.AttributeKindID("minsize")
+	var worklist []llvm.Value
+	for llvmFn := mod.FirstFunction(); !llvmFn.IsNil(); llvmFn = llvm.NextFunction(llvmFn) {
+		if !llvmFn.IsDeclaration() && hot[llvmFn.Name()] {
+			worklist = append(worklist, llvmFn)
+		}
+	}
+	marked := make(map[llvm.Value]bool)
+	for len(worklist) != 0 {
+		llvmFn := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		if marked[llvmFn] {
+			continue
+		}
+		marked[llvmFn] = true
+		llvmFn.RemoveEnumFunctionAttribute(optsize)
+		llvmFn.RemoveEnumFunctionAttribute(minsize)
+		llvmFn.AddFunctionAttr(ctx.CreateEnumAttribute(llvm.AttributeKindID("hot"), 0))
+		llvmFn.AddFunctionAttr(ctx.CreateEnumAttribute(llvm.AttributeKindID("inlinehint"), 0))
+		if name := llvmFn.Name(); llvmFn.Section() == ".text."+name {
+			llvmFn.SetSection(".text.hot." + name)
+		}
+
+		// An internal function that is called from only one place will be
+		// inlined there, even in code optimized for size. Mark the caller as
+		// hot too, or the hot code would end up optimized for size after all.
+		// This often happens for main.main and goroutine wrappers, because
+		// the profile comes from a different compiler.
+		if llvmFn.Linkage() != llvm.InternalLinkage {
+			continue
+		}
+		uses := getUses(llvmFn)
+		if len(uses) == 1 && !uses[0].IsACallInst().IsNil() && uses[0].CalledValue() == llvmFn {
+			worklist = append(worklist, uses[0].InstructionParent().Parent())
+		}
+	}
+}
@@ -13,3 +13,24 @@ func TestApplyFunctionSections(t *testing.T) {
 		transform.ApplyFunctionSections(mod)
 	})
 }
+
+func TestKeepFramePointers(t *testing.T) {
+	t.Parallel()
+	testTransform(t, "testdata/globals-frame-pointers", func(mod llvm.Module) {
+		transform.KeepFramePointers(mod)
+	})
+}
+
+func TestMarkHotFunctions(t *testing.T) {
+	t.Parallel()
+	testTransform(t, "testdata/globals-hot-functions", func(mod llvm.Module) {
+		transform.ApplyFunctionSections(mod)
+		transform.MarkHotFunctions(mod, map[string]bool{
+			"main.hot":            true,
+			"main.extern":         true,
+			"main.hotWithSection": true,
+			"main.hotInternal":    true,
+			"main.hotShared":      true,
+		})
+	})
+}
@@ -65,6 +65,15 @@ func LowerInterrupts(mod llvm.Module) []error {
 		}
 	}
 
+	// With -stack-check, functions compare the stack pointer against the stack
+	// limit of the running goroutine. Interrupts may run on a different stack
+	// (the system stack on Cortex-M), so the limit is cleared while the
+	// handlers run.
+	var stackLimit llvm.Value
+	if !mod.NamedFunction("internal/task.stackOverflow").IsNil() {
+		stackLimit = mod.NamedGlobal("internal/task.stackLimit")
+	}
+
 	// Discover interrupts. The runtime/interrupt.callHandlers call is a
 	// compiler intrinsic that is replaced with the handlers for the given
 	// function.
@@ -87,6 +96,11 @@ func LowerInterrupts(mod llvm.Module) []error {
 			// Replace the callHandlers call with (possibly multiple) calls to
 			// these handlers.
 			builder.SetInsertPointBefore(call)
+			var prevStackLimit llvm.Value
+			if !stackLimit.IsNil() {
+				prevStackLimit = builder.CreateLoad(stackLimit, "stack.limit")
+				builder.CreateStore(llvm.ConstNull(prevStackLimit.Type()), stackLimit)
+			}
 			for _, handler := range handlers {
 				initializer := handler.Initializer()
 				context := llvm.ConstExtractValue(initializer, []uint32{0})
@@ -96,6 +110,9 @@ func LowerInterrupts(mod llvm.Module) []error {
 					context,
 				}, "")
 			}
+			if !stackLimit.IsNil() {
+				builder.CreateStore(prevStackLimit, stackLimit)
+			}
 			call.EraseFromParentAsInstruction()
 		} else {
 			// No handlers. Remove the call.
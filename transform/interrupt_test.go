@@ -19,3 +19,18 @@ func TestInterruptLowering(t *testing.T) {
 		}
 	})
 }
+
+// Test that the goroutine stack limit of -stack-check is cleared while
+// interrupt handlers run, as they may run on a different stack.
+func TestInterruptLoweringStackCheck(t *testing.T) {
+	t.Parallel()
+	testTransform(t, "testdata/interrupt-stackcheck", func(mod llvm.Module) {
+		errs := transform.LowerInterrupts(mod)
+		if len(errs) != 0 {
+			t.Fail()
+			for _, err := range errs {
+				t.Error(err)
+			}
+		}
+	})
+}
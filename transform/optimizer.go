@@ -196,6 +196,11 @@ func Optimize(mod llvm.Module, config *compileopts.Config, optLevel, sizeLevel i
 		}
 	}
 
+	// Report functions that would otherwise cause link errors.
+	if errs := CheckUnsupportedFunctions(mod); len(errs) > 0 {
+		return errs
+	}
+
 	return nil
 }
 
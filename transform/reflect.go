@@ -31,6 +31,7 @@ import (
 	"encoding/binary"
 	"go/ast"
 	"math/big"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -81,6 +82,11 @@ type typeCodeAssignmentState struct {
 	// package (or are simply unused in the compiled program).
 	fallbackIndex int
 
+	// Map of types that use a fallback type code (see fallbackIndex) to their
+	// type code, so that such a type gets the same number wherever it is used,
+	// for example as element type of a slice.
+	fallbackTypes map[string]int
+
 	// This is the length of an uintptr. Only used occasionally to know whether
 	// a given number can be encoded as a varint.
 	uintptrLen int
@@ -95,6 +101,11 @@ type typeCodeAssignmentState struct {
 	arrayTypesSidetable      []byte
 	needsArrayTypesSidetable bool
 
+	// Map of map types to their type code.
+	mapTypes               map[string]int
+	mapTypesSidetable      []byte
+	needsMapTypesSidetable bool
+
 	// Map of struct types to their type code.
 	structTypes               map[string]int
 	structTypesSidetable      []byte
@@ -121,6 +132,14 @@ type typeCodeAssignmentState struct {
 	// all. If it is false, namedNonBasicTypesSidetable will contain simple
 	// monotonically increasing numbers.
 	needsNamedNonBasicTypesSidetable bool
+
+	// Names of named types, as offsets into structNamesSidetable. They are
+	// indexed by the named type number (for basic types) or the index in
+	// namedNonBasicTypes (for non-basic types), and are only created when the
+	// reflect package needs them, for Type.Name and Type.String.
+	namedBasicTypeNamesSidetable    []uint64
+	namedNonBasicTypeNamesSidetable []uint64
+	needsTypeNamesSidetables        bool
 }
 
 // LowerReflect is used to assign a type code to each type in the program
@@ -166,16 +185,24 @@ func LowerReflect(mod llvm.Module) {
 	uintptrType := mod.Context().IntType(targetData.PointerSize() * 8)
 	state := typeCodeAssignmentState{
 		fallbackIndex:                    1,
+		fallbackTypes:                    make(map[string]int),
 		uintptrLen:                       targetData.PointerSize() * 8,
 		namedBasicTypes:                  make(map[string]int),
 		namedNonBasicTypes:               make(map[string]int),
 		arrayTypes:                       make(map[string]int),
+		mapTypes:                         make(map[string]int),
 		structTypes:                      make(map[string]int),
 		structNames:                      make(map[string]int),
 		needsNamedNonBasicTypesSidetable: len(getUses(mod.NamedGlobal("reflect.namedNonBasicTypesSidetable"))) != 0,
 		needsStructTypesSidetable:        len(getUses(mod.NamedGlobal("reflect.structTypesSidetable"))) != 0,
 		needsStructNamesSidetable:        len(getUses(mod.NamedGlobal("reflect.structNamesSidetable"))) != 0,
 		needsArrayTypesSidetable:         len(getUses(mod.NamedGlobal("reflect.arrayTypesSidetable"))) != 0,
+		needsMapTypesSidetable:           len(getUses(mod.NamedGlobal("reflect.mapTypesSidetable"))) != 0,
+		needsTypeNamesSidetables:         len(getUses(mod.NamedGlobal("reflect.namedBasicTypeNamesSidetable"))) != 0 || len(getUses(mod.NamedGlobal("reflect.namedNonBasicTypeNamesSidetable"))) != 0,
+	}
+	if state.needsTypeNamesSidetables {
+		// Type names are stored in the struct names sidetable.
+		state.needsStructNamesSidetable = true
 	}
 	for _, t := range types {
 		num := state.getTypeCodeNum(t.typecode)
@@ -220,12 +247,32 @@ func LowerReflect(mod llvm.Module) {
 		global.SetUnnamedAddr(true)
 		global.SetGlobalConstant(true)
 	}
+	if state.needsMapTypesSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.mapTypesSidetable", state.mapTypesSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
 	if state.needsStructTypesSidetable {
 		global := replaceGlobalIntWithArray(mod, "reflect.structTypesSidetable", state.structTypesSidetable)
 		global.SetLinkage(llvm.InternalLinkage)
 		global.SetUnnamedAddr(true)
 		global.SetGlobalConstant(true)
 	}
+	// The type names sidetables get an extra zero entry at the end, so that
+	// they're never zero-length arrays.
+	if state.needsTypeNamesSidetables && !mod.NamedGlobal("reflect.namedBasicTypeNamesSidetable").IsNil() {
+		global := replaceGlobalIntWithArray(mod, "reflect.namedBasicTypeNamesSidetable", append(state.namedBasicTypeNamesSidetable, 0))
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
+	if state.needsTypeNamesSidetables && !mod.NamedGlobal("reflect.namedNonBasicTypeNamesSidetable").IsNil() {
+		global := replaceGlobalIntWithArray(mod, "reflect.namedNonBasicTypeNamesSidetable", append(state.namedNonBasicTypeNamesSidetable, 0))
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
 	if state.needsStructNamesSidetable {
 		global := replaceGlobalIntWithArray(mod, "reflect.structNamesSidetable", state.structNamesSidetable)
 		global.SetLinkage(llvm.InternalLinkage)
@@ -247,6 +294,11 @@ func LowerReflect(mod llvm.Module) {
 			// typecode objects cannot be erased.
 			structFields := references.Operand(0)
 			structFields.EraseFromParentAsGlobal()
+		} else if strings.HasPrefix(typ.name, "reflect/types.type:map:") {
+			// Same for maps, which refer to a global with the key and element
+			// type.
+			mapFields := references.Operand(0)
+			mapFields.EraseFromParentAsGlobal()
 		}
 	}
 }
@@ -309,6 +361,9 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 				// smaller.
 				index := len(state.namedNonBasicTypes) + 1
 				state.namedNonBasicTypes[name] = index
+				if state.needsTypeNamesSidetables {
+					state.namedNonBasicTypeNamesSidetable = state.setTypeName(state.namedNonBasicTypeNamesSidetable, index, name)
+				}
 				num = big.NewInt(int64(index))
 			} else {
 				// We need to store full type information.
@@ -317,6 +372,9 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 				index := len(state.namedNonBasicTypesSidetable)
 				state.namedNonBasicTypesSidetable = append(state.namedNonBasicTypesSidetable, 0)
 				state.namedNonBasicTypes[name] = index
+				if state.needsTypeNamesSidetables {
+					state.namedNonBasicTypeNamesSidetable = state.setTypeName(state.namedNonBasicTypeNamesSidetable, index, name)
+				}
 				// Get the typecode of the underlying type (which could be the
 				// element type in the case of pointers, for example).
 				num = state.getNonBasicTypeCode(class, typecode)
@@ -350,16 +408,31 @@ func (state *typeCodeAssignmentState) getNonBasicTypeCode(class string, typecode
 		// An array is basically a pair of (typecode, length) stored in a
 		// sidetable.
 		return big.NewInt(int64(state.getArrayTypeNum(typecode)))
+	case "map":
+		// A map is a pair of (key typecode, element typecode) stored in a
+		// sidetable.
+		return big.NewInt(int64(state.getMapTypeNum(typecode)))
 	case "struct":
 		// More complicated type kind. The upper bits contain the index to the
 		// struct type in the struct types sidetable.
 		return big.NewInt(int64(state.getStructTypeNum(typecode)))
+	case "interface":
+		if typecode.Name() == "reflect/types.type:interface:{}" {
+			// The empty interface gets the reserved number 0, so that the
+			// reflect package can recognize it (see emptyInterfaceType).
+			return big.NewInt(0)
+		}
+		fallthrough
 	default:
 		// Type has not yet been implemented, so fall back by using a unique
 		// number.
-		num := big.NewInt(int64(state.fallbackIndex))
+		if index, ok := state.fallbackTypes[typecode.Name()]; ok {
+			return big.NewInt(int64(index))
+		}
+		index := state.fallbackIndex
 		state.fallbackIndex++
-		return num
+		state.fallbackTypes[typecode.Name()] = index
+		return big.NewInt(int64(index))
 	}
 }
 
@@ -394,9 +467,32 @@ func (state *typeCodeAssignmentState) getBasicNamedTypeNum(name string) int {
 	}
 	num := len(state.namedBasicTypes) + 1
 	state.namedBasicTypes[name] = num
+	if state.needsTypeNamesSidetables {
+		state.namedBasicTypeNamesSidetable = state.setTypeName(state.namedBasicTypeNamesSidetable, num, name)
+	}
 	return num
 }
 
+// setTypeName stores the name of a named type at the given index in a type
+// names sidetable, and returns the updated sidetable.
+func (state *typeCodeAssignmentState) setTypeName(table []uint64, index int, name string) []uint64 {
+	for len(table) <= index {
+		table = append(table, 0)
+	}
+	table[index] = uint64(state.getStructNameNumber([]byte(typeNameString(name))))
+	return table
+}
+
+// typeNameString returns the name of a named type as returned by
+// reflect.Type.String, which only uses the package name instead of the full
+// import path. For example, "github.com/foo/bar.Baz" becomes "bar.Baz".
+func typeNameString(name string) string {
+	return importPathPrefix.ReplaceAllString(name, "")
+}
+
+// importPathPrefix matches the part of an import path before the last slash.
+var importPathPrefix = regexp.MustCompile(`[\w.~-]+/([\w.~-]+/)*`)
+
 // getArrayTypeNum returns the array type number, which is an index into the
 // reflect.arrayTypesSidetable or a unique number for this type if this table is
 // not used.
@@ -434,6 +530,43 @@ func (state *typeCodeAssignmentState) getArrayTypeNum(typecode llvm.Value) int {
 	return index
 }
 
+// getMapTypeNum returns the map type number, which is an index into the
+// reflect.mapTypesSidetable or a unique number for this type if this table is
+// not used.
+func (state *typeCodeAssignmentState) getMapTypeNum(typecode llvm.Value) int {
+	name := typecode.Name()
+	if num, ok := state.mapTypes[name]; ok {
+		// This map type already has an entry in the sidetable. Don't store it
+		// twice.
+		return num
+	}
+
+	if !state.needsMapTypesSidetable {
+		// We don't need map sidetables, so we can just assign monotonically
+		// increasing numbers to each map type.
+		num := len(state.mapTypes)
+		state.mapTypes[name] = num
+		return num
+	}
+
+	// The map side table is a sequence of {key type, element type}.
+	mapFields := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0}).Operand(0).Initializer()
+	var buf []byte
+	for i := uint32(0); i < 2; i++ {
+		typeNum := state.getTypeCodeNum(llvm.ConstExtractValue(mapFields, []uint32{i}))
+		if typeNum.BitLen() > state.uintptrLen || !typeNum.IsUint64() {
+			// TODO: make this a regular error
+			panic("map key or element type has a type code that is too big")
+		}
+		buf = append(buf, makeVarint(typeNum.Uint64())...)
+	}
+
+	index := len(state.mapTypesSidetable)
+	state.mapTypes[name] = index
+	state.mapTypesSidetable = append(state.mapTypesSidetable, buf...)
+	return index
+}
+
 // getStructTypeNum returns the struct type number, which is an index into
 // reflect.structTypesSidetable or an unique number for every struct if this
 // sidetable is not needed in the to-be-compiled program.
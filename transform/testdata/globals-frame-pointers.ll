@@ -0,0 +1,15 @@
+target datalayout = "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128"
+target triple = "x86_64-unknown-linux-musl"
+
+declare void @foo()
+
+define void @bar() {
+  call void @foo()
+  ret void
+}
+
+define void @baz() #0 {
+  ret void
+}
+
+attributes #0 = { "frame-pointer"="none" }
@@ -0,0 +1,42 @@
+target datalayout = "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128"
+target triple = "x86_64-unknown-linux-musl"
+
+declare void @main.extern() #0
+
+define void @main.hot() #0 {
+  call void @main.cold()
+  call void @main.extern()
+  ret void
+}
+
+define void @main.cold() #0 {
+  ret void
+}
+
+define void @main.hotWithSection() #0 section ".text.custom" {
+  ret void
+}
+
+; Only called from main.wrapper, so main.wrapper is hot as well.
+define internal void @main.hotInternal() #0 {
+  call void @main.hot()
+  ret void
+}
+
+define internal void @main.wrapper() #0 {
+  call void @main.hotInternal()
+  ret void
+}
+
+; Called from two places, so the callers are not marked hot.
+define internal void @main.hotShared() #0 {
+  ret void
+}
+
+define void @main.useShared() #0 {
+  call void @main.hotShared()
+  call void @main.hotShared()
+  ret void
+}
+
+attributes #0 = { minsize optsize }
@@ -0,0 +1,49 @@
+target datalayout = "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128"
+target triple = "x86_64-unknown-linux-musl"
+
+; Function Attrs: minsize optsize
+declare void @main.extern() #0
+
+; Function Attrs: hot inlinehint
+define void @main.hot() #1 section ".text.hot.main.hot" {
+  call void @main.cold()
+  call void @main.extern()
+  ret void
+}
+
+; Function Attrs: minsize optsize
+define void @main.cold() #0 section ".text.main.cold" {
+  ret void
+}
+
+; Function Attrs: hot inlinehint
+define void @main.hotWithSection() #1 section ".text.custom" {
+  ret void
+}
+
+; Function Attrs: hot inlinehint
+define internal void @main.hotInternal() #1 section ".text.hot.main.hotInternal" {
+  call void @main.hot()
+  ret void
+}
+
+; Function Attrs: hot inlinehint
+define internal void @main.wrapper() #1 section ".text.hot.main.wrapper" {
+  call void @main.hotInternal()
+  ret void
+}
+
+; Function Attrs: hot inlinehint
+define internal void @main.hotShared() #1 section ".text.hot.main.hotShared" {
+  ret void
+}
+
+; Function Attrs: minsize optsize
+define void @main.useShared() #0 section ".text.main.useShared" {
+  call void @main.hotShared()
+  call void @main.hotShared()
+  ret void
+}
+
+attributes #0 = { minsize optsize }
+attributes #1 = { hot inlinehint }
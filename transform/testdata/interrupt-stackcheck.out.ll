@@ -0,0 +1,36 @@
+target datalayout = "e-m:e-p:32:32-Fi8-i64:64-v128:64:128-a:0:32-n32-S64"
+target triple = "armv7em-none-eabi"
+
+%machine.UART = type { i8* }
+
+@machine.UART0 = internal global %machine.UART zeroinitializer
+@"internal/task.stackLimit" = internal global i32 0
+
+declare void @"runtime/interrupt.callHandlers"(i32, i8*) local_unnamed_addr
+
+declare void @"device/arm.EnableIRQ"(i32, i8* nocapture readnone)
+
+declare void @"internal/task.stackOverflow"(i8*)
+
+define void @runtime.initAll(i8* nocapture readnone %0) unnamed_addr {
+entry:
+  call void @"device/arm.EnableIRQ"(i32 2, i8* undef)
+  ret void
+}
+
+define void @UARTE0_UART0_IRQHandler() {
+  %stack.limit = load i32, i32* @"internal/task.stackLimit", align 4
+  store i32 0, i32* @"internal/task.stackLimit", align 4
+  call void @"(*machine.UART).handleInterrupt$bound"(i32 2, i8* bitcast (%machine.UART* @machine.UART0 to i8*))
+  store i32 %stack.limit, i32* @"internal/task.stackLimit", align 4
+  ret void
+}
+
+define internal void @"(*machine.UART).handleInterrupt$bound"(i32 %0, i8* nocapture %context) {
+entry:
+  %unpack.ptr = bitcast i8* %context to %machine.UART*
+  call void @"(*machine.UART).handleInterrupt"(%machine.UART* %unpack.ptr, i32 %0, i8* undef)
+  ret void
+}
+
+declare void @"(*machine.UART).handleInterrupt"(%machine.UART* nocapture, i32, i8* nocapture readnone)
@@ -42,6 +42,18 @@ func main() {
 	assertType(make(chan int), (intNum<<5)|prefixChan)
 	assertType(new(int), (intNum<<5)|prefixPtr)
 	assertType([]int{}, (intNum<<5)|prefixSlice)
+
+	// The empty interface always has the same type code, so that the reflect
+	// package can recognize it.
+	const emptyInterfaceNum = (0 << 5) | prefixInterface
+	assertType(new(interface{}), (emptyInterfaceNum<<5)|prefixPtr)
+	assertType([]interface{}{}, (emptyInterfaceNum<<5)|prefixSlice)
+
+	// Types that are not yet fully supported (like other interfaces) get a
+	// fallback number, which must be the same wherever the type is used.
+	const interfaceNum = (1 << 5) | prefixInterface
+	assertType(new(interface{ M() }), (interfaceNum<<5)|prefixPtr)
+	assertType([]interface{ M() }{}, (interfaceNum<<5)|prefixSlice)
 }
 
 type (
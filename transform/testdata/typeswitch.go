@@ -0,0 +1,73 @@
+package main
+
+// A type switch with many cases, see TestTypeSwitch.
+
+type T0 struct{ a int }
+type T1 struct{ a int }
+type T2 struct{ a int }
+type T3 int
+type T4 int
+type T5 string
+type T6 float64
+type T7 uint8
+type T8 [2]int
+type T9 *int
+
+type E struct{}
+
+func (E) Error() string { return "E" }
+
+func main() {
+	values := []interface{}{T0{}, T1{}, T2{}, T3(0), T4(0), T5(""), T6(0), T7(0), T8{}, T9(nil), 0, "", false, float32(0), int8(0), int16(0), uint16(0), []byte{}, &T0{}, E{}}
+	sum := 0
+	for _, v := range values {
+		sum += dispatch(v)
+	}
+	println(sum)
+}
+
+func dispatch(v interface{}) int {
+	switch v.(type) {
+	case T0:
+		return 0
+	case T1:
+		return 1
+	case T2:
+		return 2
+	case T3:
+		return 3
+	case T4:
+		return 4
+	case T5:
+		return 5
+	case T6:
+		return 6
+	case T7:
+		return 7
+	case T8:
+		return 8
+	case T9:
+		return 9
+	case error:
+		return 10
+	case int:
+		return 11
+	case string:
+		return 12
+	case bool:
+		return 13
+	case float32:
+		return 14
+	case int8:
+		return 15
+	case int16:
+		return 16
+	case uint16:
+		return 17
+	case []byte:
+		return 18
+	case *T0:
+		return 19
+	}
+	return -1
+}
@@ -0,0 +1,25 @@
+package main
+
+import _ "unsafe"
+
+//go:linkname signalEnable os/signal.signal_enable
+func signalEnable(uint32)
+
+//go:linkname modTimer time.modTimer
+func modTimer()
+
+// Declared but not defined anywhere.
+func notImplemented()
+
+// C function, which is provided by libc.
+//
+//export strlen
+func strlen(s *byte) uintptr
+
+func main() {
+	signalEnable(2)  // OUT: function os/signal.signal_enable is not implemented by TinyGo for this target: signal handling (package os/signal) is not supported by TinyGo
+	notImplemented() // OUT: function main.notImplemented is not implemented by TinyGo for this target
+	strlen(nil)
+	modTimer() // OUT: function time.modTimer is not implemented by TinyGo for this target: Ticker.Reset is not supported, stop the ticker and create a new one with time.NewTicker instead
+	modTimer()
+}
@@ -26,3 +26,12 @@ define internal void @callExportedFunction(i64 %foo) {
   call void @exportedFunction(i64 %foo)
   ret void
 }
+
+declare i64 @importedCall(i32) #0
+
+define internal i64 @testImportedCall(i32 %n) {
+  %val = call i64 @importedCall(i32 %n)
+  ret i64 %val
+}
+
+attributes #0 = { "wasm-import-module"="env" "wasm-import-name"="importedCall" }
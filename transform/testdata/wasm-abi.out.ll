@@ -35,6 +35,15 @@ define internal void @callExportedFunction(i64 %foo) {
   ret void
 }
 
+declare i64 @"importedCall$i64wrap"(i32) #0
+
+define internal i64 @testImportedCall(i32 %n) {
+  %i64asptr = alloca i64, align 8
+  call void @importedCall(i64* %i64asptr, i32 %n)
+  %retval = load i64, i64* %i64asptr, align 8
+  ret i64 %retval
+}
+
 declare void @externalCall(i64*, i8*, i32, i64*)
 
 define void @exportedFunction(i64* %0) {
@@ -43,3 +52,7 @@ entry:
   call void @"exportedFunction$i64wrap"(i64 %i64)
   ret void
 }
+
+declare void @importedCall(i64*, i32) #0
+
+attributes #0 = { "wasm-import-module"="env" "wasm-import-name"="importedCall" }
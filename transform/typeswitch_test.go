@@ -0,0 +1,72 @@
+package transform_test
+
+import (
+	"testing"
+
+	"github.com/tinygo-org/tinygo/transform"
+	"tinygo.org/x/go-llvm"
+)
+
+// Test that a type switch with many cases is lowered to a switch instruction
+// once type codes are known, instead of remaining a linear chain of
+// comparisons. The switch instruction is then lowered to a jump table or a
+// binary search by the backend, so dispatch time doesn't grow linearly with the
+// number of cases.
+func TestTypeSwitch(t *testing.T) {
+	t.Parallel()
+
+	mod := compileGoFileForTesting(t, "./testdata/typeswitch.go")
+
+	// Lower type asserts to comparisons of type codes and assign the final
+	// numeric type codes, like the optimizer does.
+	err := transform.LowerInterfaces(mod, defaultTestConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transform.LowerReflect(mod)
+
+	// Run the regular optimization pipeline. The inliner is needed to inline
+	// the interface type assert (of the error case) into the type switch.
+	builder := llvm.NewPassManagerBuilder()
+	defer builder.Dispose()
+	builder.SetOptLevel(2)
+	builder.UseInlinerWithThreshold(225)
+	funcPasses := llvm.NewFunctionPassManagerForModule(mod)
+	defer funcPasses.Dispose()
+	builder.PopulateFunc(funcPasses)
+	funcPasses.InitializeFunc()
+	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		funcPasses.RunFunc(fn)
+	}
+	funcPasses.FinalizeFunc()
+	modPasses := llvm.NewPassManager()
+	defer modPasses.Dispose()
+	builder.Populate(modPasses)
+	modPasses.Run(mod)
+
+	fn := mod.NamedFunction("main.dispatch")
+	if fn.IsNil() {
+		t.Fatal("main.dispatch was removed")
+	}
+
+	// Count the switch cases and the remaining comparisons in the function.
+	// There are 20 cases in total (one of which matches two concrete types),
+	// almost all of them should be part of a switch.
+	switchCases := 0
+	compares := 0
+	for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+		for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+			switch inst.InstructionOpcode() {
+			case llvm.Switch:
+				// A switch has a condition and default destination operand,
+				// followed by a value and destination for each case.
+				switchCases += (inst.OperandsCount() - 2) / 2
+			case llvm.ICmp:
+				compares++
+			}
+		}
+	}
+	if switchCases < 18 || compares > 2 {
+		t.Errorf("type switch was not lowered to a switch: found %d switch cases and %d compares", switchCases, compares)
+	}
+}
@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"strings"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// Hints for functions that are known to be missing in TinyGo, to explain what
+// doesn't work and what to use instead. The key is either the full name of a
+// function, or a package path followed by a dot to match all functions in that
+// package.
+var unsupportedFunctionHints = map[string]string{
+	"os/signal.":                  "signal handling (package os/signal) is not supported by TinyGo",
+	"time.modTimer":               "Ticker.Reset is not supported, stop the ticker and create a new one with time.NewTicker instead",
+	"sync/atomic.runtime_procPin": "atomic.Value is not supported on this target, protect the value with a sync.Mutex instead",
+}
+
+// CheckUnsupportedFunctions reports all calls to Go functions that are
+// declared but not defined anywhere in the program. These are usually standard
+// library functions that rely on the Go runtime (via //go:linkname) for their
+// implementation, while the TinyGo runtime doesn't implement them. Without
+// this check, they would result in an undefined symbol error from the linker
+// that doesn't say why the function is missing or where it is used.
+//
+// This must be run on the whole program after all other transformations, so
+// that functions that are removed as dead code don't result in an error.
+func CheckUnsupportedFunctions(mod llvm.Module) []error {
+	var errs []error
+	isWasm := strings.HasPrefix(mod.Target(), "wasm")
+	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		if !fn.IsDeclaration() {
+			continue
+		}
+		name := fn.Name()
+		if strings.HasPrefix(name, "llvm.") || !strings.Contains(name, ".") {
+			// LLVM intrinsic or C function.
+			continue
+		}
+		if !fn.GetStringAttributeAtIndex(-1, "wasm-import-module").IsNil() {
+			// Imported from the WebAssembly host.
+			continue
+		}
+		if isWasm && strings.HasPrefix(name, "syscall/js.") {
+			// Implemented by wasm_exec.js, these are allowed to be undefined
+			// by targets/wasm-undefined.txt.
+			continue
+		}
+		uses := getUses(fn)
+		if len(uses) == 0 {
+			continue
+		}
+
+		msg := "function " + name + " is not implemented by TinyGo for this target"
+		if hint := unsupportedFunctionHint(name); hint != "" {
+			msg += ": " + hint
+		}
+
+		// Report the error at the first call in the source code, so that it's
+		// clear where the function is used.
+		err := errorAt(uses[0], msg)
+		for _, use := range uses[1:] {
+			pos := getPosition(use)
+			if pos.Filename == "" {
+				continue
+			}
+			if err.Pos.Filename == "" || pos.Filename < err.Pos.Filename ||
+				pos.Filename == err.Pos.Filename && (pos.Line < err.Pos.Line || pos.Line == err.Pos.Line && pos.Column < err.Pos.Column) {
+				err.Pos = pos
+			}
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// unsupportedFunctionHint returns the hint from unsupportedFunctionHints for
+// the given function name, or the empty string if there is none.
+func unsupportedFunctionHint(name string) string {
+	if hint, ok := unsupportedFunctionHints[name]; ok {
+		return hint
+	}
+	// Look for a hint for the whole package. The package path is everything
+	// before the first dot after the last slash.
+	pkgEnd := strings.LastIndexByte(name, '/') + 1
+	pkgEnd += strings.IndexByte(name[pkgEnd:], '.')
+	return unsupportedFunctionHints[name[:pkgEnd+1]]
+}
@@ -0,0 +1,48 @@
+package transform_test
+
+import (
+	"go/scanner"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/transform"
+)
+
+func TestCheckUnsupportedFunctions(t *testing.T) {
+	t.Parallel()
+
+	mod := compileGoFileForTesting(t, "./testdata/unsupported.go")
+	defer mod.Dispose()
+
+	errs := transform.CheckUnsupportedFunctions(mod)
+	var lines []string
+	for _, err := range errs {
+		err := err.(scanner.Error)
+		lines = append(lines, filepath.Base(err.Pos.Filename)+":"+strconv.Itoa(err.Pos.Line)+": "+err.Msg)
+	}
+	sort.Strings(lines)
+	testOutput := strings.Join(lines, "\n")
+
+	// Load expected test output (the OUT: lines).
+	testInput, err := os.ReadFile("./testdata/unsupported.go")
+	if err != nil {
+		t.Fatal("could not read test input:", err)
+	}
+	var expected []string
+	for i, line := range strings.Split(strings.ReplaceAll(string(testInput), "\r\n", "\n"), "\n") {
+		if idx := strings.Index(line, " // OUT: "); idx > 0 {
+			msg := line[idx+len(" // OUT: "):]
+			expected = append(expected, "unsupported.go:"+strconv.Itoa(i+1)+": "+msg)
+		}
+	}
+	sort.Strings(expected)
+	expectedTestOutput := strings.Join(expected, "\n")
+
+	if testOutput != expectedTestOutput {
+		t.Errorf("output does not match expected output:\n%s", testOutput)
+	}
+}
@@ -82,6 +82,14 @@ func ExternalInt64AsPtr(mod llvm.Module, config *compileopts.Config) error {
 		externalFn := llvm.AddFunction(mod, name, externalFnType)
 		AddStandardAttributes(fn, config)
 
+		// Keep the import module and name of imported functions, so that
+		// they're still imported from the right place.
+		for _, attrName := range []string{"wasm-import-module", "wasm-import-name"} {
+			if attr := fn.GetStringAttributeAtIndex(-1, attrName); !attr.IsNil() {
+				externalFn.AddFunctionAttr(attr)
+			}
+		}
+
 		if fn.IsDeclaration() {
 			// Just a declaration: the definition doesn't exist on the Go side
 			// so it cannot be called from external code.